@@ -1,11 +1,16 @@
 package main
 
 import (
+	"context"
 	"log"
 	"os"
+	"strings"
+	"time"
 
+	"gaspeep/backend/internal/auth"
 	"gaspeep/backend/internal/db"
 	"gaspeep/backend/internal/handler"
+	"gaspeep/backend/internal/i18n"
 	"gaspeep/backend/internal/middleware"
 	"gaspeep/backend/internal/repository"
 	"gaspeep/backend/internal/service"
@@ -39,10 +44,39 @@ func startServer(router *gin.Engine, getenv func(string) string) error {
 	return runHTTPServer(router, ":"+port)
 }
 
+// configureTrustedProxies makes the router trust X-Forwarded-For only from
+// the proxies in TRUSTED_PROXIES, a comma-separated list of IPs or CIDRs such
+// as the nginx container's address. With none, c.ClientIP() is the address of
+// the connection, so clients cannot pick their own IP to get around per-IP
+// limits.
+func configureTrustedProxies(router *gin.Engine, getenv func(string) string) error {
+	var proxies []string
+	for _, p := range strings.Split(getenv("TRUSTED_PROXIES"), ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			proxies = append(proxies, p)
+		}
+	}
+	return router.SetTrustedProxies(proxies)
+}
+
 func main() {
 	// Load .env file
 	godotenv.Load()
 
+	// Load token signing keys now that the environment is complete
+	if err := auth.LoadKeys(); err != nil {
+		log.Fatalf("Failed to load JWT signing keys: %v", err)
+	}
+	if ks, _ := auth.CurrentKeySet(); ks.Ephemeral() {
+		log.Println("⚠ JWT_KEYS_DIR not set; signing tokens with a temporary development key")
+	} else {
+		log.Printf("✓ Signing tokens with key %s", ks.ActiveKeyID())
+	}
+	oidcProviders, err := auth.OIDCProvidersFromEnv()
+	if err != nil {
+		log.Fatalf("Failed to configure sign-in providers: %v", err)
+	}
+
 	// Initialize database
 	database, err := db.NewDB()
 	if err != nil {
@@ -64,6 +98,16 @@ func main() {
 	}
 	log.Println("✓ Migrations completed")
 
+	// --- Localization ---
+	catalog, err := i18n.LoadFromEnv()
+	if err != nil {
+		log.Fatalf("Failed to load locale bundles: %v", err)
+	}
+	emailTemplates, err := service.LoadEmailTemplates(catalog)
+	if err != nil {
+		log.Fatalf("Failed to load email templates: %v", err)
+	}
+
 	// --- Repositories ---
 	userRepo := repository.NewPgUserRepository(database)
 	passwordResetRepo := repository.NewPgPasswordResetRepository(database)
@@ -73,27 +117,94 @@ func main() {
 	fuelPriceRepo := repository.NewPgFuelPriceRepository(database)
 	priceSubmissionRepo := repository.NewPgPriceSubmissionRepository(database)
 	alertRepo := repository.NewPgAlertRepository(database)
+	favouriteStationRepo := repository.NewPgFavouriteStationRepository(database)
 	broadcastRepo := repository.NewPgBroadcastRepository(database)
 	notificationRepo := repository.NewPgNotificationRepository(database)
 	stationOwnerRepo := repository.NewPgStationOwnerRepository(database)
+	priceChangeOutboxRepo := repository.NewPgPriceChangeOutboxRepository(database)
+	emailOutboxRepo := repository.NewPgEmailOutboxRepository(database)
+	emailVerificationRepo := repository.NewPgEmailVerificationRepository(database)
+	emailUnsubscribeRepo := repository.NewPgEmailUnsubscribeRepository(database)
+	sessionRepo := repository.NewPgSessionRepository(database)
+	mfaRepo := repository.NewPgMFARepository(database)
+	magicLinkRepo := repository.NewPgMagicLinkRepository(database)
+	auditLogRepo := repository.NewPgAuditLogRepository(database)
+	subscriptionRepo := repository.NewPgSubscriptionRepository(database)
+	ownerAPIKeyRepo := repository.NewPgOwnerAPIKeyRepository(database)
+	stationProfileRepo := repository.NewPgStationProfileRepository(database)
+	claimVerificationRepo := repository.NewPgClaimVerificationRepository(database)
+	ownerTeamRepo := repository.NewPgOwnerTeamRepository(database)
+	ownerAnalyticsRepo := repository.NewPgOwnerAnalyticsRepository(database)
+
+	// Failed sign-ins are kept in Postgres so every instance sees them. A
+	// single instance may keep them in memory instead.
+	var loginAttemptRepo repository.LoginAttemptRepository
+	switch store := os.Getenv("LOGIN_ATTEMPT_STORE"); store {
+	case "", "postgres":
+		loginAttemptRepo = repository.NewPgLoginAttemptRepository(database)
+	case "memory":
+		loginAttemptRepo = repository.NewMemoryLoginAttemptRepository()
+	default:
+		log.Fatalf("Unknown LOGIN_ATTEMPT_STORE %q (expected postgres or memory)", store)
+	}
 
 	// --- Services ---
+	emailVerificationPolicy := service.NewEmailVerificationPolicy(userRepo)
+	sessionService := service.NewSessionService(sessionRepo, userRepo)
+	mfaService := service.NewMFAService(mfaRepo)
 	stationService := service.NewStationService(stationRepo)
 	fuelTypeService := service.NewFuelTypeService(fuelTypeRepo)
 	brandService := service.NewBrandService(brandRepo)
 	fuelPriceService := service.NewFuelPriceService(fuelPriceRepo)
-	priceSubmissionService := service.NewPriceSubmissionService(priceSubmissionRepo, fuelPriceRepo, alertRepo)
+	priceSubmissionService := service.NewPriceSubmissionService(priceSubmissionRepo, fuelPriceRepo)
 	ocrService := service.NewGoogleVisionOCRServiceFromEnv()
 	alertService := service.NewAlertService(alertRepo)
-	broadcastService := service.NewBroadcastService(broadcastRepo, stationOwnerRepo)
+	favouriteStationService := service.NewFavouriteStationService(favouriteStationRepo)
+	broadcastService := service.NewBroadcastService(broadcastRepo, stationOwnerRepo, claimVerificationRepo)
 	notificationService := service.NewNotificationService(notificationRepo)
-	stationOwnerService := service.NewStationOwnerService(stationOwnerRepo)
+	stationOwnerService := service.NewStationOwnerService(stationOwnerRepo, ownerAnalyticsRepo, emailVerificationPolicy)
+	stationProfileService := service.NewStationProfileService(stationProfileRepo)
+	ownerAPIKeyService := service.NewOwnerAPIKeyService(ownerAPIKeyRepo, stationOwnerRepo, fuelPriceRepo)
 	serviceNSWSyncService := service.NewServiceNSWSyncService(database)
+	emailSender, err := service.NewEmailSenderFromEnv()
+	if err != nil {
+		log.Fatalf("Failed to configure email transport: %v", err)
+	}
+	emailUnsubscribeService, err := service.NewEmailUnsubscribeService(emailUnsubscribeRepo)
+	if err != nil {
+		log.Fatalf("Failed to configure email unsubscribe links: %v", err)
+	}
+	emailService := service.NewEmailService(emailOutboxRepo, userRepo, emailVerificationPolicy, emailUnsubscribeService, emailTemplates)
+	emailVerificationService := service.NewEmailVerificationService(emailVerificationRepo, userRepo, emailService)
+	loginThrottle := service.NewLoginThrottle(loginAttemptRepo, userRepo, emailService)
+	magicLinkService := service.NewMagicLinkService(magicLinkRepo, userRepo, auditLogRepo, emailService)
+	paymentProvider, err := service.NewPaymentProviderFromEnv()
+	if err != nil {
+		log.Fatalf("Failed to configure payment provider: %v", err)
+	}
+	subscriptionService := service.NewSubscriptionService(subscriptionRepo, userRepo, paymentProvider)
+	ownerVerificationService := service.NewOwnerVerificationService(claimVerificationRepo, emailService, emailVerificationPolicy)
+	ownerTeamService := service.NewOwnerTeamService(ownerTeamRepo, stationOwnerRepo, userRepo, emailService, emailVerificationPolicy)
+	alertWorker := service.NewAlertWorker(priceChangeOutboxRepo, alertRepo, emailService)
+	emailWorker := service.NewEmailWorker(emailOutboxRepo, emailSender)
+	tokenCleanupWorker := service.NewTokenCleanupWorker(passwordResetRepo, magicLinkRepo, emailVerificationRepo, ownerAPIKeyRepo)
+	subscriptionExpiryWorker := service.NewSubscriptionExpiryWorker(subscriptionService)
+	ownerVerificationWorker := service.NewOwnerVerificationWorker(ownerVerificationService)
+
+	// --- Background workers ---
+	alertWorker.Start(context.Background())
+	emailWorker.Start(context.Background())
+	tokenCleanupWorker.Start(context.Background())
+	subscriptionExpiryWorker.Start(context.Background())
+	ownerVerificationWorker.Start(context.Background())
 
 	// --- Handlers ---
-	authHandler := handler.NewAuthHandler(userRepo, passwordResetRepo)
-	oauthHandler := handler.NewOAuthHandler(userRepo)
-	userProfileHandler := handler.NewUserProfileHandler(userRepo, passwordResetRepo)
+	authHandler := handler.NewAuthHandler(userRepo, passwordResetRepo, emailVerificationService, sessionService, mfaService, loginThrottle, emailService)
+	oauthHandler := handler.NewOAuthHandler(userRepo, sessionService, mfaService, oidcProviders)
+	mfaHandler := handler.NewMFAHandler(userRepo, sessionService, mfaService, loginThrottle)
+	magicLinkHandler := handler.NewMagicLinkHandler(sessionService, mfaService, loginThrottle, magicLinkService)
+	userProfileHandler := handler.NewUserProfileHandler(userRepo, passwordResetRepo, emailService, loginThrottle)
+	subscriptionHandler := handler.NewSubscriptionHandler(subscriptionService)
 	stationHandler := handler.NewStationHandler(stationService)
 	fuelTypeHandler := handler.NewFuelTypeHandler(fuelTypeService)
 	brandHandler := handler.NewBrandHandler(brandService)
@@ -101,36 +212,68 @@ func main() {
 	priceSubmissionHandler := handler.NewPriceSubmissionHandler(priceSubmissionService)
 	priceSubmissionHandler.SetOCRService(ocrService)
 	alertHandler := handler.NewAlertHandler(alertService)
+	favouriteStationHandler := handler.NewFavouriteStationHandler(favouriteStationService)
 	broadcastHandler := handler.NewBroadcastHandler(broadcastService)
 	notificationHandler := handler.NewNotificationHandler(notificationService)
 	stationOwnerHandler := handler.NewStationOwnerHandler(stationOwnerService)
+	integrationHandler := handler.NewIntegrationHandler(ownerAPIKeyService)
+	stationProfileHandler := handler.NewStationProfileHandler(stationProfileService)
+	ownerVerificationHandler := handler.NewOwnerVerificationHandler(ownerVerificationService)
+	ownerTeamHandler := handler.NewOwnerTeamHandler(ownerTeamService)
 	serviceNSWSyncHandler := handler.NewServiceNSWSyncHandler(serviceNSWSyncService)
+	emailHandler := handler.NewEmailHandler(emailService)
+	emailUnsubscribeHandler := handler.NewEmailUnsubscribeHandler(emailUnsubscribeService)
+	jwksHandler := handler.NewJWKSHandler()
 
 	// Create Gin router
 	router := gin.Default()
+	if err := configureTrustedProxies(router, os.Getenv); err != nil {
+		log.Fatalf("Invalid TRUSTED_PROXIES: %v", err)
+	}
 
 	// Middleware
 	router.Use(middleware.CORSMiddleware())
 	router.Use(middleware.ErrorHandlingMiddleware())
+	router.Use(middleware.LocaleMiddleware(catalog, userRepo))
 
 	// Health check
 	router.GET("/health", func(c *gin.Context) {
 		c.JSON(200, gin.H{"status": "ok"})
 	})
 
+	// Public keys for verifying access tokens
+	router.GET("/.well-known/jwks.json", jwksHandler.GetJWKS)
+
 	// Auth routes
 	auth := router.Group("/api/auth")
 	{
 		auth.POST("/signup", authHandler.SignUp)
 		auth.POST("/signin", authHandler.SignIn)
+		auth.POST("/refresh", middleware.RateLimitMiddleware(30, time.Minute), authHandler.Refresh)
 		auth.POST("/logout", authHandler.Logout)
 		// OAuth endpoints
-		auth.GET("/oauth/google", oauthHandler.StartGoogle)
-		auth.GET("/oauth/google/callback", oauthHandler.GoogleCallback)
+		auth.GET("/oauth/providers", oauthHandler.ListProviders)
+		auth.GET("/oauth/:provider", oauthHandler.Start)
+		auth.GET("/oauth/:provider/callback", oauthHandler.Callback)
+		auth.POST("/oauth/:provider/callback", oauthHandler.Callback)
 		auth.GET("/check-email", authHandler.CheckEmailAvailability)
+		auth.POST("/magic-link", middleware.RateLimitMiddleware(5, time.Minute), magicLinkHandler.Request)
+		auth.POST("/magic-link/verify", middleware.RateLimitMiddleware(10, time.Minute), magicLinkHandler.Redeem)
 		auth.GET("/me", middleware.AuthMiddleware(), authHandler.GetCurrentUser)
 		auth.POST("/password-reset", userProfileHandler.PasswordReset)
 		auth.POST("/reset-password", authHandler.ResetPassword)
+		auth.POST("/change-password", middleware.AuthMiddleware(), authHandler.ChangePassword)
+		auth.GET("/sessions", middleware.AuthMiddleware(), authHandler.ListSessions)
+		auth.DELETE("/sessions", middleware.AuthMiddleware(), authHandler.RevokeOtherSessions)
+		auth.DELETE("/sessions/:id", middleware.AuthMiddleware(), authHandler.RevokeSession)
+		auth.POST("/verify-email", middleware.RateLimitMiddleware(10, time.Minute), authHandler.VerifyEmail)
+		auth.POST("/resend-verification", middleware.RateLimitMiddleware(5, time.Minute), middleware.AuthMiddleware(), authHandler.ResendVerification)
+		auth.GET("/mfa", middleware.AuthMiddleware(), mfaHandler.GetStatus)
+		auth.DELETE("/mfa", middleware.RateLimitMiddleware(10, time.Minute), middleware.AuthMiddleware(), mfaHandler.Disable)
+		auth.POST("/mfa/totp/setup", middleware.AuthMiddleware(), mfaHandler.SetupTOTP)
+		auth.POST("/mfa/totp/confirm", middleware.RateLimitMiddleware(10, time.Minute), middleware.AuthMiddleware(), mfaHandler.ConfirmTOTP)
+		auth.POST("/mfa/verify", middleware.RateLimitMiddleware(10, time.Minute), mfaHandler.Verify)
+		auth.POST("/mfa/recovery-codes", middleware.RateLimitMiddleware(10, time.Minute), middleware.AuthMiddleware(), mfaHandler.RegenerateRecoveryCodes)
 	}
 
 	// Station routes
@@ -174,23 +317,46 @@ func main() {
 		priceSubmissions.POST("", priceSubmissionHandler.CreatePriceSubmission)
 		priceSubmissions.POST("/analyze-photo", priceSubmissionHandler.AnalyzePhoto)
 		priceSubmissions.GET("/my-submissions", priceSubmissionHandler.GetMySubmissions)
-		priceSubmissions.PUT("/:id/moderate", priceSubmissionHandler.ModerateSubmission)
+		priceSubmissions.PUT("/:id/moderate", middleware.RequireMFA(mfaService), priceSubmissionHandler.ModerateSubmission)
 	}
 
-	router.GET("/api/moderation-queue", middleware.AuthMiddleware(), priceSubmissionHandler.GetModerationQueue)
+	router.GET("/api/moderation-queue", middleware.AuthMiddleware(), middleware.RequireMFA(mfaService), priceSubmissionHandler.GetModerationQueue)
 
-	// Alert routes
+	// Alert routes. Creating and changing alerts needs Premium; users whose
+	// subscription has lapsed can still see and delete theirs.
 	alerts := router.Group("/api/alerts")
 	alerts.Use(middleware.AuthMiddleware())
+	premiumAlerts := middleware.RequireEntitlement(subscriptionService, service.EntitlementPriceAlerts)
 	{
-		alerts.POST("", alertHandler.CreateAlert)
-		alerts.POST("/price-context", alertHandler.GetPriceContext)
+		alerts.POST("", premiumAlerts, alertHandler.CreateAlert)
+		alerts.POST("/price-context", premiumAlerts, alertHandler.GetPriceContext)
+		alerts.POST("/preview", premiumAlerts, alertHandler.PreviewAlert)
 		alerts.GET("", alertHandler.GetAlerts)
 		alerts.GET("/:id/matching-stations", alertHandler.GetMatchingStations)
-		alerts.PUT("/:id", alertHandler.UpdateAlert)
+		alerts.PUT("/:id", premiumAlerts, alertHandler.UpdateAlert)
 		alerts.DELETE("/:id", alertHandler.DeleteAlert)
 	}
 
+	// Subscription routes
+	router.GET("/api/subscriptions/plans", subscriptionHandler.GetPlans)
+	subscriptions := router.Group("/api/subscriptions")
+	subscriptions.Use(middleware.AuthMiddleware())
+	{
+		subscriptions.GET("/me", subscriptionHandler.GetSubscription)
+		subscriptions.POST("/checkout", subscriptionHandler.Checkout)
+		subscriptions.POST("/cancel", subscriptionHandler.Cancel)
+	}
+
+	// Favourite station routes
+	favouriteStations := router.Group("/api/favourite-stations")
+	favouriteStations.Use(middleware.AuthMiddleware())
+	{
+		favouriteStations.GET("", favouriteStationHandler.GetFavourites)
+		favouriteStations.GET("/prices", favouriteStationHandler.GetFavouritePrices)
+		favouriteStations.POST("", favouriteStationHandler.AddFavourite)
+		favouriteStations.DELETE("/:stationId", favouriteStationHandler.RemoveFavourite)
+	}
+
 	// Notification routes
 	notifications := router.Group("/api/notifications")
 	notifications.Use(middleware.AuthMiddleware())
@@ -200,26 +366,50 @@ func main() {
 
 	// Station owner routes
 	stationOwners := router.Group("/api/station-owners")
-	stationOwners.Use(middleware.AuthMiddleware())
+	stationOwners.Use(middleware.AuthMiddleware(), middleware.RequireMFA(mfaService))
 	{
 		stationOwners.GET("/profile", stationOwnerHandler.GetProfile)
 		stationOwners.PATCH("/profile", stationOwnerHandler.UpdateProfile)
 		stationOwners.GET("/stats", stationOwnerHandler.GetStats)
+		stationOwners.GET("/analytics/price-benchmarks", stationOwnerHandler.GetPriceBenchmarks)
 		stationOwners.GET("/fuel-prices", stationOwnerHandler.GetFuelPrices)
+		stationOwners.POST("/fuel-prices", stationOwnerHandler.PublishFuelPrices)
 		stationOwners.GET("/search-stations", stationOwnerHandler.SearchStations)
 		stationOwners.POST("/verify", stationOwnerHandler.VerifyOwnership)
 		stationOwners.POST("/claim-station", stationOwnerHandler.ClaimStation)
 		stationOwners.GET("/stations", stationOwnerHandler.GetStations)
 		stationOwners.GET("/stations/:id", stationOwnerHandler.GetStationDetails)
-		stationOwners.PUT("/stations/:id", stationOwnerHandler.UpdateStation)
+		stationOwners.PUT("/stations/:id", stationProfileHandler.UpdateStation)
+		stationOwners.GET("/stations/:id/changes", stationProfileHandler.GetStationChanges)
+		stationOwners.PUT("/stations/:id/prices", stationOwnerHandler.PublishStationPrices)
 		stationOwners.POST("/stations/:id/photos", stationOwnerHandler.UploadPhotos)
 		stationOwners.POST("/stations/:id/unclaim", stationOwnerHandler.UnclaimStation)
-		stationOwners.POST("/stations/:id/reverify", stationOwnerHandler.ReVerifyStation)
+		stationOwners.POST("/stations/:id/reverify", ownerVerificationHandler.ReVerifyStation)
+		stationOwners.GET("/api-keys", integrationHandler.ListAPIKeys)
+		stationOwners.POST("/api-keys", integrationHandler.CreateAPIKey)
+		stationOwners.DELETE("/api-keys/:id", integrationHandler.RevokeAPIKey)
+		stationOwners.GET("/api-keys/:id/usage", integrationHandler.GetAPIKeyUsage)
+		stationOwners.GET("/team", ownerTeamHandler.GetTeam)
+		stationOwners.GET("/team/me", ownerTeamHandler.GetMyMembership)
+		stationOwners.POST("/team/invitations", ownerTeamHandler.InviteMember)
+		stationOwners.DELETE("/team/invitations/:id", ownerTeamHandler.RevokeInvitation)
+		stationOwners.POST("/team/invitations/accept", ownerTeamHandler.AcceptInvitation)
+		stationOwners.PUT("/team/members/:id", ownerTeamHandler.UpdateMember)
+		stationOwners.DELETE("/team/members/:id", ownerTeamHandler.RemoveMember)
+	}
+
+	// Integration API for station owners' point-of-sale and pricing systems,
+	// authenticated with the owners' API keys
+	integrations := router.Group("/api/integrations")
+	integrations.Use(middleware.RateLimitMiddleware(120, time.Minute), middleware.APIKeyAuthMiddleware(ownerAPIKeyService))
+	{
+		integrations.GET("/stations/:id/prices", integrationHandler.GetStationPrices)
+		integrations.PUT("/stations/:id/prices", integrationHandler.PutStationPrices)
 	}
 
 	// Broadcast routes
 	broadcasts := router.Group("/api/broadcasts")
-	broadcasts.Use(middleware.AuthMiddleware())
+	broadcasts.Use(middleware.AuthMiddleware(), middleware.RequireMFA(mfaService))
 	{
 		broadcasts.POST("", broadcastHandler.CreateBroadcast)
 		broadcasts.GET("", broadcastHandler.GetBroadcasts)
@@ -245,12 +435,34 @@ func main() {
 		users.PUT("/preferences/map-filters", userProfileHandler.UpdateMapFilterPreferences)
 	}
 
+	// Service NSW sync, authorised by the Service NSW API key and secret
+	router.POST("/api/admin/service-nsw-sync", middleware.ServiceNSWSyncAuthMiddleware(), serviceNSWSyncHandler.TriggerSync)
+
 	admin := router.Group("/api/admin")
-	admin.Use(middleware.ServiceNSWSyncAuthMiddleware())
+	admin.Use(middleware.AuthMiddleware(), middleware.RequireMFA(mfaService), middleware.RequireAdmin(mfaRepo))
 	{
-		admin.POST("/service-nsw-sync", serviceNSWSyncHandler.TriggerSync)
+		admin.GET("/emails", emailHandler.GetEmailLog)
+		admin.GET("/station-changes", stationProfileHandler.GetReviewQueue)
+		admin.POST("/station-changes/:id/approve", stationProfileHandler.ApproveChange)
+		admin.POST("/station-changes/:id/reject", stationProfileHandler.RejectChange)
+		admin.GET("/stations/:id/versions", stationProfileHandler.GetVersions)
+		admin.GET("/stations/:id/versions/diff", stationProfileHandler.GetVersionDiff)
+		admin.POST("/stations/:id/versions/:version/rollback", stationProfileHandler.RollbackVersion)
+		admin.GET("/claim-verifications", ownerVerificationHandler.GetClaims)
+		admin.POST("/claim-verifications/:id/approve", ownerVerificationHandler.ApproveClaim)
+		admin.POST("/claim-verifications/:id/reject", ownerVerificationHandler.RejectClaim)
 	}
 
+	// Email provider webhooks
+	router.POST("/api/webhooks/email", middleware.EmailWebhookAuthMiddleware(), emailHandler.DeliveryWebhook)
+
+	// Payment provider webhooks, verified by the provider's signature
+	router.POST("/api/webhooks/payments", subscriptionHandler.PaymentWebhook)
+
+	// Unsubscribe links in alert and broadcast emails, authorised by their signed token
+	router.GET("/api/email/unsubscribe", emailUnsubscribeHandler.ShowUnsubscribe)
+	router.POST("/api/email/unsubscribe", emailUnsubscribeHandler.Unsubscribe)
+
 	if err := startServer(router, os.Getenv); err != nil {
 		log.Fatalf("Server failed to start: %v", err)
 	}
@@ -2,8 +2,14 @@ package main
 
 import (
 	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
 	"testing"
 
+	"gaspeep/backend/internal/repository"
+	"gaspeep/backend/internal/service"
+
 	"github.com/gin-gonic/gin"
 )
 
@@ -188,3 +194,78 @@ func TestStartServer_TLSErrorPropagates(t *testing.T) {
 		t.Fatalf("expected error %v, got %v", expectedErr, err)
 	}
 }
+
+// throttledSignInRouter fails every sign-in, as the auth handler does for a
+// wrong password, so repeated requests lock the client's IP.
+func throttledSignInRouter(t *testing.T, getenv func(string) string) *gin.Engine {
+	t.Setenv("LOGIN_IP_MAX_ATTEMPTS", "3")
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	if err := configureTrustedProxies(router, getenv); err != nil {
+		t.Fatalf("configureTrustedProxies returned error: %v", err)
+	}
+	throttle := service.NewLoginThrottle(repository.NewMemoryLoginAttemptRepository(), nil, nil)
+	router.POST("/signin", func(c *gin.Context) {
+		email := c.Query("email")
+		if err := throttle.Check(email, c.ClientIP()); err != nil {
+			c.Status(http.StatusTooManyRequests)
+			return
+		}
+		if err := throttle.RecordFailure(email, c.ClientIP()); err != nil {
+			t.Fatalf("RecordFailure returned error: %v", err)
+		}
+		c.Status(http.StatusUnauthorized)
+	})
+	return router
+}
+
+func signIn(router *gin.Engine, n int, forwardedFor string) int {
+	req := httptest.NewRequest(http.MethodPost, "/signin?email=user"+strconv.Itoa(n)+"@example.com", nil)
+	req.RemoteAddr = "203.0.113.7:4000"
+	if forwardedFor != "" {
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w.Code
+}
+
+func TestConfigureTrustedProxies_SpoofedForwardedForKeepsIPLockout(t *testing.T) {
+	router := throttledSignInRouter(t, func(string) string { return "" })
+
+	for i := 0; i < 3; i++ {
+		if code := signIn(router, i, "198.51.100."+strconv.Itoa(i)); code != http.StatusUnauthorized {
+			t.Fatalf("attempt %d: expected 401, got %d", i, code)
+		}
+	}
+	if code := signIn(router, 3, "198.51.100.99"); code != http.StatusTooManyRequests {
+		t.Fatalf("expected the IP to stay locked despite X-Forwarded-For, got %d", code)
+	}
+}
+
+func TestConfigureTrustedProxies_TrustsConfiguredProxy(t *testing.T) {
+	router := throttledSignInRouter(t, func(key string) string {
+		if key == "TRUSTED_PROXIES" {
+			return "10.0.0.0/8, 203.0.113.7"
+		}
+		return ""
+	})
+
+	for i := 0; i < 3; i++ {
+		signIn(router, i, "198.51.100.1")
+	}
+	if code := signIn(router, 3, "198.51.100.1"); code != http.StatusTooManyRequests {
+		t.Fatalf("expected the forwarded client IP to be locked, got %d", code)
+	}
+	if code := signIn(router, 4, "198.51.100.2"); code != http.StatusUnauthorized {
+		t.Fatalf("expected another client behind the proxy to be allowed, got %d", code)
+	}
+}
+
+func TestConfigureTrustedProxies_InvalidProxy(t *testing.T) {
+	router := gin.New()
+	getenv := func(string) string { return "not-an-ip" }
+	if err := configureTrustedProxies(router, getenv); err == nil {
+		t.Fatal("expected an error for an invalid proxy")
+	}
+}
@@ -3,41 +3,86 @@ package auth
 import (
 	"fmt"
 	"os"
+	"strconv"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 )
 
 type Claims struct {
-	UserID string `json:"userId"`
-	Email  string `json:"email"`
+	UserID    string `json:"userId"`
+	Email     string `json:"email"`
+	SessionID string `json:"sid,omitempty"`
+	// MFA is set when the session was started with a second factor
+	MFA bool `json:"mfa,omitempty"`
+	// Purpose is empty for access tokens. Tokens issued for another purpose,
+	// such as completing sign-in, are not accepted as access tokens.
+	Purpose string `json:"purpose,omitempty"`
 	jwt.RegisteredClaims
 }
 
-var secretKey []byte
+// PurposeMFA marks the token issued after a correct password to users who
+// must still enter a second factor.
+const PurposeMFA = "mfa"
+
+// MFATokenTTL is how long a user has to enter their second factor.
+const MFATokenTTL = 5 * time.Minute
+
+var (
+	keys    *KeySet
+	keysErr error
+)
+
+// AccessTokenTTL is how long an access token is valid. Clients renew it with
+// their session's refresh token, so revoking a session locks the device out
+// within this time.
+var AccessTokenTTL = 15 * time.Minute
 
 func init() {
-	secret := os.Getenv("JWT_SECRET")
-	if secret == "" {
-		secret = "your-secret-key-change-in-production"
+	_ = LoadKeys()
+}
+
+// LoadKeys loads the signing keys and access token lifetime from the
+// environment. It runs at package initialisation and should be called again
+// once the environment has been loaded from .env; the server must not start
+// if it fails.
+func LoadKeys() error {
+	if minutes, err := strconv.Atoi(os.Getenv("ACCESS_TOKEN_TTL_MINUTES")); err == nil && minutes > 0 {
+		AccessTokenTTL = time.Duration(minutes) * time.Minute
 	}
-	secretKey = []byte(secret)
+	keys, keysErr = KeySetFromEnv()
+	return keysErr
 }
 
-// GenerateToken creates a new JWT token for a user
-func GenerateToken(userID, email string) (string, error) {
+// CurrentKeySet returns the keys tokens are signed and verified with.
+func CurrentKeySet() (*KeySet, error) {
+	if keysErr != nil {
+		return nil, fmt.Errorf("signing keys unavailable: %w", keysErr)
+	}
+	return keys, nil
+}
+
+// GenerateToken creates a short-lived access token for a user's session. mfa
+// records that the session was started with a second factor.
+func GenerateToken(userID, email, sessionID string, mfa bool) (string, error) {
+	ks, err := CurrentKeySet()
+	if err != nil {
+		return "", err
+	}
+
 	claims := Claims{
-		UserID: userID,
-		Email:  email,
+		UserID:    userID,
+		Email:     email,
+		SessionID: sessionID,
+		MFA:       mfa,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(24 * time.Hour)),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(AccessTokenTTL)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			NotBefore: jwt.NewNumericDate(time.Now()),
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString(secretKey)
+	tokenString, err := ks.Sign(claims)
 	if err != nil {
 		return "", fmt.Errorf("failed to sign token: %w", err)
 	}
@@ -45,18 +90,64 @@ func GenerateToken(userID, email string) (string, error) {
 	return tokenString, nil
 }
 
-// ValidateToken verifies a JWT token and returns claims if valid
+// ValidateToken verifies an access token and returns claims if valid
 func ValidateToken(tokenString string) (*Claims, error) {
-	claims := &Claims{}
+	claims, err := parseToken(tokenString)
+	if err != nil {
+		return nil, err
+	}
+	if claims.Purpose != "" {
+		return nil, fmt.Errorf("not an access token")
+	}
+	return claims, nil
+}
 
-	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-		// Verify the signing method
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		return secretKey, nil
-	})
+// GenerateMFAToken creates the token a user exchanges, with their second
+// factor, for a session after entering a correct password.
+func GenerateMFAToken(userID string) (string, time.Time, error) {
+	ks, err := CurrentKeySet()
+	if err != nil {
+		return "", time.Time{}, err
+	}
 
+	expiresAt := time.Now().Add(MFATokenTTL)
+	claims := Claims{
+		UserID:  userID,
+		Purpose: PurposeMFA,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+	}
+
+	tokenString, err := ks.Sign(claims)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to sign token: %w", err)
+	}
+	return tokenString, expiresAt, nil
+}
+
+// ValidateMFAToken verifies a token from GenerateMFAToken and returns the
+// user ID it was issued to.
+func ValidateMFAToken(tokenString string) (string, error) {
+	claims, err := parseToken(tokenString)
+	if err != nil {
+		return "", err
+	}
+	if claims.Purpose != PurposeMFA || claims.UserID == "" {
+		return "", fmt.Errorf("not an MFA token")
+	}
+	return claims.UserID, nil
+}
+
+func parseToken(tokenString string) (*Claims, error) {
+	ks, err := CurrentKeySet()
+	if err != nil {
+		return nil, err
+	}
+
+	claims := &Claims{}
+	token, err := ks.Parse(tokenString, claims)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse token: %w", err)
 	}
@@ -0,0 +1,229 @@
+package auth
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const minRSAKeyBits = 2048
+
+// signingKey is a key tokens are signed or verified with. private is nil for
+// keys kept only to verify tokens issued before a rotation.
+type signingKey struct {
+	id      string
+	method  jwt.SigningMethod
+	private any
+	public  any
+}
+
+// KeySet holds the keys access tokens are signed and verified with, identified
+// by the kid token header. Several keys can be loaded at once so tokens signed
+// with a retiring key stay valid until they expire.
+type KeySet struct {
+	active    *signingKey
+	keys      map[string]*signingKey
+	ephemeral bool
+}
+
+// LoadKeySet loads every *.pem file in dir, using the file name without the
+// extension as the key ID. Files may hold an Ed25519 or RSA private key (PKCS#8
+// or PKCS#1), or a public key (PKIX) that is only used for verification.
+// Tokens are signed with activeKID, or when it is empty with the private key
+// whose ID sorts last, so naming keys by date rotates to the newest.
+func LoadKeySet(dir, activeKID string) (*KeySet, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.pem"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list signing keys: %w", err)
+	}
+	sort.Strings(paths)
+
+	ks := &KeySet{keys: map[string]*signingKey{}}
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read signing key: %w", err)
+		}
+		id := strings.TrimSuffix(filepath.Base(path), ".pem")
+		key, err := parseSigningKey(id, data)
+		if err != nil {
+			return nil, fmt.Errorf("signing key %s: %w", id, err)
+		}
+		ks.keys[id] = key
+		if key.private != nil && activeKID == "" {
+			ks.active = key
+		}
+	}
+
+	if activeKID != "" {
+		key, ok := ks.keys[activeKID]
+		if !ok || key.private == nil {
+			return nil, fmt.Errorf("no private key with ID %q in %s", activeKID, dir)
+		}
+		ks.active = key
+	}
+	if ks.active == nil {
+		return nil, fmt.Errorf("no private signing key in %s", dir)
+	}
+	return ks, nil
+}
+
+// NewDevelopmentKeySet returns a key set with a freshly generated Ed25519 key.
+// Tokens it signs stop validating when the process restarts.
+func NewDevelopmentKeySet() (*KeySet, error) {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate development signing key: %w", err)
+	}
+	key := &signingKey{id: "development", method: jwt.SigningMethodEdDSA, private: private, public: public}
+	return &KeySet{active: key, keys: map[string]*signingKey{key.id: key}, ephemeral: true}, nil
+}
+
+// KeySetFromEnv loads the key set from JWT_KEYS_DIR, signing with
+// JWT_ACTIVE_KID when set. Without JWT_KEYS_DIR a development key is
+// generated, except in production where it is an error.
+func KeySetFromEnv() (*KeySet, error) {
+	dir := strings.TrimSpace(os.Getenv("JWT_KEYS_DIR"))
+	if dir == "" {
+		if os.Getenv("ENV") == "production" {
+			return nil, errors.New("JWT_KEYS_DIR must be set in production")
+		}
+		return NewDevelopmentKeySet()
+	}
+	return LoadKeySet(dir, strings.TrimSpace(os.Getenv("JWT_ACTIVE_KID")))
+}
+
+func parseSigningKey(id string, data []byte) (*signingKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+
+	var parsed any
+	var err error
+	switch block.Type {
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported PEM block %q", block.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse key: %w", err)
+	}
+
+	key := &signingKey{id: id}
+	switch k := parsed.(type) {
+	case ed25519.PrivateKey:
+		key.method, key.private, key.public = jwt.SigningMethodEdDSA, k, k.Public()
+	case ed25519.PublicKey:
+		key.method, key.public = jwt.SigningMethodEdDSA, k
+	case *rsa.PrivateKey:
+		key.method, key.private, key.public = jwt.SigningMethodRS256, k, &k.PublicKey
+	case *rsa.PublicKey:
+		key.method, key.public = jwt.SigningMethodRS256, k
+	default:
+		return nil, fmt.Errorf("unsupported key type %T", parsed)
+	}
+	if pub, ok := key.public.(*rsa.PublicKey); ok && pub.N.BitLen() < minRSAKeyBits {
+		return nil, fmt.Errorf("RSA keys must be at least %d bits", minRSAKeyBits)
+	}
+	return key, nil
+}
+
+// ActiveKeyID returns the ID of the key new tokens are signed with.
+func (ks *KeySet) ActiveKeyID() string {
+	return ks.active.id
+}
+
+// Ephemeral reports whether the key set is a generated development key.
+func (ks *KeySet) Ephemeral() bool {
+	return ks.ephemeral
+}
+
+// Sign signs claims with the active key.
+func (ks *KeySet) Sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(ks.active.method, claims)
+	token.Header["kid"] = ks.active.id
+	return token.SignedString(ks.active.private)
+}
+
+// Parse verifies a token signed with any key in the set and fills claims.
+func (ks *KeySet) Parse(tokenString string, claims jwt.Claims) (*jwt.Token, error) {
+	return jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		// Verify the signing method. The key, not the token, decides the
+		// algorithm, so an RSA key cannot be used as an HMAC secret.
+		switch token.Method.(type) {
+		case *jwt.SigningMethodEd25519, *jwt.SigningMethodRSA:
+		default:
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		kid, _ := token.Header["kid"].(string)
+		key, ok := ks.keys[kid]
+		if !ok {
+			return nil, fmt.Errorf("unknown signing key %q", kid)
+		}
+		if token.Method.Alg() != key.method.Alg() {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return key.public, nil
+	})
+}
+
+// JWK is a public key in JSON Web Key format (RFC 7517).
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	Curve     string `json:"crv,omitempty"`
+	X         string `json:"x,omitempty"`
+	N         string `json:"n,omitempty"`
+	E         string `json:"e,omitempty"`
+}
+
+// JWKS is a JSON Web Key Set.
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS returns the public half of every key in the set, ordered by key ID.
+func (ks *KeySet) JWKS() JWKS {
+	ids := make([]string, 0, len(ks.keys))
+	for id := range ks.keys {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	set := JWKS{Keys: make([]JWK, 0, len(ids))}
+	for _, id := range ids {
+		key := ks.keys[id]
+		jwk := JWK{KeyID: id, Use: "sig", Algorithm: key.method.Alg()}
+		switch pub := key.public.(type) {
+		case ed25519.PublicKey:
+			jwk.KeyType, jwk.Curve = "OKP", "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+		case *rsa.PublicKey:
+			jwk.KeyType = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+	return set
+}
@@ -0,0 +1,201 @@
+package auth
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// writeKey writes key to dir/<kid>.pem as PKCS#8, or PKIX for public keys.
+func writeKey(t *testing.T, dir, kid string, key any) {
+	t.Helper()
+	var block *pem.Block
+	switch key.(type) {
+	case ed25519.PublicKey, *rsa.PublicKey:
+		der, err := x509.MarshalPKIXPublicKey(key)
+		if err != nil {
+			t.Fatalf("failed to marshal public key: %v", err)
+		}
+		block = &pem.Block{Type: "PUBLIC KEY", Bytes: der}
+	default:
+		der, err := x509.MarshalPKCS8PrivateKey(key)
+		if err != nil {
+			t.Fatalf("failed to marshal private key: %v", err)
+		}
+		block = &pem.Block{Type: "PRIVATE KEY", Bytes: der}
+	}
+	if err := os.WriteFile(filepath.Join(dir, kid+".pem"), pem.EncodeToMemory(block), 0o600); err != nil {
+		t.Fatalf("failed to write key: %v", err)
+	}
+}
+
+func signTestToken(t *testing.T, ks *KeySet) string {
+	t.Helper()
+	token, err := ks.Sign(Claims{UserID: "user-1"})
+	if err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
+	return token
+}
+
+func TestLoadKeySet_Rotation(t *testing.T) {
+	dir := t.TempDir()
+	oldPublic, oldPrivate, _ := ed25519.GenerateKey(rand.Reader)
+	writeKey(t, dir, "2026-01", oldPrivate)
+
+	ks, err := LoadKeySet(dir, "")
+	if err != nil {
+		t.Fatalf("LoadKeySet failed: %v", err)
+	}
+	oldToken := signTestToken(t, ks)
+
+	// Adding a newer key makes it the signing key; the old one still verifies
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate RSA key: %v", err)
+	}
+	writeKey(t, dir, "2026-07", rsaKey)
+	ks, err = LoadKeySet(dir, "")
+	if err != nil {
+		t.Fatalf("LoadKeySet failed: %v", err)
+	}
+	if ks.ActiveKeyID() != "2026-07" {
+		t.Fatalf("expected newest key to be active, got %q", ks.ActiveKeyID())
+	}
+	newToken := signTestToken(t, ks)
+	parsed, err := ks.Parse(newToken, &Claims{})
+	if err != nil {
+		t.Fatalf("Parse failed for new token: %v", err)
+	}
+	if parsed.Header["kid"] != "2026-07" || parsed.Method.Alg() != "RS256" {
+		t.Fatalf("unexpected header %v", parsed.Header)
+	}
+
+	// Once retired, only the public half of the old key is kept
+	writeKey(t, dir, "2026-01", oldPublic)
+	ks, err = LoadKeySet(dir, "")
+	if err != nil {
+		t.Fatalf("LoadKeySet failed: %v", err)
+	}
+	if _, err := ks.Parse(oldToken, &Claims{}); err != nil {
+		t.Fatalf("Parse failed for token signed with retired key: %v", err)
+	}
+	if _, err := LoadKeySet(dir, "2026-01"); err == nil {
+		t.Fatal("expected error when the active key has no private half")
+	}
+
+	// Tokens from a key that has been removed are rejected
+	if err := os.Remove(filepath.Join(dir, "2026-01.pem")); err != nil {
+		t.Fatalf("failed to remove key: %v", err)
+	}
+	ks, _ = LoadKeySet(dir, "")
+	if _, err := ks.Parse(oldToken, &Claims{}); err == nil {
+		t.Fatal("expected token signed with removed key to be rejected")
+	}
+}
+
+func TestKeySet_RejectsMismatchedAlgorithm(t *testing.T) {
+	dir := t.TempDir()
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate RSA key: %v", err)
+	}
+	writeKey(t, dir, "rsa", rsaKey)
+	ks, err := LoadKeySet(dir, "")
+	if err != nil {
+		t.Fatalf("LoadKeySet failed: %v", err)
+	}
+
+	// An HMAC token keyed with the public key must not verify
+	pub, _ := x509.MarshalPKIXPublicKey(&rsaKey.PublicKey)
+	forged := jwt.NewWithClaims(jwt.SigningMethodHS256, Claims{UserID: "attacker"})
+	forged.Header["kid"] = "rsa"
+	tokenString, err := forged.SignedString(pub)
+	if err != nil {
+		t.Fatalf("failed to sign forged token: %v", err)
+	}
+	if _, err := ks.Parse(tokenString, &Claims{}); err == nil {
+		t.Fatal("expected HS256 token to be rejected")
+	}
+}
+
+func TestLoadKeySet_RejectsWeakAndMissingKeys(t *testing.T) {
+	if _, err := LoadKeySet(t.TempDir(), ""); err == nil {
+		t.Fatal("expected error for empty key directory")
+	}
+
+	dir := t.TempDir()
+	weak, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatalf("failed to generate RSA key: %v", err)
+	}
+	writeKey(t, dir, "weak", weak)
+	if _, err := LoadKeySet(dir, ""); err == nil {
+		t.Fatal("expected error for 1024-bit RSA key")
+	}
+}
+
+func TestKeySetFromEnv_RequiresKeysInProduction(t *testing.T) {
+	t.Setenv("JWT_KEYS_DIR", "")
+	t.Setenv("ENV", "production")
+	if _, err := KeySetFromEnv(); err == nil {
+		t.Fatal("expected error without JWT_KEYS_DIR in production")
+	}
+
+	t.Setenv("ENV", "development")
+	ks, err := KeySetFromEnv()
+	if err != nil {
+		t.Fatalf("KeySetFromEnv failed: %v", err)
+	}
+	if !ks.Ephemeral() {
+		t.Fatal("expected a development key outside production")
+	}
+}
+
+func TestKeySet_JWKS(t *testing.T) {
+	dir := t.TempDir()
+	edPublic, edPrivate, _ := ed25519.GenerateKey(rand.Reader)
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate RSA key: %v", err)
+	}
+	writeKey(t, dir, "a-ed25519", edPrivate)
+	writeKey(t, dir, "b-rsa", &rsaKey.PublicKey)
+
+	ks, err := LoadKeySet(dir, "a-ed25519")
+	if err != nil {
+		t.Fatalf("LoadKeySet failed: %v", err)
+	}
+	set := ks.JWKS()
+	if len(set.Keys) != 2 {
+		t.Fatalf("expected 2 keys, got %d", len(set.Keys))
+	}
+
+	ed := set.Keys[0]
+	if ed.KeyID != "a-ed25519" || ed.KeyType != "OKP" || ed.Curve != "Ed25519" || ed.Algorithm != "EdDSA" || ed.Use != "sig" {
+		t.Fatalf("unexpected Ed25519 JWK %+v", ed)
+	}
+	if ed.X != base64URL(edPublic) {
+		t.Fatalf("unexpected Ed25519 x %q", ed.X)
+	}
+
+	r := set.Keys[1]
+	if r.KeyID != "b-rsa" || r.KeyType != "RSA" || r.Algorithm != "RS256" || r.E != "AQAB" {
+		t.Fatalf("unexpected RSA JWK %+v", r)
+	}
+	if r.N != base64URL(rsaKey.N.Bytes()) {
+		t.Fatal("unexpected RSA modulus")
+	}
+}
+
+func base64URL(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
@@ -0,0 +1,478 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	// oidcMetadataTTL is how long a provider's discovery document is cached.
+	oidcMetadataTTL = time.Hour
+	// oidcKeyRefreshInterval limits how often an unknown key ID triggers a
+	// JWKS refetch, so forged tokens cannot hammer the provider.
+	oidcKeyRefreshInterval = time.Minute
+	oidcClockSkew          = time.Minute
+	maxOIDCResponseBytes   = 1 << 20
+)
+
+// ErrInvalidIDToken is returned when a provider's ID token fails verification.
+var ErrInvalidIDToken = errors.New("invalid id token")
+
+// OIDCConfig configures an OpenID Connect sign-in provider.
+type OIDCConfig struct {
+	// Name identifies the provider in routes and is stored as the user's
+	// oauth_provider.
+	Name     string
+	Issuer   string
+	ClientID string
+	// ClientSecret is sent with token requests. ClientSecretFunc, when set,
+	// produces it per request instead; Apple requires a short-lived signed JWT.
+	ClientSecret     string
+	ClientSecretFunc func() (string, error)
+	RedirectURL      string
+	// Scopes defaults to openid, email and profile.
+	Scopes []string
+	// ResponseMode is sent as response_mode. With "form_post" the provider
+	// POSTs the authorization response to the callback.
+	ResponseMode string
+	HTTPClient   *http.Client
+}
+
+// OIDCProvider signs users in with an OpenID Connect provider using the
+// authorization code flow with PKCE. Endpoints and signing keys come from the
+// issuer's discovery document and are cached.
+type OIDCProvider struct {
+	config OIDCConfig
+	client *http.Client
+
+	mu              sync.Mutex
+	metadata        *oidcMetadata
+	metadataFetched time.Time
+	keys            map[string]any
+	keysFetched     time.Time
+}
+
+type oidcMetadata struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// NewOIDCProvider validates config and returns a provider. Nothing is fetched
+// until the first sign-in.
+func NewOIDCProvider(config OIDCConfig) (*OIDCProvider, error) {
+	if config.Name == "" || config.Issuer == "" || config.ClientID == "" || config.RedirectURL == "" {
+		return nil, errors.New("oidc provider requires a name, issuer, client ID and redirect URL")
+	}
+	if len(config.Scopes) == 0 {
+		config.Scopes = []string{"openid", "email", "profile"}
+	}
+	hasOpenID := false
+	for _, scope := range config.Scopes {
+		hasOpenID = hasOpenID || scope == "openid"
+	}
+	if !hasOpenID {
+		config.Scopes = append([]string{"openid"}, config.Scopes...)
+	}
+
+	client := config.HTTPClient
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &OIDCProvider{config: config, client: client}, nil
+}
+
+// Name returns the provider's name.
+func (p *OIDCProvider) Name() string {
+	return p.config.Name
+}
+
+// UsesFormPost reports whether the provider POSTs its authorization response.
+func (p *OIDCProvider) UsesFormPost() bool {
+	return p.config.ResponseMode == "form_post"
+}
+
+// OIDCAuthRequest holds the secrets generated when sign-in starts, which the
+// browser keeps until the callback: state binds the callback to the browser,
+// nonce binds the ID token to this request, and the PKCE code verifier binds
+// the authorization code to it.
+type OIDCAuthRequest struct {
+	State        string `json:"state"`
+	Nonce        string `json:"nonce"`
+	CodeVerifier string `json:"verifier"`
+}
+
+// NewOIDCAuthRequest generates a fresh state, nonce and code verifier.
+func NewOIDCAuthRequest() (OIDCAuthRequest, error) {
+	var values [3]string
+	for i := range values {
+		b := make([]byte, 32)
+		if _, err := rand.Read(b); err != nil {
+			return OIDCAuthRequest{}, fmt.Errorf("failed to generate oidc request: %w", err)
+		}
+		values[i] = base64.RawURLEncoding.EncodeToString(b)
+	}
+	return OIDCAuthRequest{State: values[0], Nonce: values[1], CodeVerifier: values[2]}, nil
+}
+
+// OIDCIdentity is the user identity asserted by a verified ID token.
+type OIDCIdentity struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+	Picture       string
+}
+
+// AuthCodeURL returns the URL to send the browser to for sign-in.
+func (p *OIDCProvider) AuthCodeURL(ctx context.Context, req OIDCAuthRequest) (string, error) {
+	metadata, err := p.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	challenge := sha256.Sum256([]byte(req.CodeVerifier))
+	q := url.Values{}
+	q.Set("response_type", "code")
+	q.Set("client_id", p.config.ClientID)
+	q.Set("redirect_uri", p.config.RedirectURL)
+	q.Set("scope", strings.Join(p.config.Scopes, " "))
+	q.Set("state", req.State)
+	q.Set("nonce", req.Nonce)
+	q.Set("code_challenge", base64.RawURLEncoding.EncodeToString(challenge[:]))
+	q.Set("code_challenge_method", "S256")
+	if p.config.ResponseMode != "" {
+		q.Set("response_mode", p.config.ResponseMode)
+	}
+
+	separator := "?"
+	if strings.Contains(metadata.AuthorizationEndpoint, "?") {
+		separator = "&"
+	}
+	return metadata.AuthorizationEndpoint + separator + q.Encode(), nil
+}
+
+// Exchange redeems an authorization code and returns the identity from the
+// verified ID token.
+func (p *OIDCProvider) Exchange(ctx context.Context, code string, req OIDCAuthRequest) (*OIDCIdentity, error) {
+	if code == "" {
+		return nil, errors.New("authorization code missing")
+	}
+	metadata, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	secret := p.config.ClientSecret
+	if p.config.ClientSecretFunc != nil {
+		if secret, err = p.config.ClientSecretFunc(); err != nil {
+			return nil, fmt.Errorf("failed to create client secret: %w", err)
+		}
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", p.config.RedirectURL)
+	form.Set("client_id", p.config.ClientID)
+	form.Set("code_verifier", req.CodeVerifier)
+	if secret != "" {
+		form.Set("client_secret", secret)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, metadata.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create token request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	httpReq.Header.Set("Accept", "application/json")
+
+	resp, err := p.client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to exchange code: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("token exchange failed: %s", resp.Status)
+	}
+
+	var tokens struct {
+		IDToken string `json:"id_token"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxOIDCResponseBytes)).Decode(&tokens); err != nil {
+		return nil, fmt.Errorf("failed to decode token response: %w", err)
+	}
+	if tokens.IDToken == "" {
+		return nil, errors.New("token response has no id_token")
+	}
+	return p.VerifyIDToken(ctx, tokens.IDToken, req.Nonce)
+}
+
+// idTokenClaims are the ID token claims used for sign-in.
+type idTokenClaims struct {
+	Nonce           string    `json:"nonce"`
+	AuthorizedParty string    `json:"azp"`
+	Email           string    `json:"email"`
+	EmailVerified   claimBool `json:"email_verified"`
+	Name            string    `json:"name"`
+	Picture         string    `json:"picture"`
+	jwt.RegisteredClaims
+}
+
+// claimBool decodes a boolean claim that some providers, notably Apple, send
+// as the string "true" or "false".
+type claimBool bool
+
+func (b *claimBool) UnmarshalJSON(data []byte) error {
+	var v any
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	switch v := v.(type) {
+	case bool:
+		*b = claimBool(v)
+	case string:
+		parsed, _ := strconv.ParseBool(v)
+		*b = claimBool(parsed)
+	default:
+		*b = false
+	}
+	return nil
+}
+
+// VerifyIDToken checks an ID token's signature against the provider's
+// published keys, its issuer, audience, expiry and nonce.
+func (p *OIDCProvider) VerifyIDToken(ctx context.Context, rawIDToken, nonce string) (*OIDCIdentity, error) {
+	metadata, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	claims := &idTokenClaims{}
+	_, err = jwt.ParseWithClaims(rawIDToken, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		key, err := p.verificationKey(ctx, kid)
+		if err != nil {
+			return nil, err
+		}
+		// The key, not the token, decides the algorithm
+		switch key.(type) {
+		case *rsa.PublicKey:
+			if token.Method.Alg() != jwt.SigningMethodRS256.Alg() {
+				return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+			}
+		case *ecdsa.PublicKey:
+			if token.Method.Alg() != jwt.SigningMethodES256.Alg() {
+				return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+			}
+		}
+		return key, nil
+	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Alg(), jwt.SigningMethodES256.Alg()}),
+		jwt.WithIssuer(metadata.Issuer),
+		jwt.WithAudience(p.config.ClientID),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(oidcClockSkew),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidIDToken, err)
+	}
+
+	if claims.ExpiresAt == nil {
+		return nil, fmt.Errorf("%w: missing exp", ErrInvalidIDToken)
+	}
+	if claims.Subject == "" {
+		return nil, fmt.Errorf("%w: missing sub", ErrInvalidIDToken)
+	}
+	if len(claims.Audience) > 1 && claims.AuthorizedParty != p.config.ClientID {
+		return nil, fmt.Errorf("%w: unexpected azp", ErrInvalidIDToken)
+	}
+	if nonce == "" || subtle.ConstantTimeCompare([]byte(claims.Nonce), []byte(nonce)) != 1 {
+		return nil, fmt.Errorf("%w: nonce mismatch", ErrInvalidIDToken)
+	}
+
+	return &OIDCIdentity{
+		Subject:       claims.Subject,
+		Email:         strings.TrimSpace(claims.Email),
+		EmailVerified: bool(claims.EmailVerified),
+		Name:          strings.TrimSpace(claims.Name),
+		Picture:       claims.Picture,
+	}, nil
+}
+
+// discover returns the issuer's discovery document, fetching it when the
+// cached copy is missing or stale.
+func (p *OIDCProvider) discover(ctx context.Context) (*oidcMetadata, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.metadata != nil && time.Since(p.metadataFetched) < oidcMetadataTTL {
+		return p.metadata, nil
+	}
+
+	var metadata oidcMetadata
+	discoveryURL := strings.TrimSuffix(p.config.Issuer, "/") + "/.well-known/openid-configuration"
+	if err := p.getJSON(ctx, discoveryURL, &metadata); err != nil {
+		return nil, fmt.Errorf("failed to fetch %s discovery document: %w", p.config.Name, err)
+	}
+	// The document must describe the configured issuer (OpenID Connect
+	// Discovery 1.0, section 4.3)
+	if metadata.Issuer != p.config.Issuer {
+		return nil, fmt.Errorf("%s discovery document is for issuer %q, expected %q", p.config.Name, metadata.Issuer, p.config.Issuer)
+	}
+	if metadata.AuthorizationEndpoint == "" || metadata.TokenEndpoint == "" || metadata.JWKSURI == "" {
+		return nil, fmt.Errorf("%s discovery document is missing endpoints", p.config.Name)
+	}
+
+	p.metadata = &metadata
+	p.metadataFetched = time.Now()
+	return p.metadata, nil
+}
+
+// verificationKey returns the provider key with the given ID, refetching the
+// JWKS when the key is unknown so provider key rotations are picked up.
+func (p *OIDCProvider) verificationKey(ctx context.Context, kid string) (any, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	key, ok := p.lookupKey(kid)
+	if ok {
+		return key, nil
+	}
+	if p.keys != nil && time.Since(p.keysFetched) < oidcKeyRefreshInterval {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+
+	var set struct {
+		Keys []json.RawMessage `json:"keys"`
+	}
+	if err := p.getJSON(ctx, p.metadata.JWKSURI, &set); err != nil {
+		return nil, fmt.Errorf("failed to fetch %s signing keys: %w", p.config.Name, err)
+	}
+	keys := map[string]any{}
+	for _, raw := range set.Keys {
+		id, key, err := parseJWK(raw)
+		if err != nil {
+			// Skip keys of types we never accept rather than failing the set
+			continue
+		}
+		keys[id] = key
+	}
+	p.keys = keys
+	p.keysFetched = time.Now()
+
+	if key, ok := p.lookupKey(kid); ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("unknown signing key %q", kid)
+}
+
+// lookupKey finds a cached key. Tokens without a kid are accepted only when
+// the provider publishes a single key.
+func (p *OIDCProvider) lookupKey(kid string) (any, bool) {
+	if kid == "" && len(p.keys) == 1 {
+		for _, key := range p.keys {
+			return key, true
+		}
+	}
+	key, ok := p.keys[kid]
+	return key, ok
+}
+
+func (p *OIDCProvider) getJSON(ctx context.Context, rawURL string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return json.NewDecoder(io.LimitReader(resp.Body, maxOIDCResponseBytes)).Decode(v)
+}
+
+// parseJWK decodes an RSA or P-256 signing key from a JWKS entry.
+func parseJWK(raw json.RawMessage) (string, any, error) {
+	var jwk struct {
+		KeyType string `json:"kty"`
+		KeyID   string `json:"kid"`
+		Use     string `json:"use"`
+		Curve   string `json:"crv"`
+		N       string `json:"n"`
+		E       string `json:"e"`
+		X       string `json:"x"`
+		Y       string `json:"y"`
+	}
+	if err := json.Unmarshal(raw, &jwk); err != nil {
+		return "", nil, err
+	}
+	if jwk.Use != "" && jwk.Use != "sig" {
+		return "", nil, fmt.Errorf("key %q is not a signing key", jwk.KeyID)
+	}
+
+	decode := func(s string) (*big.Int, error) {
+		b, err := base64.RawURLEncoding.DecodeString(s)
+		if err != nil || len(b) == 0 {
+			return nil, fmt.Errorf("invalid key parameter in %q", jwk.KeyID)
+		}
+		return new(big.Int).SetBytes(b), nil
+	}
+
+	switch jwk.KeyType {
+	case "RSA":
+		n, err := decode(jwk.N)
+		if err != nil {
+			return "", nil, err
+		}
+		e, err := decode(jwk.E)
+		if err != nil || !e.IsInt64() {
+			return "", nil, fmt.Errorf("invalid RSA exponent in %q", jwk.KeyID)
+		}
+		if n.BitLen() < minRSAKeyBits {
+			return "", nil, fmt.Errorf("RSA key %q is shorter than %d bits", jwk.KeyID, minRSAKeyBits)
+		}
+		return jwk.KeyID, &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		if jwk.Curve != "P-256" {
+			return "", nil, fmt.Errorf("unsupported curve %q", jwk.Curve)
+		}
+		x, err := decode(jwk.X)
+		if err != nil {
+			return "", nil, err
+		}
+		y, err := decode(jwk.Y)
+		if err != nil {
+			return "", nil, err
+		}
+		if !elliptic.P256().IsOnCurve(x, y) {
+			return "", nil, fmt.Errorf("EC key %q is not on P-256", jwk.KeyID)
+		}
+		return jwk.KeyID, &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}, nil
+	default:
+		return "", nil, fmt.Errorf("unsupported key type %q", jwk.KeyType)
+	}
+}
@@ -0,0 +1,208 @@
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	googleIssuer = "https://accounts.google.com"
+	appleIssuer  = "https://appleid.apple.com"
+	// appleClientSecretTTL is how long each generated Apple client secret is
+	// valid; one is signed per token request.
+	appleClientSecretTTL = 5 * time.Minute
+)
+
+var oidcProviderName = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*$`)
+
+// OIDCProviders is the set of configured sign-in providers, keyed by name.
+type OIDCProviders struct {
+	providers map[string]*OIDCProvider
+}
+
+// NewOIDCProviders returns a registry of the given providers.
+func NewOIDCProviders(providers ...*OIDCProvider) *OIDCProviders {
+	r := &OIDCProviders{providers: map[string]*OIDCProvider{}}
+	for _, p := range providers {
+		r.providers[p.Name()] = p
+	}
+	return r
+}
+
+// Get returns the provider with the given name.
+func (r *OIDCProviders) Get(name string) (*OIDCProvider, bool) {
+	p, ok := r.providers[name]
+	return p, ok
+}
+
+// Names returns the configured provider names in order.
+func (r *OIDCProviders) Names() []string {
+	names := make([]string, 0, len(r.providers))
+	for name := range r.providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// OIDCProvidersFromEnv configures the sign-in providers from the environment.
+// Google is enabled by GOOGLE_OAUTH_ID and Apple by APPLE_OAUTH_ID. Further
+// OpenID Connect providers are listed in OIDC_PROVIDERS and configured with
+// OIDC_<NAME>_ISSUER, _CLIENT_ID, _CLIENT_SECRET, _REDIRECT and _SCOPES.
+// A provider that is only partly configured is an error.
+func OIDCProvidersFromEnv() (*OIDCProviders, error) {
+	var providers []*OIDCProvider
+
+	if clientID := env("GOOGLE_OAUTH_ID"); clientID != "" {
+		secret, redirect := env("GOOGLE_OAUTH_SECRET"), env("GOOGLE_OAUTH_REDIRECT")
+		if secret == "" || redirect == "" {
+			return nil, errors.New("GOOGLE_OAUTH_SECRET and GOOGLE_OAUTH_REDIRECT must be set with GOOGLE_OAUTH_ID")
+		}
+		p, err := NewOIDCProvider(OIDCConfig{
+			Name:         "google",
+			Issuer:       envOr("GOOGLE_OIDC_ISSUER", googleIssuer),
+			ClientID:     clientID,
+			ClientSecret: secret,
+			RedirectURL:  redirect,
+		})
+		if err != nil {
+			return nil, err
+		}
+		providers = append(providers, p)
+	}
+
+	if clientID := env("APPLE_OAUTH_ID"); clientID != "" {
+		p, err := appleProviderFromEnv(clientID)
+		if err != nil {
+			return nil, err
+		}
+		providers = append(providers, p)
+	}
+
+	for _, name := range strings.Split(env("OIDC_PROVIDERS"), ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if !oidcProviderName.MatchString(name) || name == "google" || name == "apple" {
+			return nil, fmt.Errorf("invalid OIDC provider name %q", name)
+		}
+		prefix := "OIDC_" + strings.ToUpper(strings.ReplaceAll(name, "-", "_")) + "_"
+		p, err := NewOIDCProvider(OIDCConfig{
+			Name:         name,
+			Issuer:       env(prefix + "ISSUER"),
+			ClientID:     env(prefix + "CLIENT_ID"),
+			ClientSecret: env(prefix + "CLIENT_SECRET"),
+			RedirectURL:  env(prefix + "REDIRECT"),
+			Scopes:       strings.Fields(env(prefix + "SCOPES")),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("%sISSUER, %sCLIENT_ID and %sREDIRECT must be set: %w", prefix, prefix, prefix, err)
+		}
+		providers = append(providers, p)
+	}
+
+	return NewOIDCProviders(providers...), nil
+}
+
+// appleProviderFromEnv configures Sign in with Apple. Apple's client secret
+// is a JWT signed with a key from the developer account (APPLE_KEY_ID,
+// APPLE_PRIVATE_KEY_FILE) on behalf of the team (APPLE_TEAM_ID). Apple only
+// returns the email and name scopes with response_mode=form_post.
+func appleProviderFromEnv(clientID string) (*OIDCProvider, error) {
+	teamID, keyID, keyFile, redirect := env("APPLE_TEAM_ID"), env("APPLE_KEY_ID"), env("APPLE_PRIVATE_KEY_FILE"), env("APPLE_OAUTH_REDIRECT")
+	if teamID == "" || keyID == "" || keyFile == "" || redirect == "" {
+		return nil, errors.New("APPLE_TEAM_ID, APPLE_KEY_ID, APPLE_PRIVATE_KEY_FILE and APPLE_OAUTH_REDIRECT must be set with APPLE_OAUTH_ID")
+	}
+	key, err := loadApplePrivateKey(keyFile)
+	if err != nil {
+		return nil, err
+	}
+	issuer := envOr("APPLE_OIDC_ISSUER", appleIssuer)
+	return NewOIDCProvider(OIDCConfig{
+		Name:             "apple",
+		Issuer:           issuer,
+		ClientID:         clientID,
+		ClientSecretFunc: appleClientSecret(teamID, keyID, clientID, issuer, key),
+		RedirectURL:      redirect,
+		Scopes:           []string{"openid", "email", "name"},
+		ResponseMode:     "form_post",
+	})
+}
+
+// loadApplePrivateKey reads the .p8 (PKCS#8 P-256) key Apple issues for
+// signing client secrets.
+func loadApplePrivateKey(path string) (*ecdsa.PrivateKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Apple private key: %w", err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found in Apple private key")
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse Apple private key: %w", err)
+	}
+	key, ok := parsed.(*ecdsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("apple private key must be an EC key, got %T", parsed)
+	}
+	return key, nil
+}
+
+// appleClientSecret returns a function signing the ES256 client secret JWT
+// Apple's token endpoint requires.
+func appleClientSecret(teamID, keyID, clientID, audience string, key *ecdsa.PrivateKey) func() (string, error) {
+	return func() (string, error) {
+		now := time.Now()
+		token := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.RegisteredClaims{
+			Issuer:    teamID,
+			Subject:   clientID,
+			Audience:  jwt.ClaimStrings{audience},
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(appleClientSecretTTL)),
+		})
+		token.Header["kid"] = keyID
+		return token.SignedString(key)
+	}
+}
+
+// AppleUserName returns the name from the user form field Apple posts to the
+// callback. Apple sends it only the first time a user authorizes the app and
+// never includes it in the ID token.
+func AppleUserName(user string) string {
+	var parsed struct {
+		Name struct {
+			FirstName string `json:"firstName"`
+			LastName  string `json:"lastName"`
+		} `json:"name"`
+	}
+	if user == "" || json.Unmarshal([]byte(user), &parsed) != nil {
+		return ""
+	}
+	return strings.TrimSpace(parsed.Name.FirstName + " " + parsed.Name.LastName)
+}
+
+func env(name string) string {
+	return strings.TrimSpace(os.Getenv(name))
+}
+
+func envOr(name, fallback string) string {
+	if v := env(name); v != "" {
+		return v
+	}
+	return fallback
+}
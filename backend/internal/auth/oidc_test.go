@@ -0,0 +1,284 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"errors"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"gaspeep/backend/internal/auth/oidctest"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func newTestOIDCProvider(t *testing.T, issuer *oidctest.Issuer, responseMode string) *OIDCProvider {
+	t.Helper()
+	p, err := NewOIDCProvider(OIDCConfig{
+		Name:         "test",
+		Issuer:       issuer.URL,
+		ClientID:     issuer.ClientID,
+		ClientSecret: issuer.ClientSecret,
+		RedirectURL:  "https://app.example.com/api/auth/oauth/test/callback",
+		ResponseMode: responseMode,
+	})
+	if err != nil {
+		t.Fatalf("NewOIDCProvider failed: %v", err)
+	}
+	return p
+}
+
+func TestOIDCProvider_SignIn(t *testing.T) {
+	issuer := oidctest.NewIssuer(t, "client-id", "client-secret")
+	issuer.SetIdentity(oidctest.Identity{Subject: "sub-1", Email: "user@example.com", EmailVerified: true, Name: "User", Picture: "https://example.com/pic.jpg"})
+	p := newTestOIDCProvider(t, issuer, "")
+	ctx := context.Background()
+
+	req, err := NewOIDCAuthRequest()
+	if err != nil {
+		t.Fatalf("NewOIDCAuthRequest failed: %v", err)
+	}
+	authURL, err := p.AuthCodeURL(ctx, req)
+	if err != nil {
+		t.Fatalf("AuthCodeURL failed: %v", err)
+	}
+	parsed, _ := url.Parse(authURL)
+	q := parsed.Query()
+	if !strings.HasPrefix(authURL, issuer.URL+"/authorize?") {
+		t.Fatalf("unexpected authorization endpoint: %s", authURL)
+	}
+	if q.Get("state") != req.State || q.Get("nonce") != req.Nonce || q.Get("code_challenge_method") != "S256" {
+		t.Fatalf("unexpected authorization parameters: %v", q)
+	}
+	if q.Get("code_challenge") == req.CodeVerifier || q.Get("code_challenge") == "" {
+		t.Fatal("expected the code challenge to be derived from the verifier")
+	}
+	if q.Get("scope") != "openid email profile" {
+		t.Fatalf("unexpected scope %q", q.Get("scope"))
+	}
+
+	params := issuer.Authorize(t, authURL)
+	identity, err := p.Exchange(ctx, params.Get("code"), req)
+	if err != nil {
+		t.Fatalf("Exchange failed: %v", err)
+	}
+	want := OIDCIdentity{Subject: "sub-1", Email: "user@example.com", EmailVerified: true, Name: "User", Picture: "https://example.com/pic.jpg"}
+	if *identity != want {
+		t.Fatalf("unexpected identity %+v", identity)
+	}
+
+	// Codes are single use
+	if _, err := p.Exchange(ctx, params.Get("code"), req); err == nil {
+		t.Fatal("expected a replayed code to be rejected")
+	}
+
+	// A code cannot be redeemed without the verifier it was issued for
+	params = issuer.Authorize(t, authURL)
+	other, _ := NewOIDCAuthRequest()
+	other.Nonce = req.Nonce
+	if _, err := p.Exchange(ctx, params.Get("code"), other); err == nil {
+		t.Fatal("expected a code with the wrong verifier to be rejected")
+	}
+}
+
+func TestOIDCProvider_VerifyIDTokenRejectsInvalidTokens(t *testing.T) {
+	issuer := oidctest.NewIssuer(t, "client-id", "")
+	impostor := oidctest.NewIssuer(t, "client-id", "")
+	p := newTestOIDCProvider(t, issuer, "")
+	ctx := context.Background()
+
+	valid := func() jwt.MapClaims {
+		return jwt.MapClaims{
+			"iss":   issuer.URL,
+			"aud":   "client-id",
+			"sub":   "sub-1",
+			"iat":   time.Now().Unix(),
+			"exp":   time.Now().Add(time.Minute).Unix(),
+			"nonce": "nonce-1",
+		}
+	}
+	if _, err := p.VerifyIDToken(ctx, issuer.SignIDToken(t, valid()), "nonce-1"); err != nil {
+		t.Fatalf("expected valid token to verify: %v", err)
+	}
+
+	with := func(key string, value any) jwt.MapClaims {
+		claims := valid()
+		if value == nil {
+			delete(claims, key)
+		} else {
+			claims[key] = value
+		}
+		return claims
+	}
+	cases := map[string]string{
+		"wrong nonce":         issuer.SignIDToken(t, with("nonce", "nonce-2")),
+		"missing nonce":       issuer.SignIDToken(t, with("nonce", nil)),
+		"wrong audience":      issuer.SignIDToken(t, with("aud", "other-client")),
+		"wrong issuer":        issuer.SignIDToken(t, with("iss", impostor.URL)),
+		"expired":             issuer.SignIDToken(t, with("exp", time.Now().Add(-time.Hour).Unix())),
+		"missing expiry":      issuer.SignIDToken(t, with("exp", nil)),
+		"missing subject":     issuer.SignIDToken(t, with("sub", nil)),
+		"foreign azp":         issuer.SignIDToken(t, with("aud", []string{"client-id", "other-client"})),
+		"signed by impostor":  impostor.SignIDToken(t, valid()),
+		"unsigned":            unsignedToken(t, valid()),
+		"HMAC with issuer ID": hmacToken(t, valid(), []byte(issuer.URL)),
+	}
+	for name, token := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := p.VerifyIDToken(ctx, token, "nonce-1")
+			if !errors.Is(err, ErrInvalidIDToken) {
+				t.Fatalf("expected ErrInvalidIDToken, got %v", err)
+			}
+		})
+	}
+}
+
+func unsignedToken(t *testing.T, claims jwt.MapClaims) string {
+	t.Helper()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodNone, claims).SignedString(jwt.UnsafeAllowNoneSignatureType)
+	if err != nil {
+		t.Fatalf("failed to sign token: %v", err)
+	}
+	return token
+}
+
+func hmacToken(t *testing.T, claims jwt.MapClaims, secret []byte) string {
+	t.Helper()
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	token.Header["kid"] = "oidctest"
+	signed, err := token.SignedString(secret)
+	if err != nil {
+		t.Fatalf("failed to sign token: %v", err)
+	}
+	return signed
+}
+
+func TestOIDCProvider_RejectsMismatchedDiscoveryIssuer(t *testing.T) {
+	issuer := oidctest.NewIssuer(t, "client-id", "")
+	p, err := NewOIDCProvider(OIDCConfig{Name: "test", Issuer: issuer.URL + "/", ClientID: "client-id", RedirectURL: "https://app.example.com/callback"})
+	if err != nil {
+		t.Fatalf("NewOIDCProvider failed: %v", err)
+	}
+	req, _ := NewOIDCAuthRequest()
+	if _, err := p.AuthCodeURL(context.Background(), req); err == nil || !strings.Contains(err.Error(), "expected") {
+		t.Fatalf("expected issuer mismatch error, got %v", err)
+	}
+}
+
+func TestOIDCProvider_AppleStyleResponse(t *testing.T) {
+	// Apple posts the response back and sends email_verified as a string
+	issuer := oidctest.NewIssuer(t, "com.example.web", "")
+	issuer.SetIdentity(oidctest.Identity{Subject: "000123.abc", Email: "abc@privaterelay.appleid.com", EmailVerified: "true"})
+	p := newTestOIDCProvider(t, issuer, "form_post")
+	ctx := context.Background()
+
+	req, _ := NewOIDCAuthRequest()
+	authURL, err := p.AuthCodeURL(ctx, req)
+	if err != nil {
+		t.Fatalf("AuthCodeURL failed: %v", err)
+	}
+	parsed, _ := url.Parse(authURL)
+	if parsed.Query().Get("response_mode") != "form_post" || !p.UsesFormPost() {
+		t.Fatalf("expected form_post response mode in %s", authURL)
+	}
+
+	identity, err := p.Exchange(ctx, issuer.Authorize(t, authURL).Get("code"), req)
+	if err != nil {
+		t.Fatalf("Exchange failed: %v", err)
+	}
+	if !identity.EmailVerified || identity.Name != "" {
+		t.Fatalf("unexpected identity %+v", identity)
+	}
+
+	if name := AppleUserName(`{"name":{"firstName":"Jane","lastName":"Citizen"},"email":"abc@privaterelay.appleid.com"}`); name != "Jane Citizen" {
+		t.Fatalf("unexpected Apple user name %q", name)
+	}
+	if name := AppleUserName("not-json"); name != "" {
+		t.Fatalf("expected no name from invalid user field, got %q", name)
+	}
+}
+
+func TestAppleClientSecret(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	secret, err := appleClientSecret("TEAM123", "KEY123", "com.example.web", appleIssuer, key)()
+	if err != nil {
+		t.Fatalf("appleClientSecret failed: %v", err)
+	}
+
+	claims := &jwt.RegisteredClaims{}
+	token, err := jwt.ParseWithClaims(secret, claims, func(*jwt.Token) (interface{}, error) { return &key.PublicKey, nil },
+		jwt.WithValidMethods([]string{"ES256"}), jwt.WithAudience(appleIssuer), jwt.WithIssuer("TEAM123"))
+	if err != nil {
+		t.Fatalf("client secret did not verify: %v", err)
+	}
+	if token.Header["kid"] != "KEY123" || claims.Subject != "com.example.web" {
+		t.Fatalf("unexpected client secret %v %+v", token.Header, claims)
+	}
+}
+
+func TestOIDCProvidersFromEnv(t *testing.T) {
+	for _, name := range []string{"GOOGLE_OAUTH_ID", "GOOGLE_OAUTH_SECRET", "GOOGLE_OAUTH_REDIRECT", "APPLE_OAUTH_ID", "OIDC_PROVIDERS"} {
+		t.Setenv(name, "")
+	}
+	providers, err := OIDCProvidersFromEnv()
+	if err != nil || len(providers.Names()) != 0 {
+		t.Fatalf("expected no providers, got %v, %v", providers, err)
+	}
+
+	t.Setenv("GOOGLE_OAUTH_ID", "google-client")
+	if _, err := OIDCProvidersFromEnv(); err == nil {
+		t.Fatal("expected error for partly configured Google")
+	}
+	t.Setenv("GOOGLE_OAUTH_SECRET", "google-secret")
+	t.Setenv("GOOGLE_OAUTH_REDIRECT", "https://app.example.com/api/auth/oauth/google/callback")
+
+	t.Setenv("OIDC_PROVIDERS", "corp-sso")
+	if _, err := OIDCProvidersFromEnv(); err == nil {
+		t.Fatal("expected error for generic provider without an issuer")
+	}
+	t.Setenv("OIDC_CORP_SSO_ISSUER", "https://sso.example.com")
+	t.Setenv("OIDC_CORP_SSO_CLIENT_ID", "corp-client")
+	t.Setenv("OIDC_CORP_SSO_REDIRECT", "https://app.example.com/api/auth/oauth/corp-sso/callback")
+	t.Setenv("OIDC_CORP_SSO_SCOPES", "email")
+
+	t.Setenv("APPLE_OAUTH_ID", "com.example.web")
+	if _, err := OIDCProvidersFromEnv(); err == nil {
+		t.Fatal("expected error for Apple without a signing key")
+	}
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	dir := t.TempDir()
+	writeKey(t, dir, "AuthKey_KEY123", key)
+	t.Setenv("APPLE_TEAM_ID", "TEAM123")
+	t.Setenv("APPLE_KEY_ID", "KEY123")
+	t.Setenv("APPLE_PRIVATE_KEY_FILE", filepath.Join(dir, "AuthKey_KEY123.pem"))
+	t.Setenv("APPLE_OAUTH_REDIRECT", "https://app.example.com/api/auth/oauth/apple/callback")
+
+	providers, err = OIDCProvidersFromEnv()
+	if err != nil {
+		t.Fatalf("OIDCProvidersFromEnv failed: %v", err)
+	}
+	if got := strings.Join(providers.Names(), ","); got != "apple,corp-sso,google" {
+		t.Fatalf("unexpected providers %q", got)
+	}
+	apple, _ := providers.Get("apple")
+	if !apple.UsesFormPost() || apple.config.Issuer != appleIssuer {
+		t.Fatalf("unexpected Apple config %+v", apple.config)
+	}
+	corp, _ := providers.Get("corp-sso")
+	if strings.Join(corp.config.Scopes, " ") != "openid email" {
+		t.Fatalf("expected openid to be added to scopes, got %v", corp.config.Scopes)
+	}
+
+	t.Setenv("OIDC_PROVIDERS", "Google")
+	if _, err := OIDCProvidersFromEnv(); err == nil {
+		t.Fatal("expected error for invalid provider name")
+	}
+}
@@ -0,0 +1,275 @@
+// Package oidctest runs a stand-in OpenID Connect issuer so sign-in can be
+// tested end to end without reaching a real provider.
+package oidctest
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const keyID = "oidctest"
+
+// Identity is the user the issuer signs in. EmailVerified is a bool, or a
+// string to mimic providers such as Apple.
+type Identity struct {
+	Subject       string
+	Email         string
+	EmailVerified any
+	Name          string
+	Picture       string
+}
+
+// Issuer is an OpenID Connect provider backed by an httptest server. It
+// supports discovery, the authorization code flow with PKCE and nonces,
+// response_mode=form_post and a JWKS endpoint.
+type Issuer struct {
+	URL          string
+	ClientID     string
+	ClientSecret string
+
+	server *httptest.Server
+	key    *rsa.PrivateKey
+
+	mu       sync.Mutex
+	identity Identity
+	grants   map[string]grant
+}
+
+type grant struct {
+	redirectURI string
+	nonce       string
+	challenge   string
+	identity    Identity
+}
+
+// NewIssuer starts an issuer for the given client, closed when the test ends.
+// An empty clientSecret accepts token requests without one.
+func NewIssuer(t testing.TB, clientID, clientSecret string) *Issuer {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate issuer key: %v", err)
+	}
+
+	i := &Issuer{
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		key:          key,
+		identity:     Identity{Subject: "oidc-user", Email: "oidc-user@example.com", EmailVerified: true, Name: "OIDC User"},
+		grants:       map[string]grant{},
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", i.handleDiscovery)
+	mux.HandleFunc("/authorize", i.handleAuthorize)
+	mux.HandleFunc("/token", i.handleToken)
+	mux.HandleFunc("/jwks", i.handleJWKS)
+	i.server = httptest.NewServer(mux)
+	i.URL = i.server.URL
+	t.Cleanup(i.server.Close)
+	return i
+}
+
+// SetIdentity sets the user signed in by later authorizations.
+func (i *Issuer) SetIdentity(identity Identity) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.identity = identity
+}
+
+// Authorize performs the user's side of sign-in for authURL, as returned by
+// the relying party, and returns the parameters the issuer sends back to the
+// redirect URI.
+func (i *Issuer) Authorize(t testing.TB, authURL string) url.Values {
+	t.Helper()
+	u, err := url.Parse(authURL)
+	if err != nil {
+		t.Fatalf("invalid authorization URL: %v", err)
+	}
+	_, params, err := i.authorize(u.Query())
+	if err != nil {
+		t.Fatalf("authorization failed: %v", err)
+	}
+	return params
+}
+
+// SignIDToken signs arbitrary claims with the issuer's key, for testing how
+// relying parties handle malformed or forged tokens.
+func (i *Issuer) SignIDToken(t testing.TB, claims jwt.MapClaims) string {
+	t.Helper()
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = keyID
+	signed, err := token.SignedString(i.key)
+	if err != nil {
+		t.Fatalf("failed to sign ID token: %v", err)
+	}
+	return signed
+}
+
+func (i *Issuer) authorize(q url.Values) (string, url.Values, error) {
+	switch {
+	case q.Get("client_id") != i.ClientID:
+		return "", nil, errors.New("unknown client_id")
+	case q.Get("response_type") != "code":
+		return "", nil, errors.New("unsupported response_type")
+	case q.Get("redirect_uri") == "":
+		return "", nil, errors.New("redirect_uri missing")
+	case q.Get("code_challenge") == "" || q.Get("code_challenge_method") != "S256":
+		return "", nil, errors.New("PKCE with S256 is required")
+	}
+
+	code := randomString()
+	i.mu.Lock()
+	i.grants[code] = grant{
+		redirectURI: q.Get("redirect_uri"),
+		nonce:       q.Get("nonce"),
+		challenge:   q.Get("code_challenge"),
+		identity:    i.identity,
+	}
+	i.mu.Unlock()
+
+	params := url.Values{"code": {code}}
+	if state := q.Get("state"); state != "" {
+		params.Set("state", state)
+	}
+	return q.Get("redirect_uri"), params, nil
+}
+
+func (i *Issuer) handleDiscovery(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]any{
+		"issuer":                                i.URL,
+		"authorization_endpoint":                i.URL + "/authorize",
+		"token_endpoint":                        i.URL + "/token",
+		"jwks_uri":                              i.URL + "/jwks",
+		"response_types_supported":              []string{"code"},
+		"response_modes_supported":              []string{"query", "form_post"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+		"code_challenge_methods_supported":      []string{"S256"},
+	})
+}
+
+// handleAuthorize signs the current identity in without a consent screen.
+func (i *Issuer) handleAuthorize(w http.ResponseWriter, r *http.Request) {
+	redirectURI, params, err := i.authorize(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if r.URL.Query().Get("response_mode") != "form_post" {
+		http.Redirect(w, r, redirectURI+"?"+params.Encode(), http.StatusFound)
+		return
+	}
+
+	// Like Apple, post the response back with a self-submitting form
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	fmt.Fprintf(w, `<!doctype html><html><body onload="document.forms[0].submit()"><form method="post" action="%s">`, html.EscapeString(redirectURI))
+	for name := range params {
+		fmt.Fprintf(w, `<input type="hidden" name="%s" value="%s">`, html.EscapeString(name), html.EscapeString(params.Get(name)))
+	}
+	fmt.Fprint(w, `</form></body></html>`)
+}
+
+func (i *Issuer) handleToken(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_request"})
+		return
+	}
+
+	code := r.PostForm.Get("code")
+	i.mu.Lock()
+	g, ok := i.grants[code]
+	delete(i.grants, code)
+	i.mu.Unlock()
+
+	verifier := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	switch {
+	case r.PostForm.Get("grant_type") != "authorization_code":
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "unsupported_grant_type"})
+		return
+	case r.PostForm.Get("client_id") != i.ClientID || (i.ClientSecret != "" && r.PostForm.Get("client_secret") != i.ClientSecret):
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_client"})
+		return
+	case !ok || r.PostForm.Get("redirect_uri") != g.redirectURI || base64.RawURLEncoding.EncodeToString(verifier[:]) != g.challenge:
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		return
+	}
+
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"iss":   i.URL,
+		"aud":   i.ClientID,
+		"sub":   g.identity.Subject,
+		"iat":   now.Unix(),
+		"exp":   now.Add(5 * time.Minute).Unix(),
+		"nonce": g.nonce,
+	}
+	if g.identity.Email != "" {
+		claims["email"] = g.identity.Email
+		claims["email_verified"] = g.identity.EmailVerified
+	}
+	if g.identity.Name != "" {
+		claims["name"] = g.identity.Name
+	}
+	if g.identity.Picture != "" {
+		claims["picture"] = g.identity.Picture
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = keyID
+	idToken, err := token.SignedString(i.key)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "server_error"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"access_token": randomString(),
+		"token_type":   "Bearer",
+		"expires_in":   300,
+		"id_token":     idToken,
+	})
+}
+
+func (i *Issuer) handleJWKS(w http.ResponseWriter, r *http.Request) {
+	pub := i.key.PublicKey
+	writeJSON(w, http.StatusOK, map[string]any{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": keyID,
+			"use": "sig",
+			"alg": "RS256",
+			"n":   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+		}},
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func randomString() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
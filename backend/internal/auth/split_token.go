@@ -0,0 +1,51 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+)
+
+// Split tokens are single-use secrets, such as password reset tokens, made of
+// a selector and a verifier. The selector is stored as is to look the token
+// up. Only a SHA-256 hash of the verifier is stored, and it is compared in
+// constant time, so neither the database nor response timing reveals it.
+const (
+	splitTokenSelectorBytes = 16
+	splitTokenVerifierBytes = 32
+	splitTokenLength        = 2 * (splitTokenSelectorBytes + splitTokenVerifierBytes)
+)
+
+// NewSplitToken returns a new token to give to the user, and the selector and
+// verifier hash to store.
+func NewSplitToken() (token, selector, verifierHash string, err error) {
+	b := make([]byte, splitTokenSelectorBytes+splitTokenVerifierBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", "", fmt.Errorf("failed to generate token: %w", err)
+	}
+	token = hex.EncodeToString(b)
+	selector, verifierHash, _ = ParseSplitToken(token)
+	return token, selector, verifierHash, nil
+}
+
+// ParseSplitToken returns the selector and verifier hash of a token from the
+// user. ok is false if it is not a split token.
+func ParseSplitToken(token string) (selector, verifierHash string, ok bool) {
+	if len(token) != splitTokenLength {
+		return "", "", false
+	}
+	if _, err := hex.DecodeString(token); err != nil {
+		return "", "", false
+	}
+	selector = token[:2*splitTokenSelectorBytes]
+	sum := sha256.Sum256([]byte(token[2*splitTokenSelectorBytes:]))
+	return selector, hex.EncodeToString(sum[:]), true
+}
+
+// VerifySplitToken reports whether a token's verifier hash matches the stored
+// one.
+func VerifySplitToken(verifierHash, storedHash string) bool {
+	return subtle.ConstantTimeCompare([]byte(verifierHash), []byte(storedHash)) == 1
+}
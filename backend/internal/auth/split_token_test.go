@@ -0,0 +1,37 @@
+package auth
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSplitToken(t *testing.T) {
+	token, selector, verifierHash, err := NewSplitToken()
+	if err != nil {
+		t.Fatalf("NewSplitToken failed: %v", err)
+	}
+	if !strings.HasPrefix(token, selector) || strings.Contains(token, verifierHash) {
+		t.Fatalf("token should start with the selector and not contain the verifier hash")
+	}
+
+	gotSelector, gotHash, ok := ParseSplitToken(token)
+	if !ok || gotSelector != selector || !VerifySplitToken(gotHash, verifierHash) {
+		t.Fatalf("ParseSplitToken did not recover the selector and verifier hash")
+	}
+
+	// Changing the verifier keeps the selector but fails verification
+	tampered := token[:len(token)-1] + "0"
+	if tampered == token {
+		tampered = token[:len(token)-1] + "1"
+	}
+	gotSelector, gotHash, ok = ParseSplitToken(tampered)
+	if !ok || gotSelector != selector || VerifySplitToken(gotHash, verifierHash) {
+		t.Fatalf("tampered token should not verify")
+	}
+
+	for _, bad := range []string{"", "abc", strings.Repeat("z", len(token))} {
+		if _, _, ok := ParseSplitToken(bad); ok {
+			t.Fatalf("ParseSplitToken(%q) should fail", bad)
+		}
+	}
+}
@@ -24,21 +24,30 @@ func NewAlertHandler(alertService service.AlertService) *AlertHandler {
 func (h *AlertHandler) CreateAlert(c *gin.Context) {
 	userID, exists := c.Get("userID")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		c.JSON(http.StatusUnauthorized, gin.H{"error": localize(c, "errors.user_not_authenticated")})
 		return
 	}
 
 	var req struct {
-		FuelTypeID     string  `json:"fuelTypeId" binding:"required"`
-		PriceThreshold float64 `json:"priceThreshold" binding:"required,gt=0"`
-		Latitude       float64 `json:"latitude" binding:"required"`
-		Longitude      float64 `json:"longitude" binding:"required"`
-		RadiusKm       int     `json:"radiusKm" binding:"required,min=1,max=50"`
-		AlertName      string  `json:"alertName"`
-		Name           string  `json:"name"`
-		RecurrenceType string  `json:"recurrenceType" binding:"omitempty,oneof=recurring one_off"`
-		NotifyViaPush  *bool   `json:"notifyViaPush"`
-		NotifyViaEmail *bool   `json:"notifyViaEmail"`
+		FuelTypeID       string   `json:"fuelTypeId" binding:"required"`
+		PriceThreshold   float64  `json:"priceThreshold" binding:"omitempty,gt=0"`
+		ConditionType    string   `json:"conditionType" binding:"omitempty,oneof=price_threshold percent_below_average new_cheapest cycle_bottom price_rise"`
+		ConditionPercent float64  `json:"conditionPercent" binding:"omitempty,gt=0,lt=100"`
+		AverageScope     string   `json:"averageScope" binding:"omitempty,oneof=station area"`
+		LookbackDays     int      `json:"lookbackDays" binding:"omitempty,min=1,max=90"`
+		TargetMode       string   `json:"targetMode" binding:"omitempty,oneof=radius stations radius_or_stations favourites"`
+		StationIDs       []string `json:"stationIds"`
+		Latitude         float64  `json:"latitude"`
+		Longitude        float64  `json:"longitude"`
+		RadiusKm         int      `json:"radiusKm" binding:"omitempty,min=1,max=50"`
+		AlertName        string   `json:"alertName"`
+		Name             string   `json:"name"`
+		RecurrenceType   string   `json:"recurrenceType" binding:"omitempty,oneof=recurring one_off"`
+		CooldownMinutes  *int     `json:"cooldownMinutes" binding:"omitempty,min=0"`
+		RearmRule        string   `json:"rearmRule" binding:"omitempty,oneof=none price_recovers"`
+		MaxTriggers      *int     `json:"maxTriggers" binding:"omitempty,min=1"`
+		NotifyViaPush    *bool    `json:"notifyViaPush"`
+		NotifyViaEmail   *bool    `json:"notifyViaEmail"`
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -65,20 +74,41 @@ func (h *AlertHandler) CreateAlert(c *gin.Context) {
 	if recurrenceType == "" {
 		recurrenceType = "recurring"
 	}
+	conditionType := req.ConditionType
+	if conditionType == "" {
+		conditionType = repository.AlertConditionPriceThreshold
+	}
+	targetMode := req.TargetMode
+	if targetMode == "" {
+		targetMode = repository.AlertTargetRadius
+	}
 
 	alert, err := h.alertService.CreateAlert(userID.(string), repository.CreateAlertInput{
-		FuelTypeID:     req.FuelTypeID,
-		PriceThreshold: req.PriceThreshold,
-		Latitude:       req.Latitude,
-		Longitude:      req.Longitude,
-		RadiusKm:       req.RadiusKm,
-		AlertName:      alertName,
-		RecurrenceType: recurrenceType,
-		NotifyViaPush:  notifyViaPush,
-		NotifyViaEmail: notifyViaEmail,
+		FuelTypeID:       req.FuelTypeID,
+		PriceThreshold:   req.PriceThreshold,
+		ConditionType:    conditionType,
+		ConditionPercent: req.ConditionPercent,
+		AverageScope:     req.AverageScope,
+		LookbackDays:     req.LookbackDays,
+		TargetMode:       targetMode,
+		StationIDs:       req.StationIDs,
+		Latitude:         req.Latitude,
+		Longitude:        req.Longitude,
+		RadiusKm:         req.RadiusKm,
+		AlertName:        alertName,
+		RecurrenceType:   recurrenceType,
+		CooldownMinutes:  req.CooldownMinutes,
+		RearmRule:        req.RearmRule,
+		MaxTriggers:      req.MaxTriggers,
+		NotifyViaPush:    notifyViaPush,
+		NotifyViaEmail:   notifyViaEmail,
 	})
+	if errors.Is(err, repository.ErrInvalidAlertInput) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create alert"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "errors.failed_to_create_alert")})
 		return
 	}
 
@@ -89,13 +119,13 @@ func (h *AlertHandler) CreateAlert(c *gin.Context) {
 func (h *AlertHandler) GetAlerts(c *gin.Context) {
 	userID, exists := c.Get("userID")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		c.JSON(http.StatusUnauthorized, gin.H{"error": localize(c, "errors.user_not_authenticated")})
 		return
 	}
 
 	alerts, err := h.alertService.GetAlerts(userID.(string))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch alerts"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "errors.failed_to_fetch_alerts")})
 		return
 	}
 
@@ -106,19 +136,28 @@ func (h *AlertHandler) GetAlerts(c *gin.Context) {
 func (h *AlertHandler) UpdateAlert(c *gin.Context) {
 	userID, exists := c.Get("userID")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		c.JSON(http.StatusUnauthorized, gin.H{"error": localize(c, "errors.user_not_authenticated")})
 		return
 	}
 	id := c.Param("id")
 
 	var req struct {
-		PriceThreshold float64 `json:"priceThreshold"`
-		RadiusKm       int     `json:"radiusKm"`
-		AlertName      string  `json:"alertName"`
-		RecurrenceType *string `json:"recurrenceType" binding:"omitempty,oneof=recurring one_off"`
-		NotifyViaPush  *bool   `json:"notifyViaPush"`
-		NotifyViaEmail *bool   `json:"notifyViaEmail"`
-		IsActive       *bool   `json:"isActive"`
+		PriceThreshold   float64  `json:"priceThreshold"`
+		ConditionType    *string  `json:"conditionType" binding:"omitempty,oneof=price_threshold percent_below_average new_cheapest cycle_bottom price_rise"`
+		ConditionPercent *float64 `json:"conditionPercent" binding:"omitempty,gte=0,lt=100"`
+		AverageScope     *string  `json:"averageScope" binding:"omitempty,oneof=station area"`
+		LookbackDays     *int     `json:"lookbackDays" binding:"omitempty,min=1,max=90"`
+		TargetMode       *string  `json:"targetMode" binding:"omitempty,oneof=radius stations radius_or_stations favourites"`
+		StationIDs       []string `json:"stationIds"`
+		RadiusKm         int      `json:"radiusKm"`
+		AlertName        string   `json:"alertName"`
+		RecurrenceType   *string  `json:"recurrenceType" binding:"omitempty,oneof=recurring one_off"`
+		CooldownMinutes  *int     `json:"cooldownMinutes" binding:"omitempty,min=0"`
+		RearmRule        *string  `json:"rearmRule" binding:"omitempty,oneof=none price_recovers"`
+		MaxTriggers      *int     `json:"maxTriggers" binding:"omitempty,min=0"`
+		NotifyViaPush    *bool    `json:"notifyViaPush"`
+		NotifyViaEmail   *bool    `json:"notifyViaEmail"`
+		IsActive         *bool    `json:"isActive"`
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -127,70 +166,162 @@ func (h *AlertHandler) UpdateAlert(c *gin.Context) {
 	}
 
 	updatedID, err := h.alertService.UpdateAlert(id, userID.(string), repository.UpdateAlertInput{
-		PriceThreshold: req.PriceThreshold,
-		RadiusKm:       req.RadiusKm,
-		AlertName:      req.AlertName,
-		RecurrenceType: req.RecurrenceType,
-		NotifyViaPush:  req.NotifyViaPush,
-		NotifyViaEmail: req.NotifyViaEmail,
-		IsActive:       req.IsActive,
+		PriceThreshold:   req.PriceThreshold,
+		ConditionType:    req.ConditionType,
+		ConditionPercent: req.ConditionPercent,
+		AverageScope:     req.AverageScope,
+		LookbackDays:     req.LookbackDays,
+		TargetMode:       req.TargetMode,
+		StationIDs:       req.StationIDs,
+		RadiusKm:         req.RadiusKm,
+		AlertName:        req.AlertName,
+		RecurrenceType:   req.RecurrenceType,
+		CooldownMinutes:  req.CooldownMinutes,
+		RearmRule:        req.RearmRule,
+		MaxTriggers:      req.MaxTriggers,
+		NotifyViaPush:    req.NotifyViaPush,
+		NotifyViaEmail:   req.NotifyViaEmail,
+		IsActive:         req.IsActive,
 	})
+	if errors.Is(err, repository.ErrInvalidAlertInput) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 	if err == sql.ErrNoRows {
-		c.JSON(http.StatusNotFound, gin.H{"error": "alert not found"})
+		c.JSON(http.StatusNotFound, gin.H{"error": localize(c, "errors.alert_not_found")})
 		return
 	}
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update alert"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "errors.failed_to_update_alert")})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"id": updatedID, "message": "alert updated"})
+	c.JSON(http.StatusOK, gin.H{"id": updatedID, "message": localize(c, "messages.alert_updated")})
 }
 
 // DeleteAlert handles DELETE /api/alerts/:id
 func (h *AlertHandler) DeleteAlert(c *gin.Context) {
 	userID, exists := c.Get("userID")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		c.JSON(http.StatusUnauthorized, gin.H{"error": localize(c, "errors.user_not_authenticated")})
 		return
 	}
 	id := c.Param("id")
 
 	deleted, err := h.alertService.DeleteAlert(id, userID.(string))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete alert"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "errors.failed_to_delete_alert")})
 		return
 	}
 	if !deleted {
-		c.JSON(http.StatusNotFound, gin.H{"error": "alert not found"})
+		c.JSON(http.StatusNotFound, gin.H{"error": localize(c, "errors.alert_not_found")})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "alert deleted"})
+	c.JSON(http.StatusOK, gin.H{"message": localize(c, "messages.alert_deleted")})
 }
 
 // GetMatchingStations handles GET /api/alerts/:id/matching-stations
 func (h *AlertHandler) GetMatchingStations(c *gin.Context) {
 	userID, exists := c.Get("userID")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		c.JSON(http.StatusUnauthorized, gin.H{"error": localize(c, "errors.user_not_authenticated")})
 		return
 	}
 	id := c.Param("id")
 
 	stations, err := h.alertService.GetMatchingStations(id, userID.(string))
 	if errors.Is(err, sql.ErrNoRows) {
-		c.JSON(http.StatusNotFound, gin.H{"error": "alert not found"})
+		c.JSON(http.StatusNotFound, gin.H{"error": localize(c, "errors.alert_not_found")})
 		return
 	}
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch matching stations"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "errors.failed_to_fetch_matching_stations")})
 		return
 	}
 
 	c.JSON(http.StatusOK, stations)
 }
 
+// PreviewAlert handles POST /api/alerts/preview
+func (h *AlertHandler) PreviewAlert(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": localize(c, "errors.user_not_authenticated")})
+		return
+	}
+
+	var req struct {
+		FuelTypeID            string   `json:"fuelTypeId" binding:"required"`
+		PriceThreshold        float64  `json:"priceThreshold" binding:"omitempty,gt=0"`
+		ConditionType         string   `json:"conditionType" binding:"omitempty,oneof=price_threshold percent_below_average new_cheapest cycle_bottom price_rise"`
+		ConditionPercent      float64  `json:"conditionPercent" binding:"omitempty,gt=0,lt=100"`
+		AverageScope          string   `json:"averageScope" binding:"omitempty,oneof=station area"`
+		LookbackDays          int      `json:"lookbackDays" binding:"omitempty,min=1,max=90"`
+		TargetMode            string   `json:"targetMode" binding:"omitempty,oneof=radius stations radius_or_stations favourites"`
+		StationIDs            []string `json:"stationIds"`
+		Latitude              float64  `json:"latitude"`
+		Longitude             float64  `json:"longitude"`
+		RadiusKm              int      `json:"radiusKm" binding:"omitempty,min=1,max=50"`
+		RecurrenceType        string   `json:"recurrenceType" binding:"omitempty,oneof=recurring one_off"`
+		CooldownMinutes       *int     `json:"cooldownMinutes" binding:"omitempty,min=0"`
+		RearmRule             string   `json:"rearmRule" binding:"omitempty,oneof=none price_recovers"`
+		MaxTriggers           *int     `json:"maxTriggers" binding:"omitempty,min=1"`
+		Days                  int      `json:"days" binding:"omitempty,oneof=30 90"`
+		TargetTriggersPerWeek float64  `json:"targetTriggersPerWeek" binding:"omitempty,gt=0,max=50"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	recurrenceType := req.RecurrenceType
+	if recurrenceType == "" {
+		recurrenceType = "recurring"
+	}
+	conditionType := req.ConditionType
+	if conditionType == "" {
+		conditionType = repository.AlertConditionPriceThreshold
+	}
+	targetMode := req.TargetMode
+	if targetMode == "" {
+		targetMode = repository.AlertTargetRadius
+	}
+
+	preview, err := h.alertService.PreviewAlert(userID.(string), service.AlertPreviewRequest{
+		Alert: repository.CreateAlertInput{
+			FuelTypeID:       req.FuelTypeID,
+			PriceThreshold:   req.PriceThreshold,
+			ConditionType:    conditionType,
+			ConditionPercent: req.ConditionPercent,
+			AverageScope:     req.AverageScope,
+			LookbackDays:     req.LookbackDays,
+			TargetMode:       targetMode,
+			StationIDs:       req.StationIDs,
+			Latitude:         req.Latitude,
+			Longitude:        req.Longitude,
+			RadiusKm:         req.RadiusKm,
+			RecurrenceType:   recurrenceType,
+			CooldownMinutes:  req.CooldownMinutes,
+			RearmRule:        req.RearmRule,
+			MaxTriggers:      req.MaxTriggers,
+		},
+		Days:                  req.Days,
+		TargetTriggersPerWeek: req.TargetTriggersPerWeek,
+	})
+	if errors.Is(err, repository.ErrInvalidAlertInput) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "errors.failed_to_preview_alert")})
+		return
+	}
+
+	c.JSON(http.StatusOK, preview)
+}
+
 // GetPriceContext handles POST /api/alerts/price-context
 func (h *AlertHandler) GetPriceContext(c *gin.Context) {
 	var req struct {
@@ -212,11 +343,11 @@ func (h *AlertHandler) GetPriceContext(c *gin.Context) {
 		RadiusKm:   req.RadiusKm,
 	})
 	if errors.Is(err, sql.ErrNoRows) {
-		c.JSON(http.StatusNotFound, gin.H{"error": "fuel type not found"})
+		c.JSON(http.StatusNotFound, gin.H{"error": localize(c, "errors.fuel_type_not_found")})
 		return
 	}
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch price context"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "errors.failed_to_fetch_price_context")})
 		return
 	}
 
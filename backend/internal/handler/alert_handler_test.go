@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -13,6 +14,7 @@ import (
 	testhelpers "gaspeep/backend/internal/handler/testhelpers"
 	"gaspeep/backend/internal/models"
 	"gaspeep/backend/internal/repository"
+	"gaspeep/backend/internal/service"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
@@ -45,7 +47,8 @@ func TestAlertHandlerCreateAlertDefaults(t *testing.T) {
 	alert := &models.Alert{ID: "a1", AlertName: "Price Alert", RecurrenceType: "recurring", NotifyViaPush: true, NotifyViaEmail: false}
 	mockService.On("CreateAlert", "user-1", mock.MatchedBy(func(input repository.CreateAlertInput) bool {
 		return input.FuelTypeID == "u91" && input.PriceThreshold == 189.9 && input.Latitude == -33.86 && input.Longitude == 151.2 &&
-			input.RadiusKm == 10 && input.AlertName == "Price Alert" && input.RecurrenceType == "recurring" && input.NotifyViaPush && !input.NotifyViaEmail
+			input.RadiusKm == 10 && input.AlertName == "Price Alert" && input.RecurrenceType == "recurring" && input.NotifyViaPush && !input.NotifyViaEmail &&
+			input.ConditionType == repository.AlertConditionPriceThreshold
 	})).Return(alert, nil).Once()
 
 	payload := map[string]any{
@@ -67,6 +70,37 @@ func TestAlertHandlerCreateAlertDefaults(t *testing.T) {
 	mockService.AssertExpectations(t)
 }
 
+func TestAlertHandlerCreateAlertInvalidCondition(t *testing.T) {
+	mockService := new(testhelpers.MockAlertService)
+	h := NewAlertHandler(mockService)
+	r := authedRouter()
+	r.POST("/alerts", h.CreateAlert)
+
+	mockService.On("CreateAlert", "user-1", mock.MatchedBy(func(input repository.CreateAlertInput) bool {
+		return input.ConditionType == repository.AlertConditionPercentBelowAverage && input.ConditionPercent == 5
+	})).Return(nil, fmt.Errorf("%w: averageScope must be station or area", repository.ErrInvalidAlertInput)).Once()
+
+	payload := map[string]any{
+		"fuelTypeId":       "u91",
+		"conditionType":    "percent_below_average",
+		"conditionPercent": 5,
+		"latitude":         -33.86,
+		"longitude":        151.2,
+		"radiusKm":         10,
+	}
+	body, err := json.Marshal(payload)
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodPost, "/alerts", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "averageScope")
+	mockService.AssertExpectations(t)
+}
+
 func TestAlertHandlerCreateAlertUnauthorized(t *testing.T) {
 	mockService := new(testhelpers.MockAlertService)
 	h := NewAlertHandler(mockService)
@@ -253,3 +287,45 @@ func TestAlertHandlerGetMatchingStationsSuccess(t *testing.T) {
 	assert.Equal(t, http.StatusOK, w.Code)
 	mockService.AssertExpectations(t)
 }
+
+func TestAlertHandlerPreviewAlertSuccess(t *testing.T) {
+	mockService := new(testhelpers.MockAlertService)
+	h := NewAlertHandler(mockService)
+	r := authedRouter()
+	r.POST("/alerts/preview", h.PreviewAlert)
+
+	mockService.On("PreviewAlert", "user-1", mock.MatchedBy(func(req service.AlertPreviewRequest) bool {
+		return req.Days == 90 && req.TargetTriggersPerWeek == 2 &&
+			req.Alert.FuelTypeID == "u91" && req.Alert.PriceThreshold == 1.85 &&
+			req.Alert.ConditionType == repository.AlertConditionPriceThreshold &&
+			req.Alert.TargetMode == repository.AlertTargetRadius
+	})).Return(&service.AlertPreviewResult{Days: 90, TriggerCount: 12, TriggersPerWeek: 0.93}, nil).Once()
+
+	body := []byte(`{"fuelTypeId":"u91","priceThreshold":1.85,"latitude":-33.86,"longitude":151.2,"radiusKm":10,"days":90,"targetTriggersPerWeek":2}`)
+	req := httptest.NewRequest(http.MethodPost, "/alerts/preview", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp service.AlertPreviewResult
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, 12, resp.TriggerCount)
+	mockService.AssertExpectations(t)
+}
+
+func TestAlertHandlerPreviewAlertRejectsUnsupportedWindow(t *testing.T) {
+	mockService := new(testhelpers.MockAlertService)
+	h := NewAlertHandler(mockService)
+	r := authedRouter()
+	r.POST("/alerts/preview", h.PreviewAlert)
+
+	body := []byte(`{"fuelTypeId":"u91","priceThreshold":1.85,"latitude":-33.86,"longitude":151.2,"radiusKm":10,"days":45}`)
+	req := httptest.NewRequest(http.MethodPost, "/alerts/preview", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertNotCalled(t, "PreviewAlert", mock.Anything, mock.Anything)
+}
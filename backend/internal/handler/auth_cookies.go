@@ -0,0 +1,76 @@
+package handler
+
+import (
+	"net/http"
+	"os"
+	"time"
+	"unicode/utf8"
+
+	"gaspeep/backend/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	accessTokenCookie  = "auth_token"
+	refreshTokenCookie = "refresh_token"
+	// The refresh token is only sent to the auth endpoints that use it
+	refreshTokenCookiePath = "/api/auth"
+	maxUserAgentLength     = 512
+	maxDeviceNameLength    = 255
+)
+
+// setAuthCookie sets an HttpOnly cookie. Cookies are Secure when
+// AUTH_COOKIE_SECURE is "true", in production, or over TLS. Production uses
+// SameSite=Lax for CSRF protection; elsewhere None allows the OAuth popup.
+func setAuthCookie(c *gin.Context, name, value, path string, maxAge int) {
+	secureFlag := os.Getenv("AUTH_COOKIE_SECURE") == "true" || os.Getenv("ENV") == "production" || c.Request.TLS != nil
+
+	sameSite := http.SameSiteNoneMode
+	if os.Getenv("ENV") == "production" {
+		sameSite = http.SameSiteLaxMode
+	}
+
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     path,
+		Domain:   os.Getenv("AUTH_COOKIE_DOMAIN"),
+		HttpOnly: true,
+		Secure:   secureFlag,
+		SameSite: sameSite,
+		MaxAge:   maxAge,
+	})
+}
+
+// setSessionCookies stores a session's tokens in cookies for browser clients.
+func setSessionCookies(c *gin.Context, tokens *service.SessionTokens) {
+	setAuthCookie(c, accessTokenCookie, tokens.AccessToken, "/", int(time.Until(tokens.AccessTokenExpiresAt).Seconds()))
+	setAuthCookie(c, refreshTokenCookie, tokens.RefreshToken, refreshTokenCookiePath, int(time.Until(tokens.RefreshTokenExpiresAt).Seconds()))
+}
+
+func clearSessionCookies(c *gin.Context) {
+	setAuthCookie(c, accessTokenCookie, "", "/", -1)
+	setAuthCookie(c, refreshTokenCookie, "", refreshTokenCookiePath, -1)
+}
+
+// sessionMetadata describes the client making the request. deviceName is an
+// optional label supplied by the client, such as "Pixel 8".
+func sessionMetadata(c *gin.Context, deviceName string) service.SessionMetadata {
+	return service.SessionMetadata{
+		UserAgent:  truncate(c.Request.UserAgent(), maxUserAgentLength),
+		DeviceName: truncate(deviceName, maxDeviceNameLength),
+		IPAddress:  c.ClientIP(),
+	}
+}
+
+// truncate shortens s to at most n bytes without splitting a character.
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
@@ -1,27 +1,50 @@
 package handler
 
 import (
+	"errors"
+	"log"
 	"net/http"
-	"os"
+	"strconv"
+	"sync"
 	"time"
 
 	"gaspeep/backend/internal/auth"
+	"gaspeep/backend/internal/middleware"
 	"gaspeep/backend/internal/models"
 	"gaspeep/backend/internal/repository"
+	"gaspeep/backend/internal/service"
 
 	"github.com/gin-gonic/gin"
 	"golang.org/x/crypto/bcrypt"
 )
 
 type AuthHandler struct {
-	userRepo repository.UserRepository
-	prRepo   repository.PasswordResetRepository
+	userRepo            repository.UserRepository
+	prRepo              repository.PasswordResetRepository
+	verificationService service.EmailVerificationService
+	sessionService      service.SessionService
+	mfaService          service.MFAService
+	loginThrottle       service.LoginThrottle
+	emailService        service.EmailService
 }
 
-func NewAuthHandler(userRepo repository.UserRepository, prRepo repository.PasswordResetRepository) *AuthHandler {
+func NewAuthHandler(
+	userRepo repository.UserRepository,
+	prRepo repository.PasswordResetRepository,
+	verificationService service.EmailVerificationService,
+	sessionService service.SessionService,
+	mfaService service.MFAService,
+	loginThrottle service.LoginThrottle,
+	emailService service.EmailService,
+) *AuthHandler {
 	return &AuthHandler{
-		userRepo: userRepo,
-		prRepo:   prRepo,
+		userRepo:            userRepo,
+		prRepo:              prRepo,
+		verificationService: verificationService,
+		sessionService:      sessionService,
+		mfaService:          mfaService,
+		loginThrottle:       loginThrottle,
+		emailService:        emailService,
 	}
 }
 
@@ -29,7 +52,7 @@ type SignUpRequest struct {
 	Email       string `json:"email" binding:"required,email"`
 	Password    string `json:"password" binding:"required,min=8"`
 	DisplayName string `json:"displayName" binding:"required"`
-	Tier        string `json:"tier" binding:"required"`
+	DeviceName  string `json:"deviceName"`
 }
 
 type SignUpResponse struct {
@@ -37,13 +60,93 @@ type SignUpResponse struct {
 }
 
 type SignInRequest struct {
-	Email    string `json:"email" binding:"required,email"`
-	Password string `json:"password" binding:"required"`
+	Email      string `json:"email" binding:"required,email"`
+	Password   string `json:"password" binding:"required"`
+	DeviceName string `json:"deviceName"`
 }
 
+// AuthResponse carries a session's tokens for clients that cannot use the
+// auth cookies. Token is the access token; ExpiresIn is its lifetime in seconds.
 type AuthResponse struct {
-	Token string       `json:"token"`
-	User  *models.User `json:"user"`
+	Token        string       `json:"token"`
+	RefreshToken string       `json:"refreshToken"`
+	ExpiresIn    int          `json:"expiresIn"`
+	User         *models.User `json:"user,omitempty"`
+}
+
+func newAuthResponse(tokens *service.SessionTokens, user *models.User) AuthResponse {
+	return AuthResponse{
+		Token:        tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		ExpiresIn:    int(time.Until(tokens.AccessTokenExpiresAt).Seconds()),
+		User:         user,
+	}
+}
+
+// MFAChallengeResponse is returned instead of a session when the user must
+// also enter a second factor. MFAToken is exchanged at /api/auth/mfa/verify.
+type MFAChallengeResponse struct {
+	MFARequired bool   `json:"mfaRequired"`
+	MFAToken    string `json:"mfaToken"`
+	ExpiresIn   int    `json:"expiresIn"`
+}
+
+// startSession signs the user in on the requesting device and sets the
+// session cookies. It writes an error response and returns nil on failure.
+func startSession(c *gin.Context, sessionService service.SessionService, user *models.User, meta service.SessionMetadata) *service.SessionTokens {
+	tokens, err := sessionService.Start(user, meta)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "errors.failed_to_generate_token")})
+		return nil
+	}
+	setSessionCookies(c, tokens)
+	return tokens
+}
+
+// completeSignIn finishes signing in a user who has proved who they are,
+// responding with a session or, for users with two-factor authentication, a
+// challenge to enter a code.
+func completeSignIn(c *gin.Context, sessionService service.SessionService, mfaService service.MFAService, user *models.User, deviceName string) {
+	mfaEnabled, err := mfaService.Enabled(user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "errors.failed_to_check_mfa")})
+		return
+	}
+	if mfaEnabled {
+		challenge, ok := startMFAChallenge(c, user.ID)
+		if !ok {
+			return
+		}
+		c.JSON(http.StatusOK, challenge)
+		return
+	}
+
+	tokens := startSession(c, sessionService, user, sessionMetadata(c, deviceName))
+	if tokens == nil {
+		return
+	}
+
+	c.JSON(http.StatusOK, newAuthResponse(tokens, user))
+}
+
+// dummyPasswordHash is compared against when signing in to an unknown
+// account, so the response takes as long as for a wrong password.
+var dummyPasswordHash = sync.OnceValue(func() []byte {
+	hash, _ := bcrypt.GenerateFromPassword([]byte("not a real password"), bcrypt.DefaultCost)
+	return hash
+})
+
+// respondThrottled responds to an error from service.LoginThrottle, asking
+// throttled clients to retry later.
+func respondThrottled(c *gin.Context, err error) {
+	var throttled *service.ThrottledError
+	if !errors.As(err, &throttled) {
+		log.Printf("login throttle: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "errors.failed_to_process_request")})
+		return
+	}
+	c.Header("Retry-After", strconv.Itoa(int(throttled.RetryAfter.Seconds())+1))
+	c.JSON(http.StatusTooManyRequests, gin.H{"error": localize(c, "errors.too_many_attempts")})
 }
 
 func (h *AuthHandler) SignUp(c *gin.Context) {
@@ -53,62 +156,52 @@ func (h *AuthHandler) SignUp(c *gin.Context) {
 		return
 	}
 
+	// A conflict reveals that the address is registered, so sign-ups share
+	// the email availability check's budget
+	if err := h.loginThrottle.AllowLookup("check-email", c.ClientIP()); err != nil {
+		respondThrottled(c, err)
+		return
+	}
+
 	existingUser, _ := h.userRepo.GetUserByEmail(req.Email)
 	if existingUser != nil {
-		c.JSON(http.StatusConflict, gin.H{"error": "user already exists"})
+		c.JSON(http.StatusConflict, gin.H{"error": localize(c, "errors.user_already_exists")})
 		return
 	}
 
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to hash password"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "errors.failed_to_hash_password")})
 		return
 	}
 
-	user, err := h.userRepo.CreateUser(req.Email, string(hashedPassword), req.DisplayName, req.Tier)
+	user, err := h.userRepo.CreateUser(req.Email, string(hashedPassword), req.DisplayName, models.TierFree)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create user error is " + err.Error()})
+		log.Printf("failed to create user: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "errors.failed_to_create_user")})
 		return
 	}
 
-	token, err := auth.GenerateToken(user.ID, user.Email)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to generate token"})
-		return
+	// Emails are sent in the user's preferred locale, so start with the one
+	// their browser asked for
+	if c.GetHeader("Accept-Language") != "" {
+		user.Locale = middleware.Localizer(c).Locale()
+		if err := h.userRepo.UpdateLocale(user.ID, user.Locale); err != nil {
+			log.Printf("warning: failed to save locale for user %s: %v", user.ID, err)
+		}
 	}
 
-	// Set cookie (HttpOnly) for session
-	cookieDomain := os.Getenv("AUTH_COOKIE_DOMAIN")
-
-	secureFlag := false
-	if os.Getenv("AUTH_COOKIE_SECURE") == "true" {
-		secureFlag = true
-	} else if os.Getenv("ENV") == "production" {
-		secureFlag = true
-	} else if c.Request.TLS != nil {
-		secureFlag = true
+	// The user can request another link if this one fails to send
+	if err := h.verificationService.SendVerification(user.ID); err != nil {
+		log.Printf("warning: failed to send verification email to user %s: %v", user.ID, err)
 	}
 
-	sameSite := http.SameSiteNoneMode
-	if os.Getenv("ENV") == "production" {
-		sameSite = http.SameSiteLaxMode
+	tokens := startSession(c, h.sessionService, user, sessionMetadata(c, req.DeviceName))
+	if tokens == nil {
+		return
 	}
 
-	http.SetCookie(c.Writer, &http.Cookie{
-		Name:     "auth_token",
-		Value:    token,
-		Path:     "/",
-		Domain:   cookieDomain,
-		HttpOnly: true,
-		Secure:   secureFlag,
-		SameSite: sameSite,
-		MaxAge:   60 * 60 * 24 * 7,
-	})
-
-	c.JSON(http.StatusCreated, AuthResponse{
-		Token: token,
-		User:  user,
-	})
+	c.JSON(http.StatusCreated, newAuthResponse(tokens, user))
 }
 
 func (h *AuthHandler) SignIn(c *gin.Context) {
@@ -118,94 +211,91 @@ func (h *AuthHandler) SignIn(c *gin.Context) {
 		return
 	}
 
-	user, err := h.userRepo.GetUserByEmail(req.Email)
-	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid credentials"})
+	if err := h.loginThrottle.Check(req.Email, c.ClientIP()); err != nil {
+		respondThrottled(c, err)
 		return
 	}
 
-	passwordHash, err := h.userRepo.GetPasswordHash(req.Email)
+	// Unknown accounts and wrong passwords are indistinguishable, including
+	// in how long they take
+	passwordHash := dummyPasswordHash()
+	user, err := h.userRepo.GetUserByEmail(req.Email)
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid credentials"})
-		return
+		user = nil
 	}
-
-	if err := bcrypt.CompareHashAndPassword([]byte(passwordHash), []byte(req.Password)); err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid credentials"})
+	if user != nil {
+		if hash, err := h.userRepo.GetPasswordHash(req.Email); err == nil && hash != "" {
+			passwordHash = []byte(hash)
+		}
+	}
+	if bcrypt.CompareHashAndPassword(passwordHash, []byte(req.Password)) != nil || user == nil {
+		if err := h.loginThrottle.RecordFailure(req.Email, c.ClientIP()); err != nil {
+			log.Printf("warning: failed to record failed sign-in: %v", err)
+		}
+		c.JSON(http.StatusUnauthorized, gin.H{"error": localize(c, "errors.invalid_credentials")})
 		return
 	}
+	if err := h.loginThrottle.RecordSuccess(req.Email); err != nil {
+		log.Printf("warning: failed to clear failed sign-ins: %v", err)
+	}
 
-	token, err := auth.GenerateToken(user.ID, user.Email)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to generate token"})
-		return
+	completeSignIn(c, h.sessionService, h.mfaService, user, req.DeviceName)
+}
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refreshToken"`
+}
+
+// refreshToken returns the refresh token from the request body, falling back
+// to the refresh_token cookie set for browsers.
+func refreshToken(c *gin.Context) string {
+	var req RefreshRequest
+	if c.Request.ContentLength != 0 {
+		_ = c.ShouldBindJSON(&req)
+	}
+	if req.RefreshToken == "" {
+		req.RefreshToken, _ = c.Cookie(refreshTokenCookie)
 	}
+	return req.RefreshToken
+}
 
-	// Set HttpOnly cookie
-	cookieDomain := os.Getenv("AUTH_COOKIE_DOMAIN")
-	secureFlag := false
-	if os.Getenv("AUTH_COOKIE_SECURE") == "true" {
-		secureFlag = true
+// Refresh handles POST /api/auth/refresh
+func (h *AuthHandler) Refresh(c *gin.Context) {
+	tokens, err := h.sessionService.Refresh(refreshToken(c), sessionMetadata(c, ""))
+	if errors.Is(err, service.ErrInvalidRefreshToken) || errors.Is(err, service.ErrRefreshTokenReused) {
+		clearSessionCookies(c)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": localize(c, "errors.invalid_refresh_token")})
+		return
 	}
-	if c.Request.TLS != nil {
-		secureFlag = true
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "errors.failed_to_generate_token")})
+		return
 	}
-	http.SetCookie(c.Writer, &http.Cookie{
-		Name:     "auth_token",
-		Value:    token,
-		Path:     "/",
-		Domain:   cookieDomain,
-		HttpOnly: true,
-		Secure:   secureFlag,
-		SameSite: http.SameSiteNoneMode,
-		MaxAge:   60 * 60 * 24 * 7,
-	})
 
-	c.JSON(http.StatusOK, AuthResponse{
-		Token: token,
-		User:  user,
-	})
+	setSessionCookies(c, tokens)
+	c.JSON(http.StatusOK, newAuthResponse(tokens, nil))
 }
 
-// Logout clears the auth cookie
+// Logout ends the session and clears the auth cookies
 func (h *AuthHandler) Logout(c *gin.Context) {
-	cookieDomain := os.Getenv("AUTH_COOKIE_DOMAIN")
-
-	secureFlag := false
-	if os.Getenv("AUTH_COOKIE_SECURE") == "true" {
-		secureFlag = true
-	} else if os.Getenv("ENV") == "production" {
-		secureFlag = true
+	if err := h.sessionService.End(refreshToken(c)); err != nil {
+		log.Printf("warning: failed to end session on logout: %v", err)
 	}
 
-	sameSite := http.SameSiteNoneMode
-	if os.Getenv("ENV") == "production" {
-		sameSite = http.SameSiteLaxMode
-	}
-
-	http.SetCookie(c.Writer, &http.Cookie{
-		Name:     "auth_token",
-		Value:    "",
-		Path:     "/",
-		Domain:   cookieDomain,
-		HttpOnly: true,
-		Secure:   secureFlag,
-		SameSite: sameSite,
-		MaxAge:   -1,
-	})
-	c.JSON(http.StatusOK, gin.H{"message": "logged out"})
+	clearSessionCookies(c)
+	c.JSON(http.StatusOK, gin.H{"message": localize(c, "messages.logged_out")})
 }
 
 func (h *AuthHandler) GetCurrentUser(c *gin.Context) {
 	userID, exists := c.Get("userID")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not found in context"})
+		c.JSON(http.StatusUnauthorized, gin.H{"error": localize(c, "errors.user_not_found_in_context")})
 		return
 	}
 
 	user, err := h.userRepo.GetUserByID(userID.(string))
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		c.JSON(http.StatusNotFound, gin.H{"error": localize(c, "errors.user_not_found")})
 		return
 	}
 
@@ -215,7 +305,14 @@ func (h *AuthHandler) GetCurrentUser(c *gin.Context) {
 func (h *AuthHandler) CheckEmailAvailability(c *gin.Context) {
 	email := c.Query("email")
 	if email == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "email is required"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "errors.email_required")})
+		return
+	}
+
+	// Each answer reveals whether an address is registered, so clients may
+	// only ask a few times
+	if err := h.loginThrottle.AllowLookup("check-email", c.ClientIP()); err != nil {
+		respondThrottled(c, err)
 		return
 	}
 
@@ -239,30 +336,221 @@ func (h *AuthHandler) ResetPassword(c *gin.Context) {
 		return
 	}
 
-	userID, expiresAt, err := h.prRepo.FindByToken(req.Token)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid or expired token"})
+	selector, tokenHash, ok := auth.ParseSplitToken(req.Token)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "errors.invalid_or_expired_token")})
+		return
+	}
+	reset, err := h.prRepo.FindBySelector(selector)
+	if err != nil || !auth.VerifySplitToken(tokenHash, reset.TokenHash) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "errors.invalid_or_expired_token")})
 		return
 	}
+	userID := reset.UserID
 
-	if time.Now().After(expiresAt) {
-		_ = h.prRepo.DeleteByToken(req.Token)
-		c.JSON(http.StatusBadRequest, gin.H{"error": "token expired"})
+	if time.Now().After(reset.ExpiresAt) {
+		_, _ = h.prRepo.Consume(selector)
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "errors.token_expired")})
 		return
 	}
 
 	hashed, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to hash password"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "errors.failed_to_hash_password")})
+		return
+	}
+
+	// Using up the token first means concurrent requests cannot both use it
+	used, err := h.prRepo.Consume(selector)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "errors.failed_to_update_password")})
+		return
+	}
+	if !used {
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "errors.invalid_or_expired_token")})
 		return
 	}
 
 	if err := h.userRepo.UpdatePassword(userID, string(hashed)); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update password"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "errors.failed_to_update_password")})
+		return
+	}
+
+	// Whoever knew the old password may still be signed in
+	if err := h.sessionService.RevokeAll(userID, "", repository.SessionRevokedPasswordReset); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "errors.failed_to_revoke_sessions")})
+		return
+	}
+
+	if user, err := h.userRepo.GetUserByID(userID); err != nil || user == nil {
+		log.Printf("warning: failed to load user %s to confirm password reset: %v", userID, err)
+	} else if err := h.emailService.SendPasswordChanged(user.ID, user.Email); err != nil {
+		log.Printf("warning: failed to queue password changed email for user %s: %v", user.ID, err)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": localize(c, "messages.password_has_been_reset")})
+}
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"currentPassword" binding:"required"`
+	NewPassword     string `json:"newPassword" binding:"required,min=8"`
+}
+
+// ChangePassword handles POST /api/auth/change-password. Every session is
+// revoked and the requesting device is issued a new one.
+func (h *AuthHandler) ChangePassword(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": localize(c, "errors.user_not_found_in_context")})
 		return
 	}
 
-	_ = h.prRepo.DeleteByToken(req.Token)
+	var req ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, err := h.userRepo.GetUserByID(userID.(string))
+	if err != nil || user == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": localize(c, "errors.user_not_found")})
+		return
+	}
+
+	passwordHash, err := h.userRepo.GetPasswordHash(user.Email)
+	if err != nil || bcrypt.CompareHashAndPassword([]byte(passwordHash), []byte(req.CurrentPassword)) != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": localize(c, "errors.invalid_current_password")})
+		return
+	}
+
+	hashed, err := bcrypt.GenerateFromPassword([]byte(req.NewPassword), bcrypt.DefaultCost)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "errors.failed_to_hash_password")})
+		return
+	}
+
+	if err := h.userRepo.UpdatePassword(user.ID, string(hashed)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "errors.failed_to_update_password")})
+		return
+	}
+
+	if err := h.sessionService.RevokeAll(user.ID, "", repository.SessionRevokedPasswordChange); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "errors.failed_to_revoke_sessions")})
+		return
+	}
+
+	if err := h.emailService.SendPasswordChanged(user.ID, user.Email); err != nil {
+		log.Printf("warning: failed to send password changed email to user %s: %v", user.ID, err)
+	}
+
+	// The new session keeps the second factor the old one was started with
+	meta := sessionMetadata(c, "")
+	meta.MFAVerified = c.GetBool("mfa")
+	tokens := startSession(c, h.sessionService, user, meta)
+	if tokens == nil {
+		return
+	}
+
+	c.JSON(http.StatusOK, newAuthResponse(tokens, user))
+}
+
+type VerifyEmailRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// VerifyEmail handles POST /api/auth/verify-email
+func (h *AuthHandler) VerifyEmail(c *gin.Context) {
+	var req VerifyEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err := h.verificationService.Verify(req.Token)
+	if errors.Is(err, service.ErrInvalidVerificationToken) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "errors.invalid_or_expired_token")})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "errors.failed_to_verify_email")})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": localize(c, "messages.email_verified")})
+}
+
+// ResendVerification handles POST /api/auth/resend-verification
+func (h *AuthHandler) ResendVerification(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": localize(c, "errors.user_not_found_in_context")})
+		return
+	}
+
+	err := h.verificationService.SendVerification(userID.(string))
+	switch {
+	case errors.Is(err, service.ErrEmailAlreadyVerified):
+		c.JSON(http.StatusConflict, gin.H{"error": localize(c, "errors.email_already_verified")})
+	case errors.Is(err, service.ErrVerificationEmailRateLimited):
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": localize(c, "errors.verification_email_rate_limited")})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "errors.failed_to_send_verification_email")})
+	default:
+		c.JSON(http.StatusOK, gin.H{"message": localize(c, "messages.verification_email_sent")})
+	}
+}
+
+// ListSessions handles GET /api/auth/sessions
+func (h *AuthHandler) ListSessions(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": localize(c, "errors.user_not_found_in_context")})
+		return
+	}
+
+	sessions, err := h.sessionService.List(userID.(string), c.GetString("sessionID"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "errors.failed_to_fetch_sessions")})
+		return
+	}
+
+	c.JSON(http.StatusOK, sessions)
+}
+
+// RevokeSession handles DELETE /api/auth/sessions/:id
+func (h *AuthHandler) RevokeSession(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": localize(c, "errors.user_not_found_in_context")})
+		return
+	}
+
+	err := h.sessionService.Revoke(userID.(string), c.Param("id"))
+	if errors.Is(err, service.ErrSessionNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": localize(c, "errors.session_not_found")})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "errors.failed_to_revoke_sessions")})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": localize(c, "messages.session_revoked")})
+}
+
+// RevokeOtherSessions handles DELETE /api/auth/sessions, signing the user out
+// everywhere except the current device
+func (h *AuthHandler) RevokeOtherSessions(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": localize(c, "errors.user_not_found_in_context")})
+		return
+	}
+
+	if err := h.sessionService.RevokeAll(userID.(string), c.GetString("sessionID"), repository.SessionRevokedByUser); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "errors.failed_to_revoke_sessions")})
+		return
+	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "password has been reset"})
+	c.JSON(http.StatusOK, gin.H{"message": localize(c, "messages.sessions_revoked")})
 }
@@ -3,6 +3,7 @@ package handler
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
@@ -11,11 +12,58 @@ import (
 
 	"golang.org/x/crypto/bcrypt"
 
+	"gaspeep/backend/internal/auth"
+	"gaspeep/backend/internal/handler/testhelpers"
 	"gaspeep/backend/internal/models"
+	"gaspeep/backend/internal/repository"
+	"gaspeep/backend/internal/service"
 
 	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
+// newAllowingVerificationService returns a verification service mock that
+// accepts any verification email request.
+func newAllowingVerificationService() *testhelpers.MockEmailVerificationService {
+	m := new(testhelpers.MockEmailVerificationService)
+	m.On("SendVerification", mock.Anything).Return(nil).Maybe()
+	return m
+}
+
+// newAllowingSessionService returns a session service mock that starts a
+// session for anyone and accepts revocations.
+func newAllowingSessionService() *testhelpers.MockSessionService {
+	m := new(testhelpers.MockSessionService)
+	m.On("Start", mock.Anything, mock.Anything).Return(testhelpers.NewTestSessionTokens("access-token", "refresh-token"), nil).Maybe()
+	m.On("End", mock.Anything).Return(nil).Maybe()
+	m.On("RevokeAll", mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+	return m
+}
+
+// newNoMFAService returns an MFA service mock for users without two-factor
+// authentication.
+func newNoMFAService() *testhelpers.MockMFAService {
+	m := new(testhelpers.MockMFAService)
+	m.On("Enabled", mock.Anything).Return(false, nil).Maybe()
+	m.On("MFARequired", mock.Anything).Return(false, nil).Maybe()
+	return m
+}
+
+// newAllowingLoginThrottle returns a login throttle mock that never
+// throttles.
+func newAllowingLoginThrottle() *testhelpers.MockLoginThrottle {
+	m := new(testhelpers.MockLoginThrottle)
+	m.On("Check", mock.Anything, mock.Anything).Return(nil).Maybe()
+	m.On("RecordFailure", mock.Anything, mock.Anything).Return(nil).Maybe()
+	m.On("RecordSuccess", mock.Anything).Return(nil).Maybe()
+	m.On("CheckMFA", mock.Anything).Return(nil).Maybe()
+	m.On("RecordMFAFailure", mock.Anything, mock.Anything).Return(nil).Maybe()
+	m.On("RecordMFASuccess", mock.Anything).Return(nil).Maybe()
+	m.On("AllowLookup", mock.Anything, mock.Anything).Return(nil).Maybe()
+	return m
+}
+
 // mockUserRepo implements the minimal UserRepository behavior needed for the test.
 type mockUserRepo struct {
 	users     map[string]*models.User
@@ -67,7 +115,7 @@ func (m *mockUserRepo) GetPasswordHash(email string) (string, error) {
 func (m *mockUserRepo) UpdateUserTier(userID, tier string) error         { return nil }
 func (m *mockUserRepo) UpdatePassword(userID, passwordHash string) error { return nil }
 func (m *mockUserRepo) GetUserIDByEmail(email string) (string, error)    { return "", nil }
-func (m *mockUserRepo) UpdateProfile(userID, displayName, tier string) (string, error) {
+func (m *mockUserRepo) UpdateProfile(userID, displayName string) (string, error) {
 	return "", nil
 }
 func (m *mockUserRepo) GetMapFilterPreferences(userID string) (*models.MapFilterPreferences, error) {
@@ -76,6 +124,18 @@ func (m *mockUserRepo) GetMapFilterPreferences(userID string) (*models.MapFilter
 func (m *mockUserRepo) UpdateMapFilterPreferences(userID string, prefs models.MapFilterPreferences) error {
 	return nil
 }
+func (m *mockUserRepo) UpdateTimeZone(userID, timeZone string) error {
+	return nil
+}
+
+func (m *mockUserRepo) UpdateLocale(userID, locale string) error {
+	for _, u := range m.users {
+		if u.ID == userID {
+			u.Locale = locale
+		}
+	}
+	return nil
+}
 
 // TestSignInSetsAuthCookie verifies that signing in sets an HttpOnly auth cookie.
 func TestSignInSetsAuthCookie(t *testing.T) {
@@ -96,7 +156,7 @@ func TestSignInSetsAuthCookie(t *testing.T) {
 	repo.passwords[email] = string(hashed)
 
 	// Create handler with mock repo. pass nil for password reset repo since not used here
-	h := NewAuthHandler(repo, nil)
+	h := NewAuthHandler(repo, nil, newAllowingVerificationService(), newAllowingSessionService(), newNoMFAService(), newAllowingLoginThrottle(), nil)
 
 	router := gin.New()
 	router.POST("/api/auth/signin", h.SignIn)
@@ -141,7 +201,7 @@ func TestSignUp_ValidRegistration(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
 	repo := newMockUserRepo()
-	h := NewAuthHandler(repo, nil)
+	h := NewAuthHandler(repo, nil, newAllowingVerificationService(), newAllowingSessionService(), newNoMFAService(), newAllowingLoginThrottle(), nil)
 
 	router := gin.New()
 	router.POST("/api/auth/signup", h.SignUp)
@@ -151,7 +211,8 @@ func TestSignUp_ValidRegistration(t *testing.T) {
 		"email":       email,
 		"password":    "SecurePassword123",
 		"displayName": "New User",
-		"tier":        "free",
+		// Tiers come from subscriptions, so a requested tier is ignored
+		"tier": "premium",
 	}
 	body, _ := json.Marshal(payload)
 
@@ -210,7 +271,7 @@ func TestSignUp_EmailConflict(t *testing.T) {
 		Email: email,
 	}
 
-	h := NewAuthHandler(repo, nil)
+	h := NewAuthHandler(repo, nil, newAllowingVerificationService(), newAllowingSessionService(), newNoMFAService(), newAllowingLoginThrottle(), nil)
 
 	router := gin.New()
 	router.POST("/api/auth/signup", h.SignUp)
@@ -245,7 +306,7 @@ func TestSignUp_InvalidEmail(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
 	repo := newMockUserRepo()
-	h := NewAuthHandler(repo, nil)
+	h := NewAuthHandler(repo, nil, newAllowingVerificationService(), newAllowingSessionService(), newNoMFAService(), newAllowingLoginThrottle(), nil)
 
 	router := gin.New()
 	router.POST("/api/auth/signup", h.SignUp)
@@ -274,7 +335,7 @@ func TestSignUp_ShortPassword(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
 	repo := newMockUserRepo()
-	h := NewAuthHandler(repo, nil)
+	h := NewAuthHandler(repo, nil, newAllowingVerificationService(), newAllowingSessionService(), newNoMFAService(), newAllowingLoginThrottle(), nil)
 
 	router := gin.New()
 	router.POST("/api/auth/signup", h.SignUp)
@@ -303,16 +364,15 @@ func TestSignUp_MissingFields(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
 	repo := newMockUserRepo()
-	h := NewAuthHandler(repo, nil)
+	h := NewAuthHandler(repo, nil, newAllowingVerificationService(), newAllowingSessionService(), newNoMFAService(), newAllowingLoginThrottle(), nil)
 
 	router := gin.New()
 	router.POST("/api/auth/signup", h.SignUp)
 
 	tests := []map[string]string{
-		{"email": "test@example.com", "password": "pass123", "displayName": "Test"},
-		{"email": "test@example.com", "password": "pass123", "tier": "free"},
-		{"email": "test@example.com", "displayName": "Test", "tier": "free"},
-		{"password": "pass123", "displayName": "Test", "tier": "free"},
+		{"email": "test@example.com", "password": "pass123"},
+		{"email": "test@example.com", "displayName": "Test"},
+		{"password": "pass123", "displayName": "Test"},
 	}
 
 	for i, payload := range tests {
@@ -342,7 +402,7 @@ func TestSignIn_InvalidCredentials(t *testing.T) {
 	repo.users[email] = &models.User{ID: "u1", Email: email, DisplayName: "Tester"}
 	repo.passwords[email] = string(hashed)
 
-	h := NewAuthHandler(repo, nil)
+	h := NewAuthHandler(repo, nil, newAllowingVerificationService(), newAllowingSessionService(), newNoMFAService(), newAllowingLoginThrottle(), nil)
 
 	router := gin.New()
 	router.POST("/api/auth/signin", h.SignIn)
@@ -372,7 +432,7 @@ func TestSignIn_UserNotFound(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
 	repo := newMockUserRepo()
-	h := NewAuthHandler(repo, nil)
+	h := NewAuthHandler(repo, nil, newAllowingVerificationService(), newAllowingSessionService(), newNoMFAService(), newAllowingLoginThrottle(), nil)
 
 	router := gin.New()
 	router.POST("/api/auth/signin", h.SignIn)
@@ -396,7 +456,7 @@ func TestSignIn_MissingFields(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
 	repo := newMockUserRepo()
-	h := NewAuthHandler(repo, nil)
+	h := NewAuthHandler(repo, nil, newAllowingVerificationService(), newAllowingSessionService(), newNoMFAService(), newAllowingLoginThrottle(), nil)
 
 	router := gin.New()
 	router.POST("/api/auth/signin", h.SignIn)
@@ -426,7 +486,7 @@ func TestLogout_ClearsAuthCookie(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
 	repo := newMockUserRepo()
-	h := NewAuthHandler(repo, nil)
+	h := NewAuthHandler(repo, nil, newAllowingVerificationService(), newAllowingSessionService(), newNoMFAService(), newAllowingLoginThrottle(), nil)
 
 	router := gin.New()
 	router.POST("/api/auth/logout", h.Logout)
@@ -477,7 +537,7 @@ func TestGetCurrentUser_ValidContext(t *testing.T) {
 	}
 	repo.users[user.Email] = user
 
-	h := NewAuthHandler(repo, nil)
+	h := NewAuthHandler(repo, nil, newAllowingVerificationService(), newAllowingSessionService(), newNoMFAService(), newAllowingLoginThrottle(), nil)
 
 	router := gin.New()
 	router.GET("/api/auth/me", func(c *gin.Context) {
@@ -507,7 +567,7 @@ func TestGetCurrentUser_NoUserID(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
 	repo := newMockUserRepo()
-	h := NewAuthHandler(repo, nil)
+	h := NewAuthHandler(repo, nil, newAllowingVerificationService(), newAllowingSessionService(), newNoMFAService(), newAllowingLoginThrottle(), nil)
 
 	router := gin.New()
 	router.GET("/api/auth/me", h.GetCurrentUser)
@@ -527,7 +587,7 @@ func TestCheckEmailAvailability_Available(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
 	repo := newMockUserRepo()
-	h := NewAuthHandler(repo, nil)
+	h := NewAuthHandler(repo, nil, newAllowingVerificationService(), newAllowingSessionService(), newNoMFAService(), newAllowingLoginThrottle(), nil)
 
 	router := gin.New()
 	router.GET("/api/auth/check-email", h.CheckEmailAvailability)
@@ -557,7 +617,7 @@ func TestCheckEmailAvailability_Taken(t *testing.T) {
 	email := "taken@example.com"
 	repo.users[email] = &models.User{ID: "u1", Email: email}
 
-	h := NewAuthHandler(repo, nil)
+	h := NewAuthHandler(repo, nil, newAllowingVerificationService(), newAllowingSessionService(), newNoMFAService(), newAllowingLoginThrottle(), nil)
 
 	router := gin.New()
 	router.GET("/api/auth/check-email", h.CheckEmailAvailability)
@@ -584,7 +644,7 @@ func TestCheckEmailAvailability_MissingEmail(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
 	repo := newMockUserRepo()
-	h := NewAuthHandler(repo, nil)
+	h := NewAuthHandler(repo, nil, newAllowingVerificationService(), newAllowingSessionService(), newNoMFAService(), newAllowingLoginThrottle(), nil)
 
 	router := gin.New()
 	router.GET("/api/auth/check-email", h.CheckEmailAvailability)
@@ -601,39 +661,48 @@ func TestCheckEmailAvailability_MissingEmail(t *testing.T) {
 
 // mockPasswordResetRepo implements PasswordResetRepository for testing
 type mockPasswordResetRepo struct {
-	tokens map[string]struct {
-		userID    string
-		expiresAt time.Time
-	}
+	tokens map[string]*repository.PasswordReset
 }
 
 func newMockPasswordResetRepo() *mockPasswordResetRepo {
-	return &mockPasswordResetRepo{
-		tokens: map[string]struct {
-			userID    string
-			expiresAt time.Time
-		}{},
+	return &mockPasswordResetRepo{tokens: map[string]*repository.PasswordReset{}}
+}
+
+// issue creates a token for the user and returns it as sent in a reset link.
+func (m *mockPasswordResetRepo) issue(t *testing.T, userID string, expiresAt time.Time) string {
+	token, selector, tokenHash, err := auth.NewSplitToken()
+	if err != nil {
+		t.Fatalf("failed to create reset token: %v", err)
 	}
+	m.Create(userID, selector, tokenHash, expiresAt)
+	return token
 }
 
-func (m *mockPasswordResetRepo) Create(userID, token string, expiresAt time.Time) error {
-	m.tokens[token] = struct {
-		userID    string
-		expiresAt time.Time
-	}{userID, expiresAt}
+func (m *mockPasswordResetRepo) Create(userID, selector, tokenHash string, expiresAt time.Time) error {
+	for s, reset := range m.tokens {
+		if reset.UserID == userID {
+			delete(m.tokens, s)
+		}
+	}
+	m.tokens[selector] = &repository.PasswordReset{UserID: userID, TokenHash: tokenHash, ExpiresAt: expiresAt}
 	return nil
 }
 
-func (m *mockPasswordResetRepo) FindByToken(token string) (string, time.Time, error) {
-	if t, ok := m.tokens[token]; ok {
-		return t.userID, t.expiresAt, nil
+func (m *mockPasswordResetRepo) FindBySelector(selector string) (*repository.PasswordReset, error) {
+	if reset, ok := m.tokens[selector]; ok {
+		return reset, nil
 	}
-	return "", time.Time{}, fmt.Errorf("token not found")
+	return nil, fmt.Errorf("token not found")
 }
 
-func (m *mockPasswordResetRepo) DeleteByToken(token string) error {
-	delete(m.tokens, token)
-	return nil
+func (m *mockPasswordResetRepo) Consume(selector string) (bool, error) {
+	_, ok := m.tokens[selector]
+	delete(m.tokens, selector)
+	return ok, nil
+}
+
+func (m *mockPasswordResetRepo) DeleteExpiredBefore(cutoff time.Time) (int64, error) {
+	return 0, nil
 }
 
 // TestResetPassword_ValidToken verifies password reset with valid token
@@ -646,11 +715,11 @@ func TestResetPassword_ValidToken(t *testing.T) {
 	userID := "user123"
 	userRepo.users["user@example.com"] = &models.User{ID: userID, Email: "user@example.com"}
 
-	token := "valid_reset_token"
-	expiresAt := time.Now().Add(1 * time.Hour)
-	prRepo.Create(userID, token, expiresAt)
+	token := prRepo.issue(t, userID, time.Now().Add(1*time.Hour))
 
-	h := NewAuthHandler(userRepo, prRepo)
+	emails := new(testhelpers.MockEmailService)
+	emails.On("SendPasswordChanged", userID, "user@example.com").Return(nil).Once()
+	h := NewAuthHandler(userRepo, prRepo, newAllowingVerificationService(), newAllowingSessionService(), newNoMFAService(), newAllowingLoginThrottle(), emails)
 
 	router := gin.New()
 	router.POST("/api/auth/reset-password", h.ResetPassword)
@@ -679,10 +748,19 @@ func TestResetPassword_ValidToken(t *testing.T) {
 	}
 
 	// Token should be deleted
-	_, _, err := prRepo.FindByToken(token)
-	if err == nil {
+	if len(prRepo.tokens) != 0 {
 		t.Fatalf("expected token to be deleted")
 	}
+	emails.AssertExpectations(t)
+
+	// and cannot be used again
+	req = httptest.NewRequest(http.MethodPost, "/api/auth/reset-password", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected reused token to be rejected, got %d", rr.Code)
+	}
 }
 
 // TestResetPassword_ExpiredToken verifies rejection of expired token
@@ -695,11 +773,9 @@ func TestResetPassword_ExpiredToken(t *testing.T) {
 	userID := "user123"
 	userRepo.users["user@example.com"] = &models.User{ID: userID, Email: "user@example.com"}
 
-	token := "expired_token"
-	expiresAt := time.Now().Add(-1 * time.Hour) // Past time
-	prRepo.Create(userID, token, expiresAt)
+	token := prRepo.issue(t, userID, time.Now().Add(-1*time.Hour)) // Past time
 
-	h := NewAuthHandler(userRepo, prRepo)
+	h := NewAuthHandler(userRepo, prRepo, newAllowingVerificationService(), newAllowingSessionService(), newNoMFAService(), newAllowingLoginThrottle(), nil)
 
 	router := gin.New()
 	router.POST("/api/auth/reset-password", h.ResetPassword)
@@ -734,7 +810,7 @@ func TestResetPassword_InvalidToken(t *testing.T) {
 	userRepo := newMockUserRepo()
 	prRepo := newMockPasswordResetRepo()
 
-	h := NewAuthHandler(userRepo, prRepo)
+	h := NewAuthHandler(userRepo, prRepo, newAllowingVerificationService(), newAllowingSessionService(), newNoMFAService(), newAllowingLoginThrottle(), nil)
 
 	router := gin.New()
 	router.POST("/api/auth/reset-password", h.ResetPassword)
@@ -756,6 +832,32 @@ func TestResetPassword_InvalidToken(t *testing.T) {
 	}
 }
 
+// TestResetPassword_TamperedAndReplacedTokens verifies a token is only
+// accepted with its exact secret, and not after a newer one was requested.
+func TestResetPassword_TamperedAndReplacedTokens(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	userRepo := newMockUserRepo()
+	prRepo := newMockPasswordResetRepo()
+	h := NewAuthHandler(userRepo, prRepo, newAllowingVerificationService(), newAllowingSessionService(), newNoMFAService(), newAllowingLoginThrottle(), nil)
+
+	router := gin.New()
+	router.POST("/api/auth/reset-password", h.ResetPassword)
+
+	old := prRepo.issue(t, "user123", time.Now().Add(time.Hour))
+	current := prRepo.issue(t, "user123", time.Now().Add(time.Hour))
+	tampered := current[:len(current)-1] + "0"
+	if tampered == current {
+		tampered = current[:len(current)-1] + "1"
+	}
+
+	for _, token := range []string{old, tampered} {
+		rr := postJSON(router, "/api/auth/reset-password", map[string]string{"token": token, "password": "NewPassword123"})
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	}
+	assert.Len(t, prRepo.tokens, 1, "rejected tokens must not use up the current one")
+}
+
 // TestResetPassword_ShortPassword verifies password minimum length requirement
 func TestResetPassword_ShortPassword(t *testing.T) {
 	gin.SetMode(gin.TestMode)
@@ -763,7 +865,7 @@ func TestResetPassword_ShortPassword(t *testing.T) {
 	userRepo := newMockUserRepo()
 	prRepo := newMockPasswordResetRepo()
 
-	h := NewAuthHandler(userRepo, prRepo)
+	h := NewAuthHandler(userRepo, prRepo, newAllowingVerificationService(), newAllowingSessionService(), newNoMFAService(), newAllowingLoginThrottle(), nil)
 
 	router := gin.New()
 	router.POST("/api/auth/reset-password", h.ResetPassword)
@@ -792,7 +894,7 @@ func TestResetPassword_MissingFields(t *testing.T) {
 	userRepo := newMockUserRepo()
 	prRepo := newMockPasswordResetRepo()
 
-	h := NewAuthHandler(userRepo, prRepo)
+	h := NewAuthHandler(userRepo, prRepo, newAllowingVerificationService(), newAllowingSessionService(), newNoMFAService(), newAllowingLoginThrottle(), nil)
 
 	router := gin.New()
 	router.POST("/api/auth/reset-password", h.ResetPassword)
@@ -816,3 +918,423 @@ func TestResetPassword_MissingFields(t *testing.T) {
 		}
 	}
 }
+
+// TestSignUp_SendsVerificationEmail verifies that signing up sends a verification email
+func TestSignUp_SendsVerificationEmail(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	repo := newMockUserRepo()
+	verification := new(testhelpers.MockEmailVerificationService)
+	verification.On("SendVerification", "u1").Return(nil)
+	h := NewAuthHandler(repo, nil, verification, newAllowingSessionService(), newNoMFAService(), newAllowingLoginThrottle(), nil)
+
+	router := gin.New()
+	router.POST("/api/auth/signup", h.SignUp)
+
+	body, _ := json.Marshal(map[string]string{
+		"email":       "verify@example.com",
+		"password":    "SecurePassword123",
+		"displayName": "Verify Me",
+		"tier":        "free",
+	})
+	req := httptest.NewRequest(http.MethodPost, "/api/auth/signup", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusCreated, rr.Code)
+	verification.AssertExpectations(t)
+}
+
+func TestVerifyEmail(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name       string
+		body       string
+		verifyErr  error
+		wantStatus int
+	}{
+		{name: "valid token", body: `{"token":"abc"}`, wantStatus: http.StatusOK},
+		{name: "invalid token", body: `{"token":"abc"}`, verifyErr: service.ErrInvalidVerificationToken, wantStatus: http.StatusBadRequest},
+		{name: "missing token", body: `{}`, wantStatus: http.StatusBadRequest},
+		{name: "storage error", body: `{"token":"abc"}`, verifyErr: fmt.Errorf("db down"), wantStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verification := new(testhelpers.MockEmailVerificationService)
+			verification.On("Verify", "abc").Return(tt.verifyErr).Maybe()
+			h := NewAuthHandler(newMockUserRepo(), nil, verification, newAllowingSessionService(), newNoMFAService(), newAllowingLoginThrottle(), nil)
+
+			router := gin.New()
+			router.POST("/api/auth/verify-email", h.VerifyEmail)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/auth/verify-email", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.wantStatus, rr.Code)
+		})
+	}
+}
+
+func TestResendVerification(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name       string
+		sendErr    error
+		wantStatus int
+	}{
+		{name: "sent", wantStatus: http.StatusOK},
+		{name: "already verified", sendErr: service.ErrEmailAlreadyVerified, wantStatus: http.StatusConflict},
+		{name: "rate limited", sendErr: service.ErrVerificationEmailRateLimited, wantStatus: http.StatusTooManyRequests},
+		{name: "storage error", sendErr: fmt.Errorf("db down"), wantStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verification := new(testhelpers.MockEmailVerificationService)
+			verification.On("SendVerification", "user-1").Return(tt.sendErr)
+			h := NewAuthHandler(newMockUserRepo(), nil, verification, newAllowingSessionService(), newNoMFAService(), newAllowingLoginThrottle(), nil)
+
+			router := gin.New()
+			router.POST("/api/auth/resend-verification", func(c *gin.Context) {
+				c.Set("userID", "user-1")
+				h.ResendVerification(c)
+			})
+
+			req := httptest.NewRequest(http.MethodPost, "/api/auth/resend-verification", nil)
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.wantStatus, rr.Code)
+			verification.AssertExpectations(t)
+		})
+	}
+}
+
+func TestRefresh(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	sessions := new(testhelpers.MockSessionService)
+	sessions.On("Refresh", "from-body", mock.Anything).Return(testhelpers.NewTestSessionTokens("access-2", "refresh-2"), nil).Once()
+	sessions.On("Refresh", "from-cookie", mock.Anything).Return(testhelpers.NewTestSessionTokens("access-3", "refresh-3"), nil).Once()
+	sessions.On("Refresh", "replayed", mock.Anything).Return(nil, service.ErrRefreshTokenReused).Once()
+	h := NewAuthHandler(newMockUserRepo(), nil, newAllowingVerificationService(), sessions, newNoMFAService(), newAllowingLoginThrottle(), nil)
+
+	router := gin.New()
+	router.POST("/api/auth/refresh", h.Refresh)
+
+	// Mobile clients send the refresh token in the body
+	req := httptest.NewRequest(http.MethodPost, "/api/auth/refresh", bytes.NewReader([]byte(`{"refreshToken":"from-body"}`)))
+	req.Header.Set("Content-Type", "application/json")
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var response AuthResponse
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+	assert.Equal(t, "access-2", response.Token)
+	assert.Equal(t, "refresh-2", response.RefreshToken)
+	assert.InDelta(t, 15*60, response.ExpiresIn, 5)
+
+	// Browsers send the refresh_token cookie
+	req = httptest.NewRequest(http.MethodPost, "/api/auth/refresh", nil)
+	req.AddCookie(&http.Cookie{Name: "refresh_token", Value: "from-cookie"})
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	cookies := map[string]*http.Cookie{}
+	for _, c := range rr.Result().Cookies() {
+		cookies[c.Name] = c
+	}
+	assert.Equal(t, "access-3", cookies["auth_token"].Value)
+	assert.Equal(t, "refresh-3", cookies["refresh_token"].Value)
+	assert.Equal(t, "/api/auth", cookies["refresh_token"].Path)
+
+	// A reused token signs the browser out
+	req = httptest.NewRequest(http.MethodPost, "/api/auth/refresh", nil)
+	req.AddCookie(&http.Cookie{Name: "refresh_token", Value: "replayed"})
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	for _, c := range rr.Result().Cookies() {
+		assert.Equal(t, -1, c.MaxAge, c.Name)
+	}
+
+	sessions.AssertExpectations(t)
+}
+
+func TestLogout_EndsSession(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	sessions := new(testhelpers.MockSessionService)
+	sessions.On("End", "refresh-1").Return(nil).Once()
+	h := NewAuthHandler(newMockUserRepo(), nil, newAllowingVerificationService(), sessions, newNoMFAService(), newAllowingLoginThrottle(), nil)
+
+	router := gin.New()
+	router.POST("/api/auth/logout", h.Logout)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/auth/logout", nil)
+	req.AddCookie(&http.Cookie{Name: "refresh_token", Value: "refresh-1"})
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	sessions.AssertExpectations(t)
+}
+
+func TestChangePassword_RevokesSessions(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	repo := newMockUserRepo()
+	user := &models.User{ID: "user-1", Email: "user@example.com"}
+	repo.users[user.Email] = user
+	hash, _ := bcrypt.GenerateFromPassword([]byte("OldPassword1"), bcrypt.MinCost)
+	repo.passwords[user.Email] = string(hash)
+
+	sessions := new(testhelpers.MockSessionService)
+	sessions.On("RevokeAll", "user-1", "", repository.SessionRevokedPasswordChange).Return(nil).Once()
+	sessions.On("Start", user, mock.Anything).Return(testhelpers.NewTestSessionTokens("access-2", "refresh-2"), nil).Once()
+	emails := new(testhelpers.MockEmailService)
+	emails.On("SendPasswordChanged", "user-1", "user@example.com").Return(nil).Once()
+	h := NewAuthHandler(repo, nil, newAllowingVerificationService(), sessions, newNoMFAService(), newAllowingLoginThrottle(), emails)
+
+	router := gin.New()
+	router.POST("/api/auth/change-password", func(c *gin.Context) {
+		c.Set("userID", "user-1")
+		h.ChangePassword(c)
+	})
+
+	post := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/auth/change-password", bytes.NewReader([]byte(body)))
+		req.Header.Set("Content-Type", "application/json")
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := post(`{"currentPassword":"wrong-password","newPassword":"NewPassword1"}`)
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	sessions.AssertNotCalled(t, "RevokeAll", mock.Anything, mock.Anything, mock.Anything)
+
+	rr = post(`{"currentPassword":"OldPassword1","newPassword":"NewPassword1"}`)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var response AuthResponse
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+	assert.Equal(t, "access-2", response.Token)
+
+	sessions.AssertExpectations(t)
+	emails.AssertExpectations(t)
+}
+
+func TestResetPassword_RevokesSessions(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	userRepo := newMockUserRepo()
+	prRepo := newMockPasswordResetRepo()
+	token := prRepo.issue(t, "user123", time.Now().Add(time.Hour))
+
+	sessions := new(testhelpers.MockSessionService)
+	sessions.On("RevokeAll", "user123", "", repository.SessionRevokedPasswordReset).Return(nil).Once()
+	h := NewAuthHandler(userRepo, prRepo, newAllowingVerificationService(), sessions, newNoMFAService(), newAllowingLoginThrottle(), nil)
+
+	router := gin.New()
+	router.POST("/api/auth/reset-password", h.ResetPassword)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/auth/reset-password", bytes.NewReader([]byte(`{"token":"`+token+`","password":"NewPassword1"}`)))
+	req.Header.Set("Content-Type", "application/json")
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	sessions.AssertExpectations(t)
+}
+
+func TestSessions_ListAndRevoke(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	sessions := new(testhelpers.MockSessionService)
+	sessions.On("List", "user-1", "session-1").Return([]models.Session{{ID: "session-1", Current: true}, {ID: "session-2"}}, nil).Once()
+	sessions.On("Revoke", "user-1", "session-2").Return(nil).Once()
+	sessions.On("Revoke", "user-1", "someone-elses").Return(service.ErrSessionNotFound).Once()
+	sessions.On("RevokeAll", "user-1", "session-1", repository.SessionRevokedByUser).Return(nil).Once()
+	h := NewAuthHandler(newMockUserRepo(), nil, newAllowingVerificationService(), sessions, newNoMFAService(), newAllowingLoginThrottle(), nil)
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("userID", "user-1")
+		c.Set("sessionID", "session-1")
+	})
+	router.GET("/api/auth/sessions", h.ListSessions)
+	router.DELETE("/api/auth/sessions", h.RevokeOtherSessions)
+	router.DELETE("/api/auth/sessions/:id", h.RevokeSession)
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/auth/sessions", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var listed []map[string]any
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &listed))
+	assert.Len(t, listed, 2)
+	assert.Equal(t, true, listed[0]["current"])
+
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodDelete, "/api/auth/sessions/session-2", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodDelete, "/api/auth/sessions/someone-elses", nil))
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodDelete, "/api/auth/sessions", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	sessions.AssertExpectations(t)
+}
+
+func TestSignIn_Throttled(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	throttle := new(testhelpers.MockLoginThrottle)
+	throttle.On("Check", "test@example.com", mock.Anything).Return(&service.ThrottledError{RetryAfter: 4 * time.Second}).Once()
+	h := NewAuthHandler(newMockUserRepo(), nil, newAllowingVerificationService(), newAllowingSessionService(), newNoMFAService(), throttle, nil)
+
+	router := gin.New()
+	router.POST("/api/auth/signin", h.SignIn)
+
+	rr := postJSON(router, "/api/auth/signin", map[string]string{"email": "test@example.com", "password": "password123"})
+
+	assert.Equal(t, http.StatusTooManyRequests, rr.Code)
+	assert.Equal(t, "5", rr.Header().Get("Retry-After"))
+	throttle.AssertExpectations(t)
+}
+
+// TestSignIn_UnknownAccountLooksLikeWrongPassword verifies both failures
+// are counted and get the same response.
+func TestSignIn_UnknownAccountLooksLikeWrongPassword(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	repo := newMockUserRepo()
+	hashed, _ := bcrypt.GenerateFromPassword([]byte("CorrectPassword123"), bcrypt.MinCost)
+	repo.users["known@example.com"] = &models.User{ID: "u1", Email: "known@example.com"}
+	repo.passwords["known@example.com"] = string(hashed)
+
+	throttle := new(testhelpers.MockLoginThrottle)
+	throttle.On("Check", mock.Anything, mock.Anything).Return(nil)
+	throttle.On("RecordFailure", "known@example.com", mock.Anything).Return(nil).Once()
+	throttle.On("RecordFailure", "unknown@example.com", mock.Anything).Return(nil).Once()
+	h := NewAuthHandler(repo, nil, newAllowingVerificationService(), newAllowingSessionService(), newNoMFAService(), throttle, nil)
+
+	router := gin.New()
+	router.POST("/api/auth/signin", h.SignIn)
+
+	known := postJSON(router, "/api/auth/signin", map[string]string{"email": "known@example.com", "password": "WrongPassword123"})
+	unknown := postJSON(router, "/api/auth/signin", map[string]string{"email": "unknown@example.com", "password": "WrongPassword123"})
+
+	assert.Equal(t, http.StatusUnauthorized, known.Code)
+	assert.Equal(t, known.Code, unknown.Code)
+	assert.Equal(t, known.Body.String(), unknown.Body.String())
+	throttle.AssertExpectations(t)
+}
+
+// TestSignIn_LocksAccountAfterRepeatedFailures uses the real throttle, so
+// the lock holds even for the right password.
+func TestSignIn_LocksAccountAfterRepeatedFailures(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	t.Setenv("LOGIN_FREE_ATTEMPTS", "2")
+	t.Setenv("LOGIN_MAX_ATTEMPTS", "2")
+
+	repo := newMockUserRepo()
+	hashed, _ := bcrypt.GenerateFromPassword([]byte("CorrectPassword123"), bcrypt.MinCost)
+	user := &models.User{ID: "u1", Email: "test@example.com"}
+	repo.users[user.Email] = user
+	repo.passwords[user.Email] = string(hashed)
+
+	emailService := new(testhelpers.MockEmailService)
+	emailService.On("SendAccountLocked", "u1", user.Email, 15*time.Minute).Return(nil).Once()
+	throttle := service.NewLoginThrottle(repository.NewMemoryLoginAttemptRepository(), repo, emailService)
+	h := NewAuthHandler(repo, nil, newAllowingVerificationService(), newAllowingSessionService(), newNoMFAService(), throttle, nil)
+
+	router := gin.New()
+	router.POST("/api/auth/signin", h.SignIn)
+
+	for range 2 {
+		rr := postJSON(router, "/api/auth/signin", map[string]string{"email": user.Email, "password": "WrongPassword123"})
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	}
+	rr := postJSON(router, "/api/auth/signin", map[string]string{"email": user.Email, "password": "CorrectPassword123"})
+
+	assert.Equal(t, http.StatusTooManyRequests, rr.Code)
+	assert.NotEmpty(t, rr.Header().Get("Retry-After"))
+	emailService.AssertExpectations(t)
+}
+
+func TestCheckEmailAvailability_Throttled(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	throttle := new(testhelpers.MockLoginThrottle)
+	throttle.On("AllowLookup", "check-email", mock.Anything).Return(&service.ThrottledError{RetryAfter: time.Minute}).Once()
+	h := NewAuthHandler(newMockUserRepo(), nil, newAllowingVerificationService(), newAllowingSessionService(), newNoMFAService(), throttle, nil)
+
+	router := gin.New()
+	router.GET("/api/auth/check-email", h.CheckEmailAvailability)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/auth/check-email?email=test@example.com", nil)
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusTooManyRequests, rr.Code)
+	throttle.AssertExpectations(t)
+}
+
+func TestSignUp_ThrottledLikeEmailChecks(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	repo := newMockUserRepo()
+	repo.users["existing@example.com"] = &models.User{ID: "existing_user", Email: "existing@example.com"}
+	throttle := new(testhelpers.MockLoginThrottle)
+	throttle.On("AllowLookup", "check-email", []string{"192.0.2.1"}).Return(&service.ThrottledError{RetryAfter: time.Minute}).Once()
+	h := NewAuthHandler(repo, nil, newAllowingVerificationService(), newAllowingSessionService(), newNoMFAService(), throttle, nil)
+
+	router := gin.New()
+	router.POST("/api/auth/signup", h.SignUp)
+
+	rr := postJSON(router, "/api/auth/signup", map[string]string{
+		"email":       "existing@example.com",
+		"password":    "SecurePassword123",
+		"displayName": "Duplicate User",
+	})
+
+	assert.Equal(t, http.StatusTooManyRequests, rr.Code)
+	assert.NotEmpty(t, rr.Header().Get("Retry-After"))
+	throttle.AssertExpectations(t)
+}
+
+// failingCreateUserRepo fails to create users with a database error.
+type failingCreateUserRepo struct {
+	*mockUserRepo
+}
+
+func (m failingCreateUserRepo) CreateUser(email, passwordHash, displayName, tier string) (*models.User, error) {
+	return nil, errors.New(`pq: duplicate key value violates unique constraint "users_email_key"`)
+}
+
+func TestSignUp_CreateFailureHidesDatabaseError(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	h := NewAuthHandler(failingCreateUserRepo{newMockUserRepo()}, nil, newAllowingVerificationService(), newAllowingSessionService(), newNoMFAService(), newAllowingLoginThrottle(), nil)
+	router := gin.New()
+	router.POST("/api/auth/signup", h.SignUp)
+
+	rr := postJSON(router, "/api/auth/signup", map[string]string{
+		"email":       "new@example.com",
+		"password":    "SecurePassword123",
+		"displayName": "New User",
+	})
+
+	assert.Equal(t, http.StatusInternalServerError, rr.Code)
+	assert.JSONEq(t, `{"error":"failed to create user"}`, rr.Body.String())
+}
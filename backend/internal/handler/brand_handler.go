@@ -21,7 +21,7 @@ func NewBrandHandler(brandService service.BrandService) *BrandHandler {
 func (h *BrandHandler) GetBrands(c *gin.Context) {
 	brands, err := h.brandService.GetBrands()
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch brands"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "errors.failed_to_fetch_brands")})
 		return
 	}
 
@@ -34,11 +34,11 @@ func (h *BrandHandler) GetBrand(c *gin.Context) {
 
 	brand, err := h.brandService.GetBrand(id)
 	if err == sql.ErrNoRows {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Brand not found"})
+		c.JSON(http.StatusNotFound, gin.H{"error": localize(c, "errors.brand_not_found")})
 		return
 	}
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch brand"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "errors.failed_to_fetch_brand")})
 		return
 	}
 
@@ -2,6 +2,7 @@ package handler
 
 import (
 	"database/sql"
+	"errors"
 	"net/http"
 	"time"
 
@@ -20,11 +21,24 @@ func NewBroadcastHandler(broadcastService service.BroadcastService) *BroadcastHa
 	return &BroadcastHandler{broadcastService: broadcastService}
 }
 
+// respondBroadcastForbidden writes a 403 when err means the user may not
+// broadcast for the station, and reports whether it did.
+func respondBroadcastForbidden(c *gin.Context, err error) bool {
+	if respondPermissionDenied(c, err) {
+		return true
+	}
+	if !errors.Is(err, repository.ErrStationClaimNotApproved) {
+		return false
+	}
+	c.JSON(http.StatusForbidden, gin.H{"error": localize(c, "errors.station_broadcast_not_allowed")})
+	return true
+}
+
 // CreateBroadcast handles POST /api/broadcasts
 func (h *BroadcastHandler) CreateBroadcast(c *gin.Context) {
 	userID, exists := c.Get("userID")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		c.JSON(http.StatusUnauthorized, gin.H{"error": localize(c, "errors.user_not_authenticated")})
 		return
 	}
 
@@ -52,8 +66,11 @@ func (h *BroadcastHandler) CreateBroadcast(c *gin.Context) {
 		EndDate:         req.EndDate,
 		TargetFuelTypes: req.TargetFuelTypes,
 	})
+	if respondBroadcastForbidden(c, err) {
+		return
+	}
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create broadcast", "details": err.Error()})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "errors.failed_to_create_broadcast"), "details": err.Error()})
 		return
 	}
 
@@ -64,13 +81,13 @@ func (h *BroadcastHandler) CreateBroadcast(c *gin.Context) {
 func (h *BroadcastHandler) GetBroadcasts(c *gin.Context) {
 	userID, exists := c.Get("userID")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		c.JSON(http.StatusUnauthorized, gin.H{"error": localize(c, "errors.user_not_authenticated")})
 		return
 	}
 
 	broadcasts, err := h.broadcastService.GetBroadcasts(userID.(string))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch broadcasts"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "errors.failed_to_fetch_broadcasts")})
 		return
 	}
 
@@ -81,7 +98,7 @@ func (h *BroadcastHandler) GetBroadcasts(c *gin.Context) {
 func (h *BroadcastHandler) UpdateBroadcast(c *gin.Context) {
 	userID, exists := c.Get("userID")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		c.JSON(http.StatusUnauthorized, gin.H{"error": localize(c, "errors.user_not_authenticated")})
 		return
 	}
 	id := c.Param("id")
@@ -110,34 +127,37 @@ func (h *BroadcastHandler) UpdateBroadcast(c *gin.Context) {
 		BroadcastStatus: req.BroadcastStatus,
 		TargetFuelTypes: req.TargetFuelTypes,
 	})
+	if respondBroadcastForbidden(c, err) {
+		return
+	}
 	if err == sql.ErrNoRows {
-		c.JSON(http.StatusNotFound, gin.H{"error": "broadcast not found"})
+		c.JSON(http.StatusNotFound, gin.H{"error": localize(c, "errors.broadcast_not_found")})
 		return
 	}
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update broadcast"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "errors.failed_to_update_broadcast")})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"id": updatedID, "message": "broadcast updated"})
+	c.JSON(http.StatusOK, gin.H{"id": updatedID, "message": localize(c, "messages.broadcast_updated")})
 }
 
 // GetBroadcast handles GET /api/broadcasts/:id
 func (h *BroadcastHandler) GetBroadcast(c *gin.Context) {
 	userID, exists := c.Get("userID")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		c.JSON(http.StatusUnauthorized, gin.H{"error": localize(c, "errors.user_not_authenticated")})
 		return
 	}
 
 	id := c.Param("id")
 	broadcast, err := h.broadcastService.GetBroadcast(id, userID.(string))
 	if err == sql.ErrNoRows {
-		c.JSON(http.StatusNotFound, gin.H{"error": "broadcast not found"})
+		c.JSON(http.StatusNotFound, gin.H{"error": localize(c, "errors.broadcast_not_found")})
 		return
 	}
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch broadcast"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "errors.failed_to_fetch_broadcast")})
 		return
 	}
 
@@ -148,14 +168,17 @@ func (h *BroadcastHandler) GetBroadcast(c *gin.Context) {
 func (h *BroadcastHandler) GetBroadcastEngagement(c *gin.Context) {
 	userID, exists := c.Get("userID")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		c.JSON(http.StatusUnauthorized, gin.H{"error": localize(c, "errors.user_not_authenticated")})
 		return
 	}
 
 	id := c.Param("id")
 	engagement, err := h.broadcastService.GetEngagement(id, userID.(string))
+	if respondBroadcastForbidden(c, err) {
+		return
+	}
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch engagement"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "errors.failed_to_fetch_engagement")})
 		return
 	}
 
@@ -166,7 +189,7 @@ func (h *BroadcastHandler) GetBroadcastEngagement(c *gin.Context) {
 func (h *BroadcastHandler) SaveDraft(c *gin.Context) {
 	userID, exists := c.Get("userID")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		c.JSON(http.StatusUnauthorized, gin.H{"error": localize(c, "errors.user_not_authenticated")})
 		return
 	}
 
@@ -194,8 +217,11 @@ func (h *BroadcastHandler) SaveDraft(c *gin.Context) {
 		EndDate:         req.EndDate,
 		TargetFuelTypes: req.TargetFuelTypes,
 	})
+	if respondBroadcastForbidden(c, err) {
+		return
+	}
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to save draft", "details": err.Error()})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "errors.failed_to_save_draft"), "details": err.Error()})
 		return
 	}
 
@@ -206,14 +232,17 @@ func (h *BroadcastHandler) SaveDraft(c *gin.Context) {
 func (h *BroadcastHandler) SendBroadcast(c *gin.Context) {
 	userID, exists := c.Get("userID")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		c.JSON(http.StatusUnauthorized, gin.H{"error": localize(c, "errors.user_not_authenticated")})
 		return
 	}
 
 	id := c.Param("id")
 	broadcast, err := h.broadcastService.SendBroadcast(id, userID.(string))
+	if respondBroadcastForbidden(c, err) {
+		return
+	}
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to send broadcast"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "errors.failed_to_send_broadcast")})
 		return
 	}
 
@@ -224,7 +253,7 @@ func (h *BroadcastHandler) SendBroadcast(c *gin.Context) {
 func (h *BroadcastHandler) ScheduleBroadcast(c *gin.Context) {
 	userID, exists := c.Get("userID")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		c.JSON(http.StatusUnauthorized, gin.H{"error": localize(c, "errors.user_not_authenticated")})
 		return
 	}
 
@@ -240,8 +269,11 @@ func (h *BroadcastHandler) ScheduleBroadcast(c *gin.Context) {
 	}
 
 	broadcast, err := h.broadcastService.ScheduleBroadcast(id, userID.(string), req.ScheduledFor)
+	if respondBroadcastForbidden(c, err) {
+		return
+	}
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to schedule broadcast"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "errors.failed_to_schedule_broadcast")})
 		return
 	}
 
@@ -252,48 +284,59 @@ func (h *BroadcastHandler) ScheduleBroadcast(c *gin.Context) {
 func (h *BroadcastHandler) CancelBroadcast(c *gin.Context) {
 	userID, exists := c.Get("userID")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		c.JSON(http.StatusUnauthorized, gin.H{"error": localize(c, "errors.user_not_authenticated")})
 		return
 	}
 
 	id := c.Param("id")
-	if err := h.broadcastService.CancelBroadcast(id, userID.(string)); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to cancel broadcast"})
+	err := h.broadcastService.CancelBroadcast(id, userID.(string))
+	if respondBroadcastForbidden(c, err) {
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "errors.failed_to_cancel_broadcast")})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "broadcast cancelled"})
+	c.JSON(http.StatusOK, gin.H{"message": localize(c, "messages.broadcast_cancelled")})
 }
 
 // DeleteBroadcast handles DELETE /api/broadcasts/:id
 func (h *BroadcastHandler) DeleteBroadcast(c *gin.Context) {
 	userID, exists := c.Get("userID")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		c.JSON(http.StatusUnauthorized, gin.H{"error": localize(c, "errors.user_not_authenticated")})
 		return
 	}
 
 	id := c.Param("id")
-	if err := h.broadcastService.DeleteBroadcast(id, userID.(string)); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete broadcast"})
+	err := h.broadcastService.DeleteBroadcast(id, userID.(string))
+	if respondBroadcastForbidden(c, err) {
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "errors.failed_to_delete_broadcast")})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "broadcast deleted"})
+	c.JSON(http.StatusOK, gin.H{"message": localize(c, "messages.broadcast_deleted")})
 }
 
 // DuplicateBroadcast handles POST /api/broadcasts/:id/duplicate
 func (h *BroadcastHandler) DuplicateBroadcast(c *gin.Context) {
 	userID, exists := c.Get("userID")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		c.JSON(http.StatusUnauthorized, gin.H{"error": localize(c, "errors.user_not_authenticated")})
 		return
 	}
 
 	id := c.Param("id")
 	broadcast, err := h.broadcastService.DuplicateBroadcast(id, userID.(string))
+	if respondBroadcastForbidden(c, err) {
+		return
+	}
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to duplicate broadcast"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "errors.failed_to_duplicate_broadcast")})
 		return
 	}
 
@@ -306,13 +349,13 @@ func (h *BroadcastHandler) EstimateRecipients(c *gin.Context) {
 	radiusKm := c.Query("radiusKm")
 
 	if stationID == "" || radiusKm == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "stationId and radiusKm required"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "errors.station_and_radius_required")})
 		return
 	}
 
 	count, err := h.broadcastService.EstimateRecipients(stationID, radiusKm)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to estimate recipients"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "errors.failed_to_estimate_recipients")})
 		return
 	}
 
@@ -171,6 +171,38 @@ func TestBroadcastHandlerSendBroadcastSuccess(t *testing.T) {
 	mockService.AssertExpectations(t)
 }
 
+func TestBroadcastHandlerSendBroadcastLapsedClaim(t *testing.T) {
+	mockService := new(testhelpers.MockBroadcastService)
+	h := NewBroadcastHandler(mockService)
+	r := authedBroadcastRouter()
+	r.POST("/broadcasts/:id/send", h.SendBroadcast)
+
+	mockService.On("SendBroadcast", "b1", "user-1").Return(nil, repository.ErrStationClaimNotApproved).Once()
+
+	req := httptest.NewRequest(http.MethodPost, "/broadcasts/b1/send", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestBroadcastHandlerDeleteBroadcastWithoutPermission(t *testing.T) {
+	mockService := new(testhelpers.MockBroadcastService)
+	h := NewBroadcastHandler(mockService)
+	r := authedBroadcastRouter()
+	r.DELETE("/broadcasts/:id", h.DeleteBroadcast)
+
+	mockService.On("DeleteBroadcast", "b1", "user-1").Return(repository.ErrStationPermissionDenied).Once()
+
+	req := httptest.NewRequest(http.MethodDelete, "/broadcasts/b1", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	mockService.AssertExpectations(t)
+}
+
 func TestBroadcastHandlerScheduleBroadcastBadRequest(t *testing.T) {
 	mockService := new(testhelpers.MockBroadcastService)
 	h := NewBroadcastHandler(mockService)
@@ -0,0 +1,90 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"gaspeep/backend/internal/repository"
+	"gaspeep/backend/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// EmailHandler handles email delivery webhooks and the admin email log
+type EmailHandler struct {
+	emailService service.EmailService
+}
+
+func NewEmailHandler(emailService service.EmailService) *EmailHandler {
+	return &EmailHandler{emailService: emailService}
+}
+
+// DeliveryWebhook handles POST /api/webhooks/email
+func (h *EmailHandler) DeliveryWebhook(c *gin.Context) {
+	var req struct {
+		Type      string `json:"type" binding:"required,oneof=bounce complaint"`
+		MessageID string `json:"messageId" binding:"omitempty,uuid"`
+		Email     string `json:"email" binding:"omitempty,email"`
+		Permanent bool   `json:"permanent"`
+		Detail    string `json:"detail" binding:"max=1000"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.MessageID == "" && req.Email == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "errors.message_id_or_email_required")})
+		return
+	}
+
+	err := h.emailService.RecordDeliveryEvent(repository.EmailDeliveryEvent{
+		Type:      req.Type,
+		MessageID: req.MessageID,
+		Email:     strings.TrimSpace(req.Email),
+		Permanent: req.Permanent,
+		Detail:    req.Detail,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "errors.failed_to_record_delivery_event")})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": localize(c, "messages.delivery_event_recorded")})
+}
+
+// GetEmailLog handles GET /api/admin/emails
+func (h *EmailHandler) GetEmailLog(c *gin.Context) {
+	var req struct {
+		UserID string `form:"userId" binding:"omitempty,uuid"`
+		Email  string `form:"email" binding:"omitempty,email"`
+	}
+
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.UserID == "" && req.Email == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "errors.user_id_or_email_required")})
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+
+	messages, total, err := h.emailService.GetEmailLog(repository.EmailMessageFilter{UserID: req.UserID, Email: req.Email}, page, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "errors.failed_to_fetch_email_log")})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"messages": messages,
+		"pagination": gin.H{
+			"page":  page,
+			"limit": limit,
+			"total": total,
+		},
+	})
+}
@@ -0,0 +1,83 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	testhelpers "gaspeep/backend/internal/handler/testhelpers"
+	"gaspeep/backend/internal/repository"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmailHandlerDeliveryWebhook(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockService := new(testhelpers.MockEmailService)
+	h := NewEmailHandler(mockService)
+	r := gin.New()
+	r.POST("/webhooks/email", h.DeliveryWebhook)
+
+	mockService.On("RecordDeliveryEvent", repository.EmailDeliveryEvent{
+		Type:      repository.EmailEventBounce,
+		MessageID: "6f1c2a4e-7d1b-4a8e-9c53-0f5b2d7e8a91",
+		Email:     "gone@example.com",
+		Permanent: true,
+		Detail:    "550 user unknown",
+	}).Return(nil).Once()
+
+	body := `{"type":"bounce","messageId":"6f1c2a4e-7d1b-4a8e-9c53-0f5b2d7e8a91","email":"gone@example.com","permanent":true,"detail":"550 user unknown"}`
+	req := httptest.NewRequest(http.MethodPost, "/webhooks/email", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockService.AssertExpectations(t)
+
+	for _, body := range []string{
+		`{"type":"delivered","email":"a@example.com"}`,
+		`{"type":"complaint"}`,
+	} {
+		req := httptest.NewRequest(http.MethodPost, "/webhooks/email", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code, body)
+	}
+}
+
+func TestEmailHandlerGetEmailLog(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockService := new(testhelpers.MockEmailService)
+	h := NewEmailHandler(mockService)
+	r := gin.New()
+	r.GET("/admin/emails", h.GetEmailLog)
+
+	userID := "0b6a3a5e-2f6d-4c59-8a1e-3d2f1c9b7e64"
+	mockService.On("GetEmailLog", repository.EmailMessageFilter{UserID: userID}, 1, 20).Return([]repository.EmailMessageLog{
+		{ID: "msg-1", ToEmail: "a@example.com", Template: "password_reset", Status: repository.EmailStatusSent,
+			Events: []repository.EmailStatusEvent{{Status: repository.EmailStatusQueued}, {Status: repository.EmailStatusSent}}},
+	}, 1, nil).Once()
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/emails?userId="+userID, nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var resp struct {
+		Messages []repository.EmailMessageLog `json:"messages"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Len(t, resp.Messages, 1)
+	assert.Len(t, resp.Messages[0].Events, 2)
+
+	req = httptest.NewRequest(http.MethodGet, "/admin/emails", nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertExpectations(t)
+}
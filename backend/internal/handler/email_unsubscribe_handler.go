@@ -0,0 +1,121 @@
+package handler
+
+import (
+	"errors"
+	"html/template"
+	"net/http"
+
+	"gaspeep/backend/internal/middleware"
+	"gaspeep/backend/internal/repository"
+	"gaspeep/backend/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// unsubscribePage is shown to recipients who follow an unsubscribe link. The
+// link only shows the page; opting out takes a POST, so link scanners that
+// fetch every URL in an email cannot unsubscribe anyone.
+var unsubscribePage = template.Must(template.New("unsubscribe").Parse(`<!DOCTYPE html>
+<html lang="{{.Locale}}">
+<head>
+  <meta charset="utf-8">
+  <meta name="viewport" content="width=device-width, initial-scale=1.0">
+  <meta name="robots" content="noindex">
+  <title>{{.Title}}</title>
+</head>
+<body style="margin:0;padding:48px 16px;background-color:#f1f5f9;font-family:'Inter',-apple-system,BlinkMacSystemFont,'Segoe UI',Roboto,Helvetica,Arial,sans-serif;">
+  <main style="max-width:480px;margin:0 auto;padding:32px;border-radius:8px;background-color:#ffffff;text-align:center;">
+    <h1 style="margin:0 0 16px;font-size:22px;font-weight:600;color:#1e293b;">{{.Title}}</h1>
+    <p style="margin:0 0 24px;font-size:16px;line-height:1.6;color:#475569;">{{.Message}}</p>
+    {{if .Token}}
+    <form method="post">
+      <input type="hidden" name="token" value="{{.Token}}">
+      <button type="submit" style="padding:12px 28px;border:0;border-radius:6px;background-color:#2563EB;font-size:16px;font-weight:600;color:#ffffff;cursor:pointer;">{{.Confirm}}</button>
+    </form>
+    {{end}}
+  </main>
+</body>
+</html>`))
+
+type unsubscribePageData struct {
+	Locale  string
+	Title   string
+	Message string
+	Token   string
+	Confirm string
+}
+
+// EmailUnsubscribeHandler handles unsubscribe links in alert and broadcast emails
+type EmailUnsubscribeHandler struct {
+	unsubscribeService service.EmailUnsubscribeService
+}
+
+func NewEmailUnsubscribeHandler(unsubscribeService service.EmailUnsubscribeService) *EmailUnsubscribeHandler {
+	return &EmailUnsubscribeHandler{unsubscribeService: unsubscribeService}
+}
+
+// ShowUnsubscribe handles GET /api/email/unsubscribe
+func (h *EmailUnsubscribeHandler) ShowUnsubscribe(c *gin.Context) {
+	token := c.Query("token")
+	unsubscribe, err := h.unsubscribeService.Lookup(token)
+	if err != nil {
+		h.renderPage(c, http.StatusBadRequest, localize(c, "errors.invalid_unsubscribe_token"), "")
+		return
+	}
+
+	h.renderPage(c, http.StatusOK, localize(c, "email.unsubscribe."+unsubscribe.Scope.Category), token)
+}
+
+// Unsubscribe handles POST /api/email/unsubscribe. Mail clients send RFC 8058
+// one-click requests with the body "List-Unsubscribe=One-Click" and get a JSON
+// response; the confirmation form on the unsubscribe page gets a page back.
+func (h *EmailUnsubscribeHandler) Unsubscribe(c *gin.Context) {
+	token := c.Query("token")
+	if token == "" {
+		token = c.PostForm("token")
+	}
+	oneClick := c.PostForm("List-Unsubscribe") == "One-Click"
+	method := repository.UnsubscribeMethodLink
+	if oneClick {
+		method = repository.UnsubscribeMethodOneClick
+	}
+
+	unsubscribe, err := h.unsubscribeService.Unsubscribe(token, method)
+	if err != nil {
+		status, key := http.StatusInternalServerError, "errors.failed_to_unsubscribe"
+		if errors.Is(err, service.ErrInvalidUnsubscribeToken) {
+			status, key = http.StatusBadRequest, "errors.invalid_unsubscribe_token"
+		}
+		if oneClick {
+			c.JSON(status, gin.H{"error": localize(c, key)})
+		} else {
+			h.renderPage(c, status, localize(c, key), "")
+		}
+		return
+	}
+
+	if oneClick {
+		c.JSON(http.StatusOK, gin.H{
+			"message":  localize(c, "messages.unsubscribed"),
+			"category": unsubscribe.Scope.Category,
+			"scopeId":  unsubscribe.Scope.ScopeID,
+		})
+		return
+	}
+	h.renderPage(c, http.StatusOK, localize(c, "unsubscribe.done"), "")
+}
+
+// renderPage renders the unsubscribe page with message, and a confirmation
+// button when token is set.
+func (h *EmailUnsubscribeHandler) renderPage(c *gin.Context, status int, message, token string) {
+	c.Status(status)
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	c.Header("Cache-Control", "no-store")
+	_ = unsubscribePage.Execute(c.Writer, unsubscribePageData{
+		Locale:  middleware.Localizer(c).Locale(),
+		Title:   localize(c, "unsubscribe.title"),
+		Message: message,
+		Token:   token,
+		Confirm: localize(c, "unsubscribe.confirm"),
+	})
+}
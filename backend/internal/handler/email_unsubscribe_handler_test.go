@@ -0,0 +1,90 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	testhelpers "gaspeep/backend/internal/handler/testhelpers"
+	"gaspeep/backend/internal/repository"
+	"gaspeep/backend/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupEmailUnsubscribeRouter() (*gin.Engine, *testhelpers.MockEmailUnsubscribeService) {
+	gin.SetMode(gin.TestMode)
+	mockService := new(testhelpers.MockEmailUnsubscribeService)
+	h := NewEmailUnsubscribeHandler(mockService)
+	r := gin.New()
+	r.GET("/email/unsubscribe", h.ShowUnsubscribe)
+	r.POST("/email/unsubscribe", h.Unsubscribe)
+	return r, mockService
+}
+
+func TestEmailUnsubscribeHandlerOneClick(t *testing.T) {
+	r, mockService := setupEmailUnsubscribeRouter()
+
+	scope := repository.UnsubscribeScope{Category: repository.UnsubscribeCategoryAlert, ScopeID: "alert-1"}
+	mockService.On("Unsubscribe", "good", repository.UnsubscribeMethodOneClick).
+		Return(&service.EmailUnsubscribe{UserID: "user-1", Scope: scope}, nil).Once()
+	mockService.On("Unsubscribe", "forged", repository.UnsubscribeMethodOneClick).
+		Return(nil, service.ErrInvalidUnsubscribeToken).Once()
+
+	// RFC 8058: the token is in the List-Unsubscribe URL and the body is fixed
+	req := httptest.NewRequest(http.MethodPost, "/email/unsubscribe?token=good", strings.NewReader("List-Unsubscribe=One-Click"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	var resp map[string]any
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "alert", resp["category"])
+	assert.Equal(t, "alert-1", resp["scopeId"])
+
+	req = httptest.NewRequest(http.MethodPost, "/email/unsubscribe?token=forged", strings.NewReader("List-Unsubscribe=One-Click"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestEmailUnsubscribeHandlerConfirmationPage(t *testing.T) {
+	r, mockService := setupEmailUnsubscribeRouter()
+
+	scope := repository.UnsubscribeScope{Category: repository.UnsubscribeCategoryAllBroadcasts}
+	mockService.On("Lookup", "good").Return(&service.EmailUnsubscribe{UserID: "user-1", Scope: scope}, nil)
+	mockService.On("Lookup", "forged").Return(nil, service.ErrInvalidUnsubscribeToken)
+
+	// Following the link only asks for confirmation
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/email/unsubscribe?token=good", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Header().Get("Content-Type"), "text/html")
+	assert.Contains(t, w.Body.String(), "Unsubscribe from all station messages")
+	assert.Contains(t, w.Body.String(), `<input type="hidden" name="token" value="good">`)
+	mockService.AssertNotCalled(t, "Unsubscribe", "good", repository.UnsubscribeMethodLink)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/email/unsubscribe?token=forged", nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.NotContains(t, w.Body.String(), "<form")
+
+	// Submitting the confirmation form unsubscribes
+	mockService.On("Unsubscribe", "good", repository.UnsubscribeMethodLink).
+		Return(&service.EmailUnsubscribe{UserID: "user-1", Scope: scope}, nil).Once()
+	req := httptest.NewRequest(http.MethodPost, "/email/unsubscribe", strings.NewReader("token=good"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "You have been unsubscribed")
+
+	mockService.AssertExpectations(t)
+}
@@ -0,0 +1,115 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"gaspeep/backend/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// FavouriteStationHandler handles favourite station endpoints
+type FavouriteStationHandler struct {
+	favouriteService service.FavouriteStationService
+}
+
+func NewFavouriteStationHandler(favouriteService service.FavouriteStationService) *FavouriteStationHandler {
+	return &FavouriteStationHandler{favouriteService: favouriteService}
+}
+
+// GetFavourites handles GET /api/favourite-stations
+func (h *FavouriteStationHandler) GetFavourites(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": localize(c, "errors.user_not_authenticated")})
+		return
+	}
+
+	favourites, err := h.favouriteService.GetFavourites(userID.(string))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "errors.failed_to_fetch_favourite_stations")})
+		return
+	}
+
+	c.JSON(http.StatusOK, favourites)
+}
+
+// GetFavouritePrices handles GET /api/favourite-stations/prices?fuelTypeId=
+func (h *FavouriteStationHandler) GetFavouritePrices(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": localize(c, "errors.user_not_authenticated")})
+		return
+	}
+
+	fuelTypeID := c.Query("fuelTypeId")
+	if _, err := uuid.Parse(fuelTypeID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "errors.invalid_fuel_type_id")})
+		return
+	}
+
+	prices, err := h.favouriteService.GetFavouritePrices(userID.(string), fuelTypeID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "errors.failed_to_fetch_favourite_prices")})
+		return
+	}
+
+	c.JSON(http.StatusOK, prices)
+}
+
+// AddFavourite handles POST /api/favourite-stations
+func (h *FavouriteStationHandler) AddFavourite(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": localize(c, "errors.user_not_authenticated")})
+		return
+	}
+
+	var req struct {
+		StationID string `json:"stationId" binding:"required,uuid"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err := h.favouriteService.AddFavourite(userID.(string), req.StationID)
+	if errors.Is(err, service.ErrStationNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": localize(c, "errors.station_not_found")})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "errors.failed_to_add_favourite_station")})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"stationId": req.StationID, "message": localize(c, "messages.station_added_to_favourites")})
+}
+
+// RemoveFavourite handles DELETE /api/favourite-stations/:stationId
+func (h *FavouriteStationHandler) RemoveFavourite(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": localize(c, "errors.user_not_authenticated")})
+		return
+	}
+	stationID := c.Param("stationId")
+	if _, err := uuid.Parse(stationID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "errors.invalid_station_id")})
+		return
+	}
+
+	removed, err := h.favouriteService.RemoveFavourite(userID.(string), stationID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "errors.failed_to_remove_favourite_station")})
+		return
+	}
+	if !removed {
+		c.JSON(http.StatusNotFound, gin.H{"error": localize(c, "errors.favourite_station_not_found")})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": localize(c, "messages.station_removed_from_favourites")})
+}
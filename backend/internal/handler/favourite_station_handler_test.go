@@ -0,0 +1,109 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	testhelpers "gaspeep/backend/internal/handler/testhelpers"
+	"gaspeep/backend/internal/repository"
+	"gaspeep/backend/internal/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const favouriteStationID = "7f1f6c1e-4b8e-4a53-9a43-2f7d7b0d3c11"
+
+func TestFavouriteStationHandlerAddFavourite(t *testing.T) {
+	mockService := new(testhelpers.MockFavouriteStationService)
+	h := NewFavouriteStationHandler(mockService)
+	r := authedRouter()
+	r.POST("/favourite-stations", h.AddFavourite)
+
+	mockService.On("AddFavourite", "user-1", favouriteStationID).Return(nil).Once()
+
+	body, err := json.Marshal(map[string]any{"stationId": favouriteStationID})
+	require.NoError(t, err)
+	req := httptest.NewRequest(http.MethodPost, "/favourite-stations", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestFavouriteStationHandlerAddFavouriteStationNotFound(t *testing.T) {
+	mockService := new(testhelpers.MockFavouriteStationService)
+	h := NewFavouriteStationHandler(mockService)
+	r := authedRouter()
+	r.POST("/favourite-stations", h.AddFavourite)
+
+	mockService.On("AddFavourite", "user-1", favouriteStationID).Return(service.ErrStationNotFound).Once()
+
+	body, err := json.Marshal(map[string]any{"stationId": favouriteStationID})
+	require.NoError(t, err)
+	req := httptest.NewRequest(http.MethodPost, "/favourite-stations", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestFavouriteStationHandlerRemoveFavouriteNotFound(t *testing.T) {
+	mockService := new(testhelpers.MockFavouriteStationService)
+	h := NewFavouriteStationHandler(mockService)
+	r := authedRouter()
+	r.DELETE("/favourite-stations/:stationId", h.RemoveFavourite)
+
+	mockService.On("RemoveFavourite", "user-1", favouriteStationID).Return(false, nil).Once()
+
+	req := httptest.NewRequest(http.MethodDelete, "/favourite-stations/"+favouriteStationID, nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestFavouriteStationHandlerGetFavouritePricesRequiresFuelType(t *testing.T) {
+	mockService := new(testhelpers.MockFavouriteStationService)
+	h := NewFavouriteStationHandler(mockService)
+	r := authedRouter()
+	r.GET("/favourite-stations/prices", h.GetFavouritePrices)
+
+	req := httptest.NewRequest(http.MethodGet, "/favourite-stations/prices", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertNotCalled(t, "GetFavouritePrices")
+}
+
+func TestFavouriteStationHandlerGetFavouritePrices(t *testing.T) {
+	mockService := new(testhelpers.MockFavouriteStationService)
+	h := NewFavouriteStationHandler(mockService)
+	r := authedRouter()
+	r.GET("/favourite-stations/prices", h.GetFavouritePrices)
+
+	fuelTypeID := "0b8a0f5e-7c7c-4e0e-9a4a-1c2d3e4f5a6b"
+	mockService.On("GetFavouritePrices", "user-1", fuelTypeID).Return([]repository.FavouritePriceResult{
+		{StationID: favouriteStationID, StationName: "Home Servo", Price: 1.79},
+	}, nil).Once()
+
+	req := httptest.NewRequest(http.MethodGet, "/favourite-stations/prices?fuelTypeId="+fuelTypeID, nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var prices []repository.FavouritePriceResult
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &prices))
+	require.Len(t, prices, 1)
+	assert.Equal(t, "Home Servo", prices[0].StationName)
+	mockService.AssertExpectations(t)
+}
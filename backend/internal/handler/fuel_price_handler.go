@@ -36,7 +36,7 @@ func (h *FuelPriceHandler) GetFuelPrices(c *gin.Context) {
 
 	prices, err := h.fuelPriceService.GetFuelPrices(filters)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch fuel prices"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "errors.failed_to_fetch_fuel_prices")})
 		return
 	}
 
@@ -49,7 +49,7 @@ func (h *FuelPriceHandler) GetStationPrices(c *gin.Context) {
 
 	prices, err := h.fuelPriceService.GetStationPrices(stationID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch station prices"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "errors.failed_to_fetch_station_prices")})
 		return
 	}
 
@@ -63,7 +63,7 @@ func (h *FuelPriceHandler) GetCheapestPrices(c *gin.Context) {
 	radius := c.Query("radius")
 
 	if lat == "" || lon == "" || radius == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "lat, lon, and radius are required"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "errors.location_required")})
 		return
 	}
 
@@ -73,7 +73,7 @@ func (h *FuelPriceHandler) GetCheapestPrices(c *gin.Context) {
 
 	prices, err := h.fuelPriceService.GetCheapestPrices(latitude, longitude, radiusKm)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch cheapest prices"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "errors.failed_to_fetch_cheapest_prices")})
 		return
 	}
 
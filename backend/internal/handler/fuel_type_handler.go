@@ -21,7 +21,7 @@ func NewFuelTypeHandler(fuelTypeService service.FuelTypeService) *FuelTypeHandle
 func (h *FuelTypeHandler) GetFuelTypes(c *gin.Context) {
 	fuelTypes, err := h.fuelTypeService.GetFuelTypes()
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch fuel types"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "errors.failed_to_fetch_fuel_types")})
 		return
 	}
 
@@ -34,11 +34,11 @@ func (h *FuelTypeHandler) GetFuelType(c *gin.Context) {
 
 	ft, err := h.fuelTypeService.GetFuelType(id)
 	if err == sql.ErrNoRows {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Fuel type not found"})
+		c.JSON(http.StatusNotFound, gin.H{"error": localize(c, "errors.fuel_type_not_found")})
 		return
 	}
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch fuel type"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "errors.failed_to_fetch_fuel_type")})
 		return
 	}
 
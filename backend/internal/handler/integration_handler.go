@@ -0,0 +1,168 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"gaspeep/backend/internal/models"
+	"gaspeep/backend/internal/repository"
+	"gaspeep/backend/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// IntegrationHandler handles station owners' API keys and the integration API
+// their point-of-sale and pricing systems call with them
+type IntegrationHandler struct {
+	apiKeyService service.OwnerAPIKeyService
+}
+
+func NewIntegrationHandler(apiKeyService service.OwnerAPIKeyService) *IntegrationHandler {
+	return &IntegrationHandler{apiKeyService: apiKeyService}
+}
+
+// ListAPIKeys handles GET /api/station-owners/api-keys
+func (h *IntegrationHandler) ListAPIKeys(c *gin.Context) {
+	keys, err := h.apiKeyService.List(c.GetString("userID"))
+	if respondPermissionDenied(c, err) {
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "errors.failed_to_fetch_api_keys")})
+		return
+	}
+	c.JSON(http.StatusOK, keys)
+}
+
+// CreateAPIKey handles POST /api/station-owners/api-keys. The key itself is
+// only ever returned here.
+func (h *IntegrationHandler) CreateAPIKey(c *gin.Context) {
+	var req service.CreateAPIKeyInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	key, rawKey, err := h.apiKeyService.Create(c.GetString("userID"), req)
+	switch {
+	case errors.Is(err, service.ErrInvalidAPIKeyInput):
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "errors.invalid_api_key_input")})
+		return
+	case respondPermissionDenied(c, err):
+		return
+	case errors.Is(err, repository.ErrStationOwnerNotFound), errors.Is(err, repository.ErrStationClaimNotApproved):
+		c.JSON(http.StatusForbidden, gin.H{"error": localize(c, "errors.station_claim_not_approved")})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "errors.failed_to_create_api_key")})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"apiKey": key, "key": rawKey})
+}
+
+// RevokeAPIKey handles DELETE /api/station-owners/api-keys/:id
+func (h *IntegrationHandler) RevokeAPIKey(c *gin.Context) {
+	err := h.apiKeyService.Revoke(c.GetString("userID"), c.Param("id"))
+	if errors.Is(err, service.ErrAPIKeyNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": localize(c, "errors.api_key_not_found")})
+		return
+	}
+	if respondPermissionDenied(c, err) {
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "errors.failed_to_revoke_api_key")})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": localize(c, "messages.api_key_revoked")})
+}
+
+// GetAPIKeyUsage handles GET /api/station-owners/api-keys/:id/usage
+func (h *IntegrationHandler) GetAPIKeyUsage(c *gin.Context) {
+	usage, err := h.apiKeyService.Usage(c.GetString("userID"), c.Param("id"))
+	if errors.Is(err, service.ErrAPIKeyNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": localize(c, "errors.api_key_not_found")})
+		return
+	}
+	if respondPermissionDenied(c, err) {
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "errors.failed_to_fetch_api_key_usage")})
+		return
+	}
+	c.JSON(http.StatusOK, usage)
+}
+
+// apiKey returns the key APIKeyAuthMiddleware authenticated the request with.
+func apiKey(c *gin.Context) *models.OwnerAPIKey {
+	key, _ := c.Get("apiKey")
+	return key.(*models.OwnerAPIKey)
+}
+
+// respondAPIKeyDenied writes the response for a key that may not make a
+// request, and reports whether it did.
+func respondAPIKeyDenied(c *gin.Context, err error) bool {
+	switch {
+	case errors.Is(err, service.ErrAPIKeyScopeMissing):
+		c.JSON(http.StatusForbidden, gin.H{"error": localize(c, "errors.api_key_scope_missing")})
+	case errors.Is(err, service.ErrAPIKeyStationNotAllowed):
+		c.JSON(http.StatusForbidden, gin.H{"error": localize(c, "errors.api_key_station_not_allowed")})
+	default:
+		return false
+	}
+	return true
+}
+
+// GetStationPrices handles GET /api/integrations/stations/:id/prices
+func (h *IntegrationHandler) GetStationPrices(c *gin.Context) {
+	prices, err := h.apiKeyService.StationPrices(apiKey(c), c.Param("id"))
+	if respondAPIKeyDenied(c, err) {
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "errors.failed_to_fetch_station_prices")})
+		return
+	}
+	c.JSON(http.StatusOK, prices)
+}
+
+// PutStationPrices handles PUT /api/integrations/stations/:id/prices. Each
+// price is published or rejected on its own; a request with an
+// Idempotency-Key header is applied at most once.
+func (h *IntegrationHandler) PutStationPrices(c *gin.Context) {
+	var req struct {
+		Prices []service.IntegrationPriceEntry `json:"prices" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := h.apiKeyService.PublishPrices(apiKey(c), c.Param("id"), c.GetHeader("Idempotency-Key"), req.Prices)
+	if respondAPIKeyDenied(c, err) {
+		return
+	}
+	switch {
+	case errors.Is(err, service.ErrInvalidIntegrationPrices):
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "errors.invalid_integration_prices",
+			"max", strconv.Itoa(service.MaxIntegrationPriceBatch), "keyLength", strconv.Itoa(service.MaxIdempotencyKeyLength))})
+		return
+	case errors.Is(err, service.ErrIdempotencyKeyReused):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": localize(c, "errors.idempotency_key_reused")})
+		return
+	case errors.Is(err, service.ErrIdempotentRequestInProgress):
+		c.JSON(http.StatusConflict, gin.H{"error": localize(c, "errors.idempotent_request_in_progress")})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "errors.failed_to_publish_fuel_prices")})
+		return
+	}
+
+	if result.Replayed {
+		c.Header("Idempotent-Replayed", "true")
+	}
+	c.JSON(http.StatusOK, result)
+}
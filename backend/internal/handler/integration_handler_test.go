@@ -0,0 +1,133 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gaspeep/backend/internal/handler/testhelpers"
+	"gaspeep/backend/internal/models"
+	"gaspeep/backend/internal/repository"
+	"gaspeep/backend/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testIntegrationKey = &models.OwnerAPIKey{ID: "key-1", OwnerUserID: "u1"}
+
+func newIntegrationRouter(apiKeys service.OwnerAPIKeyService) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	h := NewIntegrationHandler(apiKeys)
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Set("userID", "u1")
+		c.Set("apiKey", testIntegrationKey)
+		c.Next()
+	})
+	r.POST("/api/station-owners/api-keys", h.CreateAPIKey)
+	r.DELETE("/api/station-owners/api-keys/:id", h.RevokeAPIKey)
+	r.PUT("/api/integrations/stations/:id/prices", h.PutStationPrices)
+	return r
+}
+
+func putPrices(r *gin.Engine, stationID, idempotencyKey string, payload interface{}) *httptest.ResponseRecorder {
+	body, _ := json.Marshal(payload)
+	req := httptest.NewRequest(http.MethodPut, "/api/integrations/stations/"+stationID+"/prices", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if idempotencyKey != "" {
+		req.Header.Set("Idempotency-Key", idempotencyKey)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestIntegration_CreateAPIKey(t *testing.T) {
+	apiKeys := new(testhelpers.MockOwnerAPIKeyService)
+	input := service.CreateAPIKeyInput{Name: "Till", Scopes: []string{models.APIKeyScopePricesWrite}, StationIDs: []string{"s1"}}
+	apiKeys.On("Create", "u1", input).Return(&models.OwnerAPIKey{ID: "key-1", Prefix: "gpk_abcdefgh"}, "gpk_secret", nil).Once()
+	apiKeys.On("Create", "u1", service.CreateAPIKeyInput{Name: "Till", Scopes: []string{"admin"}}).Return(nil, "", service.ErrInvalidAPIKeyInput).Once()
+	apiKeys.On("Create", "u1", service.CreateAPIKeyInput{Name: "Till", Scopes: []string{models.APIKeyScopePricesWrite}, StationIDs: []string{"s2"}}).
+		Return(nil, "", repository.ErrStationClaimNotApproved).Once()
+	r := newIntegrationRouter(apiKeys)
+
+	w := postJSON(r, "/api/station-owners/api-keys", input)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Contains(t, w.Body.String(), `"key":"gpk_secret"`)
+	assert.Contains(t, w.Body.String(), `"prefix":"gpk_abcdefgh"`)
+
+	w = postJSON(r, "/api/station-owners/api-keys", service.CreateAPIKeyInput{Name: "Till", Scopes: []string{"admin"}})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = postJSON(r, "/api/station-owners/api-keys", service.CreateAPIKeyInput{Name: "Till", Scopes: []string{models.APIKeyScopePricesWrite}, StationIDs: []string{"s2"}})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	apiKeys.AssertExpectations(t)
+}
+
+func TestIntegration_RevokeAPIKey(t *testing.T) {
+	apiKeys := new(testhelpers.MockOwnerAPIKeyService)
+	apiKeys.On("Revoke", "u1", "key-1").Return(nil).Once()
+	apiKeys.On("Revoke", "u1", "key-2").Return(service.ErrAPIKeyNotFound).Once()
+	r := newIntegrationRouter(apiKeys)
+
+	for keyID, status := range map[string]int{"key-1": http.StatusOK, "key-2": http.StatusNotFound} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/api/station-owners/api-keys/"+keyID, nil))
+		assert.Equal(t, status, w.Code, keyID)
+	}
+	apiKeys.AssertExpectations(t)
+}
+
+func TestIntegration_PutStationPrices(t *testing.T) {
+	apiKeys := new(testhelpers.MockOwnerAPIKeyService)
+	entries := []service.IntegrationPriceEntry{{FuelTypeID: "e10", Price: 1.799}}
+	result := &service.IntegrationPriceResult{
+		StationID: "s1",
+		Published: 1,
+		Results:   []service.IntegrationPriceEntryResult{{FuelTypeID: "e10", Price: 1.799, Status: service.IntegrationPricePublished}},
+	}
+	apiKeys.On("PublishPrices", testIntegrationKey, "s1", "req-1", entries).Return(result, nil).Once()
+	replayed := *result
+	replayed.Replayed = true
+	apiKeys.On("PublishPrices", testIntegrationKey, "s1", "req-1", entries).Return(&replayed, nil).Once()
+	r := newIntegrationRouter(apiKeys)
+
+	w := putPrices(r, "s1", "req-1", map[string]interface{}{"prices": entries})
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Header().Get("Idempotent-Replayed"))
+	assert.Contains(t, w.Body.String(), `"status":"published"`)
+
+	w = putPrices(r, "s1", "req-1", map[string]interface{}{"prices": entries})
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "true", w.Header().Get("Idempotent-Replayed"))
+	apiKeys.AssertExpectations(t)
+}
+
+func TestIntegration_PutStationPricesErrors(t *testing.T) {
+	entries := []service.IntegrationPriceEntry{{FuelTypeID: "e10", Price: 1.799}}
+	tests := []struct {
+		name   string
+		err    error
+		status int
+	}{
+		{"scope missing", service.ErrAPIKeyScopeMissing, http.StatusForbidden},
+		{"station not allowed", service.ErrAPIKeyStationNotAllowed, http.StatusForbidden},
+		{"invalid batch", service.ErrInvalidIntegrationPrices, http.StatusBadRequest},
+		{"key reused", service.ErrIdempotencyKeyReused, http.StatusUnprocessableEntity},
+		{"in progress", service.ErrIdempotentRequestInProgress, http.StatusConflict},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			apiKeys := new(testhelpers.MockOwnerAPIKeyService)
+			apiKeys.On("PublishPrices", testIntegrationKey, "s1", "req-1", entries).Return(nil, tt.err).Once()
+			r := newIntegrationRouter(apiKeys)
+
+			w := putPrices(r, "s1", "req-1", map[string]interface{}{"prices": entries})
+			assert.Equal(t, tt.status, w.Code)
+		})
+	}
+}
@@ -0,0 +1,33 @@
+package handler
+
+import (
+	"net/http"
+
+	"gaspeep/backend/internal/auth"
+
+	"github.com/gin-gonic/gin"
+)
+
+// JWKSHandler publishes the public keys access tokens are signed with, so
+// other services can verify Gas Peep tokens without a shared secret
+type JWKSHandler struct {
+	keys func() (*auth.KeySet, error)
+}
+
+func NewJWKSHandler() *JWKSHandler {
+	return &JWKSHandler{keys: auth.CurrentKeySet}
+}
+
+// GetJWKS handles GET /.well-known/jwks.json
+func (h *JWKSHandler) GetJWKS(c *gin.Context) {
+	ks, err := h.keys()
+	if err != nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": localize(c, "errors.signing_keys_unavailable")})
+		return
+	}
+
+	// Verifiers may cache the set briefly; a new key is published before it
+	// signs anything, so keep this shorter than the rotation lead time
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, ks.JWKS())
+}
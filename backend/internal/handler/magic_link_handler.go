@@ -0,0 +1,118 @@
+package handler
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"net/http"
+
+	"gaspeep/backend/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	magicLinkNonceCookie     = "magic_link_nonce"
+	magicLinkNonceCookiePath = "/api/auth/magic-link"
+)
+
+type MagicLinkHandler struct {
+	sessionService   service.SessionService
+	mfaService       service.MFAService
+	loginThrottle    service.LoginThrottle
+	magicLinkService service.MagicLinkService
+}
+
+func NewMagicLinkHandler(
+	sessionService service.SessionService,
+	mfaService service.MFAService,
+	loginThrottle service.LoginThrottle,
+	magicLinkService service.MagicLinkService,
+) *MagicLinkHandler {
+	return &MagicLinkHandler{
+		sessionService:   sessionService,
+		mfaService:       mfaService,
+		loginThrottle:    loginThrottle,
+		magicLinkService: magicLinkService,
+	}
+}
+
+type MagicLinkRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+type RedeemMagicLinkRequest struct {
+	Token      string `json:"token" binding:"required"`
+	DeviceName string `json:"deviceName"`
+}
+
+// magicLinkNonce returns the browser's nonce from its cookie, or a new one.
+// Reusing it keeps earlier links the browser asked for working.
+func magicLinkNonce(c *gin.Context) (string, error) {
+	if nonce, err := c.Cookie(magicLinkNonceCookie); err == nil && len(nonce) == 64 {
+		return nonce, nil
+	}
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// Request handles POST /api/auth/magic-link, emailing a sign-in link that
+// only works in the requesting browser. The response is the same whether or
+// not the email is registered.
+func (h *MagicLinkHandler) Request(c *gin.Context) {
+	var req MagicLinkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.loginThrottle.AllowLookup("magic-link", c.ClientIP(), req.Email); err != nil {
+		respondThrottled(c, err)
+		return
+	}
+
+	nonce, err := magicLinkNonce(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "errors.failed_to_send_magic_link")})
+		return
+	}
+	if err := h.magicLinkService.Send(req.Email, nonce, sessionMetadata(c, "")); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "errors.failed_to_send_magic_link")})
+		return
+	}
+
+	setAuthCookie(c, magicLinkNonceCookie, nonce, magicLinkNonceCookiePath, int(service.MagicLinkTTL.Seconds()))
+	c.JSON(http.StatusOK, gin.H{"message": localize(c, "messages.magic_link_sent")})
+}
+
+// Redeem handles POST /api/auth/magic-link/verify, signing in with the token
+// from a link as SignIn does with a password.
+func (h *MagicLinkHandler) Redeem(c *gin.Context) {
+	var req RedeemMagicLinkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.loginThrottle.AllowLookup("magic-link-verify", c.ClientIP()); err != nil {
+		respondThrottled(c, err)
+		return
+	}
+
+	nonce, _ := c.Cookie(magicLinkNonceCookie)
+	user, err := h.magicLinkService.Redeem(req.Token, nonce, sessionMetadata(c, req.DeviceName))
+	if errors.Is(err, service.ErrInvalidMagicLink) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "errors.invalid_magic_link")})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "errors.failed_to_process_request")})
+		return
+	}
+
+	setAuthCookie(c, magicLinkNonceCookie, "", magicLinkNonceCookiePath, -1)
+	completeSignIn(c, h.sessionService, h.mfaService, user, req.DeviceName)
+}
@@ -0,0 +1,114 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"gaspeep/backend/internal/handler/testhelpers"
+	"gaspeep/backend/internal/models"
+	"gaspeep/backend/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newMagicLinkRouter(links service.MagicLinkService, sessions service.SessionService, mfa service.MFAService, throttle service.LoginThrottle) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	h := NewMagicLinkHandler(sessions, mfa, throttle, links)
+	r := gin.New()
+	r.POST("/api/auth/magic-link", h.Request)
+	r.POST("/api/auth/magic-link/verify", h.Redeem)
+	return r
+}
+
+func TestMagicLink_RequestSetsNonceCookie(t *testing.T) {
+	links := new(testhelpers.MockMagicLinkService)
+	var nonce string
+	links.On("Send", "user@example.com", mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) { nonce = args.String(1) }).Return(nil).Twice()
+	r := newMagicLinkRouter(links, newAllowingSessionService(), newNoMFAService(), newAllowingLoginThrottle())
+
+	w := postJSON(r, "/api/auth/magic-link", map[string]string{"email": "user@example.com"})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	cookie := findCookie(w.Result().Cookies(), magicLinkNonceCookie)
+	require.NotNil(t, cookie)
+	assert.Equal(t, nonce, cookie.Value)
+	assert.Len(t, nonce, 64)
+	assert.True(t, cookie.HttpOnly)
+	assert.Equal(t, magicLinkNonceCookiePath, cookie.Path)
+
+	// Asking again from the same browser keeps its nonce
+	first := nonce
+	w = postJSON(r, "/api/auth/magic-link", map[string]string{"email": "user@example.com"}, cookie)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, first, nonce)
+	links.AssertExpectations(t)
+}
+
+func TestMagicLink_RequestThrottled(t *testing.T) {
+	links := new(testhelpers.MockMagicLinkService)
+	throttle := new(testhelpers.MockLoginThrottle)
+	throttle.On("AllowLookup", "magic-link", mock.Anything).Return(&service.ThrottledError{RetryAfter: 30 * time.Second}).Once()
+	r := newMagicLinkRouter(links, newAllowingSessionService(), newNoMFAService(), throttle)
+
+	w := postJSON(r, "/api/auth/magic-link", map[string]string{"email": "user@example.com"})
+
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	links.AssertNotCalled(t, "Send", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestMagicLink_RedeemStartsSession(t *testing.T) {
+	user := &models.User{ID: "u1", Email: "user@example.com"}
+	links := new(testhelpers.MockMagicLinkService)
+	links.On("Redeem", "token-1", "nonce-1", mock.MatchedBy(func(meta service.SessionMetadata) bool {
+		return meta.DeviceName == "Pixel 8"
+	})).Return(user, nil).Once()
+	r := newMagicLinkRouter(links, newAllowingSessionService(), newNoMFAService(), newAllowingLoginThrottle())
+
+	w := postJSON(r, "/api/auth/magic-link/verify", map[string]string{"token": "token-1", "deviceName": "Pixel 8"},
+		&http.Cookie{Name: magicLinkNonceCookie, Value: "nonce-1"})
+
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var resp AuthResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "access-token", resp.Token)
+	assert.NotNil(t, findCookie(w.Result().Cookies(), "auth_token"))
+	cleared := findCookie(w.Result().Cookies(), magicLinkNonceCookie)
+	require.NotNil(t, cleared)
+	assert.Empty(t, cleared.Value)
+}
+
+func TestMagicLink_RedeemRequiresMFA(t *testing.T) {
+	user := &models.User{ID: "u1", Email: "user@example.com"}
+	links := new(testhelpers.MockMagicLinkService)
+	links.On("Redeem", "token-1", "nonce-1", mock.Anything).Return(user, nil).Once()
+	sessions := new(testhelpers.MockSessionService)
+	mfa := new(testhelpers.MockMFAService)
+	mfa.On("Enabled", "u1").Return(true, nil)
+	r := newMagicLinkRouter(links, sessions, mfa, newAllowingLoginThrottle())
+
+	w := postJSON(r, "/api/auth/magic-link/verify", map[string]string{"token": "token-1"},
+		&http.Cookie{Name: magicLinkNonceCookie, Value: "nonce-1"})
+
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var challenge MFAChallengeResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &challenge))
+	assert.True(t, challenge.MFARequired)
+	sessions.AssertNotCalled(t, "Start", mock.Anything, mock.Anything)
+}
+
+func TestMagicLink_RedeemInvalid(t *testing.T) {
+	links := new(testhelpers.MockMagicLinkService)
+	// Without the cookie the link was requested from another browser
+	links.On("Redeem", "token-1", "", mock.Anything).Return(nil, service.ErrInvalidMagicLink).Once()
+	r := newMagicLinkRouter(links, newAllowingSessionService(), newNoMFAService(), newAllowingLoginThrottle())
+
+	w := postJSON(r, "/api/auth/magic-link/verify", map[string]string{"token": "token-1"})
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Nil(t, findCookie(w.Result().Cookies(), "auth_token"))
+}
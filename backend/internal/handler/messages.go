@@ -0,0 +1,13 @@
+package handler
+
+import (
+	"gaspeep/backend/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// localize returns the message for key in the request's locale, with each
+// {name} placeholder replaced from args given as name, value pairs.
+func localize(c *gin.Context, key string, args ...string) string {
+	return middleware.Localizer(c).T(key, args...)
+}
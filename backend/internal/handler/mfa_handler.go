@@ -0,0 +1,250 @@
+package handler
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"gaspeep/backend/internal/auth"
+	"gaspeep/backend/internal/repository"
+	"gaspeep/backend/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	mfaTokenCookie     = "mfa_token"
+	mfaTokenCookiePath = "/api/auth/mfa"
+)
+
+type MFAHandler struct {
+	userRepo       repository.UserRepository
+	sessionService service.SessionService
+	mfaService     service.MFAService
+	loginThrottle  service.LoginThrottle
+}
+
+func NewMFAHandler(userRepo repository.UserRepository, sessionService service.SessionService, mfaService service.MFAService, loginThrottle service.LoginThrottle) *MFAHandler {
+	return &MFAHandler{userRepo: userRepo, sessionService: sessionService, mfaService: mfaService, loginThrottle: loginThrottle}
+}
+
+type MFACodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+type VerifyMFARequest struct {
+	MFAToken   string `json:"mfaToken"`
+	Code       string `json:"code" binding:"required"`
+	DeviceName string `json:"deviceName"`
+}
+
+// RecoveryCodesResponse lists newly issued recovery codes. They are only
+// ever shown once.
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recoveryCodes"`
+}
+
+// ConfirmTOTPResponse carries the recovery codes and a session started with
+// the new second factor.
+type ConfirmTOTPResponse struct {
+	AuthResponse
+	RecoveryCodes []string `json:"recoveryCodes"`
+}
+
+// startMFAChallenge issues the token a user who has entered their password
+// exchanges for a session at /api/auth/mfa/verify. Browsers also get it in a
+// cookie. It writes an error response and returns false on failure.
+func startMFAChallenge(c *gin.Context, userID string) (MFAChallengeResponse, bool) {
+	token, expiresAt, err := auth.GenerateMFAToken(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "errors.failed_to_generate_token")})
+		return MFAChallengeResponse{}, false
+	}
+	expiresIn := int(time.Until(expiresAt).Seconds())
+	setAuthCookie(c, mfaTokenCookie, token, mfaTokenCookiePath, expiresIn)
+	return MFAChallengeResponse{MFARequired: true, MFAToken: token, ExpiresIn: expiresIn}, true
+}
+
+// respondMFAError maps MFA service errors to responses.
+func respondMFAError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidMFACode):
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "errors.invalid_mfa_code")})
+	case errors.Is(err, service.ErrMFANotEnabled):
+		c.JSON(http.StatusConflict, gin.H{"error": localize(c, "errors.mfa_not_enabled")})
+	case errors.Is(err, service.ErrMFAAlreadyEnabled):
+		c.JSON(http.StatusConflict, gin.H{"error": localize(c, "errors.mfa_already_enabled")})
+	case errors.Is(err, service.ErrMFASetupNotStarted):
+		c.JSON(http.StatusConflict, gin.H{"error": localize(c, "errors.mfa_setup_not_started")})
+	case errors.Is(err, service.ErrMFARequired):
+		c.JSON(http.StatusForbidden, gin.H{"error": localize(c, "errors.mfa_cannot_be_disabled")})
+	default:
+		log.Printf("mfa: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "errors.failed_to_update_mfa")})
+	}
+}
+
+// checkMFACode runs check, which verifies a second-factor code for the user,
+// under the sign-in throttle so codes cannot be guessed through any endpoint
+// that takes one. It writes an error response and returns false on failure.
+func (h *MFAHandler) checkMFACode(c *gin.Context, userID, email string, check func() error) bool {
+	if err := h.loginThrottle.CheckMFA(userID); err != nil {
+		respondThrottled(c, err)
+		return false
+	}
+	if err := check(); err != nil {
+		if errors.Is(err, service.ErrInvalidMFACode) {
+			if err := h.loginThrottle.RecordMFAFailure(userID, email); err != nil {
+				log.Printf("warning: failed to record failed MFA attempt: %v", err)
+			}
+		}
+		respondMFAError(c, err)
+		return false
+	}
+	if err := h.loginThrottle.RecordMFASuccess(userID); err != nil {
+		log.Printf("warning: failed to clear failed MFA attempts: %v", err)
+	}
+	return true
+}
+
+// GetStatus handles GET /api/auth/mfa
+func (h *MFAHandler) GetStatus(c *gin.Context) {
+	status, err := h.mfaService.Status(c.GetString("userID"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "errors.failed_to_check_mfa")})
+		return
+	}
+
+	c.JSON(http.StatusOK, status)
+}
+
+// SetupTOTP handles POST /api/auth/mfa/totp/setup, returning a new secret
+// for the user to add to their authenticator app.
+func (h *MFAHandler) SetupTOTP(c *gin.Context) {
+	user, err := h.userRepo.GetUserByID(c.GetString("userID"))
+	if err != nil || user == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": localize(c, "errors.user_not_found")})
+		return
+	}
+
+	enrolment, err := h.mfaService.BeginTOTP(user)
+	if err != nil {
+		respondMFAError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, enrolment)
+}
+
+// ConfirmTOTP handles POST /api/auth/mfa/totp/confirm. The current session is
+// replaced with one started with the new second factor.
+func (h *MFAHandler) ConfirmTOTP(c *gin.Context) {
+	var req MFACodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID := c.GetString("userID")
+	user, err := h.userRepo.GetUserByID(userID)
+	if err != nil || user == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": localize(c, "errors.user_not_found")})
+		return
+	}
+
+	codes, err := h.mfaService.ConfirmTOTP(userID, req.Code)
+	if err != nil {
+		respondMFAError(c, err)
+		return
+	}
+
+	if sessionID := c.GetString("sessionID"); sessionID != "" {
+		if err := h.sessionService.Revoke(userID, sessionID); err != nil && !errors.Is(err, service.ErrSessionNotFound) {
+			log.Printf("warning: failed to end session %s after enabling MFA: %v", sessionID, err)
+		}
+	}
+	meta := sessionMetadata(c, "")
+	meta.MFAVerified = true
+	tokens := startSession(c, h.sessionService, user, meta)
+	if tokens == nil {
+		return
+	}
+
+	c.JSON(http.StatusOK, ConfirmTOTPResponse{AuthResponse: newAuthResponse(tokens, user), RecoveryCodes: codes})
+}
+
+// Verify handles POST /api/auth/mfa/verify, completing sign-in with a TOTP or
+// recovery code. The MFA token comes from the request body or, for browsers,
+// the mfa_token cookie.
+func (h *MFAHandler) Verify(c *gin.Context) {
+	var req VerifyMFARequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.MFAToken == "" {
+		req.MFAToken, _ = c.Cookie(mfaTokenCookie)
+	}
+
+	userID, err := auth.ValidateMFAToken(req.MFAToken)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": localize(c, "errors.invalid_mfa_token")})
+		return
+	}
+	user, err := h.userRepo.GetUserByID(userID)
+	if err != nil || user == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": localize(c, "errors.invalid_mfa_token")})
+		return
+	}
+
+	if !h.checkMFACode(c, userID, user.Email, func() error { return h.mfaService.Verify(userID, req.Code) }) {
+		return
+	}
+
+	setAuthCookie(c, mfaTokenCookie, "", mfaTokenCookiePath, -1)
+	meta := sessionMetadata(c, req.DeviceName)
+	meta.MFAVerified = true
+	tokens := startSession(c, h.sessionService, user, meta)
+	if tokens == nil {
+		return
+	}
+
+	c.JSON(http.StatusOK, newAuthResponse(tokens, user))
+}
+
+// RegenerateRecoveryCodes handles POST /api/auth/mfa/recovery-codes
+func (h *MFAHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	var req MFACodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID := c.GetString("userID")
+	var codes []string
+	if !h.checkMFACode(c, userID, c.GetString("email"), func() (err error) {
+		codes, err = h.mfaService.RegenerateRecoveryCodes(userID, req.Code)
+		return err
+	}) {
+		return
+	}
+
+	c.JSON(http.StatusOK, RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+// Disable handles DELETE /api/auth/mfa
+func (h *MFAHandler) Disable(c *gin.Context) {
+	var req MFACodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID := c.GetString("userID")
+	if !h.checkMFACode(c, userID, c.GetString("email"), func() error { return h.mfaService.Disable(userID, req.Code) }) {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": localize(c, "messages.mfa_disabled")})
+}
@@ -0,0 +1,224 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"gaspeep/backend/internal/auth"
+	"gaspeep/backend/internal/handler/testhelpers"
+	"gaspeep/backend/internal/models"
+	"gaspeep/backend/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func postJSON(r *gin.Engine, path string, payload any, cookies ...*http.Cookie) *httptest.ResponseRecorder {
+	body, _ := json.Marshal(payload)
+	req := httptest.NewRequest(http.MethodPost, path, bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	for _, c := range cookies {
+		req.AddCookie(c)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestSignIn_MFAEnabledRequiresSecondStep(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	repo := newMockUserRepo()
+	hashed, err := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.MinCost)
+	require.NoError(t, err)
+	user := &models.User{ID: "u1", Email: "mfa@example.com"}
+	repo.users[user.Email] = user
+	repo.passwords[user.Email] = string(hashed)
+
+	sessions := new(testhelpers.MockSessionService)
+	mfa := new(testhelpers.MockMFAService)
+	mfa.On("Enabled", "u1").Return(true, nil)
+
+	authHandler := NewAuthHandler(repo, nil, newAllowingVerificationService(), sessions, mfa, newAllowingLoginThrottle(), nil)
+	mfaHandler := NewMFAHandler(repo, sessions, mfa, newAllowingLoginThrottle())
+	r := gin.New()
+	r.POST("/api/auth/signin", authHandler.SignIn)
+	r.POST("/api/auth/mfa/verify", mfaHandler.Verify)
+
+	// The password alone does not start a session
+	w := postJSON(r, "/api/auth/signin", map[string]string{"email": user.Email, "password": "password123"})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var challenge MFAChallengeResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &challenge))
+	assert.True(t, challenge.MFARequired)
+	assert.NotEmpty(t, challenge.MFAToken)
+	assert.Nil(t, findCookie(w.Result().Cookies(), "auth_token"))
+	mfaCookie := findCookie(w.Result().Cookies(), mfaTokenCookie)
+	require.NotNil(t, mfaCookie)
+	sessions.AssertNotCalled(t, "Start", mock.Anything, mock.Anything)
+
+	// Neither the MFA token nor an access token is enough without a valid code
+	mfa.On("Verify", "u1", "111111").Return(service.ErrInvalidMFACode).Once()
+	w = postJSON(r, "/api/auth/mfa/verify", map[string]string{"mfaToken": challenge.MFAToken, "code": "111111"})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	accessToken, err := auth.GenerateToken("u1", user.Email, "session-1", false)
+	require.NoError(t, err)
+	w = postJSON(r, "/api/auth/mfa/verify", map[string]string{"mfaToken": accessToken, "code": "123456"})
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	// The browser's cookie and a correct code start a session that records the second factor
+	mfa.On("Verify", "u1", "123456").Return(nil).Once()
+	sessions.On("Start", user, mock.MatchedBy(func(meta service.SessionMetadata) bool {
+		return meta.MFAVerified && meta.DeviceName == "Pixel 8"
+	})).Return(testhelpers.NewTestSessionTokens("access-token", "refresh-token"), nil).Once()
+
+	w = postJSON(r, "/api/auth/mfa/verify", map[string]string{"code": "123456", "deviceName": "Pixel 8"}, mfaCookie)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var response AuthResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "access-token", response.Token)
+	if c := findCookie(w.Result().Cookies(), mfaTokenCookie); assert.NotNil(t, c) {
+		assert.Equal(t, -1, c.MaxAge, "MFA token cookie should be cleared")
+	}
+	sessions.AssertExpectations(t)
+	mfa.AssertExpectations(t)
+}
+
+func TestConfirmTOTP_ReplacesSessionWithVerifiedOne(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	repo := newMockUserRepo()
+	user := &models.User{ID: "u1", Email: "mfa@example.com"}
+	repo.users[user.Email] = user
+	sessions := new(testhelpers.MockSessionService)
+	mfa := new(testhelpers.MockMFAService)
+	h := NewMFAHandler(repo, sessions, mfa, nil)
+
+	r := gin.New()
+	r.POST("/api/auth/mfa/totp/confirm", func(c *gin.Context) {
+		c.Set("userID", "u1")
+		c.Set("sessionID", "session-old")
+	}, h.ConfirmTOTP)
+
+	mfa.On("ConfirmTOTP", "u1", "000000").Return(nil, service.ErrInvalidMFACode).Once()
+	w := postJSON(r, "/api/auth/mfa/totp/confirm", map[string]string{"code": "000000"})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	mfa.On("ConfirmTOTP", "u1", "123456").Return([]string{"aaaaa-bbbbb"}, nil).Once()
+	sessions.On("Revoke", "u1", "session-old").Return(nil).Once()
+	sessions.On("Start", user, mock.MatchedBy(func(meta service.SessionMetadata) bool {
+		return meta.MFAVerified
+	})).Return(testhelpers.NewTestSessionTokens("access-token", "refresh-token"), nil).Once()
+
+	w = postJSON(r, "/api/auth/mfa/totp/confirm", map[string]string{"code": "123456"})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var response ConfirmTOTPResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, []string{"aaaaa-bbbbb"}, response.RecoveryCodes)
+	assert.Equal(t, "access-token", response.Token)
+	sessions.AssertExpectations(t)
+	mfa.AssertExpectations(t)
+}
+
+func TestDisableMFA_RefusedWhenRequired(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mfa := new(testhelpers.MockMFAService)
+	h := NewMFAHandler(newMockUserRepo(), new(testhelpers.MockSessionService), mfa, newAllowingLoginThrottle())
+	r := gin.New()
+	r.DELETE("/api/auth/mfa", func(c *gin.Context) { c.Set("userID", "owner-1") }, h.Disable)
+
+	mfa.On("Disable", "owner-1", "123456").Return(service.ErrMFARequired).Once()
+
+	req := httptest.NewRequest(http.MethodDelete, "/api/auth/mfa", bytes.NewReader([]byte(`{"code":"123456"}`)))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	mfa.AssertExpectations(t)
+}
+
+func TestVerifyMFA_CountsFailuresAndThrottles(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	repo := newMockUserRepo()
+	user := &models.User{ID: "u1", Email: "mfa@example.com"}
+	repo.users[user.Email] = user
+	mfa := new(testhelpers.MockMFAService)
+	throttle := new(testhelpers.MockLoginThrottle)
+	h := NewMFAHandler(repo, new(testhelpers.MockSessionService), mfa, throttle)
+	r := gin.New()
+	r.POST("/api/auth/mfa/verify", h.Verify)
+
+	token, _, err := auth.GenerateMFAToken("u1")
+	require.NoError(t, err)
+
+	// A wrong code counts against the account
+	throttle.On("CheckMFA", "u1").Return(nil).Once()
+	mfa.On("Verify", "u1", "111111").Return(service.ErrInvalidMFACode).Once()
+	throttle.On("RecordMFAFailure", "u1", user.Email).Return(nil).Once()
+	w := postJSON(r, "/api/auth/mfa/verify", map[string]string{"mfaToken": token, "code": "111111"})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// Once throttled, codes are not checked at all
+	throttle.On("CheckMFA", "u1").Return(&service.ThrottledError{RetryAfter: 15 * time.Minute}).Once()
+	w = postJSON(r, "/api/auth/mfa/verify", map[string]string{"mfaToken": token, "code": "123456"})
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "901", w.Header().Get("Retry-After"))
+
+	throttle.AssertExpectations(t)
+	mfa.AssertExpectations(t)
+	mfa.AssertNotCalled(t, "Verify", "u1", "123456")
+}
+
+func TestMFACodeEndpoints_CountFailuresAndThrottle(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mfa := new(testhelpers.MockMFAService)
+	throttle := new(testhelpers.MockLoginThrottle)
+	h := NewMFAHandler(newMockUserRepo(), new(testhelpers.MockSessionService), mfa, throttle)
+	r := gin.New()
+	signedIn := func(c *gin.Context) {
+		c.Set("userID", "u1")
+		c.Set("email", "mfa@example.com")
+	}
+	r.POST("/api/auth/mfa/recovery-codes", signedIn, h.RegenerateRecoveryCodes)
+	r.DELETE("/api/auth/mfa", signedIn, h.Disable)
+
+	// Wrong codes count against the account like they do when signing in
+	throttle.On("CheckMFA", "u1").Return(nil).Twice()
+	mfa.On("RegenerateRecoveryCodes", "u1", "111111").Return(nil, service.ErrInvalidMFACode).Once()
+	mfa.On("Disable", "u1", "222222").Return(service.ErrInvalidMFACode).Once()
+	throttle.On("RecordMFAFailure", "u1", "mfa@example.com").Return(nil).Twice()
+	w := postJSON(r, "/api/auth/mfa/recovery-codes", map[string]string{"code": "111111"})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	req := httptest.NewRequest(http.MethodDelete, "/api/auth/mfa", bytes.NewReader([]byte(`{"code":"222222"}`)))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// Once throttled, codes are not checked at all
+	throttle.On("CheckMFA", "u1").Return(&service.ThrottledError{RetryAfter: 15 * time.Minute}).Twice()
+	w = postJSON(r, "/api/auth/mfa/recovery-codes", map[string]string{"code": "123456"})
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	req = httptest.NewRequest(http.MethodDelete, "/api/auth/mfa", bytes.NewReader([]byte(`{"code":"123456"}`)))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+
+	throttle.AssertExpectations(t)
+	mfa.AssertExpectations(t)
+	mfa.AssertNotCalled(t, "RegenerateRecoveryCodes", "u1", "123456")
+	mfa.AssertNotCalled(t, "Disable", "u1", "123456")
+}
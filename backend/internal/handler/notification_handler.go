@@ -21,13 +21,13 @@ func NewNotificationHandler(notificationService service.NotificationService) *No
 func (h *NotificationHandler) GetNotifications(c *gin.Context) {
 	userID, exists := c.Get("userID")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		c.JSON(http.StatusUnauthorized, gin.H{"error": localize(c, "errors.user_not_authenticated")})
 		return
 	}
 
 	notifications, err := h.notificationService.GetNotifications(userID.(string))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch notifications"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "errors.failed_to_fetch_notifications")})
 		return
 	}
 
@@ -4,231 +4,287 @@ import (
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"strings"
 	"testing"
 
 	"gaspeep/backend/internal/auth"
+	"gaspeep/backend/internal/auth/oidctest"
+	"gaspeep/backend/internal/handler/testhelpers"
 	"gaspeep/backend/internal/models"
+	"gaspeep/backend/internal/service"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
-func withOAuthStubs(t *testing.T, build func(string) (string, error), exchange func(string) (*auth.GoogleTokenResponse, error), fetch func(string) (*auth.GoogleProfile, error), gen func(string, string) (string, error)) {
+// newTestOIDCProviders returns a registry with a "google" provider and an
+// Apple-style "apple" provider using response_mode=form_post, both backed by
+// the returned stand-in issuer.
+func newTestOIDCProviders(t *testing.T) (*auth.OIDCProviders, *oidctest.Issuer) {
 	t.Helper()
-	origBuild := buildGoogleAuthURL
-	origExchange := exchangeGoogleCode
-	origFetch := fetchGoogleProfile
-	origGen := generateJWTToken
-
-	if build != nil {
-		buildGoogleAuthURL = build
-	}
-	if exchange != nil {
-		exchangeGoogleCode = exchange
+	issuer := oidctest.NewIssuer(t, "client-id", "client-secret")
+	newProvider := func(name, responseMode string) *auth.OIDCProvider {
+		p, err := auth.NewOIDCProvider(auth.OIDCConfig{
+			Name:         name,
+			Issuer:       issuer.URL,
+			ClientID:     issuer.ClientID,
+			ClientSecret: issuer.ClientSecret,
+			RedirectURL:  "https://app.example.com/api/auth/oauth/" + name + "/callback",
+			ResponseMode: responseMode,
+		})
+		require.NoError(t, err)
+		return p
 	}
-	if fetch != nil {
-		fetchGoogleProfile = fetch
+	return auth.NewOIDCProviders(newProvider("google", ""), newProvider("apple", "form_post")), issuer
+}
+
+func newOAuthTestRouter(t *testing.T, repo *MockUserRepositoryOAuth, sessions service.SessionService) (*gin.Engine, *oidctest.Issuer) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	providers, issuer := newTestOIDCProviders(t)
+	h := NewOAuthHandler(repo, sessions, newNoMFAService(), providers)
+
+	r := gin.New()
+	r.GET("/api/auth/oauth/providers", h.ListProviders)
+	r.GET("/api/auth/oauth/:provider", h.Start)
+	r.GET("/api/auth/oauth/:provider/callback", h.Callback)
+	r.POST("/api/auth/oauth/:provider/callback", h.Callback)
+	return r, issuer
+}
+
+// oauthSignIn starts sign-in with provider, authorizes at the issuer and
+// delivers the response to the callback the way the provider would. extra
+// adds or overrides callback parameters.
+func oauthSignIn(t *testing.T, r *gin.Engine, issuer *oidctest.Issuer, provider string, extra url.Values) *httptest.ResponseRecorder {
+	t.Helper()
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/auth/oauth/"+provider, nil))
+	require.Equal(t, http.StatusFound, w.Code, w.Body.String())
+	stateCookie := findCookie(w.Result().Cookies(), oauthStateCookie)
+	require.NotNil(t, stateCookie)
+
+	params := issuer.Authorize(t, w.Header().Get("Location"))
+	for k, v := range extra {
+		params[k] = v
 	}
-	if gen != nil {
-		generateJWTToken = gen
+
+	var req *http.Request
+	if strings.Contains(w.Header().Get("Location"), "response_mode=form_post") {
+		req = httptest.NewRequest(http.MethodPost, "/api/auth/oauth/"+provider+"/callback", strings.NewReader(params.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	} else {
+		req = httptest.NewRequest(http.MethodGet, "/api/auth/oauth/"+provider+"/callback?"+params.Encode(), nil)
 	}
+	req.AddCookie(&http.Cookie{Name: stateCookie.Name, Value: stateCookie.Value})
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
 
-	t.Cleanup(func() {
-		buildGoogleAuthURL = origBuild
-		exchangeGoogleCode = origExchange
-		fetchGoogleProfile = origFetch
-		generateJWTToken = origGen
-	})
+func findCookie(cookies []*http.Cookie, name string) *http.Cookie {
+	for _, c := range cookies {
+		if c.Name == name {
+			return c
+		}
+	}
+	return nil
 }
 
-func TestGoogleCallback_ProviderUserSuccess(t *testing.T) {
-	gin.SetMode(gin.TestMode)
+func TestOAuthCallback_ProviderUserSuccess(t *testing.T) {
 	mockRepo := new(MockUserRepositoryOAuth)
-	h := NewOAuthHandler(mockRepo)
-
-	withOAuthStubs(t,
-		nil,
-		func(code string) (*auth.GoogleTokenResponse, error) {
-			return &auth.GoogleTokenResponse{AccessToken: "token"}, nil
-		},
-		func(accessToken string) (*auth.GoogleProfile, error) {
-			return &auth.GoogleProfile{Sub: "google-sub", Email: "a@b.com", Name: "A", Picture: "pic", EmailVerified: true}, nil
-		},
-		func(userID, email string) (string, error) {
-			return "jwt-token", nil
-		},
-	)
+	r, issuer := newOAuthTestRouter(t, mockRepo, newAllowingSessionService())
+	issuer.SetIdentity(oidctest.Identity{Subject: "google-sub", Email: "a@b.com", EmailVerified: true, Name: "A", Picture: "pic"})
 
 	mockRepo.On("GetUserByProvider", "google", "google-sub").Return(&models.User{ID: "u1", Email: "a@b.com"}, nil).Once()
 
-	r := gin.New()
-	r.GET("/oauth/callback", h.GoogleCallback)
-
-	req := httptest.NewRequest(http.MethodGet, "/oauth/callback?code=abc&state=s1", nil)
-	req.AddCookie(&http.Cookie{Name: "oauth_state", Value: "s1"})
-	w := httptest.NewRecorder()
-	r.ServeHTTP(w, req)
+	w := oauthSignIn(t, r, issuer, "google", nil)
 
 	assert.Equal(t, http.StatusOK, w.Code)
 	assert.Contains(t, w.Body.String(), "oauth_success")
 	cookies := w.Result().Cookies()
-	foundAuth := false
-	for _, c := range cookies {
-		if c.Name == "auth_token" && c.Value == "jwt-token" {
-			foundAuth = true
-		}
+	if c := findCookie(cookies, "auth_token"); assert.NotNil(t, c) {
+		assert.Equal(t, "access-token", c.Value)
+	}
+	if c := findCookie(cookies, "refresh_token"); assert.NotNil(t, c) {
+		assert.Equal(t, "refresh-token", c.Value)
+		assert.Equal(t, "/api/auth", c.Path)
+		assert.True(t, c.HttpOnly)
+	}
+	if c := findCookie(cookies, oauthStateCookie); assert.NotNil(t, c) {
+		assert.Equal(t, -1, c.MaxAge, "state cookie should be cleared")
 	}
-	assert.True(t, foundAuth)
 	mockRepo.AssertExpectations(t)
 }
 
-func TestGoogleCallback_LinkExistingUser(t *testing.T) {
-	gin.SetMode(gin.TestMode)
+func TestOAuthCallback_LinkExistingUser(t *testing.T) {
 	mockRepo := new(MockUserRepositoryOAuth)
-	h := NewOAuthHandler(mockRepo)
-
-	withOAuthStubs(t,
-		nil,
-		func(code string) (*auth.GoogleTokenResponse, error) {
-			return &auth.GoogleTokenResponse{AccessToken: "token"}, nil
-		},
-		func(accessToken string) (*auth.GoogleProfile, error) {
-			return &auth.GoogleProfile{Sub: "google-sub", Email: "a@b.com", Name: "A", Picture: "pic", EmailVerified: true}, nil
-		},
-		func(userID, email string) (string, error) {
-			return "jwt-token", nil
-		},
-	)
+	r, issuer := newOAuthTestRouter(t, mockRepo, newAllowingSessionService())
+	issuer.SetIdentity(oidctest.Identity{Subject: "google-sub", Email: "a@b.com", EmailVerified: true, Name: "A", Picture: "pic"})
 
 	existing := &models.User{ID: "u-existing", Email: "a@b.com"}
 	mockRepo.On("GetUserByProvider", "google", "google-sub").Return(nil, errors.New("not found")).Once()
 	mockRepo.On("GetUserByEmail", "a@b.com").Return(existing, nil).Once()
 	mockRepo.On("UpdateUserOAuth", "u-existing", "google", "google-sub", "pic", true).Return(nil).Once()
 
-	r := gin.New()
-	r.GET("/oauth/callback", h.GoogleCallback)
-	req := httptest.NewRequest(http.MethodGet, "/oauth/callback?code=abc&state=s1", nil)
-	req.AddCookie(&http.Cookie{Name: "oauth_state", Value: "s1"})
-	w := httptest.NewRecorder()
-	r.ServeHTTP(w, req)
+	w := oauthSignIn(t, r, issuer, "google", nil)
 
 	assert.Equal(t, http.StatusOK, w.Code)
 	mockRepo.AssertExpectations(t)
 }
 
-func TestGoogleCallback_CreateUserFailure(t *testing.T) {
-	gin.SetMode(gin.TestMode)
+func TestOAuthCallback_DoesNotLinkUnverifiedEmail(t *testing.T) {
 	mockRepo := new(MockUserRepositoryOAuth)
-	h := NewOAuthHandler(mockRepo)
-
-	withOAuthStubs(t,
-		nil,
-		func(code string) (*auth.GoogleTokenResponse, error) {
-			return &auth.GoogleTokenResponse{AccessToken: "token"}, nil
-		},
-		func(accessToken string) (*auth.GoogleProfile, error) {
-			return &auth.GoogleProfile{Sub: "google-sub", Email: "new@b.com", Name: "New", Picture: "pic", EmailVerified: true}, nil
-		},
-		func(userID, email string) (string, error) {
-			return "jwt-token", nil
-		},
-	)
+	r, issuer := newOAuthTestRouter(t, mockRepo, newAllowingSessionService())
+	issuer.SetIdentity(oidctest.Identity{Subject: "google-sub", Email: "a@b.com", EmailVerified: false})
+
+	mockRepo.On("GetUserByProvider", "google", "google-sub").Return(nil, errors.New("not found")).Once()
+	mockRepo.On("GetUserByEmail", "a@b.com").Return(&models.User{ID: "u-existing", Email: "a@b.com"}, nil).Once()
+
+	w := oauthSignIn(t, r, issuer, "google", nil)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	mockRepo.AssertNotCalled(t, "UpdateUserOAuth", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestOAuthCallback_CreateUserFailure(t *testing.T) {
+	mockRepo := new(MockUserRepositoryOAuth)
+	r, issuer := newOAuthTestRouter(t, mockRepo, newAllowingSessionService())
+	issuer.SetIdentity(oidctest.Identity{Subject: "google-sub", Email: "new@b.com", EmailVerified: true, Name: "New", Picture: "pic"})
 
 	mockRepo.On("GetUserByProvider", "google", "google-sub").Return(nil, errors.New("not found")).Once()
 	mockRepo.On("GetUserByEmail", "new@b.com").Return(nil, errors.New("not found")).Once()
 	mockRepo.On("CreateUserWithProvider", "new@b.com", "New", "free", "google", "google-sub", "pic", true).Return(nil, errors.New("create failed")).Once()
 
-	r := gin.New()
-	r.GET("/oauth/callback", h.GoogleCallback)
-	req := httptest.NewRequest(http.MethodGet, "/oauth/callback?code=abc&state=s1", nil)
-	req.AddCookie(&http.Cookie{Name: "oauth_state", Value: "s1"})
-	w := httptest.NewRecorder()
-	r.ServeHTTP(w, req)
+	w := oauthSignIn(t, r, issuer, "google", nil)
 
 	assert.Equal(t, http.StatusInternalServerError, w.Code)
 	assert.Contains(t, w.Body.String(), "failed to create user")
 	mockRepo.AssertExpectations(t)
 }
 
-func TestGoogleCallback_ProfileFetchAndJWTFailure(t *testing.T) {
-	gin.SetMode(gin.TestMode)
+func TestOAuthCallback_SessionFailure(t *testing.T) {
 	mockRepo := new(MockUserRepositoryOAuth)
-	h := NewOAuthHandler(mockRepo)
-
-	// Fetch profile failure path
-	withOAuthStubs(t,
-		nil,
-		func(code string) (*auth.GoogleTokenResponse, error) {
-			return &auth.GoogleTokenResponse{AccessToken: "token"}, nil
-		},
-		func(accessToken string) (*auth.GoogleProfile, error) {
-			return nil, errors.New("profile failed")
-		},
-		func(userID, email string) (string, error) {
-			return "jwt-token", nil
-		},
-	)
+	sessions := new(testhelpers.MockSessionService)
+	r, issuer := newOAuthTestRouter(t, mockRepo, sessions)
+	issuer.SetIdentity(oidctest.Identity{Subject: "google-sub", Email: "a@b.com", EmailVerified: true})
 
-	r := gin.New()
-	r.GET("/oauth/callback", h.GoogleCallback)
-	req := httptest.NewRequest(http.MethodGet, "/oauth/callback?code=abc&state=s1", nil)
-	req.AddCookie(&http.Cookie{Name: "oauth_state", Value: "s1"})
-	w := httptest.NewRecorder()
-	r.ServeHTTP(w, req)
-	assert.Equal(t, http.StatusBadRequest, w.Code)
-
-	// JWT generation failure path
-	withOAuthStubs(t,
-		nil,
-		func(code string) (*auth.GoogleTokenResponse, error) {
-			return &auth.GoogleTokenResponse{AccessToken: "token"}, nil
-		},
-		func(accessToken string) (*auth.GoogleProfile, error) {
-			return &auth.GoogleProfile{Sub: "google-sub", Email: "a@b.com", Name: "A", Picture: "pic", EmailVerified: true}, nil
-		},
-		func(userID, email string) (string, error) {
-			return "", errors.New("jwt failed")
-		},
-	)
 	mockRepo.On("GetUserByProvider", "google", "google-sub").Return(&models.User{ID: "u1", Email: "a@b.com"}, nil).Once()
+	sessions.On("Start", mock.Anything, mock.Anything).Return(nil, errors.New("session failed")).Once()
+
+	w := oauthSignIn(t, r, issuer, "google", nil)
 
-	req = httptest.NewRequest(http.MethodGet, "/oauth/callback?code=abc&state=s1", nil)
-	req.AddCookie(&http.Cookie{Name: "oauth_state", Value: "s1"})
-	w = httptest.NewRecorder()
-	r.ServeHTTP(w, req)
 	assert.Equal(t, http.StatusInternalServerError, w.Code)
 	assert.Contains(t, w.Body.String(), "failed to generate token")
 	mockRepo.AssertExpectations(t)
 }
 
-func TestGoogleCallback_TokenExchangeFailureWithStub(t *testing.T) {
-	gin.SetMode(gin.TestMode)
+func TestOAuthCallback_TokenExchangeFailure(t *testing.T) {
 	mockRepo := new(MockUserRepositoryOAuth)
-	h := NewOAuthHandler(mockRepo)
-
-	withOAuthStubs(t,
-		nil,
-		func(code string) (*auth.GoogleTokenResponse, error) {
-			return nil, errors.New("exchange failed")
-		},
-		func(accessToken string) (*auth.GoogleProfile, error) {
-			return nil, nil
-		},
-		func(userID, email string) (string, error) {
-			return "", nil
-		},
-	)
+	r, issuer := newOAuthTestRouter(t, mockRepo, newAllowingSessionService())
+
+	w := oauthSignIn(t, r, issuer, "google", url.Values{"code": {"forged-code"}})
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "token exchange failed")
+	mockRepo.AssertNotCalled(t, "GetUserByProvider", mock.Anything, mock.Anything)
+}
+
+func TestOAuthCallback_ProviderError(t *testing.T) {
+	mockRepo := new(MockUserRepositoryOAuth)
+	r, issuer := newOAuthTestRouter(t, mockRepo, newAllowingSessionService())
+
+	w := oauthSignIn(t, r, issuer, "google", url.Values{"error": {"access_denied"}})
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "cancelled or denied")
+}
+
+func TestOAuthCallback_AppleFormPost(t *testing.T) {
+	mockRepo := new(MockUserRepositoryOAuth)
+	r, issuer := newOAuthTestRouter(t, mockRepo, newAllowingSessionService())
+	// Apple leaves the name out of the ID token and sends email_verified as a string
+	issuer.SetIdentity(oidctest.Identity{Subject: "000123.abc", Email: "abc@privaterelay.appleid.com", EmailVerified: "true"})
 
-	r := gin.New()
-	r.GET("/oauth/callback", h.GoogleCallback)
-	req := httptest.NewRequest(http.MethodGet, "/oauth/callback?code=abc&state=s1", nil)
-	req.AddCookie(&http.Cookie{Name: "oauth_state", Value: "s1"})
 	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/auth/oauth/apple", nil))
+	require.Equal(t, http.StatusFound, w.Code)
+	stateCookie := findCookie(w.Result().Cookies(), oauthStateCookie)
+	require.NotNil(t, stateCookie)
+	assert.Equal(t, http.SameSiteNoneMode, stateCookie.SameSite, "the cross-site POST must carry the state cookie")
+	assert.True(t, stateCookie.Secure)
+
+	mockRepo.On("GetUserByProvider", "apple", "000123.abc").Return(nil, errors.New("not found")).Once()
+	mockRepo.On("GetUserByEmail", "abc@privaterelay.appleid.com").Return(nil, errors.New("not found")).Once()
+	mockRepo.On("CreateUserWithProvider", "abc@privaterelay.appleid.com", "Jane Citizen", "free", "apple", "000123.abc", "", true).
+		Return(&models.User{ID: "u-apple", Email: "abc@privaterelay.appleid.com"}, nil).Once()
+
+	w = oauthSignIn(t, r, issuer, "apple", url.Values{"user": {`{"name":{"firstName":"Jane","lastName":"Citizen"},"email":"abc@privaterelay.appleid.com"}`}})
+
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	mockRepo.AssertExpectations(t)
+}
+
+func TestOAuthCallback_StateFromAnotherProvider(t *testing.T) {
+	mockRepo := new(MockUserRepositoryOAuth)
+	r, issuer := newOAuthTestRouter(t, mockRepo, newAllowingSessionService())
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/auth/oauth/google", nil))
+	stateCookie := findCookie(w.Result().Cookies(), oauthStateCookie)
+	require.NotNil(t, stateCookie)
+	params := issuer.Authorize(t, w.Header().Get("Location"))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/auth/oauth/apple/callback?"+params.Encode(), nil)
+	req.AddCookie(&http.Cookie{Name: stateCookie.Name, Value: stateCookie.Value})
+	w = httptest.NewRecorder()
 	r.ServeHTTP(w, req)
 
 	assert.Equal(t, http.StatusBadRequest, w.Code)
-	assert.Contains(t, w.Body.String(), "token exchange failed")
+	assert.Contains(t, w.Body.String(), "invalid state")
+}
 
-	mockRepo.AssertNotCalled(t, "GetUserByProvider", mock.Anything, mock.Anything)
+func TestOAuthHandler_UnknownProviderAndList(t *testing.T) {
+	r, _ := newOAuthTestRouter(t, new(MockUserRepositoryOAuth), newAllowingSessionService())
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/auth/oauth/facebook", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/auth/oauth/providers", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"providers":["apple","google"]}`, w.Body.String())
+}
+
+func TestOAuthCallback_MFAUserMustEnterCode(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockRepo := new(MockUserRepositoryOAuth)
+	sessions := new(testhelpers.MockSessionService)
+	mfa := new(testhelpers.MockMFAService)
+	providers, issuer := newTestOIDCProviders(t)
+	h := NewOAuthHandler(mockRepo, sessions, mfa, providers)
+	r := gin.New()
+	r.GET("/api/auth/oauth/:provider", h.Start)
+	r.GET("/api/auth/oauth/:provider/callback", h.Callback)
+	issuer.SetIdentity(oidctest.Identity{Subject: "google-sub", Email: "a@b.com", EmailVerified: true})
+
+	mockRepo.On("GetUserByProvider", "google", "google-sub").Return(&models.User{ID: "u1", Email: "a@b.com"}, nil).Once()
+	mfa.On("Enabled", "u1").Return(true, nil).Once()
+
+	w := oauthSignIn(t, r, issuer, "google", nil)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "mfa_required")
+	cookies := w.Result().Cookies()
+	assert.Nil(t, findCookie(cookies, "auth_token"))
+	if c := findCookie(cookies, mfaTokenCookie); assert.NotNil(t, c) {
+		userID, err := auth.ValidateMFAToken(c.Value)
+		require.NoError(t, err)
+		assert.Equal(t, "u1", userID)
+		assert.Equal(t, mfaTokenCookiePath, c.Path)
+	}
+	sessions.AssertNotCalled(t, "Start", mock.Anything, mock.Anything)
 }
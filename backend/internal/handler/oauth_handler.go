@@ -1,129 +1,220 @@
 package handler
 
 import (
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"log"
 	"net/http"
 	"os"
+	"strings"
 
 	"gaspeep/backend/internal/auth"
+	"gaspeep/backend/internal/models"
 	"gaspeep/backend/internal/repository"
+	"gaspeep/backend/internal/service"
 
 	"github.com/gin-gonic/gin"
-	"github.com/google/uuid"
+)
+
+const (
+	oauthStateCookie     = "oauth_state"
+	oauthStateCookiePath = "/api/auth/oauth"
+	oauthStateMaxAge     = 600
 )
 
 type OAuthHandler struct {
-	userRepo repository.UserRepository
+	userRepo       repository.UserRepository
+	sessionService service.SessionService
+	mfaService     service.MFAService
+	providers      *auth.OIDCProviders
 }
 
-var (
-	buildGoogleAuthURL = auth.BuildGoogleAuthURL
-	exchangeGoogleCode = auth.ExchangeCode
-	fetchGoogleProfile = auth.FetchProfile
-	generateJWTToken   = auth.GenerateToken
-)
+func NewOAuthHandler(userRepo repository.UserRepository, sessionService service.SessionService, mfaService service.MFAService, providers *auth.OIDCProviders) *OAuthHandler {
+	return &OAuthHandler{userRepo: userRepo, sessionService: sessionService, mfaService: mfaService, providers: providers}
+}
 
-func NewOAuthHandler(userRepo repository.UserRepository) *OAuthHandler {
-	return &OAuthHandler{userRepo: userRepo}
+// oauthState is kept in a cookie between starting sign-in and the callback.
+type oauthState struct {
+	Provider string `json:"provider"`
+	auth.OIDCAuthRequest
 }
 
-// StartGoogle begins the OAuth flow by redirecting to Google's consent screen.
-func (h *OAuthHandler) StartGoogle(c *gin.Context) {
-	state := uuid.New().String()
-	// set state cookie for basic CSRF protection
-	c.SetCookie("oauth_state", state, 300, "/", "", false, true)
+// ListProviders handles GET /api/auth/oauth/providers
+func (h *OAuthHandler) ListProviders(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"providers": h.providers.Names()})
+}
 
-	authURL, err := buildGoogleAuthURL(state)
+// Start handles GET /api/auth/oauth/:provider by redirecting to the
+// provider's sign-in page.
+func (h *OAuthHandler) Start(c *gin.Context) {
+	provider, ok := h.providers.Get(c.Param("provider"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": localize(c, "errors.unknown_oauth_provider")})
+		return
+	}
+
+	req, err := auth.NewOIDCAuthRequest()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "errors.oauth_unavailable")})
+		return
+	}
+	authURL, err := provider.AuthCodeURL(c.Request.Context(), req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		log.Printf("oauth: %v", err)
+		c.JSON(http.StatusBadGateway, gin.H{"error": localize(c, "errors.oauth_unavailable")})
 		return
 	}
 
+	value, _ := json.Marshal(oauthState{Provider: provider.Name(), OIDCAuthRequest: req})
+	setOAuthStateCookie(c, provider, base64.RawURLEncoding.EncodeToString(value), oauthStateMaxAge)
 	c.Redirect(http.StatusFound, authURL)
 }
 
-// GoogleCallback handles the OAuth callback from Google.
-func (h *OAuthHandler) GoogleCallback(c *gin.Context) {
-	code := c.Query("code")
-	state := c.Query("state")
-	cookieState, _ := c.Cookie("oauth_state")
-	// basic state validation
-	if state == "" || cookieState == "" || state != cookieState {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid state"})
+// Callback handles GET and POST /api/auth/oauth/:provider/callback. Providers
+// using response_mode=form_post, such as Apple, POST the response.
+func (h *OAuthHandler) Callback(c *gin.Context) {
+	provider, ok := h.providers.Get(c.Param("provider"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": localize(c, "errors.unknown_oauth_provider")})
 		return
 	}
 
-	// Exchange code for tokens
-	tr, err := exchangeGoogleCode(code)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "token exchange failed"})
+	state, ok := readOAuthState(c)
+	setOAuthStateCookie(c, provider, "", -1)
+	param := oauthCallbackParam(c)
+	if !ok || state.Provider != provider.Name() || param("state") == "" ||
+		subtle.ConstantTimeCompare([]byte(param("state")), []byte(state.State)) != 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "errors.invalid_state")})
+		return
+	}
+	if param("error") != "" {
+		// The user declined or the provider refused the request
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "errors.oauth_denied")})
 		return
 	}
 
-	// Fetch profile
-	profile, err := fetchGoogleProfile(tr.AccessToken)
+	identity, err := provider.Exchange(c.Request.Context(), param("code"), state.OIDCAuthRequest)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "failed to fetch profile"})
+		log.Printf("oauth: %s sign-in failed: %v", provider.Name(), err)
+		key := "errors.token_exchange_failed"
+		if errors.Is(err, auth.ErrInvalidIDToken) {
+			key = "errors.invalid_id_token"
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, key)})
 		return
 	}
+	if identity.Name == "" {
+		identity.Name = auth.AppleUserName(param("user"))
+	}
 
-	// Try to find user by provider
-	user, err := h.userRepo.GetUserByProvider("google", profile.Sub)
+	user, status, key := h.findOrCreateUser(provider.Name(), identity)
+	if user == nil {
+		c.JSON(status, gin.H{"error": localize(c, key)})
+		return
+	}
+
+	// Users with two-factor authentication finish signing in with a code
+	mfaEnabled, err := h.mfaService.Enabled(user.ID)
 	if err != nil {
-		// not found => try to find by email
-		existing, err2 := h.userRepo.GetUserByEmail(profile.Email)
-		if err2 == nil && existing != nil {
-			// link existing account
-			_ = h.userRepo.UpdateUserOAuth(existing.ID, "google", profile.Sub, profile.Picture, profile.EmailVerified)
-			user = existing
-		} else {
-			// create new user
-			u, err3 := h.userRepo.CreateUserWithProvider(profile.Email, profile.Name, "free", "google", profile.Sub, profile.Picture, profile.EmailVerified)
-			if err3 != nil {
-				c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create user error is " + err3.Error()})
-				return
-			}
-			user = u
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "errors.failed_to_check_mfa")})
+		return
+	}
+	if mfaEnabled {
+		if _, ok := startMFAChallenge(c, user.ID); ok {
+			writeOAuthResultPage(c, "mfa_required")
 		}
+		return
 	}
 
-	// Generate internal JWT
-	token, err := generateJWTToken(user.ID, user.Email)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to generate token"})
+	// Start a session and set the auth cookies
+	if startSession(c, h.sessionService, user, sessionMetadata(c, "")) == nil {
 		return
 	}
+	writeOAuthResultPage(c, "oauth_success")
+}
 
-	// Set auth cookie (HttpOnly). Use env to determine cookie attributes where possible.
-	cookieDomain := os.Getenv("AUTH_COOKIE_DOMAIN") // optional, leave empty for host-only cookie
+// findOrCreateUser returns the user signed in by identity: the account
+// already linked to it, an existing account with the same verified email
+// which is then linked, or a new account. On failure it returns the status
+// and message key to respond with.
+func (h *OAuthHandler) findOrCreateUser(provider string, identity *auth.OIDCIdentity) (*models.User, int, string) {
+	if user, err := h.userRepo.GetUserByProvider(provider, identity.Subject); err == nil {
+		return user, 0, ""
+	}
+	if identity.Email == "" {
+		return nil, http.StatusBadRequest, "errors.oauth_email_required"
+	}
 
-	// Determine secure flag: explicit env takes precedence, then production, then TLS
-	secureFlag := false
-	if os.Getenv("AUTH_COOKIE_SECURE") == "true" {
-		secureFlag = true
-	} else if os.Getenv("ENV") == "production" {
-		secureFlag = true
-	} else if c.Request.TLS != nil {
-		secureFlag = true
+	if existing, err := h.userRepo.GetUserByEmail(identity.Email); err == nil && existing != nil {
+		// Only a provider that has verified the address may claim the account
+		if !identity.EmailVerified {
+			return nil, http.StatusConflict, "errors.oauth_email_unverified"
+		}
+		if err := h.userRepo.UpdateUserOAuth(existing.ID, provider, identity.Subject, identity.Picture, identity.EmailVerified); err != nil {
+			return nil, http.StatusInternalServerError, "errors.failed_to_create_user"
+		}
+		return existing, 0, ""
 	}
 
-	// Determine SameSite: in production prefer Lax for better CSRF protection; in dev allow None for popups
-	sameSite := http.SameSiteNoneMode
-	if os.Getenv("ENV") == "production" {
-		sameSite = http.SameSiteLaxMode
+	name := identity.Name
+	if name == "" {
+		name, _, _ = strings.Cut(identity.Email, "@")
 	}
+	user, err := h.userRepo.CreateUserWithProvider(identity.Email, name, models.TierFree, provider, identity.Subject, identity.Picture, identity.EmailVerified)
+	if err != nil {
+		return nil, http.StatusInternalServerError, "errors.failed_to_create_user"
+	}
+	return user, 0, ""
+}
 
+// setOAuthStateCookie stores the sign-in state. Providers that POST the
+// response do so cross-site, which browsers only send SameSite=None cookies
+// with, and those must be Secure.
+func setOAuthStateCookie(c *gin.Context, provider *auth.OIDCProvider, value string, maxAge int) {
+	if !provider.UsesFormPost() {
+		setAuthCookie(c, oauthStateCookie, value, oauthStateCookiePath, maxAge)
+		return
+	}
 	http.SetCookie(c.Writer, &http.Cookie{
-		Name:     "auth_token",
-		Value:    token,
-		Path:     "/",
-		Domain:   cookieDomain,
+		Name:     oauthStateCookie,
+		Value:    value,
+		Path:     oauthStateCookiePath,
+		Domain:   os.Getenv("AUTH_COOKIE_DOMAIN"),
 		HttpOnly: true,
-		Secure:   secureFlag,
-		SameSite: sameSite,
-		MaxAge:   60 * 60 * 24 * 7, // 7 days
+		Secure:   true,
+		SameSite: http.SameSiteNoneMode,
+		MaxAge:   maxAge,
 	})
+}
+
+func readOAuthState(c *gin.Context) (oauthState, bool) {
+	var state oauthState
+	value, err := c.Cookie(oauthStateCookie)
+	if err != nil {
+		return state, false
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil || json.Unmarshal(raw, &state) != nil || state.State == "" {
+		return state, false
+	}
+	return state, true
+}
 
-	// Respond with a small HTML page that notifies opener (popup) and closes.
+// oauthCallbackParam reads callback parameters from the form body of POSTed
+// responses and from the query string otherwise.
+func oauthCallbackParam(c *gin.Context) func(string) string {
+	if c.Request.Method == http.MethodPost {
+		return c.PostForm
+	}
+	return c.Query
+}
+
+// writeOAuthResultPage responds with a small HTML page that notifies the
+// opener (popup) and closes. messageType is "oauth_success", or
+// "mfa_required" when the user must still enter a second factor.
+func writeOAuthResultPage(c *gin.Context, messageType string) {
 	frontendSuccess := os.Getenv("FRONTEND_OAUTH_SUCCESS_URL")
 	if frontendSuccess == "" {
 		frontendSuccess = os.Getenv("APP_BASE_URL")
@@ -132,12 +223,16 @@ func (h *OAuthHandler) GoogleCallback(c *gin.Context) {
 		}
 		frontendSuccess = frontendSuccess + "/auth/oauth/success"
 	}
+	if messageType == "mfa_required" {
+		frontendSuccess += "?mfa=required"
+	}
 
 	html := `<!doctype html><html><head><meta charset="utf-8"></head><body><script>
     try {
         if (window.opener) {
-            // Notify opener that oauth succeeded; opener should fetch /api/auth/me
-            window.opener.postMessage({ type: 'oauth_success' }, '*');
+            // Notify opener; on oauth_success it should fetch /api/auth/me,
+            // on mfa_required ask for a code and POST /api/auth/mfa/verify
+            window.opener.postMessage({ type: '` + messageType + `' }, '*');
             window.close();
         } else {
             // No opener - navigate the current window to frontend success
@@ -9,6 +9,7 @@ import (
 	"gaspeep/backend/internal/auth"
 	"gaspeep/backend/internal/repository"
 	testhelpers "gaspeep/backend/internal/repository/testhelpers"
+	"gaspeep/backend/internal/service"
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -21,10 +22,11 @@ func TestGoogleCallback_CreateNewUser_Integration(t *testing.T) {
 	// Create user repo
 	userRepo := repository.NewPgUserRepository(db)
 
-	h := NewOAuthHandler(userRepo)
+	providers, _ := newTestOIDCProviders(t)
+	h := NewOAuthHandler(userRepo, service.NewSessionService(repository.NewPgSessionRepository(db), userRepo), service.NewMFAService(repository.NewPgMFARepository(db)), providers)
 
 	// Test the repository interaction patterns
-	// In real scenario, this would be called after auth.FetchProfile returns user info
+	// In real scenario, this would be called after the provider's ID token is verified
 	email := "oauth-new@example.com"
 	displayName := "OAuth User"
 	tier := "free"
@@ -58,7 +60,8 @@ func TestGoogleCallback_LinkExistingUser_Integration(t *testing.T) {
 	existingUser := testhelpers.CreateTestUser(t, db)
 
 	userRepo := repository.NewPgUserRepository(db)
-	h := NewOAuthHandler(userRepo)
+	providers, _ := newTestOIDCProviders(t)
+	h := NewOAuthHandler(userRepo, service.NewSessionService(repository.NewPgSessionRepository(db), userRepo), service.NewMFAService(repository.NewPgMFARepository(db)), providers)
 
 	// Simulate OAuth flow: user already exists by email
 	user, err := userRepo.GetUserByEmail(existingUser.Email)
@@ -108,13 +111,14 @@ func TestOAuthHandler_CookieAttributes_Integration(t *testing.T) {
 	db := testhelpers.SetupTestDBWithCleanup(t)
 
 	userRepo := repository.NewPgUserRepository(db)
-	h := NewOAuthHandler(userRepo)
+	providers, _ := newTestOIDCProviders(t)
+	h := NewOAuthHandler(userRepo, service.NewSessionService(repository.NewPgSessionRepository(db), userRepo), service.NewMFAService(repository.NewPgMFARepository(db)), providers)
 
 	// Create test user
 	user := testhelpers.CreateTestUser(t, db)
 
 	// Generate JWT token (simulating OAuth success)
-	token, err := auth.GenerateToken(user.ID, user.Email)
+	token, err := auth.GenerateToken(user.ID, user.Email, "", false)
 	require.NoError(t, err)
 	require.NotEmpty(t, token)
 
@@ -130,14 +134,15 @@ func TestOAuthHandler_InvalidState_Integration(t *testing.T) {
 	db := testhelpers.SetupTestDBWithCleanup(t)
 
 	userRepo := repository.NewPgUserRepository(db)
-	h := NewOAuthHandler(userRepo)
+	providers, _ := newTestOIDCProviders(t)
+	h := NewOAuthHandler(userRepo, service.NewSessionService(repository.NewPgSessionRepository(db), userRepo), service.NewMFAService(repository.NewPgMFARepository(db)), providers)
 
 	gin.SetMode(gin.TestMode)
 	router := gin.New()
-	router.GET("/api/auth/oauth/callback", h.GoogleCallback)
+	router.GET("/api/auth/oauth/:provider/callback", h.Callback)
 
 	// Test with mismatched state
-	req := httptest.NewRequest(http.MethodGet, "/api/auth/oauth/callback?code=auth_code&state=wrong_state", nil)
+	req := httptest.NewRequest(http.MethodGet, "/api/auth/oauth/google/callback?code=auth_code&state=wrong_state", nil)
 	req.AddCookie(&http.Cookie{
 		Name:  "oauth_state",
 		Value: "different_state",
@@ -158,14 +163,15 @@ func TestOAuthHandler_MissingState_Integration(t *testing.T) {
 	db := testhelpers.SetupTestDBWithCleanup(t)
 
 	userRepo := repository.NewPgUserRepository(db)
-	h := NewOAuthHandler(userRepo)
+	providers, _ := newTestOIDCProviders(t)
+	h := NewOAuthHandler(userRepo, service.NewSessionService(repository.NewPgSessionRepository(db), userRepo), service.NewMFAService(repository.NewPgMFARepository(db)), providers)
 
 	gin.SetMode(gin.TestMode)
 	router := gin.New()
-	router.GET("/api/auth/oauth/callback", h.GoogleCallback)
+	router.GET("/api/auth/oauth/:provider/callback", h.Callback)
 
 	// Request without state cookie
-	req := httptest.NewRequest(http.MethodGet, "/api/auth/oauth/callback?code=auth_code&state=some_state", nil)
+	req := httptest.NewRequest(http.MethodGet, "/api/auth/oauth/google/callback?code=auth_code&state=some_state", nil)
 
 	rr := httptest.NewRecorder()
 	router.ServeHTTP(rr, req)
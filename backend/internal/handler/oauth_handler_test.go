@@ -7,6 +7,7 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	"gaspeep/backend/internal/auth"
 	"gaspeep/backend/internal/models"
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/mock"
@@ -82,8 +83,8 @@ func (m *MockUserRepositoryOAuth) GetUserIDByEmail(email string) (string, error)
 	return args.String(0), args.Error(1)
 }
 
-func (m *MockUserRepositoryOAuth) UpdateProfile(userID, displayName, tier string) (string, error) {
-	args := m.Called(userID, displayName, tier)
+func (m *MockUserRepositoryOAuth) UpdateProfile(userID, displayName string) (string, error) {
+	args := m.Called(userID, displayName)
 	return args.String(0), args.Error(1)
 }
 func (m *MockUserRepositoryOAuth) GetMapFilterPreferences(userID string) (*models.MapFilterPreferences, error) {
@@ -97,6 +98,15 @@ func (m *MockUserRepositoryOAuth) UpdateMapFilterPreferences(userID string, pref
 	args := m.Called(userID, prefs)
 	return args.Error(0)
 }
+func (m *MockUserRepositoryOAuth) UpdateTimeZone(userID, timeZone string) error {
+	args := m.Called(userID, timeZone)
+	return args.Error(0)
+}
+
+func (m *MockUserRepositoryOAuth) UpdateLocale(userID, locale string) error {
+	args := m.Called(userID, locale)
+	return args.Error(0)
+}
 
 // TestStartGoogle_SetsStateCookie verifies that StartGoogle sets an OAuth state cookie
 func TestStartGoogle_SetsStateCookie(t *testing.T) {
@@ -117,7 +127,7 @@ func TestGoogleCallback_CreateNewUser(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
 	mockRepo := new(MockUserRepositoryOAuth)
-	_ = NewOAuthHandler(mockRepo)
+	_ = NewOAuthHandler(mockRepo, newAllowingSessionService(), newNoMFAService(), auth.NewOIDCProviders())
 
 	// Mock: no user by provider
 	mockRepo.On("GetUserByProvider", "google", "google123").Return(nil, errors.New("not found"))
@@ -147,7 +157,7 @@ func TestGoogleCallback_LinksExistingUser(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
 	mockRepo := new(MockUserRepositoryOAuth)
-	_ = NewOAuthHandler(mockRepo)
+	_ = NewOAuthHandler(mockRepo, newAllowingSessionService(), newNoMFAService(), auth.NewOIDCProviders())
 
 	existingUser := &models.User{
 		ID:    "existing_user_123",
@@ -171,7 +181,7 @@ func TestGoogleCallback_CookieAttributes(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
 	mockRepo := new(MockUserRepositoryOAuth)
-	h := NewOAuthHandler(mockRepo)
+	h := NewOAuthHandler(mockRepo, newAllowingSessionService(), newNoMFAService(), auth.NewOIDCProviders())
 
 	// This is tested indirectly through the auth_handler_test patterns
 	// The cookie attributes are set based on environment variables
@@ -213,13 +223,14 @@ func TestGoogleCallback_InvalidState(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
 	mockRepo := new(MockUserRepositoryOAuth)
-	h := NewOAuthHandler(mockRepo)
+	providers, _ := newTestOIDCProviders(t)
+	h := NewOAuthHandler(mockRepo, newAllowingSessionService(), newNoMFAService(), providers)
 
 	router := gin.New()
-	router.GET("/api/auth/oauth/callback", h.GoogleCallback)
+	router.GET("/api/auth/oauth/:provider/callback", h.Callback)
 
 	// Create request with mismatched state
-	req := httptest.NewRequest(http.MethodGet, "/api/auth/oauth/callback?code=auth_code&state=wrong_state", nil)
+	req := httptest.NewRequest(http.MethodGet, "/api/auth/oauth/google/callback?code=auth_code&state=wrong_state", nil)
 
 	// Set different state cookie
 	req.AddCookie(&http.Cookie{
@@ -247,13 +258,14 @@ func TestGoogleCallback_MissingCode(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
 	mockRepo := new(MockUserRepositoryOAuth)
-	h := NewOAuthHandler(mockRepo)
+	providers, _ := newTestOIDCProviders(t)
+	h := NewOAuthHandler(mockRepo, newAllowingSessionService(), newNoMFAService(), providers)
 
 	router := gin.New()
-	router.GET("/api/auth/oauth/callback", h.GoogleCallback)
+	router.GET("/api/auth/oauth/:provider/callback", h.Callback)
 
 	// Request with missing code parameter
-	req := httptest.NewRequest(http.MethodGet, "/api/auth/oauth/callback?state=some_state", nil)
+	req := httptest.NewRequest(http.MethodGet, "/api/auth/oauth/google/callback?state=some_state", nil)
 	req.AddCookie(&http.Cookie{
 		Name:  "oauth_state",
 		Value: "some_state",
@@ -274,7 +286,7 @@ func TestGoogleCallback_ResponseHTML(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
 	mockRepo := new(MockUserRepositoryOAuth)
-	h := NewOAuthHandler(mockRepo)
+	h := NewOAuthHandler(mockRepo, newAllowingSessionService(), newNoMFAService(), auth.NewOIDCProviders())
 
 	// The actual response format is tested in integration tests
 	// This is a placeholder for the structure verification
@@ -6,6 +6,7 @@ import (
 	"strings"
 	"testing"
 
+	"gaspeep/backend/internal/auth"
 	"gaspeep/backend/internal/service"
 
 	"github.com/gin-gonic/gin"
@@ -13,31 +14,28 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
-func TestOAuthHandlerStartGoogle_ConfigMissing(t *testing.T) {
+func TestOAuthHandlerStart_ProviderNotConfigured(t *testing.T) {
 	gin.SetMode(gin.TestMode)
-	t.Setenv("GOOGLE_OAUTH_ID", "")
-	t.Setenv("GOOGLE_OAUTH_REDIRECT", "")
 
-	h := NewOAuthHandler(new(MockUserRepositoryOAuth))
+	h := NewOAuthHandler(new(MockUserRepositoryOAuth), newAllowingSessionService(), newNoMFAService(), auth.NewOIDCProviders())
 	r := gin.New()
-	r.GET("/oauth/google", h.StartGoogle)
+	r.GET("/oauth/:provider", h.Start)
 
 	req := httptest.NewRequest(http.MethodGet, "/oauth/google", nil)
 	w := httptest.NewRecorder()
 	r.ServeHTTP(w, req)
 
-	assert.Equal(t, http.StatusInternalServerError, w.Code)
-	assert.Contains(t, w.Body.String(), "not configured")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Contains(t, w.Body.String(), "unknown sign-in provider")
 }
 
-func TestOAuthHandlerStartGoogle_RedirectsAndSetsStateCookie(t *testing.T) {
+func TestOAuthHandlerStart_RedirectsAndSetsStateCookie(t *testing.T) {
 	gin.SetMode(gin.TestMode)
-	t.Setenv("GOOGLE_OAUTH_ID", "client-id")
-	t.Setenv("GOOGLE_OAUTH_REDIRECT", "https://example.com/callback")
 
-	h := NewOAuthHandler(new(MockUserRepositoryOAuth))
+	providers, issuer := newTestOIDCProviders(t)
+	h := NewOAuthHandler(new(MockUserRepositoryOAuth), newAllowingSessionService(), newNoMFAService(), providers)
 	r := gin.New()
-	r.GET("/oauth/google", h.StartGoogle)
+	r.GET("/oauth/:provider", h.Start)
 
 	req := httptest.NewRequest(http.MethodGet, "/oauth/google", nil)
 	w := httptest.NewRecorder()
@@ -45,9 +43,11 @@ func TestOAuthHandlerStartGoogle_RedirectsAndSetsStateCookie(t *testing.T) {
 
 	assert.Equal(t, http.StatusFound, w.Code)
 	location := w.Header().Get("Location")
-	assert.Contains(t, location, "accounts.google.com")
+	assert.True(t, strings.HasPrefix(location, issuer.URL+"/authorize?"))
 	assert.Contains(t, location, "client_id=client-id")
-	assert.Contains(t, location, "redirect_uri=https%3A%2F%2Fexample.com%2Fcallback")
+	assert.Contains(t, location, "redirect_uri=https%3A%2F%2Fapp.example.com%2Fapi%2Fauth%2Foauth%2Fgoogle%2Fcallback")
+	assert.Contains(t, location, "code_challenge_method=S256")
+	assert.Contains(t, location, "nonce=")
 
 	cookies := w.Result().Cookies()
 	require.NotEmpty(t, cookies)
@@ -57,29 +57,28 @@ func TestOAuthHandlerStartGoogle_RedirectsAndSetsStateCookie(t *testing.T) {
 			foundState = true
 			assert.NotEmpty(t, c.Value)
 			assert.True(t, c.HttpOnly)
+			assert.Equal(t, "/api/auth/oauth", c.Path)
 		}
 	}
 	assert.True(t, foundState)
 }
 
-func TestOAuthHandlerGoogleCallback_TokenExchangeFails(t *testing.T) {
+func TestOAuthHandlerStart_DiscoveryUnavailable(t *testing.T) {
 	gin.SetMode(gin.TestMode)
-	// Intentionally omit GOOGLE_OAUTH_SECRET to force ExchangeCode config error without external network call.
-	t.Setenv("GOOGLE_OAUTH_ID", "client-id")
-	t.Setenv("GOOGLE_OAUTH_REDIRECT", "https://example.com/callback")
-	t.Setenv("GOOGLE_OAUTH_SECRET", "")
 
-	h := NewOAuthHandler(new(MockUserRepositoryOAuth))
+	// Nothing listens on the issuer, so discovery fails without reaching the network
+	p, err := auth.NewOIDCProvider(auth.OIDCConfig{Name: "google", Issuer: "http://127.0.0.1:1", ClientID: "client-id", RedirectURL: "https://example.com/callback"})
+	require.NoError(t, err)
+	h := NewOAuthHandler(new(MockUserRepositoryOAuth), newAllowingSessionService(), newNoMFAService(), auth.NewOIDCProviders(p))
 	r := gin.New()
-	r.GET("/oauth/callback", h.GoogleCallback)
+	r.GET("/oauth/:provider", h.Start)
 
-	req := httptest.NewRequest(http.MethodGet, "/oauth/callback?code=abc123&state=s1", nil)
-	req.AddCookie(&http.Cookie{Name: "oauth_state", Value: "s1"})
+	req := httptest.NewRequest(http.MethodGet, "/oauth/google", nil)
 	w := httptest.NewRecorder()
 	r.ServeHTTP(w, req)
 
-	assert.Equal(t, http.StatusBadRequest, w.Code)
-	assert.Contains(t, w.Body.String(), "token exchange failed")
+	assert.Equal(t, http.StatusBadGateway, w.Code)
+	assert.Contains(t, w.Body.String(), "unavailable")
 }
 
 func TestServiceNSWSyncHandler_TriggerSync_FailedDependency(t *testing.T) {
@@ -0,0 +1,147 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"gaspeep/backend/internal/repository"
+	"gaspeep/backend/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// OwnerTeamHandler handles the members of station owner businesses and
+// invitations to join them
+type OwnerTeamHandler struct {
+	teamService service.OwnerTeamService
+}
+
+func NewOwnerTeamHandler(teamService service.OwnerTeamService) *OwnerTeamHandler {
+	return &OwnerTeamHandler{teamService: teamService}
+}
+
+// respondTeamError writes the response for a failed team change, falling
+// back to a 500 with failedKey.
+func respondTeamError(c *gin.Context, err error, failedKey string) {
+	switch {
+	case errors.Is(err, service.ErrInvalidTeamInput):
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "errors.invalid_team_input")})
+	case errors.Is(err, repository.ErrStationOwnerNotFound), errors.Is(err, repository.ErrStationPermissionDenied):
+		c.JSON(http.StatusForbidden, gin.H{"error": localize(c, "errors.station_permission_denied")})
+	case errors.Is(err, service.ErrTeamMemberNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": localize(c, "errors.team_member_not_found")})
+	case errors.Is(err, service.ErrTeamInvitationNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": localize(c, "errors.team_invitation_not_found")})
+	case errors.Is(err, repository.ErrAccountHolderMember):
+		c.JSON(http.StatusConflict, gin.H{"error": localize(c, "errors.account_holder_member")})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, failedKey)})
+	}
+}
+
+// GetMyMembership handles GET /api/station-owners/team/me
+func (h *OwnerTeamHandler) GetMyMembership(c *gin.Context) {
+	member, err := h.teamService.Me(c.GetString("userID"))
+	if errors.Is(err, repository.ErrStationOwnerNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": localize(c, "errors.team_member_not_found")})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "errors.failed_to_fetch_team")})
+		return
+	}
+	c.JSON(http.StatusOK, member)
+}
+
+// GetTeam handles GET /api/station-owners/team. It lists members and pending
+// invitations, for owners and managers only.
+func (h *OwnerTeamHandler) GetTeam(c *gin.Context) {
+	team, err := h.teamService.Team(c.GetString("userID"))
+	if err != nil {
+		respondTeamError(c, err, "errors.failed_to_fetch_team")
+		return
+	}
+	c.JSON(http.StatusOK, team)
+}
+
+// InviteMember handles POST /api/station-owners/team/invitations
+func (h *OwnerTeamHandler) InviteMember(c *gin.Context) {
+	var req service.TeamInvitationInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	inv, err := h.teamService.Invite(c.GetString("userID"), req)
+	if err != nil {
+		respondTeamError(c, err, "errors.failed_to_invite_team_member")
+		return
+	}
+	c.JSON(http.StatusCreated, inv)
+}
+
+// RevokeInvitation handles DELETE /api/station-owners/team/invitations/:id
+func (h *OwnerTeamHandler) RevokeInvitation(c *gin.Context) {
+	if err := h.teamService.RevokeInvitation(c.GetString("userID"), c.Param("id")); err != nil {
+		respondTeamError(c, err, "errors.failed_to_revoke_team_invitation")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": localize(c, "messages.team_invitation_revoked")})
+}
+
+// AcceptInvitation handles POST /api/station-owners/team/invitations/accept.
+// Any signed-in user can call it with the token from an invitation email.
+func (h *OwnerTeamHandler) AcceptInvitation(c *gin.Context) {
+	var req struct {
+		Token string `json:"token" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	member, err := h.teamService.AcceptInvitation(c.GetString("userID"), req.Token)
+	switch {
+	case errors.Is(err, service.ErrEmailNotVerified):
+		c.JSON(http.StatusForbidden, gin.H{"error": localize(c, "errors.email_not_verified_for_team")})
+		return
+	case errors.Is(err, service.ErrInvalidTeamInvitation):
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "errors.invalid_team_invitation")})
+		return
+	case errors.Is(err, service.ErrTeamInvitationEmailMismatch):
+		c.JSON(http.StatusForbidden, gin.H{"error": localize(c, "errors.team_invitation_email_mismatch")})
+		return
+	case errors.Is(err, repository.ErrAlreadyTeamMember):
+		c.JSON(http.StatusConflict, gin.H{"error": localize(c, "errors.already_team_member")})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "errors.failed_to_accept_team_invitation")})
+		return
+	}
+	c.JSON(http.StatusOK, member)
+}
+
+// UpdateMember handles PUT /api/station-owners/team/members/:id
+func (h *OwnerTeamHandler) UpdateMember(c *gin.Context) {
+	var req service.TeamMemberInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	member, err := h.teamService.UpdateMember(c.GetString("userID"), c.Param("id"), req)
+	if err != nil {
+		respondTeamError(c, err, "errors.failed_to_update_team_member")
+		return
+	}
+	c.JSON(http.StatusOK, member)
+}
+
+// RemoveMember handles DELETE /api/station-owners/team/members/:id
+func (h *OwnerTeamHandler) RemoveMember(c *gin.Context) {
+	if err := h.teamService.RemoveMember(c.GetString("userID"), c.Param("id")); err != nil {
+		respondTeamError(c, err, "errors.failed_to_remove_team_member")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": localize(c, "messages.team_member_removed")})
+}
@@ -0,0 +1,144 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gaspeep/backend/internal/handler/testhelpers"
+	"gaspeep/backend/internal/models"
+	"gaspeep/backend/internal/repository"
+	"gaspeep/backend/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newOwnerTeamRouter(teams service.OwnerTeamService) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	h := NewOwnerTeamHandler(teams)
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Set("userID", "u1")
+		c.Next()
+	})
+	r.GET("/api/station-owners/team", h.GetTeam)
+	r.GET("/api/station-owners/team/me", h.GetMyMembership)
+	r.POST("/api/station-owners/team/invitations", h.InviteMember)
+	r.DELETE("/api/station-owners/team/invitations/:id", h.RevokeInvitation)
+	r.POST("/api/station-owners/team/invitations/accept", h.AcceptInvitation)
+	r.PUT("/api/station-owners/team/members/:id", h.UpdateMember)
+	r.DELETE("/api/station-owners/team/members/:id", h.RemoveMember)
+	return r
+}
+
+func TestOwnerTeam_InviteMember(t *testing.T) {
+	teams := new(testhelpers.MockOwnerTeamService)
+	input := service.TeamInvitationInput{
+		Email:    "kim@example.com",
+		Role:     models.OwnerRoleStaff,
+		Stations: []models.StationPermissions{{StationID: "s1", Permissions: []string{models.StationPermissionPublishPrices}}},
+	}
+	teams.On("Invite", "u1", input).Return(&models.OwnerTeamInvitation{ID: "inv-1", Email: "kim@example.com", Role: models.OwnerRoleStaff}, nil).Once()
+	r := newOwnerTeamRouter(teams)
+
+	w := postJSON(r, "/api/station-owners/team/invitations", input)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Contains(t, w.Body.String(), `"id":"inv-1"`)
+
+	// Email and role are required
+	w = postJSON(r, "/api/station-owners/team/invitations", map[string]string{"email": "kim@example.com"})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	teams.AssertExpectations(t)
+}
+
+func TestOwnerTeam_ChangeErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		status int
+	}{
+		{"invalid input", service.ErrInvalidTeamInput, http.StatusBadRequest},
+		{"not a manager", repository.ErrStationPermissionDenied, http.StatusForbidden},
+		{"no business", repository.ErrStationOwnerNotFound, http.StatusForbidden},
+		{"unknown member", service.ErrTeamMemberNotFound, http.StatusNotFound},
+		{"account holder", repository.ErrAccountHolderMember, http.StatusConflict},
+		{"other", assert.AnError, http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			teams := new(testhelpers.MockOwnerTeamService)
+			teams.On("UpdateMember", "u1", "m1", mock.Anything).Return(nil, tt.err).Once()
+			teams.On("RemoveMember", "u1", "m1").Return(tt.err).Once()
+			r := newOwnerTeamRouter(teams)
+
+			body, _ := json.Marshal(map[string]string{"role": models.OwnerRoleManager})
+			req := httptest.NewRequest(http.MethodPut, "/api/station-owners/team/members/m1", bytes.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			assert.Equal(t, tt.status, w.Code)
+
+			w = httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/api/station-owners/team/members/m1", nil))
+			assert.Equal(t, tt.status, w.Code)
+		})
+	}
+}
+
+func TestOwnerTeam_RevokeInvitation(t *testing.T) {
+	teams := new(testhelpers.MockOwnerTeamService)
+	teams.On("RevokeInvitation", "u1", "inv-1").Return(nil).Once()
+	teams.On("RevokeInvitation", "u1", "inv-2").Return(service.ErrTeamInvitationNotFound).Once()
+	r := newOwnerTeamRouter(teams)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/api/station-owners/team/invitations/inv-1", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/api/station-owners/team/invitations/inv-2", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestOwnerTeam_AcceptInvitation(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		status int
+	}{
+		{"accepted", nil, http.StatusOK},
+		{"email not verified", service.ErrEmailNotVerified, http.StatusForbidden},
+		{"invalid token", service.ErrInvalidTeamInvitation, http.StatusBadRequest},
+		{"other account", service.ErrTeamInvitationEmailMismatch, http.StatusForbidden},
+		{"already a member", repository.ErrAlreadyTeamMember, http.StatusConflict},
+		{"other", assert.AnError, http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			teams := new(testhelpers.MockOwnerTeamService)
+			var member *models.OwnerTeamMember
+			if tt.err == nil {
+				member = &models.OwnerTeamMember{ID: "m2", Role: models.OwnerRoleStaff}
+			}
+			teams.On("AcceptInvitation", "u1", "token-1").Return(member, tt.err).Once()
+			r := newOwnerTeamRouter(teams)
+
+			w := postJSON(r, "/api/station-owners/team/invitations/accept", map[string]string{"token": "token-1"})
+			assert.Equal(t, tt.status, w.Code)
+		})
+	}
+}
+
+func TestOwnerTeam_GetMyMembership(t *testing.T) {
+	teams := new(testhelpers.MockOwnerTeamService)
+	teams.On("Me", "u1").Return(nil, repository.ErrStationOwnerNotFound).Once()
+	r := newOwnerTeamRouter(teams)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/station-owners/team/me", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
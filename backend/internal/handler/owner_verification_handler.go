@@ -0,0 +1,114 @@
+package handler
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"gaspeep/backend/internal/repository"
+	"gaspeep/backend/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// OwnerVerificationHandler handles owners re-verifying their stations and
+// the admin review of claims and re-verifications
+type OwnerVerificationHandler struct {
+	verificationService service.OwnerVerificationService
+}
+
+func NewOwnerVerificationHandler(verificationService service.OwnerVerificationService) *OwnerVerificationHandler {
+	return &OwnerVerificationHandler{verificationService: verificationService}
+}
+
+// ReVerifyStation handles POST /api/station-owners/stations/:id/reverify. It
+// takes the same evidence as a claim.
+func (h *OwnerVerificationHandler) ReVerifyStation(c *gin.Context) {
+	var req ClaimEvidenceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	claim, err := h.verificationService.ReVerify(c.GetString("userID"), c.Param("id"), repository.ClaimEvidence{
+		VerificationMethod: req.VerificationMethod,
+		DocumentURLs:       req.DocumentUrls,
+		PhoneNumber:        req.PhoneNumber,
+		Email:              req.Email,
+	})
+	switch {
+	case errors.Is(err, service.ErrEmailNotVerified):
+		c.JSON(http.StatusForbidden, gin.H{"error": localize(c, "errors.email_not_verified_for_claim")})
+		return
+	case errors.Is(err, repository.ErrNoClaimToRenew):
+		c.JSON(http.StatusNotFound, gin.H{"error": localize(c, "errors.no_station_claim_to_renew")})
+		return
+	case errors.Is(err, repository.ErrClaimRenewalPending):
+		c.JSON(http.StatusConflict, gin.H{"error": localize(c, "errors.station_reverification_pending")})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "errors.failed_to_reverify_station")})
+		return
+	}
+
+	c.JSON(http.StatusCreated, claim)
+}
+
+// GetClaims handles GET /api/admin/claim-verifications. It lists pending
+// claims and re-verifications unless a status is given.
+func (h *OwnerVerificationHandler) GetClaims(c *gin.Context) {
+	var req struct {
+		Status string `form:"status" binding:"omitempty,oneof=pending approved rejected expired renewed"`
+	}
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	claims, err := h.verificationService.Claims(req.Status)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "errors.failed_to_fetch_claim_verifications")})
+		return
+	}
+	c.JSON(http.StatusOK, claims)
+}
+
+// respondClaimReviewError writes the response for a failed claim review.
+func respondClaimReviewError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrClaimVerificationNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": localize(c, "errors.claim_verification_not_found")})
+	case errors.Is(err, repository.ErrClaimNotPending):
+		c.JSON(http.StatusConflict, gin.H{"error": localize(c, "errors.claim_verification_not_pending")})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "errors.failed_to_review_claim_verification")})
+	}
+}
+
+// ApproveClaim handles POST /api/admin/claim-verifications/:id/approve
+func (h *OwnerVerificationHandler) ApproveClaim(c *gin.Context) {
+	claim, err := h.verificationService.Approve(c.Param("id"), c.GetString("userID"))
+	if err != nil {
+		respondClaimReviewError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, claim)
+}
+
+// RejectClaim handles POST /api/admin/claim-verifications/:id/reject. The
+// reason is optional.
+func (h *OwnerVerificationHandler) RejectClaim(c *gin.Context) {
+	var req struct {
+		Reason string `json:"reason"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.verificationService.Reject(c.Param("id"), c.GetString("userID"), req.Reason); err != nil {
+		respondClaimReviewError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": localize(c, "messages.claim_verification_rejected")})
+}
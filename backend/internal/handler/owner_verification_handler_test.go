@@ -0,0 +1,109 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gaspeep/backend/internal/handler/testhelpers"
+	"gaspeep/backend/internal/models"
+	"gaspeep/backend/internal/repository"
+	"gaspeep/backend/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newOwnerVerificationRouter(verifications service.OwnerVerificationService) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	h := NewOwnerVerificationHandler(verifications)
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Set("userID", "u1")
+		c.Next()
+	})
+	r.POST("/api/station-owners/stations/:id/reverify", h.ReVerifyStation)
+	r.GET("/api/admin/claim-verifications", h.GetClaims)
+	r.POST("/api/admin/claim-verifications/:id/approve", h.ApproveClaim)
+	r.POST("/api/admin/claim-verifications/:id/reject", h.RejectClaim)
+	return r
+}
+
+func TestOwnerVerification_ReVerifyStation(t *testing.T) {
+	verifications := new(testhelpers.MockOwnerVerificationService)
+	verifications.On("ReVerify", "u1", "s1", repository.ClaimEvidence{
+		VerificationMethod: "document",
+		DocumentURLs:       []string{"https://example.com/lease.pdf"},
+	}).Return(&models.ClaimVerification{ID: "claim-2", Status: models.ClaimVerificationPending}, nil).Once()
+	r := newOwnerVerificationRouter(verifications)
+
+	w := postJSON(r, "/api/station-owners/stations/s1/reverify", map[string]interface{}{
+		"verificationMethod": "document",
+		"documentUrls":       []string{"https://example.com/lease.pdf"},
+	})
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Contains(t, w.Body.String(), `"verificationStatus":"pending"`)
+
+	// The evidence is required, as for a claim
+	w = postJSON(r, "/api/station-owners/stations/s1/reverify", map[string]string{})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	verifications.AssertExpectations(t)
+}
+
+func TestOwnerVerification_ReVerifyStationErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		status int
+	}{
+		{"email not verified", service.ErrEmailNotVerified, http.StatusForbidden},
+		{"nothing to renew", repository.ErrNoClaimToRenew, http.StatusNotFound},
+		{"already pending", repository.ErrClaimRenewalPending, http.StatusConflict},
+		{"other", assert.AnError, http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verifications := new(testhelpers.MockOwnerVerificationService)
+			verifications.On("ReVerify", "u1", "s1", mock.Anything).Return(nil, tt.err).Once()
+			r := newOwnerVerificationRouter(verifications)
+
+			w := postJSON(r, "/api/station-owners/stations/s1/reverify", map[string]string{"verificationMethod": "phone"})
+			assert.Equal(t, tt.status, w.Code)
+		})
+	}
+}
+
+func TestOwnerVerification_Review(t *testing.T) {
+	verifications := new(testhelpers.MockOwnerVerificationService)
+	verifications.On("Claims", "expired").Return([]models.ClaimVerification{{ID: "claim-1"}}, nil).Once()
+	verifications.On("Approve", "claim-1", "u1").Return(&models.ClaimVerification{ID: "claim-1", Status: models.ClaimVerificationApproved}, nil).Once()
+	verifications.On("Approve", "claim-2", "u1").Return(nil, repository.ErrClaimNotPending).Once()
+	verifications.On("Reject", "claim-3", "u1", "Lease has expired").Return(nil).Once()
+	verifications.On("Reject", "claim-4", "u1", "").Return(service.ErrClaimVerificationNotFound).Once()
+	r := newOwnerVerificationRouter(verifications)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/admin/claim-verifications?status=expired", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/admin/claim-verifications?status=bogus", nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = postJSON(r, "/api/admin/claim-verifications/claim-1/approve", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"verificationStatus":"approved"`)
+
+	w = postJSON(r, "/api/admin/claim-verifications/claim-2/approve", nil)
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	w = postJSON(r, "/api/admin/claim-verifications/claim-3/reject", map[string]string{"reason": "Lease has expired"})
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// The reason is optional
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/admin/claim-verifications/claim-4/reject", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	verifications.AssertExpectations(t)
+}
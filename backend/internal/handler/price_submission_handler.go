@@ -32,7 +32,7 @@ func (h *PriceSubmissionHandler) SetOCRService(ocrService service.OCRService) {
 func (h *PriceSubmissionHandler) CreatePriceSubmission(c *gin.Context) {
 	userID, exists := c.Get("userID")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		c.JSON(http.StatusUnauthorized, gin.H{"error": localize(c, "errors.user_not_authenticated")})
 		return
 	}
 
@@ -60,7 +60,7 @@ func (h *PriceSubmissionHandler) CreatePriceSubmission(c *gin.Context) {
 	entries := req.Entries
 	if len(entries) == 0 {
 		if req.FuelTypeID == "" || req.Price <= 0 {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "fuelTypeId and price are required when entries is not provided"})
+			c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "errors.fuel_type_and_price_required")})
 			return
 		}
 		entries = []submissionEntry{{
@@ -82,15 +82,15 @@ func (h *PriceSubmissionHandler) CreatePriceSubmission(c *gin.Context) {
 		})
 
 		if errors.Is(err, service.ErrStationNotFound) {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "station not found"})
+			c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "errors.station_not_found")})
 			return
 		}
 		if errors.Is(err, service.ErrFuelTypeNotFound) {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "fuel type not found"})
+			c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "errors.fuel_type_not_found")})
 			return
 		}
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create price submission"})
+			c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "errors.failed_to_create_price_submission")})
 			return
 		}
 
@@ -110,7 +110,7 @@ func (h *PriceSubmissionHandler) CreatePriceSubmission(c *gin.Context) {
 // AnalyzePhoto handles POST /api/price-submissions/analyze-photo
 func (h *PriceSubmissionHandler) AnalyzePhoto(c *gin.Context) {
 	if h.ocrService == nil {
-		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "photo analysis is not configured"})
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": localize(c, "errors.photo_analysis_not_configured")})
 		return
 	}
 
@@ -119,7 +119,7 @@ func (h *PriceSubmissionHandler) AnalyzePhoto(c *gin.Context) {
 		file, _, err = c.Request.FormFile("image")
 	}
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "photo file is required"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "errors.photo_file_required")})
 		return
 	}
 	defer file.Close()
@@ -127,15 +127,15 @@ func (h *PriceSubmissionHandler) AnalyzePhoto(c *gin.Context) {
 	const maxImageBytes = 10 << 20 // 10MB
 	imageBytes, err := io.ReadAll(io.LimitReader(file, maxImageBytes+1))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "failed to read uploaded photo"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "errors.failed_to_read_uploaded_photo")})
 		return
 	}
 	if len(imageBytes) == 0 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "uploaded photo is empty"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "errors.photo_empty")})
 		return
 	}
 	if len(imageBytes) > maxImageBytes {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "uploaded photo exceeds 10MB limit"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "errors.photo_too_large")})
 		return
 	}
 
@@ -147,13 +147,13 @@ func (h *PriceSubmissionHandler) AnalyzePhoto(c *gin.Context) {
 		case errors.Is(err, service.ErrOCRUnavailable):
 			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "photo analysis is temporarily unavailable and error is: " + err.Error()})
 		default:
-			c.JSON(http.StatusBadRequest, gin.H{"error": "failed to analyze photo"})
+			c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "errors.failed_to_analyze_photo")})
 		}
 		return
 	}
 
 	if len(result.Entries) == 0 {
-		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "could not detect readable fuel prices"})
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": localize(c, "errors.no_readable_fuel_prices")})
 		return
 	}
 
@@ -167,7 +167,7 @@ func (h *PriceSubmissionHandler) AnalyzePhoto(c *gin.Context) {
 func (h *PriceSubmissionHandler) GetMySubmissions(c *gin.Context) {
 	userID, exists := c.Get("userID")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		c.JSON(http.StatusUnauthorized, gin.H{"error": localize(c, "errors.user_not_authenticated")})
 		return
 	}
 
@@ -176,7 +176,7 @@ func (h *PriceSubmissionHandler) GetMySubmissions(c *gin.Context) {
 
 	submissions, total, err := h.submissionService.GetMySubmissions(userID.(string), page, limit)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch submissions"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "errors.failed_to_fetch_submissions")})
 		return
 	}
 
@@ -198,7 +198,7 @@ func (h *PriceSubmissionHandler) GetModerationQueue(c *gin.Context) {
 
 	submissions, total, err := h.submissionService.GetModerationQueue(status, page, limit)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch moderation queue"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "errors.failed_to_fetch_moderation_queue")})
 		return
 	}
 
@@ -228,20 +228,20 @@ func (h *PriceSubmissionHandler) ModerateSubmission(c *gin.Context) {
 
 	updated, err := h.submissionService.ModerateSubmission(id, req.Status, req.ModeratorNotes)
 	if errors.Is(err, sql.ErrNoRows) {
-		c.JSON(http.StatusNotFound, gin.H{"error": "submission not found"})
+		c.JSON(http.StatusNotFound, gin.H{"error": localize(c, "errors.submission_not_found")})
 		return
 	}
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update submission"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "errors.failed_to_update_submission")})
 		return
 	}
 	if !updated {
-		c.JSON(http.StatusNotFound, gin.H{"error": "submission not found"})
+		c.JSON(http.StatusNotFound, gin.H{"error": localize(c, "errors.submission_not_found")})
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"message": "submission " + req.Status,
+		"message": localize(c, "messages.submission_"+req.Status),
 		"id":      id,
 	})
 }
@@ -32,7 +32,7 @@ func (h *ServiceNSWSyncHandler) TriggerSync(c *gin.Context) {
 
 	mode := strings.ToLower(strings.TrimSpace(req.Mode))
 	if mode != "full" && mode != "incremental" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "mode must be one of: full, incremental"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "errors.invalid_sync_mode")})
 		return
 	}
 
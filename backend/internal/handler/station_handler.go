@@ -29,7 +29,7 @@ func (h *StationHandler) GetStations(c *gin.Context) {
 
 	stations, err := h.stationService.GetStations(lat, lon, radiusKm, fuelTypeID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch stations"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "errors.failed_to_fetch_stations")})
 		return
 	}
 
@@ -42,11 +42,11 @@ func (h *StationHandler) GetStation(c *gin.Context) {
 
 	station, err := h.stationService.GetStationByID(id)
 	if errors.Is(err, sql.ErrNoRows) {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Station not found"})
+		c.JSON(http.StatusNotFound, gin.H{"error": localize(c, "errors.station_not_found")})
 		return
 	}
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch station"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "errors.failed_to_fetch_station")})
 		return
 	}
 
@@ -71,11 +71,11 @@ func (h *StationHandler) CreateStation(c *gin.Context) {
 	}
 
 	if input.Latitude < -90 || input.Latitude > 90 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid latitude"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "errors.invalid_latitude")})
 		return
 	}
 	if input.Longitude < -180 || input.Longitude > 180 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid longitude"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "errors.invalid_longitude")})
 		return
 	}
 
@@ -89,7 +89,7 @@ func (h *StationHandler) CreateStation(c *gin.Context) {
 		Amenities:      input.Amenities,
 	})
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create station"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "errors.failed_to_create_station")})
 		return
 	}
 
@@ -124,15 +124,15 @@ func (h *StationHandler) UpdateStation(c *gin.Context) {
 		OperatingHours: input.OperatingHours,
 	})
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update station"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "errors.failed_to_update_station")})
 		return
 	}
 	if !updated {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Station not found"})
+		c.JSON(http.StatusNotFound, gin.H{"error": localize(c, "errors.station_not_found")})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Station updated successfully"})
+	c.JSON(http.StatusOK, gin.H{"message": localize(c, "messages.station_updated")})
 }
 
 // DeleteStation deletes a station
@@ -141,22 +141,22 @@ func (h *StationHandler) DeleteStation(c *gin.Context) {
 
 	deleted, err := h.stationService.DeleteStation(id)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete station"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "errors.failed_to_delete_station")})
 		return
 	}
 	if !deleted {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Station not found"})
+		c.JSON(http.StatusNotFound, gin.H{"error": localize(c, "errors.station_not_found")})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Station deleted successfully"})
+	c.JSON(http.StatusOK, gin.H{"message": localize(c, "messages.station_deleted")})
 }
 
 // SearchStations handles GET /api/stations/search
 func (h *StationHandler) SearchStations(c *gin.Context) {
 	query := c.Query("q")
 	if query == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Search query is required"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "errors.search_query_required")})
 		return
 	}
 
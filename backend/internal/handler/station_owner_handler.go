@@ -1,8 +1,10 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 	"regexp"
+	"strconv"
 	"strings"
 
 	"gaspeep/backend/internal/repository"
@@ -60,6 +62,16 @@ func validateProfileInput(businessName, contactName, email, phone string) map[st
 	return errors
 }
 
+// respondPermissionDenied writes a 403 when err means the user's team role or
+// station permissions do not allow a request, and reports whether it did.
+func respondPermissionDenied(c *gin.Context, err error) bool {
+	if !errors.Is(err, repository.ErrStationPermissionDenied) {
+		return false
+	}
+	c.JSON(http.StatusForbidden, gin.H{"error": localize(c, "errors.station_permission_denied")})
+	return true
+}
+
 // StationOwnerHandler handles station owner endpoints
 type StationOwnerHandler struct {
 	stationOwnerService service.StationOwnerService
@@ -73,7 +85,7 @@ func NewStationOwnerHandler(stationOwnerService service.StationOwnerService) *St
 func (h *StationOwnerHandler) VerifyOwnership(c *gin.Context) {
 	userID, exists := c.Get("userID")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		c.JSON(http.StatusUnauthorized, gin.H{"error": localize(c, "errors.user_not_authenticated")})
 		return
 	}
 
@@ -93,8 +105,16 @@ func (h *StationOwnerHandler) VerifyOwnership(c *gin.Context) {
 		VerificationDocuments: req.VerificationDocuments,
 		ContactInfo:           req.ContactInfo,
 	})
+	if errors.Is(err, service.ErrEmailNotVerified) {
+		c.JSON(http.StatusForbidden, gin.H{"error": localize(c, "errors.email_not_verified_for_claim")})
+		return
+	}
+	if errors.Is(err, repository.ErrAlreadyTeamMember) {
+		c.JSON(http.StatusConflict, gin.H{"error": localize(c, "errors.already_team_member")})
+		return
+	}
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to verify ownership"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "errors.failed_to_verify_ownership")})
 		return
 	}
 
@@ -105,13 +125,13 @@ func (h *StationOwnerHandler) VerifyOwnership(c *gin.Context) {
 func (h *StationOwnerHandler) GetStations(c *gin.Context) {
 	userID, exists := c.Get("userID")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		c.JSON(http.StatusUnauthorized, gin.H{"error": localize(c, "errors.user_not_authenticated")})
 		return
 	}
 
 	stations, err := h.stationOwnerService.GetStations(userID.(string))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch stations"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "errors.failed_to_fetch_stations")})
 		return
 	}
 
@@ -122,13 +142,13 @@ func (h *StationOwnerHandler) GetStations(c *gin.Context) {
 func (h *StationOwnerHandler) GetProfile(c *gin.Context) {
 	userID, exists := c.Get("userID")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		c.JSON(http.StatusUnauthorized, gin.H{"error": localize(c, "errors.user_not_authenticated")})
 		return
 	}
 
 	profile, err := h.stationOwnerService.GetProfile(userID.(string))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch profile"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "errors.failed_to_fetch_profile")})
 		return
 	}
 
@@ -139,7 +159,7 @@ func (h *StationOwnerHandler) GetProfile(c *gin.Context) {
 func (h *StationOwnerHandler) UpdateProfile(c *gin.Context) {
 	userID, exists := c.Get("userID")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		c.JSON(http.StatusUnauthorized, gin.H{"error": localize(c, "errors.user_not_authenticated")})
 		return
 	}
 
@@ -166,8 +186,11 @@ func (h *StationOwnerHandler) UpdateProfile(c *gin.Context) {
 		ContactEmail: req.Email,
 		ContactPhone: req.Phone,
 	})
+	if respondPermissionDenied(c, err) {
+		return
+	}
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update profile"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "errors.failed_to_update_profile")})
 		return
 	}
 
@@ -178,36 +201,121 @@ func (h *StationOwnerHandler) UpdateProfile(c *gin.Context) {
 func (h *StationOwnerHandler) GetStats(c *gin.Context) {
 	userID, exists := c.Get("userID")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		c.JSON(http.StatusUnauthorized, gin.H{"error": localize(c, "errors.user_not_authenticated")})
 		return
 	}
 
 	stats, err := h.stationOwnerService.GetStats(userID.(string))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch stats"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "errors.failed_to_fetch_stats")})
 		return
 	}
 
 	c.JSON(http.StatusOK, stats)
 }
 
+// GetPriceBenchmarks handles GET /api/station-owners/analytics/price-benchmarks.
+// It compares the user's prices with competitors within radiusKm over the
+// last days days, for stations where they may view analytics.
+func (h *StationOwnerHandler) GetPriceBenchmarks(c *gin.Context) {
+	req := struct {
+		RadiusKm float64 `form:"radiusKm"`
+		Days     int     `form:"days"`
+	}{RadiusKm: service.DefaultBenchmarkRadiusKm, Days: service.DefaultBenchmarkDays}
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	benchmarks, err := h.stationOwnerService.PriceBenchmarks(c.GetString("userID"), req.RadiusKm, req.Days)
+	if errors.Is(err, service.ErrInvalidBenchmarkRange) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "errors.invalid_benchmark_range",
+			"maxRadius", strconv.Itoa(service.MaxBenchmarkRadiusKm), "maxDays", strconv.Itoa(service.MaxBenchmarkDays))})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "errors.failed_to_fetch_price_benchmarks")})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"radiusKm": req.RadiusKm, "days": req.Days, "benchmarks": benchmarks})
+}
+
 // GetFuelPrices handles GET /api/station-owners/fuel-prices
 func (h *StationOwnerHandler) GetFuelPrices(c *gin.Context) {
 	userID, exists := c.Get("userID")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		c.JSON(http.StatusUnauthorized, gin.H{"error": localize(c, "errors.user_not_authenticated")})
 		return
 	}
 
 	prices, err := h.stationOwnerService.GetFuelPrices(userID.(string))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch fuel prices"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "errors.failed_to_fetch_fuel_prices")})
 		return
 	}
 
 	c.JSON(http.StatusOK, prices)
 }
 
+// PublishFuelPrices handles POST /api/station-owners/fuel-prices, publishing
+// official prices for any of the owner's approved stations at once.
+func (h *StationOwnerHandler) PublishFuelPrices(c *gin.Context) {
+	var req struct {
+		Prices []repository.OwnerPriceInput `json:"prices" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.publishPrices(c, req.Prices)
+}
+
+// PublishStationPrices handles PUT /api/station-owners/stations/:id/prices,
+// publishing official prices for one or more fuel types at a station.
+func (h *StationOwnerHandler) PublishStationPrices(c *gin.Context) {
+	var req struct {
+		Prices []struct {
+			FuelTypeID string  `json:"fuelTypeId"`
+			Price      float64 `json:"price"`
+		} `json:"prices" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	prices := make([]repository.OwnerPriceInput, len(req.Prices))
+	for i, p := range req.Prices {
+		prices[i] = repository.OwnerPriceInput{StationID: c.Param("id"), FuelTypeID: p.FuelTypeID, Price: p.Price}
+	}
+	h.publishPrices(c, prices)
+}
+
+func (h *StationOwnerHandler) publishPrices(c *gin.Context, prices []repository.OwnerPriceInput) {
+	err := h.stationOwnerService.PublishPrices(c.GetString("userID"), prices)
+	switch {
+	case errors.Is(err, service.ErrInvalidOwnerPrices):
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "errors.invalid_owner_prices", "max", strconv.Itoa(service.MaxOwnerPriceBatch))})
+		return
+	case errors.Is(err, repository.ErrUnknownFuelType):
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "errors.fuel_type_not_found")})
+		return
+	case errors.Is(err, repository.ErrStationPermissionDenied):
+		c.JSON(http.StatusForbidden, gin.H{"error": localize(c, "errors.station_permission_denied")})
+		return
+	case errors.Is(err, repository.ErrStationClaimNotApproved):
+		c.JSON(http.StatusForbidden, gin.H{"error": localize(c, "errors.station_claim_not_approved")})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "errors.failed_to_publish_fuel_prices")})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"published": len(prices), "source": repository.PriceChangeSourceOwner})
+}
+
 // SearchStations handles GET /api/station-owners/search-stations
 func (h *StationOwnerHandler) SearchStations(c *gin.Context) {
 	query := c.Query("query")
@@ -217,7 +325,7 @@ func (h *StationOwnerHandler) SearchStations(c *gin.Context) {
 
 	stations, err := h.stationOwnerService.SearchAvailableStations(query, lat, lon, radius)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to search stations"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "errors.failed_to_search_stations")})
 		return
 	}
 
@@ -250,20 +358,26 @@ func (h *StationOwnerHandler) SearchStations(c *gin.Context) {
 	c.JSON(http.StatusOK, response)
 }
 
+// ClaimEvidenceRequest is the evidence an owner gives when claiming a station
+// and again each year when re-verifying it.
+type ClaimEvidenceRequest struct {
+	VerificationMethod string   `json:"verificationMethod" binding:"required"`
+	DocumentUrls       []string `json:"documentUrls"`
+	PhoneNumber        string   `json:"phoneNumber"`
+	Email              string   `json:"email"`
+}
+
 // ClaimStation handles POST /api/station-owners/claim-station
 func (h *StationOwnerHandler) ClaimStation(c *gin.Context) {
 	userID, exists := c.Get("userID")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		c.JSON(http.StatusUnauthorized, gin.H{"error": localize(c, "errors.user_not_authenticated")})
 		return
 	}
 
 	var req struct {
-		StationID           string   `json:"stationId" binding:"required"`
-		VerificationMethod  string   `json:"verificationMethod" binding:"required"`
-		DocumentUrls        []string `json:"documentUrls"`
-		PhoneNumber         string   `json:"phoneNumber"`
-		Email               string   `json:"email"`
+		StationID string `json:"stationId" binding:"required"`
+		ClaimEvidenceRequest
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -279,58 +393,33 @@ func (h *StationOwnerHandler) ClaimStation(c *gin.Context) {
 		req.PhoneNumber,
 		req.Email,
 	)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to claim station"})
+	if errors.Is(err, service.ErrEmailNotVerified) {
+		c.JSON(http.StatusForbidden, gin.H{"error": localize(c, "errors.email_not_verified_for_claim")})
 		return
 	}
-
-	c.JSON(http.StatusCreated, result)
-}
-
-// GetStationDetails handles GET /api/station-owners/stations/:id
-func (h *StationOwnerHandler) GetStationDetails(c *gin.Context) {
-	userID, exists := c.Get("userID")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+	if respondPermissionDenied(c, err) {
 		return
 	}
-
-	stationID := c.Param("id")
-	station, err := h.stationOwnerService.GetStationDetails(userID.(string), stationID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch station details"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "errors.failed_to_claim_station")})
 		return
 	}
 
-	c.JSON(http.StatusOK, station)
+	c.JSON(http.StatusCreated, result)
 }
 
-// UpdateStation handles PUT /api/station-owners/stations/:id
-func (h *StationOwnerHandler) UpdateStation(c *gin.Context) {
+// GetStationDetails handles GET /api/station-owners/stations/:id
+func (h *StationOwnerHandler) GetStationDetails(c *gin.Context) {
 	userID, exists := c.Get("userID")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		c.JSON(http.StatusUnauthorized, gin.H{"error": localize(c, "errors.user_not_authenticated")})
 		return
 	}
 
 	stationID := c.Param("id")
-
-	var req struct {
-		Name            string      `json:"name"`
-		Phone           string      `json:"phone"`
-		Website         string      `json:"website"`
-		OperatingHours  interface{} `json:"operatingHours"`
-		Amenities       []string    `json:"amenities"`
-	}
-
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
-	}
-
-	station, err := h.stationOwnerService.UpdateStation(userID.(string), stationID, req)
+	station, err := h.stationOwnerService.GetStationDetails(userID.(string), stationID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update station"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "errors.failed_to_fetch_station_details")})
 		return
 	}
 
@@ -341,7 +430,7 @@ func (h *StationOwnerHandler) UpdateStation(c *gin.Context) {
 func (h *StationOwnerHandler) UploadPhotos(c *gin.Context) {
 	userID, exists := c.Get("userID")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		c.JSON(http.StatusUnauthorized, gin.H{"error": localize(c, "errors.user_not_authenticated")})
 		return
 	}
 
@@ -349,13 +438,13 @@ func (h *StationOwnerHandler) UploadPhotos(c *gin.Context) {
 
 	// Parse multipart form
 	if err := c.Request.ParseMultipartForm(32 << 20); err != nil { // 32MB
-		c.JSON(http.StatusBadRequest, gin.H{"error": "failed to parse form"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "errors.failed_to_parse_form")})
 		return
 	}
 
 	files := c.Request.MultipartForm.File["photos"]
 	if len(files) == 0 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "no photos provided"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "errors.no_photos_provided")})
 		return
 	}
 
@@ -369,7 +458,7 @@ func (h *StationOwnerHandler) UploadPhotos(c *gin.Context) {
 
 	result, err := h.stationOwnerService.SavePhotos(userID.(string), stationID, photoURLs)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to save photos"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "errors.failed_to_save_photos")})
 		return
 	}
 
@@ -380,35 +469,20 @@ func (h *StationOwnerHandler) UploadPhotos(c *gin.Context) {
 func (h *StationOwnerHandler) UnclaimStation(c *gin.Context) {
 	userID, exists := c.Get("userID")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		c.JSON(http.StatusUnauthorized, gin.H{"error": localize(c, "errors.user_not_authenticated")})
 		return
 	}
 
 	stationID := c.Param("id")
 
-	if err := h.stationOwnerService.UnclaimStation(userID.(string), stationID); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to unclaim station"})
+	err := h.stationOwnerService.UnclaimStation(userID.(string), stationID)
+	if respondPermissionDenied(c, err) {
 		return
 	}
-
-	c.JSON(http.StatusOK, gin.H{"message": "station unclaimed"})
-}
-
-// ReVerifyStation handles POST /api/station-owners/stations/:id/reverify
-func (h *StationOwnerHandler) ReVerifyStation(c *gin.Context) {
-	userID, exists := c.Get("userID")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
-		return
-	}
-
-	stationID := c.Param("id")
-
-	result, err := h.stationOwnerService.ReVerifyStation(userID.(string), stationID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to reverify station"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "errors.failed_to_unclaim_station")})
 		return
 	}
 
-	c.JSON(http.StatusOK, result)
+	c.JSON(http.StatusOK, gin.H{"message": localize(c, "messages.station_unclaimed")})
 }
@@ -11,10 +11,10 @@ import (
 	testhelpers "gaspeep/backend/internal/handler/testhelpers"
 	"gaspeep/backend/internal/models"
 	"gaspeep/backend/internal/repository"
+	"gaspeep/backend/internal/service"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
-	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 )
 
@@ -139,9 +139,7 @@ func TestStationOwnerHandlerSearchClaimAndStationMutations(t *testing.T) {
 	r.GET("/search-stations", h.SearchStations)
 	r.POST("/claim-station", h.ClaimStation)
 	r.GET("/stations/:id", h.GetStationDetails)
-	r.PUT("/stations/:id", h.UpdateStation)
 	r.POST("/stations/:id/unclaim", h.UnclaimStation)
-	r.POST("/stations/:id/reverify", h.ReVerifyStation)
 
 	stations := []map[string]interface{}{{
 		"id":         "s1",
@@ -181,28 +179,12 @@ func TestStationOwnerHandlerSearchClaimAndStationMutations(t *testing.T) {
 	r.ServeHTTP(w, req)
 	assert.Equal(t, http.StatusOK, w.Code)
 
-	mockService.On("UpdateStation", "user-1", "s1", mock.Anything).Return(map[string]interface{}{"id": "s1", "name": "Updated"}, nil).Once()
-	updatePayload := map[string]any{"name": "Updated", "phone": "0400", "website": "https://x.com", "operatingHours": map[string]any{"mon": "9-5"}, "amenities": []string{"carwash"}}
-	body, err = json.Marshal(updatePayload)
-	require.NoError(t, err)
-	req = httptest.NewRequest(http.MethodPut, "/stations/s1", bytes.NewReader(body))
-	req.Header.Set("Content-Type", "application/json")
-	w = httptest.NewRecorder()
-	r.ServeHTTP(w, req)
-	assert.Equal(t, http.StatusOK, w.Code)
-
 	mockService.On("UnclaimStation", "user-1", "s1").Return(nil).Once()
 	req = httptest.NewRequest(http.MethodPost, "/stations/s1/unclaim", nil)
 	w = httptest.NewRecorder()
 	r.ServeHTTP(w, req)
 	assert.Equal(t, http.StatusOK, w.Code)
 
-	mockService.On("ReVerifyStation", "user-1", "s1").Return(map[string]interface{}{"status": "pending"}, nil).Once()
-	req = httptest.NewRequest(http.MethodPost, "/stations/s1/reverify", nil)
-	w = httptest.NewRecorder()
-	r.ServeHTTP(w, req)
-	assert.Equal(t, http.StatusOK, w.Code)
-
 	mockService.AssertExpectations(t)
 }
 
@@ -236,3 +218,90 @@ func TestStationOwnerHandlerUploadPhotosAndUnauthorized(t *testing.T) {
 	unauthRouter.ServeHTTP(w, req)
 	assert.Equal(t, http.StatusUnauthorized, w.Code)
 }
+
+func TestStationOwnerHandlerPublishPrices(t *testing.T) {
+	mockService := new(testhelpers.MockStationOwnerService)
+	h := NewStationOwnerHandler(mockService)
+	r := authedStationOwnerRouter()
+	r.POST("/fuel-prices", h.PublishFuelPrices)
+	r.PUT("/stations/:id/prices", h.PublishStationPrices)
+
+	stationPrices := []repository.OwnerPriceInput{
+		{StationID: "s1", FuelTypeID: "ft-e10", Price: 1.799},
+		{StationID: "s1", FuelTypeID: "ft-u91", Price: 1.859},
+	}
+	mockService.On("PublishPrices", "user-1", stationPrices).Return(nil).Once()
+	body, err := json.Marshal(map[string]any{"prices": []map[string]any{
+		{"fuelTypeId": "ft-e10", "price": 1.799},
+		{"fuelTypeId": "ft-u91", "price": 1.859},
+	}})
+	require.NoError(t, err)
+	req := httptest.NewRequest(http.MethodPut, "/stations/s1/prices", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"published":2,"source":"owner"}`, w.Body.String())
+
+	bulk := []repository.OwnerPriceInput{{StationID: "s2", FuelTypeID: "ft-e10", Price: 1.819}}
+	mockService.On("PublishPrices", "user-1", bulk).Return(repository.ErrStationClaimNotApproved).Once()
+	w = postJSON(r, "/fuel-prices", map[string]any{"prices": bulk})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	// Staff need the publish_prices permission for the station
+	staffPrices := []repository.OwnerPriceInput{{StationID: "s3", FuelTypeID: "ft-e10", Price: 1.829}}
+	mockService.On("PublishPrices", "user-1", staffPrices).Return(repository.ErrStationPermissionDenied).Once()
+	w = postJSON(r, "/fuel-prices", map[string]any{"prices": staffPrices})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Contains(t, w.Body.String(), "role")
+
+	mockService.On("PublishPrices", "user-1", []repository.OwnerPriceInput{}).Return(service.ErrInvalidOwnerPrices).Once()
+	w = postJSON(r, "/fuel-prices", map[string]any{"prices": []any{}})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestStationOwnerHandlerGetPriceBenchmarks(t *testing.T) {
+	mockService := new(testhelpers.MockStationOwnerService)
+	h := NewStationOwnerHandler(mockService)
+	r := authedStationOwnerRouter()
+	r.GET("/analytics/price-benchmarks", h.GetPriceBenchmarks)
+
+	benchmarks := []models.PriceBenchmark{{
+		StationID:       "s1",
+		FuelTypeID:      "ft-e10",
+		PriceComparison: models.PriceComparison{Price: 1.899, Rank: 2, CompetitorCount: 3},
+	}}
+	mockService.On("PriceBenchmarks", "user-1", float64(service.DefaultBenchmarkRadiusKm), service.DefaultBenchmarkDays).Return(benchmarks, nil).Once()
+	mockService.On("PriceBenchmarks", "user-1", 2.5, 7).Return([]models.PriceBenchmark{}, nil).Once()
+	mockService.On("PriceBenchmarks", "user-1", 80.0, 7).Return(nil, service.ErrInvalidBenchmarkRange).Once()
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/analytics/price-benchmarks", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp struct {
+		RadiusKm   float64                 `json:"radiusKm"`
+		Days       int                     `json:"days"`
+		Benchmarks []models.PriceBenchmark `json:"benchmarks"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, float64(service.DefaultBenchmarkRadiusKm), resp.RadiusKm)
+	assert.Equal(t, service.DefaultBenchmarkDays, resp.Days)
+	require.Len(t, resp.Benchmarks, 1)
+	assert.Equal(t, 2, resp.Benchmarks[0].Rank)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/analytics/price-benchmarks?radiusKm=2.5&days=7", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/analytics/price-benchmarks?radiusKm=80&days=7", nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/analytics/price-benchmarks?days=week", nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	mockService.AssertExpectations(t)
+}
@@ -0,0 +1,239 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"gaspeep/backend/internal/models"
+	"gaspeep/backend/internal/repository"
+	"gaspeep/backend/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// StationProfileHandler handles owners' edits to their stations and the
+// admin review queue and version history for them
+type StationProfileHandler struct {
+	profileService service.StationProfileService
+}
+
+func NewStationProfileHandler(profileService service.StationProfileService) *StationProfileHandler {
+	return &StationProfileHandler{profileService: profileService}
+}
+
+// UpdateStationRequest holds an owner's changes to a station. Omitted fields
+// are left as they are. OperatingHours may be a string or an object of
+// opening times per day.
+type UpdateStationRequest struct {
+	Name           *string         `json:"name"`
+	Brand          *string         `json:"brand"`
+	Address        *string         `json:"address"`
+	Latitude       *float64        `json:"latitude"`
+	Longitude      *float64        `json:"longitude"`
+	OperatingHours json.RawMessage `json:"operatingHours"`
+	Amenities      []string        `json:"amenities"`
+	Phone          *string         `json:"phone"`
+	Website        *string         `json:"website"`
+}
+
+// operatingHoursText returns operating hours as they are stored: strings as
+// they are and objects as compact JSON.
+func operatingHoursText(raw json.RawMessage) (*string, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil
+	}
+	var text string
+	if raw[0] == '"' {
+		if err := json.Unmarshal(raw, &text); err != nil {
+			return nil, err
+		}
+		return &text, nil
+	}
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, raw); err != nil {
+		return nil, err
+	}
+	text = buf.String()
+	return &text, nil
+}
+
+// UpdateStation handles PUT /api/station-owners/stations/:id. Brand, address
+// and location changes wait for review; the rest apply straight away.
+func (h *StationProfileHandler) UpdateStation(c *gin.Context) {
+	var req UpdateStationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	operatingHours, err := operatingHoursText(req.OperatingHours)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "errors.invalid_station_profile")})
+		return
+	}
+
+	result, err := h.profileService.UpdateStation(c.GetString("userID"), c.Param("id"), models.StationProfileChanges{
+		Name:           req.Name,
+		Brand:          req.Brand,
+		Address:        req.Address,
+		Latitude:       req.Latitude,
+		Longitude:      req.Longitude,
+		OperatingHours: operatingHours,
+		Amenities:      req.Amenities,
+		Phone:          req.Phone,
+		Website:        req.Website,
+	})
+	switch {
+	case errors.Is(err, service.ErrInvalidStationProfile):
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "errors.invalid_station_profile")})
+		return
+	case errors.Is(err, repository.ErrStationClaimNotApproved):
+		c.JSON(http.StatusForbidden, gin.H{"error": localize(c, "errors.station_edit_not_allowed")})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "errors.failed_to_update_station")})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// GetStationChanges handles GET /api/station-owners/stations/:id/changes
+func (h *StationProfileHandler) GetStationChanges(c *gin.Context) {
+	changes, err := h.profileService.StationChanges(c.GetString("userID"), c.Param("id"))
+	if errors.Is(err, repository.ErrStationClaimNotApproved) {
+		c.JSON(http.StatusForbidden, gin.H{"error": localize(c, "errors.station_edit_not_allowed")})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "errors.failed_to_fetch_station_changes")})
+		return
+	}
+	c.JSON(http.StatusOK, changes)
+}
+
+// GetReviewQueue handles GET /api/admin/station-changes. It lists pending
+// changes unless a status is given.
+func (h *StationProfileHandler) GetReviewQueue(c *gin.Context) {
+	var req struct {
+		Status string `form:"status" binding:"omitempty,oneof=pending approved rejected superseded"`
+	}
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	changes, err := h.profileService.ReviewQueue(req.Status)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "errors.failed_to_fetch_station_changes")})
+		return
+	}
+	c.JSON(http.StatusOK, changes)
+}
+
+// reviewNotes reads the optional reviewer notes from a review request.
+func reviewNotes(c *gin.Context) (string, bool) {
+	var req struct {
+		Notes string `json:"notes"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return "", false
+	}
+	return req.Notes, true
+}
+
+// respondReviewError writes the response for a failed review.
+func respondReviewError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrStationChangeNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": localize(c, "errors.station_change_not_found")})
+	case errors.Is(err, repository.ErrChangeRequestNotPending):
+		c.JSON(http.StatusConflict, gin.H{"error": localize(c, "errors.station_change_not_pending")})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "errors.failed_to_review_station_change")})
+	}
+}
+
+// ApproveChange handles POST /api/admin/station-changes/:id/approve
+func (h *StationProfileHandler) ApproveChange(c *gin.Context) {
+	notes, ok := reviewNotes(c)
+	if !ok {
+		return
+	}
+	version, err := h.profileService.ApproveChange(c.Param("id"), c.GetString("userID"), notes)
+	if err != nil {
+		respondReviewError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, version)
+}
+
+// RejectChange handles POST /api/admin/station-changes/:id/reject
+func (h *StationProfileHandler) RejectChange(c *gin.Context) {
+	notes, ok := reviewNotes(c)
+	if !ok {
+		return
+	}
+	if err := h.profileService.RejectChange(c.Param("id"), c.GetString("userID"), notes); err != nil {
+		respondReviewError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": localize(c, "messages.station_change_rejected")})
+}
+
+// GetVersions handles GET /api/admin/stations/:id/versions
+func (h *StationProfileHandler) GetVersions(c *gin.Context) {
+	versions, err := h.profileService.Versions(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "errors.failed_to_fetch_station_versions")})
+		return
+	}
+	c.JSON(http.StatusOK, versions)
+}
+
+// GetVersionDiff handles GET /api/admin/stations/:id/versions/diff?from=&to=
+func (h *StationProfileHandler) GetVersionDiff(c *gin.Context) {
+	var req struct {
+		From int `form:"from" binding:"required,min=1"`
+		To   int `form:"to" binding:"required,min=1"`
+	}
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	diff, err := h.profileService.Diff(c.Param("id"), req.From, req.To)
+	if errors.Is(err, service.ErrStationVersionNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": localize(c, "errors.station_version_not_found")})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "errors.failed_to_fetch_station_versions")})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"from": req.From, "to": req.To, "changes": diff})
+}
+
+// RollbackVersion handles POST /api/admin/stations/:id/versions/:version/rollback
+func (h *StationProfileHandler) RollbackVersion(c *gin.Context) {
+	version, err := strconv.Atoi(c.Param("version"))
+	if err != nil || version < 1 {
+		c.JSON(http.StatusNotFound, gin.H{"error": localize(c, "errors.station_version_not_found")})
+		return
+	}
+
+	restored, err := h.profileService.Rollback(c.Param("id"), c.GetString("userID"), version)
+	if errors.Is(err, service.ErrStationVersionNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": localize(c, "errors.station_version_not_found")})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "errors.failed_to_roll_back_station")})
+		return
+	}
+	c.JSON(http.StatusOK, restored)
+}
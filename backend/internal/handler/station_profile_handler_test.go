@@ -0,0 +1,141 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gaspeep/backend/internal/handler/testhelpers"
+	"gaspeep/backend/internal/models"
+	"gaspeep/backend/internal/repository"
+	"gaspeep/backend/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newStationProfileRouter(profiles service.StationProfileService) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	h := NewStationProfileHandler(profiles)
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Set("userID", "u1")
+		c.Next()
+	})
+	r.PUT("/api/station-owners/stations/:id", h.UpdateStation)
+	r.POST("/api/admin/station-changes/:id/approve", h.ApproveChange)
+	r.POST("/api/admin/station-changes/:id/reject", h.RejectChange)
+	r.GET("/api/admin/stations/:id/versions/diff", h.GetVersionDiff)
+	r.POST("/api/admin/stations/:id/versions/:version/rollback", h.RollbackVersion)
+	return r
+}
+
+func putStation(r *gin.Engine, stationID string, payload interface{}) *httptest.ResponseRecorder {
+	body, _ := json.Marshal(payload)
+	req := httptest.NewRequest(http.MethodPut, "/api/station-owners/stations/"+stationID, bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestStationProfile_UpdateStation(t *testing.T) {
+	profiles := new(testhelpers.MockStationProfileService)
+	name, brand := "Acme Fuel George St", "Metro"
+	hours := `{"monday":{"close":"22:00","open":"06:00"}}`
+	profiles.On("UpdateStation", "u1", "s1", models.StationProfileChanges{
+		Name:           &name,
+		Brand:          &brand,
+		OperatingHours: &hours,
+		Amenities:      []string{"toilets"},
+	}).Return(&service.StationUpdateResult{
+		Profile:       models.StationProfile{Name: name, Brand: "Acme"},
+		PendingChange: &models.StationChangeRequest{ID: "change-1", Status: models.StationChangePending},
+	}, nil).Once()
+	r := newStationProfileRouter(profiles)
+
+	// Operating hours objects are stored as JSON text
+	w := putStation(r, "s1", map[string]interface{}{
+		"name":           name,
+		"brand":          brand,
+		"operatingHours": map[string]interface{}{"monday": map[string]string{"open": "06:00", "close": "22:00"}},
+		"amenities":      []string{"toilets"},
+	})
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"pendingChange":{"id":"change-1"`)
+	profiles.AssertExpectations(t)
+}
+
+func TestStationProfile_UpdateStationErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		status int
+	}{
+		{"invalid", service.ErrInvalidStationProfile, http.StatusBadRequest},
+		{"not approved", repository.ErrStationClaimNotApproved, http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			profiles := new(testhelpers.MockStationProfileService)
+			profiles.On("UpdateStation", "u1", "s1", mock.Anything).Return(nil, tt.err).Once()
+			r := newStationProfileRouter(profiles)
+
+			w := putStation(r, "s1", map[string]string{"name": ""})
+			assert.Equal(t, tt.status, w.Code)
+		})
+	}
+}
+
+func TestStationProfile_Review(t *testing.T) {
+	profiles := new(testhelpers.MockStationProfileService)
+	profiles.On("ApproveChange", "change-1", "u1", "").Return(&models.StationProfileVersion{Version: 3}, nil).Once()
+	profiles.On("ApproveChange", "change-2", "u1", "").Return(nil, repository.ErrChangeRequestNotPending).Once()
+	profiles.On("RejectChange", "change-3", "u1", "Wrong address").Return(nil).Once()
+	profiles.On("RejectChange", "change-4", "u1", "").Return(service.ErrStationChangeNotFound).Once()
+	r := newStationProfileRouter(profiles)
+
+	// Notes are optional
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/admin/station-changes/change-1/approve", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"version":3`)
+
+	w = postJSON(r, "/api/admin/station-changes/change-2/approve", map[string]string{})
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	w = postJSON(r, "/api/admin/station-changes/change-3/reject", map[string]string{"notes": "Wrong address"})
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = postJSON(r, "/api/admin/station-changes/change-4/reject", map[string]string{})
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	profiles.AssertExpectations(t)
+}
+
+func TestStationProfile_DiffAndRollback(t *testing.T) {
+	profiles := new(testhelpers.MockStationProfileService)
+	profiles.On("Diff", "s1", 1, 3).Return([]service.StationProfileDiff{{Field: "brand", From: "Acme", To: "Metro"}}, nil).Once()
+	profiles.On("Rollback", "s1", "u1", 1).Return(&models.StationProfileVersion{Version: 4, Source: models.StationVersionSourceRollback}, nil).Once()
+	profiles.On("Rollback", "s1", "u1", 9).Return(nil, service.ErrStationVersionNotFound).Once()
+	r := newStationProfileRouter(profiles)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/admin/stations/s1/versions/diff?from=1&to=3", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `{"field":"brand","from":"Acme","to":"Metro"}`)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/admin/stations/s1/versions/diff?from=1", nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = postJSON(r, "/api/admin/stations/s1/versions/1/rollback", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"source":"rollback"`)
+
+	w = postJSON(r, "/api/admin/stations/s1/versions/9/rollback", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	profiles.AssertExpectations(t)
+}
@@ -0,0 +1,113 @@
+package handler
+
+import (
+	"errors"
+	"io"
+	"log"
+	"net/http"
+
+	"gaspeep/backend/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxPaymentWebhookBytes caps the size of payment webhook bodies.
+const maxPaymentWebhookBytes = 64 << 10
+
+// SubscriptionHandler handles plans, users' subscriptions and payment
+// provider webhooks
+type SubscriptionHandler struct {
+	subscriptionService service.SubscriptionService
+}
+
+func NewSubscriptionHandler(subscriptionService service.SubscriptionService) *SubscriptionHandler {
+	return &SubscriptionHandler{subscriptionService: subscriptionService}
+}
+
+type CheckoutRequest struct {
+	PlanID string `json:"planId" binding:"required"`
+}
+
+// GetPlans handles GET /api/subscriptions/plans
+func (h *SubscriptionHandler) GetPlans(c *gin.Context) {
+	plans, err := h.subscriptionService.Plans()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "errors.failed_to_fetch_plans")})
+		return
+	}
+	c.JSON(http.StatusOK, plans)
+}
+
+// GetSubscription handles GET /api/subscriptions/me
+func (h *SubscriptionHandler) GetSubscription(c *gin.Context) {
+	status, err := h.subscriptionService.Status(c.GetString("userID"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "errors.failed_to_check_subscription")})
+		return
+	}
+	c.JSON(http.StatusOK, status)
+}
+
+// Checkout handles POST /api/subscriptions/checkout, returning the payment
+// page to send the user to. Their subscription starts when the provider's
+// webhook says it has.
+func (h *SubscriptionHandler) Checkout(c *gin.Context) {
+	var req CheckoutRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	checkout, err := h.subscriptionService.Checkout(c.GetString("userID"), req.PlanID)
+	switch {
+	case errors.Is(err, service.ErrPlanNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": localize(c, "errors.plan_not_found")})
+		return
+	case errors.Is(err, service.ErrAlreadySubscribed):
+		c.JSON(http.StatusConflict, gin.H{"error": localize(c, "errors.already_subscribed")})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "errors.failed_to_start_checkout")})
+		return
+	}
+
+	c.JSON(http.StatusCreated, checkout)
+}
+
+// Cancel handles POST /api/subscriptions/cancel
+func (h *SubscriptionHandler) Cancel(c *gin.Context) {
+	sub, err := h.subscriptionService.Cancel(c.GetString("userID"))
+	if errors.Is(err, service.ErrNoSubscription) {
+		c.JSON(http.StatusNotFound, gin.H{"error": localize(c, "errors.no_active_subscription")})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "errors.failed_to_cancel_subscription")})
+		return
+	}
+	c.JSON(http.StatusOK, sub)
+}
+
+// PaymentWebhook handles POST /api/webhooks/payments. Failures other than an
+// invalid request return 500 so the provider retries.
+func (h *SubscriptionHandler) PaymentWebhook(c *gin.Context) {
+	payload, err := io.ReadAll(io.LimitReader(c.Request.Body, maxPaymentWebhookBytes+1))
+	if err != nil || len(payload) > maxPaymentWebhookBytes {
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "errors.invalid_payment_webhook")})
+		return
+	}
+
+	err = h.subscriptionService.HandleWebhook(payload, c.Request.Header)
+	if errors.Is(err, service.ErrInvalidPaymentWebhook) {
+		log.Printf("Rejected payment webhook: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "errors.invalid_payment_webhook")})
+		return
+	}
+	if err != nil {
+		log.Printf("Failed to apply payment webhook: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "errors.failed_to_process_request")})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"received": true})
+}
@@ -0,0 +1,64 @@
+package handler
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gaspeep/backend/internal/handler/testhelpers"
+	"gaspeep/backend/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newSubscriptionRouter(subscriptions service.SubscriptionService) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	h := NewSubscriptionHandler(subscriptions)
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Set("userID", "u1")
+		c.Next()
+	})
+	r.POST("/api/subscriptions/checkout", h.Checkout)
+	r.POST("/api/webhooks/payments", h.PaymentWebhook)
+	return r
+}
+
+func TestSubscription_Checkout(t *testing.T) {
+	subscriptions := new(testhelpers.MockSubscriptionService)
+	subscriptions.On("Checkout", "u1", "premium-monthly").Return(&service.Checkout{ID: "cs_1", URL: "https://pay.example.com/cs_1"}, nil).Once()
+	subscriptions.On("Checkout", "u1", "gold").Return(nil, service.ErrPlanNotFound).Once()
+	subscriptions.On("Checkout", "u1", "premium-annual").Return(nil, service.ErrAlreadySubscribed).Once()
+	r := newSubscriptionRouter(subscriptions)
+
+	w := postJSON(r, "/api/subscriptions/checkout", map[string]string{"planId": "premium-monthly"})
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Contains(t, w.Body.String(), "https://pay.example.com/cs_1")
+
+	w = postJSON(r, "/api/subscriptions/checkout", map[string]string{"planId": "gold"})
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = postJSON(r, "/api/subscriptions/checkout", map[string]string{"planId": "premium-annual"})
+	assert.Equal(t, http.StatusConflict, w.Code)
+}
+
+func TestSubscription_PaymentWebhook(t *testing.T) {
+	subscriptions := new(testhelpers.MockSubscriptionService)
+	subscriptions.On("HandleWebhook", []byte(`{"id":"evt-1"}`), mock.Anything).Return(nil).Once()
+	subscriptions.On("HandleWebhook", []byte(`{"id":"forged"}`), mock.Anything).Return(service.ErrInvalidPaymentWebhook).Once()
+	r := newSubscriptionRouter(subscriptions)
+
+	for body, want := range map[string]int{
+		`{"id":"evt-1"}`:  http.StatusOK,
+		`{"id":"forged"}`: http.StatusBadRequest,
+	} {
+		req := httptest.NewRequest(http.MethodPost, "/api/webhooks/payments", bytes.NewBufferString(body))
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, want, w.Code, body)
+	}
+	subscriptions.AssertExpectations(t)
+}
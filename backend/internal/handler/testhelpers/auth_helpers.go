@@ -8,7 +8,7 @@ import (
 
 // CreateTestJWT generates a valid JWT token for testing
 func CreateTestJWT(userID, email string) (string, error) {
-	return auth.GenerateToken(userID, email)
+	return auth.GenerateToken(userID, email, "", false)
 }
 
 // SetAuthHeader sets the Authorization header on a request with a Bearer token
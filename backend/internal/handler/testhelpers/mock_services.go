@@ -4,6 +4,7 @@ import (
 	"gaspeep/backend/internal/models"
 	"gaspeep/backend/internal/repository"
 	"gaspeep/backend/internal/service"
+	"net/http"
 	"time"
 
 	"github.com/stretchr/testify/mock"
@@ -182,6 +183,45 @@ func (m *MockAlertService) GetMatchingStations(alertID, userID string) ([]reposi
 	return args.Get(0).([]repository.MatchingStationResult), args.Error(1)
 }
 
+func (m *MockAlertService) PreviewAlert(userID string, req service.AlertPreviewRequest) (*service.AlertPreviewResult, error) {
+	args := m.Called(userID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*service.AlertPreviewResult), args.Error(1)
+}
+
+// MockFavouriteStationService is a mock implementation of service.FavouriteStationService
+type MockFavouriteStationService struct {
+	mock.Mock
+}
+
+func (m *MockFavouriteStationService) AddFavourite(userID, stationID string) error {
+	args := m.Called(userID, stationID)
+	return args.Error(0)
+}
+
+func (m *MockFavouriteStationService) RemoveFavourite(userID, stationID string) (bool, error) {
+	args := m.Called(userID, stationID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockFavouriteStationService) GetFavourites(userID string) ([]repository.FavouriteStationResult, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]repository.FavouriteStationResult), args.Error(1)
+}
+
+func (m *MockFavouriteStationService) GetFavouritePrices(userID, fuelTypeID string) ([]repository.FavouritePriceResult, error) {
+	args := m.Called(userID, fuelTypeID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]repository.FavouritePriceResult), args.Error(1)
+}
+
 // MockNotificationService is a mock implementation of service.NotificationService
 type MockNotificationService struct {
 	mock.Mock
@@ -379,6 +419,19 @@ func (m *MockStationOwnerService) GetFuelPrices(userID string) (map[string]inter
 	return args.Get(0).(map[string]interface{}), args.Error(1)
 }
 
+func (m *MockStationOwnerService) PublishPrices(userID string, prices []repository.OwnerPriceInput) error {
+	args := m.Called(userID, prices)
+	return args.Error(0)
+}
+
+func (m *MockStationOwnerService) PriceBenchmarks(userID string, radiusKm float64, days int) ([]models.PriceBenchmark, error) {
+	args := m.Called(userID, radiusKm, days)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.PriceBenchmark), args.Error(1)
+}
+
 func (m *MockStationOwnerService) SearchAvailableStations(query, lat, lon, radius string) ([]map[string]interface{}, error) {
 	args := m.Called(query, lat, lon, radius)
 	if args.Get(0) == nil {
@@ -403,31 +456,584 @@ func (m *MockStationOwnerService) GetStationDetails(userID, stationID string) (m
 	return args.Get(0).(map[string]interface{}), args.Error(1)
 }
 
-func (m *MockStationOwnerService) UpdateStation(userID, stationID string, req interface{}) (map[string]interface{}, error) {
-	args := m.Called(userID, stationID, req)
+func (m *MockStationOwnerService) SavePhotos(userID, stationID string, photoURLs []string) ([]string, error) {
+	args := m.Called(userID, stationID, photoURLs)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
-	return args.Get(0).(map[string]interface{}), args.Error(1)
+	return args.Get(0).([]string), args.Error(1)
 }
 
-func (m *MockStationOwnerService) SavePhotos(userID, stationID string, photoURLs []string) ([]string, error) {
-	args := m.Called(userID, stationID, photoURLs)
+func (m *MockStationOwnerService) UnclaimStation(userID, stationID string) error {
+	args := m.Called(userID, stationID)
+	return args.Error(0)
+}
+
+// MockEmailService is a mock implementation of service.EmailService
+type MockEmailService struct {
+	mock.Mock
+}
+
+func (m *MockEmailService) SendPasswordReset(userID, toEmail, resetURL string) error {
+	args := m.Called(userID, toEmail, resetURL)
+	return args.Error(0)
+}
+
+func (m *MockEmailService) SendPasswordChanged(userID, toEmail string) error {
+	args := m.Called(userID, toEmail)
+	return args.Error(0)
+}
+
+func (m *MockEmailService) SendAccountLocked(userID, toEmail string, lockedFor time.Duration) error {
+	args := m.Called(userID, toEmail, lockedFor)
+	return args.Error(0)
+}
+
+func (m *MockEmailService) SendEmailVerification(userID, toEmail, verificationURL string) error {
+	args := m.Called(userID, toEmail, verificationURL)
+	return args.Error(0)
+}
+
+func (m *MockEmailService) SendMagicLink(userID, toEmail, signInURL string, expiresIn time.Duration) error {
+	args := m.Called(userID, toEmail, signInURL, expiresIn)
+	return args.Error(0)
+}
+
+func (m *MockEmailService) SendWelcome(userID, toEmail, displayName string) error {
+	args := m.Called(userID, toEmail, displayName)
+	return args.Error(0)
+}
+
+func (m *MockEmailService) SendPriceAlert(userID, alertID, toEmail, alertName, stationName, fuelType string, price float64, currency string) error {
+	args := m.Called(userID, alertID, toEmail, alertName, stationName, fuelType, price, currency)
+	return args.Error(0)
+}
+
+func (m *MockEmailService) SendAlertApproved(userID, toEmail, alertName string) error {
+	args := m.Called(userID, toEmail, alertName)
+	return args.Error(0)
+}
+
+func (m *MockEmailService) SendStationBroadcast(userID, stationID, toEmail, stationName, title, message string) error {
+	args := m.Called(userID, stationID, toEmail, stationName, title, message)
+	return args.Error(0)
+}
+
+func (m *MockEmailService) SendOwnerVerificationReminder(userID, toEmail, stationName string, expiresAt time.Time, daysLeft int) error {
+	args := m.Called(userID, toEmail, stationName, expiresAt, daysLeft)
+	return args.Error(0)
+}
+
+func (m *MockEmailService) SendOwnerVerificationLapsed(userID, toEmail, stationName string) error {
+	args := m.Called(userID, toEmail, stationName)
+	return args.Error(0)
+}
+
+func (m *MockEmailService) SendOwnerTeamInvitation(toEmail, businessName, inviterName, role, acceptURL string) error {
+	args := m.Called(toEmail, businessName, inviterName, role, acceptURL)
+	return args.Error(0)
+}
+
+func (m *MockEmailService) RecordDeliveryEvent(event repository.EmailDeliveryEvent) error {
+	args := m.Called(event)
+	return args.Error(0)
+}
+
+func (m *MockEmailService) GetEmailLog(filter repository.EmailMessageFilter, page, limit int) ([]repository.EmailMessageLog, int, error) {
+	args := m.Called(filter, page, limit)
+	if args.Get(0) == nil {
+		return nil, args.Int(1), args.Error(2)
+	}
+	return args.Get(0).([]repository.EmailMessageLog), args.Int(1), args.Error(2)
+}
+
+// MockEmailVerificationService is a mock implementation of service.EmailVerificationService
+type MockEmailVerificationService struct {
+	mock.Mock
+}
+
+func (m *MockEmailVerificationService) SendVerification(userID string) error {
+	args := m.Called(userID)
+	return args.Error(0)
+}
+
+func (m *MockEmailVerificationService) Verify(token string) error {
+	args := m.Called(token)
+	return args.Error(0)
+}
+
+// MockEmailUnsubscribeService is a mock implementation of service.EmailUnsubscribeService
+type MockEmailUnsubscribeService struct {
+	mock.Mock
+}
+
+func (m *MockEmailUnsubscribeService) URL(userID string, scope repository.UnsubscribeScope) string {
+	args := m.Called(userID, scope)
+	return args.String(0)
+}
+
+func (m *MockEmailUnsubscribeService) IsUnsubscribed(userID string, scopes ...repository.UnsubscribeScope) (bool, error) {
+	args := m.Called(userID, scopes)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockEmailUnsubscribeService) Lookup(token string) (*service.EmailUnsubscribe, error) {
+	args := m.Called(token)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*service.EmailUnsubscribe), args.Error(1)
+}
+
+func (m *MockEmailUnsubscribeService) Unsubscribe(token, method string) (*service.EmailUnsubscribe, error) {
+	args := m.Called(token, method)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*service.EmailUnsubscribe), args.Error(1)
+}
+
+// MockSessionService is a mock implementation of service.SessionService
+type MockSessionService struct {
+	mock.Mock
+}
+
+func (m *MockSessionService) Start(user *models.User, meta service.SessionMetadata) (*service.SessionTokens, error) {
+	args := m.Called(user, meta)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*service.SessionTokens), args.Error(1)
+}
+
+func (m *MockSessionService) Refresh(refreshToken string, meta service.SessionMetadata) (*service.SessionTokens, error) {
+	args := m.Called(refreshToken, meta)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*service.SessionTokens), args.Error(1)
+}
+
+func (m *MockSessionService) End(refreshToken string) error {
+	args := m.Called(refreshToken)
+	return args.Error(0)
+}
+
+func (m *MockSessionService) List(userID, currentSessionID string) ([]models.Session, error) {
+	args := m.Called(userID, currentSessionID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Session), args.Error(1)
+}
+
+func (m *MockSessionService) Revoke(userID, sessionID string) error {
+	args := m.Called(userID, sessionID)
+	return args.Error(0)
+}
+
+func (m *MockSessionService) RevokeAll(userID, exceptSessionID, reason string) error {
+	args := m.Called(userID, exceptSessionID, reason)
+	return args.Error(0)
+}
+
+// MockMFAService is a mock implementation of service.MFAService
+type MockMFAService struct {
+	mock.Mock
+}
+
+func (m *MockMFAService) Status(userID string) (*service.MFAStatus, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*service.MFAStatus), args.Error(1)
+}
+
+func (m *MockMFAService) BeginTOTP(user *models.User) (*service.TOTPEnrollment, error) {
+	args := m.Called(user)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*service.TOTPEnrollment), args.Error(1)
+}
+
+func (m *MockMFAService) ConfirmTOTP(userID, code string) ([]string, error) {
+	args := m.Called(userID, code)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).([]string), args.Error(1)
 }
 
-func (m *MockStationOwnerService) UnclaimStation(userID, stationID string) error {
-	args := m.Called(userID, stationID)
+func (m *MockMFAService) Enabled(userID string) (bool, error) {
+	args := m.Called(userID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockMFAService) Verify(userID, code string) error {
+	args := m.Called(userID, code)
+	return args.Error(0)
+}
+
+func (m *MockMFAService) RegenerateRecoveryCodes(userID, code string) ([]string, error) {
+	args := m.Called(userID, code)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockMFAService) Disable(userID, code string) error {
+	args := m.Called(userID, code)
+	return args.Error(0)
+}
+
+func (m *MockMFAService) MFARequired(userID string) (bool, error) {
+	args := m.Called(userID)
+	return args.Bool(0), args.Error(1)
+}
+
+// MockLoginThrottle is a mock implementation of service.LoginThrottle
+type MockLoginThrottle struct {
+	mock.Mock
+}
+
+func (m *MockLoginThrottle) Check(email, ip string) error {
+	args := m.Called(email, ip)
+	return args.Error(0)
+}
+
+func (m *MockLoginThrottle) RecordFailure(email, ip string) error {
+	args := m.Called(email, ip)
+	return args.Error(0)
+}
+
+func (m *MockLoginThrottle) RecordSuccess(email string) error {
+	args := m.Called(email)
+	return args.Error(0)
+}
+
+func (m *MockLoginThrottle) CheckMFA(userID string) error {
+	args := m.Called(userID)
+	return args.Error(0)
+}
+
+func (m *MockLoginThrottle) RecordMFAFailure(userID, email string) error {
+	args := m.Called(userID, email)
+	return args.Error(0)
+}
+
+func (m *MockLoginThrottle) RecordMFASuccess(userID string) error {
+	args := m.Called(userID)
+	return args.Error(0)
+}
+
+func (m *MockLoginThrottle) AllowLookup(scope string, keys ...string) error {
+	args := m.Called(scope, keys)
+	return args.Error(0)
+}
+
+// MockMagicLinkService is a mock implementation of service.MagicLinkService
+type MockMagicLinkService struct {
+	mock.Mock
+}
+
+func (m *MockMagicLinkService) Send(email, nonce string, meta service.SessionMetadata) error {
+	args := m.Called(email, nonce, meta)
+	return args.Error(0)
+}
+
+func (m *MockMagicLinkService) Redeem(token, nonce string, meta service.SessionMetadata) (*models.User, error) {
+	args := m.Called(token, nonce, meta)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.User), args.Error(1)
+}
+
+// MockSubscriptionService is a mock implementation of service.SubscriptionService
+type MockSubscriptionService struct {
+	mock.Mock
+}
+
+func (m *MockSubscriptionService) Plans() ([]models.SubscriptionPlan, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.SubscriptionPlan), args.Error(1)
+}
+
+func (m *MockSubscriptionService) Status(userID string) (*service.SubscriptionStatus, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*service.SubscriptionStatus), args.Error(1)
+}
+
+func (m *MockSubscriptionService) Checkout(userID, planID string) (*service.Checkout, error) {
+	args := m.Called(userID, planID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*service.Checkout), args.Error(1)
+}
+
+func (m *MockSubscriptionService) Cancel(userID string) (*models.Subscription, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Subscription), args.Error(1)
+}
+
+func (m *MockSubscriptionService) HandleWebhook(payload []byte, header http.Header) error {
+	args := m.Called(payload, header)
+	return args.Error(0)
+}
+
+func (m *MockSubscriptionService) HasEntitlement(userID, entitlement string) (bool, error) {
+	args := m.Called(userID, entitlement)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockSubscriptionService) ExpireLapsed() error {
+	args := m.Called()
+	return args.Error(0)
+}
+
+// MockOwnerAPIKeyService is a mock implementation of service.OwnerAPIKeyService
+type MockOwnerAPIKeyService struct {
+	mock.Mock
+}
+
+func (m *MockOwnerAPIKeyService) Create(userID string, input service.CreateAPIKeyInput) (*models.OwnerAPIKey, string, error) {
+	args := m.Called(userID, input)
+	if args.Get(0) == nil {
+		return nil, "", args.Error(2)
+	}
+	return args.Get(0).(*models.OwnerAPIKey), args.String(1), args.Error(2)
+}
+
+func (m *MockOwnerAPIKeyService) List(userID string) ([]models.OwnerAPIKey, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.OwnerAPIKey), args.Error(1)
+}
+
+func (m *MockOwnerAPIKeyService) Revoke(userID, keyID string) error {
+	args := m.Called(userID, keyID)
+	return args.Error(0)
+}
+
+func (m *MockOwnerAPIKeyService) Usage(userID, keyID string) ([]models.OwnerAPIKeyUsage, error) {
+	args := m.Called(userID, keyID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.OwnerAPIKeyUsage), args.Error(1)
+}
+
+func (m *MockOwnerAPIKeyService) Authenticate(rawKey string) (*models.OwnerAPIKey, error) {
+	args := m.Called(rawKey)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.OwnerAPIKey), args.Error(1)
+}
+
+func (m *MockOwnerAPIKeyService) RecordUsage(usage models.OwnerAPIKeyUsage) error {
+	args := m.Called(usage)
 	return args.Error(0)
 }
 
-func (m *MockStationOwnerService) ReVerifyStation(userID, stationID string) (map[string]interface{}, error) {
+func (m *MockOwnerAPIKeyService) StationPrices(key *models.OwnerAPIKey, stationID string) ([]repository.StationPriceResult, error) {
+	args := m.Called(key, stationID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]repository.StationPriceResult), args.Error(1)
+}
+
+func (m *MockOwnerAPIKeyService) PublishPrices(key *models.OwnerAPIKey, stationID, idempotencyKey string, entries []service.IntegrationPriceEntry) (*service.IntegrationPriceResult, error) {
+	args := m.Called(key, stationID, idempotencyKey, entries)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*service.IntegrationPriceResult), args.Error(1)
+}
+
+// MockStationProfileService is a mock implementation of service.StationProfileService
+type MockStationProfileService struct {
+	mock.Mock
+}
+
+func (m *MockStationProfileService) UpdateStation(userID, stationID string, changes models.StationProfileChanges) (*service.StationUpdateResult, error) {
+	args := m.Called(userID, stationID, changes)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*service.StationUpdateResult), args.Error(1)
+}
+
+func (m *MockStationProfileService) StationChanges(userID, stationID string) ([]models.StationChangeRequest, error) {
 	args := m.Called(userID, stationID)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
-	return args.Get(0).(map[string]interface{}), args.Error(1)
+	return args.Get(0).([]models.StationChangeRequest), args.Error(1)
+}
+
+func (m *MockStationProfileService) ReviewQueue(status string) ([]models.StationChangeRequest, error) {
+	args := m.Called(status)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.StationChangeRequest), args.Error(1)
+}
+
+func (m *MockStationProfileService) ApproveChange(id, reviewerID, notes string) (*models.StationProfileVersion, error) {
+	args := m.Called(id, reviewerID, notes)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.StationProfileVersion), args.Error(1)
+}
+
+func (m *MockStationProfileService) RejectChange(id, reviewerID, notes string) error {
+	args := m.Called(id, reviewerID, notes)
+	return args.Error(0)
+}
+
+func (m *MockStationProfileService) Versions(stationID string) ([]models.StationProfileVersion, error) {
+	args := m.Called(stationID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.StationProfileVersion), args.Error(1)
+}
+
+func (m *MockStationProfileService) Diff(stationID string, from, to int) ([]service.StationProfileDiff, error) {
+	args := m.Called(stationID, from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]service.StationProfileDiff), args.Error(1)
+}
+
+func (m *MockStationProfileService) Rollback(stationID, adminID string, version int) (*models.StationProfileVersion, error) {
+	args := m.Called(stationID, adminID, version)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.StationProfileVersion), args.Error(1)
+}
+
+// MockOwnerVerificationService is a mock implementation of service.OwnerVerificationService
+type MockOwnerVerificationService struct {
+	mock.Mock
+}
+
+func (m *MockOwnerVerificationService) ReVerify(userID, stationID string, evidence repository.ClaimEvidence) (*models.ClaimVerification, error) {
+	args := m.Called(userID, stationID, evidence)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ClaimVerification), args.Error(1)
+}
+
+func (m *MockOwnerVerificationService) Claims(status string) ([]models.ClaimVerification, error) {
+	args := m.Called(status)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.ClaimVerification), args.Error(1)
+}
+
+func (m *MockOwnerVerificationService) Approve(id, reviewerID string) (*models.ClaimVerification, error) {
+	args := m.Called(id, reviewerID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ClaimVerification), args.Error(1)
+}
+
+func (m *MockOwnerVerificationService) Reject(id, reviewerID, reason string) error {
+	args := m.Called(id, reviewerID, reason)
+	return args.Error(0)
+}
+
+func (m *MockOwnerVerificationService) ProcessExpiries() error {
+	args := m.Called()
+	return args.Error(0)
+}
+
+// MockOwnerTeamService is a mock implementation of service.OwnerTeamService
+type MockOwnerTeamService struct {
+	mock.Mock
+}
+
+func (m *MockOwnerTeamService) Me(userID string) (*models.OwnerTeamMember, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.OwnerTeamMember), args.Error(1)
+}
+
+func (m *MockOwnerTeamService) Team(userID string) (*service.OwnerTeam, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*service.OwnerTeam), args.Error(1)
+}
+
+func (m *MockOwnerTeamService) Invite(userID string, input service.TeamInvitationInput) (*models.OwnerTeamInvitation, error) {
+	args := m.Called(userID, input)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.OwnerTeamInvitation), args.Error(1)
+}
+
+func (m *MockOwnerTeamService) RevokeInvitation(userID, invitationID string) error {
+	args := m.Called(userID, invitationID)
+	return args.Error(0)
+}
+
+func (m *MockOwnerTeamService) UpdateMember(userID, memberID string, input service.TeamMemberInput) (*models.OwnerTeamMember, error) {
+	args := m.Called(userID, memberID, input)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.OwnerTeamMember), args.Error(1)
+}
+
+func (m *MockOwnerTeamService) RemoveMember(userID, memberID string) error {
+	args := m.Called(userID, memberID)
+	return args.Error(0)
+}
+
+func (m *MockOwnerTeamService) AcceptInvitation(userID, token string) (*models.OwnerTeamMember, error) {
+	args := m.Called(userID, token)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.OwnerTeamMember), args.Error(1)
+}
+
+// NewTestSessionTokens returns tokens as issued for a new session.
+func NewTestSessionTokens(accessToken, refreshToken string) *service.SessionTokens {
+	return &service.SessionTokens{
+		Session:               &models.Session{ID: "session-1"},
+		AccessToken:           accessToken,
+		AccessTokenExpiresAt:  time.Now().Add(15 * time.Minute),
+		RefreshToken:          refreshToken,
+		RefreshTokenExpiresAt: time.Now().Add(30 * 24 * time.Hour),
+	}
 }
@@ -1,9 +1,7 @@
 package handler
 
 import (
-	"crypto/rand"
 	"database/sql"
-	"encoding/hex"
 	"errors"
 	"log"
 	"net/http"
@@ -11,6 +9,8 @@ import (
 	"strings"
 	"time"
 
+	"gaspeep/backend/internal/auth"
+	"gaspeep/backend/internal/middleware"
 	"gaspeep/backend/internal/models"
 	"gaspeep/backend/internal/repository"
 	"gaspeep/backend/internal/service"
@@ -20,14 +20,18 @@ import (
 
 // UserProfileHandler handles user profile endpoints
 type UserProfileHandler struct {
-	userRepo repository.UserRepository
-	prRepo   repository.PasswordResetRepository
+	userRepo      repository.UserRepository
+	prRepo        repository.PasswordResetRepository
+	emailService  service.EmailService
+	loginThrottle service.LoginThrottle
 }
 
-func NewUserProfileHandler(userRepo repository.UserRepository, prRepo repository.PasswordResetRepository) *UserProfileHandler {
+func NewUserProfileHandler(userRepo repository.UserRepository, prRepo repository.PasswordResetRepository, emailService service.EmailService, loginThrottle service.LoginThrottle) *UserProfileHandler {
 	return &UserProfileHandler{
-		userRepo: userRepo,
-		prRepo:   prRepo,
+		userRepo:      userRepo,
+		prRepo:        prRepo,
+		emailService:  emailService,
+		loginThrottle: loginThrottle,
 	}
 }
 
@@ -39,13 +43,13 @@ func isUserNotFoundError(err error) bool {
 func (h *UserProfileHandler) GetProfile(c *gin.Context) {
 	userID, exists := c.Get("userID")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		c.JSON(http.StatusUnauthorized, gin.H{"error": localize(c, "errors.user_not_authenticated")})
 		return
 	}
 
 	user, err := h.userRepo.GetUserByID(userID.(string))
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		c.JSON(http.StatusNotFound, gin.H{"error": localize(c, "errors.user_not_found")})
 		return
 	}
 
@@ -54,6 +58,8 @@ func (h *UserProfileHandler) GetProfile(c *gin.Context) {
 		"email":       user.Email,
 		"displayName": user.DisplayName,
 		"tier":        user.Tier,
+		"timeZone":    user.TimeZone,
+		"locale":      user.Locale,
 		"createdAt":   user.CreatedAt,
 		"updatedAt":   user.UpdatedAt,
 	})
@@ -63,13 +69,14 @@ func (h *UserProfileHandler) GetProfile(c *gin.Context) {
 func (h *UserProfileHandler) UpdateProfile(c *gin.Context) {
 	userID, exists := c.Get("userID")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		c.JSON(http.StatusUnauthorized, gin.H{"error": localize(c, "errors.user_not_authenticated")})
 		return
 	}
 
 	var req struct {
-		DisplayName string `json:"displayName"`
-		Tier        string `json:"tier"`
+		DisplayName string  `json:"displayName"`
+		TimeZone    *string `json:"timeZone"`
+		Locale      *string `json:"locale"`
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -77,34 +84,68 @@ func (h *UserProfileHandler) UpdateProfile(c *gin.Context) {
 		return
 	}
 
-	updatedID, err := h.userRepo.UpdateProfile(userID.(string), req.DisplayName, req.Tier)
+	// The time zone decides when "once per day" alerts reset, so it must be a
+	// real IANA zone rather than an offset.
+	if req.TimeZone != nil {
+		if _, err := time.LoadLocation(*req.TimeZone); err != nil || *req.TimeZone == "" || *req.TimeZone == "Local" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "errors.invalid_timezone")})
+			return
+		}
+	}
+
+	// An empty locale clears the preference; anything else must be a
+	// supported locale, stored in its canonical form.
+	if req.Locale != nil && *req.Locale != "" {
+		locale, ok := middleware.Localizer(c).Catalog().Match(*req.Locale)
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "errors.invalid_locale")})
+			return
+		}
+		req.Locale = &locale
+	}
+
+	updatedID, err := h.userRepo.UpdateProfile(userID.(string), req.DisplayName)
 	if err == sql.ErrNoRows {
-		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		c.JSON(http.StatusNotFound, gin.H{"error": localize(c, "errors.user_not_found")})
 		return
 	}
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update profile"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "errors.failed_to_update_profile")})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"id": updatedID, "message": "profile updated"})
+	if req.TimeZone != nil {
+		if err := h.userRepo.UpdateTimeZone(userID.(string), *req.TimeZone); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "errors.failed_to_update_profile")})
+			return
+		}
+	}
+
+	if req.Locale != nil {
+		if err := h.userRepo.UpdateLocale(userID.(string), *req.Locale); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "errors.failed_to_update_profile")})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"id": updatedID, "message": localize(c, "messages.profile_updated")})
 }
 
 // GetMapFilterPreferences handles GET /api/users/preferences/map-filters
 func (h *UserProfileHandler) GetMapFilterPreferences(c *gin.Context) {
 	userID, exists := c.Get("userID")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		c.JSON(http.StatusUnauthorized, gin.H{"error": localize(c, "errors.user_not_authenticated")})
 		return
 	}
 
 	prefs, err := h.userRepo.GetMapFilterPreferences(userID.(string))
 	if err != nil {
 		if isUserNotFoundError(err) {
-			c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+			c.JSON(http.StatusNotFound, gin.H{"error": localize(c, "errors.user_not_found")})
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get map filter preferences"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "errors.failed_to_get_map_filter_preferences")})
 		return
 	}
 
@@ -125,7 +166,7 @@ func (h *UserProfileHandler) GetMapFilterPreferences(c *gin.Context) {
 func (h *UserProfileHandler) UpdateMapFilterPreferences(c *gin.Context) {
 	userID, exists := c.Get("userID")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		c.JSON(http.StatusUnauthorized, gin.H{"error": localize(c, "errors.user_not_authenticated")})
 		return
 	}
 
@@ -136,7 +177,7 @@ func (h *UserProfileHandler) UpdateMapFilterPreferences(c *gin.Context) {
 	}
 
 	if req.MaxPrice < 0 || req.MaxPrice > 400 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "maxPrice must be between 0 and 400"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "errors.invalid_max_price")})
 		return
 	}
 	if req.FuelTypes == nil {
@@ -148,14 +189,14 @@ func (h *UserProfileHandler) UpdateMapFilterPreferences(c *gin.Context) {
 
 	if err := h.userRepo.UpdateMapFilterPreferences(userID.(string), req); err != nil {
 		if isUserNotFoundError(err) {
-			c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+			c.JSON(http.StatusNotFound, gin.H{"error": localize(c, "errors.user_not_found")})
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update map filter preferences"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "errors.failed_to_update_map_filter_preferences")})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "map filter preferences updated"})
+	c.JSON(http.StatusOK, gin.H{"message": localize(c, "messages.map_filter_preferences_updated")})
 }
 
 // PasswordReset handles POST /api/auth/password-reset
@@ -169,22 +210,39 @@ func (h *UserProfileHandler) PasswordReset(c *gin.Context) {
 		return
 	}
 
+	// Limits apply whether or not the address is registered, so they do not
+	// reveal it either. Requests over the per-address limit get the usual
+	// response but send nothing, so no one can lock the owner out of resets.
+	if err := h.loginThrottle.AllowLookup("password-reset", c.ClientIP()); err != nil {
+		respondThrottled(c, err)
+		return
+	}
+	var throttled *service.ThrottledError
+	if err := h.loginThrottle.AllowLookup("password-reset-email", req.Email); errors.As(err, &throttled) {
+		log.Printf("password reset for %s not sent: too many requests for the address", req.Email)
+		c.JSON(http.StatusOK, gin.H{"message": localize(c, "messages.password_reset_requested")})
+		return
+	} else if err != nil {
+		respondThrottled(c, err)
+		return
+	}
+
 	userID, err := h.userRepo.GetUserIDByEmail(req.Email)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to process request"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "errors.failed_to_process_request")})
 		return
 	}
 
-	b := make([]byte, 32)
-	if _, err := rand.Read(b); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to generate reset token"})
+	token, selector, tokenHash, err := auth.NewSplitToken()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "errors.failed_to_generate_reset_token")})
 		return
 	}
-	token := hex.EncodeToString(b)
 	expiresAt := time.Now().Add(1 * time.Hour).UTC()
 
 	if userID != "" {
-		if err := h.prRepo.Create(userID, token, expiresAt); err != nil {
+		// Creating a token invalidates any the user was sent before
+		if err := h.prRepo.Create(userID, selector, tokenHash, expiresAt); err != nil {
 			log.Printf("warning: failed to persist password reset token: %v", err)
 		}
 
@@ -197,18 +255,12 @@ func (h *UserProfileHandler) PasswordReset(c *gin.Context) {
 			fullURL = resetPath
 		}
 
-		if err := service.SendPasswordReset(req.Email, fullURL); err != nil {
-			log.Printf("warning: failed to send password reset email to %s: %v", req.Email, err)
+		if err := h.emailService.SendPasswordReset(userID, req.Email, fullURL); err != nil {
+			log.Printf("warning: failed to queue password reset email to %s: %v", req.Email, err)
 		} else {
-			var masked string
-			if len(token) > 8 {
-				masked = token[:8] + "..."
-			} else {
-				masked = "<masked>"
-			}
-			log.Printf("password reset requested for %s; token=%s (expires %s)", req.Email, masked, expiresAt.Format(time.RFC3339))
+			log.Printf("password reset requested for %s; token=%s... (expires %s)", req.Email, selector[:8], expiresAt.Format(time.RFC3339))
 		}
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "If an account with that email exists, a password reset link has been sent."})
+	c.JSON(http.StatusOK, gin.H{"message": localize(c, "messages.password_reset_requested")})
 }
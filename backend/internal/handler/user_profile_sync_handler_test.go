@@ -6,22 +6,30 @@ import (
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
+	"gaspeep/backend/internal/auth"
+	testhelpers "gaspeep/backend/internal/handler/testhelpers"
 	"gaspeep/backend/internal/models"
+	"gaspeep/backend/internal/repository"
+	"gaspeep/backend/internal/service"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 )
 
 type mockUserRepoProfile struct {
 	getUserByIDFn                func(id string) (*models.User, error)
-	updateProfileFn              func(userID, displayName, tier string) (string, error)
+	updateProfileFn              func(userID, displayName string) (string, error)
 	getUserIDByEmail             func(email string) (string, error)
 	getMapFilterPreferencesFn    func(userID string) (*models.MapFilterPreferences, error)
 	updateMapFilterPreferencesFn func(userID string, prefs models.MapFilterPreferences) error
+	updateTimeZoneFn             func(userID, timeZone string) error
+	updateLocaleFn               func(userID, locale string) error
 }
 
 func (m *mockUserRepoProfile) CreateUser(email, passwordHash, displayName, tier string) (*models.User, error) {
@@ -54,9 +62,9 @@ func (m *mockUserRepoProfile) GetUserIDByEmail(email string) (string, error) {
 	}
 	return "", nil
 }
-func (m *mockUserRepoProfile) UpdateProfile(userID, displayName, tier string) (string, error) {
+func (m *mockUserRepoProfile) UpdateProfile(userID, displayName string) (string, error) {
 	if m.updateProfileFn != nil {
-		return m.updateProfileFn(userID, displayName, tier)
+		return m.updateProfileFn(userID, displayName)
 	}
 	return userID, nil
 }
@@ -72,27 +80,42 @@ func (m *mockUserRepoProfile) UpdateMapFilterPreferences(userID string, prefs mo
 	}
 	return nil
 }
+func (m *mockUserRepoProfile) UpdateTimeZone(userID, timeZone string) error {
+	if m.updateTimeZoneFn != nil {
+		return m.updateTimeZoneFn(userID, timeZone)
+	}
+	return nil
+}
+func (m *mockUserRepoProfile) UpdateLocale(userID, locale string) error {
+	if m.updateLocaleFn != nil {
+		return m.updateLocaleFn(userID, locale)
+	}
+	return nil
+}
 
 type mockPasswordResetRepoProfile struct {
-	createFn func(userID, token string, expiresAt time.Time) error
+	createFn func(userID, selector, tokenHash string, expiresAt time.Time) error
 }
 
-func (m *mockPasswordResetRepoProfile) Create(userID, token string, expiresAt time.Time) error {
+func (m *mockPasswordResetRepoProfile) Create(userID, selector, tokenHash string, expiresAt time.Time) error {
 	if m.createFn != nil {
-		return m.createFn(userID, token, expiresAt)
+		return m.createFn(userID, selector, tokenHash, expiresAt)
 	}
 	return nil
 }
-func (m *mockPasswordResetRepoProfile) FindByToken(token string) (string, time.Time, error) {
-	return "", time.Time{}, nil
+func (m *mockPasswordResetRepoProfile) FindBySelector(selector string) (*repository.PasswordReset, error) {
+	return nil, sql.ErrNoRows
+}
+func (m *mockPasswordResetRepoProfile) Consume(selector string) (bool, error) { return false, nil }
+func (m *mockPasswordResetRepoProfile) DeleteExpiredBefore(cutoff time.Time) (int64, error) {
+	return 0, nil
 }
-func (m *mockPasswordResetRepoProfile) DeleteByToken(token string) error { return nil }
 
 func TestUserProfileHandlerGetAndUpdateProfile(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	repo := &mockUserRepoProfile{}
 	prRepo := &mockPasswordResetRepoProfile{}
-	h := NewUserProfileHandler(repo, prRepo)
+	h := NewUserProfileHandler(repo, prRepo, new(testhelpers.MockEmailService), newAllowingLoginThrottle())
 	r := gin.New()
 	r.Use(func(c *gin.Context) {
 		c.Set("userID", "u1")
@@ -115,10 +138,10 @@ func TestUserProfileHandlerGetAndUpdateProfile(t *testing.T) {
 	r.ServeHTTP(w, req)
 	assert.Equal(t, http.StatusNotFound, w.Code)
 
-	repo.updateProfileFn = func(userID, displayName, tier string) (string, error) {
+	repo.updateProfileFn = func(userID, displayName string) (string, error) {
 		return userID, nil
 	}
-	body := []byte(`{"displayName":"New Name","tier":"premium"}`)
+	body := []byte(`{"displayName":"New Name"}`)
 	req = httptest.NewRequest(http.MethodPut, "/profile", bytes.NewReader(body))
 	req.Header.Set("Content-Type", "application/json")
 	w = httptest.NewRecorder()
@@ -131,16 +154,16 @@ func TestUserProfileHandlerGetAndUpdateProfile(t *testing.T) {
 	r.ServeHTTP(w, req)
 	assert.Equal(t, http.StatusBadRequest, w.Code)
 
-	repo.updateProfileFn = func(userID, displayName, tier string) (string, error) { return "", sql.ErrNoRows }
-	body = []byte(`{"displayName":"New Name","tier":"premium"}`)
+	repo.updateProfileFn = func(userID, displayName string) (string, error) { return "", sql.ErrNoRows }
+	body = []byte(`{"displayName":"New Name"}`)
 	req = httptest.NewRequest(http.MethodPut, "/profile", bytes.NewReader(body))
 	req.Header.Set("Content-Type", "application/json")
 	w = httptest.NewRecorder()
 	r.ServeHTTP(w, req)
 	assert.Equal(t, http.StatusNotFound, w.Code)
 
-	repo.updateProfileFn = func(userID, displayName, tier string) (string, error) { return "", errors.New("write failed") }
-	body = []byte(`{"displayName":"New Name","tier":"premium"}`)
+	repo.updateProfileFn = func(userID, displayName string) (string, error) { return "", errors.New("write failed") }
+	body = []byte(`{"displayName":"New Name"}`)
 	req = httptest.NewRequest(http.MethodPut, "/profile", bytes.NewReader(body))
 	req.Header.Set("Content-Type", "application/json")
 	w = httptest.NewRecorder()
@@ -148,11 +171,80 @@ func TestUserProfileHandlerGetAndUpdateProfile(t *testing.T) {
 	assert.Equal(t, http.StatusInternalServerError, w.Code)
 }
 
+func TestUserProfileHandlerUpdateTimeZone(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	repo := &mockUserRepoProfile{}
+	h := NewUserProfileHandler(repo, &mockPasswordResetRepoProfile{}, new(testhelpers.MockEmailService), newAllowingLoginThrottle())
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Set("userID", "u1")
+		c.Next()
+	})
+	r.PUT("/profile", h.UpdateProfile)
+
+	var savedZone string
+	repo.updateTimeZoneFn = func(userID, timeZone string) error {
+		savedZone = timeZone
+		return nil
+	}
+	req := httptest.NewRequest(http.MethodPut, "/profile", bytes.NewReader([]byte(`{"timeZone":"Australia/Perth"}`)))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "Australia/Perth", savedZone)
+
+	req = httptest.NewRequest(http.MethodPut, "/profile", bytes.NewReader([]byte(`{"timeZone":"Mars/Olympus"}`)))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestUserProfileHandlerUpdateLocale(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	repo := &mockUserRepoProfile{}
+	h := NewUserProfileHandler(repo, &mockPasswordResetRepoProfile{}, new(testhelpers.MockEmailService), newAllowingLoginThrottle())
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Set("userID", "u1")
+		c.Next()
+	})
+	r.PUT("/profile", h.UpdateProfile)
+
+	savedLocale := "unchanged"
+	repo.updateLocaleFn = func(userID, locale string) error {
+		savedLocale = locale
+		return nil
+	}
+	put := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPut, "/profile", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	// Locales are stored in their canonical form
+	w := put(`{"locale":"zh-cn"}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "zh-CN", savedLocale)
+
+	w = put(`{"locale":"fr-FR"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, "zh-CN", savedLocale)
+
+	w = put(`{"locale":""}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "", savedLocale)
+}
+
 func TestUserProfileHandlerPasswordReset(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	repo := &mockUserRepoProfile{}
 	prRepo := &mockPasswordResetRepoProfile{}
-	h := NewUserProfileHandler(repo, prRepo)
+	emailService := new(testhelpers.MockEmailService)
+	h := NewUserProfileHandler(repo, prRepo, emailService, newAllowingLoginThrottle())
 	r := gin.New()
 	r.POST("/password-reset", h.PasswordReset)
 
@@ -170,9 +262,11 @@ func TestUserProfileHandlerPasswordReset(t *testing.T) {
 	assert.Equal(t, http.StatusInternalServerError, w.Code)
 
 	calledCreate := false
+	var storedSelector, storedHash string
 	repo.getUserIDByEmail = func(email string) (string, error) { return "", nil }
-	prRepo.createFn = func(userID, token string, expiresAt time.Time) error {
+	prRepo.createFn = func(userID, selector, tokenHash string, expiresAt time.Time) error {
 		calledCreate = true
+		storedSelector, storedHash = selector, tokenHash
 		return nil
 	}
 	req = httptest.NewRequest(http.MethodPost, "/password-reset", bytes.NewReader([]byte(`{"email":"ok@example.com"}`)))
@@ -183,16 +277,74 @@ func TestUserProfileHandlerPasswordReset(t *testing.T) {
 	assert.False(t, calledCreate)
 
 	repo.getUserIDByEmail = func(email string) (string, error) { return "u1", nil }
+	emailService.On("SendPasswordReset", "u1", "ok@example.com", mock.MatchedBy(func(url string) bool {
+		// Only the token's selector and a hash of its secret are stored
+		_, token, ok := strings.Cut(url, "/auth/reset-password?token=")
+		selector, tokenHash, valid := auth.ParseSplitToken(token)
+		return ok && valid && selector == storedSelector && auth.VerifySplitToken(tokenHash, storedHash) && !strings.Contains(token, storedHash)
+	})).Return(nil).Once()
 	req = httptest.NewRequest(http.MethodPost, "/password-reset", bytes.NewReader([]byte(`{"email":"ok@example.com"}`)))
 	req.Header.Set("Content-Type", "application/json")
 	w = httptest.NewRecorder()
 	r.ServeHTTP(w, req)
 	assert.Equal(t, http.StatusOK, w.Code)
+	assert.True(t, calledCreate)
+	emailService.AssertExpectations(t)
+
+	// A failure to queue the email is logged, not reported to the caller
+	emailService.On("SendPasswordReset", "u1", "ok@example.com", mock.Anything).Return(errors.New("db")).Once()
+	req = httptest.NewRequest(http.MethodPost, "/password-reset", bytes.NewReader([]byte(`{"email":"ok@example.com"}`)))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestUserProfileHandlerPasswordResetThrottled(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	throttle := new(testhelpers.MockLoginThrottle)
+	throttle.On("AllowLookup", "password-reset", []string{"192.0.2.1"}).Return(&service.ThrottledError{RetryAfter: time.Minute}).Once()
+	h := NewUserProfileHandler(&mockUserRepoProfile{}, &mockPasswordResetRepoProfile{}, new(testhelpers.MockEmailService), throttle)
+	r := gin.New()
+	r.POST("/password-reset", h.PasswordReset)
+
+	req := httptest.NewRequest(http.MethodPost, "/password-reset", bytes.NewReader([]byte(`{"email":"ok@example.com"}`)))
+	req.Header.Set("Content-Type", "application/json")
+	req.RemoteAddr = "192.0.2.1:1234"
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "61", w.Header().Get("Retry-After"))
+	throttle.AssertExpectations(t)
+}
+
+func TestUserProfileHandlerPasswordResetEmailThrottled(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	throttle := new(testhelpers.MockLoginThrottle)
+	throttle.On("AllowLookup", "password-reset", []string{"192.0.2.1"}).Return(nil).Once()
+	throttle.On("AllowLookup", "password-reset-email", []string{"ok@example.com"}).Return(&service.ThrottledError{RetryAfter: time.Minute}).Once()
+	repo := &mockUserRepoProfile{getUserIDByEmail: func(email string) (string, error) { return "u1", nil }}
+	emailService := new(testhelpers.MockEmailService)
+	h := NewUserProfileHandler(repo, &mockPasswordResetRepoProfile{}, emailService, throttle)
+	r := gin.New()
+	r.POST("/password-reset", h.PasswordReset)
+
+	// Too many requests for an address cannot lock its owner out, so they
+	// get the usual response but send nothing
+	req := httptest.NewRequest(http.MethodPost, "/password-reset", bytes.NewReader([]byte(`{"email":"ok@example.com"}`)))
+	req.Header.Set("Content-Type", "application/json")
+	req.RemoteAddr = "192.0.2.1:1234"
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Header().Get("Retry-After"))
+	emailService.AssertNotCalled(t, "SendPasswordReset", mock.Anything, mock.Anything, mock.Anything)
+	throttle.AssertExpectations(t)
 }
 
 func TestUserProfileHandlerUnauthorized(t *testing.T) {
 	gin.SetMode(gin.TestMode)
-	h := NewUserProfileHandler(&mockUserRepoProfile{}, &mockPasswordResetRepoProfile{})
+	h := NewUserProfileHandler(&mockUserRepoProfile{}, &mockPasswordResetRepoProfile{}, new(testhelpers.MockEmailService), newAllowingLoginThrottle())
 	r := gin.New()
 	r.GET("/profile", h.GetProfile)
 	req := httptest.NewRequest(http.MethodGet, "/profile", nil)
@@ -205,7 +357,7 @@ func TestUserProfileHandlerMapFilterPreferences(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	repo := &mockUserRepoProfile{}
 	prRepo := &mockPasswordResetRepoProfile{}
-	h := NewUserProfileHandler(repo, prRepo)
+	h := NewUserProfileHandler(repo, prRepo, new(testhelpers.MockEmailService), newAllowingLoginThrottle())
 	r := gin.New()
 	r.Use(func(c *gin.Context) {
 		c.Set("userID", "u1")
@@ -0,0 +1,66 @@
+package i18n
+
+import (
+	"strconv"
+	"strings"
+)
+
+// fallbackPriceFormat is used for currencies no bundle describes.
+var fallbackPriceFormat = PriceFormat{Format: "{amount} {currency}/L", Scale: 0.01, Decimals: 3}
+
+// FormatNumber formats v with the locale's separators and the given number of
+// decimal places.
+func (l Localizer) FormatNumber(v float64, decimals int) string {
+	number := l.catalog.bundles[l.locale].Number
+	if number.Decimal == "" {
+		number = l.catalog.bundles[DefaultLocale].Number
+	}
+	if number.Decimal == "" {
+		number.Decimal = "."
+	}
+
+	s := strconv.FormatFloat(v, 'f', decimals, 64)
+	sign := ""
+	if strings.HasPrefix(s, "-") {
+		sign, s = "-", s[1:]
+	}
+	whole, frac, _ := strings.Cut(s, ".")
+
+	var b strings.Builder
+	b.WriteString(sign)
+	for i, digit := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			b.WriteString(number.Group)
+		}
+		b.WriteRune(digit)
+	}
+	if frac != "" {
+		b.WriteString(number.Decimal + frac)
+	}
+	return b.String()
+}
+
+// FormatPrice formats a price per litre, given in the currency's minor unit,
+// the way the locale shows prices in that currency: 179.9 AUD is "179.9c/L"
+// in en-AU.
+func (l Localizer) FormatPrice(price float64, currency string) string {
+	currency = strings.ToUpper(currency)
+	format := l.priceFormat(currency)
+	return replacePlaceholders(format.Format, []string{
+		"amount", l.FormatNumber(price*format.Scale, format.Decimals),
+		"currency", currency,
+	})
+}
+
+func (l Localizer) priceFormat(currency string) PriceFormat {
+	for _, locale := range []string{l.locale, DefaultLocale} {
+		prices := l.catalog.bundles[locale].Prices
+		if f, ok := prices[currency]; ok {
+			return f
+		}
+		if f, ok := prices["*"]; ok {
+			return f
+		}
+	}
+	return fallbackPriceFormat
+}
@@ -0,0 +1,242 @@
+// Package i18n holds the per-locale message bundles used for API messages and
+// emails, and negotiates which locale to use for a request.
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultLocale is used when neither the user nor the request asks for a
+// supported locale, and supplies messages missing from other bundles.
+const DefaultLocale = "en-AU"
+
+//go:embed locales/*.json
+var embeddedLocales embed.FS
+
+// Bundle is one locale's messages and formatting rules, loaded from
+// <locale>.json.
+type Bundle struct {
+	Name     string                 `json:"name"`
+	Number   NumberFormat           `json:"number"`
+	Prices   map[string]PriceFormat `json:"prices"`
+	Messages map[string]string      `json:"messages"`
+}
+
+// NumberFormat holds the decimal and digit group separators.
+type NumberFormat struct {
+	Decimal string `json:"decimal"`
+	Group   string `json:"group"`
+}
+
+// PriceFormat describes how a price per litre in a currency is shown. Prices
+// are stored in the currency's minor unit (cents), so Scale 1 shows cents and
+// Scale 0.01 shows dollars. Format may use {amount} and {currency}.
+type PriceFormat struct {
+	Format   string  `json:"format"`
+	Scale    float64 `json:"scale"`
+	Decimals int     `json:"decimals"`
+}
+
+// Catalog is the set of bundles the app can respond in.
+type Catalog struct {
+	bundles map[string]*Bundle
+	locales []string
+}
+
+var (
+	embeddedOnce    sync.Once
+	embeddedCatalog *Catalog
+)
+
+// Embedded returns the catalog built into the binary.
+func Embedded() *Catalog {
+	embeddedOnce.Do(func() {
+		sub, err := fs.Sub(embeddedLocales, "locales")
+		if err == nil {
+			embeddedCatalog, err = Load(sub)
+		}
+		if err != nil {
+			panic(fmt.Sprintf("i18n: invalid embedded locales: %v", err))
+		}
+	})
+	return embeddedCatalog
+}
+
+// LoadFromEnv loads the bundles in I18N_DIR, so translations can be changed
+// without rebuilding, or returns the embedded catalog when it is unset.
+func LoadFromEnv() (*Catalog, error) {
+	dir := os.Getenv("I18N_DIR")
+	if dir == "" {
+		return Embedded(), nil
+	}
+	return Load(os.DirFS(dir))
+}
+
+// Load reads every <locale>.json bundle at the root of fsys. The default
+// locale's bundle is required.
+func Load(fsys fs.FS) (*Catalog, error) {
+	files, err := fs.Glob(fsys, "*.json")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list locale bundles: %w", err)
+	}
+
+	c := &Catalog{bundles: make(map[string]*Bundle)}
+	for _, file := range files {
+		data, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read locale bundle %s: %w", file, err)
+		}
+		var b Bundle
+		if err := json.Unmarshal(data, &b); err != nil {
+			return nil, fmt.Errorf("failed to parse locale bundle %s: %w", file, err)
+		}
+		locale := strings.TrimSuffix(path.Base(file), ".json")
+		for currency, f := range b.Prices {
+			if f.Format == "" || f.Scale <= 0 {
+				return nil, fmt.Errorf("locale bundle %s: price format for %s needs a format and a positive scale", file, currency)
+			}
+		}
+		c.bundles[locale] = &b
+		c.locales = append(c.locales, locale)
+	}
+	if _, ok := c.bundles[DefaultLocale]; !ok {
+		return nil, fmt.Errorf("locale bundle %s.json is required", DefaultLocale)
+	}
+	sort.Strings(c.locales)
+	return c, nil
+}
+
+// Locales returns the supported locale tags, sorted.
+func (c *Catalog) Locales() []string {
+	return append([]string(nil), c.locales...)
+}
+
+// Match returns the supported locale for tag, comparing case-insensitively and
+// falling back to a locale of the same language, so "en-GB" matches "en-AU".
+func (c *Catalog) Match(tag string) (string, bool) {
+	tag = strings.ReplaceAll(strings.TrimSpace(tag), "_", "-")
+	if tag == "" {
+		return "", false
+	}
+	for _, locale := range c.locales {
+		if strings.EqualFold(locale, tag) {
+			return locale, true
+		}
+	}
+
+	lang := baseLanguage(tag)
+	if strings.EqualFold(baseLanguage(DefaultLocale), lang) {
+		return DefaultLocale, true
+	}
+	for _, locale := range c.locales {
+		if strings.EqualFold(baseLanguage(locale), lang) {
+			return locale, true
+		}
+	}
+	return "", false
+}
+
+// Negotiate picks the best supported locale for an Accept-Language header,
+// honouring q-values, and returns DefaultLocale when nothing matches.
+func (c *Catalog) Negotiate(acceptLanguage string) string {
+	type preference struct {
+		tag string
+		q   float64
+	}
+
+	var prefs []preference
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(part, ";")
+		tag = strings.TrimSpace(tag)
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		if tag == "" || q <= 0 {
+			continue
+		}
+		prefs = append(prefs, preference{tag: tag, q: q})
+	}
+	sort.SliceStable(prefs, func(i, j int) bool { return prefs[i].q > prefs[j].q })
+
+	for _, p := range prefs {
+		if p.tag == "*" {
+			return DefaultLocale
+		}
+		if locale, ok := c.Match(p.tag); ok {
+			return locale
+		}
+	}
+	return DefaultLocale
+}
+
+// Localizer returns a Localizer for locale, which must be supported; other
+// values get the default locale.
+func (c *Catalog) Localizer(locale string) Localizer {
+	if _, ok := c.bundles[locale]; !ok {
+		locale = DefaultLocale
+	}
+	return Localizer{catalog: c, locale: locale}
+}
+
+// Localizer looks up messages and formats values for one locale.
+type Localizer struct {
+	catalog *Catalog
+	locale  string
+}
+
+// Locale returns the locale tag, e.g. "en-AU".
+func (l Localizer) Locale() string {
+	return l.locale
+}
+
+// Catalog returns the catalog the localizer reads from.
+func (l Localizer) Catalog() *Catalog {
+	return l.catalog
+}
+
+// T returns the message for key with each {name} placeholder replaced, given
+// args as name, value pairs. Messages missing from the locale's bundle come
+// from the default locale, and unknown keys are returned as is.
+func (l Localizer) T(key string, args ...string) string {
+	return replacePlaceholders(l.message(key), args)
+}
+
+func (l Localizer) message(key string) string {
+	if msg, ok := l.catalog.bundles[l.locale].Messages[key]; ok {
+		return msg
+	}
+	if msg, ok := l.catalog.bundles[DefaultLocale].Messages[key]; ok {
+		return msg
+	}
+	return key
+}
+
+func replacePlaceholders(msg string, args []string) string {
+	if len(args) == 0 {
+		return msg
+	}
+	pairs := make([]string, 0, len(args))
+	for i := 0; i+1 < len(args); i += 2 {
+		pairs = append(pairs, "{"+args[i]+"}", args[i+1])
+	}
+	return strings.NewReplacer(pairs...).Replace(msg)
+}
+
+func baseLanguage(tag string) string {
+	lang, _, _ := strings.Cut(tag, "-")
+	return lang
+}
@@ -0,0 +1,101 @@
+package i18n
+
+import (
+	"sort"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNegotiate(t *testing.T) {
+	c := Embedded()
+
+	tests := []struct {
+		header string
+		want   string
+	}{
+		{"", DefaultLocale},
+		{"zh-CN,zh;q=0.9,en;q=0.8", "zh-CN"},
+		{"en-us", "en-US"},
+		{"en-GB,en;q=0.9", "en-AU"},
+		{"zh-TW", "zh-CN"},
+		{"fr-FR,fr;q=0.9", DefaultLocale},
+		{"fr;q=0.9,zh;q=0.5", "zh-CN"},
+		{"en-US;q=0.5,zh-CN;q=0.8", "zh-CN"},
+		{"zh-CN;q=0,en-US", "en-US"},
+		{"*", DefaultLocale},
+		{"de,en_US;q=0.7", "en-US"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, c.Negotiate(tt.header), "Accept-Language %q", tt.header)
+	}
+}
+
+func TestLocalizerT(t *testing.T) {
+	c := Embedded()
+
+	assert.Equal(t, "station not found", c.Localizer("en-AU").T("errors.station_not_found"))
+	assert.Equal(t, "未找到加油站", c.Localizer("zh-CN").T("errors.station_not_found"))
+	// en-US only overrides some messages
+	assert.Equal(t, "favorite station not found", c.Localizer("en-US").T("errors.favourite_station_not_found"))
+	assert.Equal(t, "station not found", c.Localizer("en-US").T("errors.station_not_found"))
+	assert.Equal(t, "Hi Sam,", c.Localizer("en-AU").T("email.welcome.greeting", "name", "Sam"))
+	assert.Equal(t, "no.such.key", c.Localizer("en-AU").T("no.such.key"))
+	assert.Equal(t, DefaultLocale, c.Localizer("xx").Locale())
+}
+
+func TestFormatPrice(t *testing.T) {
+	c := Embedded()
+
+	assert.Equal(t, "179.9c/L", c.Localizer("en-AU").FormatPrice(179.9, "AUD"))
+	assert.Equal(t, "179.9c/L", c.Localizer("en-AU").FormatPrice(179.94, "aud"))
+	assert.Equal(t, "NZD 2.459/L", c.Localizer("en-AU").FormatPrice(245.9, "NZD"))
+	assert.Equal(t, "A$1.799/L", c.Localizer("en-US").FormatPrice(179.9, "AUD"))
+	assert.Equal(t, "$3.459/L", c.Localizer("en-US").FormatPrice(345.9, "USD"))
+	assert.Equal(t, "179.9 澳分/升", c.Localizer("zh-CN").FormatPrice(179.9, "AUD"))
+	assert.Equal(t, "¥8.12/升", c.Localizer("zh-CN").FormatPrice(812, "CNY"))
+}
+
+func TestFormatNumber(t *testing.T) {
+	catalog, err := Load(fstest.MapFS{
+		"en-AU.json": {Data: []byte(`{"number":{"decimal":".","group":","}}`)},
+		"de-DE.json": {Data: []byte(`{"number":{"decimal":",","group":"."}}`)},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "1,234,567.89", catalog.Localizer("en-AU").FormatNumber(1234567.891, 2))
+	assert.Equal(t, "1.234.567,89", catalog.Localizer("de-DE").FormatNumber(1234567.891, 2))
+	assert.Equal(t, "-999", catalog.Localizer("de-DE").FormatNumber(-999, 0))
+	assert.Equal(t, "-1.000", catalog.Localizer("de-DE").FormatNumber(-1000, 0))
+}
+
+func TestLoad_Validates(t *testing.T) {
+	_, err := Load(fstest.MapFS{"zh-CN.json": {Data: []byte(`{}`)}})
+	assert.ErrorContains(t, err, "en-AU.json is required")
+
+	_, err = Load(fstest.MapFS{"en-AU.json": {Data: []byte(`{"messages":`)}})
+	assert.ErrorContains(t, err, "failed to parse locale bundle en-AU.json")
+
+	_, err = Load(fstest.MapFS{"en-AU.json": {Data: []byte(`{"prices":{"AUD":{"format":"{amount}c/L"}}}`)}})
+	assert.ErrorContains(t, err, "positive scale")
+}
+
+// Every message in another bundle must translate one in the default bundle,
+// which catches misspelt keys.
+func TestEmbeddedBundlesMatchDefault(t *testing.T) {
+	c := Embedded()
+	defaults := c.bundles[DefaultLocale].Messages
+
+	for _, locale := range c.Locales() {
+		var unknown []string
+		for key := range c.bundles[locale].Messages {
+			if _, ok := defaults[key]; !ok {
+				unknown = append(unknown, key)
+			}
+		}
+		sort.Strings(unknown)
+		assert.Empty(t, unknown, "unknown keys in %s.json", locale)
+	}
+}
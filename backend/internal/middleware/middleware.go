@@ -1,13 +1,20 @@
 package middleware
 
 import (
+	"crypto/subtle"
 	"encoding/base64"
 	"log"
 	"net/http"
 	"os"
+	"slices"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"gaspeep/backend/internal/auth"
+	"gaspeep/backend/internal/i18n"
+	"gaspeep/backend/internal/models"
 
 	"github.com/gin-gonic/gin"
 )
@@ -114,7 +121,7 @@ func AuthMiddleware() gin.HandlerFunc {
 		}
 
 		if tokenString == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "missing authorization token"})
+			c.JSON(http.StatusUnauthorized, gin.H{"error": Localizer(c).T("errors.missing_authorization_token")})
 			c.Abort()
 			return
 		}
@@ -122,7 +129,7 @@ func AuthMiddleware() gin.HandlerFunc {
 		// Validate JWT token
 		claims, err := auth.ValidateToken(tokenString)
 		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid token"})
+			c.JSON(http.StatusUnauthorized, gin.H{"error": Localizer(c).T("errors.invalid_token")})
 			c.Abort()
 			return
 		}
@@ -130,17 +137,158 @@ func AuthMiddleware() gin.HandlerFunc {
 		// Set user ID in context for downstream handlers
 		c.Set("userID", claims.UserID)
 		c.Set("email", claims.Email)
+		c.Set("sessionID", claims.SessionID)
+		c.Set("mfa", claims.MFA)
 
 		c.Next()
 	}
 }
 
+// MFAPolicy decides which users must sign in with a second factor.
+type MFAPolicy interface {
+	MFARequired(userID string) (bool, error)
+}
+
+// RequireMFA rejects requests from users whom policy requires to use
+// two-factor authentication unless their session was started with it. It
+// must run after AuthMiddleware.
+func RequireMFA(policy MFAPolicy) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetBool("mfa") {
+			c.Next()
+			return
+		}
+
+		required, err := policy.MFARequired(c.GetString("userID"))
+		if err != nil {
+			log.Printf("failed to check MFA policy: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": Localizer(c).T("errors.failed_to_check_mfa")})
+			c.Abort()
+			return
+		}
+		if required {
+			c.JSON(http.StatusForbidden, gin.H{"error": Localizer(c).T("errors.mfa_required"), "mfaRequired": true})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// EntitlementChecker decides whether a user's subscription includes a
+// feature.
+type EntitlementChecker interface {
+	HasEntitlement(userID, entitlement string) (bool, error)
+}
+
+// RequireEntitlement rejects requests from users whose subscription does not
+// include entitlement. It must run after AuthMiddleware.
+func RequireEntitlement(checker EntitlementChecker, entitlement string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		entitled, err := checker.HasEntitlement(c.GetString("userID"), entitlement)
+		if err != nil {
+			log.Printf("failed to check entitlement %s: %v", entitlement, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": Localizer(c).T("errors.failed_to_check_subscription")})
+			c.Abort()
+			return
+		}
+		if !entitled {
+			c.JSON(http.StatusForbidden, gin.H{"error": Localizer(c).T("errors.subscription_required"), "entitlement": entitlement})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// RoleLookup returns a user's staff and owner roles.
+type RoleLookup interface {
+	GetUserRoles(userID string) ([]string, error)
+}
+
+// RequireAdmin rejects requests from users without the admin role. It must
+// run after AuthMiddleware.
+func RequireAdmin(roles RoleLookup) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userRoles, err := roles.GetUserRoles(c.GetString("userID"))
+		if err != nil {
+			log.Printf("failed to check admin role: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": Localizer(c).T("errors.failed_to_check_role")})
+			c.Abort()
+			return
+		}
+		if !slices.Contains(userRoles, "admin") {
+			c.JSON(http.StatusForbidden, gin.H{"error": Localizer(c).T("errors.admin_required")})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// APIKeyAuthenticator checks station owners' API keys and logs the requests
+// made with them.
+type APIKeyAuthenticator interface {
+	// Authenticate returns the key rawKey identifies, or nil if it is unknown
+	// or revoked.
+	Authenticate(rawKey string) (*models.OwnerAPIKey, error)
+	RecordUsage(usage models.OwnerAPIKeyUsage) error
+}
+
+// APIKeyAuthMiddleware authenticates integration requests by the API key in
+// their bearer token, making it available as "apiKey", and logs each request
+// made with a valid key once it has been handled.
+func APIKeyAuthMiddleware(authenticator APIKeyAuthenticator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		rawKey, ok := strings.CutPrefix(strings.TrimSpace(c.GetHeader("Authorization")), "Bearer ")
+		rawKey = strings.TrimSpace(rawKey)
+		if !ok || rawKey == "" {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": Localizer(c).T("errors.missing_api_key")})
+			c.Abort()
+			return
+		}
+
+		key, err := authenticator.Authenticate(rawKey)
+		if err != nil {
+			log.Printf("failed to check API key: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": Localizer(c).T("errors.failed_to_process_request")})
+			c.Abort()
+			return
+		}
+		if key == nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": Localizer(c).T("errors.invalid_api_key")})
+			c.Abort()
+			return
+		}
+
+		c.Set("apiKey", key)
+		c.Next()
+
+		err = authenticator.RecordUsage(models.OwnerAPIKeyUsage{
+			APIKeyID:   key.ID,
+			Method:     c.Request.Method,
+			Path:       c.Request.URL.Path,
+			StationID:  c.Param("id"),
+			StatusCode: c.Writer.Status(),
+			IPAddress:  c.ClientIP(),
+		})
+		if err != nil {
+			log.Printf("failed to record usage of API key %s: %v", key.ID, err)
+		}
+	}
+}
+
+// ServiceNSWSyncAuthMiddleware checks the Service NSW API key and secret sent
+// to trigger a sync.
 func ServiceNSWSyncAuthMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		apiKey := strings.TrimSpace(os.Getenv("SERVICE_NSW_API_KEY"))
 		apiSecret := strings.TrimSpace(os.Getenv("SERVICE_NSW_API_SECRET"))
 		if apiKey == "" || apiSecret == "" {
-			c.JSON(http.StatusFailedDependency, gin.H{"error": "service NSW credentials are not configured"})
+			c.JSON(http.StatusFailedDependency, gin.H{"error": Localizer(c).T("errors.service_nsw_not_configured")})
 			c.Abort()
 			return
 		}
@@ -148,7 +296,7 @@ func ServiceNSWSyncAuthMiddleware() gin.HandlerFunc {
 		expected := base64.StdEncoding.EncodeToString([]byte(apiKey + ":" + apiSecret))
 		authHeader := strings.TrimSpace(c.GetHeader("Authorization"))
 		if authHeader == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "missing authorization token"})
+			c.JSON(http.StatusUnauthorized, gin.H{"error": Localizer(c).T("errors.missing_authorization_token")})
 			c.Abort()
 			return
 		}
@@ -156,14 +304,85 @@ func ServiceNSWSyncAuthMiddleware() gin.HandlerFunc {
 		valid := false
 		if strings.HasPrefix(authHeader, "Bearer ") {
 			token := strings.TrimSpace(strings.TrimPrefix(authHeader, "Bearer "))
-			valid = token == expected
+			valid = subtle.ConstantTimeCompare([]byte(token), []byte(expected)) == 1
 		} else if strings.HasPrefix(authHeader, "Basic ") {
 			token := strings.TrimSpace(strings.TrimPrefix(authHeader, "Basic "))
-			valid = token == expected
+			valid = subtle.ConstantTimeCompare([]byte(token), []byte(expected)) == 1
 		}
 
 		if !valid {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid service NSW sync authorization token"})
+			c.JSON(http.StatusUnauthorized, gin.H{"error": Localizer(c).T("errors.invalid_service_nsw_token")})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// EmailWebhookAuthMiddleware checks the bearer token the email provider sends
+// with delivery webhooks against EMAIL_WEBHOOK_SECRET.
+func EmailWebhookAuthMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		secret := strings.TrimSpace(os.Getenv("EMAIL_WEBHOOK_SECRET"))
+		if secret == "" {
+			c.JSON(http.StatusFailedDependency, gin.H{"error": Localizer(c).T("errors.email_webhook_not_configured")})
+			c.Abort()
+			return
+		}
+
+		authHeader := strings.TrimSpace(c.GetHeader("Authorization"))
+		token := strings.TrimSpace(strings.TrimPrefix(authHeader, "Bearer "))
+		if !strings.HasPrefix(authHeader, "Bearer ") || subtle.ConstantTimeCompare([]byte(token), []byte(secret)) != 1 {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": Localizer(c).T("errors.invalid_email_webhook_token")})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// rateLimitSweepSize is the number of tracked clients above which expired
+// windows are dropped.
+const rateLimitSweepSize = 1024
+
+// RateLimitMiddleware allows each client IP at most limit requests per window.
+// Counts are kept in memory, so each API instance limits separately.
+func RateLimitMiddleware(limit int, window time.Duration) gin.HandlerFunc {
+	type clientWindow struct {
+		count   int
+		resetAt time.Time
+	}
+
+	var mu sync.Mutex
+	clients := make(map[string]*clientWindow)
+
+	return func(c *gin.Context) {
+		now := time.Now()
+		key := c.ClientIP()
+
+		mu.Lock()
+		w, ok := clients[key]
+		if !ok || !now.Before(w.resetAt) {
+			if len(clients) >= rateLimitSweepSize {
+				for k, cw := range clients {
+					if !now.Before(cw.resetAt) {
+						delete(clients, k)
+					}
+				}
+			}
+			w = &clientWindow{resetAt: now.Add(window)}
+			clients[key] = w
+		}
+		w.count++
+		count, resetAt := w.count, w.resetAt
+		mu.Unlock()
+
+		if count > limit {
+			retryAfter := int(resetAt.Sub(now).Seconds()) + 1
+			c.Header("Retry-After", strconv.Itoa(retryAfter))
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": Localizer(c).T("errors.too_many_requests")})
 			c.Abort()
 			return
 		}
@@ -171,3 +390,58 @@ func ServiceNSWSyncAuthMiddleware() gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+const (
+	localeConfigKey = "localeConfig"
+	localizerKey    = "localizer"
+)
+
+// UserLocaleLookup loads a signed-in user so their preferred locale can be used.
+type UserLocaleLookup interface {
+	GetUserByID(id string) (*models.User, error)
+}
+
+type localeConfig struct {
+	catalog *i18n.Catalog
+	users   UserLocaleLookup
+}
+
+// LocaleMiddleware makes catalog available to Localizer. The locale itself is
+// resolved on first use, after AuthMiddleware has identified the user.
+func LocaleMiddleware(catalog *i18n.Catalog, users UserLocaleLookup) gin.HandlerFunc {
+	cfg := localeConfig{catalog: catalog, users: users}
+	return func(c *gin.Context) {
+		c.Set(localeConfigKey, cfg)
+		c.Writer.Header().Add("Vary", "Accept-Language")
+		c.Next()
+	}
+}
+
+// Localizer returns the localizer for the request: the signed-in user's
+// preferred locale if they have chosen one, otherwise the best match for the
+// Accept-Language header. Without LocaleMiddleware the embedded catalog is used.
+func Localizer(c *gin.Context) i18n.Localizer {
+	if l, ok := c.Get(localizerKey); ok {
+		return l.(i18n.Localizer)
+	}
+
+	cfg := localeConfig{catalog: i18n.Embedded()}
+	if v, ok := c.Get(localeConfigKey); ok {
+		cfg = v.(localeConfig)
+	}
+
+	locale := ""
+	if userID := c.GetString("userID"); userID != "" && cfg.users != nil {
+		if user, err := cfg.users.GetUserByID(userID); err == nil {
+			locale, _ = cfg.catalog.Match(user.Locale)
+		}
+	}
+	if locale == "" {
+		locale = cfg.catalog.Negotiate(c.GetHeader("Accept-Language"))
+	}
+
+	l := cfg.catalog.Localizer(locale)
+	c.Set(localizerKey, l)
+	c.Header("Content-Language", l.Locale())
+	return l
+}
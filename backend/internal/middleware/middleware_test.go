@@ -7,8 +7,11 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"gaspeep/backend/internal/auth"
+	"gaspeep/backend/internal/i18n"
+	"gaspeep/backend/internal/models"
 
 	"github.com/gin-gonic/gin"
 )
@@ -226,7 +229,7 @@ func TestAuthMiddleware_InvalidToken(t *testing.T) {
 func TestAuthMiddleware_ValidBearerTokenSetsContext(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
-	token, err := auth.GenerateToken("user-123", "user@example.com")
+	token, err := auth.GenerateToken("user-123", "user@example.com", "session-123", false)
 	if err != nil {
 		t.Fatalf("failed to generate token: %v", err)
 	}
@@ -236,7 +239,8 @@ func TestAuthMiddleware_ValidBearerTokenSetsContext(t *testing.T) {
 	r.GET("/", func(c *gin.Context) {
 		userID, _ := c.Get("userID")
 		email, _ := c.Get("email")
-		c.JSON(http.StatusOK, gin.H{"userID": userID, "email": email})
+		sessionID, _ := c.Get("sessionID")
+		c.JSON(http.StatusOK, gin.H{"userID": userID, "email": email, "sessionID": sessionID})
 	})
 
 	req := httptest.NewRequest(http.MethodGet, "/", nil)
@@ -258,12 +262,15 @@ func TestAuthMiddleware_ValidBearerTokenSetsContext(t *testing.T) {
 	if body["email"] != "user@example.com" {
 		t.Fatalf("expected email user@example.com, got %q", body["email"])
 	}
+	if body["sessionID"] != "session-123" {
+		t.Fatalf("expected sessionID session-123, got %q", body["sessionID"])
+	}
 }
 
 func TestAuthMiddleware_UsesCookieTokenFallback(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
-	token, err := auth.GenerateToken("cookie-user", "cookie@example.com")
+	token, err := auth.GenerateToken("cookie-user", "cookie@example.com", "", false)
 	if err != nil {
 		t.Fatalf("failed to generate token: %v", err)
 	}
@@ -378,3 +385,335 @@ func TestServiceNSWSyncAuthMiddleware_AcceptsBearerAndBasic(t *testing.T) {
 		t.Fatalf("expected 200 for basic token, got %d", basicW.Code)
 	}
 }
+
+func TestEmailWebhookAuthMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	t.Setenv("EMAIL_WEBHOOK_SECRET", "hook-secret")
+
+	r := gin.New()
+	r.Use(EmailWebhookAuthMiddleware())
+	r.POST("/", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	cases := map[string]int{
+		"":                   http.StatusUnauthorized,
+		"Bearer wrong":       http.StatusUnauthorized,
+		"Basic hook-secret":  http.StatusUnauthorized,
+		"Bearer hook-secret": http.StatusOK,
+	}
+	for header, want := range cases {
+		req := httptest.NewRequest(http.MethodPost, "/", nil)
+		if header != "" {
+			req.Header.Set("Authorization", header)
+		}
+		w := httptest.NewRecorder()
+
+		r.ServeHTTP(w, req)
+
+		if w.Code != want {
+			t.Fatalf("Authorization %q: expected %d, got %d", header, want, w.Code)
+		}
+	}
+}
+
+func TestRateLimitMiddleware_LimitsPerClientIP(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	r := gin.New()
+	r.Use(RateLimitMiddleware(2, time.Minute))
+	r.POST("/", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	send := func(remoteAddr string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/", nil)
+		req.RemoteAddr = remoteAddr
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	for i := 0; i < 2; i++ {
+		if w := send("10.0.0.1:1234"); w.Code != http.StatusOK {
+			t.Fatalf("request %d: expected 200, got %d", i+1, w.Code)
+		}
+	}
+
+	w := send("10.0.0.1:1234")
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected 429 once the limit is reached, got %d", w.Code)
+	}
+	if w.Header().Get("Retry-After") == "" {
+		t.Fatalf("expected Retry-After header on 429")
+	}
+
+	if w := send("10.0.0.2:1234"); w.Code != http.StatusOK {
+		t.Fatalf("expected other clients to be unaffected, got %d", w.Code)
+	}
+}
+
+type stubUserLocales map[string]string
+
+func (s stubUserLocales) GetUserByID(id string) (*models.User, error) {
+	locale, ok := s[id]
+	if !ok {
+		return nil, errors.New("user not found")
+	}
+	return &models.User{ID: id, Locale: locale}, nil
+}
+
+func TestLocalizer_PrefersUserLocaleOverAcceptLanguage(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	r := gin.New()
+	r.Use(LocaleMiddleware(i18n.Embedded(), stubUserLocales{"chosen": "zh-CN", "unset": ""}))
+	r.GET("/", func(c *gin.Context) {
+		if userID := c.Query("user"); userID != "" {
+			c.Set("userID", userID)
+		}
+		c.String(http.StatusOK, Localizer(c).T("errors.user_not_found"))
+	})
+
+	cases := []struct {
+		user, acceptLanguage, want string
+	}{
+		{"", "", "user not found"},
+		{"", "zh-CN,zh;q=0.9", "未找到用户"},
+		{"chosen", "en-US", "未找到用户"},
+		{"unset", "zh", "未找到用户"},
+		{"unset", "", "user not found"},
+		{"missing", "en", "user not found"},
+	}
+	for _, tc := range cases {
+		req := httptest.NewRequest(http.MethodGet, "/?user="+tc.user, nil)
+		if tc.acceptLanguage != "" {
+			req.Header.Set("Accept-Language", tc.acceptLanguage)
+		}
+		w := httptest.NewRecorder()
+
+		r.ServeHTTP(w, req)
+
+		if w.Body.String() != tc.want {
+			t.Fatalf("user %q, Accept-Language %q: expected %q, got %q", tc.user, tc.acceptLanguage, tc.want, w.Body.String())
+		}
+	}
+}
+
+func TestAuthMiddleware_LocalizesErrors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	r := gin.New()
+	r.GET("/", AuthMiddleware(), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept-Language", "zh-CN")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	var body map[string]string
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if body["error"] != "缺少授权令牌" {
+		t.Fatalf("expected localized error, got %q", body["error"])
+	}
+	if got := w.Header().Get("Content-Language"); got != "zh-CN" {
+		t.Fatalf("expected Content-Language zh-CN, got %q", got)
+	}
+}
+
+type stubMFAPolicy map[string]bool
+
+func (p stubMFAPolicy) MFARequired(userID string) (bool, error) {
+	return p[userID], nil
+}
+
+func TestRequireMFA(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	r := gin.New()
+	r.Use(AuthMiddleware(), RequireMFA(stubMFAPolicy{"owner-1": true}))
+	r.GET("/", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	cases := []struct {
+		name   string
+		userID string
+		mfa    bool
+		want   int
+	}{
+		{"not required", "user-1", false, http.StatusOK},
+		{"required without second factor", "owner-1", false, http.StatusForbidden},
+		{"required with second factor", "owner-1", true, http.StatusOK},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			token, err := auth.GenerateToken(tc.userID, "user@example.com", "session-1", tc.mfa)
+			if err != nil {
+				t.Fatalf("failed to generate token: %v", err)
+			}
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			w := httptest.NewRecorder()
+
+			r.ServeHTTP(w, req)
+
+			if w.Code != tc.want {
+				t.Fatalf("expected %d, got %d: %s", tc.want, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+type stubEntitlements map[string]string
+
+func (e stubEntitlements) HasEntitlement(userID, entitlement string) (bool, error) {
+	if userID == "broken" {
+		return false, errors.New("database down")
+	}
+	return e[userID] == entitlement, nil
+}
+
+func TestRequireEntitlement(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Set("userID", c.GetHeader("X-User"))
+		c.Next()
+	}, RequireEntitlement(stubEntitlements{"premium-1": "price_alerts"}, "price_alerts"))
+	r.GET("/", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	cases := []struct {
+		userID string
+		want   int
+	}{
+		{"premium-1", http.StatusOK},
+		{"free-1", http.StatusForbidden},
+		{"broken", http.StatusInternalServerError},
+	}
+	for _, tc := range cases {
+		t.Run(tc.userID, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set("X-User", tc.userID)
+			w := httptest.NewRecorder()
+
+			r.ServeHTTP(w, req)
+
+			if w.Code != tc.want {
+				t.Fatalf("expected %d, got %d: %s", tc.want, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+type stubRoles map[string][]string
+
+func (r stubRoles) GetUserRoles(userID string) ([]string, error) {
+	if userID == "broken" {
+		return nil, errors.New("database down")
+	}
+	return r[userID], nil
+}
+
+func TestRequireAdmin(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Set("userID", c.GetHeader("X-User"))
+		c.Next()
+	}, RequireAdmin(stubRoles{"admin-1": {"admin"}, "moderator-1": {"moderator", "owner"}}))
+	r.GET("/", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	cases := []struct {
+		userID string
+		want   int
+	}{
+		{"admin-1", http.StatusOK},
+		{"moderator-1", http.StatusForbidden},
+		{"user-1", http.StatusForbidden},
+		{"broken", http.StatusInternalServerError},
+	}
+	for _, tc := range cases {
+		t.Run(tc.userID, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set("X-User", tc.userID)
+			w := httptest.NewRecorder()
+
+			r.ServeHTTP(w, req)
+
+			if w.Code != tc.want {
+				t.Fatalf("expected %d, got %d: %s", tc.want, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+type stubAPIKeys struct {
+	usage []models.OwnerAPIKeyUsage
+}
+
+func (s *stubAPIKeys) Authenticate(rawKey string) (*models.OwnerAPIKey, error) {
+	switch rawKey {
+	case "gpk_valid":
+		return &models.OwnerAPIKey{ID: "key-1"}, nil
+	case "gpk_broken":
+		return nil, errors.New("database down")
+	}
+	return nil, nil
+}
+
+func (s *stubAPIKeys) RecordUsage(usage models.OwnerAPIKeyUsage) error {
+	s.usage = append(s.usage, usage)
+	return nil
+}
+
+func TestAPIKeyAuthMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	keys := &stubAPIKeys{}
+	r := gin.New()
+	r.Use(APIKeyAuthMiddleware(keys))
+	r.PUT("/stations/:id/prices", func(c *gin.Context) {
+		key, _ := c.Get("apiKey")
+		c.JSON(http.StatusAccepted, gin.H{"key": key.(*models.OwnerAPIKey).ID})
+	})
+
+	cases := []struct {
+		name   string
+		header string
+		want   int
+	}{
+		{"valid", "Bearer gpk_valid", http.StatusAccepted},
+		{"missing", "", http.StatusUnauthorized},
+		{"unknown", "Bearer gpk_unknown", http.StatusUnauthorized},
+		{"broken", "Bearer gpk_broken", http.StatusInternalServerError},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPut, "/stations/station-1/prices", nil)
+			if tc.header != "" {
+				req.Header.Set("Authorization", tc.header)
+			}
+			w := httptest.NewRecorder()
+
+			r.ServeHTTP(w, req)
+
+			if w.Code != tc.want {
+				t.Fatalf("expected %d, got %d: %s", tc.want, w.Code, w.Body.String())
+			}
+		})
+	}
+
+	// Only requests made with a valid key are logged, with their outcome
+	if len(keys.usage) != 1 {
+		t.Fatalf("expected 1 usage record, got %d", len(keys.usage))
+	}
+	got := keys.usage[0]
+	if got.APIKeyID != "key-1" || got.StationID != "station-1" || got.StatusCode != http.StatusAccepted || got.Method != http.MethodPut {
+		t.Fatalf("unexpected usage record: %+v", got)
+	}
+}
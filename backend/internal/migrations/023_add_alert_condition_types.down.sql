@@ -0,0 +1,17 @@
+-- 023_add_alert_condition_types.down.sql
+DROP INDEX IF EXISTS idx_price_submissions_history;
+
+ALTER TABLE fuel_prices
+  DROP COLUMN IF EXISTS previous_price;
+
+ALTER TABLE alerts
+  DROP CONSTRAINT IF EXISTS alerts_average_scope_check;
+
+ALTER TABLE alerts
+  DROP CONSTRAINT IF EXISTS alerts_condition_type_check;
+
+ALTER TABLE alerts
+  DROP COLUMN IF EXISTS lookback_days,
+  DROP COLUMN IF EXISTS average_scope,
+  DROP COLUMN IF EXISTS condition_percent,
+  DROP COLUMN IF EXISTS condition_type;
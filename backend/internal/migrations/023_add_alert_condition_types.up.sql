@@ -0,0 +1,29 @@
+-- 023_add_alert_condition_types.up.sql
+ALTER TABLE alerts
+  ADD COLUMN IF NOT EXISTS condition_type VARCHAR(32) NOT NULL DEFAULT 'price_threshold',
+  ADD COLUMN IF NOT EXISTS condition_percent DECIMAL(5, 2),
+  ADD COLUMN IF NOT EXISTS average_scope VARCHAR(16),
+  ADD COLUMN IF NOT EXISTS lookback_days INT NOT NULL DEFAULT 7;
+
+ALTER TABLE alerts
+  DROP CONSTRAINT IF EXISTS alerts_condition_type_check;
+
+ALTER TABLE alerts
+  ADD CONSTRAINT alerts_condition_type_check
+  CHECK (condition_type IN ('price_threshold', 'percent_below_average', 'new_cheapest', 'cycle_bottom', 'price_rise'));
+
+ALTER TABLE alerts
+  DROP CONSTRAINT IF EXISTS alerts_average_scope_check;
+
+ALTER TABLE alerts
+  ADD CONSTRAINT alerts_average_scope_check
+  CHECK (average_scope IS NULL OR average_scope IN ('station', 'area'));
+
+-- Previous price lets the trigger path detect rises without replaying history
+ALTER TABLE fuel_prices
+  ADD COLUMN IF NOT EXISTS previous_price DECIMAL(10, 3);
+
+-- Trailing averages and minimums scan approved history per station and fuel type
+CREATE INDEX IF NOT EXISTS idx_price_submissions_history
+  ON price_submissions(station_id, fuel_type_id, submitted_at)
+  WHERE moderation_status = 'approved';
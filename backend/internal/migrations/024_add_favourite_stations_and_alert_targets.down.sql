@@ -0,0 +1,9 @@
+-- 024_add_favourite_stations_and_alert_targets.down.sql
+ALTER TABLE alerts
+  DROP CONSTRAINT IF EXISTS alerts_target_mode_check;
+
+ALTER TABLE alerts
+  DROP COLUMN IF EXISTS target_mode;
+
+DROP TABLE IF EXISTS alert_stations;
+DROP TABLE IF EXISTS favourite_stations;
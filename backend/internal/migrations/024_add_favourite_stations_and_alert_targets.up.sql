@@ -0,0 +1,28 @@
+-- 024_add_favourite_stations_and_alert_targets.up.sql
+CREATE TABLE IF NOT EXISTS favourite_stations (
+  id UUID PRIMARY KEY,
+  user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  station_id UUID NOT NULL REFERENCES stations(id) ON DELETE CASCADE,
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  UNIQUE (user_id, station_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_favourite_stations_station ON favourite_stations(station_id);
+
+CREATE TABLE IF NOT EXISTS alert_stations (
+  alert_id UUID NOT NULL REFERENCES alerts(id) ON DELETE CASCADE,
+  station_id UUID NOT NULL REFERENCES stations(id) ON DELETE CASCADE,
+  PRIMARY KEY (alert_id, station_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_alert_stations_station ON alert_stations(station_id);
+
+ALTER TABLE alerts
+  ADD COLUMN IF NOT EXISTS target_mode VARCHAR(32) NOT NULL DEFAULT 'radius';
+
+ALTER TABLE alerts
+  DROP CONSTRAINT IF EXISTS alerts_target_mode_check;
+
+ALTER TABLE alerts
+  ADD CONSTRAINT alerts_target_mode_check
+  CHECK (target_mode IN ('radius', 'stations', 'radius_or_stations', 'favourites'));
@@ -0,0 +1,23 @@
+-- 025_add_alert_cooldown_and_rearm.down.sql
+ALTER TABLE users
+  DROP COLUMN IF EXISTS time_zone;
+
+ALTER TABLE alerts
+  DROP CONSTRAINT IF EXISTS alerts_max_triggers_check;
+
+ALTER TABLE alerts
+  DROP CONSTRAINT IF EXISTS alerts_cooldown_minutes_check;
+
+ALTER TABLE alerts
+  DROP CONSTRAINT IF EXISTS alerts_rearm_rule_check;
+
+ALTER TABLE alerts
+  DROP COLUMN IF EXISTS last_evaluation_detail,
+  DROP COLUMN IF EXISTS last_evaluation_reason,
+  DROP COLUMN IF EXISTS last_evaluated_at,
+  DROP COLUMN IF EXISTS last_triggered_price,
+  DROP COLUMN IF EXISTS last_triggered_station_id,
+  DROP COLUMN IF EXISTS armed,
+  DROP COLUMN IF EXISTS max_triggers,
+  DROP COLUMN IF EXISTS rearm_rule,
+  DROP COLUMN IF EXISTS cooldown_minutes;
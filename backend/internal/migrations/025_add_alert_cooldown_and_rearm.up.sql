@@ -0,0 +1,36 @@
+-- 025_add_alert_cooldown_and_rearm.up.sql
+ALTER TABLE alerts
+  ADD COLUMN IF NOT EXISTS cooldown_minutes INT,
+  ADD COLUMN IF NOT EXISTS rearm_rule VARCHAR(32) NOT NULL DEFAULT 'none',
+  ADD COLUMN IF NOT EXISTS max_triggers INT,
+  ADD COLUMN IF NOT EXISTS armed BOOLEAN NOT NULL DEFAULT true,
+  ADD COLUMN IF NOT EXISTS last_triggered_station_id UUID REFERENCES stations(id) ON DELETE SET NULL,
+  ADD COLUMN IF NOT EXISTS last_triggered_price DECIMAL(10, 3),
+  ADD COLUMN IF NOT EXISTS last_evaluated_at TIMESTAMP,
+  ADD COLUMN IF NOT EXISTS last_evaluation_reason VARCHAR(32),
+  ADD COLUMN IF NOT EXISTS last_evaluation_detail TEXT;
+
+ALTER TABLE alerts
+  DROP CONSTRAINT IF EXISTS alerts_rearm_rule_check;
+
+ALTER TABLE alerts
+  ADD CONSTRAINT alerts_rearm_rule_check
+  CHECK (rearm_rule IN ('none', 'price_recovers'));
+
+ALTER TABLE alerts
+  DROP CONSTRAINT IF EXISTS alerts_cooldown_minutes_check;
+
+ALTER TABLE alerts
+  ADD CONSTRAINT alerts_cooldown_minutes_check
+  CHECK (cooldown_minutes IS NULL OR cooldown_minutes >= 0);
+
+ALTER TABLE alerts
+  DROP CONSTRAINT IF EXISTS alerts_max_triggers_check;
+
+ALTER TABLE alerts
+  ADD CONSTRAINT alerts_max_triggers_check
+  CHECK (max_triggers IS NULL OR max_triggers > 0);
+
+-- Used for the once-per-day rule when an alert has no explicit cooldown
+ALTER TABLE users
+  ADD COLUMN IF NOT EXISTS time_zone VARCHAR(64) NOT NULL DEFAULT 'Australia/Sydney';
@@ -0,0 +1,4 @@
+-- 026_add_price_change_outbox.down.sql
+DROP INDEX IF EXISTS idx_price_change_events_processed;
+DROP INDEX IF EXISTS idx_price_change_events_due;
+DROP TABLE IF EXISTS price_change_events;
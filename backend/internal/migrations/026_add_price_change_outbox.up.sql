@@ -0,0 +1,27 @@
+-- 026_add_price_change_outbox.up.sql
+-- Price changes are written here in the same statement as the fuel_prices
+-- upsert and evaluated against alerts by the background alert workers.
+CREATE TABLE IF NOT EXISTS price_change_events (
+  id UUID PRIMARY KEY,
+  station_id UUID NOT NULL REFERENCES stations(id) ON DELETE CASCADE,
+  fuel_type_id UUID NOT NULL REFERENCES fuel_types(id) ON DELETE CASCADE,
+  price DECIMAL(10, 3) NOT NULL,
+  previous_price DECIMAL(10, 3),
+  source VARCHAR(32) NOT NULL,
+  status VARCHAR(16) NOT NULL DEFAULT 'pending',
+  attempts INT NOT NULL DEFAULT 0,
+  next_attempt_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  locked_until TIMESTAMP,
+  last_error TEXT,
+  created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  processed_at TIMESTAMP,
+  CONSTRAINT price_change_events_status_check CHECK (status IN ('pending', 'processing', 'done', 'failed'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_price_change_events_due
+  ON price_change_events(next_attempt_at)
+  WHERE status IN ('pending', 'processing');
+
+CREATE INDEX IF NOT EXISTS idx_price_change_events_processed
+  ON price_change_events(processed_at)
+  WHERE status = 'done';
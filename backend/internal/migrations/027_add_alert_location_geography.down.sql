@@ -0,0 +1,9 @@
+-- 027_add_alert_location_geography.down.sql
+DROP INDEX IF EXISTS idx_alerts_active_fuel_type;
+
+CREATE INDEX IF NOT EXISTS idx_alerts_location ON alerts(latitude, longitude);
+
+DROP INDEX IF EXISTS idx_alerts_location_geo;
+
+ALTER TABLE alerts
+  DROP COLUMN IF EXISTS location;
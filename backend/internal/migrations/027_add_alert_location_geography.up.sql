@@ -0,0 +1,21 @@
+-- 027_add_alert_location_geography.up.sql
+-- Radius alerts carry a geography point so trigger matching can use a GiST
+-- index instead of building a point for every alert on each price change.
+-- Alerts that only watch pinned or favourite stations have no location.
+ALTER TABLE alerts
+  ADD COLUMN IF NOT EXISTS location GEOGRAPHY(POINT, 4326)
+  GENERATED ALWAYS AS (
+    CASE WHEN target_mode IN ('radius', 'radius_or_stations')
+      THEN ST_SetSRID(ST_MakePoint(longitude::float8, latitude::float8), 4326)::geography
+    END
+  ) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_alerts_location_geo
+  ON alerts USING GIST(location)
+  WHERE is_active = true AND location IS NOT NULL;
+
+DROP INDEX IF EXISTS idx_alerts_location;
+
+CREATE INDEX IF NOT EXISTS idx_alerts_active_fuel_type
+  ON alerts(fuel_type_id)
+  WHERE is_active = true;
@@ -0,0 +1,4 @@
+-- 028_add_email_outbox.down.sql
+DROP TABLE IF EXISTS email_suppressions;
+DROP TABLE IF EXISTS email_message_events;
+DROP TABLE IF EXISTS email_messages;
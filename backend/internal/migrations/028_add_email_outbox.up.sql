@@ -0,0 +1,46 @@
+-- 028_add_email_outbox.up.sql
+-- Emails are queued here and delivered by the background email workers.
+CREATE TABLE IF NOT EXISTS email_messages (
+  id UUID PRIMARY KEY,
+  user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+  to_email VARCHAR(255) NOT NULL,
+  template VARCHAR(64) NOT NULL,
+  subject VARCHAR(255) NOT NULL,
+  html_body TEXT NOT NULL,
+  status VARCHAR(16) NOT NULL DEFAULT 'queued',
+  attempts INT NOT NULL DEFAULT 0,
+  next_attempt_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  locked_until TIMESTAMP,
+  last_error TEXT,
+  created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  sent_at TIMESTAMP,
+  updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  CONSTRAINT email_messages_status_check CHECK (status IN ('queued', 'sending', 'sent', 'failed', 'bounced'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_email_messages_due
+  ON email_messages(next_attempt_at)
+  WHERE status IN ('queued', 'sending');
+
+CREATE INDEX IF NOT EXISTS idx_email_messages_user_id ON email_messages(user_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_email_messages_to_email ON email_messages(LOWER(to_email), created_at DESC);
+
+-- Every status a message passes through, for support lookups.
+CREATE TABLE IF NOT EXISTS email_message_events (
+  id UUID PRIMARY KEY,
+  message_id UUID NOT NULL REFERENCES email_messages(id) ON DELETE CASCADE,
+  status VARCHAR(16) NOT NULL,
+  detail TEXT,
+  created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_email_message_events_message_id ON email_message_events(message_id, created_at);
+
+-- Addresses that hard bounced or complained are never emailed again.
+CREATE TABLE IF NOT EXISTS email_suppressions (
+  email VARCHAR(255) PRIMARY KEY,
+  reason VARCHAR(16) NOT NULL,
+  detail TEXT,
+  created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  CONSTRAINT email_suppressions_reason_check CHECK (reason IN ('bounce', 'complaint'))
+);
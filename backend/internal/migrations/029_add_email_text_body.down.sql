@@ -0,0 +1,2 @@
+-- 029_add_email_text_body.down.sql
+ALTER TABLE email_messages DROP COLUMN IF EXISTS text_body;
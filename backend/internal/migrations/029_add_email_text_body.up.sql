@@ -0,0 +1,3 @@
+-- 029_add_email_text_body.up.sql
+-- Plain-text alternative sent alongside the HTML body.
+ALTER TABLE email_messages ADD COLUMN IF NOT EXISTS text_body TEXT NOT NULL DEFAULT '';
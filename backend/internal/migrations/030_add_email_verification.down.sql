@@ -0,0 +1,3 @@
+-- 030_add_email_verification.down.sql
+ALTER TABLE users ALTER COLUMN email_verified DROP NOT NULL;
+DROP TABLE IF EXISTS email_verification_tokens;
@@ -0,0 +1,19 @@
+-- 030_add_email_verification.up.sql
+-- Single-use email verification tokens. Only a SHA-256 hash of each token is stored.
+CREATE TABLE IF NOT EXISTS email_verification_tokens (
+  id UUID PRIMARY KEY,
+  user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  email VARCHAR(255) NOT NULL,
+  token_hash VARCHAR(64) NOT NULL UNIQUE,
+  expires_at TIMESTAMP NOT NULL,
+  used_at TIMESTAMP,
+  created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_email_verification_tokens_user_id ON email_verification_tokens(user_id, created_at DESC);
+
+-- Accounts created before verification was enforced keep working.
+UPDATE users SET email_verified = TRUE WHERE email_verified IS NOT TRUE;
+
+ALTER TABLE users ALTER COLUMN email_verified SET DEFAULT FALSE;
+ALTER TABLE users ALTER COLUMN email_verified SET NOT NULL;
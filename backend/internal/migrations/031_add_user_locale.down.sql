@@ -0,0 +1,3 @@
+-- 031_add_user_locale.down.sql
+ALTER TABLE users
+  DROP COLUMN IF EXISTS locale;
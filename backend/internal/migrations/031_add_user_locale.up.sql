@@ -0,0 +1,5 @@
+-- 031_add_user_locale.up.sql
+-- Preferred locale for emails and API messages. NULL means the user has not
+-- chosen one and the default locale is used.
+ALTER TABLE users
+  ADD COLUMN IF NOT EXISTS locale VARCHAR(35);
@@ -0,0 +1,3 @@
+-- 032_add_email_unsubscribes.down.sql
+ALTER TABLE email_messages DROP COLUMN IF EXISTS list_unsubscribe_url;
+DROP TABLE IF EXISTS email_unsubscribes;
@@ -0,0 +1,17 @@
+-- 032_add_email_unsubscribes.up.sql
+-- Opt-outs from alert and broadcast emails. scope_id is the alert or station
+-- for single-alert and single-station opt-outs and NULL for whole categories.
+CREATE TABLE IF NOT EXISTS email_unsubscribes (
+  id UUID PRIMARY KEY,
+  user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  category VARCHAR(32) NOT NULL CHECK (category IN ('alert', 'all_alerts', 'station_broadcasts', 'all_broadcasts')),
+  scope_id UUID,
+  method VARCHAR(20) NOT NULL CHECK (method IN ('one_click', 'link')),
+  created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_email_unsubscribes_scope
+  ON email_unsubscribes(user_id, category, COALESCE(scope_id, '00000000-0000-0000-0000-000000000000'::uuid));
+
+-- One-click unsubscribe URL sent in the List-Unsubscribe header.
+ALTER TABLE email_messages ADD COLUMN IF NOT EXISTS list_unsubscribe_url TEXT;
@@ -0,0 +1,3 @@
+-- 033_add_user_sessions.down.sql
+DROP TABLE IF EXISTS session_refresh_tokens;
+DROP TABLE IF EXISTS user_sessions;
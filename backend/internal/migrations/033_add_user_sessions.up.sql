@@ -0,0 +1,27 @@
+-- 033_add_user_sessions.up.sql
+-- Signed-in sessions, renewed with rotating refresh tokens. Only a SHA-256
+-- hash of each refresh token is stored. Rotated tokens are kept so that a
+-- replayed one can be recognised and its session revoked.
+CREATE TABLE IF NOT EXISTS user_sessions (
+  id UUID PRIMARY KEY,
+  user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  user_agent VARCHAR(512) NOT NULL DEFAULT '',
+  device_name VARCHAR(255) NOT NULL DEFAULT '',
+  ip_address VARCHAR(45) NOT NULL DEFAULT '',
+  created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  last_used_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  expires_at TIMESTAMP NOT NULL,
+  revoked_at TIMESTAMP,
+  revoked_reason VARCHAR(50)
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_sessions_user_id ON user_sessions(user_id) WHERE revoked_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS session_refresh_tokens (
+  token_hash VARCHAR(64) PRIMARY KEY,
+  session_id UUID NOT NULL REFERENCES user_sessions(id) ON DELETE CASCADE,
+  created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  rotated_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_session_refresh_tokens_session_id ON session_refresh_tokens(session_id);
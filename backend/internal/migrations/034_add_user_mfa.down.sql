@@ -0,0 +1,5 @@
+-- 034_add_user_mfa.down.sql
+ALTER TABLE users DROP COLUMN IF EXISTS role;
+ALTER TABLE user_sessions DROP COLUMN IF EXISTS mfa_verified;
+DROP TABLE IF EXISTS user_recovery_codes;
+DROP TABLE IF EXISTS user_totp;
@@ -0,0 +1,29 @@
+-- 034_add_user_mfa.up.sql
+-- Optional TOTP two-factor authentication. A secret is stored when
+-- enrolment starts and confirmed_at is set once the user has entered a code
+-- from it. last_used_step records the time step of the last accepted code so
+-- a code cannot be replayed. Recovery codes are single-use and stored as
+-- SHA-256 hashes.
+CREATE TABLE IF NOT EXISTS user_totp (
+  user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+  secret VARCHAR(64) NOT NULL,
+  confirmed_at TIMESTAMP,
+  last_used_step BIGINT NOT NULL DEFAULT 0,
+  created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS user_recovery_codes (
+  code_hash VARCHAR(64) PRIMARY KEY,
+  user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  used_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_recovery_codes_user_id ON user_recovery_codes(user_id);
+
+-- Sessions started with a second factor keep issuing access tokens that say so
+ALTER TABLE user_sessions ADD COLUMN IF NOT EXISTS mfa_verified BOOLEAN NOT NULL DEFAULT false;
+
+-- Staff roles. Station owners are identified by their station_owners row.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS role VARCHAR(20) NOT NULL DEFAULT 'user'
+  CHECK (role IN ('user', 'moderator', 'admin'));
@@ -0,0 +1,3 @@
+-- 035_add_login_attempts.down.sql
+DROP INDEX IF EXISTS idx_login_attempts_last_failure_at;
+DROP TABLE IF EXISTS login_attempts;
@@ -0,0 +1,13 @@
+-- 035_add_login_attempts.up.sql
+-- Failed sign-in attempts, keyed by account (email) or client IP, for
+-- progressive delays and temporary lockouts shared by every API instance.
+-- Failures are counted in a window starting at the first failure.
+CREATE TABLE IF NOT EXISTS login_attempts (
+  key VARCHAR(320) PRIMARY KEY,
+  failures INTEGER NOT NULL DEFAULT 0,
+  window_started_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  last_failure_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  locked_until TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_login_attempts_last_failure_at ON login_attempts(last_failure_at);
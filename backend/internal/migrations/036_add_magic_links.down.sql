@@ -0,0 +1,6 @@
+-- 036_add_magic_links.down.sql
+DROP INDEX IF EXISTS idx_audit_log_action;
+DROP INDEX IF EXISTS idx_audit_log_user_id;
+DROP TABLE IF EXISTS audit_log;
+DROP INDEX IF EXISTS idx_magic_link_tokens_user_id;
+DROP TABLE IF EXISTS magic_link_tokens;
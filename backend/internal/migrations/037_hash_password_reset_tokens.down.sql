@@ -0,0 +1,7 @@
+-- 037_hash_password_reset_tokens.down.sql
+DELETE FROM password_resets;
+
+ALTER TABLE password_resets DROP COLUMN IF EXISTS token_hash;
+ALTER TABLE password_resets DROP COLUMN IF EXISTS selector;
+ALTER TABLE password_resets ADD COLUMN token VARCHAR(255) NOT NULL UNIQUE;
+CREATE INDEX IF NOT EXISTS idx_password_resets_token ON password_resets(token);
@@ -0,0 +1,11 @@
+-- 037_hash_password_reset_tokens.up.sql
+-- Password reset tokens are looked up by a selector and verified against a
+-- SHA-256 hash of their secret part, instead of being stored in plaintext.
+-- Outstanding plaintext tokens cannot be converted, and expire within an hour
+-- anyway, so they are removed.
+DELETE FROM password_resets;
+
+DROP INDEX IF EXISTS idx_password_resets_token;
+ALTER TABLE password_resets DROP COLUMN IF EXISTS token;
+ALTER TABLE password_resets ADD COLUMN selector VARCHAR(32) NOT NULL UNIQUE;
+ALTER TABLE password_resets ADD COLUMN token_hash VARCHAR(64) NOT NULL;
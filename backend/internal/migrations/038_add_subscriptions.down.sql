@@ -0,0 +1,4 @@
+-- 038_add_subscriptions.down.sql
+DROP TABLE IF EXISTS payment_webhook_events;
+DROP TABLE IF EXISTS subscriptions;
+DROP TABLE IF EXISTS subscription_plans;
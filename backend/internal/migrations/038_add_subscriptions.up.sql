@@ -0,0 +1,55 @@
+-- 038_add_subscriptions.up.sql
+-- Paid plans and users' subscriptions to them. users.tier now follows the
+-- user's subscription instead of being chosen by the user.
+CREATE TABLE IF NOT EXISTS subscription_plans (
+  id VARCHAR(64) PRIMARY KEY,
+  name VARCHAR(100) NOT NULL,
+  tier VARCHAR(50) NOT NULL,
+  billing_interval VARCHAR(10) NOT NULL CHECK (billing_interval IN ('month', 'year')),
+  price_cents INTEGER NOT NULL CHECK (price_cents >= 0),
+  currency CHAR(3) NOT NULL DEFAULT 'AUD',
+  trial_days INTEGER NOT NULL DEFAULT 0 CHECK (trial_days >= 0),
+  active BOOLEAN NOT NULL DEFAULT TRUE,
+  created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+INSERT INTO subscription_plans (id, name, tier, billing_interval, price_cents, trial_days) VALUES
+  ('premium-monthly', 'Premium (monthly)', 'premium', 'month', 499, 14),
+  ('premium-annual', 'Premium (annual)', 'premium', 'year', 4999, 14)
+ON CONFLICT (id) DO NOTHING;
+
+-- Subscriptions are created and updated from the payment provider's
+-- webhooks. A canceled subscription runs until the end of its period, and a
+-- past_due one until grace_until, before it expires.
+CREATE TABLE IF NOT EXISTS subscriptions (
+  id UUID PRIMARY KEY,
+  user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  plan_id VARCHAR(64) NOT NULL REFERENCES subscription_plans(id),
+  status VARCHAR(20) NOT NULL CHECK (status IN ('trialing', 'active', 'past_due', 'canceled', 'expired')),
+  provider VARCHAR(32) NOT NULL,
+  provider_subscription_id VARCHAR(255) NOT NULL,
+  current_period_start TIMESTAMP NOT NULL,
+  current_period_end TIMESTAMP NOT NULL,
+  trial_end TIMESTAMP,
+  grace_until TIMESTAMP,
+  canceled_at TIMESTAMP,
+  created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  UNIQUE (provider, provider_subscription_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_subscriptions_user_id ON subscriptions(user_id, created_at DESC);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_subscriptions_one_live ON subscriptions(user_id)
+  WHERE status <> 'expired';
+
+-- Webhook events already applied, so redelivered events are ignored.
+CREATE TABLE IF NOT EXISTS payment_webhook_events (
+  provider VARCHAR(32) NOT NULL,
+  event_id VARCHAR(255) NOT NULL,
+  event_type VARCHAR(64) NOT NULL,
+  received_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  PRIMARY KEY (provider, event_id)
+);
+
+-- Tiers were self-declared until now, so nobody has paid for premium.
+UPDATE users SET tier = 'free', updated_at = NOW() WHERE tier <> 'free';
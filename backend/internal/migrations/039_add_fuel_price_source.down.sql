@@ -0,0 +1,6 @@
+-- 039_add_fuel_price_source.down.sql
+ALTER TABLE fuel_prices
+  DROP CONSTRAINT IF EXISTS fuel_prices_source_check;
+
+ALTER TABLE fuel_prices
+  DROP COLUMN IF EXISTS source;
@@ -0,0 +1,24 @@
+-- 039_add_fuel_price_source.up.sql
+-- Where each current price came from, so apps can show prices published by
+-- the station itself. Values match price_change_events.source.
+ALTER TABLE fuel_prices
+  ADD COLUMN IF NOT EXISTS source VARCHAR(32) NOT NULL DEFAULT 'submission';
+
+ALTER TABLE fuel_prices
+  DROP CONSTRAINT IF EXISTS fuel_prices_source_check;
+
+ALTER TABLE fuel_prices
+  ADD CONSTRAINT fuel_prices_source_check
+  CHECK (source IN ('submission', 'service_nsw', 'owner'));
+
+-- Existing prices take the source of their latest change event, where known
+UPDATE fuel_prices fp
+SET source = latest.source
+FROM (
+  SELECT DISTINCT ON (station_id, fuel_type_id) station_id, fuel_type_id, source
+  FROM price_change_events
+  ORDER BY station_id, fuel_type_id, created_at DESC
+) latest
+WHERE latest.station_id = fp.station_id
+  AND latest.fuel_type_id = fp.fuel_type_id
+  AND latest.source IN ('submission', 'service_nsw');
@@ -0,0 +1,4 @@
+-- 040_add_owner_api_keys.down.sql
+DROP TABLE IF EXISTS owner_api_key_usage;
+DROP TABLE IF EXISTS owner_api_idempotency_keys;
+DROP TABLE IF EXISTS owner_api_keys;
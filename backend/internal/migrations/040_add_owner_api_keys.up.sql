@@ -0,0 +1,47 @@
+-- 040_add_owner_api_keys.up.sql
+-- API keys for station owners' point-of-sale and pricing systems. Only a
+-- SHA-256 hash of each key is stored. A key can only be used for the stations
+-- it lists.
+CREATE TABLE IF NOT EXISTS owner_api_keys (
+  id UUID PRIMARY KEY,
+  station_owner_id UUID NOT NULL REFERENCES station_owners(id) ON DELETE CASCADE,
+  name VARCHAR(100) NOT NULL,
+  key_prefix VARCHAR(16) NOT NULL,
+  key_hash VARCHAR(64) NOT NULL UNIQUE,
+  scopes TEXT[] NOT NULL,
+  station_ids UUID[] NOT NULL,
+  created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  last_used_at TIMESTAMP,
+  revoked_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_owner_api_keys_owner ON owner_api_keys(station_owner_id, created_at DESC);
+
+-- Requests sent with an Idempotency-Key header and their responses, which
+-- are replayed when a request is retried. response_body is NULL while the
+-- first request is still being handled.
+CREATE TABLE IF NOT EXISTS owner_api_idempotency_keys (
+  api_key_id UUID NOT NULL REFERENCES owner_api_keys(id) ON DELETE CASCADE,
+  idempotency_key VARCHAR(255) NOT NULL,
+  request_hash VARCHAR(64) NOT NULL,
+  response_body JSONB,
+  created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  PRIMARY KEY (api_key_id, idempotency_key)
+);
+
+CREATE INDEX IF NOT EXISTS idx_owner_api_idempotency_keys_created_at ON owner_api_idempotency_keys(created_at);
+
+-- Every request made with a key
+CREATE TABLE IF NOT EXISTS owner_api_key_usage (
+  id BIGSERIAL PRIMARY KEY,
+  api_key_id UUID NOT NULL REFERENCES owner_api_keys(id) ON DELETE CASCADE,
+  method VARCHAR(10) NOT NULL,
+  path VARCHAR(255) NOT NULL,
+  station_id VARCHAR(64),
+  status_code INTEGER NOT NULL,
+  ip_address VARCHAR(64),
+  created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_owner_api_key_usage_key ON owner_api_key_usage(api_key_id, created_at DESC);
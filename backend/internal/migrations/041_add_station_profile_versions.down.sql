@@ -0,0 +1,8 @@
+-- 041_add_station_profile_versions.down.sql
+DROP TABLE IF EXISTS station_change_requests;
+DROP TABLE IF EXISTS station_profile_versions;
+
+ALTER TABLE stations
+  DROP COLUMN IF EXISTS website,
+  DROP COLUMN IF EXISTS phone,
+  ALTER COLUMN operating_hours TYPE VARCHAR(255) USING LEFT(operating_hours, 255);
@@ -0,0 +1,37 @@
+-- 041_add_station_profile_versions.up.sql
+-- Station contact details owners can edit. Operating hours may be a JSON
+-- object of opening times per day, which does not fit in 255 characters.
+ALTER TABLE stations
+  ADD COLUMN IF NOT EXISTS phone VARCHAR(50),
+  ADD COLUMN IF NOT EXISTS website VARCHAR(500),
+  ALTER COLUMN operating_hours TYPE TEXT;
+
+-- Every version of a station's profile. Version 1 is the profile as it was
+-- before its first recorded change.
+CREATE TABLE IF NOT EXISTS station_profile_versions (
+  id UUID PRIMARY KEY,
+  station_id UUID NOT NULL REFERENCES stations(id) ON DELETE CASCADE,
+  version INTEGER NOT NULL,
+  profile JSONB NOT NULL,
+  source VARCHAR(32) NOT NULL CHECK (source IN ('baseline', 'owner', 'review', 'rollback')),
+  changed_by UUID REFERENCES users(id) ON DELETE SET NULL,
+  change_request_id UUID,
+  created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  UNIQUE (station_id, version)
+);
+
+-- Brand and location changes owners have asked for, waiting for an admin.
+-- A newer request for the same station supersedes a pending one.
+CREATE TABLE IF NOT EXISTS station_change_requests (
+  id UUID PRIMARY KEY,
+  station_id UUID NOT NULL REFERENCES stations(id) ON DELETE CASCADE,
+  requested_by UUID REFERENCES users(id) ON DELETE SET NULL,
+  changes JSONB NOT NULL,
+  status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'approved', 'rejected', 'superseded')),
+  reviewer_notes TEXT,
+  reviewed_at TIMESTAMP,
+  created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_station_change_requests_status ON station_change_requests(status, created_at);
+CREATE INDEX IF NOT EXISTS idx_station_change_requests_station ON station_change_requests(station_id, created_at DESC);
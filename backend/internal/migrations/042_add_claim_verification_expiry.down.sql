@@ -0,0 +1,10 @@
+-- 042_add_claim_verification_expiry.down.sql
+DROP INDEX IF EXISTS idx_claim_verifications_renews_id;
+DROP INDEX IF EXISTS idx_claim_verifications_expires_at;
+
+UPDATE claim_verifications SET verification_status = 'rejected' WHERE verification_status IN ('expired', 'renewed');
+
+ALTER TABLE claim_verifications
+  DROP COLUMN IF EXISTS renews_id,
+  DROP COLUMN IF EXISTS reminder_days_sent,
+  DROP COLUMN IF EXISTS expires_at;
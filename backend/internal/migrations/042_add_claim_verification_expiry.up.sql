@@ -0,0 +1,19 @@
+-- 042_add_claim_verification_expiry.up.sql
+-- Ownership verifications last a year. Owners are reminded before a claim
+-- expires (reminder_days_sent is the nearest reminder sent, in days before
+-- expiry) and re-verify with a new claim that renews the old one. Claim
+-- statuses are now pending, approved, rejected, expired and renewed.
+ALTER TABLE claim_verifications
+  ADD COLUMN IF NOT EXISTS expires_at TIMESTAMP,
+  ADD COLUMN IF NOT EXISTS reminder_days_sent INTEGER,
+  ADD COLUMN IF NOT EXISTS renews_id UUID REFERENCES claim_verifications(id) ON DELETE SET NULL;
+
+-- Claims approved before expiry was tracked get a year from approval, and at
+-- least 30 days so their owners have time to re-verify.
+UPDATE claim_verifications
+SET expires_at = GREATEST(COALESCE(verified_at, updated_at) + INTERVAL '1 year', NOW() + INTERVAL '30 days')
+WHERE verification_status = 'approved' AND expires_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_claim_verifications_expires_at ON claim_verifications(expires_at)
+  WHERE verification_status = 'approved';
+CREATE INDEX IF NOT EXISTS idx_claim_verifications_renews_id ON claim_verifications(renews_id);
@@ -0,0 +1,11 @@
+-- 043_add_owner_teams.down.sql
+ALTER TABLE price_change_events DROP COLUMN IF EXISTS changed_by;
+ALTER TABLE fuel_prices DROP COLUMN IF EXISTS changed_by;
+ALTER TABLE broadcasts
+  DROP COLUMN IF EXISTS updated_by,
+  DROP COLUMN IF EXISTS created_by;
+ALTER TABLE station_owners DROP COLUMN IF EXISTS updated_by;
+
+DROP TABLE IF EXISTS station_owner_invitations;
+DROP TABLE IF EXISTS station_owner_member_stations;
+DROP TABLE IF EXISTS station_owner_members;
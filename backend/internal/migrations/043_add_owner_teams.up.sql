@@ -0,0 +1,66 @@
+-- 043_add_owner_teams.up.sql
+-- The users who make up a station owner business. Owners run the team,
+-- managers can use every station, and staff only have the permissions they
+-- are granted for each station. A user belongs to at most one business.
+CREATE TABLE IF NOT EXISTS station_owner_members (
+  id UUID PRIMARY KEY,
+  station_owner_id UUID NOT NULL REFERENCES station_owners(id) ON DELETE CASCADE,
+  user_id UUID NOT NULL UNIQUE REFERENCES users(id) ON DELETE CASCADE,
+  role VARCHAR(20) NOT NULL CHECK (role IN ('owner', 'manager', 'staff')),
+  invited_by UUID REFERENCES users(id) ON DELETE SET NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_station_owner_members_owner ON station_owner_members(station_owner_id, created_at);
+
+-- What each staff member may do at each station. Values are
+-- publish_prices, send_broadcasts and view_analytics.
+CREATE TABLE IF NOT EXISTS station_owner_member_stations (
+  member_id UUID NOT NULL REFERENCES station_owner_members(id) ON DELETE CASCADE,
+  station_id UUID NOT NULL REFERENCES stations(id) ON DELETE CASCADE,
+  permissions TEXT[] NOT NULL,
+  PRIMARY KEY (member_id, station_id)
+);
+
+-- Invitations to join a business. Only a SHA-256 hash of the token in the
+-- accept link is stored. stations holds the permissions a staff member gets
+-- when they accept.
+CREATE TABLE IF NOT EXISTS station_owner_invitations (
+  id UUID PRIMARY KEY,
+  station_owner_id UUID NOT NULL REFERENCES station_owners(id) ON DELETE CASCADE,
+  email VARCHAR(255) NOT NULL,
+  role VARCHAR(20) NOT NULL CHECK (role IN ('owner', 'manager', 'staff')),
+  stations JSONB NOT NULL DEFAULT '[]',
+  token_hash VARCHAR(64) NOT NULL UNIQUE,
+  invited_by UUID REFERENCES users(id) ON DELETE SET NULL,
+  expires_at TIMESTAMP NOT NULL,
+  accepted_at TIMESTAMP,
+  accepted_by UUID REFERENCES users(id) ON DELETE SET NULL,
+  revoked_at TIMESTAMP,
+  created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_station_owner_invitations_owner ON station_owner_invitations(station_owner_id, created_at DESC);
+
+-- Each existing business's account holder becomes its first owner
+INSERT INTO station_owner_members (id, station_owner_id, user_id, role, created_at)
+SELECT gen_random_uuid(), so.id, so.user_id, 'owner', so.created_at
+FROM station_owners so
+ON CONFLICT (user_id) DO NOTHING;
+
+-- The member behind each broadcast, price change and business profile edit.
+-- Station profile changes already record the user in
+-- station_profile_versions.changed_by and station_change_requests.requested_by.
+ALTER TABLE station_owners
+  ADD COLUMN IF NOT EXISTS updated_by UUID REFERENCES users(id) ON DELETE SET NULL;
+
+ALTER TABLE broadcasts
+  ADD COLUMN IF NOT EXISTS created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+  ADD COLUMN IF NOT EXISTS updated_by UUID REFERENCES users(id) ON DELETE SET NULL;
+
+ALTER TABLE fuel_prices
+  ADD COLUMN IF NOT EXISTS changed_by UUID REFERENCES users(id) ON DELETE SET NULL;
+
+ALTER TABLE price_change_events
+  ADD COLUMN IF NOT EXISTS changed_by UUID REFERENCES users(id) ON DELETE SET NULL;
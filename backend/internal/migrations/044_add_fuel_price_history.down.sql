@@ -0,0 +1,2 @@
+-- 044_add_fuel_price_history.down.sql
+DROP TABLE IF EXISTS fuel_price_history;
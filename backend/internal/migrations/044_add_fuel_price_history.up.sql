@@ -0,0 +1,20 @@
+-- 044_add_fuel_price_history.up.sql
+-- Every change to a station's price, kept so owners can benchmark their
+-- prices against nearby competitors over time. price_change_events is an
+-- outbox that is purged a week after processing, so it cannot be used.
+CREATE TABLE IF NOT EXISTS fuel_price_history (
+  id UUID PRIMARY KEY,
+  station_id UUID NOT NULL REFERENCES stations(id) ON DELETE CASCADE,
+  fuel_type_id UUID NOT NULL REFERENCES fuel_types(id) ON DELETE CASCADE,
+  price DECIMAL(10, 3) NOT NULL,
+  previous_price DECIMAL(10, 3),
+  source VARCHAR(32) NOT NULL,
+  recorded_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_fuel_price_history_station ON fuel_price_history(station_id, fuel_type_id, recorded_at);
+
+-- History starts with each current price, from when it was last updated
+INSERT INTO fuel_price_history (id, station_id, fuel_type_id, price, source, recorded_at)
+SELECT gen_random_uuid(), station_id, fuel_type_id, price, source, last_updated_at
+FROM fuel_prices;
@@ -0,0 +1,2 @@
+-- 045_add_change_request_reviewer.down.sql
+ALTER TABLE station_change_requests DROP COLUMN IF EXISTS reviewed_by;
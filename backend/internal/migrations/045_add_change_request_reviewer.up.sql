@@ -0,0 +1,5 @@
+-- 045_add_change_request_reviewer.up.sql
+-- The admin who approved or rejected a station change request. Claim
+-- verifications already record theirs in verified_by.
+ALTER TABLE station_change_requests
+  ADD COLUMN IF NOT EXISTS reviewed_by UUID REFERENCES users(id) ON DELETE SET NULL;
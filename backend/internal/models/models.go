@@ -2,6 +2,12 @@ package models
 
 import "time"
 
+// User tiers. A user's tier follows their subscription; see Subscription.
+const (
+	TierFree    = "free"
+	TierPremium = "premium"
+)
+
 type User struct {
 	ID           string `json:"id"`
 	Email        string `json:"email"`
@@ -13,6 +19,8 @@ type User struct {
 	OAuthProviderID string    `json:"oauthProviderId,omitempty"`
 	AvatarURL       string    `json:"avatarUrl,omitempty"`
 	EmailVerified   bool      `json:"emailVerified,omitempty"`
+	TimeZone        string    `json:"timeZone,omitempty"`
+	Locale          string    `json:"locale,omitempty"`
 	CreatedAt       time.Time `json:"createdAt"`
 	UpdatedAt       time.Time `json:"updatedAt"`
 }
@@ -65,6 +73,7 @@ type FuelPrice struct {
 	LastUpdatedAt      *time.Time `json:"lastUpdatedAt"`
 	VerificationStatus string     `json:"verificationStatus"`
 	ConfirmationCount  int        `json:"confirmationCount"`
+	Source             string     `json:"source"`
 }
 
 type PriceSubmission struct {
@@ -84,21 +93,36 @@ type PriceSubmission struct {
 }
 
 type Alert struct {
-	ID              string     `json:"id"`
-	UserID          string     `json:"userId"`
-	FuelTypeID      string     `json:"fuelTypeId"`
-	PriceThreshold  float64    `json:"priceThreshold"`
-	Latitude        float64    `json:"latitude"`
-	Longitude       float64    `json:"longitude"`
-	RadiusKm        int        `json:"radiusKm"`
-	AlertName       string     `json:"alertName"`
-	RecurrenceType  string     `json:"recurrenceType"`
-	NotifyViaPush   bool       `json:"notifyViaPush"`
-	NotifyViaEmail  bool       `json:"notifyViaEmail"`
-	IsActive        bool       `json:"isActive"`
-	CreatedAt       time.Time  `json:"createdAt"`
-	LastTriggeredAt *time.Time `json:"lastTriggeredAt"`
-	TriggerCount    int        `json:"triggerCount"`
+	ID               string     `json:"id"`
+	UserID           string     `json:"userId"`
+	FuelTypeID       string     `json:"fuelTypeId"`
+	PriceThreshold   float64    `json:"priceThreshold"`
+	ConditionType    string     `json:"conditionType"`
+	ConditionPercent float64    `json:"conditionPercent,omitempty"`
+	AverageScope     string     `json:"averageScope,omitempty"`
+	LookbackDays     int        `json:"lookbackDays"`
+	TargetMode       string     `json:"targetMode"`
+	StationIDs       []string   `json:"stationIds"`
+	Latitude         float64    `json:"latitude"`
+	Longitude        float64    `json:"longitude"`
+	RadiusKm         int        `json:"radiusKm"`
+	AlertName        string     `json:"alertName"`
+	RecurrenceType   string     `json:"recurrenceType"`
+	NotifyViaPush    bool       `json:"notifyViaPush"`
+	NotifyViaEmail   bool       `json:"notifyViaEmail"`
+	IsActive         bool       `json:"isActive"`
+	CreatedAt        time.Time  `json:"createdAt"`
+	LastTriggeredAt  *time.Time `json:"lastTriggeredAt"`
+	TriggerCount     int        `json:"triggerCount"`
+	// Re-trigger rules: a nil cooldown means at most once per day in the user's time zone
+	CooldownMinutes *int   `json:"cooldownMinutes"`
+	RearmRule       string `json:"rearmRule"`
+	MaxTriggers     *int   `json:"maxTriggers"`
+	Armed           bool   `json:"armed"`
+	// Outcome of the most recent evaluation, whether or not it fired
+	LastEvaluatedAt      *time.Time `json:"lastEvaluatedAt"`
+	LastEvaluationReason string     `json:"lastEvaluationReason,omitempty"`
+	LastEvaluationDetail string     `json:"lastEvaluationDetail,omitempty"`
 }
 
 type Notification struct {
@@ -128,6 +152,8 @@ type StationOwner struct {
 	Plan                  string     `json:"plan"`
 	VerifiedAt            *time.Time `json:"verifiedAt"`
 	CreatedAt             time.Time  `json:"createdAt"`
+	// Role is the requesting user's role in the business's team.
+	Role string `json:"role,omitempty"`
 }
 
 type PasswordReset struct {
@@ -138,6 +164,279 @@ type PasswordReset struct {
 	CreatedAt time.Time `json:"createdAt"`
 }
 
+// Session is a device the user is signed in on. Tokens are never exposed.
+type Session struct {
+	ID          string    `json:"id"`
+	UserID      string    `json:"-"`
+	UserAgent   string    `json:"userAgent"`
+	DeviceName  string    `json:"deviceName,omitempty"`
+	IPAddress   string    `json:"ipAddress"`
+	Current     bool      `json:"current"`
+	MFAVerified bool      `json:"mfaVerified"`
+	CreatedAt   time.Time `json:"createdAt"`
+	LastUsedAt  time.Time `json:"lastUsedAt"`
+	ExpiresAt   time.Time `json:"expiresAt"`
+}
+
+// SubscriptionPlan is a paid plan users can subscribe to, granting its tier.
+type SubscriptionPlan struct {
+	ID              string `json:"id"`
+	Name            string `json:"name"`
+	Tier            string `json:"tier"`
+	BillingInterval string `json:"billingInterval"`
+	PriceCents      int    `json:"priceCents"`
+	Currency        string `json:"currency"`
+	TrialDays       int    `json:"trialDays"`
+}
+
+// Subscription statuses.
+const (
+	SubscriptionTrialing = "trialing"
+	SubscriptionActive   = "active"
+	SubscriptionPastDue  = "past_due"
+	SubscriptionCanceled = "canceled"
+	SubscriptionExpired  = "expired"
+)
+
+// Subscription is a user's subscription to a plan, kept in step with the
+// payment provider. Tier is the plan's tier.
+type Subscription struct {
+	ID                     string     `json:"id"`
+	UserID                 string     `json:"-"`
+	PlanID                 string     `json:"planId"`
+	Tier                   string     `json:"tier"`
+	Status                 string     `json:"status"`
+	Provider               string     `json:"-"`
+	ProviderSubscriptionID string     `json:"-"`
+	CurrentPeriodStart     time.Time  `json:"currentPeriodStart"`
+	CurrentPeriodEnd       time.Time  `json:"currentPeriodEnd"`
+	TrialEnd               *time.Time `json:"trialEnd,omitempty"`
+	GraceUntil             *time.Time `json:"graceUntil,omitempty"`
+	CanceledAt             *time.Time `json:"canceledAt,omitempty"`
+	CreatedAt              time.Time  `json:"createdAt"`
+	UpdatedAt              time.Time  `json:"updatedAt"`
+}
+
+// Station owner team roles. Owners run the team, managers can use every
+// station, and staff only have the permissions granted to them for each
+// station.
+const (
+	OwnerRoleOwner   = "owner"
+	OwnerRoleManager = "manager"
+	OwnerRoleStaff   = "staff"
+)
+
+// Permissions staff can be granted for a station. Owners and managers have
+// all of them for every station.
+const (
+	StationPermissionPublishPrices  = "publish_prices"
+	StationPermissionSendBroadcasts = "send_broadcasts"
+	StationPermissionViewAnalytics  = "view_analytics"
+)
+
+// StationPermissions are what a team member may do at one station.
+type StationPermissions struct {
+	StationID   string   `json:"stationId"`
+	StationName string   `json:"stationName,omitempty"`
+	Permissions []string `json:"permissions"`
+}
+
+// OwnerTeamMember is a user who belongs to a station owner business.
+// Stations is only set for staff. AccountHolder marks the user who set up
+// the business, who cannot be removed or change role.
+type OwnerTeamMember struct {
+	ID             string               `json:"id"`
+	StationOwnerID string               `json:"stationOwnerId"`
+	UserID         string               `json:"userId"`
+	Email          string               `json:"email"`
+	DisplayName    string               `json:"displayName"`
+	Role           string               `json:"role"`
+	AccountHolder  bool                 `json:"accountHolder"`
+	Stations       []StationPermissions `json:"stations"`
+	CreatedAt      time.Time            `json:"createdAt"`
+}
+
+// OwnerTeamInvitation invites someone, by email, to join a station owner
+// business with a role and, for staff, station permissions.
+type OwnerTeamInvitation struct {
+	ID             string               `json:"id"`
+	StationOwnerID string               `json:"-"`
+	BusinessName   string               `json:"businessName"`
+	Email          string               `json:"email"`
+	Role           string               `json:"role"`
+	Stations       []StationPermissions `json:"stations"`
+	InvitedBy      *string              `json:"invitedBy,omitempty"`
+	ExpiresAt      time.Time            `json:"expiresAt"`
+	CreatedAt      time.Time            `json:"createdAt"`
+}
+
+// API key scopes.
+const (
+	APIKeyScopePricesRead  = "prices:read"
+	APIKeyScopePricesWrite = "prices:write"
+)
+
+// OwnerAPIKey lets a station owner's point-of-sale or pricing system use the
+// integration API for some of their stations. Only a hash of the key is
+// stored; Prefix is its first few characters, to tell keys apart.
+type OwnerAPIKey struct {
+	ID             string     `json:"id"`
+	StationOwnerID string     `json:"-"`
+	OwnerUserID    string     `json:"-"`
+	Name           string     `json:"name"`
+	Prefix         string     `json:"prefix"`
+	Scopes         []string   `json:"scopes"`
+	StationIDs     []string   `json:"stationIds"`
+	CreatedAt      time.Time  `json:"createdAt"`
+	LastUsedAt     *time.Time `json:"lastUsedAt,omitempty"`
+	RevokedAt      *time.Time `json:"revokedAt,omitempty"`
+}
+
+// OwnerAPIKeyUsage is a request made with an API key.
+type OwnerAPIKeyUsage struct {
+	APIKeyID   string    `json:"-"`
+	Method     string    `json:"method"`
+	Path       string    `json:"path"`
+	StationID  string    `json:"stationId,omitempty"`
+	StatusCode int       `json:"statusCode"`
+	IPAddress  string    `json:"ipAddress"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+// StationProfile is the part of a station its owner can edit.
+type StationProfile struct {
+	Name           string   `json:"name"`
+	Brand          string   `json:"brand"`
+	Address        string   `json:"address"`
+	Latitude       float64  `json:"latitude"`
+	Longitude      float64  `json:"longitude"`
+	OperatingHours string   `json:"operatingHours"`
+	Amenities      []string `json:"amenities"`
+	Phone          string   `json:"phone"`
+	Website        string   `json:"website"`
+}
+
+// StationProfileChanges lists changes to a station's profile. Nil fields are
+// left as they are.
+type StationProfileChanges struct {
+	Name           *string  `json:"name,omitempty"`
+	Brand          *string  `json:"brand,omitempty"`
+	Address        *string  `json:"address,omitempty"`
+	Latitude       *float64 `json:"latitude,omitempty"`
+	Longitude      *float64 `json:"longitude,omitempty"`
+	OperatingHours *string  `json:"operatingHours,omitempty"`
+	Amenities      []string `json:"amenities,omitempty"`
+	Phone          *string  `json:"phone,omitempty"`
+	Website        *string  `json:"website,omitempty"`
+}
+
+// Station profile version sources.
+const (
+	StationVersionSourceBaseline = "baseline"
+	StationVersionSourceOwner    = "owner"
+	StationVersionSourceReview   = "review"
+	StationVersionSourceRollback = "rollback"
+)
+
+// StationProfileVersion is a station's profile after one change.
+type StationProfileVersion struct {
+	ID              string         `json:"id"`
+	StationID       string         `json:"stationId"`
+	Version         int            `json:"version"`
+	Profile         StationProfile `json:"profile"`
+	Source          string         `json:"source"`
+	ChangedBy       *string        `json:"changedBy,omitempty"`
+	ChangeRequestID *string        `json:"changeRequestId,omitempty"`
+	CreatedAt       time.Time      `json:"createdAt"`
+}
+
+// Station change request statuses.
+const (
+	StationChangePending    = "pending"
+	StationChangeApproved   = "approved"
+	StationChangeRejected   = "rejected"
+	StationChangeSuperseded = "superseded"
+)
+
+// StationChangeRequest is a brand or location change an owner asked for,
+// which applies once an admin approves it.
+type StationChangeRequest struct {
+	ID            string                `json:"id"`
+	StationID     string                `json:"stationId"`
+	StationName   string                `json:"stationName"`
+	RequestedBy   *string               `json:"requestedBy,omitempty"`
+	Changes       StationProfileChanges `json:"changes"`
+	Status        string                `json:"status"`
+	ReviewerNotes *string               `json:"reviewerNotes,omitempty"`
+	ReviewedBy    *string               `json:"reviewedBy,omitempty"`
+	ReviewedAt    *time.Time            `json:"reviewedAt,omitempty"`
+	CreatedAt     time.Time             `json:"createdAt"`
+}
+
+// Claim verification statuses. An expired claim lapsed before it was
+// re-verified; a renewed one was replaced by an approved re-verification.
+const (
+	ClaimVerificationPending  = "pending"
+	ClaimVerificationApproved = "approved"
+	ClaimVerificationRejected = "rejected"
+	ClaimVerificationExpired  = "expired"
+	ClaimVerificationRenewed  = "renewed"
+)
+
+// ClaimVerification is an owner's claim on a station and the evidence for
+// it. A re-verification is a claim that renews an earlier one.
+type ClaimVerification struct {
+	ID                 string     `json:"id"`
+	StationID          string     `json:"stationId"`
+	StationName        string     `json:"stationName"`
+	StationOwnerID     string     `json:"stationOwnerId"`
+	VerificationMethod string     `json:"verificationMethod"`
+	DocumentURLs       []string   `json:"documentUrls"`
+	PhoneNumber        string     `json:"phoneNumber,omitempty"`
+	Email              string     `json:"email,omitempty"`
+	Status             string     `json:"verificationStatus"`
+	RejectionReason    *string    `json:"rejectionReason,omitempty"`
+	RenewsID           *string    `json:"renewsId,omitempty"`
+	VerifiedAt         *time.Time `json:"verifiedAt,omitempty"`
+	VerifiedBy         *string    `json:"verifiedBy,omitempty"`
+	ExpiresAt          *time.Time `json:"expiresAt,omitempty"`
+	CreatedAt          time.Time  `json:"createdAt"`
+}
+
+// PriceComparison compares a station's price with its competitors' prices
+// for the same fuel type. Rank 1 is the cheapest, and stations with the same
+// price share a rank. A positive gap means the station is dearer. Cheapest,
+// median and the gaps are nil when no competitor has a price.
+type PriceComparison struct {
+	Price           float64  `json:"price"`
+	Rank            int      `json:"rank"`
+	CompetitorCount int      `json:"competitorCount"`
+	CheapestPrice   *float64 `json:"cheapestPrice"`
+	MedianPrice     *float64 `json:"medianPrice"`
+	GapToCheapest   *float64 `json:"gapToCheapest"`
+	GapToMedian     *float64 `json:"gapToMedian"`
+}
+
+// PriceBenchmarkDay is how a station's price compared at the end of a day.
+type PriceBenchmarkDay struct {
+	Date string `json:"date"`
+	PriceComparison
+}
+
+// PriceBenchmark compares an owner's price for a fuel type at a station with
+// nearby competitors now and on each day of a period, and counts how often
+// the competitors changed their price in that period.
+type PriceBenchmark struct {
+	StationID    string `json:"stationId"`
+	StationName  string `json:"stationName"`
+	FuelTypeID   string `json:"fuelTypeId"`
+	FuelTypeName string `json:"fuelTypeName"`
+	PriceComparison
+	CompetitorPriceChanges   int                 `json:"competitorPriceChanges"`
+	CompetitorsChangingPrice int                 `json:"competitorsChangingPrice"`
+	History                  []PriceBenchmarkDay `json:"history"`
+}
+
 type Broadcast struct {
 	ID              string    `json:"id"`
 	StationOwnerID  string    `json:"stationOwnerId"`
@@ -152,6 +451,8 @@ type Broadcast struct {
 	CreatedAt       time.Time `json:"createdAt"`
 	Views           int       `json:"views"`
 	Clicks          int       `json:"clicks"`
+	CreatedBy       *string   `json:"createdBy,omitempty"`
+	UpdatedBy       *string   `json:"updatedBy,omitempty"`
 }
 
 // FuelPriceData represents fuel price information for a station
@@ -162,6 +463,7 @@ type FuelPriceData struct {
 	Currency     string    `json:"currency" db:"currency"`
 	LastUpdated  time.Time `json:"lastUpdated" db:"last_updated_at"`
 	Verified     bool      `json:"verified" db:"verified"`
+	Source       string    `json:"source" db:"source"`
 }
 
 // StationsNearbyRequest represents the request payload for fetching nearby stations
@@ -0,0 +1,269 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+
+	"gaspeep/backend/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// Alert condition types supported by the trigger path.
+const (
+	AlertConditionPriceThreshold      = "price_threshold"
+	AlertConditionPercentBelowAverage = "percent_below_average"
+	AlertConditionNewCheapest         = "new_cheapest"
+	AlertConditionCycleBottom         = "cycle_bottom"
+	AlertConditionPriceRise           = "price_rise"
+)
+
+// Average scopes for percent_below_average alerts.
+const (
+	AverageScopeStation = "station"
+	AverageScopeArea    = "area"
+)
+
+// Alert target modes: which stations an alert watches.
+const (
+	AlertTargetRadius           = "radius"
+	AlertTargetStations         = "stations"
+	AlertTargetRadiusOrStations = "radius_or_stations"
+	AlertTargetFavourites       = "favourites"
+)
+
+// Re-arm rules: when an alert that has fired may fire again.
+const (
+	AlertRearmNone          = "none"
+	AlertRearmPriceRecovers = "price_recovers"
+)
+
+const (
+	maxAlertLookbackDays    = 90
+	maxAlertRadiusKm        = 50
+	maxAlertStations        = 20
+	maxAlertCooldownMinutes = 30 * 24 * 60
+	maxAlertTriggers        = 1000
+)
+
+// ErrInvalidAlertInput is returned when alert input fails condition or target validation.
+var ErrInvalidAlertInput = errors.New("invalid alert input")
+
+// DefaultLookbackDays returns the trailing window used when an alert does not specify one.
+func DefaultLookbackDays(conditionType string) int {
+	if conditionType == AlertConditionCycleBottom {
+		return 30
+	}
+	return 7
+}
+
+// Validate checks that the fields required by the alert's condition type and
+// target mode are present and in range.
+func (in CreateAlertInput) Validate() error {
+	conditionType := in.ConditionType
+	if conditionType == "" {
+		conditionType = AlertConditionPriceThreshold
+	}
+	// A missing lookback is filled in by DefaultLookbackDays
+	lookbackDays := in.LookbackDays
+	if lookbackDays == 0 {
+		lookbackDays = DefaultLookbackDays(conditionType)
+	}
+	if err := validateAlertCondition(conditionType, in.PriceThreshold, in.ConditionPercent, in.AverageScope, lookbackDays); err != nil {
+		return err
+	}
+
+	targetMode := in.TargetMode
+	if targetMode == "" {
+		targetMode = AlertTargetRadius
+	}
+	if err := validateAlertTarget(targetMode, in.Latitude, in.Longitude, in.RadiusKm, in.StationIDs); err != nil {
+		return err
+	}
+
+	if in.MaxTriggers != nil && *in.MaxTriggers == 0 {
+		return fmt.Errorf("%w: maxTriggers must be at least 1", ErrInvalidAlertInput)
+	}
+	return validateAlertRetrigger(in.CooldownMinutes, in.RearmRule, in.MaxTriggers)
+}
+
+// Validate checks the update merged over the alert's current state, so a partial
+// update cannot leave the alert with a condition or target it can no longer evaluate.
+func (in UpdateAlertInput) Validate(current models.Alert) error {
+	conditionType := current.ConditionType
+	typeChanged := false
+	if in.ConditionType != nil {
+		typeChanged = *in.ConditionType != current.ConditionType
+		conditionType = *in.ConditionType
+	}
+	priceThreshold := current.PriceThreshold
+	if in.PriceThreshold > 0 {
+		priceThreshold = in.PriceThreshold
+	}
+	// Switching type resets the type-specific fields unless they are supplied
+	// again, mirroring how PgAlertRepository.Update stores them.
+	percent := current.ConditionPercent
+	if in.ConditionPercent != nil {
+		percent = *in.ConditionPercent
+	} else if typeChanged {
+		percent = 0
+	}
+	scope := current.AverageScope
+	if in.AverageScope != nil {
+		scope = *in.AverageScope
+	} else if conditionType != AlertConditionPercentBelowAverage {
+		scope = ""
+	}
+	lookbackDays := current.LookbackDays
+	if in.LookbackDays != nil {
+		lookbackDays = *in.LookbackDays
+	}
+	if err := validateAlertCondition(conditionType, priceThreshold, percent, scope, lookbackDays); err != nil {
+		return err
+	}
+
+	targetMode := current.TargetMode
+	if in.TargetMode != nil {
+		targetMode = *in.TargetMode
+	}
+	radiusKm := current.RadiusKm
+	if in.RadiusKm > 0 {
+		radiusKm = in.RadiusKm
+	}
+	stationIDs := current.StationIDs
+	if in.StationIDs != nil {
+		stationIDs = in.StationIDs
+	} else if !targetUsesStations(targetMode) {
+		stationIDs = nil
+	}
+	if err := validateAlertTarget(targetMode, current.Latitude, current.Longitude, radiusKm, stationIDs); err != nil {
+		return err
+	}
+
+	rearmRule := ""
+	if in.RearmRule != nil {
+		rearmRule = *in.RearmRule
+		if rearmRule == "" {
+			return fmt.Errorf("%w: rearmRule must not be empty", ErrInvalidAlertInput)
+		}
+	}
+	return validateAlertRetrigger(in.CooldownMinutes, rearmRule, in.MaxTriggers)
+}
+
+// ChangesCondition reports whether the update touches any condition, target or
+// re-trigger field other than the price threshold and radius.
+func (in UpdateAlertInput) ChangesCondition() bool {
+	return in.ConditionType != nil || in.ConditionPercent != nil || in.AverageScope != nil || in.LookbackDays != nil ||
+		in.TargetMode != nil || in.StationIDs != nil ||
+		in.CooldownMinutes != nil || in.RearmRule != nil || in.MaxTriggers != nil
+}
+
+func validateAlertCondition(conditionType string, priceThreshold, percent float64, scope string, lookbackDays int) error {
+	if lookbackDays < 1 || lookbackDays > maxAlertLookbackDays {
+		return fmt.Errorf("%w: lookbackDays must be between 1 and %d", ErrInvalidAlertInput, maxAlertLookbackDays)
+	}
+
+	switch conditionType {
+	case AlertConditionPriceThreshold:
+		if priceThreshold <= 0 {
+			return fmt.Errorf("%w: priceThreshold is required for price_threshold alerts", ErrInvalidAlertInput)
+		}
+	case AlertConditionPercentBelowAverage:
+		if percent <= 0 || percent >= 100 {
+			return fmt.Errorf("%w: conditionPercent must be greater than 0 and less than 100", ErrInvalidAlertInput)
+		}
+		if scope != AverageScopeStation && scope != AverageScopeArea {
+			return fmt.Errorf("%w: averageScope must be station or area", ErrInvalidAlertInput)
+		}
+	case AlertConditionNewCheapest:
+		if percent != 0 {
+			return fmt.Errorf("%w: conditionPercent is not supported for new_cheapest alerts", ErrInvalidAlertInput)
+		}
+	case AlertConditionCycleBottom:
+		if percent < 0 || percent > 20 {
+			return fmt.Errorf("%w: conditionPercent tolerance must be between 0 and 20", ErrInvalidAlertInput)
+		}
+	case AlertConditionPriceRise:
+		if percent < 0 || percent >= 100 {
+			return fmt.Errorf("%w: conditionPercent must be between 0 and 100", ErrInvalidAlertInput)
+		}
+	default:
+		return fmt.Errorf("%w: unknown conditionType %q", ErrInvalidAlertInput, conditionType)
+	}
+
+	if scope != "" && conditionType != AlertConditionPercentBelowAverage {
+		return fmt.Errorf("%w: averageScope only applies to percent_below_average alerts", ErrInvalidAlertInput)
+	}
+
+	return nil
+}
+
+func targetUsesRadius(targetMode string) bool {
+	return targetMode == AlertTargetRadius || targetMode == AlertTargetRadiusOrStations
+}
+
+func targetUsesStations(targetMode string) bool {
+	return targetMode == AlertTargetStations || targetMode == AlertTargetRadiusOrStations
+}
+
+func validateAlertTarget(targetMode string, latitude, longitude float64, radiusKm int, stationIDs []string) error {
+	switch targetMode {
+	case AlertTargetRadius, AlertTargetStations, AlertTargetRadiusOrStations, AlertTargetFavourites:
+	default:
+		return fmt.Errorf("%w: unknown targetMode %q", ErrInvalidAlertInput, targetMode)
+	}
+
+	if targetUsesRadius(targetMode) {
+		if latitude == 0 && longitude == 0 {
+			return fmt.Errorf("%w: latitude and longitude are required for radius alerts", ErrInvalidAlertInput)
+		}
+		if latitude < -90 || latitude > 90 || longitude < -180 || longitude > 180 {
+			return fmt.Errorf("%w: latitude or longitude out of range", ErrInvalidAlertInput)
+		}
+		if radiusKm < 1 || radiusKm > maxAlertRadiusKm {
+			return fmt.Errorf("%w: radiusKm must be between 1 and %d", ErrInvalidAlertInput, maxAlertRadiusKm)
+		}
+	}
+
+	if !targetUsesStations(targetMode) {
+		if len(stationIDs) > 0 {
+			return fmt.Errorf("%w: stationIds only apply to stations or radius_or_stations alerts", ErrInvalidAlertInput)
+		}
+		return nil
+	}
+
+	if len(stationIDs) == 0 {
+		return fmt.Errorf("%w: stationIds are required for %s alerts", ErrInvalidAlertInput, targetMode)
+	}
+	if len(stationIDs) > maxAlertStations {
+		return fmt.Errorf("%w: at most %d stationIds are allowed", ErrInvalidAlertInput, maxAlertStations)
+	}
+	seen := make(map[string]struct{}, len(stationIDs))
+	for _, id := range stationIDs {
+		if _, err := uuid.Parse(id); err != nil {
+			return fmt.Errorf("%w: invalid stationId %q", ErrInvalidAlertInput, id)
+		}
+		if _, ok := seen[id]; ok {
+			return fmt.Errorf("%w: duplicate stationId %q", ErrInvalidAlertInput, id)
+		}
+		seen[id] = struct{}{}
+	}
+	return nil
+}
+
+// validateAlertRetrigger checks cooldown, re-arm and trigger-limit settings. An
+// empty rearmRule means the default and a zero maxTriggers means no limit.
+func validateAlertRetrigger(cooldownMinutes *int, rearmRule string, maxTriggers *int) error {
+	if cooldownMinutes != nil && (*cooldownMinutes < 0 || *cooldownMinutes > maxAlertCooldownMinutes) {
+		return fmt.Errorf("%w: cooldownMinutes must be between 0 and %d", ErrInvalidAlertInput, maxAlertCooldownMinutes)
+	}
+	switch rearmRule {
+	case "", AlertRearmNone, AlertRearmPriceRecovers:
+	default:
+		return fmt.Errorf("%w: unknown rearmRule %q", ErrInvalidAlertInput, rearmRule)
+	}
+	if maxTriggers != nil && (*maxTriggers < 0 || *maxTriggers > maxAlertTriggers) {
+		return fmt.Errorf("%w: maxTriggers must be between 1 and %d", ErrInvalidAlertInput, maxAlertTriggers)
+	}
+	return nil
+}
@@ -7,26 +7,45 @@ import (
 
 // CreateAlertInput holds parameters for creating an alert.
 type CreateAlertInput struct {
-	FuelTypeID     string
-	PriceThreshold float64
-	Latitude       float64
-	Longitude      float64
-	RadiusKm       int
-	AlertName      string
-	RecurrenceType string
-	NotifyViaPush  bool
-	NotifyViaEmail bool
+	FuelTypeID       string
+	PriceThreshold   float64
+	ConditionType    string
+	ConditionPercent float64
+	AverageScope     string
+	LookbackDays     int
+	TargetMode       string
+	StationIDs       []string
+	Latitude         float64
+	Longitude        float64
+	RadiusKm         int
+	AlertName        string
+	RecurrenceType   string
+	CooldownMinutes  *int
+	RearmRule        string
+	MaxTriggers      *int
+	NotifyViaPush    bool
+	NotifyViaEmail   bool
 }
 
-// UpdateAlertInput holds parameters for updating an alert.
+// UpdateAlertInput holds parameters for updating an alert. A non-nil StationIDs
+// replaces the alert's pinned stations, and a MaxTriggers of 0 removes the limit.
 type UpdateAlertInput struct {
-	PriceThreshold float64
-	RadiusKm       int
-	AlertName      string
-	RecurrenceType *string
-	NotifyViaPush  *bool
-	NotifyViaEmail *bool
-	IsActive       *bool
+	PriceThreshold   float64
+	ConditionType    *string
+	ConditionPercent *float64
+	AverageScope     *string
+	LookbackDays     *int
+	TargetMode       *string
+	StationIDs       []string
+	RadiusKm         int
+	AlertName        string
+	RecurrenceType   *string
+	CooldownMinutes  *int
+	RearmRule        *string
+	MaxTriggers      *int
+	NotifyViaPush    *bool
+	NotifyViaEmail   *bool
+	IsActive         *bool
 }
 
 // PriceContextInput holds request parameters for alert price context.
@@ -62,7 +81,92 @@ type MatchingStationResult struct {
 	LastUpdated    *time.Time `json:"lastUpdated"`
 }
 
-// TriggeredAlertResult holds alert metadata returned after a trigger event is recorded.
+// PriceHistoryInput selects the approved price reports a draft alert would have
+// watched since a point in time.
+type PriceHistoryInput struct {
+	UserID     string
+	FuelTypeID string
+	TargetMode string
+	StationIDs []string
+	Latitude   float64
+	Longitude  float64
+	RadiusKm   int
+	Since      time.Time
+}
+
+// PriceHistoryPoint is one approved price report at a station.
+type PriceHistoryPoint struct {
+	StationID   string
+	StationName string
+	Price       float64
+	ReportedAt  time.Time
+}
+
+// PriceHistoryResult holds price reports in the order they were made, and the
+// user's time zone for replaying daily alert rules.
+type PriceHistoryResult struct {
+	TimeZone string
+	Points   []PriceHistoryPoint
+}
+
+// AlertTriggerCandidate holds an active alert covering a changed price, its
+// re-trigger state, and the market metrics its condition type is evaluated
+// against. Metrics that do not apply to the alert's condition type are left nil.
+// EventID identifies the price change the candidate was matched against when
+// candidates are fetched for a batch of changes.
+type AlertTriggerCandidate struct {
+	EventID                string
+	AlertID                string
+	UserID                 string
+	AlertName              string
+	ConditionType          string
+	PriceThreshold         float64
+	ConditionPercent       float64
+	AverageScope           string
+	LookbackDays           int
+	RecurrenceType         string
+	TriggerCount           int
+	LastTriggeredAt        *time.Time
+	LastTriggeredStationID *string
+	CooldownMinutes        *int
+	RearmRule              string
+	MaxTriggers            *int
+	Armed                  bool
+	TimeZone               string
+	EvaluatedAt            time.Time
+	PreviousPrice          *float64
+	StationAverage         *float64
+	AreaAverage            *float64
+	AreaTrailingMin        *float64
+	AreaCheapestOther      *float64
+}
+
+// Reasons recorded on an alert after each evaluation.
+const (
+	AlertReasonTriggered       = "triggered"
+	AlertReasonConditionNotMet = "condition_not_met"
+	AlertReasonCooldown        = "cooldown"
+	AlertReasonFiredToday      = "already_fired_today"
+	AlertReasonOneOffFired     = "one_off_already_fired"
+	AlertReasonMaxTriggers     = "max_triggers_reached"
+	AlertReasonAwaitingRearm   = "awaiting_rearm"
+	AlertReasonRearmed         = "rearmed"
+)
+
+// AlertEvaluation is the outcome of evaluating one alert against a price change.
+// ExpectedTriggerCount guards against recording the same trigger twice when
+// evaluations race.
+type AlertEvaluation struct {
+	AlertID              string
+	Triggered            bool
+	Rearm                bool
+	ExpectedTriggerCount int
+	Reason               string
+	Detail               string
+}
+
+// TriggeredAlertResult holds alert metadata returned after a trigger event is
+// recorded, including what is needed to notify the user.
 type TriggeredAlertResult struct {
 	AlertID        string
 	UserID         string
@@ -70,15 +174,23 @@ type TriggeredAlertResult struct {
 	RecurrenceType string
 	NotifyViaPush  bool
 	NotifyViaEmail bool
+	UserEmail      string
+	StationName    string
+	FuelTypeName   string
+	Currency       string
 }
 
 // AlertRepository defines data-access operations for alerts.
 type AlertRepository interface {
 	Create(userID string, input CreateAlertInput) (*models.Alert, error)
 	GetByUserID(userID string) ([]models.Alert, error)
+	GetByID(id, userID string) (*models.Alert, error)
 	Update(id, userID string, input UpdateAlertInput) (string, error)
 	Delete(id, userID string) (bool, error)
 	GetPriceContext(input PriceContextInput) (*PriceContextResult, error)
 	GetMatchingStations(alertID, userID string) ([]MatchingStationResult, error)
-	RecordTriggersForPrice(stationID, fuelTypeID string, price float64) ([]TriggeredAlertResult, error)
+	GetPriceHistory(input PriceHistoryInput) (*PriceHistoryResult, error)
+	GetTriggerCandidates(stationID, fuelTypeID string) ([]AlertTriggerCandidate, error)
+	GetTriggerCandidatesForChanges(events []PriceChangeEvent) ([]AlertTriggerCandidate, error)
+	RecordEvaluations(eventID, stationID string, price float64, evaluations []AlertEvaluation) ([]TriggeredAlertResult, error)
 }
@@ -0,0 +1,23 @@
+package repository
+
+// Actions recorded in the audit log.
+const (
+	AuditMagicLinkRequested = "magic_link.requested"
+	AuditMagicLinkRedeemed  = "magic_link.redeemed"
+	AuditMagicLinkRejected  = "magic_link.rejected"
+)
+
+// AuditLogEntry is a security-relevant event. UserID is empty when no account
+// is known, e.g. for a request naming an unregistered email.
+type AuditLogEntry struct {
+	UserID    string
+	Action    string
+	IPAddress string
+	UserAgent string
+	Details   map[string]string
+}
+
+// AuditLogRepository records security-relevant events for later review.
+type AuditLogRepository interface {
+	Record(entry AuditLogEntry) error
+}
@@ -14,6 +14,8 @@ type CreateBroadcastInput struct {
 	StartDate       time.Time
 	EndDate         time.Time
 	TargetFuelTypes string
+	// CreatedBy is the team member creating the broadcast.
+	CreatedBy string
 }
 
 // UpdateBroadcastInput holds parameters for updating a broadcast.
@@ -25,6 +27,8 @@ type UpdateBroadcastInput struct {
 	EndDate         time.Time
 	BroadcastStatus string
 	TargetFuelTypes string
+	// UpdatedBy is the team member making the change.
+	UpdatedBy string
 }
 
 // BroadcastRepository defines data-access operations for broadcasts.
@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"gaspeep/backend/internal/models"
+)
+
+var (
+	// ErrClaimNotPending is returned when reviewing a claim that has already
+	// been reviewed.
+	ErrClaimNotPending = errors.New("claim verification not pending")
+	// ErrNoClaimToRenew is returned when an owner re-verifies a station they
+	// have no approved or expired claim on.
+	ErrNoClaimToRenew = errors.New("no station claim to renew")
+	// ErrClaimRenewalPending is returned when an owner re-verifies a station
+	// while an earlier request is still waiting for review.
+	ErrClaimRenewalPending = errors.New("station re-verification already pending")
+)
+
+// ClaimEvidence is the evidence an owner gives that they run a station.
+type ClaimEvidence struct {
+	VerificationMethod string
+	DocumentURLs       []string
+	PhoneNumber        string
+	Email              string
+}
+
+// ExpiringClaim is an approved claim near or past its expiry, with the owner
+// to tell about it.
+type ExpiringClaim struct {
+	ID               string
+	StationID        string
+	StationName      string
+	OwnerUserID      string
+	OwnerEmail       string
+	ExpiresAt        time.Time
+	ReminderDaysSent *int
+}
+
+// ClaimVerificationRepository defines data-access operations for reviewing,
+// renewing and expiring station claims.
+type ClaimVerificationRepository interface {
+	// ListClaims returns claims with status, oldest first.
+	ListClaims(status string) ([]models.ClaimVerification, error)
+	// CreateRenewal records a pending re-verification of the latest approved
+	// or expired claim on a station by the business the user owns or
+	// manages. It returns ErrNoClaimToRenew or ErrClaimRenewalPending.
+	CreateRenewal(userID, stationID string, evidence ClaimEvidence) (*models.ClaimVerification, error)
+	// ApproveClaim approves a pending claim on behalf of the reviewer until
+	// expiresAt and marks the station verified. Earlier claims it renews
+	// become renewed. It returns sql.ErrNoRows or ErrClaimNotPending.
+	ApproveClaim(id, reviewerID string, expiresAt time.Time) (*models.ClaimVerification, error)
+	// RejectClaim rejects a pending claim on behalf of the reviewer.
+	// Rejecting a re-verification leaves the claim it renews as it is. It
+	// returns sql.ErrNoRows or ErrClaimNotPending.
+	RejectClaim(id, reviewerID, reason string) error
+	// ListExpiring returns approved claims on stations their owners still
+	// hold that expire before before and are not being re-verified, soonest
+	// first.
+	ListExpiring(before time.Time) ([]ExpiringClaim, error)
+	// MarkReminderSent records the reminder, in days before expiry, last
+	// sent for a claim.
+	MarkReminderSent(id string, days int) error
+	// ExpireLapsed expires approved claims whose expiry is before now and
+	// marks their stations unverified. It returns those whose owners still
+	// hold the station.
+	ExpireLapsed(now time.Time) ([]ExpiringClaim, error)
+	// HasActiveClaim reports whether the user belongs to a business holding
+	// an approved, unexpired claim on the station, with access to it.
+	HasActiveClaim(userID, stationID string) (bool, error)
+}
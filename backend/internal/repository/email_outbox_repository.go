@@ -0,0 +1,110 @@
+package repository
+
+import "time"
+
+// Email message statuses. A message is sending while a worker holds its lease.
+// Complained only appears in the status log; the message stays sent.
+const (
+	EmailStatusQueued     = "queued"
+	EmailStatusSending    = "sending"
+	EmailStatusSent       = "sent"
+	EmailStatusFailed     = "failed"
+	EmailStatusBounced    = "bounced"
+	EmailStatusComplained = "complained"
+)
+
+// Delivery event types reported by the email provider.
+const (
+	EmailEventBounce    = "bounce"
+	EmailEventComplaint = "complaint"
+)
+
+// EnqueueEmailInput holds the data for queuing a rendered email. UserID may be
+// empty for mail to addresses without an account. ListUnsubscribeURL is the
+// one-click unsubscribe URL for emails recipients can opt out of.
+type EnqueueEmailInput struct {
+	UserID             string
+	ToEmail            string
+	Template           string
+	Subject            string
+	HTMLBody           string
+	TextBody           string
+	ListUnsubscribeURL string
+}
+
+// QueuedEmail is a message claimed for delivery. Suppressed is set when the
+// recipient was added to the suppression list after the message was queued.
+type QueuedEmail struct {
+	ID                 string
+	ToEmail            string
+	Subject            string
+	HTMLBody           string
+	TextBody           string
+	ListUnsubscribeURL string
+	Attempts           int
+	Suppressed         bool
+}
+
+// EmailStatusEvent is one entry in a message's status log.
+type EmailStatusEvent struct {
+	Status    string    `json:"status"`
+	Detail    *string   `json:"detail,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// EmailMessageLog describes a queued or delivered message and its status history.
+type EmailMessageLog struct {
+	ID        string             `json:"id"`
+	UserID    *string            `json:"userId,omitempty"`
+	ToEmail   string             `json:"toEmail"`
+	Template  string             `json:"template"`
+	Subject   string             `json:"subject"`
+	Status    string             `json:"status"`
+	Attempts  int                `json:"attempts"`
+	LastError *string            `json:"lastError,omitempty"`
+	CreatedAt time.Time          `json:"createdAt"`
+	SentAt    *time.Time         `json:"sentAt,omitempty"`
+	Events    []EmailStatusEvent `json:"events"`
+}
+
+// EmailMessageFilter selects messages by recipient. Messages match if either
+// field matches.
+type EmailMessageFilter struct {
+	UserID string
+	Email  string
+}
+
+// EmailDeliveryEvent is a bounce or complaint reported by the email provider.
+// MessageID is the outbox ID echoed back from the X-Gaspeep-Message-ID header,
+// when the provider includes it.
+type EmailDeliveryEvent struct {
+	Type      string
+	MessageID string
+	Email     string
+	Permanent bool
+	Detail    string
+}
+
+// EmailOutboxRepository defines operations for queuing, delivering and
+// auditing outgoing email.
+type EmailOutboxRepository interface {
+	// Enqueue stores a message for delivery. Messages to suppressed addresses
+	// are stored as failed and never sent.
+	Enqueue(input EnqueueEmailInput) (string, error)
+	// Claim leases up to limit due messages to the caller for the lease duration.
+	Claim(limit int, lease time.Duration) ([]QueuedEmail, error)
+	// Renew extends a claimed message's lease just before it is sent. It
+	// returns false if the claim that made attempt no longer holds the
+	// message, because it was settled or claimed again after its lease
+	// lapsed.
+	Renew(id string, attempt int, lease time.Duration) (bool, error)
+	MarkSent(id string) error
+	// Retry releases a claimed message to be attempted again after delay.
+	Retry(id, lastError string, delay time.Duration) error
+	// Fail marks a claimed message as permanently failed.
+	Fail(id, lastError string) error
+	// RecordDeliveryEvent marks the reported message bounced and suppresses the
+	// address for complaints and permanent bounces.
+	RecordDeliveryEvent(event EmailDeliveryEvent) error
+	ListMessages(filter EmailMessageFilter, limit, offset int) ([]EmailMessageLog, int, error)
+}
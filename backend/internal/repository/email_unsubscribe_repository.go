@@ -0,0 +1,32 @@
+package repository
+
+// Email unsubscribe categories. Single-alert and single-station opt-outs carry
+// the alert or station ID as their scope.
+const (
+	UnsubscribeCategoryAlert             = "alert"
+	UnsubscribeCategoryAllAlerts         = "all_alerts"
+	UnsubscribeCategoryStationBroadcasts = "station_broadcasts"
+	UnsubscribeCategoryAllBroadcasts     = "all_broadcasts"
+)
+
+// How an unsubscribe was made: a mail client's RFC 8058 one-click POST, or a
+// recipient following the link in the email.
+const (
+	UnsubscribeMethodOneClick = "one_click"
+	UnsubscribeMethodLink     = "link"
+)
+
+// UnsubscribeScope identifies a kind of email a user can opt out of. ScopeID
+// is empty for whole categories.
+type UnsubscribeScope struct {
+	Category string
+	ScopeID  string
+}
+
+// EmailUnsubscribeRepository defines data-access operations for email opt-outs.
+type EmailUnsubscribeRepository interface {
+	// Record stores an opt-out. Repeating an opt-out is not an error.
+	Record(userID string, scope UnsubscribeScope, method string) error
+	// IsUnsubscribed reports whether the user has opted out of any of scopes.
+	IsUnsubscribed(userID string, scopes ...UnsubscribeScope) (bool, error)
+}
@@ -0,0 +1,28 @@
+package repository
+
+import "time"
+
+// EmailVerificationRepository defines data-access operations for email
+// verification tokens. Tokens are looked up by their SHA-256 hash.
+type EmailVerificationRepository interface {
+	Create(userID, email, tokenHash string, expiresAt time.Time) error
+	// CountIssuedSince returns how many tokens the user has been issued since
+	// the given time, and when the latest was issued.
+	CountIssuedSince(userID string, since time.Time) (int, *time.Time, error)
+	// Consume uses up an unexpired token and marks the address it was issued
+	// for as verified. It returns sql.ErrNoRows when the token is unknown,
+	// used, expired, or the user has since changed their email.
+	Consume(tokenHash string) (*ConsumedEmailVerification, error)
+	// DeleteExpiredBefore deletes tokens that expired before cutoff and
+	// returns how many there were.
+	DeleteExpiredBefore(cutoff time.Time) (int64, error)
+}
+
+// ConsumedEmailVerification describes the user whose email a token verified.
+// WasVerified is true when the address had already been verified.
+type ConsumedEmailVerification struct {
+	UserID      string
+	Email       string
+	DisplayName string
+	WasVerified bool
+}
@@ -0,0 +1,46 @@
+package repository
+
+import "time"
+
+// FavouriteStationPrice holds a current fuel price at a favourite station.
+type FavouriteStationPrice struct {
+	FuelTypeID         string     `json:"fuelTypeId"`
+	FuelTypeName       string     `json:"fuelTypeName"`
+	Price              float64    `json:"price"`
+	Currency           string     `json:"currency"`
+	Unit               string     `json:"unit"`
+	LastUpdatedAt      *time.Time `json:"lastUpdatedAt"`
+	VerificationStatus string     `json:"verificationStatus"`
+}
+
+// FavouriteStationResult holds a user's favourite station with its current prices.
+type FavouriteStationResult struct {
+	StationID string                  `json:"stationId"`
+	Name      string                  `json:"name"`
+	Brand     string                  `json:"brand"`
+	Address   string                  `json:"address"`
+	Latitude  float64                 `json:"latitude"`
+	Longitude float64                 `json:"longitude"`
+	AddedAt   time.Time               `json:"addedAt"`
+	Prices    []FavouriteStationPrice `json:"prices"`
+}
+
+// FavouritePriceResult holds one fuel type's current price at a favourite station,
+// for the quick price comparison view.
+type FavouritePriceResult struct {
+	StationID     string     `json:"stationId"`
+	StationName   string     `json:"stationName"`
+	StationBrand  string     `json:"stationBrand"`
+	Price         float64    `json:"price"`
+	Currency      string     `json:"currency"`
+	Unit          string     `json:"unit"`
+	LastUpdatedAt *time.Time `json:"lastUpdatedAt"`
+}
+
+// FavouriteStationRepository defines data-access operations for favourite stations.
+type FavouriteStationRepository interface {
+	Add(userID, stationID string) error
+	Remove(userID, stationID string) (bool, error)
+	GetByUserID(userID string) ([]FavouriteStationResult, error)
+	GetPrices(userID, fuelTypeID string) ([]FavouritePriceResult, error)
+}
@@ -15,6 +15,7 @@ type FuelPriceResult struct {
 	LastUpdatedAt      *time.Time `json:"lastUpdatedAt"`
 	VerificationStatus string     `json:"verificationStatus"`
 	ConfirmationCount  int        `json:"confirmationCount"`
+	Source             string     `json:"source"`
 	DistanceKm         *float64   `json:"distanceKm,omitempty"`
 }
 
@@ -29,6 +30,7 @@ type StationPriceResult struct {
 	LastUpdatedAt       *time.Time `json:"lastUpdatedAt"`
 	VerificationStatus  string     `json:"verificationStatus"`
 	ConfirmationCount   int        `json:"confirmationCount"`
+	Source              string     `json:"source"`
 	FuelTypeName        string     `json:"fuelTypeName"`
 	FuelTypeDisplayName string     `json:"fuelTypeDisplayName"`
 	FuelTypeColorCode   string     `json:"fuelTypeColorCode"`
@@ -45,6 +47,7 @@ type CheapestPriceResult struct {
 	LastUpdatedAt      *time.Time `json:"lastUpdatedAt"`
 	VerificationStatus string     `json:"verificationStatus"`
 	ConfirmationCount  int        `json:"confirmationCount"`
+	Source             string     `json:"source"`
 	StationName        string     `json:"stationName"`
 	StationBrand       string     `json:"stationBrand"`
 	Latitude           float64    `json:"latitude"`
@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"sync"
+	"time"
+)
+
+// LoginAttempts is the failed sign-in record for a key, such as an account or
+// a client IP address. LockedUntil is zero when the key is not locked.
+type LoginAttempts struct {
+	Failures      int
+	LastFailureAt time.Time
+	LockedUntil   time.Time
+}
+
+// LoginAttemptRepository stores failed sign-in attempts. Failures are counted
+// in a window that starts with the first failure.
+type LoginAttemptRepository interface {
+	// Get returns the key's record, which is empty if it has none.
+	Get(key string) (LoginAttempts, error)
+	// RecordFailure counts a failure for key, starting a new window when the
+	// current one is older than window, and returns the updated record.
+	RecordFailure(key string, window time.Duration) (LoginAttempts, error)
+	// Lock locks key until the given time and clears its failures.
+	Lock(key string, until time.Time) error
+	// Reset clears the key's failures and any lock.
+	Reset(key string) error
+}
+
+// memoryLoginAttemptSweepSize is the number of tracked keys above which
+// expired records are dropped.
+const memoryLoginAttemptSweepSize = 1024
+
+// MemoryLoginAttemptRepository keeps attempts in memory, so each API
+// instance counts separately. Use PgLoginAttemptRepository to share them.
+type MemoryLoginAttemptRepository struct {
+	mu       sync.Mutex
+	attempts map[string]*memoryLoginAttempts
+	now      func() time.Time
+}
+
+type memoryLoginAttempts struct {
+	LoginAttempts
+	windowStartedAt time.Time
+	window          time.Duration
+}
+
+func NewMemoryLoginAttemptRepository() *MemoryLoginAttemptRepository {
+	return &MemoryLoginAttemptRepository{attempts: make(map[string]*memoryLoginAttempts), now: time.Now}
+}
+
+func (r *MemoryLoginAttemptRepository) Get(key string) (LoginAttempts, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if a, ok := r.attempts[key]; ok {
+		return a.LoginAttempts, nil
+	}
+	return LoginAttempts{}, nil
+}
+
+func (r *MemoryLoginAttemptRepository) RecordFailure(key string, window time.Duration) (LoginAttempts, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+	a, ok := r.attempts[key]
+	if !ok {
+		if len(r.attempts) >= memoryLoginAttemptSweepSize {
+			r.sweep(now)
+		}
+		a = &memoryLoginAttempts{windowStartedAt: now}
+		r.attempts[key] = a
+	}
+	if now.Sub(a.windowStartedAt) > window {
+		a.Failures = 0
+		a.windowStartedAt = now
+	}
+	a.Failures++
+	a.LastFailureAt = now
+	a.window = window
+	return a.LoginAttempts, nil
+}
+
+func (r *MemoryLoginAttemptRepository) Lock(key string, until time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+	a, ok := r.attempts[key]
+	if !ok {
+		a = &memoryLoginAttempts{LoginAttempts: LoginAttempts{LastFailureAt: now}}
+		r.attempts[key] = a
+	}
+	a.Failures = 0
+	a.windowStartedAt = now
+	a.LockedUntil = until
+	return nil
+}
+
+func (r *MemoryLoginAttemptRepository) Reset(key string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.attempts, key)
+	return nil
+}
+
+// sweep drops records whose window and lock have both expired.
+func (r *MemoryLoginAttemptRepository) sweep(now time.Time) {
+	for key, a := range r.attempts {
+		if now.Sub(a.windowStartedAt) > a.window && !now.Before(a.LockedUntil) {
+			delete(r.attempts, key)
+		}
+	}
+}
+
+var _ LoginAttemptRepository = (*MemoryLoginAttemptRepository)(nil)
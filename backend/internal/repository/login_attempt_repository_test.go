@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryLoginAttemptRepository(t *testing.T) {
+	now := time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC)
+	repo := NewMemoryLoginAttemptRepository()
+	repo.now = func() time.Time { return now }
+
+	a, err := repo.Get("account:a@example.com")
+	require.NoError(t, err)
+	assert.Zero(t, a.Failures)
+
+	for i := 1; i <= 3; i++ {
+		a, err = repo.RecordFailure("account:a@example.com", 15*time.Minute)
+		require.NoError(t, err)
+		assert.Equal(t, i, a.Failures)
+		now = now.Add(time.Minute)
+	}
+
+	// Failures outside the window start a new count
+	now = now.Add(15 * time.Minute)
+	a, err = repo.RecordFailure("account:a@example.com", 15*time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, 1, a.Failures)
+
+	until := now.Add(15 * time.Minute)
+	require.NoError(t, repo.Lock("account:a@example.com", until))
+	a, err = repo.Get("account:a@example.com")
+	require.NoError(t, err)
+	assert.Zero(t, a.Failures)
+	assert.Equal(t, until, a.LockedUntil)
+
+	require.NoError(t, repo.Reset("account:a@example.com"))
+	a, err = repo.Get("account:a@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, LoginAttempts{}, a)
+}
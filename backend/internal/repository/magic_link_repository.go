@@ -0,0 +1,22 @@
+package repository
+
+import "time"
+
+// MagicLinkRepository defines data-access operations for passwordless sign-in
+// links. Tokens, and the nonces binding them to a browser, are looked up by
+// their SHA-256 hashes.
+type MagicLinkRepository interface {
+	Create(userID, email, tokenHash, nonceHash string, expiresAt time.Time) error
+	// CountIssuedSince returns how many links the user has been issued since
+	// the given time, and when the latest was issued.
+	CountIssuedSince(userID string, since time.Time) (int, *time.Time, error)
+	// Consume uses up an unexpired token requested with nonceHash and returns
+	// the ID of the user it signs in. Following a link proves the user owns
+	// the address, so it is marked verified. It returns sql.ErrNoRows when the
+	// token is unknown, used, expired, was requested from another browser, or
+	// the user has since changed their email.
+	Consume(tokenHash, nonceHash string) (string, error)
+	// DeleteExpiredBefore deletes tokens that expired before cutoff and
+	// returns how many there were.
+	DeleteExpiredBefore(cutoff time.Time) (int64, error)
+}
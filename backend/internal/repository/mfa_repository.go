@@ -0,0 +1,41 @@
+package repository
+
+import "time"
+
+// TOTPCredential is a user's authenticator app secret. ConfirmedAt is nil
+// until the user has entered a code from it.
+type TOTPCredential struct {
+	UserID       string
+	Secret       string
+	ConfirmedAt  *time.Time
+	LastUsedStep int64
+}
+
+// MFARepository defines data-access operations for two-factor
+// authentication. Recovery codes are looked up by their SHA-256 hash.
+type MFARepository interface {
+	// GetTOTP returns sql.ErrNoRows when the user has no secret.
+	GetTOTP(userID string) (*TOTPCredential, error)
+	// SaveTOTPSecret stores an unconfirmed secret, replacing any earlier
+	// unconfirmed one.
+	SaveTOTPSecret(userID, secret string) error
+	// ConfirmTOTP marks the user's secret as confirmed by the code for step.
+	// It returns false when there is no unconfirmed secret.
+	ConfirmTOTP(userID string, step int64) (bool, error)
+	// UseTOTPStep records that the code for step was used. It returns false
+	// when a code for that step or a later one has already been used.
+	UseTOTPStep(userID string, step int64) (bool, error)
+	// DeleteTOTP removes the user's secret and recovery codes.
+	DeleteTOTP(userID string) error
+	// ReplaceRecoveryCodes discards the user's recovery codes and stores new
+	// ones.
+	ReplaceRecoveryCodes(userID string, codeHashes []string) error
+	// UseRecoveryCode marks an unused recovery code as used. It returns false
+	// when the user has no such unused code.
+	UseRecoveryCode(userID, codeHash string) (bool, error)
+	// CountRecoveryCodes returns how many unused recovery codes the user has.
+	CountRecoveryCodes(userID string) (int, error)
+	// GetUserRoles returns the user's staff role, if any, and "owner" when
+	// they are a station owner.
+	GetUserRoles(userID string) ([]string, error)
+}
@@ -0,0 +1,51 @@
+package repository
+
+import "time"
+
+// BenchmarkedPrice is the current price of a fuel type at one of an owner's
+// stations, with the current prices of the nearby competitors that sell it.
+// CompetitorPrices[i] is the price at CompetitorIDs[i].
+type BenchmarkedPrice struct {
+	StationID        string
+	StationName      string
+	FuelTypeID       string
+	FuelTypeName     string
+	Price            float64
+	CompetitorIDs    []string
+	CompetitorPrices []float64
+}
+
+// PricePoint is a station's price for a fuel type from RecordedAt until it
+// next changed. PreviousPrice is nil for a station's first price.
+type PricePoint struct {
+	StationID     string
+	FuelTypeID    string
+	Price         float64
+	PreviousPrice *float64
+	RecordedAt    time.Time
+}
+
+// OwnerBroadcastStats sums up the broadcasts for an owner's stations.
+// Delivered and Opened come from the broadcasts' recorded analytics.
+type OwnerBroadcastStats struct {
+	ActiveBroadcasts   int
+	BroadcastsThisWeek int
+	DeliveredThisMonth int
+	OpenedThisMonth    int
+}
+
+// OwnerAnalyticsRepository defines data-access operations for station owner
+// analytics. Only stations where the user may view analytics are included.
+type OwnerAnalyticsRepository interface {
+	// BenchmarkedPrices returns the fuel types priced at the user's stations
+	// with an active claim, ordered by station and fuel type. Competitors are
+	// the stations within radiusKm that belong to no business or another one.
+	BenchmarkedPrices(userID string, radiusKm float64) ([]BenchmarkedPrice, error)
+	// PriceHistory returns the price changes for the stations' fuel types
+	// since the given time, each preceded by the last price before it,
+	// oldest first.
+	PriceHistory(stationIDs, fuelTypeIDs []string, since time.Time) ([]PricePoint, error)
+	// BroadcastStats counts the user's active broadcasts and those sent since
+	// weekStart, and sums their deliveries and opens since monthStart.
+	BroadcastStats(userID string, weekStart, monthStart time.Time) (*OwnerBroadcastStats, error)
+}
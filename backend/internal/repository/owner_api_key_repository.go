@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"time"
+
+	"gaspeep/backend/internal/models"
+)
+
+// IdempotentRequest is an earlier request made with the same API key and
+// idempotency key. Response is nil while that request is still being
+// handled.
+type IdempotentRequest struct {
+	RequestHash string
+	Response    []byte
+}
+
+// OwnerAPIKeyRepository defines data-access operations for station owners'
+// API keys, the requests made with them, and idempotency keys. Keys are
+// looked up by their SHA-256 hashes.
+type OwnerAPIKeyRepository interface {
+	// Create stores key, which must have its StationOwnerID set, and fills in
+	// its ID and CreatedAt.
+	Create(key *models.OwnerAPIKey, keyHash, createdBy string) error
+	// ListByOwner returns the owner's keys, revoked ones included, newest
+	// first.
+	ListByOwner(stationOwnerID string) ([]models.OwnerAPIKey, error)
+	// GetByID returns one of the owner's keys, or sql.ErrNoRows.
+	GetByID(stationOwnerID, keyID string) (*models.OwnerAPIKey, error)
+	// GetByHash returns the key with keyHash unless it has been revoked. It
+	// returns sql.ErrNoRows otherwise.
+	GetByHash(keyHash string) (*models.OwnerAPIKey, error)
+	// Revoke revokes one of the owner's keys. It returns sql.ErrNoRows if the
+	// owner has no such key or it is already revoked.
+	Revoke(stationOwnerID, keyID string) error
+	// RecordUsage logs a request made with a key and marks the key used.
+	RecordUsage(usage models.OwnerAPIKeyUsage) error
+	// ListUsage returns the latest requests made with a key, newest first.
+	ListUsage(keyID string, limit int) ([]models.OwnerAPIKeyUsage, error)
+	// BeginIdempotent reserves idempotencyKey for a request with requestHash
+	// and returns nil. If the key has been used before, it returns the
+	// earlier request instead.
+	BeginIdempotent(keyID, idempotencyKey, requestHash string) (*IdempotentRequest, error)
+	// CompleteIdempotent stores the response to a reserved request.
+	CompleteIdempotent(keyID, idempotencyKey string, response []byte) error
+	// DeleteIdempotencyKeysBefore deletes idempotency keys first used before
+	// cutoff and returns how many there were.
+	DeleteIdempotencyKeysBefore(cutoff time.Time) (int64, error)
+}
@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"errors"
+
+	"gaspeep/backend/internal/models"
+)
+
+var (
+	// ErrAlreadyTeamMember is returned when a user who already belongs to a
+	// station owner business joins or sets up another.
+	ErrAlreadyTeamMember = errors.New("user already belongs to a station owner business")
+	// ErrAccountHolderMember is returned when the account holder of a
+	// business would be removed from its team or lose the owner role.
+	ErrAccountHolderMember = errors.New("account holder cannot be removed or change role")
+)
+
+// OwnerTeamRepository defines data-access operations for the members of
+// station owner businesses and invitations to join them. Invitations are
+// looked up by the SHA-256 hashes of their tokens.
+type OwnerTeamRepository interface {
+	// GetMember returns the user's membership, or ErrStationOwnerNotFound.
+	GetMember(userID string) (*models.OwnerTeamMember, error)
+	// ListMembers returns a business's members, oldest first.
+	ListMembers(stationOwnerID string) ([]models.OwnerTeamMember, error)
+	// UpdateMember sets a member's role and, for staff, station permissions.
+	// Members made staff lose the API keys they created. It returns
+	// sql.ErrNoRows for an unknown member or ErrAccountHolderMember.
+	UpdateMember(stationOwnerID, memberID, role string, stations []models.StationPermissions) (*models.OwnerTeamMember, error)
+	// RemoveMember removes a member from a business and revokes the API keys
+	// they created. It returns sql.ErrNoRows for an unknown member or
+	// ErrAccountHolderMember.
+	RemoveMember(stationOwnerID, memberID string) error
+
+	// CreateInvitation stores inv, which must have its StationOwnerID set,
+	// fills in its ID and CreatedAt, and revokes the business's other pending
+	// invitations to the same email address.
+	CreateInvitation(inv *models.OwnerTeamInvitation, tokenHash string) error
+	// ListInvitations returns a business's pending invitations, newest first.
+	ListInvitations(stationOwnerID string) ([]models.OwnerTeamInvitation, error)
+	// RevokeInvitation revokes a pending invitation. It returns sql.ErrNoRows
+	// if the business has no such pending invitation.
+	RevokeInvitation(stationOwnerID, invitationID string) error
+	// GetInvitationByToken returns the pending invitation with tokenHash, or
+	// sql.ErrNoRows.
+	GetInvitationByToken(tokenHash string) (*models.OwnerTeamInvitation, error)
+	// AcceptInvitation adds the user to the invitation's business with its
+	// role and station permissions, all or none. It returns sql.ErrNoRows if
+	// the invitation is no longer pending, or ErrAlreadyTeamMember.
+	AcceptInvitation(invitationID, userID string) (*models.OwnerTeamMember, error)
+}
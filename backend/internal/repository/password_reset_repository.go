@@ -2,9 +2,25 @@ package repository
 
 import "time"
 
+// PasswordReset is a password reset token. Only a SHA-256 hash of its secret
+// part is stored.
+type PasswordReset struct {
+	UserID    string
+	TokenHash string
+	ExpiresAt time.Time
+}
+
 // PasswordResetRepository defines data-access operations for password resets.
+// Tokens are looked up by their selector.
 type PasswordResetRepository interface {
-	Create(userID, token string, expiresAt time.Time) error
-	FindByToken(token string) (string, time.Time, error)
-	DeleteByToken(token string) error
+	// Create stores a new token for the user, invalidating any earlier ones.
+	Create(userID, selector, tokenHash string, expiresAt time.Time) error
+	// FindBySelector returns sql.ErrNoRows if there is no such token.
+	FindBySelector(selector string) (*PasswordReset, error)
+	// Consume deletes a token. It returns false if the token was already
+	// gone, so only one request can use it.
+	Consume(selector string) (bool, error)
+	// DeleteExpiredBefore deletes tokens that expired before cutoff and
+	// returns how many there were.
+	DeleteExpiredBefore(cutoff time.Time) (int64, error)
 }
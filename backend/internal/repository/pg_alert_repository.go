@@ -3,10 +3,12 @@ package repository
 import (
 	"database/sql"
 	"fmt"
+	"strconv"
 
 	"gaspeep/backend/internal/models"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
 // PgAlertRepository is the PostgreSQL implementation of AlertRepository.
@@ -18,38 +20,124 @@ func NewPgAlertRepository(db *sql.DB) *PgAlertRepository {
 	return &PgAlertRepository{db: db}
 }
 
+// alertColumns lists the alert columns scanned by scanAlert, in order.
+const alertColumns = `id, user_id, fuel_type_id, price_threshold, condition_type, COALESCE(condition_percent, 0), COALESCE(average_scope, ''), lookback_days,
+	target_mode, ARRAY(SELECT station_id::text FROM alert_stations WHERE alert_id = alerts.id ORDER BY station_id),
+	latitude, longitude, radius_km, alert_name, recurrence_type, notify_via_push, notify_via_email, is_active, created_at, last_triggered_at, trigger_count,
+	cooldown_minutes, rearm_rule, max_triggers, armed, last_evaluated_at, COALESCE(last_evaluation_reason, ''), COALESCE(last_evaluation_detail, '')`
+
+// alertCoversStation returns a predicate that is true when the alert aliased as a
+// targets the station aliased as s, by radius, pinned stations or favourites.
+// The constant-distance ST_DWithin lets the planner use the GiST index on
+// alerts.location before applying each alert's own radius.
+func alertCoversStation(a, s string) string {
+	return fmt.Sprintf(`(
+		(%[1]s.location IS NOT NULL
+			AND ST_DWithin(%[1]s.location, %[2]s.location, %[3]d)
+			AND ST_DWithin(%[1]s.location, %[2]s.location, %[1]s.radius_km * 1000))
+		OR (%[1]s.target_mode IN ('stations', 'radius_or_stations') AND EXISTS (
+			SELECT 1 FROM alert_stations ast WHERE ast.alert_id = %[1]s.id AND ast.station_id = %[2]s.id
+		))
+		OR (%[1]s.target_mode = 'favourites' AND EXISTS (
+			SELECT 1 FROM favourite_stations fav WHERE fav.user_id = %[1]s.user_id AND fav.station_id = %[2]s.id
+		))
+	)`, a, s, maxAlertRadiusKm*1000)
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanAlert(row rowScanner, a *models.Alert) error {
+	return row.Scan(
+		&a.ID, &a.UserID, &a.FuelTypeID, &a.PriceThreshold,
+		&a.ConditionType, &a.ConditionPercent, &a.AverageScope, &a.LookbackDays,
+		&a.TargetMode, pq.Array(&a.StationIDs),
+		&a.Latitude, &a.Longitude, &a.RadiusKm, &a.AlertName,
+		&a.RecurrenceType, &a.NotifyViaPush, &a.NotifyViaEmail, &a.IsActive, &a.CreatedAt, &a.LastTriggeredAt, &a.TriggerCount,
+		&a.CooldownMinutes, &a.RearmRule, &a.MaxTriggers, &a.Armed, &a.LastEvaluatedAt, &a.LastEvaluationReason, &a.LastEvaluationDetail,
+	)
+}
+
 func (r *PgAlertRepository) Create(userID string, input CreateAlertInput) (*models.Alert, error) {
 	id := uuid.New().String()
 	recurrenceType := input.RecurrenceType
 	if recurrenceType == "" {
 		recurrenceType = "recurring"
 	}
-	query := `
-		INSERT INTO alerts (
-			id, user_id, fuel_type_id, price_threshold, latitude, longitude, radius_km, alert_name, recurrence_type, notify_via_push, notify_via_email, is_active, created_at
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, true, NOW())
-		RETURNING id, user_id, fuel_type_id, price_threshold, latitude, longitude, radius_km, alert_name, recurrence_type, notify_via_push, notify_via_email, is_active, created_at, last_triggered_at, trigger_count`
+	conditionType := input.ConditionType
+	if conditionType == "" {
+		conditionType = AlertConditionPriceThreshold
+	}
+	lookbackDays := input.LookbackDays
+	if lookbackDays == 0 {
+		lookbackDays = DefaultLookbackDays(conditionType)
+	}
+	targetMode := input.TargetMode
+	if targetMode == "" {
+		targetMode = AlertTargetRadius
+	}
+	rearmRule := input.RearmRule
+	if rearmRule == "" {
+		rearmRule = AlertRearmNone
+	}
 
-	var alert models.Alert
-	err := r.db.QueryRow(
-		query,
-		id, userID, input.FuelTypeID, input.PriceThreshold, input.Latitude, input.Longitude, input.RadiusKm, input.AlertName, recurrenceType, input.NotifyViaPush, input.NotifyViaEmail,
-	).Scan(
-		&alert.ID, &alert.UserID, &alert.FuelTypeID, &alert.PriceThreshold,
-		&alert.Latitude, &alert.Longitude, &alert.RadiusKm, &alert.AlertName,
-		&alert.RecurrenceType, &alert.NotifyViaPush, &alert.NotifyViaEmail, &alert.IsActive, &alert.CreatedAt, &alert.LastTriggeredAt, &alert.TriggerCount,
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		INSERT INTO alerts (
+			id, user_id, fuel_type_id, price_threshold, condition_type, condition_percent, average_scope, lookback_days, target_mode, latitude, longitude, radius_km, alert_name, recurrence_type, notify_via_push, notify_via_email, cooldown_minutes, rearm_rule, max_triggers, is_active, created_at
+		) VALUES ($1, $2, $3, $4, $5, NULLIF($6::numeric, 0), NULLIF($7, ''), $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, true, NOW())`,
+		id, userID, input.FuelTypeID, input.PriceThreshold, conditionType, input.ConditionPercent, input.AverageScope, lookbackDays, targetMode,
+		input.Latitude, input.Longitude, input.RadiusKm, input.AlertName, recurrenceType, input.NotifyViaPush, input.NotifyViaEmail,
+		input.CooldownMinutes, rearmRule, input.MaxTriggers,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create alert: %w", err)
 	}
+	if err := replaceAlertStations(tx, id, input.StationIDs); err != nil {
+		return nil, err
+	}
+
+	var alert models.Alert
+	if err := scanAlert(tx.QueryRow(`SELECT `+alertColumns+` FROM alerts WHERE id = $1`, id), &alert); err != nil {
+		return nil, fmt.Errorf("failed to load created alert: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit alert: %w", err)
+	}
 
 	return &alert, nil
 }
 
+// replaceAlertStations sets the alert's pinned stations to exactly stationIDs.
+func replaceAlertStations(tx *sql.Tx, alertID string, stationIDs []string) error {
+	if _, err := tx.Exec(`DELETE FROM alert_stations WHERE alert_id = $1`, alertID); err != nil {
+		return fmt.Errorf("failed to clear alert stations: %w", err)
+	}
+	if len(stationIDs) == 0 {
+		return nil
+	}
+	result, err := tx.Exec(`
+		INSERT INTO alert_stations (alert_id, station_id)
+		SELECT $1, s.id FROM stations s WHERE s.id = ANY($2::uuid[])`,
+		alertID, pq.Array(stationIDs),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save alert stations: %w", err)
+	}
+	if inserted, _ := result.RowsAffected(); int(inserted) != len(stationIDs) {
+		return fmt.Errorf("%w: one or more stationIds do not exist", ErrInvalidAlertInput)
+	}
+	return nil
+}
+
 func (r *PgAlertRepository) GetByUserID(userID string) ([]models.Alert, error) {
-	query := `
-		SELECT id, user_id, fuel_type_id, price_threshold, latitude, longitude, radius_km, alert_name, recurrence_type, notify_via_push, notify_via_email, is_active, created_at, last_triggered_at, trigger_count
-		FROM alerts WHERE user_id = $1 ORDER BY created_at DESC`
+	query := `SELECT ` + alertColumns + ` FROM alerts WHERE user_id = $1 ORDER BY created_at DESC`
 
 	rows, err := r.db.Query(query, userID)
 	if err != nil {
@@ -60,11 +148,7 @@ func (r *PgAlertRepository) GetByUserID(userID string) ([]models.Alert, error) {
 	alerts := make([]models.Alert, 0)
 	for rows.Next() {
 		var a models.Alert
-		if err := rows.Scan(
-			&a.ID, &a.UserID, &a.FuelTypeID, &a.PriceThreshold,
-			&a.Latitude, &a.Longitude, &a.RadiusKm, &a.AlertName,
-			&a.RecurrenceType, &a.NotifyViaPush, &a.NotifyViaEmail, &a.IsActive, &a.CreatedAt, &a.LastTriggeredAt, &a.TriggerCount,
-		); err != nil {
+		if err := scanAlert(rows, &a); err != nil {
 			return nil, fmt.Errorf("failed to scan alert: %w", err)
 		}
 		alerts = append(alerts, a)
@@ -77,15 +161,65 @@ func (r *PgAlertRepository) GetByUserID(userID string) ([]models.Alert, error) {
 	return alerts, nil
 }
 
+// GetByID returns sql.ErrNoRows when the alert does not exist or belongs to another user.
+func (r *PgAlertRepository) GetByID(id, userID string) (*models.Alert, error) {
+	var alert models.Alert
+	err := scanAlert(r.db.QueryRow(`SELECT `+alertColumns+` FROM alerts WHERE id = $1 AND user_id = $2`, id, userID), &alert)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, sql.ErrNoRows
+		}
+		return nil, fmt.Errorf("failed to get alert: %w", err)
+	}
+	return &alert, nil
+}
+
 func (r *PgAlertRepository) Update(id, userID string, input UpdateAlertInput) (string, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return "", fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Changing the condition type clears percent and scope unless they are supplied
+	// again. Re-activating an alert or dropping its re-arm rule re-arms it.
 	query := `
-		UPDATE alerts SET price_threshold = COALESCE($1, price_threshold), radius_km = COALESCE($2, radius_km), alert_name = COALESCE($3, alert_name), notify_via_push = COALESCE($4, notify_via_push), notify_via_email = COALESCE($5, notify_via_email), is_active = COALESCE($6, is_active), recurrence_type = COALESCE($7, recurrence_type), updated_at = NOW() WHERE id = $8 AND user_id = $9 RETURNING id`
+		UPDATE alerts SET price_threshold = COALESCE($1, price_threshold), radius_km = COALESCE($2, radius_km), alert_name = COALESCE($3, alert_name), notify_via_push = COALESCE($4, notify_via_push), notify_via_email = COALESCE($5, notify_via_email), is_active = COALESCE($6, is_active), recurrence_type = COALESCE($7, recurrence_type),
+			condition_type = COALESCE($10, condition_type),
+			condition_percent = CASE
+				WHEN $11::numeric IS NOT NULL THEN NULLIF($11::numeric, 0)
+				WHEN $10::varchar IS NOT NULL AND $10::varchar <> condition_type THEN NULL
+				ELSE condition_percent
+			END,
+			average_scope = CASE
+				WHEN COALESCE($10::varchar, condition_type) <> 'percent_below_average' THEN NULL
+				ELSE COALESCE(NULLIF($12::varchar, ''), average_scope)
+			END,
+			lookback_days = COALESCE($13, lookback_days),
+			target_mode = COALESCE($14, target_mode),
+			cooldown_minutes = COALESCE($15, cooldown_minutes),
+			rearm_rule = COALESCE($16, rearm_rule),
+			max_triggers = CASE WHEN $17::int IS NULL THEN max_triggers ELSE NULLIF($17::int, 0) END,
+			armed = armed OR COALESCE($6, false) OR COALESCE($16, '') = 'none',
+			updated_at = NOW() WHERE id = $8 AND user_id = $9 RETURNING id, target_mode`
 
-	var updatedID string
-	err := r.db.QueryRow(query, input.PriceThreshold, input.RadiusKm, input.AlertName, input.NotifyViaPush, input.NotifyViaEmail, input.IsActive, input.RecurrenceType, id, userID).Scan(&updatedID)
+	var updatedID, targetMode string
+	err = tx.QueryRow(query, input.PriceThreshold, input.RadiusKm, input.AlertName, input.NotifyViaPush, input.NotifyViaEmail, input.IsActive, input.RecurrenceType, id, userID,
+		input.ConditionType, input.ConditionPercent, input.AverageScope, input.LookbackDays, input.TargetMode,
+		input.CooldownMinutes, input.RearmRule, input.MaxTriggers).Scan(&updatedID, &targetMode)
 	if err != nil {
 		return "", err
 	}
+
+	// Pinned stations only survive in modes that use them.
+	if input.StationIDs != nil || !targetUsesStations(targetMode) {
+		if err := replaceAlertStations(tx, updatedID, input.StationIDs); err != nil {
+			return "", err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return "", fmt.Errorf("failed to commit alert update: %w", err)
+	}
 	return updatedID, nil
 }
 
@@ -157,57 +291,87 @@ func (r *PgAlertRepository) GetPriceContext(input PriceContextInput) (*PriceCont
 }
 
 func (r *PgAlertRepository) GetMatchingStations(alertID, userID string) ([]MatchingStationResult, error) {
-	var fuelTypeID string
-	var priceThreshold float64
-	var latitude, longitude float64
-	var radiusKm int
-
-	err := r.db.QueryRow(
-		`SELECT fuel_type_id, price_threshold, latitude, longitude, radius_km
-		 FROM alerts
-		 WHERE id = $1 AND user_id = $2`,
-		alertID,
-		userID,
-	).Scan(&fuelTypeID, &priceThreshold, &latitude, &longitude, &radiusKm)
-	if err != nil {
+	if _, err := r.GetByID(alertID, userID); err != nil {
 		if err == sql.ErrNoRows {
 			return nil, sql.ErrNoRows
 		}
 		return nil, fmt.Errorf("failed to load alert for matching stations: %w", err)
 	}
 
+	// Each condition type is applied to current prices at the stations the alert
+	// targets: averages and minimums come from approved submissions in the
+	// alert's lookback window. Station-only alerts have no centre, so they are
+	// ordered by price alone.
 	rows, err := r.db.Query(
 		`
+		WITH a AS (
+			SELECT * FROM alerts WHERE id = $1
+		),
+		nearby AS (
+			SELECT
+				s.id,
+				s.name,
+				s.address,
+				fp.price,
+				fp.previous_price,
+				fp.currency,
+				fp.unit,
+				CASE WHEN a.target_mode IN ('radius', 'radius_or_stations') THEN
+					ST_Distance(
+						s.location::geography,
+						ST_SetSRID(ST_MakePoint(a.longitude, a.latitude), 4326)::geography
+					) / 1000
+				ELSE 0 END AS distance_km,
+				fp.last_updated_at
+			FROM a
+			INNER JOIN fuel_prices fp ON fp.fuel_type_id = a.fuel_type_id
+			INNER JOIN stations s ON s.id = fp.station_id
+			WHERE fp.verification_status IN ('verified', 'unverified')
+				AND `+alertCoversStation("a", "s")+`
+		),
+		area_history AS (
+			SELECT AVG(ps.price) AS avg_price, MIN(ps.price) AS min_price
+			FROM a
+			INNER JOIN price_submissions ps ON ps.fuel_type_id = a.fuel_type_id
+			INNER JOIN stations s ON s.id = ps.station_id
+			WHERE a.condition_type IN ('percent_below_average', 'cycle_bottom')
+				AND ps.moderation_status = 'approved'
+				AND ps.submitted_at >= NOW() - make_interval(days => a.lookback_days)
+				AND `+alertCoversStation("a", "s")+`
+		)
 		SELECT
-			s.id::text AS station_id,
-			s.name AS station_name,
-			s.address AS station_address,
-			fp.price,
-			fp.currency,
-			fp.unit,
-			ST_Distance(
-				s.location::geography,
-				ST_SetSRID(ST_MakePoint($1, $2), 4326)::geography
-			) / 1000 AS distance_km,
-			fp.last_updated_at
-		FROM fuel_prices fp
-		INNER JOIN stations s ON s.id = fp.station_id
-		WHERE fp.fuel_type_id = $3
-			AND fp.verification_status IN ('verified', 'unverified')
-			AND fp.price <= $4
-			AND ST_DWithin(
-				s.location::geography,
-				ST_SetSRID(ST_MakePoint($1, $2), 4326)::geography,
-				$5
-			)
-		ORDER BY distance_km ASC, fp.price ASC
+			n.id::text AS station_id,
+			n.name AS station_name,
+			n.address AS station_address,
+			n.price,
+			n.currency,
+			n.unit,
+			n.distance_km,
+			n.last_updated_at
+		FROM nearby n
+		CROSS JOIN a
+		CROSS JOIN area_history h
+		LEFT JOIN LATERAL (
+			SELECT AVG(ps.price) AS avg_price
+			FROM price_submissions ps
+			WHERE a.condition_type = 'percent_below_average' AND a.average_scope = 'station'
+				AND ps.station_id = n.id
+				AND ps.fuel_type_id = a.fuel_type_id
+				AND ps.moderation_status = 'approved'
+				AND ps.submitted_at >= NOW() - make_interval(days => a.lookback_days)
+		) sh ON true
+		WHERE CASE a.condition_type
+			WHEN 'percent_below_average' THEN
+				n.price <= (CASE WHEN a.average_scope = 'station' THEN sh.avg_price ELSE h.avg_price END) * (1 - COALESCE(a.condition_percent, 0) / 100)
+			WHEN 'new_cheapest' THEN n.price <= (SELECT MIN(price) FROM nearby)
+			WHEN 'cycle_bottom' THEN n.price <= h.min_price * (1 + COALESCE(a.condition_percent, 0) / 100)
+			WHEN 'price_rise' THEN n.previous_price IS NOT NULL AND n.price > n.previous_price * (1 + COALESCE(a.condition_percent, 0) / 100)
+			ELSE n.price <= a.price_threshold
+		END
+		ORDER BY n.distance_km ASC, n.price ASC
 		LIMIT 100
 		`,
-		longitude,
-		latitude,
-		fuelTypeID,
-		priceThreshold,
-		radiusKm*1000,
+		alertID,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query matching stations: %w", err)
@@ -239,71 +403,361 @@ func (r *PgAlertRepository) GetMatchingStations(alertID, userID string) ([]Match
 	return stations, nil
 }
 
-func (r *PgAlertRepository) RecordTriggersForPrice(stationID, fuelTypeID string, price float64) ([]TriggeredAlertResult, error) {
+// maxPriceHistoryPoints bounds how many reports a back-test replays.
+const maxPriceHistoryPoints = 50000
+
+// GetPriceHistory returns approved price reports at the stations a draft alert
+// targets, oldest first. Report times are returned as absolute instants.
+func (r *PgAlertRepository) GetPriceHistory(input PriceHistoryInput) (*PriceHistoryResult, error) {
+	result := &PriceHistoryResult{Points: make([]PriceHistoryPoint, 0)}
+	err := r.db.QueryRow(`SELECT time_zone FROM users WHERE id = $1`, input.UserID).Scan(&result.TimeZone)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch user time zone: %w", err)
+	}
+
+	stationIDs := input.StationIDs
+	if stationIDs == nil {
+		stationIDs = []string{}
+	}
+
+	rows, err := r.db.Query(`
+		SELECT s.id, s.name, ps.price, ps.submitted_at AT TIME ZONE current_setting('TimeZone')
+		FROM price_submissions ps
+		INNER JOIN stations s ON s.id = ps.station_id
+		WHERE ps.fuel_type_id = $1
+			AND ps.moderation_status = 'approved'
+			AND ps.submitted_at >= $2::timestamptz AT TIME ZONE current_setting('TimeZone')
+			AND (
+				($3 IN ('radius', 'radius_or_stations') AND ST_DWithin(
+					s.location,
+					ST_SetSRID(ST_MakePoint($5, $4), 4326)::geography,
+					$6 * 1000
+				))
+				OR ($3 IN ('stations', 'radius_or_stations') AND s.id = ANY($7::uuid[]))
+				OR ($3 = 'favourites' AND EXISTS (
+					SELECT 1 FROM favourite_stations fav WHERE fav.user_id = $8 AND fav.station_id = s.id
+				))
+			)
+		ORDER BY ps.submitted_at, ps.id
+		LIMIT $9`,
+		input.FuelTypeID, input.Since, input.TargetMode, input.Latitude, input.Longitude, input.RadiusKm,
+		pq.Array(stationIDs), input.UserID, maxPriceHistoryPoints,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query price history: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var p PriceHistoryPoint
+		if err := rows.Scan(&p.StationID, &p.StationName, &p.Price, &p.ReportedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan price history: %w", err)
+		}
+		result.Points = append(result.Points, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating price history: %w", err)
+	}
+
+	return result, nil
+}
+
+// GetTriggerCandidates returns active alerts targeting the station with their
+// re-trigger state and the metrics each condition type needs for evaluation.
+func (r *PgAlertRepository) GetTriggerCandidates(stationID, fuelTypeID string) ([]AlertTriggerCandidate, error) {
+	return r.GetTriggerCandidatesForChanges([]PriceChangeEvent{{StationID: stationID, FuelTypeID: fuelTypeID}})
+}
+
+// GetTriggerCandidatesForChanges matches a batch of price changes against active
+// alerts in one query. Alerts are found per change through the radius index,
+// pinned stations and favourites separately, so each branch can use its own
+// index. Area metrics are taken over the same stations the alert targets.
+// Timestamps are returned as absolute instants so they can be compared in the
+// user's zone.
+func (r *PgAlertRepository) GetTriggerCandidatesForChanges(events []PriceChangeEvent) ([]AlertTriggerCandidate, error) {
+	candidates := make([]AlertTriggerCandidate, 0)
+	if len(events) == 0 {
+		return candidates, nil
+	}
+
+	eventIDs := make([]string, len(events))
+	stationIDs := make([]string, len(events))
+	fuelTypeIDs := make([]string, len(events))
+	previousPrices := make([]sql.NullFloat64, len(events))
+	for i, e := range events {
+		eventIDs[i] = e.ID
+		stationIDs[i] = e.StationID
+		fuelTypeIDs[i] = e.FuelTypeID
+		if e.PreviousPrice != nil {
+			previousPrices[i] = sql.NullFloat64{Float64: *e.PreviousPrice, Valid: true}
+		}
+	}
+
+	// Previous prices come from the events, as the station's current price
+	// may have changed again since
 	query := `
-		WITH station AS (
-			SELECT location
-			FROM stations
-			WHERE id = $1
+		WITH changes AS (
+			SELECT c.event_id, s.id AS station_id, s.location, c.fuel_type_id, c.previous_price
+			FROM unnest($1::text[], $2::uuid[], $3::uuid[], $4::numeric[]) AS c(event_id, station_id, fuel_type_id, previous_price)
+			INNER JOIN stations s ON s.id = c.station_id
 		),
-		eligible AS (
-			SELECT a.id
-			FROM alerts a
-			CROSS JOIN station s
-			WHERE a.is_active = true
-				AND a.fuel_type_id = $2
-				AND a.price_threshold >= $3
-				AND ST_DWithin(
-					ST_SetSRID(ST_MakePoint(a.longitude, a.latitude), 4326)::geography,
-					s.location::geography,
-					a.radius_km * 1000
-				)
-				AND (
-					(a.recurrence_type = 'one_off' AND a.last_triggered_at IS NULL)
-					OR (
-						a.recurrence_type = 'recurring'
-						AND (
-							a.last_triggered_at IS NULL
-							OR a.last_triggered_at < date_trunc('day', NOW())
-						)
-					)
-				)
+		matches AS (
+			SELECT ch.event_id, a.id AS alert_id
+			FROM changes ch
+			INNER JOIN alerts a ON a.location IS NOT NULL
+				AND ST_DWithin(a.location, ch.location, ` + strconv.Itoa(maxAlertRadiusKm*1000) + `)
+				AND ST_DWithin(a.location, ch.location, a.radius_km * 1000)
+			WHERE a.is_active = true AND a.fuel_type_id = ch.fuel_type_id
+			UNION
+			SELECT ch.event_id, a.id
+			FROM changes ch
+			INNER JOIN alert_stations ast ON ast.station_id = ch.station_id
+			INNER JOIN alerts a ON a.id = ast.alert_id
+			WHERE a.is_active = true AND a.fuel_type_id = ch.fuel_type_id
+				AND a.target_mode IN ('stations', 'radius_or_stations')
+			UNION
+			SELECT ch.event_id, a.id
+			FROM changes ch
+			INNER JOIN favourite_stations fav ON fav.station_id = ch.station_id
+			INNER JOIN alerts a ON a.user_id = fav.user_id
+			WHERE a.is_active = true AND a.fuel_type_id = ch.fuel_type_id
+				AND a.target_mode = 'favourites'
 		)
-		UPDATE alerts a
-		SET
-			last_triggered_at = NOW(),
-			trigger_count = a.trigger_count + 1,
-			is_active = CASE WHEN a.recurrence_type = 'one_off' THEN false ELSE a.is_active END,
-			updated_at = NOW()
-		FROM eligible e
-		WHERE a.id = e.id
-		RETURNING a.id, a.user_id, a.alert_name, a.recurrence_type, a.notify_via_push, a.notify_via_email`
-
-	rows, err := r.db.Query(query, stationID, fuelTypeID, price)
+		SELECT
+			st.event_id,
+			a.id,
+			a.user_id,
+			a.alert_name,
+			a.condition_type,
+			a.price_threshold,
+			COALESCE(a.condition_percent, 0),
+			COALESCE(a.average_scope, ''),
+			a.lookback_days,
+			a.recurrence_type,
+			a.trigger_count,
+			a.last_triggered_at AT TIME ZONE current_setting('TimeZone'),
+			a.last_triggered_station_id::text,
+			a.cooldown_minutes,
+			a.rearm_rule,
+			a.max_triggers,
+			a.armed,
+			u.time_zone,
+			NOW(),
+			st.previous_price,
+			station_history.avg_price,
+			area_history.avg_price,
+			area_history.min_price,
+			area_current.min_price
+		FROM matches m
+		INNER JOIN changes st ON st.event_id = m.event_id
+		INNER JOIN alerts a ON a.id = m.alert_id
+		INNER JOIN users u ON u.id = a.user_id
+		LEFT JOIN LATERAL (
+			SELECT AVG(ps.price) AS avg_price
+			FROM price_submissions ps
+			WHERE a.condition_type = 'percent_below_average' AND a.average_scope = 'station'
+				AND ps.station_id = st.station_id
+				AND ps.fuel_type_id = a.fuel_type_id
+				AND ps.moderation_status = 'approved'
+				AND ps.submitted_at >= NOW() - make_interval(days => a.lookback_days)
+		) station_history ON true
+		LEFT JOIN LATERAL (
+			SELECT AVG(ps.price) AS avg_price, MIN(ps.price) AS min_price
+			FROM price_submissions ps
+			INNER JOIN stations s ON s.id = ps.station_id
+			WHERE ((a.condition_type = 'percent_below_average' AND a.average_scope = 'area') OR a.condition_type = 'cycle_bottom')
+				AND ps.fuel_type_id = a.fuel_type_id
+				AND ps.moderation_status = 'approved'
+				AND ps.submitted_at >= NOW() - make_interval(days => a.lookback_days)
+				AND ` + alertCoversStation("a", "s") + `
+		) area_history ON true
+		LEFT JOIN LATERAL (
+			SELECT MIN(fp.price) AS min_price
+			FROM fuel_prices fp
+			INNER JOIN stations s ON s.id = fp.station_id
+			WHERE a.condition_type = 'new_cheapest'
+				AND fp.fuel_type_id = a.fuel_type_id
+				AND fp.station_id <> st.station_id
+				AND fp.verification_status IN ('verified', 'unverified')
+				AND ` + alertCoversStation("a", "s") + `
+		) area_current ON true
+		ORDER BY st.event_id, a.created_at`
+
+	rows, err := r.db.Query(query, pq.Array(eventIDs), pq.Array(stationIDs), pq.Array(fuelTypeIDs), pq.Array(previousPrices))
 	if err != nil {
-		return nil, fmt.Errorf("failed to record alert triggers: %w", err)
+		return nil, fmt.Errorf("failed to query alert trigger candidates: %w", err)
 	}
 	defer rows.Close()
 
-	results := make([]TriggeredAlertResult, 0)
 	for rows.Next() {
-		var result TriggeredAlertResult
+		var c AlertTriggerCandidate
 		if err := rows.Scan(
-			&result.AlertID,
-			&result.UserID,
-			&result.AlertName,
-			&result.RecurrenceType,
-			&result.NotifyViaPush,
-			&result.NotifyViaEmail,
+			&c.EventID,
+			&c.AlertID,
+			&c.UserID,
+			&c.AlertName,
+			&c.ConditionType,
+			&c.PriceThreshold,
+			&c.ConditionPercent,
+			&c.AverageScope,
+			&c.LookbackDays,
+			&c.RecurrenceType,
+			&c.TriggerCount,
+			&c.LastTriggeredAt,
+			&c.LastTriggeredStationID,
+			&c.CooldownMinutes,
+			&c.RearmRule,
+			&c.MaxTriggers,
+			&c.Armed,
+			&c.TimeZone,
+			&c.EvaluatedAt,
+			&c.PreviousPrice,
+			&c.StationAverage,
+			&c.AreaAverage,
+			&c.AreaTrailingMin,
+			&c.AreaCheapestOther,
 		); err != nil {
-			return nil, fmt.Errorf("failed to scan triggered alert: %w", err)
+			return nil, fmt.Errorf("failed to scan alert trigger candidate: %w", err)
 		}
-		results = append(results, result)
+		candidates = append(candidates, c)
 	}
 	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating triggered alerts: %w", err)
+		return nil, fmt.Errorf("error iterating alert trigger candidates: %w", err)
+	}
+
+	return candidates, nil
+}
+
+// RecordEvaluations stores the outcome of evaluating each alert against a price
+// change and records triggers. A trigger is only recorded if the alert's trigger
+// count still matches the evaluated one, so concurrent evaluations fire once.
+// When eventID is set the price change event is completed in the same
+// transaction; if it was already completed nothing is recorded, which keeps
+// redelivered events from firing alerts twice.
+func (r *PgAlertRepository) RecordEvaluations(eventID, stationID string, price float64, evaluations []AlertEvaluation) ([]TriggeredAlertResult, error) {
+	results := make([]TriggeredAlertResult, 0)
+	if len(evaluations) == 0 && eventID == "" {
+		return results, nil
+	}
+
+	var triggeredIDs, triggeredDetails, otherIDs, otherReasons, otherDetails []string
+	var expectedCounts []int64
+	var rearms []bool
+	for _, e := range evaluations {
+		if e.Triggered {
+			triggeredIDs = append(triggeredIDs, e.AlertID)
+			expectedCounts = append(expectedCounts, int64(e.ExpectedTriggerCount))
+			triggeredDetails = append(triggeredDetails, e.Detail)
+			continue
+		}
+		otherIDs = append(otherIDs, e.AlertID)
+		otherReasons = append(otherReasons, e.Reason)
+		otherDetails = append(otherDetails, e.Detail)
+		rearms = append(rearms, e.Rearm)
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if eventID != "" {
+		completed, err := tx.Exec(`
+			UPDATE price_change_events
+			SET status = 'done', locked_until = NULL, last_error = NULL, processed_at = NOW()
+			WHERE id = $1 AND status <> 'done'`,
+			eventID,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to complete price change event: %w", err)
+		}
+		rows, err := completed.RowsAffected()
+		if err != nil {
+			return nil, fmt.Errorf("failed to complete price change event: %w", err)
+		}
+		if rows == 0 {
+			return results, nil
+		}
+	}
+
+	if len(otherIDs) > 0 {
+		_, err := tx.Exec(`
+			UPDATE alerts a
+			SET
+				armed = a.armed OR t.rearm,
+				last_evaluated_at = NOW(),
+				last_evaluation_reason = t.reason,
+				last_evaluation_detail = t.detail
+			FROM unnest($1::uuid[], $2::text[], $3::text[], $4::bool[]) AS t(id, reason, detail, rearm)
+			WHERE a.id = t.id`,
+			pq.Array(otherIDs), pq.Array(otherReasons), pq.Array(otherDetails), pq.Array(rearms),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to record alert evaluations: %w", err)
+		}
+	}
+
+	if len(triggeredIDs) > 0 {
+		rows, err := tx.Query(`
+			UPDATE alerts a
+			SET
+				last_triggered_at = NOW(),
+				trigger_count = a.trigger_count + 1,
+				last_triggered_station_id = $4,
+				last_triggered_price = $5,
+				armed = a.rearm_rule <> 'price_recovers',
+				is_active = CASE
+					WHEN a.recurrence_type = 'one_off' THEN false
+					WHEN a.max_triggers IS NOT NULL AND a.trigger_count + 1 >= a.max_triggers THEN false
+					ELSE a.is_active
+				END,
+				last_evaluated_at = NOW(),
+				last_evaluation_reason = 'triggered',
+				last_evaluation_detail = t.detail,
+				updated_at = NOW()
+			FROM unnest($1::uuid[], $2::int[], $3::text[]) AS t(id, expected_count, detail)
+			WHERE a.id = t.id
+				AND a.is_active = true
+				AND a.trigger_count = t.expected_count
+			RETURNING a.id, a.user_id, a.alert_name, a.recurrence_type, a.notify_via_push, a.notify_via_email,
+				COALESCE((SELECT u.email FROM users u WHERE u.id = a.user_id), ''),
+				COALESCE((SELECT s.name FROM stations s WHERE s.id = $4), ''),
+				COALESCE((SELECT ft.display_name FROM fuel_types ft WHERE ft.id = a.fuel_type_id), ''),
+				COALESCE((SELECT fp.currency FROM fuel_prices fp WHERE fp.station_id = $4 AND fp.fuel_type_id = a.fuel_type_id), 'AUD')`,
+			pq.Array(triggeredIDs), pq.Array(expectedCounts), pq.Array(triggeredDetails), stationID, price,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to record alert triggers: %w", err)
+		}
+		for rows.Next() {
+			var result TriggeredAlertResult
+			if err := rows.Scan(
+				&result.AlertID,
+				&result.UserID,
+				&result.AlertName,
+				&result.RecurrenceType,
+				&result.NotifyViaPush,
+				&result.NotifyViaEmail,
+				&result.UserEmail,
+				&result.StationName,
+				&result.FuelTypeName,
+				&result.Currency,
+			); err != nil {
+				rows.Close()
+				return nil, fmt.Errorf("failed to scan triggered alert: %w", err)
+			}
+			results = append(results, result)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("error iterating triggered alerts: %w", err)
+		}
 	}
 
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit alert evaluations: %w", err)
+	}
 	return results, nil
 }
 
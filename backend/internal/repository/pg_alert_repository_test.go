@@ -333,6 +333,115 @@ func TestGetPriceContext_NoNearbyPrices_ReturnsZeroedContext(t *testing.T) {
 	assert.Equal(t, "", context.LowestPriceStationName)
 }
 
+func TestGetTriggerCandidates_AndRecordEvaluations(t *testing.T) {
+	db := testhelpers.SetupTestDBWithCleanup(t)
+
+	user := testhelpers.CreateTestUser(t, db)
+	station := testhelpers.CreateTestStation(t, db, -33.8568, 151.2153)
+	other := testhelpers.CreateTestStation(t, db, -33.8570, 151.2150)
+	fuelTypeID := testhelpers.CreateTestFuelType(t, db, "U91")
+	testhelpers.CreateTestFuelPrice(t, db, other.ID, fuelTypeID, 1.85)
+
+	repo := NewPgAlertRepository(db)
+	alert, err := repo.Create(user.ID, CreateAlertInput{
+		FuelTypeID:     fuelTypeID,
+		ConditionType:  AlertConditionNewCheapest,
+		Latitude:       -33.8568,
+		Longitude:      151.2153,
+		RadiusKm:       5,
+		AlertName:      "Cheapest nearby",
+		RecurrenceType: "one_off",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 7, alert.LookbackDays)
+
+	candidates, err := repo.GetTriggerCandidates(station.ID, fuelTypeID)
+	require.NoError(t, err)
+	require.Len(t, candidates, 1)
+	assert.Equal(t, alert.ID, candidates[0].AlertID)
+	require.NotNil(t, candidates[0].AreaCheapestOther)
+	assert.InDelta(t, 1.85, *candidates[0].AreaCheapestOther, 0.0001)
+
+	assert.True(t, candidates[0].Armed)
+	assert.Equal(t, "Australia/Sydney", candidates[0].TimeZone)
+
+	triggered, err := repo.RecordEvaluations("", station.ID, 1.80, []AlertEvaluation{{
+		AlertID:              alert.ID,
+		Triggered:            true,
+		ExpectedTriggerCount: candidates[0].TriggerCount,
+		Reason:               AlertReasonTriggered,
+		Detail:               "price 1.800 vs cheapest other station 1.850",
+	}})
+	require.NoError(t, err)
+	require.Len(t, triggered, 1)
+
+	stored, err := repo.GetByID(alert.ID, user.ID)
+	require.NoError(t, err)
+	assert.False(t, stored.IsActive)
+	assert.Equal(t, AlertReasonTriggered, stored.LastEvaluationReason)
+	require.NotNil(t, stored.LastEvaluatedAt)
+
+	// A stale trigger count means another evaluation already fired the alert
+	triggered, err = repo.RecordEvaluations("", station.ID, 1.80, []AlertEvaluation{{
+		AlertID:              alert.ID,
+		Triggered:            true,
+		ExpectedTriggerCount: candidates[0].TriggerCount,
+		Reason:               AlertReasonTriggered,
+	}})
+	require.NoError(t, err)
+	assert.Empty(t, triggered)
+}
+
+func TestGetTriggerCandidatesForChanges_MatchesBatch(t *testing.T) {
+	db := testhelpers.SetupTestDBWithCleanup(t)
+
+	user := testhelpers.CreateTestUser(t, db)
+	near := testhelpers.CreateTestStation(t, db, -33.8568, 151.2153)
+	far := testhelpers.CreateTestStation(t, db, -35.2809, 149.1300)
+	pinned := testhelpers.CreateTestStation(t, db, -34.4278, 150.8931)
+	fuelTypeID := testhelpers.CreateTestFuelType(t, db, "U91")
+
+	repo := NewPgAlertRepository(db)
+	radiusAlert, err := repo.Create(user.ID, CreateAlertInput{
+		FuelTypeID:     fuelTypeID,
+		PriceThreshold: 1.80,
+		Latitude:       -33.8568,
+		Longitude:      151.2153,
+		RadiusKm:       5,
+		AlertName:      "Nearby",
+		RecurrenceType: "recurring",
+	})
+	require.NoError(t, err)
+	pinnedAlert, err := repo.Create(user.ID, CreateAlertInput{
+		FuelTypeID:     fuelTypeID,
+		PriceThreshold: 1.80,
+		TargetMode:     AlertTargetStations,
+		StationIDs:     []string{pinned.ID},
+		AlertName:      "Pinned",
+		RecurrenceType: "recurring",
+	})
+	require.NoError(t, err)
+
+	// The station's price has moved on since the event was queued
+	require.NoError(t, NewPgFuelPriceRepository(db).UpsertFuelPrice(near.ID, fuelTypeID, 1.75))
+	previous := 1.90
+
+	candidates, err := repo.GetTriggerCandidatesForChanges([]PriceChangeEvent{
+		{ID: "a", StationID: near.ID, FuelTypeID: fuelTypeID, Price: 1.79, PreviousPrice: &previous},
+		{ID: "b", StationID: far.ID, FuelTypeID: fuelTypeID},
+		{ID: "c", StationID: pinned.ID, FuelTypeID: fuelTypeID},
+	})
+	require.NoError(t, err)
+	require.Len(t, candidates, 2)
+	assert.Equal(t, "a", candidates[0].EventID)
+	assert.Equal(t, radiusAlert.ID, candidates[0].AlertID)
+	require.NotNil(t, candidates[0].PreviousPrice)
+	assert.Equal(t, 1.90, *candidates[0].PreviousPrice)
+	assert.Equal(t, "c", candidates[1].EventID)
+	assert.Equal(t, pinnedAlert.ID, candidates[1].AlertID)
+	assert.Nil(t, candidates[1].PreviousPrice)
+}
+
 // Helper function
 func ptrBool(b bool) *bool {
 	return &b
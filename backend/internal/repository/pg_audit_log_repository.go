@@ -0,0 +1,39 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// PgAuditLogRepository is the PostgreSQL implementation of AuditLogRepository.
+type PgAuditLogRepository struct {
+	db *sql.DB
+}
+
+func NewPgAuditLogRepository(db *sql.DB) *PgAuditLogRepository {
+	return &PgAuditLogRepository{db: db}
+}
+
+func (r *PgAuditLogRepository) Record(entry AuditLogEntry) error {
+	details := []byte("{}")
+	if len(entry.Details) > 0 {
+		var err error
+		if details, err = json.Marshal(entry.Details); err != nil {
+			return fmt.Errorf("failed to encode audit log details: %w", err)
+		}
+	}
+	_, err := r.db.Exec(`
+		INSERT INTO audit_log (id, user_id, action, ip_address, user_agent, details)
+		VALUES ($1, NULLIF($2, '')::uuid, $3, $4, $5, $6)`,
+		uuid.New().String(), entry.UserID, entry.Action, entry.IPAddress, entry.UserAgent, details,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record audit log entry: %w", err)
+	}
+	return nil
+}
+
+var _ AuditLogRepository = (*PgAuditLogRepository)(nil)
@@ -18,18 +18,24 @@ func NewPgBroadcastRepository(db *sql.DB) *PgBroadcastRepository {
 	return &PgBroadcastRepository{db: db}
 }
 
+const broadcastColumns = `id, station_owner_id, station_id, title, message, target_radius_km, start_date, end_date, broadcast_status, target_fuel_types,
+	created_at, views, clicks, created_by, updated_by`
+
+func scanBroadcast(row rowScanner, b *models.Broadcast) error {
+	return row.Scan(&b.ID, &b.StationOwnerID, &b.StationID, &b.Title, &b.Message, &b.TargetRadiusKm, &b.StartDate, &b.EndDate, &b.BroadcastStatus, &b.TargetFuelTypes,
+		&b.CreatedAt, &b.Views, &b.Clicks, &b.CreatedBy, &b.UpdatedBy)
+}
+
 func (r *PgBroadcastRepository) Create(stationOwnerID string, input CreateBroadcastInput) (*models.Broadcast, error) {
 	id := uuid.New().String()
 	query := `
 		INSERT INTO broadcasts (
-			id, station_owner_id, station_id, title, message, target_radius_km, start_date, end_date, broadcast_status, target_fuel_types, created_at
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, 'scheduled', $9, NOW())
-		RETURNING id, station_owner_id, station_id, title, message, target_radius_km, start_date, end_date, broadcast_status, target_fuel_types, created_at, views, clicks`
+			id, station_owner_id, station_id, title, message, target_radius_km, start_date, end_date, broadcast_status, target_fuel_types, created_at, created_by, updated_by
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, 'scheduled', $9, NOW(), $10, $10)
+		RETURNING ` + broadcastColumns
 
 	var b models.Broadcast
-	err := r.db.QueryRow(query, id, stationOwnerID, input.StationID, input.Title, input.Message, input.TargetRadiusKm, input.StartDate, input.EndDate, input.TargetFuelTypes).Scan(
-		&b.ID, &b.StationOwnerID, &b.StationID, &b.Title, &b.Message, &b.TargetRadiusKm, &b.StartDate, &b.EndDate, &b.BroadcastStatus, &b.TargetFuelTypes, &b.CreatedAt, &b.Views, &b.Clicks,
-	)
+	err := scanBroadcast(r.db.QueryRow(query, id, stationOwnerID, input.StationID, input.Title, input.Message, input.TargetRadiusKm, input.StartDate, input.EndDate, input.TargetFuelTypes, nilIfEmpty(input.CreatedBy)), &b)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create broadcast: %w", err)
 	}
@@ -39,7 +45,7 @@ func (r *PgBroadcastRepository) Create(stationOwnerID string, input CreateBroadc
 
 func (r *PgBroadcastRepository) GetByOwnerID(stationOwnerID string) ([]models.Broadcast, error) {
 	query := `
-		SELECT id, station_owner_id, station_id, title, message, target_radius_km, start_date, end_date, broadcast_status, target_fuel_types, created_at, views, clicks
+		SELECT ` + broadcastColumns + `
 		FROM broadcasts WHERE station_owner_id = $1 ORDER BY created_at DESC LIMIT 100`
 
 	rows, err := r.db.Query(query, stationOwnerID)
@@ -51,7 +57,7 @@ func (r *PgBroadcastRepository) GetByOwnerID(stationOwnerID string) ([]models.Br
 	var broadcasts []models.Broadcast
 	for rows.Next() {
 		var b models.Broadcast
-		if err := rows.Scan(&b.ID, &b.StationOwnerID, &b.StationID, &b.Title, &b.Message, &b.TargetRadiusKm, &b.StartDate, &b.EndDate, &b.BroadcastStatus, &b.TargetFuelTypes, &b.CreatedAt, &b.Views, &b.Clicks); err != nil {
+		if err := scanBroadcast(rows, &b); err != nil {
 			return nil, fmt.Errorf("failed to scan broadcast: %w", err)
 		}
 		broadcasts = append(broadcasts, b)
@@ -66,10 +72,10 @@ func (r *PgBroadcastRepository) GetByOwnerID(stationOwnerID string) ([]models.Br
 
 func (r *PgBroadcastRepository) Update(id, ownerID string, input UpdateBroadcastInput) (string, error) {
 	query := `
-		UPDATE broadcasts SET title = COALESCE($1, title), message = COALESCE($2, message), target_radius_km = COALESCE($3, target_radius_km), start_date = COALESCE($4, start_date), end_date = COALESCE($5, end_date), broadcast_status = COALESCE($6, broadcast_status), target_fuel_types = COALESCE($7, target_fuel_types), updated_at = NOW() WHERE id = $8 AND station_owner_id = $9 RETURNING id`
+		UPDATE broadcasts SET title = COALESCE($1, title), message = COALESCE($2, message), target_radius_km = COALESCE($3, target_radius_km), start_date = COALESCE($4, start_date), end_date = COALESCE($5, end_date), broadcast_status = COALESCE($6, broadcast_status), target_fuel_types = COALESCE($7, target_fuel_types), updated_by = COALESCE($10, updated_by), updated_at = NOW() WHERE id = $8 AND station_owner_id = $9 RETURNING id`
 
 	var updatedID string
-	err := r.db.QueryRow(query, input.Title, input.Message, input.TargetRadiusKm, input.StartDate, input.EndDate, input.BroadcastStatus, input.TargetFuelTypes, id, ownerID).Scan(&updatedID)
+	err := r.db.QueryRow(query, input.Title, input.Message, input.TargetRadiusKm, input.StartDate, input.EndDate, input.BroadcastStatus, input.TargetFuelTypes, id, ownerID, nilIfEmpty(input.UpdatedBy)).Scan(&updatedID)
 	if err != nil {
 		return "", err
 	}
@@ -78,13 +84,11 @@ func (r *PgBroadcastRepository) Update(id, ownerID string, input UpdateBroadcast
 
 func (r *PgBroadcastRepository) GetByID(id, ownerID string) (*models.Broadcast, error) {
 	query := `
-		SELECT id, station_owner_id, station_id, title, message, target_radius_km, start_date, end_date, broadcast_status, target_fuel_types, created_at, views, clicks
+		SELECT ` + broadcastColumns + `
 		FROM broadcasts WHERE id = $1 AND station_owner_id = $2`
 
 	var b models.Broadcast
-	err := r.db.QueryRow(query, id, ownerID).Scan(
-		&b.ID, &b.StationOwnerID, &b.StationID, &b.Title, &b.Message, &b.TargetRadiusKm, &b.StartDate, &b.EndDate, &b.BroadcastStatus, &b.TargetFuelTypes, &b.CreatedAt, &b.Views, &b.Clicks,
-	)
+	err := scanBroadcast(r.db.QueryRow(query, id, ownerID), &b)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, fmt.Errorf("broadcast not found")
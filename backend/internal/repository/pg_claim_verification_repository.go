@@ -0,0 +1,343 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"gaspeep/backend/internal/models"
+	"github.com/google/uuid"
+)
+
+// activeClaimCondition holds for a claim cv that is approved and has not
+// expired. Claims approved without an expiry do not lapse.
+const activeClaimCondition = `cv.verification_status = 'approved' AND (cv.expires_at IS NULL OR cv.expires_at > NOW())`
+
+// PgClaimVerificationRepository is the PostgreSQL implementation of ClaimVerificationRepository.
+type PgClaimVerificationRepository struct {
+	db *sql.DB
+}
+
+func NewPgClaimVerificationRepository(db *sql.DB) *PgClaimVerificationRepository {
+	return &PgClaimVerificationRepository{db: db}
+}
+
+const claimVerificationColumns = `cv.id, cv.station_id, s.name, cv.station_owner_id, cv.verification_method, cv.verification_documents,
+	COALESCE(cv.phone_number, ''), COALESCE(cv.email, ''), cv.verification_status, cv.rejection_reason, cv.renews_id,
+	cv.verified_at, cv.verified_by, cv.expires_at, cv.created_at`
+
+func scanClaimVerification(row rowScanner) (*models.ClaimVerification, error) {
+	var c models.ClaimVerification
+	var documents sql.NullString
+	err := row.Scan(&c.ID, &c.StationID, &c.StationName, &c.StationOwnerID, &c.VerificationMethod, &documents,
+		&c.PhoneNumber, &c.Email, &c.Status, &c.RejectionReason, &c.RenewsID,
+		&c.VerifiedAt, &c.VerifiedBy, &c.ExpiresAt, &c.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	c.DocumentURLs = []string{}
+	if documents.Valid && documents.String != "" {
+		if err := json.Unmarshal([]byte(documents.String), &c.DocumentURLs); err != nil {
+			return nil, fmt.Errorf("failed to decode claim documents: %w", err)
+		}
+	}
+	return &c, nil
+}
+
+// claimDocumentsJSON encodes a claim's document URLs as stored, or nil if
+// there are none.
+func claimDocumentsJSON(urls []string) (*string, error) {
+	if len(urls) == 0 {
+		return nil, nil
+	}
+	b, err := json.Marshal(urls)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode claim documents: %w", err)
+	}
+	documents := string(b)
+	return &documents, nil
+}
+
+func (r *PgClaimVerificationRepository) ListClaims(status string) ([]models.ClaimVerification, error) {
+	rows, err := r.db.Query(`
+		SELECT `+claimVerificationColumns+`
+		FROM claim_verifications cv
+		JOIN stations s ON s.id = cv.station_id
+		WHERE cv.verification_status = $1
+		ORDER BY cv.created_at, cv.id`, status)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list claim verifications: %w", err)
+	}
+	defer rows.Close()
+
+	claims := []models.ClaimVerification{}
+	for rows.Next() {
+		c, err := scanClaimVerification(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan claim verification: %w", err)
+		}
+		claims = append(claims, *c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating claim verifications: %w", err)
+	}
+	return claims, nil
+}
+
+func getClaimVerification(tx *sql.Tx, id string, forUpdate bool) (*models.ClaimVerification, error) {
+	query := `
+		SELECT ` + claimVerificationColumns + `
+		FROM claim_verifications cv
+		JOIN stations s ON s.id = cv.station_id
+		WHERE cv.id::text = $1`
+	if forUpdate {
+		query += ` FOR UPDATE OF cv`
+	}
+	c, err := scanClaimVerification(tx.QueryRow(query, id))
+	if err == sql.ErrNoRows {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get claim verification: %w", err)
+	}
+	return c, nil
+}
+
+func (r *PgClaimVerificationRepository) CreateRenewal(userID, stationID string, evidence ClaimEvidence) (*models.ClaimVerification, error) {
+	documents, err := claimDocumentsJSON(evidence.DocumentURLs)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Locking the claim being renewed keeps two renewals from both seeing
+	// no pending one.
+	var renewsID, ownerID string
+	err = tx.QueryRow(`
+		SELECT cv.id, cv.station_owner_id
+		FROM claim_verifications cv
+		JOIN stations s ON s.id = cv.station_id AND s.owner_id = cv.station_owner_id
+		WHERE s.id::text = $2 AND cv.verification_status IN ('approved', 'expired') AND `+stationManagerCondition("$1")+`
+		ORDER BY cv.created_at DESC
+		LIMIT 1
+		FOR UPDATE OF cv`, userID, stationID).Scan(&renewsID, &ownerID)
+	if err == sql.ErrNoRows {
+		return nil, ErrNoClaimToRenew
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find claim to renew: %w", err)
+	}
+
+	var pending bool
+	err = tx.QueryRow(`
+		SELECT EXISTS(
+			SELECT 1 FROM claim_verifications
+			WHERE station_id::text = $1 AND station_owner_id = $2 AND verification_status = 'pending'
+		)`, stationID, ownerID).Scan(&pending)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check pending re-verification: %w", err)
+	}
+	if pending {
+		return nil, ErrClaimRenewalPending
+	}
+
+	id := uuid.New().String()
+	_, err = tx.Exec(`
+		INSERT INTO claim_verifications (
+			id, station_id, station_owner_id, verification_method, verification_documents,
+			phone_number, email, verification_status, renews_id
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, 'pending', $8)`,
+		id, stationID, ownerID, evidence.VerificationMethod, documents,
+		nilIfEmpty(evidence.PhoneNumber), nilIfEmpty(evidence.Email), renewsID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create re-verification: %w", err)
+	}
+
+	claim, err := getClaimVerification(tx, id, false)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return claim, nil
+}
+
+func (r *PgClaimVerificationRepository) ApproveClaim(id, reviewerID string, expiresAt time.Time) (*models.ClaimVerification, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	claim, err := getClaimVerification(tx, id, true)
+	if err != nil {
+		return nil, err
+	}
+	if claim.Status != models.ClaimVerificationPending {
+		return nil, ErrClaimNotPending
+	}
+
+	_, err = tx.Exec(`
+		UPDATE claim_verifications
+		SET verification_status = 'approved', verified_at = NOW(), verified_by = $3, expires_at = $2,
+			reminder_days_sent = NULL, rejection_reason = NULL, updated_at = NOW()
+		WHERE id = $1`, claim.ID, expiresAt, nilIfEmpty(reviewerID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to approve claim verification: %w", err)
+	}
+
+	_, err = tx.Exec(`
+		UPDATE claim_verifications
+		SET verification_status = 'renewed', updated_at = NOW()
+		WHERE station_id = $1 AND station_owner_id = $2 AND id <> $3 AND verification_status IN ('approved', 'expired')`,
+		claim.StationID, claim.StationOwnerID, claim.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to mark renewed claims: %w", err)
+	}
+
+	_, err = tx.Exec(`
+		UPDATE stations SET verification_status = 'verified'
+		WHERE id = $1 AND owner_id = $2`, claim.StationID, claim.StationOwnerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to mark station verified: %w", err)
+	}
+
+	claim, err = getClaimVerification(tx, claim.ID, false)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return claim, nil
+}
+
+func (r *PgClaimVerificationRepository) RejectClaim(id, reviewerID, reason string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	claim, err := getClaimVerification(tx, id, true)
+	if err != nil {
+		return err
+	}
+	if claim.Status != models.ClaimVerificationPending {
+		return ErrClaimNotPending
+	}
+
+	_, err = tx.Exec(`
+		UPDATE claim_verifications
+		SET verification_status = 'rejected', rejection_reason = $2, verified_by = $3, updated_at = NOW()
+		WHERE id = $1`, claim.ID, nilIfEmpty(reason), nilIfEmpty(reviewerID))
+	if err != nil {
+		return fmt.Errorf("failed to reject claim verification: %w", err)
+	}
+
+	if claim.RenewsID == nil {
+		_, err = tx.Exec(`
+			UPDATE stations SET verification_status = 'rejected'
+			WHERE id = $1 AND owner_id = $2`, claim.StationID, claim.StationOwnerID)
+		if err != nil {
+			return fmt.Errorf("failed to mark station rejected: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+func scanExpiringClaims(rows *sql.Rows) ([]ExpiringClaim, error) {
+	defer rows.Close()
+	var claims []ExpiringClaim
+	for rows.Next() {
+		var c ExpiringClaim
+		if err := rows.Scan(&c.ID, &c.StationID, &c.StationName, &c.OwnerUserID, &c.OwnerEmail, &c.ExpiresAt, &c.ReminderDaysSent); err != nil {
+			return nil, fmt.Errorf("failed to scan expiring claim: %w", err)
+		}
+		claims = append(claims, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating expiring claims: %w", err)
+	}
+	return claims, nil
+}
+
+func (r *PgClaimVerificationRepository) ListExpiring(before time.Time) ([]ExpiringClaim, error) {
+	rows, err := r.db.Query(`
+		SELECT cv.id, cv.station_id, s.name, so.user_id, u.email, cv.expires_at, cv.reminder_days_sent
+		FROM claim_verifications cv
+		JOIN station_owners so ON so.id = cv.station_owner_id
+		JOIN stations s ON s.id = cv.station_id AND s.owner_id = so.id
+		JOIN users u ON u.id = so.user_id
+		WHERE cv.verification_status = 'approved' AND cv.expires_at < $1
+			AND NOT EXISTS (
+				SELECT 1 FROM claim_verifications p
+				WHERE p.renews_id = cv.id AND p.verification_status = 'pending'
+			)
+		ORDER BY cv.expires_at, cv.id`, before)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list expiring claims: %w", err)
+	}
+	return scanExpiringClaims(rows)
+}
+
+func (r *PgClaimVerificationRepository) MarkReminderSent(id string, days int) error {
+	if _, err := r.db.Exec(`UPDATE claim_verifications SET reminder_days_sent = $2 WHERE id = $1`, id, days); err != nil {
+		return fmt.Errorf("failed to mark claim reminder sent: %w", err)
+	}
+	return nil
+}
+
+func (r *PgClaimVerificationRepository) ExpireLapsed(now time.Time) ([]ExpiringClaim, error) {
+	rows, err := r.db.Query(`
+		WITH lapsed AS (
+			UPDATE claim_verifications
+			SET verification_status = 'expired', updated_at = NOW()
+			WHERE verification_status = 'approved' AND expires_at <= $1
+			RETURNING id, station_id, station_owner_id, expires_at, reminder_days_sent
+		), unverified AS (
+			UPDATE stations s
+			SET verification_status = 'not_verified'
+			FROM lapsed l
+			WHERE s.id = l.station_id AND s.owner_id = l.station_owner_id
+		)
+		SELECT l.id, l.station_id, s.name, so.user_id, u.email, l.expires_at, l.reminder_days_sent
+		FROM lapsed l
+		JOIN station_owners so ON so.id = l.station_owner_id
+		JOIN stations s ON s.id = l.station_id AND s.owner_id = so.id
+		JOIN users u ON u.id = so.user_id
+		ORDER BY l.expires_at, l.id`, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to expire lapsed claims: %w", err)
+	}
+	return scanExpiringClaims(rows)
+}
+
+func (r *PgClaimVerificationRepository) HasActiveClaim(userID, stationID string) (bool, error) {
+	var ok bool
+	err := r.db.QueryRow(`
+		SELECT EXISTS(
+			SELECT 1
+			FROM stations s
+			INNER JOIN claim_verifications cv ON cv.station_id = s.id AND cv.station_owner_id = s.owner_id
+			WHERE s.id::text = $2 AND `+activeClaimCondition+` AND `+stationAccessCondition("$1", "")+`
+		)`, userID, stationID).Scan(&ok)
+	if err != nil {
+		return false, fmt.Errorf("failed to check station claim: %w", err)
+	}
+	return ok, nil
+}
+
+var _ ClaimVerificationRepository = (*PgClaimVerificationRepository)(nil)
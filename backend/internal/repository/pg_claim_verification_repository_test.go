@@ -0,0 +1,145 @@
+package repository
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"gaspeep/backend/internal/models"
+	"gaspeep/backend/internal/repository/testhelpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func stationVerificationStatus(t *testing.T, db *sql.DB, stationID string) string {
+	var status string
+	require.NoError(t, db.QueryRow("SELECT verification_status FROM stations WHERE id = $1", stationID).Scan(&status))
+	return status
+}
+
+// TestClaimVerification_RenewalLifecycle tests that a claim is approved for a
+// year, lapses, and is restored by an approved re-verification
+func TestClaimVerification_RenewalLifecycle(t *testing.T) {
+	db := testhelpers.SetupTestDBWithCleanup(t)
+
+	user := testhelpers.CreateTestUser(t, db)
+	station := testhelpers.CreateTestStation(t, db, -33.8568, 151.2153)
+	claimed, err := NewPgStationOwnerRepository(db).ClaimStation(user.ID, station.ID, "document", []string{"https://example.com/lease.pdf"}, "", "")
+	require.NoError(t, err)
+	repo := NewPgClaimVerificationRepository(db)
+
+	_, err = repo.CreateRenewal(user.ID, station.ID, ClaimEvidence{VerificationMethod: "phone"})
+	assert.ErrorIs(t, err, ErrNoClaimToRenew)
+
+	pending, err := repo.ListClaims(models.ClaimVerificationPending)
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	assert.Equal(t, []string{"https://example.com/lease.pdf"}, pending[0].DocumentURLs)
+
+	expiresAt := time.Now().Add(365 * 24 * time.Hour).Truncate(time.Second)
+	first, err := repo.ApproveClaim(claimed["id"].(string), user.ID, expiresAt)
+	require.NoError(t, err)
+	assert.Equal(t, models.ClaimVerificationApproved, first.Status)
+	require.NotNil(t, first.VerifiedBy)
+	assert.Equal(t, user.ID, *first.VerifiedBy)
+	require.NotNil(t, first.ExpiresAt)
+	assert.WithinDuration(t, expiresAt, *first.ExpiresAt, time.Second)
+	assert.Equal(t, "verified", stationVerificationStatus(t, db, station.ID))
+	_, err = repo.ApproveClaim(first.ID, user.ID, expiresAt)
+	assert.ErrorIs(t, err, ErrClaimNotPending)
+
+	active, err := repo.HasActiveClaim(user.ID, station.ID)
+	require.NoError(t, err)
+	assert.True(t, active)
+
+	// The claim lapses
+	_, err = db.Exec("UPDATE claim_verifications SET expires_at = NOW() - INTERVAL '1 minute' WHERE id = $1", first.ID)
+	require.NoError(t, err)
+	lapsed, err := repo.ExpireLapsed(time.Now())
+	require.NoError(t, err)
+	require.Len(t, lapsed, 1)
+	assert.Equal(t, user.Email, lapsed[0].OwnerEmail)
+	assert.Equal(t, station.Name, lapsed[0].StationName)
+	assert.Equal(t, "not_verified", stationVerificationStatus(t, db, station.ID))
+
+	active, err = repo.HasActiveClaim(user.ID, station.ID)
+	require.NoError(t, err)
+	assert.False(t, active)
+	editable, err := NewPgStationProfileRepository(db).IsApprovedOwner(user.ID, station.ID)
+	require.NoError(t, err)
+	assert.False(t, editable)
+
+	// Re-verifying reuses the claim evidence
+	renewal, err := repo.CreateRenewal(user.ID, station.ID, ClaimEvidence{VerificationMethod: "phone", PhoneNumber: "0290000000"})
+	require.NoError(t, err)
+	assert.Equal(t, models.ClaimVerificationPending, renewal.Status)
+	require.NotNil(t, renewal.RenewsID)
+	assert.Equal(t, first.ID, *renewal.RenewsID)
+	_, err = repo.CreateRenewal(user.ID, station.ID, ClaimEvidence{VerificationMethod: "phone"})
+	assert.ErrorIs(t, err, ErrClaimRenewalPending)
+
+	_, err = repo.ApproveClaim(renewal.ID, user.ID, expiresAt)
+	require.NoError(t, err)
+	active, err = repo.HasActiveClaim(user.ID, station.ID)
+	require.NoError(t, err)
+	assert.True(t, active)
+	assert.Equal(t, "verified", stationVerificationStatus(t, db, station.ID))
+
+	renewed, err := repo.ListClaims(models.ClaimVerificationRenewed)
+	require.NoError(t, err)
+	require.Len(t, renewed, 1)
+	assert.Equal(t, first.ID, renewed[0].ID)
+
+	// The owner's station list shows one entry for the current claim
+	stations, err := NewPgStationOwnerRepository(db).GetStationsByOwnerUserID(user.ID)
+	require.NoError(t, err)
+	require.Len(t, stations, 1)
+	assert.Equal(t, models.ClaimVerificationApproved, stations[0]["verificationStatus"])
+	assert.NotNil(t, stations[0]["verificationExpiresAt"])
+}
+
+// TestClaimVerification_Reminders tests which claims are listed for reminders
+func TestClaimVerification_Reminders(t *testing.T) {
+	db := testhelpers.SetupTestDBWithCleanup(t)
+
+	user := testhelpers.CreateTestUser(t, db)
+	station := testhelpers.CreateTestStation(t, db, -33.8568, 151.2153)
+	claimed, err := NewPgStationOwnerRepository(db).ClaimStation(user.ID, station.ID, "document", nil, "", "")
+	require.NoError(t, err)
+	repo := NewPgClaimVerificationRepository(db)
+
+	claim, err := repo.ApproveClaim(claimed["id"].(string), user.ID, time.Now().Add(10*24*time.Hour))
+	require.NoError(t, err)
+
+	expiring, err := repo.ListExpiring(time.Now().Add(30 * 24 * time.Hour))
+	require.NoError(t, err)
+	require.Len(t, expiring, 1)
+	assert.Equal(t, claim.ID, expiring[0].ID)
+	assert.Nil(t, expiring[0].ReminderDaysSent)
+
+	require.NoError(t, repo.MarkReminderSent(claim.ID, 30))
+	expiring, err = repo.ListExpiring(time.Now().Add(30 * 24 * time.Hour))
+	require.NoError(t, err)
+	require.Len(t, expiring, 1)
+	require.NotNil(t, expiring[0].ReminderDaysSent)
+	assert.Equal(t, 30, *expiring[0].ReminderDaysSent)
+
+	// Claims already being re-verified are not reminded
+	_, err = repo.CreateRenewal(user.ID, station.ID, ClaimEvidence{VerificationMethod: "document"})
+	require.NoError(t, err)
+	expiring, err = repo.ListExpiring(time.Now().Add(30 * 24 * time.Hour))
+	require.NoError(t, err)
+	assert.Empty(t, expiring)
+
+	// Rejecting the re-verification leaves the current claim approved
+	renewals, err := repo.ListClaims(models.ClaimVerificationPending)
+	require.NoError(t, err)
+	require.Len(t, renewals, 1)
+	require.NoError(t, repo.RejectClaim(renewals[0].ID, user.ID, "Unreadable"))
+	active, err := repo.HasActiveClaim(user.ID, station.ID)
+	require.NoError(t, err)
+	assert.True(t, active)
+	assert.Equal(t, "verified", stationVerificationStatus(t, db, station.ID))
+
+	assert.ErrorIs(t, repo.RejectClaim("00000000-0000-0000-0000-000000000000", user.ID, ""), sql.ErrNoRows)
+}
@@ -0,0 +1,282 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// PgEmailOutboxRepository is the PostgreSQL implementation of EmailOutboxRepository.
+type PgEmailOutboxRepository struct {
+	db *sql.DB
+}
+
+func NewPgEmailOutboxRepository(db *sql.DB) *PgEmailOutboxRepository {
+	return &PgEmailOutboxRepository{db: db}
+}
+
+// Enqueue stores the message and its first status log entry in one statement.
+func (r *PgEmailOutboxRepository) Enqueue(input EnqueueEmailInput) (string, error) {
+	id := uuid.New().String()
+	_, err := r.db.Exec(`
+		WITH message AS (
+			INSERT INTO email_messages (id, user_id, to_email, template, subject, html_body, text_body, list_unsubscribe_url, status, last_error)
+			SELECT $1::uuid, NULLIF($2::text, '')::uuid, $3::text, $4::text, $5::text, $6::text, $8::text, NULLIF($9::text, ''),
+				CASE WHEN s.email IS NULL THEN 'queued' ELSE 'failed' END,
+				CASE WHEN s.email IS NULL THEN NULL ELSE 'recipient is suppressed (' || s.reason || ')' END
+			FROM (SELECT 1) AS one
+			LEFT JOIN email_suppressions s ON s.email = LOWER($3::text)
+			RETURNING id, status, last_error
+		)
+		INSERT INTO email_message_events (id, message_id, status, detail)
+		SELECT $7::uuid, id, status, last_error FROM message`,
+		id, input.UserID, input.ToEmail, input.Template, input.Subject, input.HTMLBody, uuid.New().String(), input.TextBody, input.ListUnsubscribeURL,
+	)
+	if err != nil {
+		return "", fmt.Errorf("failed to enqueue email: %w", err)
+	}
+	return id, nil
+}
+
+// Claim uses SKIP LOCKED so concurrent workers never claim the same message,
+// and returns messages oldest first.
+func (r *PgEmailOutboxRepository) Claim(limit int, lease time.Duration) ([]QueuedEmail, error) {
+	rows, err := r.db.Query(`
+		WITH claimed AS (
+			UPDATE email_messages m
+			SET
+				status = 'sending',
+				attempts = m.attempts + 1,
+				locked_until = NOW() + make_interval(secs => $2),
+				updated_at = NOW()
+			WHERE m.id IN (
+				SELECT id FROM email_messages
+				WHERE (status = 'queued' AND next_attempt_at <= NOW())
+					OR (status = 'sending' AND locked_until < NOW())
+				ORDER BY created_at
+				LIMIT $1
+				FOR UPDATE SKIP LOCKED
+			)
+			RETURNING m.id, m.to_email, m.subject, m.html_body, m.text_body, m.list_unsubscribe_url, m.attempts, m.created_at
+		)
+		SELECT c.id, c.to_email, c.subject, c.html_body, c.text_body, COALESCE(c.list_unsubscribe_url, ''), c.attempts,
+			EXISTS (SELECT 1 FROM email_suppressions s WHERE s.email = LOWER(c.to_email))
+		FROM claimed c
+		ORDER BY c.created_at`,
+		limit, lease.Seconds(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim emails: %w", err)
+	}
+	defer rows.Close()
+
+	messages := make([]QueuedEmail, 0)
+	for rows.Next() {
+		var m QueuedEmail
+		if err := rows.Scan(&m.ID, &m.ToEmail, &m.Subject, &m.HTMLBody, &m.TextBody, &m.ListUnsubscribeURL, &m.Attempts, &m.Suppressed); err != nil {
+			return nil, fmt.Errorf("failed to scan email: %w", err)
+		}
+		messages = append(messages, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating emails: %w", err)
+	}
+
+	return messages, nil
+}
+
+// Renew matches on attempts because every claim increments them, so a worker
+// whose lease lapsed cannot renew a message another worker has claimed since.
+func (r *PgEmailOutboxRepository) Renew(id string, attempt int, lease time.Duration) (bool, error) {
+	res, err := r.db.Exec(`
+		UPDATE email_messages
+		SET locked_until = NOW() + make_interval(secs => $3), updated_at = NOW()
+		WHERE id = $1 AND status = 'sending' AND attempts = $2`,
+		id, attempt, lease.Seconds(),
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to renew email lease: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to renew email lease: %w", err)
+	}
+	return n > 0, nil
+}
+
+func (r *PgEmailOutboxRepository) MarkSent(id string) error {
+	return r.settle(id, `status = 'sent', sent_at = NOW(), last_error = NULL`, EmailStatusSent, "")
+}
+
+func (r *PgEmailOutboxRepository) Retry(id, lastError string, delay time.Duration) error {
+	return r.settle(id, `status = 'queued', next_attempt_at = NOW() + make_interval(secs => $5), last_error = $3`,
+		EmailStatusQueued, lastError, delay.Seconds())
+}
+
+func (r *PgEmailOutboxRepository) Fail(id, lastError string) error {
+	return r.settle(id, `status = 'failed', last_error = $3`, EmailStatusFailed, lastError)
+}
+
+// settle applies set to a claimed message and logs the new status, in one
+// statement so the log never disagrees with the message. set may refer to the
+// detail as $3 and to extra args from $5.
+func (r *PgEmailOutboxRepository) settle(id, set, status, detail string, extra ...any) error {
+	_, err := r.db.Exec(fmt.Sprintf(`
+		WITH updated AS (
+			UPDATE email_messages
+			SET %s, locked_until = NULL, updated_at = NOW()
+			WHERE id = $1 AND status = 'sending'
+			RETURNING id
+		)
+		INSERT INTO email_message_events (id, message_id, status, detail)
+		SELECT $2::uuid, id, $4::text, NULLIF($3::text, '') FROM updated`, set),
+		append([]any{id, uuid.New().String(), detail, status}, extra...)...,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to mark email %s: %w", status, err)
+	}
+	return nil
+}
+
+// RecordDeliveryEvent attributes the event to MessageID, or otherwise to the
+// most recent message sent to Email.
+func (r *PgEmailOutboxRepository) RecordDeliveryEvent(event EmailDeliveryEvent) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var messageID, toEmail string
+	if event.MessageID != "" {
+		err = tx.QueryRow(`SELECT id, to_email FROM email_messages WHERE id = $1 FOR UPDATE`, event.MessageID).Scan(&messageID, &toEmail)
+	} else {
+		err = tx.QueryRow(`
+			SELECT id, to_email FROM email_messages
+			WHERE LOWER(to_email) = LOWER($1) AND status IN ('sent', 'bounced')
+			ORDER BY sent_at DESC
+			LIMIT 1
+			FOR UPDATE`,
+			event.Email,
+		).Scan(&messageID, &toEmail)
+	}
+	if err != nil && err != sql.ErrNoRows {
+		return fmt.Errorf("failed to find email for delivery event: %w", err)
+	}
+	if toEmail == "" {
+		toEmail = event.Email
+	}
+
+	if messageID != "" {
+		logStatus, set := EmailStatusComplained, ""
+		if event.Type == EmailEventBounce {
+			logStatus, set = EmailStatusBounced, `status = 'bounced', `
+		}
+		_, err = tx.Exec(fmt.Sprintf(`UPDATE email_messages SET %supdated_at = NOW() WHERE id = $1`, set), messageID)
+		if err != nil {
+			return fmt.Errorf("failed to update email status: %w", err)
+		}
+		_, err = tx.Exec(`
+			INSERT INTO email_message_events (id, message_id, status, detail)
+			VALUES ($1, $2, $3, NULLIF($4, ''))`,
+			uuid.New().String(), messageID, logStatus, event.Detail,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to log email delivery event: %w", err)
+		}
+	}
+
+	if toEmail != "" && (event.Type == EmailEventComplaint || event.Permanent) {
+		_, err = tx.Exec(`
+			INSERT INTO email_suppressions (email, reason, detail)
+			VALUES (LOWER($1), $2, NULLIF($3, ''))
+			ON CONFLICT (email) DO NOTHING`,
+			strings.TrimSpace(toEmail), event.Type, event.Detail,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to suppress email address: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// ListMessages returns matching messages newest first with their status logs,
+// and the total number of matches.
+func (r *PgEmailOutboxRepository) ListMessages(filter EmailMessageFilter, limit, offset int) ([]EmailMessageLog, int, error) {
+	where := `(($1::text <> '' AND user_id = NULLIF($1::text, '')::uuid) OR ($2::text <> '' AND LOWER(to_email) = LOWER($2::text)))`
+
+	var total int
+	if err := r.db.QueryRow(`SELECT COUNT(*) FROM email_messages WHERE `+where, filter.UserID, filter.Email).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count emails: %w", err)
+	}
+
+	rows, err := r.db.Query(`
+		SELECT id, user_id, to_email, template, subject, status, attempts, last_error, created_at, sent_at
+		FROM email_messages
+		WHERE `+where+`
+		ORDER BY created_at DESC, id
+		LIMIT $3 OFFSET $4`,
+		filter.UserID, filter.Email, limit, offset,
+	)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query emails: %w", err)
+	}
+	defer rows.Close()
+
+	messages := make([]EmailMessageLog, 0)
+	index := make(map[string]int)
+	ids := make([]string, 0)
+	for rows.Next() {
+		var m EmailMessageLog
+		if err := rows.Scan(&m.ID, &m.UserID, &m.ToEmail, &m.Template, &m.Subject, &m.Status, &m.Attempts, &m.LastError, &m.CreatedAt, &m.SentAt); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan email: %w", err)
+		}
+		m.Events = make([]EmailStatusEvent, 0)
+		index[m.ID] = len(messages)
+		ids = append(ids, m.ID)
+		messages = append(messages, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("error iterating emails: %w", err)
+	}
+	if len(ids) == 0 {
+		return messages, total, nil
+	}
+
+	eventRows, err := r.db.Query(`
+		SELECT message_id, status, detail, created_at
+		FROM email_message_events
+		WHERE message_id = ANY($1::uuid[])
+		ORDER BY created_at, id`,
+		pq.Array(ids),
+	)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query email status log: %w", err)
+	}
+	defer eventRows.Close()
+
+	for eventRows.Next() {
+		var messageID string
+		var e EmailStatusEvent
+		if err := eventRows.Scan(&messageID, &e.Status, &e.Detail, &e.CreatedAt); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan email status: %w", err)
+		}
+		i := index[messageID]
+		messages[i].Events = append(messages[i].Events, e)
+	}
+	if err := eventRows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("error iterating email status log: %w", err)
+	}
+
+	return messages, total, nil
+}
+
+var _ EmailOutboxRepository = (*PgEmailOutboxRepository)(nil)
@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"gaspeep/backend/internal/repository/testhelpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmailOutbox_DeliveryAndStatusLog(t *testing.T) {
+	db := testhelpers.SetupTestDBWithCleanup(t)
+
+	user := testhelpers.CreateTestUser(t, db)
+	repo := NewPgEmailOutboxRepository(db)
+
+	id, err := repo.Enqueue(EnqueueEmailInput{UserID: user.ID, ToEmail: user.Email, Template: "password_reset", Subject: "Reset", HTMLBody: "<p>hi</p>"})
+	require.NoError(t, err)
+
+	claimed, err := repo.Claim(10, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+	assert.Equal(t, id, claimed[0].ID)
+	assert.Equal(t, 1, claimed[0].Attempts)
+	assert.False(t, claimed[0].Suppressed)
+
+	assert.Empty(t, claimed[0].ListUnsubscribeURL)
+
+	require.NoError(t, repo.Retry(id, "connection refused", 0))
+	claimed, err = repo.Claim(10, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+
+	// Only the latest claim can renew its lease
+	held, err := repo.Renew(id, 1, time.Minute)
+	require.NoError(t, err)
+	assert.False(t, held)
+	held, err = repo.Renew(id, claimed[0].Attempts, time.Minute)
+	require.NoError(t, err)
+	assert.True(t, held)
+	require.NoError(t, repo.MarkSent(id))
+
+	messages, total, err := repo.ListMessages(EmailMessageFilter{UserID: user.ID}, 20, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 1, total)
+	require.Len(t, messages, 1)
+	assert.Equal(t, EmailStatusSent, messages[0].Status)
+	assert.Equal(t, 2, messages[0].Attempts)
+	require.Len(t, messages[0].Events, 3)
+	assert.Equal(t, EmailStatusQueued, messages[0].Events[0].Status)
+	assert.Equal(t, EmailStatusQueued, messages[0].Events[1].Status)
+	assert.Equal(t, EmailStatusSent, messages[0].Events[2].Status)
+}
+
+func TestEmailOutbox_KeepsListUnsubscribeURL(t *testing.T) {
+	db := testhelpers.SetupTestDBWithCleanup(t)
+
+	user := testhelpers.CreateTestUser(t, db)
+	repo := NewPgEmailOutboxRepository(db)
+
+	unsubscribeURL := "https://api.example.com/api/email/unsubscribe?token=t"
+	_, err := repo.Enqueue(EnqueueEmailInput{UserID: user.ID, ToEmail: user.Email, Template: "price_alert", Subject: "Alert", HTMLBody: "<p>hi</p>", ListUnsubscribeURL: unsubscribeURL})
+	require.NoError(t, err)
+
+	claimed, err := repo.Claim(10, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+	assert.Equal(t, unsubscribeURL, claimed[0].ListUnsubscribeURL)
+}
+
+func TestEmailOutbox_BounceSuppressesAddress(t *testing.T) {
+	db := testhelpers.SetupTestDBWithCleanup(t)
+
+	user := testhelpers.CreateTestUser(t, db)
+	repo := NewPgEmailOutboxRepository(db)
+
+	id, err := repo.Enqueue(EnqueueEmailInput{UserID: user.ID, ToEmail: user.Email, Template: "welcome", Subject: "Welcome", HTMLBody: "<p>hi</p>"})
+	require.NoError(t, err)
+	_, err = repo.Claim(10, time.Minute)
+	require.NoError(t, err)
+	require.NoError(t, repo.MarkSent(id))
+
+	// The provider reports the bounce by address only
+	require.NoError(t, repo.RecordDeliveryEvent(EmailDeliveryEvent{Type: EmailEventBounce, Email: user.Email, Permanent: true, Detail: "user unknown"}))
+
+	// Later mail to the address is never sent
+	_, err = repo.Enqueue(EnqueueEmailInput{ToEmail: user.Email, Template: "welcome", Subject: "Welcome", HTMLBody: "<p>hi</p>"})
+	require.NoError(t, err)
+	claimed, err := repo.Claim(10, time.Minute)
+	require.NoError(t, err)
+	assert.Empty(t, claimed)
+
+	messages, total, err := repo.ListMessages(EmailMessageFilter{Email: user.Email}, 20, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 2, total)
+	assert.Equal(t, EmailStatusFailed, messages[0].Status)
+	assert.Equal(t, EmailStatusBounced, messages[1].Status)
+}
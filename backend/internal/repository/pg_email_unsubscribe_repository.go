@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// PgEmailUnsubscribeRepository is the PostgreSQL implementation of EmailUnsubscribeRepository.
+type PgEmailUnsubscribeRepository struct {
+	db *sql.DB
+}
+
+func NewPgEmailUnsubscribeRepository(db *sql.DB) *PgEmailUnsubscribeRepository {
+	return &PgEmailUnsubscribeRepository{db: db}
+}
+
+func (r *PgEmailUnsubscribeRepository) Record(userID string, scope UnsubscribeScope, method string) error {
+	_, err := r.db.Exec(`
+		INSERT INTO email_unsubscribes (id, user_id, category, scope_id, method)
+		VALUES ($1, $2, $3, NULLIF($4, '')::uuid, $5)
+		ON CONFLICT DO NOTHING`,
+		uuid.New().String(), userID, scope.Category, scope.ScopeID, method,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record email unsubscribe: %w", err)
+	}
+	return nil
+}
+
+func (r *PgEmailUnsubscribeRepository) IsUnsubscribed(userID string, scopes ...UnsubscribeScope) (bool, error) {
+	if len(scopes) == 0 {
+		return false, nil
+	}
+	categories := make([]string, len(scopes))
+	scopeIDs := make([]string, len(scopes))
+	for i, s := range scopes {
+		categories[i] = s.Category
+		scopeIDs[i] = s.ScopeID
+	}
+
+	var unsubscribed bool
+	err := r.db.QueryRow(`
+		SELECT EXISTS (
+			SELECT 1
+			FROM email_unsubscribes u
+			JOIN unnest($2::text[], $3::text[]) AS s(category, scope_id)
+				ON u.category = s.category
+				AND u.scope_id IS NOT DISTINCT FROM NULLIF(s.scope_id, '')::uuid
+			WHERE u.user_id = $1
+		)`,
+		userID, pq.Array(categories), pq.Array(scopeIDs),
+	).Scan(&unsubscribed)
+	if err != nil {
+		return false, fmt.Errorf("failed to check email unsubscribes: %w", err)
+	}
+	return unsubscribed, nil
+}
+
+var _ EmailUnsubscribeRepository = (*PgEmailUnsubscribeRepository)(nil)
@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"testing"
+
+	"gaspeep/backend/internal/repository/testhelpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmailUnsubscribe_RecordAndCheck(t *testing.T) {
+	db := testhelpers.SetupTestDBWithCleanup(t)
+
+	user := testhelpers.CreateTestUser(t, db)
+	alert := testhelpers.CreateTestAlert(t, db, user.ID, -33.8688, 151.2093)
+	repo := NewPgEmailUnsubscribeRepository(db)
+
+	alertScope := UnsubscribeScope{Category: UnsubscribeCategoryAlert, ScopeID: alert.ID}
+	allAlerts := UnsubscribeScope{Category: UnsubscribeCategoryAllAlerts}
+
+	unsubscribed, err := repo.IsUnsubscribed(user.ID, alertScope, allAlerts)
+	require.NoError(t, err)
+	assert.False(t, unsubscribed)
+
+	require.NoError(t, repo.Record(user.ID, alertScope, UnsubscribeMethodOneClick))
+	// Repeating an opt-out is a no-op
+	require.NoError(t, repo.Record(user.ID, alertScope, UnsubscribeMethodLink))
+
+	unsubscribed, err = repo.IsUnsubscribed(user.ID, alertScope, allAlerts)
+	require.NoError(t, err)
+	assert.True(t, unsubscribed)
+
+	// Opting out of one alert leaves the others subscribed
+	unsubscribed, err = repo.IsUnsubscribed(user.ID, allAlerts)
+	require.NoError(t, err)
+	assert.False(t, unsubscribed)
+
+	require.NoError(t, repo.Record(user.ID, allAlerts, UnsubscribeMethodLink))
+	unsubscribed, err = repo.IsUnsubscribed(user.ID, allAlerts)
+	require.NoError(t, err)
+	assert.True(t, unsubscribed)
+
+	var count int
+	require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM email_unsubscribes WHERE user_id = $1`, user.ID).Scan(&count))
+	assert.Equal(t, 2, count)
+}
@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PgEmailVerificationRepository is the PostgreSQL implementation of EmailVerificationRepository.
+type PgEmailVerificationRepository struct {
+	db *sql.DB
+}
+
+func NewPgEmailVerificationRepository(db *sql.DB) *PgEmailVerificationRepository {
+	return &PgEmailVerificationRepository{db: db}
+}
+
+func (r *PgEmailVerificationRepository) Create(userID, email, tokenHash string, expiresAt time.Time) error {
+	_, err := r.db.Exec(`
+		INSERT INTO email_verification_tokens (id, user_id, email, token_hash, expires_at)
+		VALUES ($1, $2, $3, $4, $5)`,
+		uuid.New().String(), userID, email, tokenHash, expiresAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create email verification token: %w", err)
+	}
+	return nil
+}
+
+func (r *PgEmailVerificationRepository) CountIssuedSince(userID string, since time.Time) (int, *time.Time, error) {
+	var count int
+	var latest *time.Time
+	err := r.db.QueryRow(`
+		SELECT COUNT(*), MAX(created_at)
+		FROM email_verification_tokens
+		WHERE user_id = $1 AND created_at >= $2`,
+		userID, since,
+	).Scan(&count, &latest)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to count email verification tokens: %w", err)
+	}
+	return count, latest, nil
+}
+
+// Consume marks the token used and the user verified in one statement, so a
+// token can never be used twice. The self-join reads the user's verified flag
+// from before the update.
+func (r *PgEmailVerificationRepository) Consume(tokenHash string) (*ConsumedEmailVerification, error) {
+	var v ConsumedEmailVerification
+	err := r.db.QueryRow(`
+		WITH token AS (
+			UPDATE email_verification_tokens
+			SET used_at = NOW()
+			WHERE token_hash = $1 AND used_at IS NULL AND expires_at > NOW()
+			RETURNING user_id, email
+		)
+		UPDATE users u
+		SET email_verified = TRUE, updated_at = NOW()
+		FROM token, users before
+		WHERE u.id = token.user_id AND before.id = u.id AND LOWER(u.email) = LOWER(token.email)
+		RETURNING u.id, u.email, u.display_name, before.email_verified`,
+		tokenHash,
+	).Scan(&v.UserID, &v.Email, &v.DisplayName, &v.WasVerified)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, sql.ErrNoRows
+		}
+		return nil, fmt.Errorf("failed to consume email verification token: %w", err)
+	}
+	return &v, nil
+}
+
+func (r *PgEmailVerificationRepository) DeleteExpiredBefore(cutoff time.Time) (int64, error) {
+	result, err := r.db.Exec(`DELETE FROM email_verification_tokens WHERE expires_at < $1`, cutoff)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired email verification tokens: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+var _ EmailVerificationRepository = (*PgEmailVerificationRepository)(nil)
@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"gaspeep/backend/internal/repository/testhelpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmailVerification_ConsumeIsSingleUse(t *testing.T) {
+	db := testhelpers.SetupTestDBWithCleanup(t)
+
+	user := testhelpers.CreateTestUser(t, db)
+	repo := NewPgEmailVerificationRepository(db)
+
+	require.NoError(t, repo.Create(user.ID, user.Email, "hash-1", time.Now().Add(time.Hour)))
+
+	count, latest, err := repo.CountIssuedSince(user.ID, time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+	require.NotNil(t, latest)
+
+	verified, err := repo.Consume("hash-1")
+	require.NoError(t, err)
+	assert.Equal(t, user.ID, verified.UserID)
+	assert.False(t, verified.WasVerified)
+
+	var emailVerified bool
+	require.NoError(t, db.QueryRow(`SELECT email_verified FROM users WHERE id = $1`, user.ID).Scan(&emailVerified))
+	assert.True(t, emailVerified)
+
+	_, err = repo.Consume("hash-1")
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestEmailVerification_RejectsExpiredAndStaleTokens(t *testing.T) {
+	db := testhelpers.SetupTestDBWithCleanup(t)
+
+	user := testhelpers.CreateTestUser(t, db)
+	repo := NewPgEmailVerificationRepository(db)
+
+	require.NoError(t, repo.Create(user.ID, user.Email, "expired", time.Now().Add(-time.Minute)))
+	_, err := repo.Consume("expired")
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+
+	// A token issued for a previous address must not verify the new one
+	require.NoError(t, repo.Create(user.ID, "old-"+user.Email, "stale", time.Now().Add(time.Hour)))
+	_, err = repo.Consume("stale")
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+}
@@ -0,0 +1,160 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PgFavouriteStationRepository is the PostgreSQL implementation of FavouriteStationRepository.
+type PgFavouriteStationRepository struct {
+	db *sql.DB
+}
+
+func NewPgFavouriteStationRepository(db *sql.DB) *PgFavouriteStationRepository {
+	return &PgFavouriteStationRepository{db: db}
+}
+
+// Add returns sql.ErrNoRows when the station does not exist. Adding a station
+// that is already a favourite is a no-op.
+func (r *PgFavouriteStationRepository) Add(userID, stationID string) error {
+	var exists bool
+	if err := r.db.QueryRow(`SELECT EXISTS(SELECT 1 FROM stations WHERE id = $1)`, stationID).Scan(&exists); err != nil {
+		return fmt.Errorf("failed to check station: %w", err)
+	}
+	if !exists {
+		return sql.ErrNoRows
+	}
+
+	_, err := r.db.Exec(`
+		INSERT INTO favourite_stations (id, user_id, station_id, created_at)
+		VALUES ($1, $2, $3, NOW())
+		ON CONFLICT (user_id, station_id) DO NOTHING`,
+		uuid.New().String(), userID, stationID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to add favourite station: %w", err)
+	}
+	return nil
+}
+
+func (r *PgFavouriteStationRepository) Remove(userID, stationID string) (bool, error) {
+	result, err := r.db.Exec(`DELETE FROM favourite_stations WHERE user_id = $1 AND station_id = $2`, userID, stationID)
+	if err != nil {
+		return false, fmt.Errorf("failed to remove favourite station: %w", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	return rowsAffected > 0, nil
+}
+
+func (r *PgFavouriteStationRepository) GetByUserID(userID string) ([]FavouriteStationResult, error) {
+	rows, err := r.db.Query(`
+		SELECT
+			s.id::text,
+			s.name,
+			COALESCE(s.brand, ''),
+			COALESCE(s.address, ''),
+			ST_Y(s.location::geometry) AS latitude,
+			ST_X(s.location::geometry) AS longitude,
+			f.created_at,
+			fp.fuel_type_id::text,
+			ft.display_name,
+			fp.price,
+			fp.currency,
+			fp.unit,
+			fp.last_updated_at,
+			fp.verification_status
+		FROM favourite_stations f
+		INNER JOIN stations s ON s.id = f.station_id
+		LEFT JOIN fuel_prices fp ON fp.station_id = s.id
+		LEFT JOIN fuel_types ft ON ft.id = fp.fuel_type_id
+		WHERE f.user_id = $1
+		ORDER BY f.created_at DESC, s.id, ft.display_order`,
+		userID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query favourite stations: %w", err)
+	}
+	defer rows.Close()
+
+	favourites := make([]FavouriteStationResult, 0)
+	for rows.Next() {
+		var station FavouriteStationResult
+		var fuelTypeID, fuelTypeName, currency, unit, verificationStatus sql.NullString
+		var price sql.NullFloat64
+		var lastUpdatedAt *time.Time
+		if err := rows.Scan(
+			&station.StationID, &station.Name, &station.Brand, &station.Address,
+			&station.Latitude, &station.Longitude, &station.AddedAt,
+			&fuelTypeID, &fuelTypeName, &price, &currency, &unit, &lastUpdatedAt, &verificationStatus,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan favourite station: %w", err)
+		}
+
+		if n := len(favourites); n == 0 || favourites[n-1].StationID != station.StationID {
+			station.Prices = make([]FavouriteStationPrice, 0)
+			favourites = append(favourites, station)
+		}
+		if fuelTypeID.Valid {
+			last := &favourites[len(favourites)-1]
+			last.Prices = append(last.Prices, FavouriteStationPrice{
+				FuelTypeID:         fuelTypeID.String,
+				FuelTypeName:       fuelTypeName.String,
+				Price:              price.Float64,
+				Currency:           currency.String,
+				Unit:               unit.String,
+				LastUpdatedAt:      lastUpdatedAt,
+				VerificationStatus: verificationStatus.String,
+			})
+		}
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating favourite stations: %w", err)
+	}
+
+	return favourites, nil
+}
+
+func (r *PgFavouriteStationRepository) GetPrices(userID, fuelTypeID string) ([]FavouritePriceResult, error) {
+	rows, err := r.db.Query(`
+		SELECT
+			s.id::text,
+			s.name,
+			COALESCE(s.brand, ''),
+			fp.price,
+			fp.currency,
+			fp.unit,
+			fp.last_updated_at
+		FROM favourite_stations f
+		INNER JOIN stations s ON s.id = f.station_id
+		INNER JOIN fuel_prices fp ON fp.station_id = s.id AND fp.fuel_type_id = $2
+		WHERE f.user_id = $1
+		ORDER BY fp.price ASC, s.name ASC`,
+		userID, fuelTypeID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query favourite prices: %w", err)
+	}
+	defer rows.Close()
+
+	prices := make([]FavouritePriceResult, 0)
+	for rows.Next() {
+		var p FavouritePriceResult
+		if err := rows.Scan(&p.StationID, &p.StationName, &p.StationBrand, &p.Price, &p.Currency, &p.Unit, &p.LastUpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan favourite price: %w", err)
+		}
+		prices = append(prices, p)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating favourite prices: %w", err)
+	}
+
+	return prices, nil
+}
+
+var _ FavouriteStationRepository = (*PgFavouriteStationRepository)(nil)
@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"database/sql"
+	"testing"
+
+	"gaspeep/backend/internal/repository/testhelpers"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFavouriteStations_AddListAndRemove(t *testing.T) {
+	db := testhelpers.SetupTestDBWithCleanup(t)
+
+	user := testhelpers.CreateTestUser(t, db)
+	station := testhelpers.CreateTestStation(t, db, -33.8568, 151.2153)
+	fuelTypeID := testhelpers.CreateTestFuelType(t, db, "U91")
+	testhelpers.CreateTestFuelPrice(t, db, station.ID, fuelTypeID, 1.79)
+
+	repo := NewPgFavouriteStationRepository(db)
+	require.NoError(t, repo.Add(user.ID, station.ID))
+	// Adding twice is a no-op
+	require.NoError(t, repo.Add(user.ID, station.ID))
+
+	favourites, err := repo.GetByUserID(user.ID)
+	require.NoError(t, err)
+	require.Len(t, favourites, 1)
+	assert.Equal(t, station.ID, favourites[0].StationID)
+	require.Len(t, favourites[0].Prices, 1)
+	assert.InDelta(t, 1.79, favourites[0].Prices[0].Price, 0.0001)
+
+	prices, err := repo.GetPrices(user.ID, fuelTypeID)
+	require.NoError(t, err)
+	require.Len(t, prices, 1)
+
+	removed, err := repo.Remove(user.ID, station.ID)
+	require.NoError(t, err)
+	assert.True(t, removed)
+}
+
+func TestFavouriteStations_AddMissingStation(t *testing.T) {
+	db := testhelpers.SetupTestDBWithCleanup(t)
+
+	user := testhelpers.CreateTestUser(t, db)
+	repo := NewPgFavouriteStationRepository(db)
+
+	err := repo.Add(user.ID, uuid.New().String())
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+}
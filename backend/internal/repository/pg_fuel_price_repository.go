@@ -24,7 +24,7 @@ func (r *PgFuelPriceRepository) GetFuelPrices(filters FuelPriceFilters) ([]FuelP
 
 	query := `
 		SELECT fp.id, fp.station_id, fp.fuel_type_id, fp.price, fp.currency, fp.unit,
-			fp.last_updated_at, fp.verification_status, fp.confirmation_count,
+			fp.last_updated_at, fp.verification_status, fp.confirmation_count, fp.source,
 			s.latitude, s.longitude`
 
 	if hasGeo {
@@ -107,7 +107,7 @@ func (r *PgFuelPriceRepository) GetFuelPrices(filters FuelPriceFilters) ([]FuelP
 
 		scanArgs := []interface{}{
 			&fp.ID, &fp.StationID, &fp.FuelTypeID, &fp.Price, &fp.Currency, &fp.Unit,
-			&fp.LastUpdatedAt, &fp.VerificationStatus, &fp.ConfirmationCount,
+			&fp.LastUpdatedAt, &fp.VerificationStatus, &fp.ConfirmationCount, &fp.Source,
 			&stationLat, &stationLon,
 		}
 
@@ -136,7 +136,7 @@ func (r *PgFuelPriceRepository) GetFuelPrices(filters FuelPriceFilters) ([]FuelP
 func (r *PgFuelPriceRepository) GetStationPrices(stationID string) ([]StationPriceResult, error) {
 	query := `
 		SELECT fp.id, fp.station_id, fp.fuel_type_id, fp.price, fp.currency, fp.unit,
-			fp.last_updated_at, fp.verification_status, fp.confirmation_count,
+			fp.last_updated_at, fp.verification_status, fp.confirmation_count, fp.source,
 			ft.name, ft.display_name, ft.color_code
 		FROM fuel_prices fp
 		INNER JOIN fuel_types ft ON fp.fuel_type_id = ft.id
@@ -155,7 +155,7 @@ func (r *PgFuelPriceRepository) GetStationPrices(stationID string) ([]StationPri
 		var sp StationPriceResult
 		if err := rows.Scan(
 			&sp.ID, &sp.StationID, &sp.FuelTypeID, &sp.Price, &sp.Currency, &sp.Unit,
-			&sp.LastUpdatedAt, &sp.VerificationStatus, &sp.ConfirmationCount,
+			&sp.LastUpdatedAt, &sp.VerificationStatus, &sp.ConfirmationCount, &sp.Source,
 			&sp.FuelTypeName, &sp.FuelTypeDisplayName, &sp.FuelTypeColorCode,
 		); err != nil {
 			return nil, fmt.Errorf("failed to scan station price: %w", err)
@@ -174,7 +174,7 @@ func (r *PgFuelPriceRepository) GetCheapestPrices(lat, lon, radiusKm float64) ([
 	query := `
 		WITH nearby_prices AS (
 			SELECT fp.id, fp.station_id, fp.fuel_type_id, fp.price, fp.currency, fp.unit,
-				fp.last_updated_at, fp.verification_status, fp.confirmation_count,
+				fp.last_updated_at, fp.verification_status, fp.confirmation_count, fp.source,
 				s.name as station_name, s.brand as station_brand,
 				ST_Y(s.location::geometry) as latitude,
 				ST_X(s.location::geometry) as longitude,
@@ -199,7 +199,7 @@ func (r *PgFuelPriceRepository) GetCheapestPrices(lat, lon, radiusKm float64) ([
 			FROM nearby_prices
 		)
 		SELECT id, station_id, fuel_type_id, price, currency, unit,
-			last_updated_at, verification_status, confirmation_count,
+			last_updated_at, verification_status, confirmation_count, source,
 			station_name, station_brand, latitude, longitude, distance_km, fuel_type_name
 		FROM ranked_prices
 		WHERE rank = 1
@@ -218,7 +218,7 @@ func (r *PgFuelPriceRepository) GetCheapestPrices(lat, lon, radiusKm float64) ([
 
 		if err := rows.Scan(
 			&cp.ID, &cp.StationID, &cp.FuelTypeID, &cp.Price, &cp.Currency, &cp.Unit,
-			&lastUpdatedAt, &cp.VerificationStatus, &cp.ConfirmationCount,
+			&lastUpdatedAt, &cp.VerificationStatus, &cp.ConfirmationCount, &cp.Source,
 			&cp.StationName, &cp.StationBrand, &cp.Latitude, &cp.Longitude, &cp.DistanceKm, &cp.FuelTypeName,
 		); err != nil {
 			return nil, fmt.Errorf("failed to scan cheapest price: %w", err)
@@ -238,17 +238,45 @@ func (r *PgFuelPriceRepository) GetCheapestPrices(lat, lon, radiusKm float64) ([
 	return prices, nil
 }
 
-func (r *PgFuelPriceRepository) UpsertFuelPrice(stationID, fuelTypeID string, price float64) error {
-	_, err := r.db.Exec(`
-		INSERT INTO fuel_prices (id, station_id, fuel_type_id, price, currency, unit, last_updated_at, verification_status, confirmation_count)
-		VALUES ($1, $2, $3, $4, 'AUD', 'litre', NOW(), 'verified', 1)
+// upsertFuelPriceSQL stores a verified price from source $6 and queues a
+// price change event for alert evaluation in the same statement, so the event
+// exists if and only if the price was written. The event's previous price is
+// the one replaced, locked so concurrent writes queue in order, while the
+// stored previous price only moves when the price changes. A new or changed
+// price is also added to the price history. Its arguments are the price ID,
+// station ID, fuel type ID, price, event ID, source and the ID of the user
+// who changed the price, or nil.
+const upsertFuelPriceSQL = `
+	WITH existing AS (
+		SELECT price FROM fuel_prices WHERE station_id = $2 AND fuel_type_id = $3 FOR UPDATE
+	), upserted AS (
+		INSERT INTO fuel_prices (id, station_id, fuel_type_id, price, currency, unit, last_updated_at, verification_status, confirmation_count, source, changed_by)
+		VALUES ($1, $2, $3, $4, 'AUD', 'litre', NOW(), 'verified', 1, $6, $7)
 		ON CONFLICT (station_id, fuel_type_id)
-		DO UPDATE SET price = $4, last_updated_at = NOW(),
+		DO UPDATE SET previous_price = CASE WHEN fuel_prices.price IS DISTINCT FROM EXCLUDED.price
+				THEN fuel_prices.price ELSE fuel_prices.previous_price END,
+			price = $4, last_updated_at = NOW(),
 			verification_status = 'verified',
 			confirmation_count = fuel_prices.confirmation_count + 1,
+			source = $6,
+			changed_by = $7,
 			updated_at = NOW()
-	`, uuid.New().String(), stationID, fuelTypeID, price)
+		RETURNING station_id, fuel_type_id, price
+	), changed AS (
+		SELECT u.station_id, u.fuel_type_id, u.price, e.price AS previous_price
+		FROM upserted u LEFT JOIN existing e ON true
+	), recorded AS (
+		INSERT INTO fuel_price_history (id, station_id, fuel_type_id, price, previous_price, source)
+		SELECT gen_random_uuid(), station_id, fuel_type_id, price, previous_price, $6 FROM changed
+		WHERE previous_price IS DISTINCT FROM price
+	)
+	INSERT INTO price_change_events (id, station_id, fuel_type_id, price, previous_price, source, changed_by)
+	SELECT $5, station_id, fuel_type_id, price, previous_price, $6, $7 FROM changed
+`
 
+// UpsertFuelPrice stores a price confirmed by community submissions.
+func (r *PgFuelPriceRepository) UpsertFuelPrice(stationID, fuelTypeID string, price float64) error {
+	_, err := r.db.Exec(upsertFuelPriceSQL, uuid.New().String(), stationID, fuelTypeID, price, uuid.New().String(), PriceChangeSourceSubmission, nil)
 	if err != nil {
 		return fmt.Errorf("failed to upsert fuel price: %w", err)
 	}
@@ -209,6 +209,7 @@ func TestUpsertFuelPrice_Insert(t *testing.T) {
 	assert.Len(t, prices, 1)
 	assert.Equal(t, 1.55, prices[0].Price)
 	assert.Equal(t, 1, prices[0].ConfirmationCount)
+	assert.Equal(t, PriceChangeSourceSubmission, prices[0].Source)
 }
 
 // TestUpsertFuelPrice_Update tests updating an existing fuel price
@@ -236,6 +237,35 @@ func TestUpsertFuelPrice_Update(t *testing.T) {
 	assert.Equal(t, 2, prices[0].ConfirmationCount, "Should increment on conflict")
 }
 
+// TestUpsertFuelPrice_ConfirmationKeepsPreviousPrice tests that confirming
+// the same price keeps the price it replaced, while its event records the
+// price just before it
+func TestUpsertFuelPrice_ConfirmationKeepsPreviousPrice(t *testing.T) {
+	db := testhelpers.SetupTestDBWithCleanup(t)
+
+	station := testhelpers.CreateTestStation(t, db, -33.8568, 151.2153)
+	fuelType := testhelpers.CreateTestFuelType(t, db, "E10")
+
+	repo := NewPgFuelPriceRepository(db)
+	require.NoError(t, repo.UpsertFuelPrice(station.ID, fuelType, 1.80))
+	require.NoError(t, repo.UpsertFuelPrice(station.ID, fuelType, 1.70))
+	require.NoError(t, repo.UpsertFuelPrice(station.ID, fuelType, 1.70))
+
+	var previous float64
+	err := db.QueryRow("SELECT previous_price FROM fuel_prices WHERE station_id = $1 AND fuel_type_id = $2", station.ID, fuelType).Scan(&previous)
+	require.NoError(t, err)
+	assert.Equal(t, 1.80, previous)
+
+	var last float64
+	err = db.QueryRow("SELECT previous_price FROM price_change_events WHERE station_id = $1 ORDER BY created_at DESC LIMIT 1", station.ID).Scan(&last)
+	require.NoError(t, err)
+	assert.Equal(t, 1.70, last)
+
+	var recorded int
+	require.NoError(t, db.QueryRow("SELECT COUNT(*) FROM fuel_price_history WHERE station_id = $1", station.ID).Scan(&recorded))
+	assert.Equal(t, 2, recorded)
+}
+
 // TestUpsertFuelPrice_IncrementConfirmation tests that confirmation count increments
 func TestUpsertFuelPrice_IncrementConfirmation(t *testing.T) {
 	db := testhelpers.SetupTestDBWithCleanup(t)
@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// PgLoginAttemptRepository is the PostgreSQL implementation of
+// LoginAttemptRepository, shared by every API instance.
+type PgLoginAttemptRepository struct {
+	db *sql.DB
+}
+
+func NewPgLoginAttemptRepository(db *sql.DB) *PgLoginAttemptRepository {
+	return &PgLoginAttemptRepository{db: db}
+}
+
+func scanLoginAttempts(row rowScanner) (LoginAttempts, error) {
+	var a LoginAttempts
+	var lockedUntil sql.NullTime
+	if err := row.Scan(&a.Failures, &a.LastFailureAt, &lockedUntil); err != nil {
+		return LoginAttempts{}, err
+	}
+	if lockedUntil.Valid {
+		a.LockedUntil = lockedUntil.Time
+	}
+	return a, nil
+}
+
+func (r *PgLoginAttemptRepository) Get(key string) (LoginAttempts, error) {
+	a, err := scanLoginAttempts(r.db.QueryRow(`SELECT failures, last_failure_at, locked_until FROM login_attempts WHERE key = $1`, key))
+	if err == sql.ErrNoRows {
+		return LoginAttempts{}, nil
+	}
+	if err != nil {
+		return LoginAttempts{}, fmt.Errorf("failed to get login attempts: %w", err)
+	}
+	return a, nil
+}
+
+func (r *PgLoginAttemptRepository) RecordFailure(key string, window time.Duration) (LoginAttempts, error) {
+	a, err := scanLoginAttempts(r.db.QueryRow(`
+		INSERT INTO login_attempts (key, failures, window_started_at, last_failure_at)
+		VALUES ($1, 1, NOW(), NOW())
+		ON CONFLICT (key) DO UPDATE
+		SET failures = CASE
+		        WHEN login_attempts.window_started_at < NOW() - $2 * INTERVAL '1 second' THEN 1
+		        ELSE login_attempts.failures + 1
+		    END,
+		    window_started_at = CASE
+		        WHEN login_attempts.window_started_at < NOW() - $2 * INTERVAL '1 second' THEN NOW()
+		        ELSE login_attempts.window_started_at
+		    END,
+		    last_failure_at = NOW()
+		RETURNING failures, last_failure_at, locked_until`,
+		key, int64(window.Seconds()),
+	))
+	if err != nil {
+		return LoginAttempts{}, fmt.Errorf("failed to record login failure: %w", err)
+	}
+	return a, nil
+}
+
+func (r *PgLoginAttemptRepository) Lock(key string, until time.Time) error {
+	_, err := r.db.Exec(`
+		INSERT INTO login_attempts (key, failures, locked_until)
+		VALUES ($1, 0, $2)
+		ON CONFLICT (key) DO UPDATE
+		SET failures = 0, window_started_at = NOW(), locked_until = EXCLUDED.locked_until`,
+		key, until,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to lock login: %w", err)
+	}
+	return nil
+}
+
+func (r *PgLoginAttemptRepository) Reset(key string) error {
+	if _, err := r.db.Exec(`DELETE FROM login_attempts WHERE key = $1`, key); err != nil {
+		return fmt.Errorf("failed to reset login attempts: %w", err)
+	}
+	return nil
+}
+
+var _ LoginAttemptRepository = (*PgLoginAttemptRepository)(nil)
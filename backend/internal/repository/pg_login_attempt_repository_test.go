@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"gaspeep/backend/internal/repository/testhelpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPgLoginAttemptRepository(t *testing.T) {
+	db := testhelpers.SetupTestDBWithCleanup(t)
+	repo := NewPgLoginAttemptRepository(db)
+
+	a, err := repo.Get("ip:203.0.113.7")
+	require.NoError(t, err)
+	assert.Zero(t, a.Failures)
+
+	for i := 1; i <= 3; i++ {
+		a, err = repo.RecordFailure("ip:203.0.113.7", 15*time.Minute)
+		require.NoError(t, err)
+		assert.Equal(t, i, a.Failures)
+	}
+	assert.True(t, a.LockedUntil.IsZero())
+
+	// A window that has passed starts a new count
+	_, err = db.Exec(`UPDATE login_attempts SET window_started_at = NOW() - INTERVAL '1 hour' WHERE key = $1`, "ip:203.0.113.7")
+	require.NoError(t, err)
+	a, err = repo.RecordFailure("ip:203.0.113.7", 15*time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, 1, a.Failures)
+
+	until := time.Now().Add(15 * time.Minute)
+	require.NoError(t, repo.Lock("ip:203.0.113.7", until))
+	require.NoError(t, repo.Lock("account:new@example.com", until))
+	for _, key := range []string{"ip:203.0.113.7", "account:new@example.com"} {
+		a, err = repo.Get(key)
+		require.NoError(t, err)
+		assert.Zero(t, a.Failures)
+		assert.WithinDuration(t, until, a.LockedUntil, time.Second)
+	}
+
+	require.NoError(t, repo.Reset("ip:203.0.113.7"))
+	a, err = repo.Get("ip:203.0.113.7")
+	require.NoError(t, err)
+	assert.Equal(t, LoginAttempts{}, a)
+}
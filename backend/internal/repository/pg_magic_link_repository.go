@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PgMagicLinkRepository is the PostgreSQL implementation of MagicLinkRepository.
+type PgMagicLinkRepository struct {
+	db *sql.DB
+}
+
+func NewPgMagicLinkRepository(db *sql.DB) *PgMagicLinkRepository {
+	return &PgMagicLinkRepository{db: db}
+}
+
+func (r *PgMagicLinkRepository) Create(userID, email, tokenHash, nonceHash string, expiresAt time.Time) error {
+	_, err := r.db.Exec(`
+		INSERT INTO magic_link_tokens (id, user_id, email, token_hash, nonce_hash, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6)`,
+		uuid.New().String(), userID, email, tokenHash, nonceHash, expiresAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create magic link token: %w", err)
+	}
+	return nil
+}
+
+func (r *PgMagicLinkRepository) CountIssuedSince(userID string, since time.Time) (int, *time.Time, error) {
+	var count int
+	var latest *time.Time
+	err := r.db.QueryRow(`
+		SELECT COUNT(*), MAX(created_at)
+		FROM magic_link_tokens
+		WHERE user_id = $1 AND created_at >= $2`,
+		userID, since,
+	).Scan(&count, &latest)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to count magic link tokens: %w", err)
+	}
+	return count, latest, nil
+}
+
+// Consume marks the token used and the user verified in one statement, so a
+// token can never be used twice. A token presented from the wrong browser is
+// left unused, so the right one can still follow it.
+func (r *PgMagicLinkRepository) Consume(tokenHash, nonceHash string) (string, error) {
+	var userID string
+	err := r.db.QueryRow(`
+		WITH token AS (
+			UPDATE magic_link_tokens
+			SET used_at = NOW()
+			WHERE token_hash = $1 AND nonce_hash = $2 AND used_at IS NULL AND expires_at > NOW()
+			RETURNING user_id, email
+		)
+		UPDATE users u
+		SET email_verified = TRUE, updated_at = NOW()
+		FROM token
+		WHERE u.id = token.user_id AND LOWER(u.email) = LOWER(token.email)
+		RETURNING u.id`,
+		tokenHash, nonceHash,
+	).Scan(&userID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", sql.ErrNoRows
+		}
+		return "", fmt.Errorf("failed to consume magic link token: %w", err)
+	}
+	return userID, nil
+}
+
+func (r *PgMagicLinkRepository) DeleteExpiredBefore(cutoff time.Time) (int64, error) {
+	result, err := r.db.Exec(`DELETE FROM magic_link_tokens WHERE expires_at < $1`, cutoff)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired magic link tokens: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+var _ MagicLinkRepository = (*PgMagicLinkRepository)(nil)
@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"gaspeep/backend/internal/repository/testhelpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMagicLink_ConsumeIsSingleUseAndBoundToNonce(t *testing.T) {
+	db := testhelpers.SetupTestDBWithCleanup(t)
+
+	user := testhelpers.CreateTestUser(t, db)
+	repo := NewPgMagicLinkRepository(db)
+
+	require.NoError(t, repo.Create(user.ID, user.Email, "hash-1", "nonce-1", time.Now().Add(time.Hour)))
+
+	count, latest, err := repo.CountIssuedSince(user.ID, time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+	require.NotNil(t, latest)
+
+	// Another browser cannot use the link, and does not use it up
+	_, err = repo.Consume("hash-1", "nonce-2")
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+
+	userID, err := repo.Consume("hash-1", "nonce-1")
+	require.NoError(t, err)
+	assert.Equal(t, user.ID, userID)
+
+	var emailVerified bool
+	require.NoError(t, db.QueryRow(`SELECT email_verified FROM users WHERE id = $1`, user.ID).Scan(&emailVerified))
+	assert.True(t, emailVerified)
+
+	_, err = repo.Consume("hash-1", "nonce-1")
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestMagicLink_RejectsExpiredAndStaleTokens(t *testing.T) {
+	db := testhelpers.SetupTestDBWithCleanup(t)
+
+	user := testhelpers.CreateTestUser(t, db)
+	repo := NewPgMagicLinkRepository(db)
+
+	require.NoError(t, repo.Create(user.ID, user.Email, "expired", "nonce", time.Now().Add(-time.Minute)))
+	_, err := repo.Consume("expired", "nonce")
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+
+	// A link sent to a previous address must not sign in to the account
+	require.NoError(t, repo.Create(user.ID, "old-"+user.Email, "stale", "nonce", time.Now().Add(time.Hour)))
+	_, err = repo.Consume("stale", "nonce")
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestAuditLog_Record(t *testing.T) {
+	db := testhelpers.SetupTestDBWithCleanup(t)
+
+	user := testhelpers.CreateTestUser(t, db)
+	repo := NewPgAuditLogRepository(db)
+
+	require.NoError(t, repo.Record(AuditLogEntry{UserID: user.ID, Action: AuditMagicLinkRedeemed, IPAddress: "192.0.2.1", Details: map[string]string{"result": "ok"}}))
+	require.NoError(t, repo.Record(AuditLogEntry{Action: AuditMagicLinkRequested}))
+
+	var withUser, withoutUser int
+	require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM audit_log WHERE user_id = $1 AND details->>'result' = 'ok'`, user.ID).Scan(&withUser))
+	require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM audit_log WHERE user_id IS NULL`).Scan(&withoutUser))
+	assert.Equal(t, 1, withUser)
+	assert.Equal(t, 1, withoutUser)
+}
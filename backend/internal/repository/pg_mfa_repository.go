@@ -0,0 +1,174 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// PgMFARepository is the PostgreSQL implementation of MFARepository.
+type PgMFARepository struct {
+	db *sql.DB
+}
+
+func NewPgMFARepository(db *sql.DB) *PgMFARepository {
+	return &PgMFARepository{db: db}
+}
+
+func (r *PgMFARepository) GetTOTP(userID string) (*TOTPCredential, error) {
+	var cred TOTPCredential
+	err := r.db.QueryRow(`
+		SELECT user_id, secret, confirmed_at, last_used_step
+		FROM user_totp
+		WHERE user_id = $1`,
+		userID,
+	).Scan(&cred.UserID, &cred.Secret, &cred.ConfirmedAt, &cred.LastUsedStep)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, sql.ErrNoRows
+		}
+		return nil, fmt.Errorf("failed to get TOTP secret: %w", err)
+	}
+	return &cred, nil
+}
+
+// SaveTOTPSecret leaves a confirmed secret alone, so starting enrolment again
+// cannot switch off two-factor authentication.
+func (r *PgMFARepository) SaveTOTPSecret(userID, secret string) error {
+	_, err := r.db.Exec(`
+		INSERT INTO user_totp (user_id, secret)
+		VALUES ($1, $2)
+		ON CONFLICT (user_id) DO UPDATE
+		SET secret = EXCLUDED.secret, last_used_step = 0, created_at = NOW()
+		WHERE user_totp.confirmed_at IS NULL`,
+		userID, secret,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save TOTP secret: %w", err)
+	}
+	return nil
+}
+
+func (r *PgMFARepository) ConfirmTOTP(userID string, step int64) (bool, error) {
+	result, err := r.db.Exec(`
+		UPDATE user_totp
+		SET confirmed_at = NOW(), last_used_step = $2
+		WHERE user_id = $1 AND confirmed_at IS NULL`,
+		userID, step,
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to confirm TOTP secret: %w", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to confirm TOTP secret: %w", err)
+	}
+	return n > 0, nil
+}
+
+func (r *PgMFARepository) UseTOTPStep(userID string, step int64) (bool, error) {
+	result, err := r.db.Exec(`
+		UPDATE user_totp
+		SET last_used_step = $2
+		WHERE user_id = $1 AND last_used_step < $2`,
+		userID, step,
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to record TOTP code use: %w", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to record TOTP code use: %w", err)
+	}
+	return n > 0, nil
+}
+
+func (r *PgMFARepository) DeleteTOTP(userID string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM user_recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return fmt.Errorf("failed to delete recovery codes: %w", err)
+	}
+	if _, err := tx.Exec(`DELETE FROM user_totp WHERE user_id = $1`, userID); err != nil {
+		return fmt.Errorf("failed to delete TOTP secret: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit TOTP removal: %w", err)
+	}
+	return nil
+}
+
+func (r *PgMFARepository) ReplaceRecoveryCodes(userID string, codeHashes []string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM user_recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return fmt.Errorf("failed to delete recovery codes: %w", err)
+	}
+	for _, hash := range codeHashes {
+		if _, err := tx.Exec(`INSERT INTO user_recovery_codes (code_hash, user_id) VALUES ($1, $2)`, hash, userID); err != nil {
+			return fmt.Errorf("failed to store recovery code: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit recovery codes: %w", err)
+	}
+	return nil
+}
+
+func (r *PgMFARepository) UseRecoveryCode(userID, codeHash string) (bool, error) {
+	result, err := r.db.Exec(`
+		UPDATE user_recovery_codes
+		SET used_at = NOW()
+		WHERE code_hash = $1 AND user_id = $2 AND used_at IS NULL`,
+		codeHash, userID,
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to use recovery code: %w", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to use recovery code: %w", err)
+	}
+	return n > 0, nil
+}
+
+func (r *PgMFARepository) CountRecoveryCodes(userID string) (int, error) {
+	var count int
+	err := r.db.QueryRow(`SELECT COUNT(*) FROM user_recovery_codes WHERE user_id = $1 AND used_at IS NULL`, userID).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count recovery codes: %w", err)
+	}
+	return count, nil
+}
+
+func (r *PgMFARepository) GetUserRoles(userID string) ([]string, error) {
+	var role string
+	var owner bool
+	err := r.db.QueryRow(`
+		SELECT u.role, EXISTS (SELECT 1 FROM station_owner_members m WHERE m.user_id = u.id)
+		FROM users u
+		WHERE u.id = $1`,
+		userID,
+	).Scan(&role, &owner)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user roles: %w", err)
+	}
+
+	var roles []string
+	if role != "" && role != "user" {
+		roles = append(roles, role)
+	}
+	if owner {
+		roles = append(roles, "owner")
+	}
+	return roles, nil
+}
+
+var _ MFARepository = (*PgMFARepository)(nil)
@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"database/sql"
+	"testing"
+
+	"gaspeep/backend/internal/repository/testhelpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPgMFARepository_TOTPLifecycle(t *testing.T) {
+	db := testhelpers.SetupTestDBWithCleanup(t)
+
+	user := testhelpers.CreateTestUser(t, db)
+	repo := NewPgMFARepository(db)
+
+	_, err := repo.GetTOTP(user.ID)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	require.NoError(t, repo.SaveTOTPSecret(user.ID, "FIRSTSECRET"))
+	require.NoError(t, repo.SaveTOTPSecret(user.ID, "SECONDSECRET"))
+	cred, err := repo.GetTOTP(user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "SECONDSECRET", cred.Secret)
+	assert.Nil(t, cred.ConfirmedAt)
+
+	ok, err := repo.ConfirmTOTP(user.ID, 100)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	ok, err = repo.ConfirmTOTP(user.ID, 101)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	// A confirmed secret is not replaced by starting enrolment again
+	require.NoError(t, repo.SaveTOTPSecret(user.ID, "THIRDSECRET"))
+	cred, err = repo.GetTOTP(user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "SECONDSECRET", cred.Secret)
+	assert.NotNil(t, cred.ConfirmedAt)
+	assert.Equal(t, int64(100), cred.LastUsedStep)
+
+	// Each time step can be used once, and never after a later one
+	ok, err = repo.UseTOTPStep(user.ID, 100)
+	require.NoError(t, err)
+	assert.False(t, ok)
+	ok, err = repo.UseTOTPStep(user.ID, 102)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	ok, err = repo.UseTOTPStep(user.ID, 101)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	require.NoError(t, repo.ReplaceRecoveryCodes(user.ID, []string{"hash-a"}))
+	require.NoError(t, repo.DeleteTOTP(user.ID))
+	_, err = repo.GetTOTP(user.ID)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+	count, err := repo.CountRecoveryCodes(user.ID)
+	require.NoError(t, err)
+	assert.Zero(t, count)
+}
+
+func TestPgMFARepository_RecoveryCodes(t *testing.T) {
+	db := testhelpers.SetupTestDBWithCleanup(t)
+
+	user := testhelpers.CreateTestUser(t, db)
+	other := testhelpers.CreateTestUser(t, db)
+	repo := NewPgMFARepository(db)
+
+	require.NoError(t, repo.ReplaceRecoveryCodes(user.ID, []string{"hash-1", "hash-2"}))
+	require.NoError(t, repo.ReplaceRecoveryCodes(user.ID, []string{"hash-3", "hash-4", "hash-5"}))
+	count, err := repo.CountRecoveryCodes(user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 3, count)
+
+	// Replaced codes, other users' codes and used codes are all rejected
+	ok, err := repo.UseRecoveryCode(user.ID, "hash-1")
+	require.NoError(t, err)
+	assert.False(t, ok)
+	ok, err = repo.UseRecoveryCode(other.ID, "hash-3")
+	require.NoError(t, err)
+	assert.False(t, ok)
+	ok, err = repo.UseRecoveryCode(user.ID, "hash-3")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	ok, err = repo.UseRecoveryCode(user.ID, "hash-3")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	count, err = repo.CountRecoveryCodes(user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+}
+
+func TestPgMFARepository_GetUserRoles(t *testing.T) {
+	db := testhelpers.SetupTestDBWithCleanup(t)
+
+	user := testhelpers.CreateTestUser(t, db)
+	repo := NewPgMFARepository(db)
+
+	roles, err := repo.GetUserRoles(user.ID)
+	require.NoError(t, err)
+	assert.Empty(t, roles)
+
+	_, err = db.Exec(`UPDATE users SET role = 'moderator' WHERE id = $1`, user.ID)
+	require.NoError(t, err)
+	testhelpers.CreateTestStationOwner(t, db, user.ID)
+
+	roles, err = repo.GetUserRoles(user.ID)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"moderator", "owner"}, roles)
+}
@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"gaspeep/backend/internal/models"
+	"github.com/lib/pq"
+)
+
+// PgOwnerAnalyticsRepository is the PostgreSQL implementation of OwnerAnalyticsRepository.
+type PgOwnerAnalyticsRepository struct {
+	db *sql.DB
+}
+
+func NewPgOwnerAnalyticsRepository(db *sql.DB) *PgOwnerAnalyticsRepository {
+	return &PgOwnerAnalyticsRepository{db: db}
+}
+
+func (r *PgOwnerAnalyticsRepository) BenchmarkedPrices(userID string, radiusKm float64) ([]BenchmarkedPrice, error) {
+	query := `
+		SELECT s.id, s.name, fp.fuel_type_id, ft.name, fp.price, c.ids, c.prices
+		FROM stations s
+		INNER JOIN fuel_prices fp ON fp.station_id = s.id
+		INNER JOIN fuel_types ft ON ft.id = fp.fuel_type_id
+		CROSS JOIN LATERAL (
+			SELECT COALESCE(array_agg(cs.id::text ORDER BY cs.id), '{}') AS ids,
+				COALESCE(array_agg(cfp.price ORDER BY cs.id), '{}') AS prices
+			FROM stations cs
+			INNER JOIN fuel_prices cfp ON cfp.station_id = cs.id AND cfp.fuel_type_id = fp.fuel_type_id
+			WHERE cs.id <> s.id AND cs.owner_id IS DISTINCT FROM s.owner_id
+				AND ST_DWithin(cs.location, s.location, $3::float8 * 1000)
+		) c
+		WHERE ` + stationAccessCondition("$1", "$2") + `
+			AND EXISTS(
+				SELECT 1 FROM claim_verifications cv
+				WHERE cv.station_id = s.id AND cv.station_owner_id = s.owner_id AND ` + activeClaimCondition + `
+			)
+		ORDER BY s.name ASC, s.id, ft.display_order ASC`
+
+	rows, err := r.db.Query(query, userID, models.StationPermissionViewAnalytics, radiusKm)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query benchmarked prices: %w", err)
+	}
+	defer rows.Close()
+
+	prices := []BenchmarkedPrice{}
+	for rows.Next() {
+		var p BenchmarkedPrice
+		if err := rows.Scan(&p.StationID, &p.StationName, &p.FuelTypeID, &p.FuelTypeName, &p.Price, pq.Array(&p.CompetitorIDs), pq.Array(&p.CompetitorPrices)); err != nil {
+			return nil, fmt.Errorf("failed to scan benchmarked price: %w", err)
+		}
+		prices = append(prices, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating benchmarked price rows: %w", err)
+	}
+	return prices, nil
+}
+
+func (r *PgOwnerAnalyticsRepository) PriceHistory(stationIDs, fuelTypeIDs []string, since time.Time) ([]PricePoint, error) {
+	query := `
+		SELECT station_id, fuel_type_id, price, previous_price, recorded_at FROM (
+			SELECT station_id, fuel_type_id, price, previous_price, recorded_at
+			FROM fuel_price_history
+			WHERE station_id = ANY($1::uuid[]) AND fuel_type_id = ANY($2::uuid[]) AND recorded_at >= $3
+			UNION ALL
+			(
+				SELECT DISTINCT ON (station_id, fuel_type_id) station_id, fuel_type_id, price, previous_price, recorded_at
+				FROM fuel_price_history
+				WHERE station_id = ANY($1::uuid[]) AND fuel_type_id = ANY($2::uuid[]) AND recorded_at < $3
+				ORDER BY station_id, fuel_type_id, recorded_at DESC
+			)
+		) h
+		ORDER BY recorded_at ASC`
+
+	rows, err := r.db.Query(query, pq.Array(stationIDs), pq.Array(fuelTypeIDs), since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query price history: %w", err)
+	}
+	defer rows.Close()
+
+	points := []PricePoint{}
+	for rows.Next() {
+		var p PricePoint
+		var previous sql.NullFloat64
+		if err := rows.Scan(&p.StationID, &p.FuelTypeID, &p.Price, &previous, &p.RecordedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan price history: %w", err)
+		}
+		if previous.Valid {
+			p.PreviousPrice = &previous.Float64
+		}
+		points = append(points, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating price history rows: %w", err)
+	}
+	return points, nil
+}
+
+func (r *PgOwnerAnalyticsRepository) BroadcastStats(userID string, weekStart, monthStart time.Time) (*OwnerBroadcastStats, error) {
+	query := `
+		WITH owned AS (
+			SELECT b.id, b.broadcast_status, b.start_date
+			FROM broadcasts b
+			INNER JOIN stations s ON s.id = b.station_id
+			WHERE ` + stationAccessCondition("$1", "$2") + `
+		)
+		SELECT
+			(SELECT COUNT(*) FROM owned WHERE broadcast_status = 'active'),
+			(SELECT COUNT(*) FROM owned WHERE broadcast_status NOT IN ('draft', 'cancelled') AND start_date >= $3),
+			COALESCE(SUM(ba.delivered), 0), COALESCE(SUM(ba.opened), 0)
+		FROM broadcast_analytics ba
+		INNER JOIN owned o ON o.id = ba.broadcast_id
+		WHERE ba.recorded_at >= $4`
+
+	var stats OwnerBroadcastStats
+	err := r.db.QueryRow(query, userID, models.StationPermissionViewAnalytics, weekStart, monthStart).Scan(
+		&stats.ActiveBroadcasts, &stats.BroadcastsThisWeek, &stats.DeliveredThisMonth, &stats.OpenedThisMonth)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query broadcast stats: %w", err)
+	}
+	return &stats, nil
+}
+
+var _ OwnerAnalyticsRepository = (*PgOwnerAnalyticsRepository)(nil)
@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"gaspeep/backend/internal/repository/testhelpers"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestOwnerAnalytics_PriceBenchmarks tests that competitors are the nearby
+// stations of other businesses, and that price history only grows when a
+// price changes
+func TestOwnerAnalytics_PriceBenchmarks(t *testing.T) {
+	db := testhelpers.SetupTestDBWithCleanup(t)
+
+	user := testhelpers.CreateTestUser(t, db)
+	station := testhelpers.CreateTestStation(t, db, -33.8568, 151.2153)
+	near := testhelpers.CreateTestStation(t, db, -33.8600, 151.2100)
+	far := testhelpers.CreateTestStation(t, db, -33.9500, 151.2153)
+	fuelType := testhelpers.CreateTestFuelType(t, db, "E10")
+
+	ownerRepo := NewPgStationOwnerRepository(db)
+	_, err := ownerRepo.ClaimStation(user.ID, station.ID, "document", nil, "", "")
+	require.NoError(t, err)
+
+	since := time.Now().Add(-time.Hour)
+	prices := NewPgFuelPriceRepository(db)
+	require.NoError(t, prices.UpsertFuelPrice(station.ID, fuelType, 1.899))
+	require.NoError(t, prices.UpsertFuelPrice(near.ID, fuelType, 1.859))
+	require.NoError(t, prices.UpsertFuelPrice(far.ID, fuelType, 1.799))
+	require.NoError(t, prices.UpsertFuelPrice(near.ID, fuelType, 1.879))
+	require.NoError(t, prices.UpsertFuelPrice(station.ID, fuelType, 1.899))
+
+	repo := NewPgOwnerAnalyticsRepository(db)
+
+	// Stations are only benchmarked once their claim is approved
+	benchmarked, err := repo.BenchmarkedPrices(user.ID, 5)
+	require.NoError(t, err)
+	assert.Empty(t, benchmarked)
+
+	_, err = db.Exec("UPDATE claim_verifications SET verification_status = 'approved', verified_at = NOW() WHERE station_id = $1", station.ID)
+	require.NoError(t, err)
+	benchmarked, err = repo.BenchmarkedPrices(user.ID, 5)
+	require.NoError(t, err)
+	require.Len(t, benchmarked, 1)
+	assert.Equal(t, station.ID, benchmarked[0].StationID)
+	assert.Equal(t, 1.899, benchmarked[0].Price)
+	assert.Equal(t, []string{near.ID}, benchmarked[0].CompetitorIDs)
+	assert.Equal(t, []float64{1.879}, benchmarked[0].CompetitorPrices)
+
+	history, err := repo.PriceHistory([]string{station.ID, near.ID}, []string{fuelType}, since)
+	require.NoError(t, err)
+	require.Len(t, history, 3)
+	last := history[2]
+	assert.Equal(t, near.ID, last.StationID)
+	assert.Equal(t, 1.879, last.Price)
+	require.NotNil(t, last.PreviousPrice)
+	assert.Equal(t, 1.859, *last.PreviousPrice)
+
+	// Prices before the period are represented by the last one
+	history, err = repo.PriceHistory([]string{station.ID, near.ID}, []string{fuelType}, time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+}
+
+// TestOwnerAnalytics_BroadcastStats tests that broadcasts and their recorded
+// deliveries are summed up for the owner's stations
+func TestOwnerAnalytics_BroadcastStats(t *testing.T) {
+	db := testhelpers.SetupTestDBWithCleanup(t)
+
+	user := testhelpers.CreateTestUser(t, db)
+	other := testhelpers.CreateTestUser(t, db)
+	owner := testhelpers.CreateTestStationOwner(t, db, user.ID)
+	station := testhelpers.CreateTestStation(t, db, -33.8568, 151.2153)
+	_, err := db.Exec("UPDATE stations SET owner_id = $1 WHERE id = $2", owner.ID, station.ID)
+	require.NoError(t, err)
+
+	active := testhelpers.CreateTestBroadcast(t, db, owner.ID, station.ID)
+	testhelpers.CreateTestBroadcast(t, db, owner.ID, station.ID)
+	_, err = db.Exec("UPDATE broadcasts SET broadcast_status = 'active' WHERE id = $1", active.ID)
+	require.NoError(t, err)
+	_, err = db.Exec("INSERT INTO broadcast_analytics (id, broadcast_id, delivered, opened) VALUES ($1, $2, 100, 25)", uuid.New().String(), active.ID)
+	require.NoError(t, err)
+
+	repo := NewPgOwnerAnalyticsRepository(db)
+	now := time.Now()
+	stats, err := repo.BroadcastStats(user.ID, now.Add(-7*24*time.Hour), now.Add(-24*time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, 1, stats.ActiveBroadcasts)
+	assert.Equal(t, 2, stats.BroadcastsThisWeek)
+	assert.Equal(t, 100, stats.DeliveredThisMonth)
+	assert.Equal(t, 25, stats.OpenedThisMonth)
+
+	stats, err = repo.BroadcastStats(other.ID, now.Add(-7*24*time.Hour), now.Add(-24*time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, OwnerBroadcastStats{}, *stats)
+}
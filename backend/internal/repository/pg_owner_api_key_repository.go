@@ -0,0 +1,214 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"gaspeep/backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// PgOwnerAPIKeyRepository is the PostgreSQL implementation of OwnerAPIKeyRepository.
+type PgOwnerAPIKeyRepository struct {
+	db *sql.DB
+}
+
+func NewPgOwnerAPIKeyRepository(db *sql.DB) *PgOwnerAPIKeyRepository {
+	return &PgOwnerAPIKeyRepository{db: db}
+}
+
+const ownerAPIKeyColumns = `k.id, k.station_owner_id, so.user_id, k.name, k.key_prefix, k.scopes, k.station_ids::text[],
+	k.created_at, k.last_used_at, k.revoked_at`
+
+const ownerAPIKeyFrom = `owner_api_keys k JOIN station_owners so ON so.id = k.station_owner_id`
+
+func scanOwnerAPIKey(row rowScanner, k *models.OwnerAPIKey) error {
+	return row.Scan(&k.ID, &k.StationOwnerID, &k.OwnerUserID, &k.Name, &k.Prefix, pq.Array(&k.Scopes), pq.Array(&k.StationIDs),
+		&k.CreatedAt, &k.LastUsedAt, &k.RevokedAt)
+}
+
+func (r *PgOwnerAPIKeyRepository) Create(key *models.OwnerAPIKey, keyHash, createdBy string) error {
+	key.ID = uuid.New().String()
+	err := r.db.QueryRow(`
+		INSERT INTO owner_api_keys (id, station_owner_id, name, key_prefix, key_hash, scopes, station_ids, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7::uuid[], $8)
+		RETURNING created_at`,
+		key.ID, key.StationOwnerID, key.Name, key.Prefix, keyHash, pq.Array(key.Scopes), pq.Array(key.StationIDs), nilIfEmpty(createdBy),
+	).Scan(&key.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create API key: %w", err)
+	}
+	return nil
+}
+
+func (r *PgOwnerAPIKeyRepository) ListByOwner(stationOwnerID string) ([]models.OwnerAPIKey, error) {
+	rows, err := r.db.Query(`
+		SELECT `+ownerAPIKeyColumns+`
+		FROM `+ownerAPIKeyFrom+`
+		WHERE k.station_owner_id = $1
+		ORDER BY k.created_at DESC`,
+		stationOwnerID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list API keys: %w", err)
+	}
+	defer rows.Close()
+
+	keys := []models.OwnerAPIKey{}
+	for rows.Next() {
+		var k models.OwnerAPIKey
+		if err := scanOwnerAPIKey(rows, &k); err != nil {
+			return nil, fmt.Errorf("failed to scan API key: %w", err)
+		}
+		keys = append(keys, k)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list API keys: %w", err)
+	}
+	return keys, nil
+}
+
+func (r *PgOwnerAPIKeyRepository) GetByID(stationOwnerID, keyID string) (*models.OwnerAPIKey, error) {
+	var k models.OwnerAPIKey
+	err := scanOwnerAPIKey(r.db.QueryRow(`
+		SELECT `+ownerAPIKeyColumns+`
+		FROM `+ownerAPIKeyFrom+`
+		WHERE k.station_owner_id = $1 AND k.id::text = $2`,
+		stationOwnerID, keyID,
+	), &k)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, sql.ErrNoRows
+		}
+		return nil, fmt.Errorf("failed to get API key: %w", err)
+	}
+	return &k, nil
+}
+
+func (r *PgOwnerAPIKeyRepository) GetByHash(keyHash string) (*models.OwnerAPIKey, error) {
+	var k models.OwnerAPIKey
+	err := scanOwnerAPIKey(r.db.QueryRow(`
+		SELECT `+ownerAPIKeyColumns+`
+		FROM `+ownerAPIKeyFrom+`
+		WHERE k.key_hash = $1 AND k.revoked_at IS NULL`,
+		keyHash,
+	), &k)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, sql.ErrNoRows
+		}
+		return nil, fmt.Errorf("failed to get API key: %w", err)
+	}
+	return &k, nil
+}
+
+func (r *PgOwnerAPIKeyRepository) Revoke(stationOwnerID, keyID string) error {
+	result, err := r.db.Exec(`
+		UPDATE owner_api_keys
+		SET revoked_at = NOW()
+		WHERE station_owner_id = $1 AND id::text = $2 AND revoked_at IS NULL`,
+		stationOwnerID, keyID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to revoke API key: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func (r *PgOwnerAPIKeyRepository) RecordUsage(usage models.OwnerAPIKeyUsage) error {
+	_, err := r.db.Exec(`
+		WITH used AS (
+			UPDATE owner_api_keys SET last_used_at = NOW() WHERE id = $1
+		)
+		INSERT INTO owner_api_key_usage (api_key_id, method, path, station_id, status_code, ip_address)
+		VALUES ($1, $2, $3, $4, $5, $6)`,
+		usage.APIKeyID, usage.Method, usage.Path, nilIfEmpty(usage.StationID), usage.StatusCode, nilIfEmpty(usage.IPAddress),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record API key usage: %w", err)
+	}
+	return nil
+}
+
+func (r *PgOwnerAPIKeyRepository) ListUsage(keyID string, limit int) ([]models.OwnerAPIKeyUsage, error) {
+	rows, err := r.db.Query(`
+		SELECT api_key_id, method, path, COALESCE(station_id, ''), status_code, COALESCE(ip_address, ''), created_at
+		FROM owner_api_key_usage
+		WHERE api_key_id = $1
+		ORDER BY created_at DESC, id DESC
+		LIMIT $2`,
+		keyID, limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list API key usage: %w", err)
+	}
+	defer rows.Close()
+
+	usage := []models.OwnerAPIKeyUsage{}
+	for rows.Next() {
+		var u models.OwnerAPIKeyUsage
+		if err := rows.Scan(&u.APIKeyID, &u.Method, &u.Path, &u.StationID, &u.StatusCode, &u.IPAddress, &u.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan API key usage: %w", err)
+		}
+		usage = append(usage, u)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list API key usage: %w", err)
+	}
+	return usage, nil
+}
+
+func (r *PgOwnerAPIKeyRepository) BeginIdempotent(keyID, idempotencyKey, requestHash string) (*IdempotentRequest, error) {
+	result, err := r.db.Exec(`
+		INSERT INTO owner_api_idempotency_keys (api_key_id, idempotency_key, request_hash)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (api_key_id, idempotency_key) DO NOTHING`,
+		keyID, idempotencyKey, requestHash,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reserve idempotency key: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 1 {
+		return nil, nil
+	}
+
+	var earlier IdempotentRequest
+	err = r.db.QueryRow(`
+		SELECT request_hash, response_body
+		FROM owner_api_idempotency_keys
+		WHERE api_key_id = $1 AND idempotency_key = $2`,
+		keyID, idempotencyKey,
+	).Scan(&earlier.RequestHash, &earlier.Response)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load idempotent request: %w", err)
+	}
+	return &earlier, nil
+}
+
+func (r *PgOwnerAPIKeyRepository) CompleteIdempotent(keyID, idempotencyKey string, response []byte) error {
+	_, err := r.db.Exec(`
+		UPDATE owner_api_idempotency_keys
+		SET response_body = $3
+		WHERE api_key_id = $1 AND idempotency_key = $2`,
+		keyID, idempotencyKey, response,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to store idempotent response: %w", err)
+	}
+	return nil
+}
+
+func (r *PgOwnerAPIKeyRepository) DeleteIdempotencyKeysBefore(cutoff time.Time) (int64, error) {
+	result, err := r.db.Exec(`DELETE FROM owner_api_idempotency_keys WHERE created_at < $1`, cutoff)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete old idempotency keys: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+var _ OwnerAPIKeyRepository = (*PgOwnerAPIKeyRepository)(nil)
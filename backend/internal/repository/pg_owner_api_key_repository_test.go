@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"gaspeep/backend/internal/models"
+	"gaspeep/backend/internal/repository/testhelpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func createTestAPIKey(t *testing.T, repo OwnerAPIKeyRepository, owner *models.StationOwner, stationID, keyHash string) *models.OwnerAPIKey {
+	t.Helper()
+	key := &models.OwnerAPIKey{
+		StationOwnerID: owner.ID,
+		Name:           "Till",
+		Prefix:         "gpk_abcdefgh",
+		Scopes:         []string{models.APIKeyScopePricesRead, models.APIKeyScopePricesWrite},
+		StationIDs:     []string{stationID},
+	}
+	require.NoError(t, repo.Create(key, keyHash, owner.UserID))
+	return key
+}
+
+// TestOwnerAPIKey_CreateLookupAndRevoke tests that keys are found by hash until revoked
+func TestOwnerAPIKey_CreateLookupAndRevoke(t *testing.T) {
+	db := testhelpers.SetupTestDBWithCleanup(t)
+
+	user := testhelpers.CreateTestUser(t, db)
+	owner := testhelpers.CreateTestStationOwner(t, db, user.ID)
+	station := testhelpers.CreateTestStation(t, db, -33.8568, 151.2153)
+
+	repo := NewPgOwnerAPIKeyRepository(db)
+	key := createTestAPIKey(t, repo, owner, station.ID, "hash-1")
+	assert.NotEmpty(t, key.ID)
+
+	found, err := repo.GetByHash("hash-1")
+	require.NoError(t, err)
+	assert.Equal(t, key.ID, found.ID)
+	assert.Equal(t, user.ID, found.OwnerUserID)
+	assert.Equal(t, []string{station.ID}, found.StationIDs)
+	assert.Equal(t, key.Scopes, found.Scopes)
+
+	// Another owner can't revoke the key
+	otherUser := testhelpers.CreateTestUser(t, db)
+	otherOwner := testhelpers.CreateTestStationOwner(t, db, otherUser.ID)
+	assert.ErrorIs(t, repo.Revoke(otherOwner.ID, key.ID), sql.ErrNoRows)
+
+	require.NoError(t, repo.Revoke(owner.ID, key.ID))
+	assert.ErrorIs(t, repo.Revoke(owner.ID, key.ID), sql.ErrNoRows)
+	_, err = repo.GetByHash("hash-1")
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+
+	keys, err := repo.ListByOwner(owner.ID)
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+	assert.NotNil(t, keys[0].RevokedAt)
+}
+
+// TestOwnerAPIKey_RecordUsage tests that usage is logged and the key marked used
+func TestOwnerAPIKey_RecordUsage(t *testing.T) {
+	db := testhelpers.SetupTestDBWithCleanup(t)
+
+	user := testhelpers.CreateTestUser(t, db)
+	owner := testhelpers.CreateTestStationOwner(t, db, user.ID)
+	station := testhelpers.CreateTestStation(t, db, -33.8568, 151.2153)
+
+	repo := NewPgOwnerAPIKeyRepository(db)
+	key := createTestAPIKey(t, repo, owner, station.ID, "hash-1")
+
+	require.NoError(t, repo.RecordUsage(models.OwnerAPIKeyUsage{
+		APIKeyID:   key.ID,
+		Method:     "PUT",
+		Path:       "/api/integrations/stations/" + station.ID + "/prices",
+		StationID:  station.ID,
+		StatusCode: 200,
+		IPAddress:  "203.0.113.5",
+	}))
+
+	usage, err := repo.ListUsage(key.ID, 10)
+	require.NoError(t, err)
+	require.Len(t, usage, 1)
+	assert.Equal(t, "PUT", usage[0].Method)
+	assert.Equal(t, 200, usage[0].StatusCode)
+
+	found, err := repo.GetByID(owner.ID, key.ID)
+	require.NoError(t, err)
+	assert.NotNil(t, found.LastUsedAt)
+}
+
+// TestOwnerAPIKey_Idempotency tests reserving, completing, releasing and purging idempotency keys
+func TestOwnerAPIKey_Idempotency(t *testing.T) {
+	db := testhelpers.SetupTestDBWithCleanup(t)
+
+	user := testhelpers.CreateTestUser(t, db)
+	owner := testhelpers.CreateTestStationOwner(t, db, user.ID)
+	station := testhelpers.CreateTestStation(t, db, -33.8568, 151.2153)
+
+	repo := NewPgOwnerAPIKeyRepository(db)
+	key := createTestAPIKey(t, repo, owner, station.ID, "hash-1")
+
+	earlier, err := repo.BeginIdempotent(key.ID, "req-1", "request-hash")
+	require.NoError(t, err)
+	assert.Nil(t, earlier)
+
+	// Still in progress
+	earlier, err = repo.BeginIdempotent(key.ID, "req-1", "request-hash")
+	require.NoError(t, err)
+	require.NotNil(t, earlier)
+	assert.Equal(t, "request-hash", earlier.RequestHash)
+	assert.Nil(t, earlier.Response)
+
+	require.NoError(t, repo.CompleteIdempotent(key.ID, "req-1", []byte(`{"published":1}`)))
+	earlier, err = repo.BeginIdempotent(key.ID, "req-1", "request-hash")
+	require.NoError(t, err)
+	require.NotNil(t, earlier)
+	assert.JSONEq(t, `{"published":1}`, string(earlier.Response))
+
+	deleted, err := repo.DeleteIdempotencyKeysBefore(time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), deleted)
+}
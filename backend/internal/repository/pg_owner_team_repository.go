@@ -0,0 +1,485 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"gaspeep/backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// stationAccessCondition returns a condition that holds when the user in
+// query argument userArg belongs to the business that owns station s and may
+// use the permission in query argument permissionArg there. Owners and
+// managers may use every permission at every station; staff only those they
+// were granted. An empty permissionArg matches staff with any permission at
+// the station.
+func stationAccessCondition(userArg, permissionArg string) string {
+	granted := "ms.member_id IS NOT NULL"
+	if permissionArg != "" {
+		granted = permissionArg + " = ANY(ms.permissions)"
+	}
+	return `EXISTS (
+			SELECT 1 FROM station_owner_members m
+			LEFT JOIN station_owner_member_stations ms ON ms.member_id = m.id AND ms.station_id = s.id
+			WHERE m.station_owner_id = s.owner_id AND m.user_id = ` + userArg + `
+				AND (m.role IN ('owner', 'manager') OR ` + granted + `)
+		)`
+}
+
+// stationManagerCondition returns a condition that holds when the user in
+// query argument userArg is an owner or manager of the business that owns
+// station s.
+func stationManagerCondition(userArg string) string {
+	return `EXISTS (
+			SELECT 1 FROM station_owner_members m
+			WHERE m.station_owner_id = s.owner_id AND m.user_id = ` + userArg + ` AND m.role IN ('owner', 'manager')
+		)`
+}
+
+// teamQueryer is a *sql.DB or *sql.Tx.
+type teamQueryer interface {
+	QueryRow(query string, args ...any) *sql.Row
+	Query(query string, args ...any) (*sql.Rows, error)
+}
+
+// PgOwnerTeamRepository is the PostgreSQL implementation of OwnerTeamRepository.
+type PgOwnerTeamRepository struct {
+	db *sql.DB
+}
+
+func NewPgOwnerTeamRepository(db *sql.DB) *PgOwnerTeamRepository {
+	return &PgOwnerTeamRepository{db: db}
+}
+
+const ownerTeamMemberColumns = `m.id, m.station_owner_id, m.user_id, u.email, COALESCE(u.display_name, ''), m.role,
+	so.user_id = m.user_id, m.created_at`
+
+const ownerTeamMemberFrom = `station_owner_members m
+	JOIN users u ON u.id = m.user_id
+	JOIN station_owners so ON so.id = m.station_owner_id`
+
+func scanOwnerTeamMember(row rowScanner, m *models.OwnerTeamMember) error {
+	return row.Scan(&m.ID, &m.StationOwnerID, &m.UserID, &m.Email, &m.DisplayName, &m.Role, &m.AccountHolder, &m.CreatedAt)
+}
+
+// loadMemberStations fills in the station permissions of staff members, for
+// stations their business still owns.
+func loadMemberStations(q teamQueryer, members []models.OwnerTeamMember) error {
+	index := make(map[string]int)
+	var ids []string
+	for i := range members {
+		members[i].Stations = []models.StationPermissions{}
+		if members[i].Role == models.OwnerRoleStaff {
+			index[members[i].ID] = i
+			ids = append(ids, members[i].ID)
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	rows, err := q.Query(`
+		SELECT ms.member_id, ms.station_id, s.name, ms.permissions
+		FROM station_owner_member_stations ms
+		JOIN station_owner_members m ON m.id = ms.member_id
+		JOIN stations s ON s.id = ms.station_id AND s.owner_id = m.station_owner_id
+		WHERE ms.member_id = ANY($1::uuid[])
+		ORDER BY s.name`,
+		pq.Array(ids),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to query member stations: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var memberID string
+		var p models.StationPermissions
+		if err := rows.Scan(&memberID, &p.StationID, &p.StationName, pq.Array(&p.Permissions)); err != nil {
+			return fmt.Errorf("failed to scan member station: %w", err)
+		}
+		i := index[memberID]
+		members[i].Stations = append(members[i].Stations, p)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating member station rows: %w", err)
+	}
+	return nil
+}
+
+// getMember returns the member matching condition, which may use query
+// argument $1, with their station permissions.
+func getMember(q teamQueryer, condition string, arg string) (*models.OwnerTeamMember, error) {
+	var m models.OwnerTeamMember
+	err := scanOwnerTeamMember(q.QueryRow(`SELECT `+ownerTeamMemberColumns+` FROM `+ownerTeamMemberFrom+` WHERE `+condition, arg), &m)
+	if err == sql.ErrNoRows {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get team member: %w", err)
+	}
+	members := []models.OwnerTeamMember{m}
+	if err := loadMemberStations(q, members); err != nil {
+		return nil, err
+	}
+	return &members[0], nil
+}
+
+// setMemberStations replaces a member's station permissions with stations,
+// skipping stations their business does not own.
+func setMemberStations(tx *sql.Tx, memberID string, stations []models.StationPermissions) error {
+	if _, err := tx.Exec(`DELETE FROM station_owner_member_stations WHERE member_id = $1`, memberID); err != nil {
+		return fmt.Errorf("failed to clear member stations: %w", err)
+	}
+	for _, p := range stations {
+		_, err := tx.Exec(`
+			INSERT INTO station_owner_member_stations (member_id, station_id, permissions)
+			SELECT m.id, s.id, $3
+			FROM station_owner_members m
+			JOIN stations s ON s.owner_id = m.station_owner_id
+			WHERE m.id = $1 AND s.id::text = $2`,
+			memberID, p.StationID, pq.Array(p.Permissions),
+		)
+		if err != nil {
+			return fmt.Errorf("failed to set member station: %w", err)
+		}
+	}
+	return nil
+}
+
+func (r *PgOwnerTeamRepository) GetMember(userID string) (*models.OwnerTeamMember, error) {
+	m, err := getMember(r.db, `m.user_id = $1`, userID)
+	if err == sql.ErrNoRows {
+		return nil, ErrStationOwnerNotFound
+	}
+	return m, err
+}
+
+func (r *PgOwnerTeamRepository) ListMembers(stationOwnerID string) ([]models.OwnerTeamMember, error) {
+	rows, err := r.db.Query(`
+		SELECT `+ownerTeamMemberColumns+`
+		FROM `+ownerTeamMemberFrom+`
+		WHERE m.station_owner_id = $1
+		ORDER BY m.created_at`,
+		stationOwnerID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list team members: %w", err)
+	}
+	defer rows.Close()
+
+	members := []models.OwnerTeamMember{}
+	for rows.Next() {
+		var m models.OwnerTeamMember
+		if err := scanOwnerTeamMember(rows, &m); err != nil {
+			return nil, fmt.Errorf("failed to scan team member: %w", err)
+		}
+		members = append(members, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating team member rows: %w", err)
+	}
+	rows.Close()
+
+	if err := loadMemberStations(r.db, members); err != nil {
+		return nil, err
+	}
+	return members, nil
+}
+
+// lockMember locks one of a business's members and reports whether they are
+// its account holder.
+func lockMember(tx *sql.Tx, stationOwnerID, memberID string) (bool, error) {
+	var accountHolder bool
+	err := tx.QueryRow(`
+		SELECT so.user_id = m.user_id
+		FROM station_owner_members m
+		JOIN station_owners so ON so.id = m.station_owner_id
+		WHERE m.id::text = $1 AND m.station_owner_id = $2
+		FOR UPDATE OF m`,
+		memberID, stationOwnerID,
+	).Scan(&accountHolder)
+	if err == sql.ErrNoRows {
+		return false, err
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to lock team member: %w", err)
+	}
+	return accountHolder, nil
+}
+
+// revokeMemberAPIKeys revokes the API keys a member created for the
+// business. Only owners and managers may hold keys, and keys publish as the
+// member who created them.
+func revokeMemberAPIKeys(tx *sql.Tx, stationOwnerID, memberID string) error {
+	_, err := tx.Exec(`
+		UPDATE owner_api_keys k
+		SET revoked_at = NOW()
+		FROM station_owner_members m
+		WHERE m.id = $1 AND k.station_owner_id = $2 AND k.created_by = m.user_id AND k.revoked_at IS NULL`,
+		memberID, stationOwnerID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to revoke team member's API keys: %w", err)
+	}
+	return nil
+}
+
+func (r *PgOwnerTeamRepository) UpdateMember(stationOwnerID, memberID, role string, stations []models.StationPermissions) (*models.OwnerTeamMember, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	accountHolder, err := lockMember(tx, stationOwnerID, memberID)
+	if err != nil {
+		return nil, err
+	}
+	if accountHolder && role != models.OwnerRoleOwner {
+		return nil, ErrAccountHolderMember
+	}
+
+	if _, err := tx.Exec(`UPDATE station_owner_members SET role = $1, updated_at = NOW() WHERE id = $2`, role, memberID); err != nil {
+		return nil, fmt.Errorf("failed to update team member: %w", err)
+	}
+	if role != models.OwnerRoleStaff {
+		stations = nil
+	} else if err := revokeMemberAPIKeys(tx, stationOwnerID, memberID); err != nil {
+		return nil, err
+	}
+	if err := setMemberStations(tx, memberID, stations); err != nil {
+		return nil, err
+	}
+
+	member, err := getMember(tx, `m.id = $1`, memberID)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return member, nil
+}
+
+func (r *PgOwnerTeamRepository) RemoveMember(stationOwnerID, memberID string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	accountHolder, err := lockMember(tx, stationOwnerID, memberID)
+	if err != nil {
+		return err
+	}
+	if accountHolder {
+		return ErrAccountHolderMember
+	}
+
+	if err := revokeMemberAPIKeys(tx, stationOwnerID, memberID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM station_owner_members WHERE id = $1`, memberID); err != nil {
+		return fmt.Errorf("failed to remove team member: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+const ownerTeamInvitationColumns = `i.id, i.station_owner_id, so.business_name, i.email, i.role, i.stations, i.invited_by,
+	i.expires_at, i.created_at`
+
+const ownerTeamInvitationFrom = `station_owner_invitations i JOIN station_owners so ON so.id = i.station_owner_id`
+
+// pendingInvitationCondition holds for an invitation i that has not been
+// accepted, revoked or expired.
+const pendingInvitationCondition = `i.accepted_at IS NULL AND i.revoked_at IS NULL AND i.expires_at > NOW()`
+
+func scanOwnerTeamInvitation(row rowScanner) (*models.OwnerTeamInvitation, error) {
+	var inv models.OwnerTeamInvitation
+	var stations []byte
+	err := row.Scan(&inv.ID, &inv.StationOwnerID, &inv.BusinessName, &inv.Email, &inv.Role, &stations, &inv.InvitedBy,
+		&inv.ExpiresAt, &inv.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(stations, &inv.Stations); err != nil {
+		return nil, fmt.Errorf("failed to decode invitation stations: %w", err)
+	}
+	if inv.Stations == nil {
+		inv.Stations = []models.StationPermissions{}
+	}
+	return &inv, nil
+}
+
+func (r *PgOwnerTeamRepository) CreateInvitation(inv *models.OwnerTeamInvitation, tokenHash string) error {
+	stations := inv.Stations
+	if stations == nil {
+		stations = []models.StationPermissions{}
+	}
+	stationsJSON, err := json.Marshal(stations)
+	if err != nil {
+		return fmt.Errorf("failed to encode invitation stations: %w", err)
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		UPDATE station_owner_invitations i
+		SET revoked_at = NOW()
+		WHERE i.station_owner_id = $1 AND LOWER(i.email) = LOWER($2) AND `+pendingInvitationCondition,
+		inv.StationOwnerID, inv.Email,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to revoke earlier invitations: %w", err)
+	}
+
+	inv.ID = uuid.New().String()
+	err = tx.QueryRow(`
+		INSERT INTO station_owner_invitations (id, station_owner_id, email, role, stations, token_hash, invited_by, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING created_at, (SELECT business_name FROM station_owners WHERE id = $2)`,
+		inv.ID, inv.StationOwnerID, inv.Email, inv.Role, string(stationsJSON), tokenHash, inv.InvitedBy, inv.ExpiresAt,
+	).Scan(&inv.CreatedAt, &inv.BusinessName)
+	if err != nil {
+		return fmt.Errorf("failed to create invitation: %w", err)
+	}
+	inv.Stations = stations
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+func (r *PgOwnerTeamRepository) ListInvitations(stationOwnerID string) ([]models.OwnerTeamInvitation, error) {
+	rows, err := r.db.Query(`
+		SELECT `+ownerTeamInvitationColumns+`
+		FROM `+ownerTeamInvitationFrom+`
+		WHERE i.station_owner_id = $1 AND `+pendingInvitationCondition+`
+		ORDER BY i.created_at DESC`,
+		stationOwnerID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list invitations: %w", err)
+	}
+	defer rows.Close()
+
+	invitations := []models.OwnerTeamInvitation{}
+	for rows.Next() {
+		inv, err := scanOwnerTeamInvitation(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan invitation: %w", err)
+		}
+		invitations = append(invitations, *inv)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating invitation rows: %w", err)
+	}
+	return invitations, nil
+}
+
+func (r *PgOwnerTeamRepository) RevokeInvitation(stationOwnerID, invitationID string) error {
+	result, err := r.db.Exec(`
+		UPDATE station_owner_invitations i
+		SET revoked_at = NOW()
+		WHERE i.id::text = $1 AND i.station_owner_id = $2 AND `+pendingInvitationCondition,
+		invitationID, stationOwnerID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to revoke invitation: %w", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func (r *PgOwnerTeamRepository) GetInvitationByToken(tokenHash string) (*models.OwnerTeamInvitation, error) {
+	inv, err := scanOwnerTeamInvitation(r.db.QueryRow(`
+		SELECT `+ownerTeamInvitationColumns+`
+		FROM `+ownerTeamInvitationFrom+`
+		WHERE i.token_hash = $1 AND `+pendingInvitationCondition,
+		tokenHash,
+	))
+	if err == sql.ErrNoRows {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get invitation: %w", err)
+	}
+	return inv, nil
+}
+
+func (r *PgOwnerTeamRepository) AcceptInvitation(invitationID, userID string) (*models.OwnerTeamMember, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	inv, err := scanOwnerTeamInvitation(tx.QueryRow(`
+		SELECT `+ownerTeamInvitationColumns+`
+		FROM `+ownerTeamInvitationFrom+`
+		WHERE i.id::text = $1 AND `+pendingInvitationCondition+`
+		FOR UPDATE OF i`,
+		invitationID,
+	))
+	if err == sql.ErrNoRows {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock invitation: %w", err)
+	}
+
+	var member bool
+	if err := tx.QueryRow(`SELECT EXISTS(SELECT 1 FROM station_owner_members WHERE user_id = $1)`, userID).Scan(&member); err != nil {
+		return nil, fmt.Errorf("failed to check team membership: %w", err)
+	}
+	if member {
+		return nil, ErrAlreadyTeamMember
+	}
+
+	memberID := uuid.New().String()
+	_, err = tx.Exec(`
+		INSERT INTO station_owner_members (id, station_owner_id, user_id, role, invited_by)
+		VALUES ($1, $2, $3, $4, $5)`,
+		memberID, inv.StationOwnerID, userID, inv.Role, inv.InvitedBy,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add team member: %w", err)
+	}
+	if inv.Role == models.OwnerRoleStaff {
+		if err := setMemberStations(tx, memberID, inv.Stations); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := tx.Exec(`UPDATE station_owner_invitations SET accepted_at = NOW(), accepted_by = $1 WHERE id = $2`, userID, inv.ID); err != nil {
+		return nil, fmt.Errorf("failed to accept invitation: %w", err)
+	}
+
+	accepted, err := getMember(tx, `m.id = $1`, memberID)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return accepted, nil
+}
+
+var _ OwnerTeamRepository = (*PgOwnerTeamRepository)(nil)
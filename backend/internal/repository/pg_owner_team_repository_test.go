@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"gaspeep/backend/internal/models"
+	"gaspeep/backend/internal/repository/testhelpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestOwnerTeam_InvitationLifecycle tests that an invited staff member gets
+// only the station permissions they were invited with, and loses them when
+// removed
+func TestOwnerTeam_InvitationLifecycle(t *testing.T) {
+	db := testhelpers.SetupTestDBWithCleanup(t)
+
+	holder := testhelpers.CreateTestUser(t, db)
+	staffUser := testhelpers.CreateTestUser(t, db)
+	owner := testhelpers.CreateTestStationOwner(t, db, holder.ID)
+	station := testhelpers.CreateTestStation(t, db, -33.8568, 151.2153)
+	_, err := db.Exec("UPDATE stations SET owner_id = $1 WHERE id = $2", owner.ID, station.ID)
+	require.NoError(t, err)
+
+	repo := NewPgOwnerTeamRepository(db)
+	ownerRepo := NewPgStationOwnerRepository(db)
+
+	inv := &models.OwnerTeamInvitation{
+		StationOwnerID: owner.ID,
+		Email:          staffUser.Email,
+		Role:           models.OwnerRoleStaff,
+		Stations: []models.StationPermissions{{
+			StationID:   station.ID,
+			Permissions: []string{models.StationPermissionPublishPrices},
+		}},
+		InvitedBy: &holder.ID,
+		ExpiresAt: time.Now().Add(7 * 24 * time.Hour),
+	}
+	require.NoError(t, repo.CreateInvitation(inv, "hash-1"))
+	require.NotEmpty(t, inv.ID)
+
+	pending, err := repo.ListInvitations(owner.ID)
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	assert.Equal(t, owner.BusinessName, pending[0].BusinessName)
+
+	found, err := repo.GetInvitationByToken("hash-1")
+	require.NoError(t, err)
+	assert.Equal(t, inv.ID, found.ID)
+	assert.Equal(t, inv.Stations, found.Stations)
+
+	member, err := repo.AcceptInvitation(inv.ID, staffUser.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.OwnerRoleStaff, member.Role)
+	assert.False(t, member.AccountHolder)
+	require.Len(t, member.Stations, 1)
+	assert.Equal(t, station.Name, member.Stations[0].StationName)
+	_, err = repo.AcceptInvitation(inv.ID, staffUser.ID)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	_, err = repo.GetInvitationByToken("hash-1")
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+
+	// Staff see the business's stations but only hold the permissions given
+	canPublish, err := ownerRepo.HasStationPermission(staffUser.ID, station.ID, models.StationPermissionPublishPrices)
+	require.NoError(t, err)
+	assert.True(t, canPublish)
+	canBroadcast, err := ownerRepo.HasStationPermission(staffUser.ID, station.ID, models.StationPermissionSendBroadcasts)
+	require.NoError(t, err)
+	assert.False(t, canBroadcast)
+	stations, err := ownerRepo.GetStationsByOwnerUserID(staffUser.ID)
+	require.NoError(t, err)
+	assert.Len(t, stations, 1)
+	business, err := ownerRepo.GetByUserID(staffUser.ID)
+	require.NoError(t, err)
+	assert.Equal(t, owner.ID, business.ID)
+	assert.Equal(t, models.OwnerRoleStaff, business.Role)
+
+	// The account holder cannot be demoted or removed
+	members, err := repo.ListMembers(owner.ID)
+	require.NoError(t, err)
+	require.Len(t, members, 2)
+	assert.True(t, members[0].AccountHolder)
+	_, err = repo.UpdateMember(owner.ID, members[0].ID, models.OwnerRoleManager, nil)
+	assert.ErrorIs(t, err, ErrAccountHolderMember)
+	assert.ErrorIs(t, repo.RemoveMember(owner.ID, members[0].ID), ErrAccountHolderMember)
+
+	require.NoError(t, repo.RemoveMember(owner.ID, member.ID))
+	canPublish, err = ownerRepo.HasStationPermission(staffUser.ID, station.ID, models.StationPermissionPublishPrices)
+	require.NoError(t, err)
+	assert.False(t, canPublish)
+	_, err = repo.GetMember(staffUser.ID)
+	assert.ErrorIs(t, err, ErrStationOwnerNotFound)
+}
+
+// TestOwnerTeam_DemotingRevokesAPIKeys tests that a manager's API keys stop
+// working once they are made staff, while other members' keys keep working
+func TestOwnerTeam_DemotingRevokesAPIKeys(t *testing.T) {
+	db := testhelpers.SetupTestDBWithCleanup(t)
+
+	holder := testhelpers.CreateTestUser(t, db)
+	managerUser := testhelpers.CreateTestUser(t, db)
+	owner := testhelpers.CreateTestStationOwner(t, db, holder.ID)
+	station := testhelpers.CreateTestStation(t, db, -33.8568, 151.2153)
+
+	repo := NewPgOwnerTeamRepository(db)
+	inv := &models.OwnerTeamInvitation{
+		StationOwnerID: owner.ID,
+		Email:          managerUser.Email,
+		Role:           models.OwnerRoleManager,
+		ExpiresAt:      time.Now().Add(7 * 24 * time.Hour),
+	}
+	require.NoError(t, repo.CreateInvitation(inv, "hash-1"))
+	manager, err := repo.AcceptInvitation(inv.ID, managerUser.ID)
+	require.NoError(t, err)
+
+	keyRepo := NewPgOwnerAPIKeyRepository(db)
+	holderKey := createTestAPIKey(t, keyRepo, owner, station.ID, "key-hash-1")
+	managerKey := &models.OwnerAPIKey{
+		StationOwnerID: owner.ID,
+		Name:           "Pricing system",
+		Prefix:         "gpk_ijklmnop",
+		Scopes:         []string{models.APIKeyScopePricesWrite},
+		StationIDs:     []string{station.ID},
+	}
+	require.NoError(t, keyRepo.Create(managerKey, "key-hash-2", managerUser.ID))
+
+	_, err = repo.UpdateMember(owner.ID, manager.ID, models.OwnerRoleStaff, nil)
+	require.NoError(t, err)
+
+	_, err = keyRepo.GetByHash("key-hash-2")
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	found, err := keyRepo.GetByHash("key-hash-1")
+	require.NoError(t, err)
+	assert.Equal(t, holderKey.ID, found.ID)
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"database/sql"
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
@@ -16,22 +17,58 @@ func NewPgPasswordResetRepository(db *sql.DB) *PgPasswordResetRepository {
 	return &PgPasswordResetRepository{db: db}
 }
 
-func (r *PgPasswordResetRepository) Create(userID, token string, expiresAt time.Time) error {
-	id := uuid.New().String()
-	_, err := r.db.Exec(`INSERT INTO password_resets (id, user_id, token, expires_at, created_at) VALUES ($1, $2, $3, $4, NOW())`, id, userID, token, expiresAt)
-	return err
+func (r *PgPasswordResetRepository) Create(userID, selector, tokenHash string, expiresAt time.Time) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM password_resets WHERE user_id = $1`, userID); err != nil {
+		return fmt.Errorf("failed to delete earlier password reset tokens: %w", err)
+	}
+	_, err = tx.Exec(`
+		INSERT INTO password_resets (id, user_id, selector, token_hash, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, NOW())`,
+		uuid.New().String(), userID, selector, tokenHash, expiresAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create password reset token: %w", err)
+	}
+	return tx.Commit()
+}
+
+func (r *PgPasswordResetRepository) FindBySelector(selector string) (*PasswordReset, error) {
+	var reset PasswordReset
+	err := r.db.QueryRow(`SELECT user_id, token_hash, expires_at FROM password_resets WHERE selector = $1`, selector).
+		Scan(&reset.UserID, &reset.TokenHash, &reset.ExpiresAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, sql.ErrNoRows
+		}
+		return nil, fmt.Errorf("failed to find password reset token: %w", err)
+	}
+	return &reset, nil
 }
 
-func (r *PgPasswordResetRepository) FindByToken(token string) (string, time.Time, error) {
-	var userID string
-	var expiresAt time.Time
-	err := r.db.QueryRow(`SELECT user_id, expires_at FROM password_resets WHERE token = $1`, token).Scan(&userID, &expiresAt)
-	return userID, expiresAt, err
+func (r *PgPasswordResetRepository) Consume(selector string) (bool, error) {
+	result, err := r.db.Exec(`DELETE FROM password_resets WHERE selector = $1`, selector)
+	if err != nil {
+		return false, fmt.Errorf("failed to consume password reset token: %w", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to consume password reset token: %w", err)
+	}
+	return n > 0, nil
 }
 
-func (r *PgPasswordResetRepository) DeleteByToken(token string) error {
-	_, err := r.db.Exec(`DELETE FROM password_resets WHERE token = $1`, token)
-	return err
+func (r *PgPasswordResetRepository) DeleteExpiredBefore(cutoff time.Time) (int64, error) {
+	result, err := r.db.Exec(`DELETE FROM password_resets WHERE expires_at < $1`, cutoff)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired password reset tokens: %w", err)
+	}
+	return result.RowsAffected()
 }
 
 var _ PasswordResetRepository = (*PgPasswordResetRepository)(nil)
@@ -10,49 +10,73 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
-func TestPgPasswordResetRepository_CreateFindDeleteByToken(t *testing.T) {
+func TestPgPasswordResetRepository_CreateFindConsume(t *testing.T) {
 	db := testhelpers.SetupTestDBWithCleanup(t)
 
 	user := testhelpers.CreateTestUser(t, db)
-	token := "reset-token-001"
 	expiresAt := time.Now().Add(30 * time.Minute).UTC().Truncate(time.Second)
 
 	repo := NewPgPasswordResetRepository(db)
-	err := repo.Create(user.ID, token, expiresAt)
+	err := repo.Create(user.ID, "selector-001", "hash-001", expiresAt)
 	require.NoError(t, err)
 
-	foundUserID, foundExpiresAt, err := repo.FindByToken(token)
+	reset, err := repo.FindBySelector("selector-001")
 	require.NoError(t, err)
-	assert.Equal(t, user.ID, foundUserID)
-	assert.WithinDuration(t, expiresAt, foundExpiresAt, time.Second)
+	assert.Equal(t, user.ID, reset.UserID)
+	assert.Equal(t, "hash-001", reset.TokenHash)
+	assert.WithinDuration(t, expiresAt, reset.ExpiresAt, time.Second)
 
-	err = repo.DeleteByToken(token)
+	// Only the first consumer gets the token
+	used, err := repo.Consume("selector-001")
 	require.NoError(t, err)
+	assert.True(t, used)
+	used, err = repo.Consume("selector-001")
+	require.NoError(t, err)
+	assert.False(t, used)
 
-	_, _, err = repo.FindByToken(token)
+	_, err = repo.FindBySelector("selector-001")
 	require.ErrorIs(t, err, sql.ErrNoRows)
 }
 
-func TestPgPasswordResetRepository_Create_DuplicateToken(t *testing.T) {
+func TestPgPasswordResetRepository_CreateInvalidatesEarlierTokens(t *testing.T) {
 	db := testhelpers.SetupTestDBWithCleanup(t)
 
 	user := testhelpers.CreateTestUser(t, db)
 	repo := NewPgPasswordResetRepository(db)
-	token := "reset-token-duplicate"
 	expiresAt := time.Now().Add(1 * time.Hour)
 
-	err := repo.Create(user.ID, token, expiresAt)
+	require.NoError(t, repo.Create(user.ID, "selector-old", "hash-old", expiresAt))
+	require.NoError(t, repo.Create(user.ID, "selector-new", "hash-new", expiresAt))
+
+	_, err := repo.FindBySelector("selector-old")
+	require.ErrorIs(t, err, sql.ErrNoRows)
+	_, err = repo.FindBySelector("selector-new")
+	require.NoError(t, err)
+}
+
+func TestPgPasswordResetRepository_DeleteExpiredBefore(t *testing.T) {
+	db := testhelpers.SetupTestDBWithCleanup(t)
+
+	expired := testhelpers.CreateTestUser(t, db)
+	current := testhelpers.CreateTestUser(t, db)
+	repo := NewPgPasswordResetRepository(db)
+
+	require.NoError(t, repo.Create(expired.ID, "selector-expired", "hash-1", time.Now().Add(-2*time.Hour)))
+	require.NoError(t, repo.Create(current.ID, "selector-current", "hash-2", time.Now().Add(time.Hour)))
+
+	deleted, err := repo.DeleteExpiredBefore(time.Now())
 	require.NoError(t, err)
+	assert.Equal(t, int64(1), deleted)
 
-	err = repo.Create(user.ID, token, expiresAt.Add(1*time.Hour))
-	require.Error(t, err)
+	_, err = repo.FindBySelector("selector-current")
+	require.NoError(t, err)
 }
 
-func TestPgPasswordResetRepository_FindByToken_NotFound(t *testing.T) {
+func TestPgPasswordResetRepository_FindBySelector_NotFound(t *testing.T) {
 	db := testhelpers.SetupTestDBWithCleanup(t)
 	repo := NewPgPasswordResetRepository(db)
 
-	_, _, err := repo.FindByToken("missing-token")
+	_, err := repo.FindBySelector("missing-selector")
 
 	require.ErrorIs(t, err, sql.ErrNoRows)
 }
@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// PgPriceChangeOutboxRepository is the PostgreSQL implementation of PriceChangeOutboxRepository.
+type PgPriceChangeOutboxRepository struct {
+	db *sql.DB
+}
+
+func NewPgPriceChangeOutboxRepository(db *sql.DB) *PgPriceChangeOutboxRepository {
+	return &PgPriceChangeOutboxRepository{db: db}
+}
+
+// Claim uses SKIP LOCKED so concurrent workers never claim the same event, and
+// returns events oldest first.
+func (r *PgPriceChangeOutboxRepository) Claim(limit int, lease time.Duration) ([]PriceChangeEvent, error) {
+	rows, err := r.db.Query(`
+		WITH claimed AS (
+			UPDATE price_change_events e
+			SET
+				status = 'processing',
+				attempts = e.attempts + 1,
+				locked_until = NOW() + make_interval(secs => $2)
+			WHERE e.id IN (
+				SELECT id FROM price_change_events
+				WHERE (status = 'pending' AND next_attempt_at <= NOW())
+					OR (status = 'processing' AND locked_until < NOW())
+				ORDER BY created_at
+				LIMIT $1
+				FOR UPDATE SKIP LOCKED
+			)
+			RETURNING e.id, e.station_id, e.fuel_type_id, e.price, e.previous_price, e.source, e.attempts, e.created_at
+		)
+		SELECT id, station_id, fuel_type_id, price, previous_price, source, attempts, created_at
+		FROM claimed
+		ORDER BY created_at`,
+		limit, lease.Seconds(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim price change events: %w", err)
+	}
+	defer rows.Close()
+
+	events := make([]PriceChangeEvent, 0)
+	for rows.Next() {
+		var e PriceChangeEvent
+		if err := rows.Scan(&e.ID, &e.StationID, &e.FuelTypeID, &e.Price, &e.PreviousPrice, &e.Source, &e.Attempts, &e.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan price change event: %w", err)
+		}
+		events = append(events, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating price change events: %w", err)
+	}
+
+	return events, nil
+}
+
+func (r *PgPriceChangeOutboxRepository) Complete(ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	_, err := r.db.Exec(`
+		UPDATE price_change_events
+		SET status = 'done', locked_until = NULL, last_error = NULL, processed_at = NOW()
+		WHERE id = ANY($1::uuid[]) AND status <> 'done'`,
+		pq.Array(ids),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to complete price change events: %w", err)
+	}
+	return nil
+}
+
+func (r *PgPriceChangeOutboxRepository) Retry(id, lastError string, delay time.Duration) error {
+	_, err := r.db.Exec(`
+		UPDATE price_change_events
+		SET status = 'pending', next_attempt_at = NOW() + make_interval(secs => $3), locked_until = NULL, last_error = $2
+		WHERE id = $1 AND status = 'processing'`,
+		id, lastError, delay.Seconds(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to reschedule price change event: %w", err)
+	}
+	return nil
+}
+
+func (r *PgPriceChangeOutboxRepository) Fail(id, lastError string) error {
+	_, err := r.db.Exec(`
+		UPDATE price_change_events
+		SET status = 'failed', locked_until = NULL, last_error = $2, processed_at = NOW()
+		WHERE id = $1 AND status = 'processing'`,
+		id, lastError,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to mark price change event failed: %w", err)
+	}
+	return nil
+}
+
+func (r *PgPriceChangeOutboxRepository) DeleteProcessedBefore(cutoff time.Time) (int64, error) {
+	result, err := r.db.Exec(`DELETE FROM price_change_events WHERE status = 'done' AND processed_at < $1`, cutoff)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete processed price change events: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+var _ PriceChangeOutboxRepository = (*PgPriceChangeOutboxRepository)(nil)
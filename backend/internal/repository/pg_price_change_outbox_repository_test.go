@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"gaspeep/backend/internal/repository/testhelpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpsertFuelPrice_QueuesPriceChangeEvent(t *testing.T) {
+	db := testhelpers.SetupTestDBWithCleanup(t)
+
+	station := testhelpers.CreateTestStation(t, db, -33.8568, 151.2153)
+	fuelTypeID := testhelpers.CreateTestFuelType(t, db, "E10")
+
+	fuelPriceRepo := NewPgFuelPriceRepository(db)
+	require.NoError(t, fuelPriceRepo.UpsertFuelPrice(station.ID, fuelTypeID, 1.55))
+	require.NoError(t, fuelPriceRepo.UpsertFuelPrice(station.ID, fuelTypeID, 1.60))
+
+	repo := NewPgPriceChangeOutboxRepository(db)
+	events, err := repo.Claim(10, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	assert.Equal(t, station.ID, events[1].StationID)
+	assert.InDelta(t, 1.60, events[1].Price, 0.0001)
+	require.NotNil(t, events[1].PreviousPrice)
+	assert.InDelta(t, 1.55, *events[1].PreviousPrice, 0.0001)
+	assert.Equal(t, PriceChangeSourceSubmission, events[1].Source)
+	assert.Equal(t, 1, events[1].Attempts)
+
+	// Claimed events are leased and not handed out again
+	again, err := repo.Claim(10, time.Minute)
+	require.NoError(t, err)
+	assert.Empty(t, again)
+}
+
+func TestPriceChangeOutbox_RetryAndComplete(t *testing.T) {
+	db := testhelpers.SetupTestDBWithCleanup(t)
+
+	station := testhelpers.CreateTestStation(t, db, -33.8568, 151.2153)
+	fuelTypeID := testhelpers.CreateTestFuelType(t, db, "U91")
+	require.NoError(t, NewPgFuelPriceRepository(db).UpsertFuelPrice(station.ID, fuelTypeID, 1.80))
+
+	repo := NewPgPriceChangeOutboxRepository(db)
+	events, err := repo.Claim(10, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+
+	require.NoError(t, repo.Retry(events[0].ID, "temporary failure", 0))
+	events, err = repo.Claim(10, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, 2, events[0].Attempts)
+
+	alertRepo := NewPgAlertRepository(db)
+	_, err = alertRepo.RecordEvaluations(events[0].ID, station.ID, 1.80, nil)
+	require.NoError(t, err)
+
+	// Completed events are not claimed again, even once the lease has expired
+	events, err = repo.Claim(10, 0)
+	require.NoError(t, err)
+	assert.Empty(t, events)
+
+	deleted, err := repo.DeleteProcessedBefore(time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), deleted)
+}
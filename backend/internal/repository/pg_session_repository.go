@@ -0,0 +1,197 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"gaspeep/backend/internal/models"
+	"github.com/google/uuid"
+)
+
+// PgSessionRepository is the PostgreSQL implementation of SessionRepository.
+type PgSessionRepository struct {
+	db *sql.DB
+}
+
+func NewPgSessionRepository(db *sql.DB) *PgSessionRepository {
+	return &PgSessionRepository{db: db}
+}
+
+const sessionColumns = `id, user_id, user_agent, device_name, ip_address, mfa_verified, created_at, last_used_at, expires_at`
+
+func scanSession(row rowScanner, s *models.Session) error {
+	return row.Scan(&s.ID, &s.UserID, &s.UserAgent, &s.DeviceName, &s.IPAddress, &s.MFAVerified, &s.CreatedAt, &s.LastUsedAt, &s.ExpiresAt)
+}
+
+func (r *PgSessionRepository) Create(input CreateSessionInput) (*models.Session, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var session models.Session
+	err = scanSession(tx.QueryRow(`
+		INSERT INTO user_sessions (id, user_id, user_agent, device_name, ip_address, mfa_verified, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING `+sessionColumns,
+		uuid.New().String(), input.UserID, input.UserAgent, input.DeviceName, input.IPAddress, input.MFAVerified, input.ExpiresAt,
+	), &session)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create session: %w", err)
+	}
+
+	_, err = tx.Exec(`INSERT INTO session_refresh_tokens (token_hash, session_id) VALUES ($1, $2)`,
+		input.RefreshTokenHash, session.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create refresh token: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit session: %w", err)
+	}
+	return &session, nil
+}
+
+// Rotate locks the token and its session, so when the same token is
+// presented twice at once exactly one request gets the new token and the
+// other is treated as reuse.
+func (r *PgSessionRepository) Rotate(input RotateSessionInput) (*models.Session, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var sessionID string
+	var rotated bool
+	err = tx.QueryRow(`
+		SELECT t.session_id, t.rotated_at IS NOT NULL
+		FROM session_refresh_tokens t
+		JOIN user_sessions s ON s.id = t.session_id
+		WHERE t.token_hash = $1 AND s.revoked_at IS NULL AND s.expires_at > NOW()
+		FOR UPDATE`,
+		input.TokenHash,
+	).Scan(&sessionID, &rotated)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, sql.ErrNoRows
+		}
+		return nil, fmt.Errorf("failed to find refresh token: %w", err)
+	}
+
+	if rotated {
+		_, err = tx.Exec(`UPDATE user_sessions SET revoked_at = NOW(), revoked_reason = $2 WHERE id = $1`,
+			sessionID, SessionRevokedTokenReuse)
+		if err != nil {
+			return nil, fmt.Errorf("failed to revoke session: %w", err)
+		}
+		if err := tx.Commit(); err != nil {
+			return nil, fmt.Errorf("failed to commit session revocation: %w", err)
+		}
+		return nil, ErrRefreshTokenReused
+	}
+
+	if _, err := tx.Exec(`UPDATE session_refresh_tokens SET rotated_at = NOW() WHERE token_hash = $1`, input.TokenHash); err != nil {
+		return nil, fmt.Errorf("failed to rotate refresh token: %w", err)
+	}
+	_, err = tx.Exec(`INSERT INTO session_refresh_tokens (token_hash, session_id) VALUES ($1, $2)`,
+		input.NewTokenHash, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create refresh token: %w", err)
+	}
+
+	var session models.Session
+	err = scanSession(tx.QueryRow(`
+		UPDATE user_sessions
+		SET last_used_at = NOW(),
+		    expires_at = $2,
+		    user_agent = COALESCE(NULLIF($3, ''), user_agent),
+		    ip_address = COALESCE(NULLIF($4, ''), ip_address)
+		WHERE id = $1
+		RETURNING `+sessionColumns,
+		sessionID, input.ExpiresAt, input.UserAgent, input.IPAddress,
+	), &session)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update session: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit refresh token rotation: %w", err)
+	}
+	return &session, nil
+}
+
+func (r *PgSessionRepository) ListActive(userID string) ([]models.Session, error) {
+	rows, err := r.db.Query(`
+		SELECT `+sessionColumns+`
+		FROM user_sessions
+		WHERE user_id = $1 AND revoked_at IS NULL AND expires_at > NOW()
+		ORDER BY last_used_at DESC`,
+		userID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+	defer rows.Close()
+
+	sessions := []models.Session{}
+	for rows.Next() {
+		var s models.Session
+		if err := scanSession(rows, &s); err != nil {
+			return nil, fmt.Errorf("failed to scan session: %w", err)
+		}
+		sessions = append(sessions, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+	return sessions, nil
+}
+
+func (r *PgSessionRepository) Revoke(userID, sessionID, reason string) (bool, error) {
+	result, err := r.db.Exec(`
+		UPDATE user_sessions SET revoked_at = NOW(), revoked_reason = $3
+		WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL AND expires_at > NOW()`,
+		sessionID, userID, reason,
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to revoke session: %w", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to revoke session: %w", err)
+	}
+	return n > 0, nil
+}
+
+func (r *PgSessionRepository) RevokeByRefreshToken(tokenHash, reason string) error {
+	_, err := r.db.Exec(`
+		UPDATE user_sessions s SET revoked_at = NOW(), revoked_reason = $2
+		FROM session_refresh_tokens t
+		WHERE t.token_hash = $1 AND t.rotated_at IS NULL AND s.id = t.session_id AND s.revoked_at IS NULL`,
+		tokenHash, reason,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to revoke session: %w", err)
+	}
+	return nil
+}
+
+func (r *PgSessionRepository) RevokeAll(userID, exceptSessionID, reason string) (int64, error) {
+	result, err := r.db.Exec(`
+		UPDATE user_sessions SET revoked_at = NOW(), revoked_reason = $3
+		WHERE user_id = $1 AND revoked_at IS NULL AND expires_at > NOW() AND id::text <> $2`,
+		userID, exceptSessionID, reason,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+	return n, nil
+}
+
+var _ SessionRepository = (*PgSessionRepository)(nil)
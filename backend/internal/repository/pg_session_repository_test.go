@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"gaspeep/backend/internal/repository/testhelpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSession_RotateDetectsReuse(t *testing.T) {
+	db := testhelpers.SetupTestDBWithCleanup(t)
+
+	user := testhelpers.CreateTestUser(t, db)
+	repo := NewPgSessionRepository(db)
+
+	session, err := repo.Create(CreateSessionInput{
+		UserID:           user.ID,
+		RefreshTokenHash: "hash-1",
+		UserAgent:        "Mozilla/5.0",
+		DeviceName:       "Pixel 8",
+		IPAddress:        "203.0.113.7",
+		MFAVerified:      true,
+		ExpiresAt:        time.Now().Add(time.Hour),
+	})
+	require.NoError(t, err)
+	assert.True(t, session.MFAVerified)
+
+	rotated, err := repo.Rotate(RotateSessionInput{TokenHash: "hash-1", NewTokenHash: "hash-2", IPAddress: "203.0.113.8", ExpiresAt: time.Now().Add(2 * time.Hour)})
+	require.NoError(t, err)
+	assert.Equal(t, session.ID, rotated.ID)
+	assert.Equal(t, "Mozilla/5.0", rotated.UserAgent)
+	assert.Equal(t, "203.0.113.8", rotated.IPAddress)
+	assert.True(t, rotated.ExpiresAt.After(session.ExpiresAt))
+	assert.True(t, rotated.MFAVerified)
+
+	// Replaying the old token revokes the session, so the new one stops working too
+	_, err = repo.Rotate(RotateSessionInput{TokenHash: "hash-1", NewTokenHash: "hash-3", ExpiresAt: time.Now().Add(time.Hour)})
+	assert.ErrorIs(t, err, ErrRefreshTokenReused)
+	_, err = repo.Rotate(RotateSessionInput{TokenHash: "hash-2", NewTokenHash: "hash-4", ExpiresAt: time.Now().Add(time.Hour)})
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+
+	var reason string
+	require.NoError(t, db.QueryRow(`SELECT revoked_reason FROM user_sessions WHERE id = $1`, session.ID).Scan(&reason))
+	assert.Equal(t, SessionRevokedTokenReuse, reason)
+
+	_, err = repo.Rotate(RotateSessionInput{TokenHash: "unknown", NewTokenHash: "hash-5", ExpiresAt: time.Now().Add(time.Hour)})
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestSession_ListAndRevoke(t *testing.T) {
+	db := testhelpers.SetupTestDBWithCleanup(t)
+
+	user := testhelpers.CreateTestUser(t, db)
+	other := testhelpers.CreateTestUser(t, db)
+	repo := NewPgSessionRepository(db)
+
+	create := func(userID, hash string, expiresAt time.Time) string {
+		s, err := repo.Create(CreateSessionInput{UserID: userID, RefreshTokenHash: hash, ExpiresAt: expiresAt})
+		require.NoError(t, err)
+		return s.ID
+	}
+	phone := create(user.ID, "phone", time.Now().Add(time.Hour))
+	create(user.ID, "laptop", time.Now().Add(time.Hour))
+	tablet := create(user.ID, "tablet", time.Now().Add(time.Hour))
+	create(user.ID, "expired", time.Now().Add(-time.Minute))
+	otherSession := create(other.ID, "other", time.Now().Add(time.Hour))
+
+	sessions, err := repo.ListActive(user.ID)
+	require.NoError(t, err)
+	assert.Len(t, sessions, 3)
+
+	// Users can only revoke their own sessions
+	ok, err := repo.Revoke(user.ID, otherSession, SessionRevokedByUser)
+	require.NoError(t, err)
+	assert.False(t, ok)
+	ok, err = repo.Revoke(user.ID, tablet, SessionRevokedByUser)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	require.NoError(t, repo.RevokeByRefreshToken("laptop", SessionRevokedLogout))
+
+	sessions, err = repo.ListActive(user.ID)
+	require.NoError(t, err)
+	require.Len(t, sessions, 1)
+	assert.Equal(t, phone, sessions[0].ID)
+
+	create(user.ID, "desktop", time.Now().Add(time.Hour))
+	n, err := repo.RevokeAll(user.ID, phone, SessionRevokedPasswordChange)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+	n, err = repo.RevokeAll(user.ID, "", SessionRevokedPasswordReset)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+
+	sessions, err = repo.ListActive(other.ID)
+	require.NoError(t, err)
+	assert.Len(t, sessions, 1)
+}
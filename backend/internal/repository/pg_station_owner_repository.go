@@ -20,6 +20,20 @@ func NewPgStationOwnerRepository(db *sql.DB) *PgStationOwnerRepository {
 }
 
 func (r *PgStationOwnerRepository) CreateVerificationRequest(userID string, input CreateOwnerVerificationInput) (*models.StationOwner, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var member bool
+	if err := tx.QueryRow(`SELECT EXISTS(SELECT 1 FROM station_owner_members WHERE user_id = $1)`, userID).Scan(&member); err != nil {
+		return nil, fmt.Errorf("failed to check team membership: %w", err)
+	}
+	if member {
+		return nil, ErrAlreadyTeamMember
+	}
+
 	id := uuid.New().String()
 	query := `
 		INSERT INTO station_owners (
@@ -31,26 +45,66 @@ func (r *PgStationOwnerRepository) CreateVerificationRequest(userID string, inpu
 	var verDocs string
 	var verifiedAt *time.Time
 
-	err := r.db.QueryRow(query, id, userID, input.BusinessName, input.VerificationDocuments, input.ContactInfo).Scan(
+	err = tx.QueryRow(query, id, userID, input.BusinessName, input.VerificationDocuments, input.ContactInfo).Scan(
 		&owner.ID, &owner.UserID, &owner.BusinessName, &owner.VerificationStatus, &verDocs, &owner.ContactInfo, &owner.CreatedAt, &verifiedAt,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create verification request: %w", err)
 	}
+	if err := addAccountHolder(tx, owner.ID, userID); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
 
 	owner.VerificationDocuments = []string{verDocs}
 	owner.VerifiedAt = verifiedAt
+	owner.Role = models.OwnerRoleOwner
 
 	return &owner, nil
 }
 
+// addAccountHolder makes the user who set up a business its first owner.
+func addAccountHolder(tx *sql.Tx, stationOwnerID, userID string) error {
+	_, err := tx.Exec(`
+		INSERT INTO station_owner_members (id, station_owner_id, user_id, role)
+		VALUES ($1, $2, $3, 'owner')`,
+		uuid.New().String(), stationOwnerID, userID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to add account holder to team: %w", err)
+	}
+	return nil
+}
+
+// ownerClaimJoin joins, as cv, the claim that sets an owner's verification
+// status for station s: their approved claim if they have one, otherwise
+// their latest. Approved claims past their expiry show as expired, and
+// renewal_pending is set while a re-verification waits for review.
+const ownerClaimJoin = `LEFT JOIN LATERAL (
+			SELECT CASE WHEN c.verification_status = 'approved' AND c.expires_at <= NOW() THEN 'expired'
+					ELSE c.verification_status END AS verification_status,
+				c.verified_at, c.expires_at,
+				EXISTS(
+					SELECT 1 FROM claim_verifications p
+					WHERE p.station_id = s.id AND p.station_owner_id = so.id
+						AND p.renews_id IS NOT NULL AND p.verification_status = 'pending'
+				) AS renewal_pending
+			FROM claim_verifications c
+			WHERE c.station_id = s.id AND c.station_owner_id = so.id AND c.verification_status <> 'renewed'
+			ORDER BY c.verification_status = 'approved' DESC, c.created_at DESC
+			LIMIT 1
+		) cv ON TRUE`
+
 func (r *PgStationOwnerRepository) GetStationsByOwnerUserID(userID string) ([]map[string]interface{}, error) {
 	query := `
-		SELECT s.id, s.name, s.brand, s.address, s.latitude, s.longitude, s.operating_hours, s.amenities, s.last_verified_at, cv.verification_status, cv.verified_at
+		SELECT s.id, s.name, s.brand, s.address, s.latitude, s.longitude, s.operating_hours, s.amenities, s.last_verified_at,
+			cv.verification_status, cv.verified_at, cv.expires_at, COALESCE(cv.renewal_pending, false)
 		FROM stations s
 		INNER JOIN station_owners so ON so.id = s.owner_id
-		LEFT JOIN claim_verifications cv ON cv.station_id = s.id AND cv.station_owner_id = so.id
-		WHERE so.user_id = $1
+		` + ownerClaimJoin + `
+		WHERE ` + stationAccessCondition("$1", "") + `
 		ORDER BY s.created_at DESC`
 
 	rows, err := r.db.Query(query, userID)
@@ -67,10 +121,12 @@ func (r *PgStationOwnerRepository) GetStationsByOwnerUserID(userID string) ([]ma
 			latitude, longitude                      float64
 			amenities                                interface{}
 			lastVerifiedAt                           sql.NullTime
-			verifiedAt                               *time.Time
+			verifiedAt, expiresAt                    *time.Time
+			renewalPending                           bool
 		)
 
-		if err := rows.Scan(&id, &name, &brand, &address, &latitude, &longitude, &operatingHours, &amenities, &lastVerifiedAt, &verificationStatus, &verifiedAt); err != nil {
+		if err := rows.Scan(&id, &name, &brand, &address, &latitude, &longitude, &operatingHours, &amenities, &lastVerifiedAt,
+			&verificationStatus, &verifiedAt, &expiresAt, &renewalPending); err != nil {
 			return nil, fmt.Errorf("failed to scan station: %w", err)
 		}
 
@@ -91,17 +147,19 @@ func (r *PgStationOwnerRepository) GetStationsByOwnerUserID(userID string) ([]ma
 		}
 
 		station := map[string]interface{}{
-			"id":                 id,
-			"name":               name,
-			"brand":              brand,
-			"address":            address,
-			"latitude":           latitude,
-			"longitude":          longitude,
-			"operatingHours":     operatingHours,
-			"amenities":          parsedAmenities,
-			"lastVerifiedAt":     lastVerifiedAtValue,
-			"verificationStatus": status,
-			"verifiedAt":         verifiedAt,
+			"id":                    id,
+			"name":                  name,
+			"brand":                 brand,
+			"address":               address,
+			"latitude":              latitude,
+			"longitude":             longitude,
+			"operatingHours":        operatingHours,
+			"amenities":             parsedAmenities,
+			"lastVerifiedAt":        lastVerifiedAtValue,
+			"verificationStatus":    status,
+			"verifiedAt":            verifiedAt,
+			"verificationExpiresAt": expiresAt,
+			"reVerificationPending": renewalPending,
 		}
 		stations = append(stations, station)
 	}
@@ -115,12 +173,12 @@ func (r *PgStationOwnerRepository) GetStationsByOwnerUserID(userID string) ([]ma
 
 func (r *PgStationOwnerRepository) GetByUserID(userID string) (*models.StationOwner, error) {
 	query := `
-		SELECT id, user_id, business_name, verification_status, contact_info,
-		       contact_name, contact_email, contact_phone, plan,
-		       created_at, verified_at
-		FROM station_owners
-		WHERE user_id = $1
-		LIMIT 1`
+		SELECT so.id, so.user_id, so.business_name, so.verification_status, so.contact_info,
+		       so.contact_name, so.contact_email, so.contact_phone, so.plan,
+		       so.created_at, so.verified_at, m.role
+		FROM station_owner_members m
+		INNER JOIN station_owners so ON so.id = m.station_owner_id
+		WHERE m.user_id = $1`
 
 	var owner models.StationOwner
 	var verifiedAt *time.Time
@@ -137,10 +195,11 @@ func (r *PgStationOwnerRepository) GetByUserID(userID string) (*models.StationOw
 		&owner.Plan,
 		&owner.CreatedAt,
 		&verifiedAt,
+		&owner.Role,
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("station owner not found")
+			return nil, ErrStationOwnerNotFound
 		}
 		return nil, fmt.Errorf("failed to query owner: %w", err)
 	}
@@ -151,23 +210,27 @@ func (r *PgStationOwnerRepository) GetByUserID(userID string) (*models.StationOw
 
 func (r *PgStationOwnerRepository) GetStationByID(userID, stationID string) (map[string]interface{}, error) {
 	query := `
-		SELECT s.id, s.name, s.brand, s.address, s.latitude, s.longitude, s.operating_hours, s.amenities, s.last_verified_at, cv.verification_status, cv.verified_at
+		SELECT s.id, s.name, s.brand, s.address, s.latitude, s.longitude, s.operating_hours, s.amenities, COALESCE(s.phone, ''), COALESCE(s.website, ''),
+			s.last_verified_at, cv.verification_status, cv.verified_at, cv.expires_at, COALESCE(cv.renewal_pending, false)
 		FROM stations s
 		INNER JOIN station_owners so ON so.id = s.owner_id
-		LEFT JOIN claim_verifications cv ON cv.station_id = s.id AND cv.station_owner_id = so.id
-		WHERE so.user_id = $1 AND s.id = $2`
+		` + ownerClaimJoin + `
+		WHERE s.id = $2 AND ` + stationAccessCondition("$1", "")
 
 	var (
 		id, name, brand, address, operatingHours string
+		phone, website                           string
 		verificationStatus                       *string
 		latitude, longitude                      float64
 		amenities                                interface{}
 		lastVerifiedAt                           sql.NullTime
-		verifiedAt                               *time.Time
+		verifiedAt, expiresAt                    *time.Time
+		renewalPending                           bool
 	)
 
 	err := r.db.QueryRow(query, userID, stationID).Scan(
-		&id, &name, &brand, &address, &latitude, &longitude, &operatingHours, &amenities, &lastVerifiedAt, &verificationStatus, &verifiedAt,
+		&id, &name, &brand, &address, &latitude, &longitude, &operatingHours, &amenities, &phone, &website,
+		&lastVerifiedAt, &verificationStatus, &verifiedAt, &expiresAt, &renewalPending,
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -193,17 +256,21 @@ func (r *PgStationOwnerRepository) GetStationByID(userID, stationID string) (map
 	}
 
 	station := map[string]interface{}{
-		"id":                 id,
-		"name":               name,
-		"brand":              brand,
-		"address":            address,
-		"latitude":           latitude,
-		"longitude":          longitude,
-		"operatingHours":     operatingHours,
-		"amenities":          parsedAmenities,
-		"lastVerifiedAt":     lastVerifiedAtValue,
-		"verificationStatus": status,
-		"verifiedAt":         verifiedAt,
+		"id":                    id,
+		"name":                  name,
+		"brand":                 brand,
+		"address":               address,
+		"latitude":              latitude,
+		"longitude":             longitude,
+		"operatingHours":        operatingHours,
+		"amenities":             parsedAmenities,
+		"phone":                 phone,
+		"website":               website,
+		"lastVerifiedAt":        lastVerifiedAtValue,
+		"verificationStatus":    status,
+		"verifiedAt":            verifiedAt,
+		"verificationExpiresAt": expiresAt,
+		"reVerificationPending": renewalPending,
 	}
 
 	return station, nil
@@ -218,7 +285,7 @@ func (r *PgStationOwnerRepository) GetStationWithPrices(userID, stationID string
 
 	// Then get fuel prices for this station
 	priceQuery := `
-		SELECT fp.fuel_type_id, ft.name, fp.price, fp.currency, fp.last_updated_at, fp.verification_status
+		SELECT fp.fuel_type_id, ft.name, fp.price, fp.currency, fp.last_updated_at, fp.verification_status, fp.source
 		FROM fuel_prices fp
 		INNER JOIN fuel_types ft ON ft.id = fp.fuel_type_id
 		WHERE fp.station_id = $1
@@ -233,12 +300,12 @@ func (r *PgStationOwnerRepository) GetStationWithPrices(userID, stationID string
 	var prices []map[string]interface{}
 	for rows.Next() {
 		var (
-			fuelTypeID, fuelTypeName, currency, verificationStatus string
-			price                                                  float64
-			lastUpdated                                            time.Time
+			fuelTypeID, fuelTypeName, currency, verificationStatus, source string
+			price                                                          float64
+			lastUpdated                                                    time.Time
 		)
 
-		if err := rows.Scan(&fuelTypeID, &fuelTypeName, &price, &currency, &lastUpdated, &verificationStatus); err != nil {
+		if err := rows.Scan(&fuelTypeID, &fuelTypeName, &price, &currency, &lastUpdated, &verificationStatus, &source); err != nil {
 			return nil, fmt.Errorf("failed to scan fuel price: %w", err)
 		}
 
@@ -249,6 +316,7 @@ func (r *PgStationOwnerRepository) GetStationWithPrices(userID, stationID string
 			"currency":           currency,
 			"lastUpdated":        lastUpdated,
 			"verificationStatus": verificationStatus,
+			"source":             source,
 		})
 	}
 
@@ -321,10 +389,10 @@ func (r *PgStationOwnerRepository) ClaimStation(userID, stationID, verificationM
 	}
 	defer tx.Rollback()
 
-	// 1. Get or create station_owner record for user
-	var ownerID string
-	ownerQuery := `SELECT id FROM station_owners WHERE user_id = $1 LIMIT 1`
-	err = tx.QueryRow(ownerQuery, userID).Scan(&ownerID)
+	// 1. Get the user's business, or set one up for them
+	var ownerID, role string
+	ownerQuery := `SELECT station_owner_id, role FROM station_owner_members WHERE user_id = $1`
+	err = tx.QueryRow(ownerQuery, userID).Scan(&ownerID, &role)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			// Create new station owner
@@ -337,9 +405,14 @@ func (r *PgStationOwnerRepository) ClaimStation(userID, stationID, verificationM
 			if err != nil {
 				return nil, fmt.Errorf("failed to create station owner: %w", err)
 			}
+			if err := addAccountHolder(tx, ownerID, userID); err != nil {
+				return nil, err
+			}
 		} else {
 			return nil, fmt.Errorf("failed to get station owner: %w", err)
 		}
+	} else if role == models.OwnerRoleStaff {
+		return nil, ErrStationPermissionDenied
 	}
 
 	// 2. Update station.owner_id and set verification_status to pending
@@ -361,20 +434,9 @@ func (r *PgStationOwnerRepository) ClaimStation(userID, stationID, verificationM
 	// 3. Create claim_verification record
 	verificationID := uuid.New().String()
 
-	// Convert document URLs to JSON array format if provided
-	var documentsJSON *string
-	if len(documentUrls) > 0 {
-		// Store as JSON array
-		docs := "["
-		for i, url := range documentUrls {
-			if i > 0 {
-				docs += ","
-			}
-			// Simple JSON encoding of URL string
-			docs += "\"" + url + "\""
-		}
-		docs += "]"
-		documentsJSON = &docs
+	documentsJSON, err := claimDocumentsJSON(documentUrls)
+	if err != nil {
+		return nil, err
 	}
 
 	createVerificationQuery := `
@@ -415,12 +477,11 @@ func (r *PgStationOwnerRepository) ClaimStation(userID, stationID, verificationM
 func (r *PgStationOwnerRepository) GetFuelPricesForOwner(userID string) (map[string]interface{}, error) {
 	// Get all fuel prices for all stations owned by this user
 	query := `
-		SELECT s.id, s.name, fp.fuel_type_id, ft.name, fp.price, fp.currency, fp.last_updated_at, fp.verification_status
+		SELECT s.id, s.name, fp.fuel_type_id, ft.name, fp.price, fp.currency, fp.last_updated_at, fp.verification_status, fp.source
 		FROM fuel_prices fp
 		INNER JOIN stations s ON s.id = fp.station_id
 		INNER JOIN fuel_types ft ON ft.id = fp.fuel_type_id
-		INNER JOIN station_owners so ON so.id = s.owner_id
-		WHERE so.user_id = $1
+		WHERE ` + stationAccessCondition("$1", "") + `
 		ORDER BY s.name ASC, ft.display_order ASC`
 
 	rows, err := r.db.Query(query, userID)
@@ -434,12 +495,12 @@ func (r *PgStationOwnerRepository) GetFuelPricesForOwner(userID string) (map[str
 
 	for rows.Next() {
 		var (
-			stationID, stationName, fuelTypeID, fuelTypeName, currency, verificationStatus string
-			price                                                                          float64
-			lastUpdated                                                                    time.Time
+			stationID, stationName, fuelTypeID, fuelTypeName, currency, verificationStatus, source string
+			price                                                                                  float64
+			lastUpdated                                                                            time.Time
 		)
 
-		if err := rows.Scan(&stationID, &stationName, &fuelTypeID, &fuelTypeName, &price, &currency, &lastUpdated, &verificationStatus); err != nil {
+		if err := rows.Scan(&stationID, &stationName, &fuelTypeID, &fuelTypeName, &price, &currency, &lastUpdated, &verificationStatus, &source); err != nil {
 			return nil, fmt.Errorf("failed to scan fuel price: %w", err)
 		}
 
@@ -450,6 +511,7 @@ func (r *PgStationOwnerRepository) GetFuelPricesForOwner(userID string) (map[str
 			"currency":           currency,
 			"lastUpdated":        lastUpdated,
 			"verificationStatus": verificationStatus,
+			"source":             source,
 		}
 
 		if _, exists := pricesByStation[stationID]; !exists {
@@ -467,14 +529,63 @@ func (r *PgStationOwnerRepository) GetFuelPricesForOwner(userID string) (map[str
 	}, nil
 }
 
-// UnclaimStation removes the owner claim from a station by setting owner_id to NULL
+func (r *PgStationOwnerRepository) PublishPrices(userID string, prices []OwnerPriceInput) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	approved := make(map[string]bool)
+	for _, p := range prices {
+		if !approved[p.StationID] {
+			var member, allowed, claimed bool
+			err := tx.QueryRow(`
+				SELECT `+stationAccessCondition("$1", "")+`, `+stationAccessCondition("$1", "$3")+`,
+					EXISTS(
+						SELECT 1 FROM claim_verifications cv
+						WHERE cv.station_id = s.id AND cv.station_owner_id = s.owner_id AND `+activeClaimCondition+`
+					)
+				FROM stations s
+				WHERE s.id::text = $2`, userID, p.StationID, models.StationPermissionPublishPrices).Scan(&member, &allowed, &claimed)
+			if err != nil && err != sql.ErrNoRows {
+				return fmt.Errorf("failed to check station claim: %w", err)
+			}
+			if member && !allowed {
+				return ErrStationPermissionDenied
+			}
+			if !allowed || !claimed {
+				return ErrStationClaimNotApproved
+			}
+			approved[p.StationID] = true
+		}
+
+		var fuelTypeExists bool
+		if err := tx.QueryRow("SELECT EXISTS(SELECT 1 FROM fuel_types WHERE id::text = $1)", p.FuelTypeID).Scan(&fuelTypeExists); err != nil {
+			return fmt.Errorf("failed to check fuel type: %w", err)
+		}
+		if !fuelTypeExists {
+			return ErrUnknownFuelType
+		}
+
+		if _, err := tx.Exec(upsertFuelPriceSQL, uuid.New().String(), p.StationID, p.FuelTypeID, p.Price, uuid.New().String(), PriceChangeSourceOwner, userID); err != nil {
+			return fmt.Errorf("failed to publish fuel price: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// UnclaimStation removes the owner claim from a station by setting owner_id
+// to NULL. Only the business's owners and managers can unclaim stations.
 func (r *PgStationOwnerRepository) UnclaimStation(userID, stationID string) error {
 	query := `
-		UPDATE stations
+		UPDATE stations s
 		SET owner_id = NULL
-		WHERE id = $1 AND owner_id IN (
-			SELECT so.id FROM station_owners so WHERE so.user_id = $2
-		)`
+		WHERE s.id = $1 AND ` + stationManagerCondition("$2")
 
 	result, err := r.db.Exec(query, stationID, userID)
 	if err != nil {
@@ -495,16 +606,22 @@ func (r *PgStationOwnerRepository) UnclaimStation(userID, stationID string) erro
 
 func (r *PgStationOwnerRepository) UpdateProfile(userID string, input UpdateOwnerProfileInput) (*models.StationOwner, error) {
 	query := `
-		UPDATE station_owners
+		WITH member AS (
+			SELECT station_owner_id, role FROM station_owner_members
+			WHERE user_id = $5 AND role IN ('owner', 'manager')
+		)
+		UPDATE station_owners so
 		SET business_name  = $1,
 		    contact_name   = $2,
 		    contact_email  = $3,
 		    contact_phone  = $4,
+		    updated_by     = $5,
 		    updated_at     = NOW()
-		WHERE user_id = $5
-		RETURNING id, user_id, business_name, verification_status, contact_info,
-		          contact_name, contact_email, contact_phone, plan,
-		          created_at, verified_at`
+		FROM member
+		WHERE so.id = member.station_owner_id
+		RETURNING so.id, so.user_id, so.business_name, so.verification_status, so.contact_info,
+		          so.contact_name, so.contact_email, so.contact_phone, so.plan,
+		          so.created_at, so.verified_at, member.role`
 
 	var owner models.StationOwner
 	var verifiedAt *time.Time
@@ -528,6 +645,7 @@ func (r *PgStationOwnerRepository) UpdateProfile(userID string, input UpdateOwne
 		&owner.Plan,
 		&owner.CreatedAt,
 		&verifiedAt,
+		&owner.Role,
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -540,4 +658,17 @@ func (r *PgStationOwnerRepository) UpdateProfile(userID string, input UpdateOwne
 	return &owner, nil
 }
 
+func (r *PgStationOwnerRepository) HasStationPermission(userID, stationID, permission string) (bool, error) {
+	var ok bool
+	err := r.db.QueryRow(`
+		SELECT EXISTS(
+			SELECT 1 FROM stations s
+			WHERE s.id::text = $2 AND `+stationAccessCondition("$1", "$3")+`
+		)`, userID, stationID, permission).Scan(&ok)
+	if err != nil {
+		return false, fmt.Errorf("failed to check station permission: %w", err)
+	}
+	return ok, nil
+}
+
 var _ StationOwnerRepository = (*PgStationOwnerRepository)(nil)
@@ -444,6 +444,65 @@ func TestGetFuelPricesForOwner_GroupsByStation(t *testing.T) {
 	assert.GreaterOrEqual(t, len(pricesByStation[s2.ID]), 1, "Station 2 should have 1 price")
 }
 
+// TestPublishPrices_ApprovedClaim tests that owners' prices are verified and marked with their source
+func TestPublishPrices_ApprovedClaim(t *testing.T) {
+	db := testhelpers.SetupTestDBWithCleanup(t)
+
+	user := testhelpers.CreateTestUser(t, db)
+	station := testhelpers.CreateTestStation(t, db, -33.8568, 151.2153)
+	fuelType := testhelpers.CreateTestFuelType(t, db, "E10")
+	testhelpers.CreateTestFuelPrice(t, db, station.ID, fuelType, 1.89)
+
+	repo := NewPgStationOwnerRepository(db)
+	_, err := repo.ClaimStation(user.ID, station.ID, "document", nil, "", "")
+	require.NoError(t, err)
+
+	prices := []OwnerPriceInput{{StationID: station.ID, FuelTypeID: fuelType, Price: 1.799}}
+	assert.ErrorIs(t, repo.PublishPrices(user.ID, prices), ErrStationClaimNotApproved)
+
+	_, err = db.Exec("UPDATE claim_verifications SET verification_status = 'approved', verified_at = NOW() WHERE station_id = $1", station.ID)
+	require.NoError(t, err)
+	require.NoError(t, repo.PublishPrices(user.ID, prices))
+
+	stationPrices, err := NewPgFuelPriceRepository(db).GetStationPrices(station.ID)
+	require.NoError(t, err)
+	require.Len(t, stationPrices, 1)
+	assert.Equal(t, 1.799, stationPrices[0].Price)
+	assert.Equal(t, "verified", stationPrices[0].VerificationStatus)
+	assert.Equal(t, PriceChangeSourceOwner, stationPrices[0].Source)
+
+	var events int
+	err = db.QueryRow("SELECT COUNT(*) FROM price_change_events WHERE station_id = $1 AND source = $2", station.ID, PriceChangeSourceOwner).Scan(&events)
+	require.NoError(t, err)
+	assert.Equal(t, 1, events)
+}
+
+// TestPublishPrices_OtherOwnersStation tests that nothing is published when one station is not the user's
+func TestPublishPrices_OtherOwnersStation(t *testing.T) {
+	db := testhelpers.SetupTestDBWithCleanup(t)
+
+	user := testhelpers.CreateTestUser(t, db)
+	station := testhelpers.CreateTestStation(t, db, -33.8568, 151.2153)
+	other := testhelpers.CreateTestStation(t, db, -33.8600, 151.2100)
+	fuelType := testhelpers.CreateTestFuelType(t, db, "E10")
+
+	repo := NewPgStationOwnerRepository(db)
+	_, err := repo.ClaimStation(user.ID, station.ID, "document", nil, "", "")
+	require.NoError(t, err)
+	_, err = db.Exec("UPDATE claim_verifications SET verification_status = 'approved' WHERE station_id = $1", station.ID)
+	require.NoError(t, err)
+
+	err = repo.PublishPrices(user.ID, []OwnerPriceInput{
+		{StationID: station.ID, FuelTypeID: fuelType, Price: 1.799},
+		{StationID: other.ID, FuelTypeID: fuelType, Price: 1.799},
+	})
+	assert.ErrorIs(t, err, ErrStationClaimNotApproved)
+
+	var count int
+	require.NoError(t, db.QueryRow("SELECT COUNT(*) FROM fuel_prices WHERE station_id = $1", station.ID).Scan(&count))
+	assert.Equal(t, 0, count)
+}
+
 // TestClaimStation_WithDocuments tests claiming with document URLs
 func TestClaimStation_WithDocuments(t *testing.T) {
 	db := testhelpers.SetupTestDBWithCleanup(t)
@@ -0,0 +1,403 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"gaspeep/backend/internal/models"
+	"github.com/google/uuid"
+)
+
+// PgStationProfileRepository is the PostgreSQL implementation of StationProfileRepository.
+type PgStationProfileRepository struct {
+	db *sql.DB
+}
+
+func NewPgStationProfileRepository(db *sql.DB) *PgStationProfileRepository {
+	return &PgStationProfileRepository{db: db}
+}
+
+const stationProfileColumns = `name, COALESCE(brand, ''), address, latitude, longitude, COALESCE(operating_hours, ''),
+	amenities, COALESCE(phone, ''), COALESCE(website, '')`
+
+func scanStationProfile(row rowScanner) (*models.StationProfile, error) {
+	var p models.StationProfile
+	var amenities interface{}
+	if err := row.Scan(&p.Name, &p.Brand, &p.Address, &p.Latitude, &p.Longitude, &p.OperatingHours, &amenities, &p.Phone, &p.Website); err != nil {
+		return nil, err
+	}
+	p.Amenities = parseAmenities(amenities)
+	return &p, nil
+}
+
+const stationVersionColumns = `id, station_id, version, profile, source, changed_by, change_request_id, created_at`
+
+func scanStationVersion(row rowScanner) (*models.StationProfileVersion, error) {
+	var v models.StationProfileVersion
+	var profile []byte
+	if err := row.Scan(&v.ID, &v.StationID, &v.Version, &profile, &v.Source, &v.ChangedBy, &v.ChangeRequestID, &v.CreatedAt); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(profile, &v.Profile); err != nil {
+		return nil, fmt.Errorf("failed to decode station profile: %w", err)
+	}
+	return &v, nil
+}
+
+const stationChangeRequestColumns = `r.id, r.station_id, s.name, r.requested_by, r.changes, r.status, r.reviewer_notes, r.reviewed_by, r.reviewed_at, r.created_at`
+
+func scanStationChangeRequest(row rowScanner) (*models.StationChangeRequest, error) {
+	var cr models.StationChangeRequest
+	var changes []byte
+	if err := row.Scan(&cr.ID, &cr.StationID, &cr.StationName, &cr.RequestedBy, &changes, &cr.Status, &cr.ReviewerNotes, &cr.ReviewedBy, &cr.ReviewedAt, &cr.CreatedAt); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(changes, &cr.Changes); err != nil {
+		return nil, fmt.Errorf("failed to decode station changes: %w", err)
+	}
+	return &cr, nil
+}
+
+func (r *PgStationProfileRepository) IsApprovedOwner(userID, stationID string) (bool, error) {
+	var ok bool
+	err := r.db.QueryRow(`
+		SELECT EXISTS(
+			SELECT 1
+			FROM stations s
+			INNER JOIN claim_verifications cv ON cv.station_id = s.id AND cv.station_owner_id = s.owner_id
+			WHERE s.id::text = $2 AND `+activeClaimCondition+` AND `+stationManagerCondition("$1")+`
+		)`, userID, stationID).Scan(&ok)
+	if err != nil {
+		return false, fmt.Errorf("failed to check station claim: %w", err)
+	}
+	return ok, nil
+}
+
+func (r *PgStationProfileRepository) GetProfile(stationID string) (*models.StationProfile, error) {
+	p, err := scanStationProfile(r.db.QueryRow(`SELECT `+stationProfileColumns+` FROM stations WHERE id = $1`, stationID))
+	if err == sql.ErrNoRows {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get station profile: %w", err)
+	}
+	return p, nil
+}
+
+func (r *PgStationProfileRepository) ApplyChanges(stationID string, changes models.StationProfileChanges, meta StationVersionMeta) (*models.StationProfileVersion, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	version, err := applyStationChanges(tx, stationID, changes, meta)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return version, nil
+}
+
+// applyStationChanges updates the station's row and records its new
+// version. The row stays locked until tx ends, so concurrent changes apply
+// one after the other.
+func applyStationChanges(tx *sql.Tx, stationID string, changes models.StationProfileChanges, meta StationVersionMeta) (*models.StationProfileVersion, error) {
+	current, err := scanStationProfile(tx.QueryRow(`SELECT `+stationProfileColumns+` FROM stations WHERE id = $1 FOR UPDATE`, stationID))
+	if err == sql.ErrNoRows {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get station profile: %w", err)
+	}
+
+	var latest int
+	if err := tx.QueryRow(`SELECT COALESCE(MAX(version), 0) FROM station_profile_versions WHERE station_id = $1`, stationID).Scan(&latest); err != nil {
+		return nil, fmt.Errorf("failed to get latest station version: %w", err)
+	}
+	if latest == 0 {
+		latest = 1
+		if _, err := insertStationVersion(tx, stationID, latest, *current, StationVersionMeta{Source: models.StationVersionSourceBaseline}); err != nil {
+			return nil, err
+		}
+	}
+
+	profile := mergeStationChanges(*current, changes)
+	amenities, err := json.Marshal(profile.Amenities)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode amenities: %w", err)
+	}
+	_, err = tx.Exec(`
+		UPDATE stations
+		SET name = $2, brand = $3, address = $4, latitude = $5, longitude = $6,
+			location = ST_SetSRID(ST_MakePoint($6, $5), 4326),
+			operating_hours = $7, amenities = $8::jsonb, phone = $9, website = $10, updated_at = NOW()
+		WHERE id = $1`,
+		stationID, profile.Name, nilIfEmpty(profile.Brand), profile.Address, profile.Latitude, profile.Longitude,
+		nilIfEmpty(profile.OperatingHours), string(amenities), nilIfEmpty(profile.Phone), nilIfEmpty(profile.Website),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update station: %w", err)
+	}
+
+	return insertStationVersion(tx, stationID, latest+1, profile, meta)
+}
+
+func mergeStationChanges(p models.StationProfile, c models.StationProfileChanges) models.StationProfile {
+	if c.Name != nil {
+		p.Name = *c.Name
+	}
+	if c.Brand != nil {
+		p.Brand = *c.Brand
+	}
+	if c.Address != nil {
+		p.Address = *c.Address
+	}
+	if c.Latitude != nil {
+		p.Latitude = *c.Latitude
+	}
+	if c.Longitude != nil {
+		p.Longitude = *c.Longitude
+	}
+	if c.OperatingHours != nil {
+		p.OperatingHours = *c.OperatingHours
+	}
+	if c.Amenities != nil {
+		p.Amenities = c.Amenities
+	}
+	if c.Phone != nil {
+		p.Phone = *c.Phone
+	}
+	if c.Website != nil {
+		p.Website = *c.Website
+	}
+	return p
+}
+
+func insertStationVersion(tx *sql.Tx, stationID string, version int, profile models.StationProfile, meta StationVersionMeta) (*models.StationProfileVersion, error) {
+	data, err := json.Marshal(profile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode station profile: %w", err)
+	}
+	v, err := scanStationVersion(tx.QueryRow(`
+		INSERT INTO station_profile_versions (id, station_id, version, profile, source, changed_by, change_request_id)
+		VALUES ($1, $2, $3, $4::jsonb, $5, $6, $7)
+		RETURNING `+stationVersionColumns,
+		uuid.New().String(), stationID, version, string(data), meta.Source, nilIfEmpty(meta.ChangedBy), nilIfEmpty(meta.ChangeRequestID),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("failed to record station version: %w", err)
+	}
+	return v, nil
+}
+
+func (r *PgStationProfileRepository) ListVersions(stationID string) ([]models.StationProfileVersion, error) {
+	rows, err := r.db.Query(`
+		SELECT `+stationVersionColumns+`
+		FROM station_profile_versions
+		WHERE station_id = $1
+		ORDER BY version DESC`,
+		stationID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list station versions: %w", err)
+	}
+	defer rows.Close()
+
+	versions := []models.StationProfileVersion{}
+	for rows.Next() {
+		v, err := scanStationVersion(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan station version: %w", err)
+		}
+		versions = append(versions, *v)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list station versions: %w", err)
+	}
+	return versions, nil
+}
+
+func (r *PgStationProfileRepository) GetVersion(stationID string, version int) (*models.StationProfileVersion, error) {
+	v, err := scanStationVersion(r.db.QueryRow(`
+		SELECT `+stationVersionColumns+`
+		FROM station_profile_versions
+		WHERE station_id = $1 AND version = $2`,
+		stationID, version,
+	))
+	if err == sql.ErrNoRows {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get station version: %w", err)
+	}
+	return v, nil
+}
+
+func (r *PgStationProfileRepository) CreateChangeRequest(stationID, requestedBy string, changes models.StationProfileChanges) (*models.StationChangeRequest, error) {
+	data, err := json.Marshal(changes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode station changes: %w", err)
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		UPDATE station_change_requests
+		SET status = $2, reviewed_at = NOW()
+		WHERE station_id = $1 AND status = $3`,
+		stationID, models.StationChangeSuperseded, models.StationChangePending,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to supersede station change requests: %w", err)
+	}
+
+	id := uuid.New().String()
+	_, err = tx.Exec(`
+		INSERT INTO station_change_requests (id, station_id, requested_by, changes, status)
+		VALUES ($1, $2, $3, $4::jsonb, $5)`,
+		id, stationID, nilIfEmpty(requestedBy), string(data), models.StationChangePending,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create station change request: %w", err)
+	}
+
+	cr, err := getStationChangeRequest(tx, id, false)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return cr, nil
+}
+
+func getStationChangeRequest(tx *sql.Tx, id string, forUpdate bool) (*models.StationChangeRequest, error) {
+	query := `
+		SELECT ` + stationChangeRequestColumns + `
+		FROM station_change_requests r
+		JOIN stations s ON s.id = r.station_id
+		WHERE r.id::text = $1`
+	if forUpdate {
+		query += ` FOR UPDATE OF r`
+	}
+	cr, err := scanStationChangeRequest(tx.QueryRow(query, id))
+	if err == sql.ErrNoRows {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get station change request: %w", err)
+	}
+	return cr, nil
+}
+
+func (r *PgStationProfileRepository) ListChangeRequests(filter StationChangeRequestFilter) ([]models.StationChangeRequest, error) {
+	rows, err := r.db.Query(`
+		SELECT `+stationChangeRequestColumns+`
+		FROM station_change_requests r
+		JOIN stations s ON s.id = r.station_id
+		WHERE ($1::text = '' OR r.station_id::text = $1) AND ($2::text = '' OR r.status = $2)
+		ORDER BY r.created_at, r.id`,
+		filter.StationID, filter.Status,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list station change requests: %w", err)
+	}
+	defer rows.Close()
+
+	requests := []models.StationChangeRequest{}
+	for rows.Next() {
+		cr, err := scanStationChangeRequest(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan station change request: %w", err)
+		}
+		requests = append(requests, *cr)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list station change requests: %w", err)
+	}
+	return requests, nil
+}
+
+func (r *PgStationProfileRepository) ApproveChangeRequest(id, reviewerID, notes string) (*models.StationProfileVersion, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	cr, err := reviewStationChangeRequest(tx, id, reviewerID, models.StationChangeApproved, notes)
+	if err != nil {
+		return nil, err
+	}
+	version, err := applyStationChanges(tx, cr.StationID, cr.Changes, StationVersionMeta{
+		Source:          models.StationVersionSourceReview,
+		ChangedBy:       stringValue(cr.RequestedBy),
+		ChangeRequestID: cr.ID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return version, nil
+}
+
+func (r *PgStationProfileRepository) RejectChangeRequest(id, reviewerID, notes string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := reviewStationChangeRequest(tx, id, reviewerID, models.StationChangeRejected, notes); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// reviewStationChangeRequest sets a pending request's status and reviewer and
+// returns it.
+func reviewStationChangeRequest(tx *sql.Tx, id, reviewerID, status, notes string) (*models.StationChangeRequest, error) {
+	cr, err := getStationChangeRequest(tx, id, true)
+	if err != nil {
+		return nil, err
+	}
+	if cr.Status != models.StationChangePending {
+		return nil, ErrChangeRequestNotPending
+	}
+
+	_, err = tx.Exec(`
+		UPDATE station_change_requests
+		SET status = $2, reviewer_notes = $3, reviewed_by = $4, reviewed_at = NOW()
+		WHERE id = $1`,
+		cr.ID, status, nilIfEmpty(notes), nilIfEmpty(reviewerID),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to review station change request: %w", err)
+	}
+	return cr, nil
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+var _ StationProfileRepository = (*PgStationProfileRepository)(nil)
@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"database/sql"
+	"testing"
+
+	"gaspeep/backend/internal/models"
+	"gaspeep/backend/internal/repository/testhelpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestStationProfile_ApplyChangesKeepsVersions tests that the first change records the original profile as version 1
+func TestStationProfile_ApplyChangesKeepsVersions(t *testing.T) {
+	db := testhelpers.SetupTestDBWithCleanup(t)
+
+	user := testhelpers.CreateTestUser(t, db)
+	station := testhelpers.CreateTestStation(t, db, -33.8568, 151.2153)
+	repo := NewPgStationProfileRepository(db)
+
+	original, err := repo.GetProfile(station.ID)
+	require.NoError(t, err)
+
+	name, phone := "Renamed Station", "02 9000 0000"
+	version, err := repo.ApplyChanges(station.ID, models.StationProfileChanges{Name: &name, Phone: &phone, Amenities: []string{"toilets"}},
+		StationVersionMeta{Source: models.StationVersionSourceOwner, ChangedBy: user.ID})
+	require.NoError(t, err)
+	assert.Equal(t, 2, version.Version)
+	assert.Equal(t, name, version.Profile.Name)
+	assert.Equal(t, original.Address, version.Profile.Address)
+	require.NotNil(t, version.ChangedBy)
+	assert.Equal(t, user.ID, *version.ChangedBy)
+
+	current, err := repo.GetProfile(station.ID)
+	require.NoError(t, err)
+	assert.Equal(t, version.Profile, *current)
+
+	versions, err := repo.ListVersions(station.ID)
+	require.NoError(t, err)
+	require.Len(t, versions, 2)
+	assert.Equal(t, models.StationVersionSourceBaseline, versions[1].Source)
+	assert.Equal(t, *original, versions[1].Profile)
+
+	_, err = repo.GetVersion(station.ID, 7)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+// TestStationProfile_ApproveChangeRequest tests that approving a change moves the station and records the version
+func TestStationProfile_ApproveChangeRequest(t *testing.T) {
+	db := testhelpers.SetupTestDBWithCleanup(t)
+
+	user := testhelpers.CreateTestUser(t, db)
+	station := testhelpers.CreateTestStation(t, db, -33.8568, 151.2153)
+	repo := NewPgStationProfileRepository(db)
+
+	brand := "Metro"
+	first, err := repo.CreateChangeRequest(station.ID, user.ID, models.StationProfileChanges{Brand: &brand})
+	require.NoError(t, err)
+	lat, lon := -33.8600, 151.2100
+	second, err := repo.CreateChangeRequest(station.ID, user.ID, models.StationProfileChanges{Brand: &brand, Latitude: &lat, Longitude: &lon})
+	require.NoError(t, err)
+	assert.Equal(t, station.Name, second.StationName)
+
+	// The newer request supersedes the first
+	pending, err := repo.ListChangeRequests(StationChangeRequestFilter{Status: models.StationChangePending})
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	assert.Equal(t, second.ID, pending[0].ID)
+	_, err = repo.ApproveChangeRequest(first.ID, user.ID, "")
+	assert.ErrorIs(t, err, ErrChangeRequestNotPending)
+
+	version, err := repo.ApproveChangeRequest(second.ID, user.ID, "Checked on the map")
+	require.NoError(t, err)
+	assert.Equal(t, models.StationVersionSourceReview, version.Source)
+	assert.Equal(t, "Metro", version.Profile.Brand)
+	assert.Equal(t, lat, version.Profile.Latitude)
+	require.NotNil(t, version.ChangeRequestID)
+	assert.Equal(t, second.ID, *version.ChangeRequestID)
+
+	var locationLat float64
+	require.NoError(t, db.QueryRow("SELECT ST_Y(location::geometry) FROM stations WHERE id = $1", station.ID).Scan(&locationLat))
+	assert.InDelta(t, lat, locationLat, 0.000001)
+
+	approved, err := repo.ListChangeRequests(StationChangeRequestFilter{Status: models.StationChangeApproved})
+	require.NoError(t, err)
+	require.Len(t, approved, 1)
+	require.NotNil(t, approved[0].ReviewedBy)
+	assert.Equal(t, user.ID, *approved[0].ReviewedBy)
+
+	assert.ErrorIs(t, repo.RejectChangeRequest(second.ID, user.ID, ""), ErrChangeRequestNotPending)
+	_, err = repo.ApproveChangeRequest("00000000-0000-0000-0000-000000000000", user.ID, "")
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+// TestStationProfile_IsApprovedOwner tests that only owners with an approved claim can edit
+func TestStationProfile_IsApprovedOwner(t *testing.T) {
+	db := testhelpers.SetupTestDBWithCleanup(t)
+
+	user := testhelpers.CreateTestUser(t, db)
+	station := testhelpers.CreateTestStation(t, db, -33.8568, 151.2153)
+	_, err := NewPgStationOwnerRepository(db).ClaimStation(user.ID, station.ID, "document", nil, "", "")
+	require.NoError(t, err)
+
+	repo := NewPgStationProfileRepository(db)
+	ok, err := repo.IsApprovedOwner(user.ID, station.ID)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	_, err = db.Exec("UPDATE claim_verifications SET verification_status = 'approved' WHERE station_id = $1", station.ID)
+	require.NoError(t, err)
+	ok, err = repo.IsApprovedOwner(user.ID, station.ID)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = repo.IsApprovedOwner(user.ID, "not-a-station")
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
@@ -155,7 +155,8 @@ func (r *PgStationRepository) GetStationByID(id string) (*models.Station, error)
 			ST_X(s.location::geometry) as longitude,
 			s.operating_hours, s.amenities, s.last_verified_at,
 			fp.fuel_type_id, ft.name as fuel_type_name, fp.price, fp.currency, fp.last_updated_at,
-			CASE WHEN fp.verification_status = 'verified' THEN true ELSE false END as verified
+			CASE WHEN fp.verification_status = 'verified' THEN true ELSE false END as verified,
+			fp.source
 		FROM stations s
 		LEFT JOIN fuel_prices fp ON s.id = fp.station_id
 		LEFT JOIN fuel_types ft ON fp.fuel_type_id = ft.id
@@ -181,12 +182,13 @@ func (r *PgStationRepository) GetStationByID(id string) (*models.Station, error)
 			price                                           sql.NullFloat64
 			lastUpdated                                     sql.NullTime
 			verified                                        sql.NullBool
+			source                                          sql.NullString
 		)
 
 		err := rows.Scan(
 			&stationID, &name, &brand, &address, &lat, &lon,
 			&operatingHours, &amenities, &lastVerified,
-			&fuelTypeID, &fuelTypeName, &price, &currency, &lastUpdated, &verified,
+			&fuelTypeID, &fuelTypeName, &price, &currency, &lastUpdated, &verified, &source,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan station row: %w", err)
@@ -218,6 +220,7 @@ func (r *PgStationRepository) GetStationByID(id string) (*models.Station, error)
 				Currency:     currency.String,
 				LastUpdated:  lastUpdated.Time,
 				Verified:     verified.Bool,
+				Source:       source.String,
 			})
 		}
 	}
@@ -317,7 +320,8 @@ func (r *PgStationRepository) GetStationsNearby(
 			ST_X(s.location::geometry) as longitude,
 			s.operating_hours, s.amenities, s.last_verified_at,
 			fp.fuel_type_id, ft.name as fuel_type_name, fp.price, fp.currency, fp.last_updated_at,
-			CASE WHEN fp.verification_status = 'verified' THEN true ELSE false END as verified
+			CASE WHEN fp.verification_status = 'verified' THEN true ELSE false END as verified,
+			fp.source
 		FROM stations s
 		LEFT JOIN fuel_prices fp ON s.id = fp.station_id
 		LEFT JOIN fuel_types ft ON fp.fuel_type_id = ft.id
@@ -383,12 +387,13 @@ func (r *PgStationRepository) GetStationsNearby(
 			price                                    sql.NullFloat64
 			lastUpdated                              sql.NullTime
 			verified                                 sql.NullBool
+			source                                   sql.NullString
 		)
 
 		err := rows.Scan(
 			&id, &name, &brand, &address, &lat, &lon,
 			&operatingHours, &amenities, &lastVerified,
-			&fuelTypeID, &fuelTypeName, &price, &currency, &lastUpdated, &verified,
+			&fuelTypeID, &fuelTypeName, &price, &currency, &lastUpdated, &verified, &source,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan station row: %w", err)
@@ -419,6 +424,7 @@ func (r *PgStationRepository) GetStationsNearby(
 				Price:        price.Float64,
 				Currency:     currency.String,
 				Verified:     verified.Bool,
+				Source:       source.String,
 			}
 			if lastUpdated.Valid {
 				priceData.LastUpdated = lastUpdated.Time
@@ -456,7 +462,8 @@ func (r *PgStationRepository) SearchStationsNearby(
 			ST_X(s.location::geometry) as longitude,
 			s.operating_hours, s.amenities, s.last_verified_at,
 			fp.fuel_type_id, ft.name as fuel_type_name, fp.price, fp.currency, fp.last_updated_at,
-			CASE WHEN fp.verification_status = 'verified' THEN true ELSE false END as verified
+			CASE WHEN fp.verification_status = 'verified' THEN true ELSE false END as verified,
+			fp.source
 		FROM stations s
 		LEFT JOIN fuel_prices fp ON s.id = fp.station_id AND fp.price > 0
 		LEFT JOIN fuel_types ft ON fp.fuel_type_id = ft.id
@@ -546,12 +553,13 @@ func (r *PgStationRepository) SearchStationsNearby(
 			price                                    sql.NullFloat64
 			lastUpdated                              sql.NullTime
 			verified                                 sql.NullBool
+			source                                   sql.NullString
 		)
 
 		err := rows.Scan(
 			&id, &name, &brand, &address, &lat, &lon,
 			&operatingHours, &amenities, &lastVerified,
-			&fuelTypeID, &fuelTypeName, &price, &currency, &lastUpdated, &verified,
+			&fuelTypeID, &fuelTypeName, &price, &currency, &lastUpdated, &verified, &source,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan station row: %w", err)
@@ -582,6 +590,7 @@ func (r *PgStationRepository) SearchStationsNearby(
 				Price:        price.Float64,
 				Currency:     currency.String,
 				Verified:     verified.Bool,
+				Source:       source.String,
 			}
 			if lastUpdated.Valid {
 				priceData.LastUpdated = lastUpdated.Time
@@ -611,7 +620,8 @@ func (r *PgStationRepository) SearchStations(searchQuery string, limit int) ([]m
 			ST_X(s.location::geometry) as longitude,
 			s.operating_hours, s.amenities, s.last_verified_at,
 			fp.fuel_type_id, ft.name as fuel_type_name, fp.price, fp.currency, fp.last_updated_at,
-			CASE WHEN fp.verification_status = 'verified' THEN true ELSE false END as verified
+			CASE WHEN fp.verification_status = 'verified' THEN true ELSE false END as verified,
+			fp.source
 		FROM stations s
 		LEFT JOIN fuel_prices fp ON s.id = fp.station_id
 		LEFT JOIN fuel_types ft ON fp.fuel_type_id = ft.id
@@ -640,12 +650,13 @@ func (r *PgStationRepository) SearchStations(searchQuery string, limit int) ([]m
 			price                                    sql.NullFloat64
 			lastUpdated                              sql.NullTime
 			verified                                 sql.NullBool
+			source                                   sql.NullString
 		)
 
 		err := rows.Scan(
 			&id, &name, &brand, &address, &lat, &lon,
 			&operatingHours, &amenities, &lastVerified,
-			&fuelTypeID, &fuelTypeName, &price, &currency, &lastUpdated, &verified,
+			&fuelTypeID, &fuelTypeName, &price, &currency, &lastUpdated, &verified, &source,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan station row: %w", err)
@@ -676,6 +687,7 @@ func (r *PgStationRepository) SearchStations(searchQuery string, limit int) ([]m
 				Price:        price.Float64,
 				Currency:     currency.String,
 				Verified:     verified.Bool,
+				Source:       source.String,
 			}
 			if lastUpdated.Valid {
 				priceData.LastUpdated = lastUpdated.Time
@@ -0,0 +1,219 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"gaspeep/backend/internal/models"
+	"github.com/google/uuid"
+)
+
+// PgSubscriptionRepository is the PostgreSQL implementation of SubscriptionRepository.
+type PgSubscriptionRepository struct {
+	db *sql.DB
+}
+
+func NewPgSubscriptionRepository(db *sql.DB) *PgSubscriptionRepository {
+	return &PgSubscriptionRepository{db: db}
+}
+
+const subscriptionColumns = `s.id, s.user_id, s.plan_id, p.tier, s.status, s.provider, s.provider_subscription_id,
+	s.current_period_start, s.current_period_end, s.trial_end, s.grace_until, s.canceled_at, s.created_at, s.updated_at`
+
+const subscriptionFrom = `subscriptions s JOIN subscription_plans p ON p.id = s.plan_id`
+
+func scanSubscription(row rowScanner, s *models.Subscription) error {
+	return row.Scan(&s.ID, &s.UserID, &s.PlanID, &s.Tier, &s.Status, &s.Provider, &s.ProviderSubscriptionID,
+		&s.CurrentPeriodStart, &s.CurrentPeriodEnd, &s.TrialEnd, &s.GraceUntil, &s.CanceledAt, &s.CreatedAt, &s.UpdatedAt)
+}
+
+const planColumns = `id, name, tier, billing_interval, price_cents, currency, trial_days`
+
+func scanPlan(row rowScanner, p *models.SubscriptionPlan) error {
+	return row.Scan(&p.ID, &p.Name, &p.Tier, &p.BillingInterval, &p.PriceCents, &p.Currency, &p.TrialDays)
+}
+
+func (r *PgSubscriptionRepository) ListPlans() ([]models.SubscriptionPlan, error) {
+	rows, err := r.db.Query(`SELECT ` + planColumns + ` FROM subscription_plans WHERE active ORDER BY price_cents, id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list subscription plans: %w", err)
+	}
+	defer rows.Close()
+
+	plans := []models.SubscriptionPlan{}
+	for rows.Next() {
+		var p models.SubscriptionPlan
+		if err := scanPlan(rows, &p); err != nil {
+			return nil, fmt.Errorf("failed to scan subscription plan: %w", err)
+		}
+		plans = append(plans, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list subscription plans: %w", err)
+	}
+	return plans, nil
+}
+
+func (r *PgSubscriptionRepository) GetPlan(planID string) (*models.SubscriptionPlan, error) {
+	var p models.SubscriptionPlan
+	err := scanPlan(r.db.QueryRow(`SELECT `+planColumns+` FROM subscription_plans WHERE id = $1 AND active`, planID), &p)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, sql.ErrNoRows
+		}
+		return nil, fmt.Errorf("failed to get subscription plan: %w", err)
+	}
+	return &p, nil
+}
+
+func (r *PgSubscriptionRepository) GetCurrent(userID string) (*models.Subscription, error) {
+	var s models.Subscription
+	err := scanSubscription(r.db.QueryRow(`
+		SELECT `+subscriptionColumns+`
+		FROM `+subscriptionFrom+`
+		WHERE s.user_id = $1
+		ORDER BY (s.status <> 'expired') DESC, s.created_at DESC
+		LIMIT 1`,
+		userID,
+	), &s)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, sql.ErrNoRows
+		}
+		return nil, fmt.Errorf("failed to get subscription: %w", err)
+	}
+	return &s, nil
+}
+
+func (r *PgSubscriptionRepository) GetByProviderID(provider, providerSubscriptionID string) (*models.Subscription, error) {
+	var s models.Subscription
+	err := scanSubscription(r.db.QueryRow(`
+		SELECT `+subscriptionColumns+`
+		FROM `+subscriptionFrom+`
+		WHERE s.provider = $1 AND s.provider_subscription_id = $2`,
+		provider, providerSubscriptionID,
+	), &s)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, sql.ErrNoRows
+		}
+		return nil, fmt.Errorf("failed to get subscription: %w", err)
+	}
+	return &s, nil
+}
+
+func (r *PgSubscriptionRepository) Save(sub *models.Subscription) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := saveSubscription(tx, sub); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit subscription: %w", err)
+	}
+	return nil
+}
+
+func (r *PgSubscriptionRepository) SaveFromWebhook(sub *models.Subscription, eventID, eventType string) (bool, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`
+		INSERT INTO payment_webhook_events (provider, event_id, event_type)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (provider, event_id) DO NOTHING`,
+		sub.Provider, eventID, eventType,
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to record webhook event: %w", err)
+	}
+	if n, err := result.RowsAffected(); err != nil || n == 0 {
+		return false, err
+	}
+
+	if err := saveSubscription(tx, sub); err != nil {
+		return false, err
+	}
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit subscription: %w", err)
+	}
+	return true, nil
+}
+
+func saveSubscription(tx *sql.Tx, sub *models.Subscription) error {
+	if sub.Status != models.SubscriptionExpired {
+		_, err := tx.Exec(`
+			UPDATE subscriptions
+			SET status = 'expired', updated_at = NOW()
+			WHERE user_id = $1 AND status <> 'expired'
+				AND NOT (provider = $2 AND provider_subscription_id = $3)`,
+			sub.UserID, sub.Provider, sub.ProviderSubscriptionID,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to expire previous subscriptions: %w", err)
+		}
+	}
+
+	err := tx.QueryRow(`
+		INSERT INTO subscriptions (
+			id, user_id, plan_id, status, provider, provider_subscription_id,
+			current_period_start, current_period_end, trial_end, grace_until, canceled_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		ON CONFLICT (provider, provider_subscription_id) DO UPDATE SET
+			plan_id = EXCLUDED.plan_id,
+			status = EXCLUDED.status,
+			current_period_start = EXCLUDED.current_period_start,
+			current_period_end = EXCLUDED.current_period_end,
+			trial_end = EXCLUDED.trial_end,
+			grace_until = EXCLUDED.grace_until,
+			canceled_at = EXCLUDED.canceled_at,
+			updated_at = NOW()
+		RETURNING id, created_at, updated_at`,
+		uuid.New().String(), sub.UserID, sub.PlanID, sub.Status, sub.Provider, sub.ProviderSubscriptionID,
+		sub.CurrentPeriodStart, sub.CurrentPeriodEnd, sub.TrialEnd, sub.GraceUntil, sub.CanceledAt,
+	).Scan(&sub.ID, &sub.CreatedAt, &sub.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save subscription: %w", err)
+	}
+	return nil
+}
+
+func (r *PgSubscriptionRepository) ExpireLapsed(now time.Time, grace time.Duration) ([]string, error) {
+	rows, err := r.db.Query(`
+		UPDATE subscriptions
+		SET status = 'expired', updated_at = NOW()
+		WHERE (status IN ('trialing', 'active') AND current_period_end + make_interval(secs => $2) < $1)
+			OR (status = 'past_due' AND COALESCE(grace_until, current_period_end) < $1)
+			OR (status = 'canceled' AND current_period_end < $1)
+		RETURNING user_id`,
+		now, grace.Seconds(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to expire subscriptions: %w", err)
+	}
+	defer rows.Close()
+
+	var userIDs []string
+	for rows.Next() {
+		var userID string
+		if err := rows.Scan(&userID); err != nil {
+			return nil, fmt.Errorf("failed to scan expired subscription: %w", err)
+		}
+		userIDs = append(userIDs, userID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to expire subscriptions: %w", err)
+	}
+	return userIDs, nil
+}
+
+var _ SubscriptionRepository = (*PgSubscriptionRepository)(nil)
@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"gaspeep/backend/internal/models"
+	"gaspeep/backend/internal/repository/testhelpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSubscription_SaveFromWebhookIsIdempotent(t *testing.T) {
+	db := testhelpers.SetupTestDBWithCleanup(t)
+
+	user := testhelpers.CreateTestUser(t, db)
+	repo := NewPgSubscriptionRepository(db)
+
+	plans, err := repo.ListPlans()
+	require.NoError(t, err)
+	require.NotEmpty(t, plans)
+
+	now := time.Now().UTC().Truncate(time.Second)
+	sub := &models.Subscription{
+		UserID:                 user.ID,
+		PlanID:                 "premium-monthly",
+		Status:                 models.SubscriptionActive,
+		Provider:               "fake",
+		ProviderSubscriptionID: "fake_sub_1",
+		CurrentPeriodStart:     now,
+		CurrentPeriodEnd:       now.AddDate(0, 1, 0),
+	}
+	applied, err := repo.SaveFromWebhook(sub, "evt-1", "subscription.started")
+	require.NoError(t, err)
+	assert.True(t, applied)
+	assert.NotEmpty(t, sub.ID)
+
+	sub.Status = models.SubscriptionCanceled
+	applied, err = repo.SaveFromWebhook(sub, "evt-1", "subscription.started")
+	require.NoError(t, err)
+	assert.False(t, applied)
+
+	got, err := repo.GetCurrent(user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.SubscriptionActive, got.Status)
+	assert.Equal(t, models.TierPremium, got.Tier)
+}
+
+func TestSubscription_NewSubscriptionReplacesOldAndLapsedExpire(t *testing.T) {
+	db := testhelpers.SetupTestDBWithCleanup(t)
+
+	user := testhelpers.CreateTestUser(t, db)
+	repo := NewPgSubscriptionRepository(db)
+	now := time.Now().UTC()
+
+	old := &models.Subscription{
+		UserID: user.ID, PlanID: "premium-monthly", Status: models.SubscriptionCanceled,
+		Provider: "fake", ProviderSubscriptionID: "fake_sub_old",
+		CurrentPeriodStart: now.AddDate(0, -1, 0), CurrentPeriodEnd: now.Add(time.Hour),
+	}
+	require.NoError(t, repo.Save(old))
+
+	renewed := &models.Subscription{
+		UserID: user.ID, PlanID: "premium-annual", Status: models.SubscriptionActive,
+		Provider: "fake", ProviderSubscriptionID: "fake_sub_new",
+		CurrentPeriodStart: now.AddDate(-1, 0, 0), CurrentPeriodEnd: now.Add(-8 * 24 * time.Hour),
+	}
+	require.NoError(t, repo.Save(renewed))
+
+	got, err := repo.GetByProviderID("fake", "fake_sub_old")
+	require.NoError(t, err)
+	assert.Equal(t, models.SubscriptionExpired, got.Status)
+
+	userIDs, err := repo.ExpireLapsed(now, 7*24*time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, []string{user.ID}, userIDs)
+
+	got, err = repo.GetCurrent(user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.SubscriptionExpired, got.Status)
+}
@@ -98,7 +98,7 @@ func (r *PgUserRepository) GetUserByProvider(provider, providerID string) (*mode
 
 func (r *PgUserRepository) UpdateUserOAuth(userID, provider, providerID, avatarURL string, emailVerified bool) error {
 	_, err := r.db.Exec(`
-		UPDATE users SET oauth_provider = $1, oauth_provider_id = $2, avatar_url = $3, email_verified = $4, updated_at = CURRENT_TIMESTAMP WHERE id = $5
+		UPDATE users SET oauth_provider = $1, oauth_provider_id = $2, avatar_url = $3, email_verified = email_verified OR $4, updated_at = CURRENT_TIMESTAMP WHERE id = $5
 	`, provider, providerID, avatarURL, emailVerified, userID)
 	return err
 }
@@ -122,8 +122,8 @@ func (r *PgUserRepository) GetUserByEmail(email string) (*models.User, error) {
 func (r *PgUserRepository) GetUserByID(id string) (*models.User, error) {
 	user := &models.User{}
 	err := r.db.QueryRow(`
-		SELECT id, email, display_name, tier, created_at, updated_at, COALESCE(oauth_provider, ''), COALESCE(oauth_provider_id, ''), COALESCE(avatar_url, ''), COALESCE(email_verified, false) FROM users WHERE id = $1
-	`, id).Scan(&user.ID, &user.Email, &user.DisplayName, &user.Tier, &user.CreatedAt, &user.UpdatedAt, &user.OAuthProvider, &user.OAuthProviderID, &user.AvatarURL, &user.EmailVerified)
+		SELECT id, email, display_name, tier, created_at, updated_at, COALESCE(oauth_provider, ''), COALESCE(oauth_provider_id, ''), COALESCE(avatar_url, ''), COALESCE(email_verified, false), time_zone, COALESCE(locale, '') FROM users WHERE id = $1
+	`, id).Scan(&user.ID, &user.Email, &user.DisplayName, &user.Tier, &user.CreatedAt, &user.UpdatedAt, &user.OAuthProvider, &user.OAuthProviderID, &user.AvatarURL, &user.EmailVerified, &user.TimeZone, &user.Locale)
 
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -177,11 +177,11 @@ func (r *PgUserRepository) GetUserIDByEmail(email string) (string, error) {
 	return userID, nil
 }
 
-func (r *PgUserRepository) UpdateProfile(userID, displayName, tier string) (string, error) {
+func (r *PgUserRepository) UpdateProfile(userID, displayName string) (string, error) {
 	var updatedID string
 	err := r.db.QueryRow(
-		`UPDATE users SET display_name = COALESCE($1, display_name), tier = COALESCE($2, tier), updated_at = NOW() WHERE id = $3 RETURNING id`,
-		displayName, tier, userID,
+		`UPDATE users SET display_name = COALESCE($1, display_name), updated_at = NOW() WHERE id = $2 RETURNING id`,
+		displayName, userID,
 	).Scan(&updatedID)
 	return updatedID, err
 }
@@ -234,4 +234,38 @@ func (r *PgUserRepository) UpdateMapFilterPreferences(userID string, prefs model
 	return nil
 }
 
+func (r *PgUserRepository) UpdateTimeZone(userID, timeZone string) error {
+	result, err := r.db.Exec(`UPDATE users SET time_zone = $1, updated_at = NOW() WHERE id = $2`, timeZone, userID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+func (r *PgUserRepository) UpdateLocale(userID, locale string) error {
+	result, err := r.db.Exec(`UPDATE users SET locale = NULLIF($1, ''), updated_at = NOW() WHERE id = $2`, locale, userID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
 var _ UserRepository = (*PgUserRepository)(nil)
@@ -187,8 +187,7 @@ func TestUpdateProfile_Success(t *testing.T) {
 
 	repo := NewPgUserRepository(db)
 	newDisplayName := "Updated Name"
-	newTier := "premium"
-	updatedID, err := repo.UpdateProfile(user.ID, newDisplayName, newTier)
+	updatedID, err := repo.UpdateProfile(user.ID, newDisplayName)
 
 	require.NoError(t, err)
 	assert.Equal(t, user.ID, updatedID)
@@ -197,7 +196,7 @@ func TestUpdateProfile_Success(t *testing.T) {
 	result, err := repo.GetUserByID(user.ID)
 	require.NoError(t, err)
 	assert.Equal(t, newDisplayName, result.DisplayName)
-	assert.Equal(t, newTier, result.Tier)
+	assert.Equal(t, user.Tier, result.Tier)
 }
 
 // TestCreateUserWithProvider_Success tests creating user with OAuth provider
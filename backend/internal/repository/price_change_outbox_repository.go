@@ -0,0 +1,42 @@
+package repository
+
+import "time"
+
+// Price sources, recorded on price change events and on fuel_prices.
+const (
+	PriceChangeSourceSubmission = "submission"
+	PriceChangeSourceServiceNSW = "service_nsw"
+	// PriceChangeSourceOwner marks prices published by the station's verified
+	// owner.
+	PriceChangeSourceOwner = "owner"
+)
+
+// PriceChangeEvent is an outbox entry recording a fuel price change that still
+// has to be evaluated against alerts.
+type PriceChangeEvent struct {
+	ID            string
+	StationID     string
+	FuelTypeID    string
+	Price         float64
+	PreviousPrice *float64
+	Source        string
+	Attempts      int
+	CreatedAt     time.Time
+}
+
+// PriceChangeOutboxRepository defines operations for claiming and settling
+// price change events. Events are completed by AlertRepository.RecordEvaluations
+// in the same transaction as the evaluation results.
+type PriceChangeOutboxRepository interface {
+	// Claim leases up to limit due events to the caller for the lease duration.
+	// Events whose lease expires without being settled become claimable again.
+	Claim(limit int, lease time.Duration) ([]PriceChangeEvent, error)
+	// Complete marks claimed events that matched no alerts as done.
+	Complete(ids []string) error
+	// Retry releases a claimed event to be attempted again after delay.
+	Retry(id, lastError string, delay time.Duration) error
+	// Fail marks a claimed event as permanently failed.
+	Fail(id, lastError string) error
+	// DeleteProcessedBefore removes completed events processed before cutoff.
+	DeleteProcessedBefore(cutoff time.Time) (int64, error)
+}
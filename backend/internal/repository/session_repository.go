@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"gaspeep/backend/internal/models"
+)
+
+// Reasons recorded when a session is revoked.
+const (
+	SessionRevokedLogout         = "logout"
+	SessionRevokedByUser         = "revoked"
+	SessionRevokedPasswordChange = "password_changed"
+	SessionRevokedPasswordReset  = "password_reset"
+	SessionRevokedTokenReuse     = "refresh_token_reused"
+)
+
+// ErrRefreshTokenReused is returned when a refresh token that has already been
+// exchanged is presented again.
+var ErrRefreshTokenReused = errors.New("refresh token reused")
+
+// CreateSessionInput describes a new session and its first refresh token.
+type CreateSessionInput struct {
+	UserID           string
+	RefreshTokenHash string
+	UserAgent        string
+	DeviceName       string
+	IPAddress        string
+	MFAVerified      bool
+	ExpiresAt        time.Time
+}
+
+// RotateSessionInput exchanges a session's refresh token for a new one and
+// records the client it was used from.
+type RotateSessionInput struct {
+	TokenHash    string
+	NewTokenHash string
+	UserAgent    string
+	IPAddress    string
+	ExpiresAt    time.Time
+}
+
+// SessionRepository defines data-access operations for user sessions.
+// Refresh tokens are looked up by their SHA-256 hash.
+type SessionRepository interface {
+	Create(input CreateSessionInput) (*models.Session, error)
+	// Rotate replaces an active session's current refresh token and extends
+	// the session. It returns sql.ErrNoRows when the token is unknown or its
+	// session has expired or been revoked. A token that was already rotated
+	// revokes its session and returns ErrRefreshTokenReused.
+	Rotate(input RotateSessionInput) (*models.Session, error)
+	// ListActive returns the user's unexpired, unrevoked sessions, most
+	// recently used first.
+	ListActive(userID string) ([]models.Session, error)
+	// Revoke ends one of the user's active sessions. It returns false when
+	// there is no such session.
+	Revoke(userID, sessionID, reason string) (bool, error)
+	// RevokeByRefreshToken ends the active session a current refresh token
+	// belongs to, if any.
+	RevokeByRefreshToken(tokenHash, reason string) error
+	// RevokeAll ends all of the user's active sessions apart from
+	// exceptSessionID, which may be empty, and returns how many were ended.
+	RevokeAll(userID, exceptSessionID, reason string) (int64, error)
+}
@@ -1,6 +1,25 @@
 package repository
 
-import "gaspeep/backend/internal/models"
+import (
+	"errors"
+
+	"gaspeep/backend/internal/models"
+)
+
+var (
+	// ErrStationOwnerNotFound is returned when a user has no station owner
+	// profile.
+	ErrStationOwnerNotFound = errors.New("station owner not found")
+	// ErrStationClaimNotApproved is returned when an owner publishes prices
+	// for a station they have not claimed, or whose claim is not approved.
+	ErrStationClaimNotApproved = errors.New("station claim not approved")
+	// ErrUnknownFuelType is returned when a published price names a fuel type
+	// that does not exist.
+	ErrUnknownFuelType = errors.New("unknown fuel type")
+	// ErrStationPermissionDenied is returned when a team member's role or
+	// station permissions do not allow what they tried to do.
+	ErrStationPermissionDenied = errors.New("station permission denied")
+)
 
 // CreateOwnerVerificationInput holds parameters for creating a station owner verification request.
 type CreateOwnerVerificationInput struct {
@@ -17,16 +36,43 @@ type UpdateOwnerProfileInput struct {
 	ContactPhone string
 }
 
+// OwnerPriceInput is a price an owner publishes for one of their stations.
+type OwnerPriceInput struct {
+	StationID  string  `json:"stationId"`
+	FuelTypeID string  `json:"fuelTypeId"`
+	Price      float64 `json:"price"`
+}
+
 // StationOwnerRepository defines data-access operations for station owners.
+// Users reach a business through their team membership: owners and managers
+// see all of its stations, staff only those they have permissions for.
 type StationOwnerRepository interface {
+	// CreateVerificationRequest sets up a business with the user as its
+	// account holder and owner. It returns ErrAlreadyTeamMember if the user
+	// already belongs to one.
 	CreateVerificationRequest(userID string, input CreateOwnerVerificationInput) (*models.StationOwner, error)
 	GetStationsByOwnerUserID(userID string) ([]map[string]interface{}, error)
+	// GetByUserID returns the business the user belongs to, with their role,
+	// or ErrStationOwnerNotFound.
 	GetByUserID(userID string) (*models.StationOwner, error)
 	UpdateProfile(userID string, input UpdateOwnerProfileInput) (*models.StationOwner, error)
 	GetStationByID(userID, stationID string) (map[string]interface{}, error)
 	GetStationWithPrices(userID, stationID string) (map[string]interface{}, error)
 	SearchAvailableStations(userID, query, lat, lon, radius string) ([]map[string]interface{}, error)
+	// ClaimStation claims a station for the user's business, setting one up
+	// if they have none. Staff cannot claim stations and get
+	// ErrStationPermissionDenied.
 	ClaimStation(userID, stationID, verificationMethod string, documentUrls []string, phoneNumber, email string) (map[string]interface{}, error)
 	UnclaimStation(userID, stationID string) error
 	GetFuelPricesForOwner(userID string) (map[string]interface{}, error)
+	// PublishPrices stores prices as verified with source owner, recording
+	// the user as the member who changed them, all or none. It returns
+	// ErrStationClaimNotApproved unless every station belongs to the user's
+	// business and its claim is approved and unexpired,
+	// ErrStationPermissionDenied for stations where the user may not publish
+	// prices, and ErrUnknownFuelType for unknown fuel types.
+	PublishPrices(userID string, prices []OwnerPriceInput) error
+	// HasStationPermission reports whether the user belongs to the business
+	// that owns the station and may use permission there.
+	HasStationPermission(userID, stationID, permission string) (bool, error)
 }
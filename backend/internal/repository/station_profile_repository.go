@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"errors"
+
+	"gaspeep/backend/internal/models"
+)
+
+// ErrChangeRequestNotPending is returned when reviewing a change request that
+// has already been reviewed or superseded.
+var ErrChangeRequestNotPending = errors.New("station change request is not pending")
+
+// StationVersionMeta records who made a change to a station's profile and
+// how.
+type StationVersionMeta struct {
+	Source          string
+	ChangedBy       string
+	ChangeRequestID string
+}
+
+// StationChangeRequestFilter selects change requests. Empty fields match any
+// request.
+type StationChangeRequestFilter struct {
+	StationID string
+	Status    string
+}
+
+// StationProfileRepository defines data-access operations for editing
+// stations' profiles, the versions kept of them and the queue of changes
+// waiting for review.
+type StationProfileRepository interface {
+	// IsApprovedOwner reports whether the user owns or manages the business
+	// whose claim on the station has been approved and has not expired.
+	IsApprovedOwner(userID, stationID string) (bool, error)
+	// GetProfile returns a station's current profile, or sql.ErrNoRows.
+	GetProfile(stationID string) (*models.StationProfile, error)
+	// ApplyChanges changes a station's profile and records the result as a
+	// new version. The profile before the station's first recorded change is
+	// kept as version 1. It returns sql.ErrNoRows if there is no such
+	// station.
+	ApplyChanges(stationID string, changes models.StationProfileChanges, meta StationVersionMeta) (*models.StationProfileVersion, error)
+	// ListVersions returns a station's versions, newest first.
+	ListVersions(stationID string) ([]models.StationProfileVersion, error)
+	// GetVersion returns one of a station's versions, or sql.ErrNoRows.
+	GetVersion(stationID string, version int) (*models.StationProfileVersion, error)
+	// CreateChangeRequest queues changes for review, superseding the
+	// station's pending request if it has one.
+	CreateChangeRequest(stationID, requestedBy string, changes models.StationProfileChanges) (*models.StationChangeRequest, error)
+	// ListChangeRequests returns matching change requests, oldest first.
+	ListChangeRequests(filter StationChangeRequestFilter) ([]models.StationChangeRequest, error)
+	// ApproveChangeRequest applies a pending request's changes on behalf of
+	// the reviewer and returns the new version. It returns sql.ErrNoRows or
+	// ErrChangeRequestNotPending.
+	ApproveChangeRequest(id, reviewerID, notes string) (*models.StationProfileVersion, error)
+	// RejectChangeRequest rejects a pending request on behalf of the
+	// reviewer. It returns sql.ErrNoRows or ErrChangeRequestNotPending.
+	RejectChangeRequest(id, reviewerID, notes string) error
+}
@@ -0,0 +1,36 @@
+package repository
+
+import (
+	"time"
+
+	"gaspeep/backend/internal/models"
+)
+
+// SubscriptionRepository defines data-access operations for plans and users'
+// subscriptions to them.
+type SubscriptionRepository interface {
+	// ListPlans returns the plans that can be subscribed to, cheapest first.
+	ListPlans() ([]models.SubscriptionPlan, error)
+	// GetPlan returns an active plan, or sql.ErrNoRows.
+	GetPlan(planID string) (*models.SubscriptionPlan, error)
+	// GetCurrent returns the user's subscription that has not expired, or
+	// their latest expired one. It returns sql.ErrNoRows if they have never
+	// subscribed.
+	GetCurrent(userID string) (*models.Subscription, error)
+	// GetByProviderID returns the subscription the payment provider knows by
+	// providerSubscriptionID, or sql.ErrNoRows.
+	GetByProviderID(provider, providerSubscriptionID string) (*models.Subscription, error)
+	// Save inserts or updates sub, matched by its provider and provider
+	// subscription ID, and fills in its ID and timestamps. Saving a
+	// subscription that has not expired expires the user's other ones.
+	Save(sub *models.Subscription) error
+	// SaveFromWebhook saves sub as Save does and records the webhook event
+	// that changed it, in one transaction. It returns false without saving if
+	// the event has already been recorded.
+	SaveFromWebhook(sub *models.Subscription, eventID, eventType string) (bool, error)
+	// ExpireLapsed expires subscriptions that have run out as of now: trials
+	// and active periods more than grace past their end, past due ones past
+	// their grace period, and canceled ones past their end. It returns the
+	// IDs of the affected users.
+	ExpireLapsed(now time.Time, grace time.Duration) ([]string, error)
+}
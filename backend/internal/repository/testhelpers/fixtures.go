@@ -209,6 +209,16 @@ func CreateTestStationOwner(t *testing.T, db *sql.DB, userID string) *models.Sta
 		t.Fatalf("Failed to create test station owner: %v", err)
 	}
 
+	// The account holder is the business's first owner
+	_, err = db.Exec(`
+		INSERT INTO station_owner_members (id, station_owner_id, user_id, role)
+		VALUES ($1, $2, $3, 'owner')
+	`, uuid.New().String(), id, userID)
+	if err != nil {
+		t.Fatalf("Failed to create test station owner member: %v", err)
+	}
+	owner.Role = models.OwnerRoleOwner
+
 	return owner
 }
 
@@ -11,13 +11,19 @@ type UserRepository interface {
 	GetUserByProvider(provider, providerID string) (*models.User, error)
 	GetUserByEmail(email string) (*models.User, error)
 	GetUserByID(id string) (*models.User, error)
-	// UpdateUserOAuth links an existing user with OAuth provider info
+	// UpdateUserOAuth links an existing user with OAuth provider info. A verified
+	// email stays verified even if the provider reports it unverified.
 	UpdateUserOAuth(userID, provider, providerID, avatarURL string, emailVerified bool) error
 	GetPasswordHash(email string) (string, error)
 	UpdateUserTier(userID, tier string) error
 	UpdatePassword(userID, passwordHash string) error
 	GetUserIDByEmail(email string) (string, error)
-	UpdateProfile(userID, displayName, tier string) (string, error)
+	UpdateProfile(userID, displayName string) (string, error)
 	GetMapFilterPreferences(userID string) (*models.MapFilterPreferences, error)
 	UpdateMapFilterPreferences(userID string, prefs models.MapFilterPreferences) error
+	// UpdateTimeZone sets the IANA time zone used to evaluate the user's alerts
+	UpdateTimeZone(userID, timeZone string) error
+	// UpdateLocale sets the locale used for the user's emails and API
+	// messages. An empty locale clears the preference.
+	UpdateLocale(userID, locale string) error
 }
@@ -0,0 +1,200 @@
+package service
+
+import (
+	"fmt"
+	"time"
+	// Embedded zone data so user time zones resolve on hosts without tzdata.
+	_ "time/tzdata"
+
+	"gaspeep/backend/internal/repository"
+)
+
+// alertConditionMet reports whether a price change at a station satisfies the
+// candidate alert's condition. Conditions that need history the station or area
+// does not have yet never fire.
+func alertConditionMet(c repository.AlertTriggerCandidate, price float64) bool {
+	switch c.ConditionType {
+	case repository.AlertConditionPercentBelowAverage:
+		average := alertAverage(c)
+		if average == nil || *average <= 0 {
+			return false
+		}
+		return price <= *average*(1-c.ConditionPercent/100)
+
+	case repository.AlertConditionNewCheapest:
+		if c.AreaCheapestOther == nil || price >= *c.AreaCheapestOther {
+			return false
+		}
+		// Only fire when the station becomes the cheapest, not on every change
+		// while it already was.
+		return c.PreviousPrice == nil || *c.PreviousPrice >= *c.AreaCheapestOther
+
+	case repository.AlertConditionCycleBottom:
+		if c.AreaTrailingMin == nil {
+			return false
+		}
+		return price <= *c.AreaTrailingMin*(1+c.ConditionPercent/100)
+
+	case repository.AlertConditionPriceRise:
+		if c.PreviousPrice == nil {
+			return false
+		}
+		return price > *c.PreviousPrice*(1+c.ConditionPercent/100)
+
+	default:
+		return price <= c.PriceThreshold
+	}
+}
+
+func alertAverage(c repository.AlertTriggerCandidate) *float64 {
+	if c.AverageScope == repository.AverageScopeStation {
+		return c.StationAverage
+	}
+	return c.AreaAverage
+}
+
+// describeAlertCondition explains what the price was compared against, for the
+// evaluation detail shown to users.
+func describeAlertCondition(c repository.AlertTriggerCandidate, price float64) string {
+	switch c.ConditionType {
+	case repository.AlertConditionPercentBelowAverage:
+		average := alertAverage(c)
+		if average == nil {
+			return fmt.Sprintf("price %.3f, no %s history in the last %d days", price, c.AverageScope, c.LookbackDays)
+		}
+		return fmt.Sprintf("price %.3f vs %s average %.3f less %.1f%%", price, c.AverageScope, *average, c.ConditionPercent)
+	case repository.AlertConditionNewCheapest:
+		if c.AreaCheapestOther == nil {
+			return fmt.Sprintf("price %.3f, no other stations to compare", price)
+		}
+		return fmt.Sprintf("price %.3f vs cheapest other station %.3f", price, *c.AreaCheapestOther)
+	case repository.AlertConditionCycleBottom:
+		if c.AreaTrailingMin == nil {
+			return fmt.Sprintf("price %.3f, no history in the last %d days", price, c.LookbackDays)
+		}
+		return fmt.Sprintf("price %.3f vs %d-day low %.3f plus %.1f%%", price, c.LookbackDays, *c.AreaTrailingMin, c.ConditionPercent)
+	case repository.AlertConditionPriceRise:
+		if c.PreviousPrice == nil {
+			return fmt.Sprintf("price %.3f, no previous price", price)
+		}
+		return fmt.Sprintf("price %.3f vs previous %.3f plus %.1f%%", price, *c.PreviousPrice, c.ConditionPercent)
+	default:
+		return fmt.Sprintf("price %.3f vs threshold %.3f", price, c.PriceThreshold)
+	}
+}
+
+// evaluateAlert decides whether a candidate alert fires for a price change at a
+// station and records why. The condition is checked before cooldowns so the
+// reason says "cooldown" only when the alert would otherwise have fired.
+func evaluateAlert(c repository.AlertTriggerCandidate, stationID string, price float64) repository.AlertEvaluation {
+	met := alertConditionMet(c, price)
+	eval := repository.AlertEvaluation{
+		AlertID:              c.AlertID,
+		ExpectedTriggerCount: c.TriggerCount,
+		Detail:               describeAlertCondition(c, price),
+	}
+
+	switch {
+	case c.MaxTriggers != nil && c.TriggerCount >= *c.MaxTriggers:
+		eval.Reason = repository.AlertReasonMaxTriggers
+	case c.RecurrenceType == "one_off" && c.LastTriggeredAt != nil:
+		eval.Reason = repository.AlertReasonOneOffFired
+	case !c.Armed:
+		// Re-arm once the station that fired the alert no longer meets the condition.
+		if !met && (c.LastTriggeredStationID == nil || *c.LastTriggeredStationID == stationID) {
+			eval.Rearm = true
+			eval.Reason = repository.AlertReasonRearmed
+		} else {
+			eval.Reason = repository.AlertReasonAwaitingRearm
+		}
+	case !met:
+		eval.Reason = repository.AlertReasonConditionNotMet
+	case c.LastTriggeredAt != nil && c.CooldownMinutes != nil &&
+		c.EvaluatedAt.Before(c.LastTriggeredAt.Add(time.Duration(*c.CooldownMinutes)*time.Minute)):
+		eval.Reason = repository.AlertReasonCooldown
+		eval.Detail += fmt.Sprintf(", cooldown until %s",
+			c.LastTriggeredAt.Add(time.Duration(*c.CooldownMinutes)*time.Minute).In(userLocation(c.TimeZone)).Format(time.RFC3339))
+	case c.LastTriggeredAt != nil && c.CooldownMinutes == nil && sameLocalDay(*c.LastTriggeredAt, c.EvaluatedAt, c.TimeZone):
+		eval.Reason = repository.AlertReasonFiredToday
+	default:
+		eval.Triggered = true
+		eval.Reason = repository.AlertReasonTriggered
+	}
+
+	return eval
+}
+
+// userLocation resolves a user's IANA time zone, falling back to UTC for
+// unknown names.
+func userLocation(timeZone string) *time.Location {
+	loc, err := time.LoadLocation(timeZone)
+	if err != nil || timeZone == "" {
+		return time.UTC
+	}
+	return loc
+}
+
+func sameLocalDay(a, b time.Time, timeZone string) bool {
+	loc := userLocation(timeZone)
+	ay, am, ad := a.In(loc).Date()
+	by, bm, bd := b.In(loc).Date()
+	return ay == by && am == bm && ad == bd
+}
+
+// alertRetriggerState is the part of a candidate that changes when an
+// evaluation is recorded.
+type alertRetriggerState struct {
+	triggerCount           int
+	lastTriggeredAt        *time.Time
+	lastTriggeredStationID *string
+	armed                  bool
+}
+
+// evaluatePriceChange evaluates the candidate alerts matched to a price change
+// event, records the outcome on each alert, completes the event and returns the
+// alerts that fired. batchState carries recorded outcomes over to later events
+// in the same batch so they see the alert as it is now, not as it was fetched.
+func evaluatePriceChange(alertRepo repository.AlertRepository, event repository.PriceChangeEvent, candidates []repository.AlertTriggerCandidate, batchState map[string]alertRetriggerState) ([]repository.TriggeredAlertResult, error) {
+	evaluations := make([]repository.AlertEvaluation, 0, len(candidates))
+	for i := range candidates {
+		c := &candidates[i]
+		// The stored price may have moved on since the event was queued; compare
+		// against the change the event describes.
+		c.PreviousPrice = event.PreviousPrice
+		if state, ok := batchState[c.AlertID]; ok {
+			c.TriggerCount = state.triggerCount
+			c.LastTriggeredAt = state.lastTriggeredAt
+			c.LastTriggeredStationID = state.lastTriggeredStationID
+			c.Armed = state.armed
+		}
+		evaluations = append(evaluations, evaluateAlert(*c, event.StationID, event.Price))
+	}
+
+	triggered, err := alertRepo.RecordEvaluations(event.ID, event.StationID, event.Price, evaluations)
+	if err != nil {
+		return nil, err
+	}
+
+	fired := make(map[string]bool, len(triggered))
+	for _, t := range triggered {
+		fired[t.AlertID] = true
+	}
+	for i, c := range candidates {
+		state := alertRetriggerState{
+			triggerCount:           c.TriggerCount,
+			lastTriggeredAt:        c.LastTriggeredAt,
+			lastTriggeredStationID: c.LastTriggeredStationID,
+			armed:                  c.Armed || evaluations[i].Rearm,
+		}
+		if fired[c.AlertID] {
+			stationID, at := event.StationID, c.EvaluatedAt
+			state.triggerCount++
+			state.lastTriggeredAt = &at
+			state.lastTriggeredStationID = &stationID
+			state.armed = c.RearmRule != repository.AlertRearmPriceRecovers
+		}
+		batchState[c.AlertID] = state
+	}
+
+	return triggered, nil
+}
@@ -0,0 +1,259 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"gaspeep/backend/internal/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+func floatPtr(v float64) *float64 {
+	return &v
+}
+
+func TestAlertConditionMet(t *testing.T) {
+	tests := []struct {
+		name      string
+		candidate repository.AlertTriggerCandidate
+		price     float64
+		want      bool
+	}{
+		{
+			name:      "threshold met",
+			candidate: repository.AlertTriggerCandidate{ConditionType: repository.AlertConditionPriceThreshold, PriceThreshold: 1.80},
+			price:     1.79,
+			want:      true,
+		},
+		{
+			name:      "threshold not met",
+			candidate: repository.AlertTriggerCandidate{ConditionType: repository.AlertConditionPriceThreshold, PriceThreshold: 1.80},
+			price:     1.81,
+			want:      false,
+		},
+		{
+			name: "percent below area average",
+			candidate: repository.AlertTriggerCandidate{
+				ConditionType:    repository.AlertConditionPercentBelowAverage,
+				ConditionPercent: 5,
+				AverageScope:     repository.AverageScopeArea,
+				AreaAverage:      floatPtr(2.00),
+			},
+			price: 1.89,
+			want:  true,
+		},
+		{
+			name: "percent below station average not reached",
+			candidate: repository.AlertTriggerCandidate{
+				ConditionType:    repository.AlertConditionPercentBelowAverage,
+				ConditionPercent: 5,
+				AverageScope:     repository.AverageScopeStation,
+				StationAverage:   floatPtr(2.00),
+				AreaAverage:      floatPtr(3.00),
+			},
+			price: 1.91,
+			want:  false,
+		},
+		{
+			name: "percent below average without history",
+			candidate: repository.AlertTriggerCandidate{
+				ConditionType:    repository.AlertConditionPercentBelowAverage,
+				ConditionPercent: 5,
+				AverageScope:     repository.AverageScopeArea,
+			},
+			price: 1.00,
+			want:  false,
+		},
+		{
+			name: "becomes new cheapest",
+			candidate: repository.AlertTriggerCandidate{
+				ConditionType:     repository.AlertConditionNewCheapest,
+				AreaCheapestOther: floatPtr(1.85),
+				PreviousPrice:     floatPtr(1.90),
+			},
+			price: 1.84,
+			want:  true,
+		},
+		{
+			name: "already cheapest",
+			candidate: repository.AlertTriggerCandidate{
+				ConditionType:     repository.AlertConditionNewCheapest,
+				AreaCheapestOther: floatPtr(1.85),
+				PreviousPrice:     floatPtr(1.80),
+			},
+			price: 1.78,
+			want:  false,
+		},
+		{
+			name: "cycle bottom within tolerance",
+			candidate: repository.AlertTriggerCandidate{
+				ConditionType:    repository.AlertConditionCycleBottom,
+				ConditionPercent: 1,
+				AreaTrailingMin:  floatPtr(1.70),
+			},
+			price: 1.71,
+			want:  true,
+		},
+		{
+			name: "cycle bottom above tolerance",
+			candidate: repository.AlertTriggerCandidate{
+				ConditionType:   repository.AlertConditionCycleBottom,
+				AreaTrailingMin: floatPtr(1.70),
+			},
+			price: 1.71,
+			want:  false,
+		},
+		{
+			name: "price rise above minimum percent",
+			candidate: repository.AlertTriggerCandidate{
+				ConditionType:    repository.AlertConditionPriceRise,
+				ConditionPercent: 2,
+				PreviousPrice:    floatPtr(1.80),
+			},
+			price: 1.90,
+			want:  true,
+		},
+		{
+			name: "price rise without previous price",
+			candidate: repository.AlertTriggerCandidate{
+				ConditionType: repository.AlertConditionPriceRise,
+			},
+			price: 1.90,
+			want:  false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, alertConditionMet(tt.candidate, tt.price))
+		})
+	}
+}
+
+func intPtr(v int) *int {
+	return &v
+}
+
+func TestEvaluateAlert(t *testing.T) {
+	// 23:30 UTC on 1 March is 10:30 on 2 March in Sydney
+	lastTriggered := time.Date(2026, 3, 1, 23, 30, 0, 0, time.UTC)
+	base := repository.AlertTriggerCandidate{
+		AlertID:        "alert-1",
+		ConditionType:  repository.AlertConditionPriceThreshold,
+		PriceThreshold: 1.80,
+		RecurrenceType: "recurring",
+		TriggerCount:   3,
+		Armed:          true,
+		TimeZone:       "Australia/Sydney",
+		EvaluatedAt:    lastTriggered.Add(2 * time.Hour),
+	}
+	stationID := "station-1"
+	otherStationID := "station-2"
+
+	tests := []struct {
+		name       string
+		modify     func(c *repository.AlertTriggerCandidate)
+		price      float64
+		wantReason string
+		wantFired  bool
+		wantRearm  bool
+	}{
+		{
+			name:       "fires when never triggered",
+			modify:     func(c *repository.AlertTriggerCandidate) {},
+			price:      1.75,
+			wantReason: repository.AlertReasonTriggered,
+			wantFired:  true,
+		},
+		{
+			name:       "condition not met",
+			modify:     func(c *repository.AlertTriggerCandidate) { c.LastTriggeredAt = &lastTriggered },
+			price:      1.85,
+			wantReason: repository.AlertReasonConditionNotMet,
+		},
+		{
+			name:       "already fired on the same local day",
+			modify:     func(c *repository.AlertTriggerCandidate) { c.LastTriggeredAt = &lastTriggered },
+			price:      1.75,
+			wantReason: repository.AlertReasonFiredToday,
+		},
+		{
+			name: "new local day in the user's zone",
+			modify: func(c *repository.AlertTriggerCandidate) {
+				c.LastTriggeredAt = &lastTriggered
+				c.EvaluatedAt = time.Date(2026, 3, 2, 14, 0, 0, 0, time.UTC)
+			},
+			price:      1.75,
+			wantReason: repository.AlertReasonTriggered,
+			wantFired:  true,
+		},
+		{
+			name: "inside cooldown",
+			modify: func(c *repository.AlertTriggerCandidate) {
+				c.LastTriggeredAt = &lastTriggered
+				c.CooldownMinutes = intPtr(180)
+			},
+			price:      1.75,
+			wantReason: repository.AlertReasonCooldown,
+		},
+		{
+			name: "cooldown elapsed",
+			modify: func(c *repository.AlertTriggerCandidate) {
+				c.LastTriggeredAt = &lastTriggered
+				c.CooldownMinutes = intPtr(60)
+			},
+			price:      1.75,
+			wantReason: repository.AlertReasonTriggered,
+			wantFired:  true,
+		},
+		{
+			name:       "max triggers reached",
+			modify:     func(c *repository.AlertTriggerCandidate) { c.MaxTriggers = intPtr(3) },
+			price:      1.75,
+			wantReason: repository.AlertReasonMaxTriggers,
+		},
+		{
+			name: "awaiting re-arm while price stays low",
+			modify: func(c *repository.AlertTriggerCandidate) {
+				c.Armed = false
+				c.LastTriggeredStationID = &stationID
+			},
+			price:      1.75,
+			wantReason: repository.AlertReasonAwaitingRearm,
+		},
+		{
+			name: "re-arms once price recovers at the triggering station",
+			modify: func(c *repository.AlertTriggerCandidate) {
+				c.Armed = false
+				c.LastTriggeredStationID = &stationID
+			},
+			price:      1.85,
+			wantReason: repository.AlertReasonRearmed,
+			wantRearm:  true,
+		},
+		{
+			name: "recovery at another station does not re-arm",
+			modify: func(c *repository.AlertTriggerCandidate) {
+				c.Armed = false
+				c.LastTriggeredStationID = &otherStationID
+			},
+			price:      1.85,
+			wantReason: repository.AlertReasonAwaitingRearm,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			candidate := base
+			tt.modify(&candidate)
+
+			eval := evaluateAlert(candidate, stationID, tt.price)
+
+			assert.Equal(t, tt.wantReason, eval.Reason)
+			assert.Equal(t, tt.wantFired, eval.Triggered)
+			assert.Equal(t, tt.wantRearm, eval.Rearm)
+			assert.Equal(t, 3, eval.ExpectedTriggerCount)
+			assert.NotEmpty(t, eval.Detail)
+		})
+	}
+}
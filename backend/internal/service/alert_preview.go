@@ -0,0 +1,347 @@
+package service
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"gaspeep/backend/internal/repository"
+)
+
+const (
+	defaultAlertPreviewDays = 30
+	maxAlertPreviewTriggers = 100
+)
+
+// AlertPreviewRequest describes a draft alert to back-test against stored
+// price history over the last Days days.
+type AlertPreviewRequest struct {
+	Alert                 repository.CreateAlertInput
+	Days                  int
+	TargetTriggersPerWeek float64
+}
+
+// AlertPreviewTrigger is a time the draft alert would have fired.
+type AlertPreviewTrigger struct {
+	StationID   string    `json:"stationId"`
+	StationName string    `json:"stationName"`
+	Price       float64   `json:"price"`
+	TriggeredAt time.Time `json:"triggeredAt"`
+}
+
+// AlertPreviewStation counts the times the draft alert would have fired at a station.
+type AlertPreviewStation struct {
+	StationID    string `json:"stationId"`
+	StationName  string `json:"stationName"`
+	TriggerCount int    `json:"triggerCount"`
+}
+
+// AlertThresholdSuggestion is the price threshold that would have fired closest
+// to the requested number of times a week.
+type AlertThresholdSuggestion struct {
+	TargetTriggersPerWeek float64 `json:"targetTriggersPerWeek"`
+	PriceThreshold        float64 `json:"priceThreshold"`
+	TriggerCount          int     `json:"triggerCount"`
+	TriggersPerWeek       float64 `json:"triggersPerWeek"`
+}
+
+// AlertPreviewResult summarises how a draft alert would have behaved. Triggers
+// lists the most recent firings, newest first.
+type AlertPreviewResult struct {
+	Days            int                       `json:"days"`
+	PriceReports    int                       `json:"priceReports"`
+	TriggerCount    int                       `json:"triggerCount"`
+	TriggersPerWeek float64                   `json:"triggersPerWeek"`
+	Triggers        []AlertPreviewTrigger     `json:"triggers"`
+	Stations        []AlertPreviewStation     `json:"stations"`
+	Suggestion      *AlertThresholdSuggestion `json:"suggestion,omitempty"`
+}
+
+func (s *alertService) PreviewAlert(userID string, req AlertPreviewRequest) (*AlertPreviewResult, error) {
+	if err := req.Alert.Validate(); err != nil {
+		return nil, err
+	}
+
+	days := req.Days
+	if days <= 0 {
+		days = defaultAlertPreviewDays
+	}
+	lookbackDays := req.Alert.LookbackDays
+	if lookbackDays == 0 {
+		lookbackDays = repository.DefaultLookbackDays(req.Alert.ConditionType)
+	}
+	targetMode := req.Alert.TargetMode
+	if targetMode == "" {
+		targetMode = repository.AlertTargetRadius
+	}
+
+	// History starts one lookback window early so averages are warm when the
+	// back-test period begins.
+	from := time.Now().AddDate(0, 0, -days)
+	history, err := s.alertRepo.GetPriceHistory(repository.PriceHistoryInput{
+		UserID:     userID,
+		FuelTypeID: req.Alert.FuelTypeID,
+		TargetMode: targetMode,
+		StationIDs: req.Alert.StationIDs,
+		Latitude:   req.Alert.Latitude,
+		Longitude:  req.Alert.Longitude,
+		RadiusKm:   req.Alert.RadiusKm,
+		Since:      from.AddDate(0, 0, -lookbackDays),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	weeks := float64(days) / 7
+	triggers := replayAlert(req.Alert, history.TimeZone, history.Points, from)
+
+	result := &AlertPreviewResult{
+		Days:            days,
+		TriggerCount:    len(triggers),
+		TriggersPerWeek: roundRate(float64(len(triggers)) / weeks),
+		Triggers:        make([]AlertPreviewTrigger, 0, maxAlertPreviewTriggers),
+		Stations:        make([]AlertPreviewStation, 0),
+	}
+	for _, p := range history.Points {
+		if !p.ReportedAt.Before(from) {
+			result.PriceReports++
+		}
+	}
+	for i := len(triggers) - 1; i >= 0 && len(result.Triggers) < maxAlertPreviewTriggers; i-- {
+		result.Triggers = append(result.Triggers, triggers[i])
+	}
+
+	stationIndex := make(map[string]int)
+	for _, t := range triggers {
+		i, ok := stationIndex[t.StationID]
+		if !ok {
+			i = len(result.Stations)
+			stationIndex[t.StationID] = i
+			result.Stations = append(result.Stations, AlertPreviewStation{StationID: t.StationID, StationName: t.StationName})
+		}
+		result.Stations[i].TriggerCount++
+	}
+	sort.SliceStable(result.Stations, func(i, j int) bool {
+		return result.Stations[i].TriggerCount > result.Stations[j].TriggerCount
+	})
+
+	conditionType := req.Alert.ConditionType
+	if req.TargetTriggersPerWeek > 0 && (conditionType == "" || conditionType == repository.AlertConditionPriceThreshold) {
+		result.Suggestion = suggestAlertThreshold(req.Alert, history.TimeZone, history.Points, from, weeks, req.TargetTriggersPerWeek)
+	}
+
+	return result, nil
+}
+
+// replayAlert runs a draft alert through price reports in the order they were
+// made, using the same evaluation and re-trigger rules as live alerts. Reports
+// before from only build up history.
+func replayAlert(input repository.CreateAlertInput, timeZone string, points []repository.PriceHistoryPoint, from time.Time) []AlertPreviewTrigger {
+	c := repository.AlertTriggerCandidate{
+		ConditionType:    input.ConditionType,
+		PriceThreshold:   input.PriceThreshold,
+		ConditionPercent: input.ConditionPercent,
+		AverageScope:     input.AverageScope,
+		LookbackDays:     input.LookbackDays,
+		RecurrenceType:   input.RecurrenceType,
+		CooldownMinutes:  input.CooldownMinutes,
+		RearmRule:        input.RearmRule,
+		MaxTriggers:      input.MaxTriggers,
+		Armed:            true,
+		TimeZone:         timeZone,
+	}
+	if c.ConditionType == "" {
+		c.ConditionType = repository.AlertConditionPriceThreshold
+	}
+	if c.LookbackDays == 0 {
+		c.LookbackDays = repository.DefaultLookbackDays(c.ConditionType)
+	}
+	if c.RearmRule == "" {
+		c.RearmRule = repository.AlertRearmNone
+	}
+
+	window := newPriceWindow(points, time.Duration(c.LookbackDays)*24*time.Hour)
+	lastPrice := make(map[string]float64)
+	triggers := make([]AlertPreviewTrigger, 0)
+
+	for i, p := range points {
+		window.advance(i)
+
+		if !p.ReportedAt.Before(from) {
+			c.EvaluatedAt = p.ReportedAt
+			c.PreviousPrice, c.StationAverage, c.AreaAverage, c.AreaTrailingMin, c.AreaCheapestOther = nil, nil, nil, nil, nil
+			if previous, ok := lastPrice[p.StationID]; ok {
+				c.PreviousPrice = &previous
+			}
+			c.StationAverage = window.stationAverage(p.StationID)
+			c.AreaAverage = window.average()
+			c.AreaTrailingMin = window.min()
+			if c.ConditionType == repository.AlertConditionNewCheapest {
+				c.AreaCheapestOther = cheapestOther(lastPrice, p.StationID)
+			}
+
+			eval := evaluateAlert(c, p.StationID, p.Price)
+			if eval.Triggered {
+				triggers = append(triggers, AlertPreviewTrigger{
+					StationID:   p.StationID,
+					StationName: p.StationName,
+					Price:       p.Price,
+					TriggeredAt: p.ReportedAt,
+				})
+				triggeredAt, stationID := p.ReportedAt, p.StationID
+				c.TriggerCount++
+				c.LastTriggeredAt = &triggeredAt
+				c.LastTriggeredStationID = &stationID
+				c.Armed = c.RearmRule != repository.AlertRearmPriceRecovers
+			} else if eval.Rearm {
+				c.Armed = true
+			}
+		}
+
+		lastPrice[p.StationID] = p.Price
+	}
+
+	return triggers
+}
+
+// suggestAlertThreshold searches the prices reported in the back-test period
+// for the threshold whose trigger rate is closest to target. The rate rises
+// with the threshold, apart from small dips cooldowns can cause, so a binary
+// search finds the lowest threshold reaching the target.
+func suggestAlertThreshold(input repository.CreateAlertInput, timeZone string, points []repository.PriceHistoryPoint, from time.Time, weeks, target float64) *AlertThresholdSuggestion {
+	seen := make(map[float64]bool)
+	prices := make([]float64, 0)
+	for _, p := range points {
+		if !p.ReportedAt.Before(from) && !seen[p.Price] {
+			seen[p.Price] = true
+			prices = append(prices, p.Price)
+		}
+	}
+	if len(prices) == 0 {
+		return nil
+	}
+	sort.Float64s(prices)
+
+	counts := make(map[int]int)
+	countAt := func(i int) int {
+		if n, ok := counts[i]; ok {
+			return n
+		}
+		draft := input
+		draft.PriceThreshold = prices[i]
+		n := len(replayAlert(draft, timeZone, points, from))
+		counts[i] = n
+		return n
+	}
+
+	best := sort.Search(len(prices), func(i int) bool {
+		return float64(countAt(i))/weeks >= target
+	})
+	if best == len(prices) {
+		best = len(prices) - 1
+	} else if best > 0 {
+		above := float64(countAt(best))/weeks - target
+		below := target - float64(countAt(best-1))/weeks
+		if below < above {
+			best--
+		}
+	}
+
+	return &AlertThresholdSuggestion{
+		TargetTriggersPerWeek: target,
+		PriceThreshold:        prices[best],
+		TriggerCount:          countAt(best),
+		TriggersPerWeek:       roundRate(float64(countAt(best)) / weeks),
+	}
+}
+
+func cheapestOther(lastPrice map[string]float64, stationID string) *float64 {
+	var cheapest *float64
+	for id, price := range lastPrice {
+		if id == stationID {
+			continue
+		}
+		if cheapest == nil || price < *cheapest {
+			p := price
+			cheapest = &p
+		}
+	}
+	return cheapest
+}
+
+func roundRate(rate float64) float64 {
+	return math.Round(rate*100) / 100
+}
+
+// priceWindow keeps running totals over the reports within a trailing
+// duration of the latest one, so a replay stays linear in the number of reports.
+type priceWindow struct {
+	points       []repository.PriceHistoryPoint
+	length       time.Duration
+	start, end   int
+	sum          float64
+	stationSum   map[string]float64
+	stationCount map[string]int
+	// minQueue holds indexes in the window with increasing prices; the front is the minimum.
+	minQueue []int
+}
+
+func newPriceWindow(points []repository.PriceHistoryPoint, length time.Duration) *priceWindow {
+	return &priceWindow{
+		points:       points,
+		length:       length,
+		stationSum:   make(map[string]float64),
+		stationCount: make(map[string]int),
+	}
+}
+
+// advance extends the window to include points[i] and drops reports older than
+// the window length before it.
+func (w *priceWindow) advance(i int) {
+	for ; w.end <= i; w.end++ {
+		p := w.points[w.end]
+		w.sum += p.Price
+		w.stationSum[p.StationID] += p.Price
+		w.stationCount[p.StationID]++
+		for len(w.minQueue) > 0 && w.points[w.minQueue[len(w.minQueue)-1]].Price >= p.Price {
+			w.minQueue = w.minQueue[:len(w.minQueue)-1]
+		}
+		w.minQueue = append(w.minQueue, w.end)
+	}
+
+	cutoff := w.points[i].ReportedAt.Add(-w.length)
+	for ; w.start < w.end && w.points[w.start].ReportedAt.Before(cutoff); w.start++ {
+		p := w.points[w.start]
+		w.sum -= p.Price
+		w.stationSum[p.StationID] -= p.Price
+		w.stationCount[p.StationID]--
+		if len(w.minQueue) > 0 && w.minQueue[0] == w.start {
+			w.minQueue = w.minQueue[1:]
+		}
+	}
+}
+
+func (w *priceWindow) average() *float64 {
+	if w.end == w.start {
+		return nil
+	}
+	avg := w.sum / float64(w.end-w.start)
+	return &avg
+}
+
+func (w *priceWindow) stationAverage(stationID string) *float64 {
+	count := w.stationCount[stationID]
+	if count == 0 {
+		return nil
+	}
+	avg := w.stationSum[stationID] / float64(count)
+	return &avg
+}
+
+func (w *priceWindow) min() *float64 {
+	if len(w.minQueue) == 0 {
+		return nil
+	}
+	m := w.points[w.minQueue[0]].Price
+	return &m
+}
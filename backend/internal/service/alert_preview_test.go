@@ -0,0 +1,119 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"gaspeep/backend/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func previewHistory(now time.Time) []repository.PriceHistoryPoint {
+	day := func(daysAgo, hour int) time.Time {
+		d := now.AddDate(0, 0, -daysAgo)
+		return time.Date(d.Year(), d.Month(), d.Day(), hour, 0, 0, 0, time.UTC)
+	}
+	return []repository.PriceHistoryPoint{
+		// Before the back-test period: builds history only
+		{StationID: "station-a", StationName: "Station A", Price: 1.70, ReportedAt: day(32, 10)},
+		{StationID: "station-a", StationName: "Station A", Price: 1.79, ReportedAt: day(10, 10)},
+		{StationID: "station-b", StationName: "Station B", Price: 1.75, ReportedAt: day(10, 12)},
+		{StationID: "station-a", StationName: "Station A", Price: 1.85, ReportedAt: day(5, 10)},
+		{StationID: "station-b", StationName: "Station B", Price: 1.78, ReportedAt: day(3, 10)},
+	}
+}
+
+func TestPreviewAlert_CountsTriggersAndSuggestsThreshold(t *testing.T) {
+	mockRepo := new(MockAlertRepository)
+	service := NewAlertService(mockRepo)
+
+	now := time.Now()
+	mockRepo.On("GetPriceHistory", mock.MatchedBy(func(input repository.PriceHistoryInput) bool {
+		// 30-day window plus the default 7-day lookback
+		since := now.AddDate(0, 0, -37)
+		return input.UserID == "user-1" && input.FuelTypeID == "fuel-1" &&
+			input.TargetMode == repository.AlertTargetRadius &&
+			input.Since.Sub(since).Abs() < time.Minute
+	})).Return(&repository.PriceHistoryResult{TimeZone: "UTC", Points: previewHistory(now)}, nil)
+
+	result, err := service.PreviewAlert("user-1", AlertPreviewRequest{
+		Alert: repository.CreateAlertInput{
+			FuelTypeID:     "fuel-1",
+			PriceThreshold: 1.80,
+			Latitude:       -33.86,
+			Longitude:      151.2,
+			RadiusKm:       10,
+			RecurrenceType: "recurring",
+		},
+		TargetTriggersPerWeek: 0.4,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, 30, result.Days)
+	assert.Equal(t, 4, result.PriceReports)
+	// Station B's 1.75 is on the same day as Station A's 1.79, so only one fires
+	assert.Equal(t, 2, result.TriggerCount)
+	assert.Equal(t, 0.47, result.TriggersPerWeek)
+	require.Len(t, result.Triggers, 2)
+	assert.Equal(t, "station-b", result.Triggers[0].StationID)
+	assert.Equal(t, 1.78, result.Triggers[0].Price)
+	assert.Equal(t, "station-a", result.Triggers[1].StationID)
+	assert.Len(t, result.Stations, 2)
+
+	require.NotNil(t, result.Suggestion)
+	assert.Equal(t, 1.78, result.Suggestion.PriceThreshold)
+	assert.Equal(t, 2, result.Suggestion.TriggerCount)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestPreviewAlert_InvalidDraft_ReturnsError(t *testing.T) {
+	mockRepo := new(MockAlertRepository)
+	service := NewAlertService(mockRepo)
+
+	_, err := service.PreviewAlert("user-1", AlertPreviewRequest{
+		Alert: repository.CreateAlertInput{FuelTypeID: "fuel-1", PriceThreshold: 1.80},
+	})
+
+	assert.ErrorIs(t, err, repository.ErrInvalidAlertInput)
+	mockRepo.AssertNotCalled(t, "GetPriceHistory", mock.Anything)
+}
+
+func TestReplayAlert_PriceRiseUsesEachStationsLastPrice(t *testing.T) {
+	now := time.Now()
+	triggers := replayAlert(repository.CreateAlertInput{
+		ConditionType:    repository.AlertConditionPriceRise,
+		ConditionPercent: 2,
+		RecurrenceType:   "recurring",
+	}, "UTC", previewHistory(now), now.AddDate(0, 0, -30))
+
+	// Station A rises from its pre-window 1.70 and again from 1.79; Station B
+	// only falls.
+	require.Len(t, triggers, 2)
+	assert.Equal(t, "station-a", triggers[0].StationID)
+	assert.Equal(t, 1.79, triggers[0].Price)
+	assert.Equal(t, "station-a", triggers[1].StationID)
+	assert.Equal(t, 1.85, triggers[1].Price)
+}
+
+func TestPriceWindow_SlidesOverLookback(t *testing.T) {
+	start := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	points := []repository.PriceHistoryPoint{
+		{StationID: "a", Price: 1.60, ReportedAt: start},
+		{StationID: "b", Price: 1.90, ReportedAt: start.Add(24 * time.Hour)},
+		{StationID: "a", Price: 1.80, ReportedAt: start.Add(48 * time.Hour)},
+	}
+	window := newPriceWindow(points, 36*time.Hour)
+
+	window.advance(1)
+	assert.InDelta(t, 1.60, *window.min(), 0.0001)
+	assert.InDelta(t, 1.75, *window.average(), 0.0001)
+
+	// The first report is now outside the window
+	window.advance(2)
+	assert.InDelta(t, 1.80, *window.min(), 0.0001)
+	assert.InDelta(t, 1.85, *window.average(), 0.0001)
+	assert.InDelta(t, 1.80, *window.stationAverage("a"), 0.0001)
+	assert.Nil(t, window.stationAverage("c"))
+}
@@ -13,6 +13,7 @@ type AlertService interface {
 	DeleteAlert(id, userID string) (bool, error)
 	GetPriceContext(input repository.PriceContextInput) (*repository.PriceContextResult, error)
 	GetMatchingStations(alertID, userID string) ([]repository.MatchingStationResult, error)
+	PreviewAlert(userID string, req AlertPreviewRequest) (*AlertPreviewResult, error)
 }
 
 type alertService struct {
@@ -24,6 +25,9 @@ func NewAlertService(alertRepo repository.AlertRepository) AlertService {
 }
 
 func (s *alertService) CreateAlert(userID string, input repository.CreateAlertInput) (*models.Alert, error) {
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
 	return s.alertRepo.Create(userID, input)
 }
 
@@ -32,6 +36,15 @@ func (s *alertService) GetAlerts(userID string) ([]models.Alert, error) {
 }
 
 func (s *alertService) UpdateAlert(id, userID string, input repository.UpdateAlertInput) (string, error) {
+	if input.ChangesCondition() {
+		current, err := s.alertRepo.GetByID(id, userID)
+		if err != nil {
+			return "", err
+		}
+		if err := input.Validate(*current); err != nil {
+			return "", err
+		}
+	}
 	return s.alertRepo.Update(id, userID, input)
 }
 
@@ -57,8 +57,40 @@ func (m *MockAlertRepository) GetMatchingStations(alertID, userID string) ([]rep
 	return args.Get(0).([]repository.MatchingStationResult), args.Error(1)
 }
 
-func (m *MockAlertRepository) RecordTriggersForPrice(stationID, fuelTypeID string, price float64) ([]repository.TriggeredAlertResult, error) {
-	args := m.Called(stationID, fuelTypeID, price)
+func (m *MockAlertRepository) GetByID(id, userID string) (*models.Alert, error) {
+	args := m.Called(id, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Alert), args.Error(1)
+}
+
+func (m *MockAlertRepository) GetTriggerCandidates(stationID, fuelTypeID string) ([]repository.AlertTriggerCandidate, error) {
+	args := m.Called(stationID, fuelTypeID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]repository.AlertTriggerCandidate), args.Error(1)
+}
+
+func (m *MockAlertRepository) GetPriceHistory(input repository.PriceHistoryInput) (*repository.PriceHistoryResult, error) {
+	args := m.Called(input)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*repository.PriceHistoryResult), args.Error(1)
+}
+
+func (m *MockAlertRepository) GetTriggerCandidatesForChanges(events []repository.PriceChangeEvent) ([]repository.AlertTriggerCandidate, error) {
+	args := m.Called(events)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]repository.AlertTriggerCandidate), args.Error(1)
+}
+
+func (m *MockAlertRepository) RecordEvaluations(eventID, stationID string, price float64, evaluations []repository.AlertEvaluation) ([]repository.TriggeredAlertResult, error) {
+	args := m.Called(eventID, stationID, price, evaluations)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
@@ -71,7 +103,13 @@ func TestAlertService_CreateAlert_CallsRepository(t *testing.T) {
 	mockRepo := new(MockAlertRepository)
 	service := NewAlertService(mockRepo)
 
-	input := repository.CreateAlertInput{FuelTypeID: "fuel-1"}
+	input := repository.CreateAlertInput{
+		FuelTypeID:     "fuel-1",
+		PriceThreshold: 1.80,
+		Latitude:       -33.8688,
+		Longitude:      151.2093,
+		RadiusKm:       10,
+	}
 	expectedAlert := &models.Alert{ID: "alert-1"}
 	mockRepo.On("Create", "user-1", input).Return(expectedAlert, nil)
 
@@ -82,6 +120,35 @@ func TestAlertService_CreateAlert_CallsRepository(t *testing.T) {
 	mockRepo.AssertExpectations(t)
 }
 
+func TestAlertService_CreateAlert_RejectsInvalidCondition(t *testing.T) {
+	mockRepo := new(MockAlertRepository)
+	service := NewAlertService(mockRepo)
+
+	result, err := service.CreateAlert("user-1", repository.CreateAlertInput{
+		FuelTypeID:       "fuel-1",
+		ConditionType:    repository.AlertConditionPercentBelowAverage,
+		ConditionPercent: 5,
+	})
+
+	assert.Nil(t, result)
+	assert.ErrorIs(t, err, repository.ErrInvalidAlertInput)
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestAlertService_CreateAlert_StationTargetRequiresStations(t *testing.T) {
+	mockRepo := new(MockAlertRepository)
+	service := NewAlertService(mockRepo)
+
+	_, err := service.CreateAlert("user-1", repository.CreateAlertInput{
+		FuelTypeID:     "fuel-1",
+		PriceThreshold: 1.80,
+		TargetMode:     repository.AlertTargetStations,
+	})
+
+	assert.ErrorIs(t, err, repository.ErrInvalidAlertInput)
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
 func TestAlertService_GetAlerts_CallsRepository(t *testing.T) {
 	mockRepo := new(MockAlertRepository)
 	service := NewAlertService(mockRepo)
@@ -138,6 +205,47 @@ func TestAlertService_UpdateAlert_CallsRepository(t *testing.T) {
 	mockRepo.AssertExpectations(t)
 }
 
+func TestAlertService_UpdateAlert_ValidatesConditionAgainstCurrentAlert(t *testing.T) {
+	mockRepo := new(MockAlertRepository)
+	service := NewAlertService(mockRepo)
+
+	conditionType := repository.AlertConditionPercentBelowAverage
+	input := repository.UpdateAlertInput{ConditionType: &conditionType}
+	mockRepo.On("GetByID", "alert-1", "user-1").Return(&models.Alert{
+		ID:             "alert-1",
+		ConditionType:  repository.AlertConditionPriceThreshold,
+		PriceThreshold: 1.75,
+		LookbackDays:   7,
+	}, nil)
+
+	_, err := service.UpdateAlert("alert-1", "user-1", input)
+
+	assert.ErrorIs(t, err, repository.ErrInvalidAlertInput)
+	mockRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestAlertService_UpdateAlert_RejectsZeroLookbackDays(t *testing.T) {
+	mockRepo := new(MockAlertRepository)
+	service := NewAlertService(mockRepo)
+
+	lookbackDays := 0
+	input := repository.UpdateAlertInput{LookbackDays: &lookbackDays}
+	mockRepo.On("GetByID", "alert-1", "user-1").Return(&models.Alert{
+		ID:             "alert-1",
+		ConditionType:  repository.AlertConditionPriceThreshold,
+		PriceThreshold: 1.75,
+		TargetMode:     repository.AlertTargetRadius,
+		RadiusKm:       10,
+		LookbackDays:   7,
+	}, nil)
+
+	_, err := service.UpdateAlert("alert-1", "user-1", input)
+
+	assert.ErrorIs(t, err, repository.ErrInvalidAlertInput)
+	assert.ErrorContains(t, err, "lookbackDays")
+	mockRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything)
+}
+
 func TestAlertService_DeleteAlert_CallsRepository(t *testing.T) {
 	mockRepo := new(MockAlertRepository)
 	service := NewAlertService(mockRepo)
@@ -0,0 +1,224 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"gaspeep/backend/internal/repository"
+)
+
+const (
+	alertWorkerBatchSize   = 500
+	alertWorkerLease       = 2 * time.Minute
+	alertWorkerMaxAttempts = 8
+	alertWorkerBaseBackoff = 30 * time.Second
+	alertWorkerMaxBackoff  = time.Hour
+	alertOutboxRetention   = 7 * 24 * time.Hour
+)
+
+// AlertWorker evaluates queued price change events against alerts in the
+// background, so writing a price never waits on or fails because of alerts.
+// Triggered alerts that notify by email are queued with emailService.
+type AlertWorker struct {
+	outboxRepo   repository.PriceChangeOutboxRepository
+	alertRepo    repository.AlertRepository
+	emailService EmailService
+
+	workers      int
+	pollInterval time.Duration
+}
+
+func NewAlertWorker(outboxRepo repository.PriceChangeOutboxRepository, alertRepo repository.AlertRepository, emailService EmailService) *AlertWorker {
+	workers := parseEnvInt("ALERT_WORKER_COUNT", 4)
+	if workers < 1 {
+		workers = 1
+	}
+
+	pollSeconds := parseEnvInt("ALERT_WORKER_POLL_SECONDS", 2)
+	if pollSeconds < 1 {
+		pollSeconds = 2
+	}
+
+	return &AlertWorker{
+		outboxRepo:   outboxRepo,
+		alertRepo:    alertRepo,
+		emailService: emailService,
+		workers:      workers,
+		pollInterval: time.Duration(pollSeconds) * time.Second,
+	}
+}
+
+// Start launches the worker pool and the outbox cleanup loop. They stop when
+// ctx is cancelled.
+func (w *AlertWorker) Start(ctx context.Context) {
+	log.Printf("Alert workers started (workers=%d poll=%s)", w.workers, w.pollInterval)
+
+	var wg sync.WaitGroup
+	for i := 0; i < w.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w.run(ctx)
+		}()
+	}
+
+	go func() {
+		ticker := time.NewTicker(time.Hour)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				deleted, err := w.outboxRepo.DeleteProcessedBefore(time.Now().Add(-alertOutboxRetention))
+				if err != nil {
+					log.Printf("Alert outbox cleanup failed: %v", err)
+				} else if deleted > 0 {
+					log.Printf("Alert outbox cleanup removed %d events", deleted)
+				}
+			}
+		}
+	}()
+
+	go func() {
+		wg.Wait()
+		log.Printf("Alert workers stopped")
+	}()
+}
+
+func (w *AlertWorker) run(ctx context.Context) {
+	for {
+		processed, err := w.processBatch()
+		if err != nil {
+			log.Printf("Alert worker failed to claim events: %v", err)
+		}
+
+		// Keep draining while there is a backlog; otherwise wait for the next poll.
+		if processed == 0 || err != nil {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(w.pollInterval):
+			}
+			continue
+		}
+
+		if ctx.Err() != nil {
+			return
+		}
+	}
+}
+
+// processBatch claims a batch of events, matches all of them against alerts in
+// one query and evaluates them in order, returning how many were claimed.
+func (w *AlertWorker) processBatch() (int, error) {
+	events, err := w.outboxRepo.Claim(alertWorkerBatchSize, alertWorkerLease)
+	if err != nil {
+		return 0, err
+	}
+	if len(events) == 0 {
+		return 0, nil
+	}
+
+	candidates, err := w.alertRepo.GetTriggerCandidatesForChanges(events)
+	if err != nil {
+		for _, event := range events {
+			w.retryEvent(event, err)
+		}
+		return len(events), nil
+	}
+
+	byEvent := make(map[string][]repository.AlertTriggerCandidate, len(events))
+	for _, c := range candidates {
+		byEvent[c.EventID] = append(byEvent[c.EventID], c)
+	}
+
+	// Most changes match no alerts; complete those in a single statement.
+	unmatched := make([]string, 0, len(events))
+	for _, event := range events {
+		if len(byEvent[event.ID]) == 0 {
+			unmatched = append(unmatched, event.ID)
+		}
+	}
+	if err := w.outboxRepo.Complete(unmatched); err != nil {
+		// The leases expire and the events are claimed again.
+		log.Printf("Alert worker: failed to complete %d unmatched events: %v", len(unmatched), err)
+	}
+
+	batchState := make(map[string]alertRetriggerState)
+	for _, event := range events {
+		eventCandidates := byEvent[event.ID]
+		if len(eventCandidates) == 0 {
+			continue
+		}
+		triggered, err := evaluatePriceChange(w.alertRepo, event, eventCandidates, batchState)
+		if err != nil {
+			w.retryEvent(event, err)
+			continue
+		}
+		if len(triggered) > 0 {
+			log.Printf("Alert worker: event %s fired %d alerts", event.ID, len(triggered))
+			w.notifyByEmail(event, triggered)
+		}
+	}
+
+	return len(events), nil
+}
+
+// notifyByEmail queues price alert emails for triggered alerts that ask for
+// them. Failures are only logged: the triggers are already recorded, so
+// retrying the event would not send them.
+func (w *AlertWorker) notifyByEmail(event repository.PriceChangeEvent, triggered []repository.TriggeredAlertResult) {
+	if w.emailService == nil {
+		return
+	}
+	for _, t := range triggered {
+		if !t.NotifyViaEmail || t.UserEmail == "" {
+			continue
+		}
+		err := w.emailService.SendPriceAlert(t.UserID, t.AlertID, t.UserEmail, t.AlertName, t.StationName, t.FuelTypeName, event.Price, t.Currency)
+		if err != nil && !errors.Is(err, ErrEmailUnsubscribed) && !errors.Is(err, ErrEmailNotVerified) {
+			log.Printf("Alert worker: failed to queue email for alert %s: %v", t.AlertID, err)
+		}
+	}
+}
+
+// retryEvent schedules a failed event for another attempt, or gives up on it
+// once it has used alertWorkerMaxAttempts.
+func (w *AlertWorker) retryEvent(event repository.PriceChangeEvent, err error) {
+	if event.Attempts >= alertWorkerMaxAttempts {
+		log.Printf("Alert worker: event %s failed after %d attempts: %v", event.ID, event.Attempts, err)
+		if failErr := w.outboxRepo.Fail(event.ID, err.Error()); failErr != nil {
+			log.Printf("Alert worker: failed to mark event %s failed: %v", event.ID, failErr)
+		}
+		return
+	}
+
+	delay := alertRetryBackoff(event.Attempts)
+	log.Printf("Alert worker: event %s attempt %d failed, retrying in %s: %v", event.ID, event.Attempts, delay, err)
+	if retryErr := w.outboxRepo.Retry(event.ID, err.Error(), delay); retryErr != nil {
+		// The lease expires on its own, so the event is still retried.
+		log.Printf("Alert worker: failed to reschedule event %s: %v", event.ID, retryErr)
+	}
+}
+
+// alertRetryBackoff doubles the delay for each failed attempt, up to alertWorkerMaxBackoff.
+func alertRetryBackoff(attempts int) time.Duration {
+	return exponentialBackoff(attempts, alertWorkerBaseBackoff, alertWorkerMaxBackoff)
+}
+
+// exponentialBackoff returns base after the first attempt and doubles it for
+// each further attempt, up to max.
+func exponentialBackoff(attempts int, base, max time.Duration) time.Duration {
+	delay := base
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= max {
+			return max
+		}
+	}
+	return delay
+}
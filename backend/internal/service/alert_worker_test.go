@@ -0,0 +1,211 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"gaspeep/backend/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockPriceChangeOutboxRepository is a mock implementation of PriceChangeOutboxRepository
+type MockPriceChangeOutboxRepository struct {
+	mock.Mock
+}
+
+func (m *MockPriceChangeOutboxRepository) Claim(limit int, lease time.Duration) ([]repository.PriceChangeEvent, error) {
+	args := m.Called(limit, lease)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]repository.PriceChangeEvent), args.Error(1)
+}
+
+func (m *MockPriceChangeOutboxRepository) Complete(ids []string) error {
+	args := m.Called(ids)
+	return args.Error(0)
+}
+
+func (m *MockPriceChangeOutboxRepository) Retry(id, lastError string, delay time.Duration) error {
+	args := m.Called(id, lastError, delay)
+	return args.Error(0)
+}
+
+func (m *MockPriceChangeOutboxRepository) Fail(id, lastError string) error {
+	args := m.Called(id, lastError)
+	return args.Error(0)
+}
+
+func (m *MockPriceChangeOutboxRepository) DeleteProcessedBefore(cutoff time.Time) (int64, error) {
+	args := m.Called(cutoff)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+// MockEmailServiceForAlerts mocks the emails sent for triggered alerts.
+// Other EmailService methods are not implemented.
+type MockEmailServiceForAlerts struct {
+	mock.Mock
+	EmailService
+}
+
+func (m *MockEmailServiceForAlerts) SendPriceAlert(userID, alertID, toEmail, alertName, stationName, fuelType string, price float64, currency string) error {
+	args := m.Called(userID, alertID, toEmail, alertName, stationName, fuelType, price, currency)
+	return args.Error(0)
+}
+
+func setupAlertWorkerTest() (*AlertWorker, *MockPriceChangeOutboxRepository, *MockAlertRepository) {
+	outboxRepo := new(MockPriceChangeOutboxRepository)
+	alertRepo := new(MockAlertRepository)
+	return NewAlertWorker(outboxRepo, alertRepo, new(MockEmailServiceForAlerts)), outboxRepo, alertRepo
+}
+
+func TestAlertWorkerProcessBatch_EvaluatesAndCompletesEvents(t *testing.T) {
+	worker, outboxRepo, alertRepo := setupAlertWorkerTest()
+
+	events := []repository.PriceChangeEvent{
+		{ID: "event-1", StationID: "station-123", FuelTypeID: "fuel-456", Price: 1.55, PreviousPrice: floatPtr(1.70), Attempts: 1},
+		{ID: "event-2", StationID: "station-999", FuelTypeID: "fuel-456", Price: 1.90, Attempts: 1},
+	}
+	outboxRepo.On("Claim", alertWorkerBatchSize, alertWorkerLease).Return(events, nil)
+	alertRepo.On("GetTriggerCandidatesForChanges", events).Return([]repository.AlertTriggerCandidate{
+		{EventID: "event-1", AlertID: "alert-1", ConditionType: repository.AlertConditionPriceThreshold, PriceThreshold: 1.60, Armed: true},
+		// The stored previous price is stale; the event's previous price is used.
+		{EventID: "event-1", AlertID: "alert-2", ConditionType: repository.AlertConditionPriceRise, PreviousPrice: floatPtr(1.40), Armed: true},
+	}, nil)
+	outboxRepo.On("Complete", []string{"event-2"}).Return(nil)
+	alertRepo.On("RecordEvaluations", "event-1", "station-123", 1.55, mock.MatchedBy(func(evaluations []repository.AlertEvaluation) bool {
+		return len(evaluations) == 2 &&
+			evaluations[0].AlertID == "alert-1" && evaluations[0].Triggered &&
+			evaluations[1].AlertID == "alert-2" && evaluations[1].Reason == repository.AlertReasonConditionNotMet
+	})).Return([]repository.TriggeredAlertResult{{AlertID: "alert-1"}}, nil)
+
+	processed, err := worker.processBatch()
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, processed)
+	outboxRepo.AssertExpectations(t)
+	alertRepo.AssertExpectations(t)
+	outboxRepo.AssertNotCalled(t, "Retry", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestAlertWorkerProcessBatch_LaterChangeSeesEarlierTrigger(t *testing.T) {
+	worker, outboxRepo, alertRepo := setupAlertWorkerTest()
+
+	evaluatedAt := time.Date(2026, 3, 2, 1, 0, 0, 0, time.UTC)
+	events := []repository.PriceChangeEvent{
+		{ID: "event-1", StationID: "station-123", FuelTypeID: "fuel-456", Price: 1.55, Attempts: 1},
+		{ID: "event-2", StationID: "station-999", FuelTypeID: "fuel-456", Price: 1.50, Attempts: 1},
+	}
+	candidate := repository.AlertTriggerCandidate{
+		AlertID:        "alert-1",
+		ConditionType:  repository.AlertConditionPriceThreshold,
+		PriceThreshold: 1.60,
+		RecurrenceType: "recurring",
+		Armed:          true,
+		TimeZone:       "Australia/Sydney",
+		EvaluatedAt:    evaluatedAt,
+	}
+	first, second := candidate, candidate
+	first.EventID, second.EventID = "event-1", "event-2"
+
+	outboxRepo.On("Claim", alertWorkerBatchSize, alertWorkerLease).Return(events, nil)
+	alertRepo.On("GetTriggerCandidatesForChanges", events).Return([]repository.AlertTriggerCandidate{first, second}, nil)
+	outboxRepo.On("Complete", []string{}).Return(nil)
+	alertRepo.On("RecordEvaluations", "event-1", "station-123", 1.55, mock.Anything).
+		Return([]repository.TriggeredAlertResult{{AlertID: "alert-1"}}, nil)
+	alertRepo.On("RecordEvaluations", "event-2", "station-999", 1.50, mock.MatchedBy(func(evaluations []repository.AlertEvaluation) bool {
+		return len(evaluations) == 1 && !evaluations[0].Triggered &&
+			evaluations[0].Reason == repository.AlertReasonFiredToday &&
+			evaluations[0].ExpectedTriggerCount == 1
+	})).Return([]repository.TriggeredAlertResult{}, nil)
+
+	_, err := worker.processBatch()
+
+	require.NoError(t, err)
+	alertRepo.AssertExpectations(t)
+}
+
+func TestAlertWorkerProcessBatch_EmailsTriggeredAlerts(t *testing.T) {
+	worker, outboxRepo, alertRepo := setupAlertWorkerTest()
+	emailService := new(MockEmailServiceForAlerts)
+	worker.emailService = emailService
+
+	events := []repository.PriceChangeEvent{{ID: "event-1", StationID: "station-123", FuelTypeID: "fuel-456", Price: 155.9, Attempts: 1}}
+	outboxRepo.On("Claim", alertWorkerBatchSize, alertWorkerLease).Return(events, nil)
+	alertRepo.On("GetTriggerCandidatesForChanges", events).Return([]repository.AlertTriggerCandidate{
+		{EventID: "event-1", AlertID: "alert-1", ConditionType: repository.AlertConditionPriceThreshold, PriceThreshold: 160, Armed: true},
+		{EventID: "event-1", AlertID: "alert-2", ConditionType: repository.AlertConditionPriceThreshold, PriceThreshold: 160, Armed: true},
+		{EventID: "event-1", AlertID: "alert-3", ConditionType: repository.AlertConditionPriceThreshold, PriceThreshold: 160, Armed: true},
+	}, nil)
+	outboxRepo.On("Complete", []string{}).Return(nil)
+	alertRepo.On("RecordEvaluations", "event-1", "station-123", 155.9, mock.Anything).Return([]repository.TriggeredAlertResult{
+		{AlertID: "alert-1", UserID: "user-1", AlertName: "Cheap U91", NotifyViaEmail: true, UserEmail: "a@example.com", StationName: "Station A", FuelTypeName: "U91", Currency: "AUD"},
+		{AlertID: "alert-2", UserID: "user-2", NotifyViaPush: true, UserEmail: "b@example.com"},
+		{AlertID: "alert-3", UserID: "user-3", AlertName: "Opted out", NotifyViaEmail: true, UserEmail: "c@example.com", StationName: "Station A", FuelTypeName: "U91", Currency: "AUD"},
+	}, nil)
+	emailService.On("SendPriceAlert", "user-1", "alert-1", "a@example.com", "Cheap U91", "Station A", "U91", 155.9, "AUD").Return(nil)
+	emailService.On("SendPriceAlert", "user-3", "alert-3", "c@example.com", "Opted out", "Station A", "U91", 155.9, "AUD").Return(ErrEmailUnsubscribed)
+
+	_, err := worker.processBatch()
+
+	require.NoError(t, err)
+	emailService.AssertExpectations(t)
+	emailService.AssertNumberOfCalls(t, "SendPriceAlert", 2)
+	// Email failures never send the event back for another attempt
+	outboxRepo.AssertNotCalled(t, "Retry", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestAlertWorkerProcessBatch_FailedEvaluationIsRetried(t *testing.T) {
+	worker, outboxRepo, alertRepo := setupAlertWorkerTest()
+
+	events := []repository.PriceChangeEvent{{ID: "event-1", StationID: "station-123", FuelTypeID: "fuel-456", Price: 1.55, Attempts: 3}}
+	outboxRepo.On("Claim", alertWorkerBatchSize, alertWorkerLease).Return(events, nil)
+	alertRepo.On("GetTriggerCandidatesForChanges", events).Return(nil, errors.New("connection reset"))
+	outboxRepo.On("Retry", "event-1", "connection reset", 2*time.Minute).Return(nil)
+
+	processed, err := worker.processBatch()
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, processed)
+	outboxRepo.AssertExpectations(t)
+}
+
+func TestAlertWorkerProcessBatch_GivesUpAfterMaxAttempts(t *testing.T) {
+	worker, outboxRepo, alertRepo := setupAlertWorkerTest()
+
+	events := []repository.PriceChangeEvent{{ID: "event-1", StationID: "station-123", FuelTypeID: "fuel-456", Price: 1.55, Attempts: alertWorkerMaxAttempts}}
+	outboxRepo.On("Claim", alertWorkerBatchSize, alertWorkerLease).Return(events, nil)
+	alertRepo.On("GetTriggerCandidatesForChanges", events).Return([]repository.AlertTriggerCandidate{
+		{EventID: "event-1", AlertID: "alert-1", ConditionType: repository.AlertConditionPriceThreshold, PriceThreshold: 1.60, Armed: true},
+	}, nil)
+	outboxRepo.On("Complete", []string{}).Return(nil)
+	alertRepo.On("RecordEvaluations", "event-1", "station-123", 1.55, mock.Anything).Return(nil, errors.New("connection reset"))
+	outboxRepo.On("Fail", "event-1", "connection reset").Return(nil)
+
+	_, err := worker.processBatch()
+
+	require.NoError(t, err)
+	outboxRepo.AssertExpectations(t)
+	outboxRepo.AssertNotCalled(t, "Retry", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestAlertWorkerProcessBatch_ClaimFails_ReturnsError(t *testing.T) {
+	worker, outboxRepo, _ := setupAlertWorkerTest()
+
+	outboxRepo.On("Claim", alertWorkerBatchSize, alertWorkerLease).Return(nil, errors.New("database error"))
+
+	processed, err := worker.processBatch()
+
+	assert.Error(t, err)
+	assert.Equal(t, 0, processed)
+}
+
+func TestAlertRetryBackoff(t *testing.T) {
+	assert.Equal(t, 30*time.Second, alertRetryBackoff(1))
+	assert.Equal(t, time.Minute, alertRetryBackoff(2))
+	assert.Equal(t, 4*time.Minute, alertRetryBackoff(4))
+	assert.Equal(t, time.Hour, alertRetryBackoff(20))
+}
@@ -27,13 +27,50 @@ type BroadcastService interface {
 type broadcastService struct {
 	broadcastRepo      repository.BroadcastRepository
 	stationOwnerRepo   repository.StationOwnerRepository
+	claimRepo          repository.ClaimVerificationRepository
 }
 
-func NewBroadcastService(broadcastRepo repository.BroadcastRepository, stationOwnerRepo repository.StationOwnerRepository) BroadcastService {
-	return &broadcastService{broadcastRepo: broadcastRepo, stationOwnerRepo: stationOwnerRepo}
+// NewBroadcastService only lets team members with the send_broadcasts
+// permission for a station work on its broadcasts, and only lets them
+// create, schedule or send broadcasts while the station's claim is approved
+// and has not expired; drafts can be saved regardless. Each broadcast records
+// the members who created and last changed it.
+func NewBroadcastService(broadcastRepo repository.BroadcastRepository, stationOwnerRepo repository.StationOwnerRepository, claimRepo repository.ClaimVerificationRepository) BroadcastService {
+	return &broadcastService{broadcastRepo: broadcastRepo, stationOwnerRepo: stationOwnerRepo, claimRepo: claimRepo}
 }
 
-// Helper function to get owner ID from user ID
+// requireActiveClaim returns repository.ErrStationClaimNotApproved unless the
+// user's claim on the station is approved and has not expired.
+func (s *broadcastService) requireActiveClaim(userID, stationID string) error {
+	ok, err := s.claimRepo.HasActiveClaim(userID, stationID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return repository.ErrStationClaimNotApproved
+	}
+	return nil
+}
+
+// requireStationPermission returns repository.ErrStationPermissionDenied
+// unless the user may use permission at the station.
+func (s *broadcastService) requireStationPermission(userID, stationID, permission string) error {
+	ok, err := s.stationOwnerRepo.HasStationPermission(userID, stationID, permission)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return repository.ErrStationPermissionDenied
+	}
+	return nil
+}
+
+// broadcastGoesOut reports whether a broadcast with status will be sent.
+func broadcastGoesOut(status string) bool {
+	return status == "active" || status == "scheduled"
+}
+
+// getOwnerID returns the ID of the business the user is a team member of.
 func (s *broadcastService) getOwnerID(userID string) (string, error) {
 	owner, err := s.stationOwnerRepo.GetByUserID(userID)
 	if err != nil {
@@ -72,6 +109,13 @@ func (s *broadcastService) CreateBroadcast(userID string, input repository.Creat
 	if input.EndDate.Before(input.StartDate) {
 		return nil, fmt.Errorf("end date must be after start date")
 	}
+	if err := s.requireStationPermission(userID, input.StationID, models.StationPermissionSendBroadcasts); err != nil {
+		return nil, err
+	}
+	if err := s.requireActiveClaim(userID, input.StationID); err != nil {
+		return nil, err
+	}
+	input.CreatedBy = userID
 
 	log.Printf("[CreateBroadcast] Validation passed, looking up station owner for userID=%s", userID)
 
@@ -123,6 +167,19 @@ func (s *broadcastService) UpdateBroadcast(id, userID string, input repository.U
 	if err != nil {
 		return "", err
 	}
+	broadcast, err := s.broadcastRepo.GetByID(id, ownerID)
+	if err != nil {
+		return "", err
+	}
+	if err := s.requireStationPermission(userID, broadcast.StationID, models.StationPermissionSendBroadcasts); err != nil {
+		return "", err
+	}
+	if broadcastGoesOut(input.BroadcastStatus) {
+		if err := s.requireActiveClaim(userID, broadcast.StationID); err != nil {
+			return "", err
+		}
+	}
+	input.UpdatedBy = userID
 	return s.broadcastRepo.Update(id, ownerID, input)
 }
 
@@ -142,10 +199,13 @@ func (s *broadcastService) GetEngagement(id, userID string) ([]map[string]interf
 	}
 
 	// Verify ownership first
-	_, err = s.broadcastRepo.GetByID(id, ownerID)
+	broadcast, err := s.broadcastRepo.GetByID(id, ownerID)
 	if err != nil {
 		return nil, err
 	}
+	if err := s.requireStationPermission(userID, broadcast.StationID, models.StationPermissionViewAnalytics); err != nil {
+		return nil, err
+	}
 
 	// TODO: Once broadcast_analytics table is added, query engagement data
 	// For now, return empty array with sample structure
@@ -165,6 +225,10 @@ func (s *broadcastService) SaveDraft(userID string, input repository.CreateBroad
 	if input.Title == "" {
 		return nil, fmt.Errorf("broadcast title is required")
 	}
+	if err := s.requireStationPermission(userID, input.StationID, models.StationPermissionSendBroadcasts); err != nil {
+		return nil, err
+	}
+	input.CreatedBy = userID
 
 	log.Printf("[SaveDraft] Validation passed, looking up station owner for userID=%s", userID)
 
@@ -204,6 +268,7 @@ func (s *broadcastService) SaveDraft(userID string, input repository.CreateBroad
 		EndDate:         broadcast.EndDate,
 		BroadcastStatus: "draft",
 		TargetFuelTypes: targetFuelTypes,
+		UpdatedBy:       userID,
 	}
 
 	_, err = s.broadcastRepo.Update(broadcast.ID, owner.ID, updateInput)
@@ -229,6 +294,12 @@ func (s *broadcastService) SendBroadcast(id, userID string) (*models.Broadcast,
 	if err != nil {
 		return nil, err
 	}
+	if err := s.requireStationPermission(userID, broadcast.StationID, models.StationPermissionSendBroadcasts); err != nil {
+		return nil, err
+	}
+	if err := s.requireActiveClaim(userID, broadcast.StationID); err != nil {
+		return nil, err
+	}
 
 	// Update status to "active"
 	targetFuelTypes := ""
@@ -243,6 +314,7 @@ func (s *broadcastService) SendBroadcast(id, userID string) (*models.Broadcast,
 		EndDate:         broadcast.EndDate,
 		BroadcastStatus: "active",
 		TargetFuelTypes: targetFuelTypes,
+		UpdatedBy:       userID,
 	}
 
 	_, err = s.broadcastRepo.Update(id, ownerID, updateInput)
@@ -268,6 +340,12 @@ func (s *broadcastService) ScheduleBroadcast(id, userID string, scheduledFor tim
 	if err != nil {
 		return nil, err
 	}
+	if err := s.requireStationPermission(userID, broadcast.StationID, models.StationPermissionSendBroadcasts); err != nil {
+		return nil, err
+	}
+	if err := s.requireActiveClaim(userID, broadcast.StationID); err != nil {
+		return nil, err
+	}
 
 	// Update status to "scheduled" with the scheduled time
 	targetFuelTypes := ""
@@ -282,6 +360,7 @@ func (s *broadcastService) ScheduleBroadcast(id, userID string, scheduledFor tim
 		EndDate:         broadcast.EndDate,
 		BroadcastStatus: "scheduled",
 		TargetFuelTypes: targetFuelTypes,
+		UpdatedBy:       userID,
 	}
 
 	_, err = s.broadcastRepo.Update(id, ownerID, updateInput)
@@ -307,6 +386,10 @@ func (s *broadcastService) CancelBroadcast(id, userID string) error {
 		return err
 	}
 
+	if err := s.requireStationPermission(userID, broadcast.StationID, models.StationPermissionSendBroadcasts); err != nil {
+		return err
+	}
+
 	// Only allow cancellation of scheduled broadcasts
 	if broadcast.BroadcastStatus != "scheduled" {
 		return fmt.Errorf("can only cancel scheduled broadcasts")
@@ -325,6 +408,7 @@ func (s *broadcastService) CancelBroadcast(id, userID string) error {
 		EndDate:         broadcast.EndDate,
 		BroadcastStatus: "cancelled",
 		TargetFuelTypes: targetFuelTypes,
+		UpdatedBy:       userID,
 	}
 
 	_, err = s.broadcastRepo.Update(id, ownerID, updateInput)
@@ -349,6 +433,10 @@ func (s *broadcastService) DeleteBroadcast(id, userID string) error {
 		return err
 	}
 
+	if err := s.requireStationPermission(userID, broadcast.StationID, models.StationPermissionSendBroadcasts); err != nil {
+		return err
+	}
+
 	// Only allow deletion of draft or cancelled broadcasts
 	if broadcast.BroadcastStatus != "draft" && broadcast.BroadcastStatus != "cancelled" {
 		return fmt.Errorf("can only delete draft or cancelled broadcasts")
@@ -368,6 +456,12 @@ func (s *broadcastService) DuplicateBroadcast(id, userID string) (*models.Broadc
 	if err != nil {
 		return nil, err
 	}
+	if err := s.requireStationPermission(userID, original.StationID, models.StationPermissionSendBroadcasts); err != nil {
+		return nil, err
+	}
+	if err := s.requireActiveClaim(userID, original.StationID); err != nil {
+		return nil, err
+	}
 
 	// Create input for new broadcast based on original
 	targetFuelTypes := ""
@@ -382,6 +476,7 @@ func (s *broadcastService) DuplicateBroadcast(id, userID string) (*models.Broadc
 		StartDate:       original.StartDate,
 		EndDate:         original.EndDate,
 		TargetFuelTypes: targetFuelTypes,
+		CreatedBy:       userID,
 	}
 
 	// Create the duplicate
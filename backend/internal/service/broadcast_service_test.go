@@ -112,6 +112,11 @@ func (m *MockStationOwnerRepository) GetFuelPricesForOwner(userID string) (map[s
 	return args.Get(0).(map[string]interface{}), args.Error(1)
 }
 
+func (m *MockStationOwnerRepository) PublishPrices(userID string, prices []repository.OwnerPriceInput) error {
+	args := m.Called(userID, prices)
+	return args.Error(0)
+}
+
 func (m *MockStationOwnerRepository) UpdateProfile(userID string, input repository.UpdateOwnerProfileInput) (*models.StationOwner, error) {
 	args := m.Called(userID, input)
 	if args.Get(0) == nil {
@@ -120,11 +125,21 @@ func (m *MockStationOwnerRepository) UpdateProfile(userID string, input reposito
 	return args.Get(0).(*models.StationOwner), args.Error(1)
 }
 
+func (m *MockStationOwnerRepository) HasStationPermission(userID, stationID, permission string) (bool, error) {
+	args := m.Called(userID, stationID, permission)
+	return args.Bool(0), args.Error(1)
+}
+
 // Helper function to set up tests
 func setupBroadcastTest(t *testing.T) (*broadcastService, *MockBroadcastRepository, *MockStationOwnerRepository) {
 	mockBroadcastRepo := new(MockBroadcastRepository)
 	mockOwnerRepo := new(MockStationOwnerRepository)
-	service := NewBroadcastService(mockBroadcastRepo, mockOwnerRepo).(*broadcastService)
+	// Claims are active unless a test says otherwise
+	mockClaimRepo := new(MockClaimVerificationRepository)
+	mockClaimRepo.On("HasActiveClaim", mock.Anything, mock.Anything).Return(true, nil).Maybe()
+	// Users hold every station permission unless a test says otherwise
+	mockOwnerRepo.On("HasStationPermission", mock.Anything, mock.Anything, mock.Anything).Return(true, nil).Maybe()
+	service := NewBroadcastService(mockBroadcastRepo, mockOwnerRepo, mockClaimRepo).(*broadcastService)
 	return service, mockBroadcastRepo, mockOwnerRepo
 }
 
@@ -657,7 +672,11 @@ func TestUpdateBroadcast_Success(t *testing.T) {
 		Message:         "Updated message",
 		BroadcastStatus: "scheduled",
 	}
-	mockBroadcastRepo.On("Update", "bc-123", "owner-123", input).Return("bc-123", nil)
+	// Scheduling checks the station's claim
+	mockBroadcastRepo.On("GetByID", "bc-123", "owner-123").Return(&models.Broadcast{ID: "bc-123", StationID: "station-1"}, nil)
+	recorded := input
+	recorded.UpdatedBy = "user-1"
+	mockBroadcastRepo.On("Update", "bc-123", "owner-123", recorded).Return("bc-123", nil)
 
 	result, err := service.UpdateBroadcast("bc-123", "user-1", input)
 
@@ -713,3 +732,67 @@ func TestEstimateRecipients_ReturnsZero(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, 0, result)
 }
+
+// ============ Claim Verification Tests ============
+
+func TestBroadcast_LapsedClaimBlocksSendingButNotDrafts(t *testing.T) {
+	mockBroadcastRepo := new(MockBroadcastRepository)
+	mockOwnerRepo := new(MockStationOwnerRepository)
+	mockClaimRepo := new(MockClaimVerificationRepository)
+	service := NewBroadcastService(mockBroadcastRepo, mockOwnerRepo, mockClaimRepo)
+
+	mockOwnerRepo.On("GetByUserID", "user-1").Return(&models.StationOwner{ID: "owner-123"}, nil)
+	mockOwnerRepo.On("HasStationPermission", "user-1", "station-1", models.StationPermissionSendBroadcasts).Return(true, nil)
+	mockClaimRepo.On("HasActiveClaim", "user-1", "station-1").Return(false, nil)
+	draft := &models.Broadcast{ID: "bc-123", StationID: "station-1", BroadcastStatus: "draft"}
+	mockBroadcastRepo.On("GetByID", "bc-123", "owner-123").Return(draft, nil)
+
+	_, err := service.CreateBroadcast("user-1", repository.CreateBroadcastInput{
+		StationID: "station-1",
+		Title:     "Sale",
+		Message:   "Cheap fuel",
+		StartDate: time.Now(),
+		EndDate:   time.Now().Add(24 * time.Hour),
+	})
+	assert.ErrorIs(t, err, repository.ErrStationClaimNotApproved)
+	_, err = service.SendBroadcast("bc-123", "user-1")
+	assert.ErrorIs(t, err, repository.ErrStationClaimNotApproved)
+	_, err = service.ScheduleBroadcast("bc-123", "user-1", time.Now().Add(time.Hour))
+	assert.ErrorIs(t, err, repository.ErrStationClaimNotApproved)
+	_, err = service.UpdateBroadcast("bc-123", "user-1", repository.UpdateBroadcastInput{BroadcastStatus: "active"})
+	assert.ErrorIs(t, err, repository.ErrStationClaimNotApproved)
+	mockBroadcastRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	mockBroadcastRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything)
+
+	// Editing a draft does not need an active claim
+	mockBroadcastRepo.On("Update", "bc-123", "owner-123", mock.Anything).Return("bc-123", nil).Once()
+	_, err = service.UpdateBroadcast("bc-123", "user-1", repository.UpdateBroadcastInput{Title: "Sale", BroadcastStatus: "draft"})
+	assert.NoError(t, err)
+}
+
+func TestBroadcast_StaffWithoutPermissionIsDenied(t *testing.T) {
+	mockBroadcastRepo := new(MockBroadcastRepository)
+	mockOwnerRepo := new(MockStationOwnerRepository)
+	mockClaimRepo := new(MockClaimVerificationRepository)
+	service := NewBroadcastService(mockBroadcastRepo, mockOwnerRepo, mockClaimRepo)
+
+	mockOwnerRepo.On("GetByUserID", "staff-1").Return(&models.StationOwner{ID: "owner-123", Role: models.OwnerRoleStaff}, nil)
+	mockOwnerRepo.On("HasStationPermission", "staff-1", "station-1", mock.Anything).Return(false, nil)
+	draft := &models.Broadcast{ID: "bc-123", StationID: "station-1", BroadcastStatus: "draft"}
+	mockBroadcastRepo.On("GetByID", "bc-123", "owner-123").Return(draft, nil)
+
+	_, err := service.SaveDraft("staff-1", repository.CreateBroadcastInput{StationID: "station-1", Title: "Sale"})
+	assert.ErrorIs(t, err, repository.ErrStationPermissionDenied)
+	_, err = service.UpdateBroadcast("bc-123", "staff-1", repository.UpdateBroadcastInput{Title: "Sale", BroadcastStatus: "draft"})
+	assert.ErrorIs(t, err, repository.ErrStationPermissionDenied)
+	_, err = service.SendBroadcast("bc-123", "staff-1")
+	assert.ErrorIs(t, err, repository.ErrStationPermissionDenied)
+	assert.ErrorIs(t, service.DeleteBroadcast("bc-123", "staff-1"), repository.ErrStationPermissionDenied)
+	_, err = service.GetEngagement("bc-123", "staff-1")
+	assert.ErrorIs(t, err, repository.ErrStationPermissionDenied)
+	mockOwnerRepo.AssertCalled(t, "HasStationPermission", "staff-1", "station-1", models.StationPermissionViewAnalytics)
+	mockBroadcastRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	mockBroadcastRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything)
+	mockBroadcastRepo.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+	mockClaimRepo.AssertNotCalled(t, "HasActiveClaim", mock.Anything, mock.Anything)
+}
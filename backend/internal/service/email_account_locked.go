@@ -0,0 +1,11 @@
+package service
+
+import "time"
+
+// SendAccountLocked tells the user that signing in to their account was
+// paused after too many failed attempts.
+func (s *emailService) SendAccountLocked(userID, toEmail string, lockedFor time.Duration) error {
+	return s.send(userID, toEmail, EmailTemplateAccountLocked, appURL("/auth/forgot-password"), struct {
+		Minutes int
+	}{int(lockedFor.Minutes())})
+}
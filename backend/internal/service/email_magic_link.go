@@ -0,0 +1,10 @@
+package service
+
+import "time"
+
+// SendMagicLink sends a link that signs the user in without a password.
+func (s *emailService) SendMagicLink(userID, toEmail, signInURL string, expiresIn time.Duration) error {
+	return s.send(userID, toEmail, EmailTemplateMagicLink, signInURL, struct {
+		Minutes int
+	}{int(expiresIn.Minutes())})
+}
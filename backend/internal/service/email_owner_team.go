@@ -0,0 +1,12 @@
+package service
+
+// SendOwnerTeamInvitation invites someone to join a station owner business.
+// The invitee may not have an account yet, so it is sent in the default
+// locale.
+func (s *emailService) SendOwnerTeamInvitation(toEmail, businessName, inviterName, role, acceptURL string) error {
+	return s.send("", toEmail, EmailTemplateOwnerTeamInvitation, acceptURL, struct {
+		BusinessName string
+		InviterName  string
+		RoleKey      string
+	}{businessName, inviterName, "email.owner_team_invitation.role_" + role})
+}
@@ -0,0 +1,22 @@
+package service
+
+import "time"
+
+// SendOwnerVerificationReminder reminds a station owner that their ownership
+// verification for a station expires soon.
+func (s *emailService) SendOwnerVerificationReminder(userID, toEmail, stationName string, expiresAt time.Time, daysLeft int) error {
+	return s.send(userID, toEmail, EmailTemplateOwnerVerificationReminder, appURL("/station-owner"), struct {
+		StationName string
+		ExpiresOn   string
+		Days        int
+	}{stationName, expiresAt.Format("2006-01-02"), daysLeft})
+}
+
+// SendOwnerVerificationLapsed tells a station owner that their ownership
+// verification for a station has expired and their owner features for it
+// are suspended until they re-verify.
+func (s *emailService) SendOwnerVerificationLapsed(userID, toEmail, stationName string) error {
+	return s.send(userID, toEmail, EmailTemplateOwnerVerificationLapsed, appURL("/station-owner"), struct {
+		StationName string
+	}{stationName})
+}
@@ -3,59 +3,146 @@ package service
 import (
 	"bytes"
 	"fmt"
-	"net/smtp"
+	"log"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
 	"os"
+	"strings"
+	"time"
 )
 
-// smtpConfig holds SMTP connection settings read from environment variables.
-type smtpConfig struct {
-	Host string
-	Port string
-	User string
-	Pass string
-	From string
+// emailMessageIDHeader carries the outbox message ID on outgoing mail.
+const emailMessageIDHeader = "X-Gaspeep-Message-ID"
+
+const defaultEmailFrom = "Gas Peep <no-reply@gaspeep.local>"
+
+// OutgoingEmail is a rendered email ready to hand to an EmailSender. ID is the
+// outbox message ID. ListUnsubscribeURL, when set, is advertised for RFC 8058
+// one-click unsubscribe.
+type OutgoingEmail struct {
+	ID                 string
+	To                 string
+	Subject            string
+	HTMLBody           string
+	TextBody           string
+	ListUnsubscribeURL string
 }
 
-// loadSMTPConfig reads SMTP configuration from environment variables:
-// SMTP_HOST, SMTP_PORT, SMTP_USER, SMTP_PASS, EMAIL_FROM
-func loadSMTPConfig() (smtpConfig, error) {
-	cfg := smtpConfig{
-		Host: os.Getenv("SMTP_HOST"),
-		Port: os.Getenv("SMTP_PORT"),
-		User: os.Getenv("SMTP_USER"),
-		Pass: os.Getenv("SMTP_PASS"),
-		From: os.Getenv("EMAIL_FROM"),
+// EmailSender delivers rendered emails.
+type EmailSender interface {
+	Send(email OutgoingEmail) error
+}
+
+// NewEmailSenderFromEnv picks the transport from EMAIL_TRANSPORT: smtp, file or
+// memory. When unset, SMTP is used if SMTP_HOST is set and emails are written
+// to the file sink otherwise, so development needs no SMTP server.
+func NewEmailSenderFromEnv() (EmailSender, error) {
+	transport := strings.ToLower(strings.TrimSpace(os.Getenv("EMAIL_TRANSPORT")))
+	if transport == "" {
+		transport = "file"
+		if os.Getenv("SMTP_HOST") != "" {
+			transport = "smtp"
+		}
 	}
-	if cfg.Host == "" || cfg.User == "" || cfg.Pass == "" {
-		return cfg, fmt.Errorf("SMTP not configured: SMTP_HOST/SMTP_USER/SMTP_PASS required")
+
+	switch transport {
+	case "smtp":
+		cfg, err := loadSMTPConfig()
+		if err != nil {
+			return nil, err
+		}
+		return newSMTPEmailSender(cfg)
+	case "file":
+		from, err := parseEmailFrom(os.Getenv("EMAIL_FROM"), defaultEmailFrom)
+		if err != nil {
+			return nil, err
+		}
+		dir := os.Getenv("EMAIL_FILE_DIR")
+		if dir == "" {
+			dir = "tmp/emails"
+		}
+		log.Printf("Email transport: writing emails to %s instead of sending them", dir)
+		return NewFileEmailSender(dir, from)
+	case "memory":
+		from, err := parseEmailFrom(os.Getenv("EMAIL_FROM"), defaultEmailFrom)
+		if err != nil {
+			return nil, err
+		}
+		return NewMemoryEmailSender(from), nil
+	default:
+		return nil, fmt.Errorf("unknown EMAIL_TRANSPORT %q: expected smtp, file or memory", transport)
 	}
-	if cfg.Port == "" {
-		cfg.Port = "25"
+}
+
+// parseEmailFrom parses a From address such as "Gas Peep <no-reply@gaspeep.com>",
+// falling back to fallback when value is empty.
+func parseEmailFrom(value, fallback string) (*mail.Address, error) {
+	if strings.TrimSpace(value) == "" {
+		value = fallback
 	}
-	if cfg.From == "" {
-		cfg.From = cfg.User
+	from, err := mail.ParseAddress(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid EMAIL_FROM %q: %w", value, err)
 	}
-	return cfg, nil
+	return from, nil
 }
 
-// sendEmail sends an HTML email via SMTP. All public Send* functions delegate to this.
-func sendEmail(toEmail, subject, htmlBody string) error {
-	cfg, err := loadSMTPConfig()
-	if err != nil {
-		return err
+// buildMIMEMessage encodes email as a multipart/alternative message with a
+// plain-text part followed by the HTML part.
+func buildMIMEMessage(from *mail.Address, email OutgoingEmail, now time.Time) ([]byte, error) {
+	var body bytes.Buffer
+	parts := multipart.NewWriter(&body)
+	if err := writeMIMEPart(parts, "text/plain", email.TextBody); err != nil {
+		return nil, err
+	}
+	if err := writeMIMEPart(parts, "text/html", email.HTMLBody); err != nil {
+		return nil, err
+	}
+	if err := parts.Close(); err != nil {
+		return nil, fmt.Errorf("failed to build email: %w", err)
+	}
+
+	domain := "localhost"
+	if at := strings.LastIndex(from.Address, "@"); at >= 0 {
+		domain = from.Address[at+1:]
 	}
 
 	var msg bytes.Buffer
-	msg.WriteString(fmt.Sprintf("From: %s\r\n", cfg.From))
-	msg.WriteString(fmt.Sprintf("To: %s\r\n", toEmail))
-	msg.WriteString(fmt.Sprintf("Subject: %s\r\n", subject))
-	msg.WriteString("MIME-Version: 1.0\r\n")
-	msg.WriteString("Content-Type: text/html; charset=\"utf-8\"\r\n")
+	header := func(name, value string) {
+		msg.WriteString(name + ": " + value + "\r\n")
+	}
+	header("From", from.String())
+	header("To", (&mail.Address{Address: email.To}).String())
+	header("Subject", mime.QEncoding.Encode("utf-8", email.Subject))
+	header("Date", now.Format(time.RFC1123Z))
+	header("Message-ID", fmt.Sprintf("<%s@%s>", email.ID, domain))
+	header(emailMessageIDHeader, email.ID)
+	if email.ListUnsubscribeURL != "" {
+		header("List-Unsubscribe", "<"+email.ListUnsubscribeURL+">")
+		header("List-Unsubscribe-Post", "List-Unsubscribe=One-Click")
+	}
+	header("MIME-Version", "1.0")
+	header("Content-Type", fmt.Sprintf("multipart/alternative; boundary=%q", parts.Boundary()))
 	msg.WriteString("\r\n")
-	msg.WriteString(htmlBody)
+	msg.Write(body.Bytes())
 
-	auth := smtp.PlainAuth("", cfg.User, cfg.Pass, cfg.Host)
-	addr := fmt.Sprintf("%s:%s", cfg.Host, cfg.Port)
+	return msg.Bytes(), nil
+}
 
-	return smtp.SendMail(addr, auth, cfg.From, []string{toEmail}, msg.Bytes())
+func writeMIMEPart(parts *multipart.Writer, contentType, content string) error {
+	part, err := parts.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {contentType + "; charset=utf-8"},
+		"Content-Transfer-Encoding": {"quoted-printable"},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to build email: %w", err)
+	}
+	qp := quotedprintable.NewWriter(part)
+	if _, err := qp.Write([]byte(content)); err != nil {
+		return fmt.Errorf("failed to build email: %w", err)
+	}
+	return qp.Close()
 }
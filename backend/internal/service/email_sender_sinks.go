@@ -0,0 +1,96 @@
+package service
+
+import (
+	"fmt"
+	"log"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// FileEmailSender writes each email as an .eml file into a maildir, so
+// development and staging can preview mail without an SMTP server.
+type FileEmailSender struct {
+	dir  string
+	from *mail.Address
+}
+
+// NewFileEmailSender creates the maildir's tmp, new and cur folders under dir.
+func NewFileEmailSender(dir string, from *mail.Address) (*FileEmailSender, error) {
+	for _, sub := range []string{"tmp", "new", "cur"} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0o755); err != nil {
+			return nil, fmt.Errorf("failed to create email directory: %w", err)
+		}
+	}
+	return &FileEmailSender{dir: dir, from: from}, nil
+}
+
+// Send writes the message to tmp and renames it into new, so readers never see
+// a partial file.
+func (s *FileEmailSender) Send(email OutgoingEmail) error {
+	now := time.Now()
+	msg, err := buildMIMEMessage(s.from, email, now)
+	if err != nil {
+		return err
+	}
+
+	name := fmt.Sprintf("%d.%s.eml", now.UnixNano(), email.ID)
+	tmpPath := filepath.Join(s.dir, "tmp", name)
+	if err := os.WriteFile(tmpPath, msg, 0o644); err != nil {
+		return fmt.Errorf("failed to write email: %w", err)
+	}
+	newPath := filepath.Join(s.dir, "new", name)
+	if err := os.Rename(tmpPath, newPath); err != nil {
+		return fmt.Errorf("failed to write email: %w", err)
+	}
+
+	log.Printf("Email %q to %s written to %s", email.Subject, email.To, newPath)
+	return nil
+}
+
+// CapturedEmail is an email recorded by MemoryEmailSender, with the encoded
+// message as it would have been sent.
+type CapturedEmail struct {
+	OutgoingEmail
+	Raw []byte
+}
+
+// MemoryEmailSender keeps sent emails in memory, for tests.
+type MemoryEmailSender struct {
+	from *mail.Address
+
+	mu     sync.Mutex
+	emails []CapturedEmail
+}
+
+func NewMemoryEmailSender(from *mail.Address) *MemoryEmailSender {
+	return &MemoryEmailSender{from: from}
+}
+
+func (s *MemoryEmailSender) Send(email OutgoingEmail) error {
+	msg, err := buildMIMEMessage(s.from, email, time.Now())
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.emails = append(s.emails, CapturedEmail{OutgoingEmail: email, Raw: msg})
+	return nil
+}
+
+// Emails returns the emails sent so far, oldest first.
+func (s *MemoryEmailSender) Emails() []CapturedEmail {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]CapturedEmail(nil), s.emails...)
+}
+
+// Reset discards the captured emails.
+func (s *MemoryEmailSender) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.emails = nil
+}
@@ -0,0 +1,166 @@
+package service
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"os"
+	"strings"
+	"time"
+)
+
+// SMTP connection security modes.
+const (
+	SMTPSecurityStartTLS = "starttls"
+	SMTPSecurityTLS      = "tls"
+	SMTPSecurityNone     = "none"
+)
+
+const smtpDialTimeout = 30 * time.Second
+
+// smtpSendTimeout bounds each SMTP session once connected, so a server that
+// stalls cannot hold a message past the worker's lease on it.
+const smtpSendTimeout = 2 * time.Minute
+
+// smtpConfig holds SMTP connection settings read from environment variables.
+type smtpConfig struct {
+	Host     string
+	Port     string
+	User     string
+	Pass     string
+	From     string
+	Security string
+}
+
+// loadSMTPConfig reads SMTP configuration from environment variables:
+// SMTP_HOST, SMTP_PORT, SMTP_USER, SMTP_PASS, SMTP_SECURITY, EMAIL_FROM.
+// SMTP_USER and SMTP_PASS are optional for servers that need no auth.
+func loadSMTPConfig() (smtpConfig, error) {
+	cfg := smtpConfig{
+		Host:     os.Getenv("SMTP_HOST"),
+		Port:     os.Getenv("SMTP_PORT"),
+		User:     os.Getenv("SMTP_USER"),
+		Pass:     os.Getenv("SMTP_PASS"),
+		From:     os.Getenv("EMAIL_FROM"),
+		Security: strings.ToLower(strings.TrimSpace(os.Getenv("SMTP_SECURITY"))),
+	}
+	if cfg.Host == "" {
+		return cfg, fmt.Errorf("SMTP not configured: SMTP_HOST required")
+	}
+	if cfg.Security == "" {
+		cfg.Security = SMTPSecurityStartTLS
+		if cfg.Port == "465" {
+			cfg.Security = SMTPSecurityTLS
+		}
+	}
+	if cfg.Port == "" {
+		switch cfg.Security {
+		case SMTPSecurityTLS:
+			cfg.Port = "465"
+		case SMTPSecurityStartTLS:
+			cfg.Port = "587"
+		default:
+			cfg.Port = "25"
+		}
+	}
+	if cfg.From == "" {
+		cfg.From = cfg.User
+	}
+	return cfg, nil
+}
+
+// SMTPEmailSender sends email through an SMTP server, over implicit TLS,
+// STARTTLS or, for local relays such as MailHog, plain text.
+type SMTPEmailSender struct {
+	cfg         smtpConfig
+	from        *mail.Address
+	sendTimeout time.Duration
+}
+
+func newSMTPEmailSender(cfg smtpConfig) (*SMTPEmailSender, error) {
+	switch cfg.Security {
+	case SMTPSecurityStartTLS, SMTPSecurityTLS, SMTPSecurityNone:
+	default:
+		return nil, fmt.Errorf("unknown SMTP_SECURITY %q: expected starttls, tls or none", cfg.Security)
+	}
+	from, err := parseEmailFrom(cfg.From, defaultEmailFrom)
+	if err != nil {
+		return nil, err
+	}
+	return &SMTPEmailSender{cfg: cfg, from: from, sendTimeout: smtpSendTimeout}, nil
+}
+
+func (s *SMTPEmailSender) Send(email OutgoingEmail) error {
+	msg, err := buildMIMEMessage(s.from, email, time.Now())
+	if err != nil {
+		return err
+	}
+
+	client, err := s.dial()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if s.cfg.Security == SMTPSecurityStartTLS {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			return fmt.Errorf("SMTP server %s does not support STARTTLS", s.cfg.Host)
+		}
+		if err := client.StartTLS(&tls.Config{ServerName: s.cfg.Host}); err != nil {
+			return fmt.Errorf("SMTP STARTTLS failed: %w", err)
+		}
+	}
+
+	if s.cfg.User != "" {
+		if err := client.Auth(smtp.PlainAuth("", s.cfg.User, s.cfg.Pass, s.cfg.Host)); err != nil {
+			return fmt.Errorf("SMTP auth failed: %w", err)
+		}
+	}
+
+	if err := client.Mail(s.from.Address); err != nil {
+		return err
+	}
+	if err := client.Rcpt(email.To); err != nil {
+		return err
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+func (s *SMTPEmailSender) dial() (*smtp.Client, error) {
+	addr := net.JoinHostPort(s.cfg.Host, s.cfg.Port)
+	dialer := &net.Dialer{Timeout: smtpDialTimeout}
+
+	var conn net.Conn
+	var err error
+	if s.cfg.Security == SMTPSecurityTLS {
+		conn, err = tls.DialWithDialer(dialer, "tcp", addr, &tls.Config{ServerName: s.cfg.Host})
+	} else {
+		conn, err = dialer.Dial("tcp", addr)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to SMTP server %s: %w", addr, err)
+	}
+	if err := conn.SetDeadline(time.Now().Add(s.sendTimeout)); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to set SMTP deadline: %w", err)
+	}
+
+	client, err := smtp.NewClient(conn, s.cfg.Host)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to start SMTP session with %s: %w", addr, err)
+	}
+	return client, nil
+}
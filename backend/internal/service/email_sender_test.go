@@ -0,0 +1,177 @@
+package service
+
+import (
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildMIMEMessage_MultipartAlternative(t *testing.T) {
+	from, err := parseEmailFrom("Gas Peep <no-reply@gaspeep.com>", defaultEmailFrom)
+	require.NoError(t, err)
+	now := time.Date(2026, 3, 2, 9, 30, 0, 0, time.UTC)
+
+	raw, err := buildMIMEMessage(from, OutgoingEmail{
+		ID:       "msg-1",
+		To:       "driver@example.com",
+		Subject:  "Prix de l’essence",
+		HTMLBody: "<p>Cheap fuel at <strong>Station A</strong></p>",
+		TextBody: "Cheap fuel at Station A",
+	}, now)
+	require.NoError(t, err)
+
+	msg, err := mail.ReadMessage(strings.NewReader(string(raw)))
+	require.NoError(t, err)
+	assert.Equal(t, "<msg-1@gaspeep.com>", msg.Header.Get("Message-ID"))
+	assert.Equal(t, "msg-1", msg.Header.Get(emailMessageIDHeader))
+	date, err := msg.Header.Date()
+	require.NoError(t, err)
+	assert.True(t, date.Equal(now))
+	subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	require.NoError(t, err)
+	assert.Equal(t, "Prix de l’essence", subject)
+
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	require.NoError(t, err)
+	assert.Equal(t, "multipart/alternative", mediaType)
+
+	reader := multipart.NewReader(msg.Body, params["boundary"])
+	var types, bodies []string
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		body, err := io.ReadAll(part)
+		require.NoError(t, err)
+		types = append(types, part.Header.Get("Content-Type"))
+		bodies = append(bodies, string(body))
+	}
+	assert.Equal(t, []string{"text/plain; charset=utf-8", "text/html; charset=utf-8"}, types)
+	assert.Equal(t, "Cheap fuel at Station A", bodies[0])
+	assert.Contains(t, bodies[1], "<strong>Station A</strong>")
+}
+
+func TestBuildMIMEMessage_ListUnsubscribe(t *testing.T) {
+	from, _ := parseEmailFrom("", defaultEmailFrom)
+	now := time.Now()
+
+	raw, err := buildMIMEMessage(from, OutgoingEmail{ID: "msg-1", To: "driver@example.com", Subject: "Hello"}, now)
+	require.NoError(t, err)
+	msg, err := mail.ReadMessage(strings.NewReader(string(raw)))
+	require.NoError(t, err)
+	assert.Empty(t, msg.Header.Get("List-Unsubscribe"))
+	assert.Empty(t, msg.Header.Get("List-Unsubscribe-Post"))
+
+	raw, err = buildMIMEMessage(from, OutgoingEmail{
+		ID:                 "msg-2",
+		To:                 "driver@example.com",
+		Subject:            "Price alert",
+		ListUnsubscribeURL: "https://api.gaspeep.com/api/email/unsubscribe?token=abc.def",
+	}, now)
+	require.NoError(t, err)
+	msg, err = mail.ReadMessage(strings.NewReader(string(raw)))
+	require.NoError(t, err)
+	assert.Equal(t, "<https://api.gaspeep.com/api/email/unsubscribe?token=abc.def>", msg.Header.Get("List-Unsubscribe"))
+	assert.Equal(t, "List-Unsubscribe=One-Click", msg.Header.Get("List-Unsubscribe-Post"))
+}
+
+func TestFileEmailSender_WritesMaildir(t *testing.T) {
+	dir := t.TempDir()
+	from, _ := parseEmailFrom("", defaultEmailFrom)
+	sender, err := NewFileEmailSender(dir, from)
+	require.NoError(t, err)
+
+	require.NoError(t, sender.Send(OutgoingEmail{ID: "msg-1", To: "driver@example.com", Subject: "Hello", TextBody: "hi", HTMLBody: "<p>hi</p>"}))
+
+	files, err := filepath.Glob(filepath.Join(dir, "new", "*.msg-1.eml"))
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	raw, err := os.ReadFile(files[0])
+	require.NoError(t, err)
+	assert.Contains(t, string(raw), "To: <driver@example.com>")
+
+	tmp, err := os.ReadDir(filepath.Join(dir, "tmp"))
+	require.NoError(t, err)
+	assert.Empty(t, tmp)
+}
+
+func TestMemoryEmailSender_CapturesEmails(t *testing.T) {
+	from, _ := parseEmailFrom("", defaultEmailFrom)
+	sender := NewMemoryEmailSender(from)
+
+	require.NoError(t, sender.Send(OutgoingEmail{ID: "msg-1", To: "a@example.com", Subject: "One"}))
+	require.NoError(t, sender.Send(OutgoingEmail{ID: "msg-2", To: "b@example.com", Subject: "Two"}))
+
+	emails := sender.Emails()
+	require.Len(t, emails, 2)
+	assert.Equal(t, "a@example.com", emails[0].To)
+	assert.Contains(t, string(emails[1].Raw), "Subject: Two")
+
+	sender.Reset()
+	assert.Empty(t, sender.Emails())
+}
+
+func TestNewEmailSenderFromEnv(t *testing.T) {
+	t.Setenv("EMAIL_FROM", "")
+	t.Setenv("SMTP_HOST", "")
+	t.Setenv("EMAIL_TRANSPORT", "")
+	t.Setenv("EMAIL_FILE_DIR", t.TempDir())
+
+	// Without SMTP settings, emails go to the file sink
+	sender, err := NewEmailSenderFromEnv()
+	require.NoError(t, err)
+	assert.IsType(t, &FileEmailSender{}, sender)
+
+	t.Setenv("SMTP_HOST", "smtp.example.com")
+	t.Setenv("SMTP_PORT", "465")
+	t.Setenv("SMTP_SECURITY", "")
+	sender, err = NewEmailSenderFromEnv()
+	require.NoError(t, err)
+	require.IsType(t, &SMTPEmailSender{}, sender)
+	assert.Equal(t, SMTPSecurityTLS, sender.(*SMTPEmailSender).cfg.Security)
+
+	t.Setenv("SMTP_SECURITY", "ssl3")
+	_, err = NewEmailSenderFromEnv()
+	assert.Error(t, err)
+
+	t.Setenv("EMAIL_TRANSPORT", "pigeon")
+	_, err = NewEmailSenderFromEnv()
+	assert.Error(t, err)
+}
+
+func TestSMTPEmailSender_TimesOutStalledServer(t *testing.T) {
+	// The server accepts the connection but never greets the client
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+	go func() {
+		conn, err := ln.Accept()
+		if err == nil {
+			defer conn.Close()
+			io.Copy(io.Discard, conn)
+		}
+	}()
+
+	host, port, err := net.SplitHostPort(ln.Addr().String())
+	require.NoError(t, err)
+	sender, err := newSMTPEmailSender(smtpConfig{Host: host, Port: port, Security: SMTPSecurityNone, From: "noreply@example.com"})
+	require.NoError(t, err)
+	sender.sendTimeout = 100 * time.Millisecond
+
+	start := time.Now()
+	err = sender.Send(OutgoingEmail{ID: "msg-1", To: "user@example.com", Subject: "Hi", TextBody: "Hello"})
+	require.Error(t, err)
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
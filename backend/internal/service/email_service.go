@@ -0,0 +1,162 @@
+package service
+
+import (
+	"strings"
+	"time"
+
+	"gaspeep/backend/internal/repository"
+)
+
+// Email templates, recorded with each queued message.
+const (
+	EmailTemplatePasswordReset     = "password_reset"
+	EmailTemplatePasswordChanged   = "password_changed"
+	EmailTemplateEmailVerification = "email_verification"
+	EmailTemplateWelcome           = "welcome"
+	EmailTemplatePriceAlert        = "price_alert"
+	EmailTemplateAlertApproved     = "alert_approved"
+	EmailTemplateStationBroadcast  = "station_broadcast"
+	EmailTemplateAccountLocked     = "account_locked"
+	EmailTemplateMagicLink         = "magic_link"
+
+	EmailTemplateOwnerVerificationReminder = "owner_verification_reminder"
+	EmailTemplateOwnerVerificationLapsed   = "owner_verification_lapsed"
+	EmailTemplateOwnerTeamInvitation       = "owner_team_invitation"
+)
+
+// accountEmailTemplates are sent whether or not the recipient has verified
+// their address, since they are needed to verify it or to secure the account.
+var accountEmailTemplates = map[string]bool{
+	EmailTemplatePasswordReset:     true,
+	EmailTemplatePasswordChanged:   true,
+	EmailTemplateEmailVerification: true,
+	EmailTemplateAccountLocked:     true,
+	EmailTemplateMagicLink:         true,
+}
+
+// EmailService renders transactional emails and queues them for delivery by
+// EmailWorker. A nil error means the email was queued, not that it was sent.
+// Other than account emails, Send* methods return ErrEmailNotVerified for
+// users who have not verified their address. Alert and broadcast emails carry
+// unsubscribe links and return ErrEmailUnsubscribed for users who have opted out.
+type EmailService interface {
+	SendPasswordReset(userID, toEmail, resetURL string) error
+	SendPasswordChanged(userID, toEmail string) error
+	SendAccountLocked(userID, toEmail string, lockedFor time.Duration) error
+	SendEmailVerification(userID, toEmail, verificationURL string) error
+	SendMagicLink(userID, toEmail, signInURL string, expiresIn time.Duration) error
+	SendWelcome(userID, toEmail, displayName string) error
+	SendPriceAlert(userID, alertID, toEmail, alertName, stationName, fuelType string, price float64, currency string) error
+	SendAlertApproved(userID, toEmail, alertName string) error
+	SendStationBroadcast(userID, stationID, toEmail, stationName, title, message string) error
+	SendOwnerVerificationReminder(userID, toEmail, stationName string, expiresAt time.Time, daysLeft int) error
+	SendOwnerVerificationLapsed(userID, toEmail, stationName string) error
+	SendOwnerTeamInvitation(toEmail, businessName, inviterName, role, acceptURL string) error
+	RecordDeliveryEvent(event repository.EmailDeliveryEvent) error
+	GetEmailLog(filter repository.EmailMessageFilter, page, limit int) ([]repository.EmailMessageLog, int, error)
+}
+
+type emailService struct {
+	outboxRepo   repository.EmailOutboxRepository
+	userRepo     repository.UserRepository
+	verification EmailVerificationPolicy
+	unsubscribes EmailUnsubscribeService
+	templates    *EmailTemplates
+}
+
+func NewEmailService(
+	outboxRepo repository.EmailOutboxRepository,
+	userRepo repository.UserRepository,
+	verification EmailVerificationPolicy,
+	unsubscribes EmailUnsubscribeService,
+	templates *EmailTemplates,
+) EmailService {
+	return &emailService{
+		outboxRepo:   outboxRepo,
+		userRepo:     userRepo,
+		verification: verification,
+		unsubscribes: unsubscribes,
+		templates:    templates,
+	}
+}
+
+// send renders an email in the recipient's preferred locale and queues it.
+// All public Send* methods delegate to this. Emails sent with unsubscribe
+// scopes, narrowest first, are skipped if the user has opted out of any of
+// them, and link to opting out of each. The first scope is used for the
+// List-Unsubscribe header.
+func (s *emailService) send(userID, toEmail, template, ctaURL string, data any, unsubscribe ...repository.UnsubscribeScope) error {
+	var links []emailUnsubscribeLink
+	if userID != "" && len(unsubscribe) > 0 {
+		unsubscribed, err := s.unsubscribes.IsUnsubscribed(userID, unsubscribe...)
+		if err != nil {
+			return err
+		}
+		if unsubscribed {
+			return ErrEmailUnsubscribed
+		}
+		for _, scope := range unsubscribe {
+			links = append(links, emailUnsubscribeLink{Category: scope.Category, URL: s.unsubscribes.URL(userID, scope)})
+		}
+	}
+
+	email, err := s.templates.Render(template, s.userLocale(userID), ctaURL, data, links...)
+	if err != nil {
+		return err
+	}
+
+	listUnsubscribeURL := ""
+	// RFC 8058 one-click unsubscribe requires an HTTPS URL.
+	if len(links) > 0 && strings.HasPrefix(links[0].URL, "https://") {
+		listUnsubscribeURL = links[0].URL
+	}
+	return s.queue(repository.EnqueueEmailInput{
+		UserID:             userID,
+		ToEmail:            toEmail,
+		Template:           template,
+		Subject:            email.Subject,
+		HTMLBody:           email.HTML,
+		TextBody:           email.Text,
+		ListUnsubscribeURL: listUnsubscribeURL,
+	})
+}
+
+// userLocale returns the user's preferred locale, or "" for the default.
+func (s *emailService) userLocale(userID string) string {
+	if userID == "" {
+		return ""
+	}
+	user, err := s.userRepo.GetUserByID(userID)
+	if err != nil {
+		return ""
+	}
+	return user.Locale
+}
+
+// queue stores a rendered email in the outbox.
+func (s *emailService) queue(input repository.EnqueueEmailInput) error {
+	if input.UserID != "" && !accountEmailTemplates[input.Template] {
+		if err := s.verification.RequireVerifiedEmail(input.UserID); err != nil {
+			return err
+		}
+	}
+
+	_, err := s.outboxRepo.Enqueue(input)
+	return err
+}
+
+func (s *emailService) RecordDeliveryEvent(event repository.EmailDeliveryEvent) error {
+	return s.outboxRepo.RecordDeliveryEvent(event)
+}
+
+func (s *emailService) GetEmailLog(filter repository.EmailMessageFilter, page, limit int) ([]repository.EmailMessageLog, int, error) {
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+	offset := (page - 1) * limit
+
+	return s.outboxRepo.ListMessages(filter, limit, offset)
+}
@@ -0,0 +1,15 @@
+package service
+
+import "gaspeep/backend/internal/repository"
+
+// SendStationBroadcast delivers a station owner's broadcast to a user.
+func (s *emailService) SendStationBroadcast(userID, stationID, toEmail, stationName, title, message string) error {
+	return s.send(userID, toEmail, EmailTemplateStationBroadcast, appURL("/stations"), struct {
+		StationName string
+		Title       string
+		Message     string
+	}{stationName, title, message},
+		repository.UnsubscribeScope{Category: repository.UnsubscribeCategoryStationBroadcasts, ScopeID: stationID},
+		repository.UnsubscribeScope{Category: repository.UnsubscribeCategoryAllBroadcasts},
+	)
+}
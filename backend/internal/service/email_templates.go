@@ -2,89 +2,255 @@ package service
 
 import (
 	"bytes"
+	"embed"
 	"fmt"
+	"html"
 	"html/template"
+	"io/fs"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
 	"time"
+
+	"gaspeep/backend/internal/i18n"
 )
 
-// EmailData holds the data for rendering the base email template.
+//go:embed templates/email/*.html
+var embeddedEmailTemplates embed.FS
+
+// emailLayoutFile is the layout every email is rendered into.
+const emailLayoutFile = "layout.html"
+
+// EmailData holds the data for rendering the email layout.
 type EmailData struct {
+	Locale     string        // Locale tag for the html lang attribute, e.g. "en-AU"
 	Heading    string        // Email heading, e.g. "Reset Your Password"
 	Body       template.HTML // Pre-rendered inner HTML content
 	CTAText    string        // Button label — empty string means no button
 	CTAURL     string        // Button link URL
-	FooterText string        // Override footer text; empty uses default
-	Year       int           // Current year for copyright line
+	FooterText string        // Footer text
+	Copyright  string        // Copyright line
+
+	Unsubscribe []EmailLink // Unsubscribe links shown in the footer, if any
 }
 
-const baseEmailTemplate = `<!DOCTYPE html>
-<html lang="en">
-<head>
-  <meta charset="utf-8">
-  <meta name="viewport" content="width=device-width, initial-scale=1.0">
-  <meta http-equiv="X-UA-Compatible" content="IE=edge">
-  <title>{{.Heading}}</title>
-</head>
-<body style="margin:0;padding:0;background-color:#f1f5f9;font-family:'Inter',-apple-system,BlinkMacSystemFont,'Segoe UI',Roboto,Helvetica,Arial,sans-serif;">
-  <!-- Full-width wrapper -->
-  <table role="presentation" width="100%" cellpadding="0" cellspacing="0" border="0" style="background-color:#f1f5f9;">
-    <tr>
-      <td align="center" style="padding:24px 16px;">
-        <!-- 600px centered container -->
-        <table role="presentation" width="600" cellpadding="0" cellspacing="0" border="0" style="max-width:600px;width:100%;border-radius:8px;overflow:hidden;box-shadow:0 1px 3px rgba(0,0,0,0.1);">
-          <!-- Header -->
-          <tr>
-            <td style="background-color:#2563EB;padding:32px 40px;text-align:center;">
-              <h1 style="margin:0;font-size:28px;font-weight:700;color:#ffffff;letter-spacing:-0.5px;">Gas Peep</h1>
-              <p style="margin:6px 0 0;font-size:13px;color:rgba(255,255,255,0.8);font-weight:400;">Community-Driven Fuel Price Monitoring</p>
-            </td>
-          </tr>
-          <!-- Body -->
-          <tr>
-            <td style="background-color:#ffffff;padding:40px;">
-              <h2 style="margin:0 0 20px;font-size:22px;font-weight:600;color:#1e293b;">{{.Heading}}</h2>
-              <div style="margin:0 0 24px;">
-                {{.Body}}
-              </div>
-              {{if .CTAText}}
-              <!-- CTA Button -->
-              <table role="presentation" cellpadding="0" cellspacing="0" border="0" style="margin:28px 0;">
-                <tr>
-                  <td align="center" style="border-radius:6px;background-color:#2563EB;">
-                    <a href="{{.CTAURL}}" target="_blank" style="display:inline-block;padding:14px 32px;font-size:16px;font-weight:600;color:#ffffff;text-decoration:none;border-radius:6px;background-color:#2563EB;">{{.CTAText}}</a>
-                  </td>
-                </tr>
-              </table>
-              {{end}}
-            </td>
-          </tr>
-          <!-- Footer -->
-          <tr>
-            <td style="background-color:#f8fafc;border-top:1px solid #e2e8f0;padding:24px 40px;">
-              <p style="margin:0 0 8px;font-size:13px;color:#64748b;line-height:1.5;">{{.FooterText}}</p>
-              <p style="margin:0;font-size:12px;color:#94a3b8;">&copy; {{.Year}} Gas Peep. All rights reserved.</p>
-            </td>
-          </tr>
-        </table>
-      </td>
-    </tr>
-  </table>
-</body>
-</html>`
-
-var parsedBaseTemplate = template.Must(template.New("email").Parse(baseEmailTemplate))
-
-// renderEmailHTML renders the base email template with the given data.
-func renderEmailHTML(data EmailData) (string, error) {
-	if data.Year == 0 {
-		data.Year = time.Now().Year()
-	}
-	if data.FooterText == "" {
-		data.FooterText = "This email was sent by Gas Peep. If you didn't expect this email, you can safely ignore it."
-	}
-	var buf bytes.Buffer
-	if err := parsedBaseTemplate.Execute(&buf, data); err != nil {
-		return "", fmt.Errorf("failed to render email template: %w", err)
-	}
-	return buf.String(), nil
+// EmailLink is a labelled link in an email.
+type EmailLink struct {
+	Text string
+	URL  string
+}
+
+// emailUnsubscribeLink is an unsubscribe link for Render. Category selects the
+// link text.
+type emailUnsubscribeLink struct {
+	Category string
+	URL      string
+}
+
+// renderedEmail is an email rendered in one locale.
+type renderedEmail struct {
+	Subject string
+	HTML    string
+	Text    string
+}
+
+// EmailTemplates renders localized emails. Each email has a <name>.html
+// template defining "subject", "heading", "body" and, when it has a button,
+// "cta". Templates read their text from the i18n catalog with
+// {{t "key" "param" value}} and format prices with {{price amount currency}}.
+type EmailTemplates struct {
+	catalog *i18n.Catalog
+	emails  map[string]*template.Template
+}
+
+// LoadEmailTemplates loads the templates in EMAIL_TEMPLATE_DIR, so emails can
+// be edited without rebuilding, or the embedded templates when it is unset.
+func LoadEmailTemplates(catalog *i18n.Catalog) (*EmailTemplates, error) {
+	if dir := os.Getenv("EMAIL_TEMPLATE_DIR"); dir != "" {
+		return loadEmailTemplates(os.DirFS(dir), catalog)
+	}
+	sub, err := fs.Sub(embeddedEmailTemplates, "templates/email")
+	if err != nil {
+		return nil, fmt.Errorf("failed to load email templates: %w", err)
+	}
+	return loadEmailTemplates(sub, catalog)
+}
+
+func loadEmailTemplates(fsys fs.FS, catalog *i18n.Catalog) (*EmailTemplates, error) {
+	files, err := fs.Glob(fsys, "*.html")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list email templates: %w", err)
+	}
+
+	t := &EmailTemplates{catalog: catalog, emails: make(map[string]*template.Template)}
+	funcs := emailTemplateFuncs(catalog.Localizer(i18n.DefaultLocale))
+	for _, file := range files {
+		if file == emailLayoutFile {
+			continue
+		}
+		name := strings.TrimSuffix(file, ".html")
+		tmpl, err := template.New(name).Funcs(funcs).ParseFS(fsys, emailLayoutFile, file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse email template %s: %w", file, err)
+		}
+		for _, block := range []string{"layout", "subject", "heading", "body"} {
+			if tmpl.Lookup(block) == nil {
+				return nil, fmt.Errorf("email template %s does not define %q", file, block)
+			}
+		}
+		t.emails[name] = tmpl
+	}
+
+	for _, name := range []string{
+		EmailTemplatePasswordReset,
+		EmailTemplatePasswordChanged,
+		EmailTemplateEmailVerification,
+		EmailTemplateWelcome,
+		EmailTemplatePriceAlert,
+		EmailTemplateAlertApproved,
+		EmailTemplateStationBroadcast,
+		EmailTemplateAccountLocked,
+		EmailTemplateMagicLink,
+		EmailTemplateOwnerVerificationReminder,
+		EmailTemplateOwnerVerificationLapsed,
+		EmailTemplateOwnerTeamInvitation,
+	} {
+		if _, ok := t.emails[name]; !ok {
+			return nil, fmt.Errorf("email template %s.html is missing", name)
+		}
+	}
+	return t, nil
+}
+
+func emailTemplateFuncs(l i18n.Localizer) template.FuncMap {
+	return template.FuncMap{
+		// Messages may contain markup such as <strong>, so only the
+		// parameter values are escaped.
+		"t": func(key string, args ...any) template.HTML {
+			pairs := make([]string, len(args))
+			for i, arg := range args {
+				pairs[i] = fmt.Sprint(arg)
+				if i%2 == 1 {
+					pairs[i] = template.HTMLEscapeString(pairs[i])
+				}
+			}
+			return template.HTML(l.T(key, pairs...))
+		},
+		"price": l.FormatPrice,
+	}
+}
+
+// Render renders the subject and the HTML and plain-text bodies of email name
+// in locale. ctaURL is the button link, for emails that have one, and
+// unsubscribe lists the unsubscribe links for the footer.
+func (t *EmailTemplates) Render(name, locale, ctaURL string, data any, unsubscribe ...emailUnsubscribeLink) (renderedEmail, error) {
+	base, ok := t.emails[name]
+	if !ok {
+		return renderedEmail{}, fmt.Errorf("unknown email template %q", name)
+	}
+	l := t.catalog.Localizer(locale)
+
+	tmpl, err := base.Clone()
+	if err != nil {
+		return renderedEmail{}, fmt.Errorf("failed to render email template %s: %w", name, err)
+	}
+	tmpl.Funcs(emailTemplateFuncs(l))
+
+	execute := func(block string, data any) (string, error) {
+		if tmpl.Lookup(block) == nil {
+			return "", nil
+		}
+		var buf bytes.Buffer
+		if err := tmpl.ExecuteTemplate(&buf, block, data); err != nil {
+			return "", fmt.Errorf("failed to render email template %s: %w", name, err)
+		}
+		return strings.TrimSpace(buf.String()), nil
+	}
+
+	parts := make(map[string]string)
+	for _, block := range []string{"subject", "heading", "body", "cta"} {
+		if parts[block], err = execute(block, data); err != nil {
+			return renderedEmail{}, err
+		}
+	}
+
+	// Subject, heading and button text are plain text; the layout escapes
+	// the heading and button text again.
+	email := EmailData{
+		Locale:     l.Locale(),
+		Heading:    html.UnescapeString(parts["heading"]),
+		Body:       template.HTML(parts["body"]),
+		FooterText: l.T("email.layout.footer"),
+		Copyright:  l.T("email.layout.copyright", "year", strconv.Itoa(time.Now().Year())),
+	}
+	if parts["cta"] != "" {
+		email.CTAText = html.UnescapeString(parts["cta"])
+		email.CTAURL = ctaURL
+	}
+	for _, link := range unsubscribe {
+		email.Unsubscribe = append(email.Unsubscribe, EmailLink{
+			Text: l.T("email.unsubscribe." + link.Category),
+			URL:  link.URL,
+		})
+	}
+
+	htmlBody, err := execute("layout", email)
+	if err != nil {
+		return renderedEmail{}, err
+	}
+	return renderedEmail{
+		Subject: html.UnescapeString(parts["subject"]),
+		HTML:    htmlBody,
+		Text:    renderEmailText(email),
+	}, nil
+}
+
+var (
+	interTagSpacePattern = regexp.MustCompile(`>\s*\n\s*<`)
+	htmlBreakPattern     = regexp.MustCompile(`(?i)<br\s*/?>`)
+	htmlBlockEndPattern  = regexp.MustCompile(`(?i)</(p|div|table|h[1-6])>`)
+	htmlLineEndPattern   = regexp.MustCompile(`(?i)</(tr|li)>`)
+	htmlCellEndPattern   = regexp.MustCompile(`(?i)</t[dh]>`)
+	htmlTagPattern       = regexp.MustCompile(`<[^>]*>`)
+	spaceRunPattern      = regexp.MustCompile(`[ \t]+`)
+	blankLineRunsPattern = regexp.MustCompile(`\n{3,}`)
+)
+
+// renderEmailText renders the plain-text alternative of an email from the same
+// data as the HTML version.
+func renderEmailText(data EmailData) string {
+	var b strings.Builder
+	b.WriteString(data.Heading + "\n\n")
+	b.WriteString(htmlToText(string(data.Body)) + "\n")
+	if data.CTAText != "" {
+		b.WriteString("\n" + data.CTAText + ": " + data.CTAURL + "\n")
+	}
+	b.WriteString("\n--\n" + data.FooterText + "\n")
+	for _, link := range data.Unsubscribe {
+		b.WriteString(link.Text + ": " + link.URL + "\n")
+	}
+	b.WriteString(data.Copyright + "\n")
+	return b.String()
+}
+
+// htmlToText converts the simple inline HTML used in email bodies to text:
+// blocks become paragraphs, table rows become lines, cells are separated by
+// spaces and other tags are dropped.
+func htmlToText(s string) string {
+	s = interTagSpacePattern.ReplaceAllString(s, "><")
+	s = htmlBreakPattern.ReplaceAllString(s, "\n")
+	s = htmlBlockEndPattern.ReplaceAllString(s, "\n\n")
+	s = htmlLineEndPattern.ReplaceAllString(s, "\n")
+	s = htmlCellEndPattern.ReplaceAllString(s, " ")
+	s = htmlTagPattern.ReplaceAllString(s, "")
+	s = html.UnescapeString(s)
+
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(spaceRunPattern.ReplaceAllString(line, " "))
+	}
+	s = strings.Join(lines, "\n")
+	s = blankLineRunsPattern.ReplaceAllString(s, "\n\n")
+	return strings.TrimSpace(s)
 }
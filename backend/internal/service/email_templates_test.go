@@ -0,0 +1,148 @@
+package service
+
+import (
+	"fmt"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"gaspeep/backend/internal/i18n"
+	"gaspeep/backend/internal/models"
+	"gaspeep/backend/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func loadTestEmailTemplates(t *testing.T) *EmailTemplates {
+	t.Helper()
+	templates, err := LoadEmailTemplates(i18n.Embedded())
+	require.NoError(t, err)
+	return templates
+}
+
+// newTestEmailService returns an EmailService whose users have no preferred
+// locale and have not unsubscribed from anything.
+func newTestEmailService(t *testing.T, outboxRepo repository.EmailOutboxRepository, verification EmailVerificationPolicy) EmailService {
+	userRepo := new(MockUserRepositoryForVerification)
+	userRepo.On("GetUserByID", mock.Anything).Return(&models.User{}, nil).Maybe()
+	unsubscribeRepo := new(MockEmailUnsubscribeRepository)
+	unsubscribeRepo.On("IsUnsubscribed", mock.Anything, mock.Anything).Return(false, nil).Maybe()
+	return NewEmailService(outboxRepo, userRepo, verification, newTestUnsubscribeService(t, unsubscribeRepo), loadTestEmailTemplates(t))
+}
+
+var testPriceAlertData = struct {
+	AlertName   string
+	StationName string
+	FuelType    string
+	Price       float64
+	Currency    string
+}{"Cheap & close", "Station A", "U91", 179.9, "AUD"}
+
+func TestEmailTemplatesRender_PriceAlert(t *testing.T) {
+	templates := loadTestEmailTemplates(t)
+
+	email, err := templates.Render(EmailTemplatePriceAlert, "en-AU", "https://gaspeep.com/stations", testPriceAlertData)
+	require.NoError(t, err)
+
+	assert.Equal(t, "Gas Peep: Cheap & close price alert", email.Subject)
+	assert.Contains(t, email.HTML, `<html lang="en-AU">`)
+	assert.Contains(t, email.HTML, "<strong>Cheap &amp; close</strong>")
+	assert.Contains(t, email.HTML, "179.9c/L")
+	assert.Equal(t, "Price Alert Triggered\n\n"+
+		"Great news! A fuel price matching your alert Cheap & close was reported:\n\n"+
+		"Station Station A\nFuel Type U91\nPrice 179.9c/L\n\n"+
+		"View Station: https://gaspeep.com/stations\n\n"+
+		"--\nThis email was sent by Gas Peep. If you didn't expect this email, you can safely ignore it.\n"+
+		fmt.Sprintf("© %d Gas Peep. All rights reserved.\n", time.Now().Year()), email.Text)
+}
+
+func TestEmailTemplatesRender_Localized(t *testing.T) {
+	templates := loadTestEmailTemplates(t)
+
+	email, err := templates.Render(EmailTemplatePriceAlert, "zh-CN", "https://gaspeep.com/stations", testPriceAlertData)
+	require.NoError(t, err)
+	assert.Equal(t, "Gas Peep：Cheap & close 价格提醒", email.Subject)
+	assert.Contains(t, email.HTML, `<html lang="zh-CN">`)
+	assert.Contains(t, email.HTML, "179.9 澳分/升")
+	assert.Contains(t, email.Text, "查看加油站: https://gaspeep.com/stations")
+
+	email, err = templates.Render(EmailTemplatePriceAlert, "en-US", "", testPriceAlertData)
+	require.NoError(t, err)
+	assert.Contains(t, email.HTML, "A$1.799/L")
+
+	// Unknown locales get the default
+	email, err = templates.Render(EmailTemplatePasswordChanged, "fr-FR", "", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "Your Gas Peep password was changed", email.Subject)
+	assert.NotContains(t, email.Text, ": \n")
+}
+
+func TestEmailTemplatesRender_OwnerVerificationReminder(t *testing.T) {
+	templates := loadTestEmailTemplates(t)
+
+	email, err := templates.Render(EmailTemplateOwnerVerificationReminder, "en-AU", "https://gaspeep.com/station-owner", struct {
+		StationName string
+		ExpiresOn   string
+		Days        int
+	}{"Acme <Fuel>", "2026-04-01", 7})
+	require.NoError(t, err)
+	assert.Equal(t, "Re-verify your ownership of Acme <Fuel>", email.Subject)
+	assert.Contains(t, email.HTML, "<strong>Acme &lt;Fuel&gt;</strong> expires on 2026-04-01, 7 day(s) from now")
+	assert.Contains(t, email.Text, "Re-verify Station: https://gaspeep.com/station-owner")
+}
+
+func TestEmailTemplatesRender_UnsubscribeLinks(t *testing.T) {
+	templates := loadTestEmailTemplates(t)
+
+	email, err := templates.Render(EmailTemplatePriceAlert, "en-AU", "https://gaspeep.com/stations", testPriceAlertData,
+		emailUnsubscribeLink{Category: repository.UnsubscribeCategoryAlert, URL: "https://api.gaspeep.com/u?token=a&b"},
+		emailUnsubscribeLink{Category: repository.UnsubscribeCategoryAllAlerts, URL: "https://api.gaspeep.com/u?token=all"},
+	)
+	require.NoError(t, err)
+
+	assert.Contains(t, email.HTML, `<a href="https://api.gaspeep.com/u?token=a&amp;b"`)
+	assert.Contains(t, email.HTML, ">Unsubscribe from all price alert emails</a>")
+	assert.Contains(t, email.Text, "Unsubscribe from this alert: https://api.gaspeep.com/u?token=a&b\n"+
+		"Unsubscribe from all price alert emails: https://api.gaspeep.com/u?token=all\n")
+}
+
+func TestLoadEmailTemplates_Validates(t *testing.T) {
+	layout := &fstest.MapFile{Data: []byte(`{{define "layout"}}{{.Body}}{{end}}`)}
+	complete := &fstest.MapFile{Data: []byte(`{{define "subject"}}s{{end}}{{define "heading"}}h{{end}}{{define "body"}}b{{end}}`)}
+
+	fsys := fstest.MapFS{"layout.html": layout}
+	for _, name := range []string{EmailTemplatePasswordReset, EmailTemplatePasswordChanged, EmailTemplateEmailVerification, EmailTemplateWelcome, EmailTemplatePriceAlert, EmailTemplateStationBroadcast, EmailTemplateAccountLocked, EmailTemplateMagicLink, EmailTemplateOwnerVerificationReminder, EmailTemplateOwnerVerificationLapsed, EmailTemplateOwnerTeamInvitation} {
+		fsys[name+".html"] = complete
+	}
+	_, err := loadEmailTemplates(fsys, i18n.Embedded())
+	assert.ErrorContains(t, err, "alert_approved.html is missing")
+
+	fsys[EmailTemplateAlertApproved+".html"] = &fstest.MapFile{Data: []byte(`{{define "subject"}}s{{end}}`)}
+	_, err = loadEmailTemplates(fsys, i18n.Embedded())
+	assert.ErrorContains(t, err, `does not define "heading"`)
+
+	fsys[EmailTemplateAlertApproved+".html"] = complete
+	templates, err := loadEmailTemplates(fsys, i18n.Embedded())
+	require.NoError(t, err)
+	email, err := templates.Render(EmailTemplateAlertApproved, "en-AU", "", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "s", email.Subject)
+	assert.Equal(t, "b", email.HTML)
+}
+
+func TestEmailService_SendsInUserLocale(t *testing.T) {
+	outboxRepo := new(MockEmailOutboxRepository)
+	userRepo := new(MockUserRepositoryForVerification)
+	service := NewEmailService(outboxRepo, userRepo, allowAllEmailVerification{}, nil, loadTestEmailTemplates(t))
+
+	userRepo.On("GetUserByID", "user-1").Return(&models.User{ID: "user-1", Locale: "zh-CN"}, nil)
+	outboxRepo.On("Enqueue", mock.MatchedBy(func(input repository.EnqueueEmailInput) bool {
+		return input.Subject == "您的 Gas Peep 提醒已生效"
+	})).Return("msg-1", nil)
+
+	err := service.SendAlertApproved("user-1", "a@example.com", "Cheap fuel")
+
+	require.NoError(t, err)
+	outboxRepo.AssertExpectations(t)
+}
@@ -0,0 +1,144 @@
+package service
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	"gaspeep/backend/internal/repository"
+)
+
+var (
+	ErrEmailUnsubscribed       = errors.New("recipient has unsubscribed from these emails")
+	ErrInvalidUnsubscribeToken = errors.New("invalid unsubscribe token")
+)
+
+// unsubscribeCategories are the categories an unsubscribe token may name.
+var unsubscribeCategories = map[string]bool{
+	repository.UnsubscribeCategoryAlert:             true,
+	repository.UnsubscribeCategoryAllAlerts:         true,
+	repository.UnsubscribeCategoryStationBroadcasts: true,
+	repository.UnsubscribeCategoryAllBroadcasts:     true,
+}
+
+// EmailUnsubscribe is the opt-out an unsubscribe token stands for.
+type EmailUnsubscribe struct {
+	UserID string
+	Scope  repository.UnsubscribeScope
+}
+
+// EmailUnsubscribeService issues and redeems unsubscribe links. Tokens are
+// signed rather than stored, so links in old emails keep working.
+type EmailUnsubscribeService interface {
+	// URL returns the one-click unsubscribe URL that opts userID out of scope.
+	URL(userID string, scope repository.UnsubscribeScope) string
+	// IsUnsubscribed reports whether the user has opted out of any of scopes.
+	IsUnsubscribed(userID string, scopes ...repository.UnsubscribeScope) (bool, error)
+	// Lookup returns the opt-out a token stands for without applying it.
+	Lookup(token string) (*EmailUnsubscribe, error)
+	// Unsubscribe applies the opt-out a token stands for. method is one of the
+	// repository.UnsubscribeMethod* values.
+	Unsubscribe(token, method string) (*EmailUnsubscribe, error)
+}
+
+type emailUnsubscribeService struct {
+	unsubscribeRepo repository.EmailUnsubscribeRepository
+	secret          []byte
+}
+
+// NewEmailUnsubscribeService signs tokens with EMAIL_UNSUBSCRIBE_SECRET, which
+// must be set in production. Elsewhere it falls back to JWT_SECRET and then to
+// a generated secret, so links stop working when the process restarts.
+func NewEmailUnsubscribeService(unsubscribeRepo repository.EmailUnsubscribeRepository) (EmailUnsubscribeService, error) {
+	secret := []byte(os.Getenv("EMAIL_UNSUBSCRIBE_SECRET"))
+	if len(secret) == 0 && os.Getenv("ENV") == "production" {
+		return nil, errors.New("EMAIL_UNSUBSCRIBE_SECRET must be set in production")
+	}
+	if len(secret) == 0 {
+		secret = []byte(os.Getenv("JWT_SECRET"))
+	}
+	if len(secret) == 0 {
+		secret = make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			return nil, fmt.Errorf("failed to generate unsubscribe secret: %w", err)
+		}
+	}
+	return &emailUnsubscribeService{unsubscribeRepo: unsubscribeRepo, secret: secret}, nil
+}
+
+func (s *emailUnsubscribeService) URL(userID string, scope repository.UnsubscribeScope) string {
+	return apiURL("/api/email/unsubscribe?token=" + url.QueryEscape(s.sign(userID, scope)))
+}
+
+func (s *emailUnsubscribeService) IsUnsubscribed(userID string, scopes ...repository.UnsubscribeScope) (bool, error) {
+	return s.unsubscribeRepo.IsUnsubscribed(userID, scopes...)
+}
+
+func (s *emailUnsubscribeService) Lookup(token string) (*EmailUnsubscribe, error) {
+	return s.parse(token)
+}
+
+func (s *emailUnsubscribeService) Unsubscribe(token, method string) (*EmailUnsubscribe, error) {
+	unsubscribe, err := s.parse(token)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.unsubscribeRepo.Record(unsubscribe.UserID, unsubscribe.Scope, method); err != nil {
+		return nil, err
+	}
+	return unsubscribe, nil
+}
+
+// sign returns a token of the form payload.signature, both base64url encoded,
+// where payload is "userID:category:scopeID".
+func (s *emailUnsubscribeService) sign(userID string, scope repository.UnsubscribeScope) string {
+	payload := userID + ":" + scope.Category + ":" + scope.ScopeID
+	return base64.RawURLEncoding.EncodeToString([]byte(payload)) + "." +
+		base64.RawURLEncoding.EncodeToString(s.mac(payload))
+}
+
+func (s *emailUnsubscribeService) parse(token string) (*EmailUnsubscribe, error) {
+	encodedPayload, encodedSig, ok := strings.Cut(token, ".")
+	if !ok {
+		return nil, ErrInvalidUnsubscribeToken
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encodedPayload)
+	if err != nil {
+		return nil, ErrInvalidUnsubscribeToken
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(encodedSig)
+	if err != nil || !hmac.Equal(sig, s.mac(string(payload))) {
+		return nil, ErrInvalidUnsubscribeToken
+	}
+
+	parts := strings.Split(string(payload), ":")
+	if len(parts) != 3 || parts[0] == "" || !unsubscribeCategories[parts[1]] {
+		return nil, ErrInvalidUnsubscribeToken
+	}
+	return &EmailUnsubscribe{
+		UserID: parts[0],
+		Scope:  repository.UnsubscribeScope{Category: parts[1], ScopeID: parts[2]},
+	}, nil
+}
+
+func (s *emailUnsubscribeService) mac(payload string) []byte {
+	h := hmac.New(sha256.New, s.secret)
+	h.Write([]byte(payload))
+	return h.Sum(nil)
+}
+
+// apiURL returns path on the API at API_BASE_URL, falling back to APP_BASE_URL
+// for deployments that serve both from one origin.
+func apiURL(path string) string {
+	base := os.Getenv("API_BASE_URL")
+	if base == "" {
+		base = os.Getenv("APP_BASE_URL")
+	}
+	return strings.TrimRight(base, "/") + path
+}
@@ -0,0 +1,138 @@
+package service
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+
+	"gaspeep/backend/internal/models"
+	"gaspeep/backend/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockEmailUnsubscribeRepository is a mock implementation of EmailUnsubscribeRepository
+type MockEmailUnsubscribeRepository struct {
+	mock.Mock
+}
+
+func (m *MockEmailUnsubscribeRepository) Record(userID string, scope repository.UnsubscribeScope, method string) error {
+	args := m.Called(userID, scope, method)
+	return args.Error(0)
+}
+
+func (m *MockEmailUnsubscribeRepository) IsUnsubscribed(userID string, scopes ...repository.UnsubscribeScope) (bool, error) {
+	args := m.Called(userID, scopes)
+	return args.Bool(0), args.Error(1)
+}
+
+// unsubscribeToken extracts the token from an unsubscribe URL.
+func unsubscribeToken(t *testing.T, rawURL string) string {
+	t.Helper()
+	u, err := url.Parse(rawURL)
+	require.NoError(t, err)
+	return u.Query().Get("token")
+}
+
+func newTestUnsubscribeService(t *testing.T, unsubscribeRepo repository.EmailUnsubscribeRepository) EmailUnsubscribeService {
+	t.Helper()
+	service, err := NewEmailUnsubscribeService(unsubscribeRepo)
+	require.NoError(t, err)
+	return service
+}
+
+func TestEmailUnsubscribeService_TokenRoundTrip(t *testing.T) {
+	t.Setenv("EMAIL_UNSUBSCRIBE_SECRET", "secret-1")
+	t.Setenv("API_BASE_URL", "https://api.example.com/")
+	unsubscribeRepo := new(MockEmailUnsubscribeRepository)
+	service := newTestUnsubscribeService(t, unsubscribeRepo)
+
+	scope := repository.UnsubscribeScope{Category: repository.UnsubscribeCategoryAlert, ScopeID: "alert-1"}
+	link := service.URL("user-1", scope)
+	assert.True(t, strings.HasPrefix(link, "https://api.example.com/api/email/unsubscribe?token="), link)
+	token := unsubscribeToken(t, link)
+
+	unsubscribe, err := service.Lookup(token)
+	require.NoError(t, err)
+	assert.Equal(t, &EmailUnsubscribe{UserID: "user-1", Scope: scope}, unsubscribe)
+
+	unsubscribeRepo.On("Record", "user-1", scope, repository.UnsubscribeMethodOneClick).Return(nil).Once()
+	_, err = service.Unsubscribe(token, repository.UnsubscribeMethodOneClick)
+	require.NoError(t, err)
+	unsubscribeRepo.AssertExpectations(t)
+
+	// Tokens signed with another secret, or edited, are rejected
+	t.Setenv("EMAIL_UNSUBSCRIBE_SECRET", "secret-2")
+	_, err = newTestUnsubscribeService(t, unsubscribeRepo).Lookup(token)
+	assert.ErrorIs(t, err, ErrInvalidUnsubscribeToken)
+
+	payload, sig, _ := strings.Cut(token, ".")
+	for _, tampered := range []string{"", "garbage", payload, payload + ".", payload[1:] + "." + sig} {
+		_, err = service.Unsubscribe(tampered, repository.UnsubscribeMethodLink)
+		assert.ErrorIs(t, err, ErrInvalidUnsubscribeToken, tampered)
+	}
+	unsubscribeRepo.AssertNumberOfCalls(t, "Record", 1)
+}
+
+func TestEmailUnsubscribeService_SecretRequiredInProduction(t *testing.T) {
+	t.Setenv("ENV", "production")
+	t.Setenv("EMAIL_UNSUBSCRIBE_SECRET", "")
+	t.Setenv("JWT_SECRET", "jwt-secret")
+
+	_, err := NewEmailUnsubscribeService(new(MockEmailUnsubscribeRepository))
+	assert.Error(t, err)
+
+	t.Setenv("EMAIL_UNSUBSCRIBE_SECRET", "secret-1")
+	_, err = NewEmailUnsubscribeService(new(MockEmailUnsubscribeRepository))
+	assert.NoError(t, err)
+}
+
+func TestEmailService_PriceAlertHonoursUnsubscribes(t *testing.T) {
+	t.Setenv("API_BASE_URL", "https://api.example.com")
+	outboxRepo := new(MockEmailOutboxRepository)
+	userRepo := new(MockUserRepositoryForVerification)
+	userRepo.On("GetUserByID", mock.Anything).Return(&models.User{}, nil)
+	unsubscribeRepo := new(MockEmailUnsubscribeRepository)
+	unsubscribes := newTestUnsubscribeService(t, unsubscribeRepo)
+	service := NewEmailService(outboxRepo, userRepo, allowAllEmailVerification{}, unsubscribes, loadTestEmailTemplates(t))
+
+	alertScope := repository.UnsubscribeScope{Category: repository.UnsubscribeCategoryAlert, ScopeID: "alert-1"}
+	allAlerts := repository.UnsubscribeScope{Category: repository.UnsubscribeCategoryAllAlerts}
+	unsubscribeRepo.On("IsUnsubscribed", "user-1", []repository.UnsubscribeScope{alertScope, allAlerts}).Return(false, nil)
+	unsubscribeRepo.On("IsUnsubscribed", "user-2", []repository.UnsubscribeScope{{Category: repository.UnsubscribeCategoryAlert, ScopeID: "alert-2"}, allAlerts}).Return(true, nil)
+
+	var queued repository.EnqueueEmailInput
+	outboxRepo.On("Enqueue", mock.Anything).Run(func(args mock.Arguments) {
+		queued = args.Get(0).(repository.EnqueueEmailInput)
+	}).Return("msg-1", nil).Once()
+
+	err := service.SendPriceAlert("user-1", "alert-1", "a@example.com", "Cheap", "Station A", "U91", 179.9, "AUD")
+	require.NoError(t, err)
+
+	// The header opts out of this alert; the footer also offers all alerts
+	unsubscribe, err := unsubscribes.Lookup(unsubscribeToken(t, queued.ListUnsubscribeURL))
+	require.NoError(t, err)
+	assert.Equal(t, alertScope, unsubscribe.Scope)
+	assert.Contains(t, queued.TextBody, "Unsubscribe from all price alert emails: https://api.example.com/api/email/unsubscribe?token=")
+
+	err = service.SendPriceAlert("user-2", "alert-2", "b@example.com", "Cheap", "Station A", "U91", 179.9, "AUD")
+	assert.ErrorIs(t, err, ErrEmailUnsubscribed)
+	outboxRepo.AssertNumberOfCalls(t, "Enqueue", 1)
+}
+
+func TestEmailService_ListUnsubscribeNeedsHTTPS(t *testing.T) {
+	t.Setenv("API_BASE_URL", "http://localhost:8080")
+	outboxRepo := new(MockEmailOutboxRepository)
+	service := newTestEmailService(t, outboxRepo, allowAllEmailVerification{})
+
+	outboxRepo.On("Enqueue", mock.MatchedBy(func(input repository.EnqueueEmailInput) bool {
+		return input.ListUnsubscribeURL == "" &&
+			strings.Contains(input.TextBody, "Unsubscribe from this station's messages: http://localhost:8080/api/email/unsubscribe?token=")
+	})).Return("msg-1", nil)
+
+	err := service.SendStationBroadcast("user-1", "station-1", "a@example.com", "Station A", "Half price car wash", "This weekend only.")
+
+	require.NoError(t, err)
+	outboxRepo.AssertExpectations(t)
+}
@@ -0,0 +1,151 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"gaspeep/backend/internal/repository"
+)
+
+const (
+	emailVerificationTTL         = 24 * time.Hour
+	emailVerificationResendDelay = time.Minute
+	emailVerificationDailyLimit  = 5
+)
+
+var (
+	ErrEmailNotVerified             = errors.New("email address not verified")
+	ErrEmailAlreadyVerified         = errors.New("email address already verified")
+	ErrInvalidVerificationToken     = errors.New("invalid or expired verification token")
+	ErrVerificationEmailRateLimited = errors.New("too many verification emails requested")
+)
+
+// EmailVerificationPolicy is the hook other services use to restrict features
+// to users who have verified their email address.
+type EmailVerificationPolicy interface {
+	// RequireVerifiedEmail returns ErrEmailNotVerified if the user has not
+	// verified their email address.
+	RequireVerifiedEmail(userID string) error
+}
+
+type emailVerificationPolicy struct {
+	userRepo repository.UserRepository
+	required bool
+}
+
+// NewEmailVerificationPolicy enforces verification unless
+// EMAIL_VERIFICATION_REQUIRED is "false".
+func NewEmailVerificationPolicy(userRepo repository.UserRepository) EmailVerificationPolicy {
+	return &emailVerificationPolicy{
+		userRepo: userRepo,
+		required: !strings.EqualFold(strings.TrimSpace(os.Getenv("EMAIL_VERIFICATION_REQUIRED")), "false"),
+	}
+}
+
+func (p *emailVerificationPolicy) RequireVerifiedEmail(userID string) error {
+	if !p.required {
+		return nil
+	}
+	user, err := p.userRepo.GetUserByID(userID)
+	if err != nil {
+		return fmt.Errorf("failed to check email verification: %w", err)
+	}
+	if !user.EmailVerified {
+		return ErrEmailNotVerified
+	}
+	return nil
+}
+
+// EmailVerificationService issues and redeems email verification links.
+type EmailVerificationService interface {
+	// SendVerification emails the user a new verification link.
+	SendVerification(userID string) error
+	// Verify redeems a token from a verification link and sends the welcome
+	// email the first time an address is verified.
+	Verify(token string) error
+}
+
+type emailVerificationService struct {
+	verificationRepo repository.EmailVerificationRepository
+	userRepo         repository.UserRepository
+	emailService     EmailService
+}
+
+func NewEmailVerificationService(
+	verificationRepo repository.EmailVerificationRepository,
+	userRepo repository.UserRepository,
+	emailService EmailService,
+) EmailVerificationService {
+	return &emailVerificationService{
+		verificationRepo: verificationRepo,
+		userRepo:         userRepo,
+		emailService:     emailService,
+	}
+}
+
+func (s *emailVerificationService) SendVerification(userID string) error {
+	user, err := s.userRepo.GetUserByID(userID)
+	if err != nil {
+		return err
+	}
+	if user.EmailVerified {
+		return ErrEmailAlreadyVerified
+	}
+
+	now := time.Now()
+	issued, latest, err := s.verificationRepo.CountIssuedSince(userID, now.Add(-24*time.Hour))
+	if err != nil {
+		return err
+	}
+	if issued >= emailVerificationDailyLimit || (latest != nil && now.Sub(*latest) < emailVerificationResendDelay) {
+		return ErrVerificationEmailRateLimited
+	}
+
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Errorf("failed to generate verification token: %w", err)
+	}
+	token := hex.EncodeToString(b)
+
+	if err := s.verificationRepo.Create(userID, user.Email, hashVerificationToken(token), now.Add(emailVerificationTTL)); err != nil {
+		return err
+	}
+
+	return s.emailService.SendEmailVerification(userID, user.Email, appURL("/auth/verify-email?token="+url.QueryEscape(token)))
+}
+
+func (s *emailVerificationService) Verify(token string) error {
+	verified, err := s.verificationRepo.Consume(hashVerificationToken(token))
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrInvalidVerificationToken
+	}
+	if err != nil {
+		return err
+	}
+
+	if !verified.WasVerified {
+		if err := s.emailService.SendWelcome(verified.UserID, verified.Email, verified.DisplayName); err != nil {
+			log.Printf("warning: failed to queue welcome email for user %s: %v", verified.UserID, err)
+		}
+	}
+	return nil
+}
+
+func hashVerificationToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// appURL returns path on the frontend at APP_BASE_URL, or path alone when it is unset.
+func appURL(path string) string {
+	return strings.TrimRight(os.Getenv("APP_BASE_URL"), "/") + path
+}
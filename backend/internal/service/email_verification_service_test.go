@@ -0,0 +1,240 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"gaspeep/backend/internal/models"
+	"gaspeep/backend/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockEmailVerificationRepository is a mock implementation of EmailVerificationRepository
+type MockEmailVerificationRepository struct {
+	mock.Mock
+}
+
+func (m *MockEmailVerificationRepository) Create(userID, email, tokenHash string, expiresAt time.Time) error {
+	args := m.Called(userID, email, tokenHash, expiresAt)
+	return args.Error(0)
+}
+
+func (m *MockEmailVerificationRepository) CountIssuedSince(userID string, since time.Time) (int, *time.Time, error) {
+	args := m.Called(userID, since)
+	var latest *time.Time
+	if args.Get(1) != nil {
+		latest = args.Get(1).(*time.Time)
+	}
+	return args.Int(0), latest, args.Error(2)
+}
+
+func (m *MockEmailVerificationRepository) Consume(tokenHash string) (*repository.ConsumedEmailVerification, error) {
+	args := m.Called(tokenHash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*repository.ConsumedEmailVerification), args.Error(1)
+}
+
+func (m *MockEmailVerificationRepository) DeleteExpiredBefore(cutoff time.Time) (int64, error) {
+	args := m.Called(cutoff)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+// MockUserRepositoryForVerification mocks the user lookups made by email
+// verification. Other UserRepository methods are not implemented.
+type MockUserRepositoryForVerification struct {
+	mock.Mock
+	repository.UserRepository
+}
+
+func (m *MockUserRepositoryForVerification) GetUserByID(id string) (*models.User, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.User), args.Error(1)
+}
+
+// MockEmailServiceForVerification mocks the emails sent by email verification.
+// Other EmailService methods are not implemented.
+type MockEmailServiceForVerification struct {
+	mock.Mock
+	EmailService
+}
+
+func (m *MockEmailServiceForVerification) SendEmailVerification(userID, toEmail, verificationURL string) error {
+	args := m.Called(userID, toEmail, verificationURL)
+	return args.Error(0)
+}
+
+func (m *MockEmailServiceForVerification) SendWelcome(userID, toEmail, displayName string) error {
+	args := m.Called(userID, toEmail, displayName)
+	return args.Error(0)
+}
+
+func setupEmailVerificationTest() (EmailVerificationService, *MockEmailVerificationRepository, *MockUserRepositoryForVerification, *MockEmailServiceForVerification) {
+	verificationRepo := new(MockEmailVerificationRepository)
+	userRepo := new(MockUserRepositoryForVerification)
+	emailService := new(MockEmailServiceForVerification)
+	return NewEmailVerificationService(verificationRepo, userRepo, emailService), verificationRepo, userRepo, emailService
+}
+
+func TestSendVerification_IssuesHashedToken(t *testing.T) {
+	t.Setenv("APP_BASE_URL", "https://app.example.com/")
+	service, verificationRepo, userRepo, emailService := setupEmailVerificationTest()
+
+	userRepo.On("GetUserByID", "user-1").Return(&models.User{ID: "user-1", Email: "a@example.com"}, nil)
+	verificationRepo.On("CountIssuedSince", "user-1", mock.Anything).Return(0, nil, nil)
+
+	var storedHash string
+	verificationRepo.On("Create", "user-1", "a@example.com", mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) { storedHash = args.String(2) }).
+		Return(nil)
+
+	var link string
+	emailService.On("SendEmailVerification", "user-1", "a@example.com", mock.Anything).
+		Run(func(args mock.Arguments) { link = args.String(2) }).
+		Return(nil)
+
+	err := service.SendVerification("user-1")
+
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(link, "https://app.example.com/auth/verify-email?token="))
+	token := strings.TrimPrefix(link, "https://app.example.com/auth/verify-email?token=")
+	assert.Len(t, token, 64)
+	assert.Equal(t, hashVerificationToken(token), storedHash)
+	assert.NotEqual(t, token, storedHash)
+}
+
+func TestSendVerification_AlreadyVerified(t *testing.T) {
+	service, verificationRepo, userRepo, _ := setupEmailVerificationTest()
+
+	userRepo.On("GetUserByID", "user-1").Return(&models.User{ID: "user-1", Email: "a@example.com", EmailVerified: true}, nil)
+
+	err := service.SendVerification("user-1")
+
+	assert.ErrorIs(t, err, ErrEmailAlreadyVerified)
+	verificationRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestSendVerification_RateLimited(t *testing.T) {
+	recent := time.Now().Add(-10 * time.Second)
+	earlier := time.Now().Add(-time.Hour)
+
+	tests := []struct {
+		name   string
+		issued int
+		latest *time.Time
+	}{
+		{name: "requested too recently", issued: 1, latest: &recent},
+		{name: "daily limit reached", issued: emailVerificationDailyLimit, latest: &earlier},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, verificationRepo, userRepo, _ := setupEmailVerificationTest()
+
+			userRepo.On("GetUserByID", "user-1").Return(&models.User{ID: "user-1", Email: "a@example.com"}, nil)
+			verificationRepo.On("CountIssuedSince", "user-1", mock.Anything).Return(tt.issued, tt.latest, nil)
+
+			err := service.SendVerification("user-1")
+
+			assert.ErrorIs(t, err, ErrVerificationEmailRateLimited)
+			verificationRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestVerify_FirstVerificationSendsWelcome(t *testing.T) {
+	service, verificationRepo, _, emailService := setupEmailVerificationTest()
+
+	verificationRepo.On("Consume", hashVerificationToken("token")).Return(&repository.ConsumedEmailVerification{
+		UserID: "user-1", Email: "a@example.com", DisplayName: "Alice",
+	}, nil)
+	emailService.On("SendWelcome", "user-1", "a@example.com", "Alice").Return(nil)
+
+	err := service.Verify("token")
+
+	require.NoError(t, err)
+	emailService.AssertExpectations(t)
+}
+
+func TestVerify_AlreadyVerifiedSkipsWelcome(t *testing.T) {
+	service, verificationRepo, _, emailService := setupEmailVerificationTest()
+
+	verificationRepo.On("Consume", hashVerificationToken("token")).Return(&repository.ConsumedEmailVerification{
+		UserID: "user-1", Email: "a@example.com", DisplayName: "Alice", WasVerified: true,
+	}, nil)
+
+	err := service.Verify("token")
+
+	require.NoError(t, err)
+	emailService.AssertNotCalled(t, "SendWelcome", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestVerify_InvalidToken(t *testing.T) {
+	service, verificationRepo, _, _ := setupEmailVerificationTest()
+
+	verificationRepo.On("Consume", hashVerificationToken("token")).Return(nil, sql.ErrNoRows)
+
+	err := service.Verify("token")
+
+	assert.ErrorIs(t, err, ErrInvalidVerificationToken)
+}
+
+func TestEmailVerificationPolicy(t *testing.T) {
+	userRepo := new(MockUserRepositoryForVerification)
+	userRepo.On("GetUserByID", "verified").Return(&models.User{ID: "verified", EmailVerified: true}, nil)
+	userRepo.On("GetUserByID", "unverified").Return(&models.User{ID: "unverified"}, nil)
+	userRepo.On("GetUserByID", "missing").Return(nil, errors.New("user not found"))
+
+	t.Run("required", func(t *testing.T) {
+		policy := NewEmailVerificationPolicy(userRepo)
+
+		assert.NoError(t, policy.RequireVerifiedEmail("verified"))
+		assert.ErrorIs(t, policy.RequireVerifiedEmail("unverified"), ErrEmailNotVerified)
+		assert.Error(t, policy.RequireVerifiedEmail("missing"))
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		t.Setenv("EMAIL_VERIFICATION_REQUIRED", "false")
+		policy := NewEmailVerificationPolicy(userRepo)
+
+		assert.NoError(t, policy.RequireVerifiedEmail("unverified"))
+	})
+}
+
+// allowAllEmailVerification is an EmailVerificationPolicy that treats every
+// user as verified.
+type allowAllEmailVerification struct{}
+
+func (allowAllEmailVerification) RequireVerifiedEmail(userID string) error { return nil }
+
+// denyAllEmailVerification is an EmailVerificationPolicy that treats every
+// user as unverified.
+type denyAllEmailVerification struct{}
+
+func (denyAllEmailVerification) RequireVerifiedEmail(userID string) error { return ErrEmailNotVerified }
+
+func TestEmailService_UnverifiedUserOnlyGetsAccountEmails(t *testing.T) {
+	outboxRepo := new(MockEmailOutboxRepository)
+	service := newTestEmailService(t, outboxRepo, denyAllEmailVerification{})
+
+	outboxRepo.On("Enqueue", mock.MatchedBy(func(input repository.EnqueueEmailInput) bool {
+		return input.Template == EmailTemplatePasswordReset
+	})).Return("msg-1", nil)
+
+	err := service.SendPasswordReset("user-1", "a@example.com", "https://example.com/reset?token=abc")
+	require.NoError(t, err)
+
+	err = service.SendPriceAlert("user-1", "alert-1", "a@example.com", "Cheap fuel", "Station", "U91", 179.9, "AUD")
+	assert.ErrorIs(t, err, ErrEmailNotVerified)
+
+	outboxRepo.AssertNumberOfCalls(t, "Enqueue", 1)
+}
@@ -0,0 +1,167 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/textproto"
+	"sync"
+	"time"
+
+	"gaspeep/backend/internal/repository"
+)
+
+const (
+	emailWorkerBatchSize = 20
+	// emailWorkerLease is how long a worker holds a message. It is renewed
+	// just before each send, so each send gets the whole lease however long
+	// the messages before it took.
+	emailWorkerLease       = 5 * time.Minute
+	emailWorkerMaxAttempts = 8
+	emailWorkerBaseBackoff = time.Minute
+	emailWorkerMaxBackoff  = 6 * time.Hour
+)
+
+// EmailWorker delivers queued emails in the background, retrying failed sends
+// with exponential backoff.
+type EmailWorker struct {
+	outboxRepo repository.EmailOutboxRepository
+	sender     EmailSender
+
+	workers      int
+	pollInterval time.Duration
+}
+
+func NewEmailWorker(outboxRepo repository.EmailOutboxRepository, sender EmailSender) *EmailWorker {
+	workers := parseEnvInt("EMAIL_WORKER_COUNT", 2)
+	if workers < 1 {
+		workers = 1
+	}
+
+	pollSeconds := parseEnvInt("EMAIL_WORKER_POLL_SECONDS", 5)
+	if pollSeconds < 1 {
+		pollSeconds = 5
+	}
+
+	return &EmailWorker{
+		outboxRepo:   outboxRepo,
+		sender:       sender,
+		workers:      workers,
+		pollInterval: time.Duration(pollSeconds) * time.Second,
+	}
+}
+
+// Start launches the worker pool. It stops when ctx is cancelled.
+func (w *EmailWorker) Start(ctx context.Context) {
+	log.Printf("Email workers started (workers=%d poll=%s)", w.workers, w.pollInterval)
+
+	var wg sync.WaitGroup
+	for i := 0; i < w.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w.run(ctx)
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		log.Printf("Email workers stopped")
+	}()
+}
+
+func (w *EmailWorker) run(ctx context.Context) {
+	for {
+		processed, err := w.processBatch()
+		if err != nil {
+			log.Printf("Email worker failed to claim messages: %v", err)
+		}
+
+		// Keep draining while there is a backlog; otherwise wait for the next poll.
+		if processed == 0 || err != nil {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(w.pollInterval):
+			}
+			continue
+		}
+
+		if ctx.Err() != nil {
+			return
+		}
+	}
+}
+
+// processBatch claims a batch of messages and sends them, returning how many
+// were claimed.
+func (w *EmailWorker) processBatch() (int, error) {
+	messages, err := w.outboxRepo.Claim(emailWorkerBatchSize, emailWorkerLease)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, m := range messages {
+		w.deliver(m)
+	}
+
+	return len(messages), nil
+}
+
+func (w *EmailWorker) deliver(m repository.QueuedEmail) {
+	// The address may have bounced or complained since the message was queued.
+	if m.Suppressed {
+		if err := w.outboxRepo.Fail(m.ID, "recipient is suppressed"); err != nil {
+			log.Printf("Email worker: failed to mark message %s failed: %v", m.ID, err)
+		}
+		return
+	}
+
+	held, err := w.outboxRepo.Renew(m.ID, m.Attempts, emailWorkerLease)
+	if err != nil {
+		// The lease expires on its own, so the message is still retried.
+		log.Printf("Email worker: failed to renew lease on message %s: %v", m.ID, err)
+		return
+	}
+	if !held {
+		log.Printf("Email worker: message %s was claimed again before it was sent, skipping", m.ID)
+		return
+	}
+
+	sendErr := w.sender.Send(OutgoingEmail{
+		ID:                 m.ID,
+		To:                 m.ToEmail,
+		Subject:            m.Subject,
+		HTMLBody:           m.HTMLBody,
+		TextBody:           m.TextBody,
+		ListUnsubscribeURL: m.ListUnsubscribeURL,
+	})
+	if sendErr == nil {
+		if err := w.outboxRepo.MarkSent(m.ID); err != nil {
+			log.Printf("Email worker: failed to mark message %s sent: %v", m.ID, err)
+		}
+		return
+	}
+
+	if m.Attempts >= emailWorkerMaxAttempts || isPermanentSMTPError(sendErr) {
+		log.Printf("Email worker: message %s failed after %d attempts: %v", m.ID, m.Attempts, sendErr)
+		if err := w.outboxRepo.Fail(m.ID, sendErr.Error()); err != nil {
+			log.Printf("Email worker: failed to mark message %s failed: %v", m.ID, err)
+		}
+		return
+	}
+
+	delay := exponentialBackoff(m.Attempts, emailWorkerBaseBackoff, emailWorkerMaxBackoff)
+	log.Printf("Email worker: message %s attempt %d failed, retrying in %s: %v", m.ID, m.Attempts, delay, sendErr)
+	if err := w.outboxRepo.Retry(m.ID, sendErr.Error(), delay); err != nil {
+		// The lease expires on its own, so the message is still retried.
+		log.Printf("Email worker: failed to reschedule message %s: %v", m.ID, err)
+	}
+}
+
+// isPermanentSMTPError reports whether the server rejected the message with a
+// 5xx reply, which retrying will not fix.
+func isPermanentSMTPError(err error) bool {
+	var smtpErr *textproto.Error
+	return errors.As(err, &smtpErr) && smtpErr.Code >= 500
+}
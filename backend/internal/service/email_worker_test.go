@@ -0,0 +1,175 @@
+package service
+
+import (
+	"errors"
+	"net/textproto"
+	"strings"
+	"testing"
+	"time"
+
+	"gaspeep/backend/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockEmailOutboxRepository is a mock implementation of EmailOutboxRepository
+type MockEmailOutboxRepository struct {
+	mock.Mock
+}
+
+func (m *MockEmailOutboxRepository) Enqueue(input repository.EnqueueEmailInput) (string, error) {
+	args := m.Called(input)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockEmailOutboxRepository) Claim(limit int, lease time.Duration) ([]repository.QueuedEmail, error) {
+	args := m.Called(limit, lease)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]repository.QueuedEmail), args.Error(1)
+}
+
+func (m *MockEmailOutboxRepository) Renew(id string, attempt int, lease time.Duration) (bool, error) {
+	args := m.Called(id, attempt, lease)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockEmailOutboxRepository) MarkSent(id string) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockEmailOutboxRepository) Retry(id, lastError string, delay time.Duration) error {
+	args := m.Called(id, lastError, delay)
+	return args.Error(0)
+}
+
+func (m *MockEmailOutboxRepository) Fail(id, lastError string) error {
+	args := m.Called(id, lastError)
+	return args.Error(0)
+}
+
+func (m *MockEmailOutboxRepository) RecordDeliveryEvent(event repository.EmailDeliveryEvent) error {
+	args := m.Called(event)
+	return args.Error(0)
+}
+
+func (m *MockEmailOutboxRepository) ListMessages(filter repository.EmailMessageFilter, limit, offset int) ([]repository.EmailMessageLog, int, error) {
+	args := m.Called(filter, limit, offset)
+	if args.Get(0) == nil {
+		return nil, args.Int(1), args.Error(2)
+	}
+	return args.Get(0).([]repository.EmailMessageLog), args.Int(1), args.Error(2)
+}
+
+// failingEmailSender records the messages it is asked to send and fails those listed in errs.
+type failingEmailSender struct {
+	errs map[string]error
+	sent []string
+}
+
+func (s *failingEmailSender) Send(email OutgoingEmail) error {
+	s.sent = append(s.sent, email.ID)
+	return s.errs[email.ID]
+}
+
+func setupEmailWorkerTest(sendErrs map[string]error) (*EmailWorker, *MockEmailOutboxRepository, *[]string) {
+	outboxRepo := new(MockEmailOutboxRepository)
+	sender := &failingEmailSender{errs: sendErrs, sent: make([]string, 0)}
+	return NewEmailWorker(outboxRepo, sender), outboxRepo, &sender.sent
+}
+
+func TestEmailWorkerProcessBatch_SendsAndRetries(t *testing.T) {
+	rejected := &textproto.Error{Code: 550, Msg: "mailbox unavailable"}
+	worker, outboxRepo, sent := setupEmailWorkerTest(map[string]error{
+		"msg-2": errors.New("connection refused"),
+		"msg-3": rejected,
+		"msg-4": errors.New("connection refused"),
+	})
+
+	outboxRepo.On("Claim", emailWorkerBatchSize, emailWorkerLease).Return([]repository.QueuedEmail{
+		{ID: "msg-1", ToEmail: "a@example.com", Attempts: 1},
+		{ID: "msg-2", ToEmail: "b@example.com", Attempts: 3},
+		{ID: "msg-3", ToEmail: "c@example.com", Attempts: 1},
+		{ID: "msg-4", ToEmail: "d@example.com", Attempts: emailWorkerMaxAttempts},
+	}, nil)
+	outboxRepo.On("Renew", mock.Anything, mock.Anything, emailWorkerLease).Return(true, nil)
+	outboxRepo.On("MarkSent", "msg-1").Return(nil)
+	outboxRepo.On("Retry", "msg-2", "connection refused", 4*time.Minute).Return(nil)
+	outboxRepo.On("Fail", "msg-3", rejected.Error()).Return(nil)
+	outboxRepo.On("Fail", "msg-4", "connection refused").Return(nil)
+
+	processed, err := worker.processBatch()
+
+	require.NoError(t, err)
+	assert.Equal(t, 4, processed)
+	assert.Equal(t, []string{"msg-1", "msg-2", "msg-3", "msg-4"}, *sent)
+	outboxRepo.AssertExpectations(t)
+}
+
+func TestEmailWorkerProcessBatch_SkipsSuppressedRecipients(t *testing.T) {
+	worker, outboxRepo, sent := setupEmailWorkerTest(nil)
+
+	outboxRepo.On("Claim", emailWorkerBatchSize, emailWorkerLease).Return([]repository.QueuedEmail{
+		{ID: "msg-1", ToEmail: "bounced@example.com", Attempts: 1, Suppressed: true},
+	}, nil)
+	outboxRepo.On("Fail", "msg-1", "recipient is suppressed").Return(nil)
+
+	_, err := worker.processBatch()
+
+	require.NoError(t, err)
+	assert.Empty(t, *sent)
+	outboxRepo.AssertExpectations(t)
+}
+
+func TestEmailWorkerProcessBatch_SkipsMessagesClaimedAgain(t *testing.T) {
+	worker, outboxRepo, sent := setupEmailWorkerTest(nil)
+
+	outboxRepo.On("Claim", emailWorkerBatchSize, emailWorkerLease).Return([]repository.QueuedEmail{
+		{ID: "msg-1", ToEmail: "a@example.com", Attempts: 1},
+		{ID: "msg-2", ToEmail: "b@example.com", Attempts: 2},
+	}, nil)
+	// msg-1's lease lapsed while earlier messages were sent and another
+	// worker claimed it
+	outboxRepo.On("Renew", "msg-1", 1, emailWorkerLease).Return(false, nil)
+	outboxRepo.On("Renew", "msg-2", 2, emailWorkerLease).Return(true, nil)
+	outboxRepo.On("MarkSent", "msg-2").Return(nil)
+
+	processed, err := worker.processBatch()
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, processed)
+	assert.Equal(t, []string{"msg-2"}, *sent)
+	outboxRepo.AssertExpectations(t)
+}
+
+func TestEmailWorkerProcessBatch_ClaimFails_ReturnsError(t *testing.T) {
+	worker, outboxRepo, _ := setupEmailWorkerTest(nil)
+
+	outboxRepo.On("Claim", emailWorkerBatchSize, emailWorkerLease).Return(nil, errors.New("database error"))
+
+	processed, err := worker.processBatch()
+
+	assert.Error(t, err)
+	assert.Equal(t, 0, processed)
+}
+
+func TestEmailServiceSendPasswordReset_QueuesMessage(t *testing.T) {
+	outboxRepo := new(MockEmailOutboxRepository)
+	service := newTestEmailService(t, outboxRepo, allowAllEmailVerification{})
+
+	outboxRepo.On("Enqueue", mock.MatchedBy(func(input repository.EnqueueEmailInput) bool {
+		return input.UserID == "user-1" && input.ToEmail == "a@example.com" &&
+			input.Template == EmailTemplatePasswordReset &&
+			input.Subject == "Reset your Gas Peep password" &&
+			strings.Contains(input.HTMLBody, "https://example.com/reset?token=abc") &&
+			strings.Contains(input.TextBody, "Reset Password: https://example.com/reset?token=abc")
+	})).Return("msg-1", nil)
+
+	err := service.SendPasswordReset("user-1", "a@example.com", "https://example.com/reset?token=abc")
+
+	require.NoError(t, err)
+	outboxRepo.AssertExpectations(t)
+}
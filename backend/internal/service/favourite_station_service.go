@@ -0,0 +1,44 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+
+	"gaspeep/backend/internal/repository"
+)
+
+// FavouriteStationService defines business operations for a user's favourite stations.
+type FavouriteStationService interface {
+	AddFavourite(userID, stationID string) error
+	RemoveFavourite(userID, stationID string) (bool, error)
+	GetFavourites(userID string) ([]repository.FavouriteStationResult, error)
+	GetFavouritePrices(userID, fuelTypeID string) ([]repository.FavouritePriceResult, error)
+}
+
+type favouriteStationService struct {
+	favouriteRepo repository.FavouriteStationRepository
+}
+
+func NewFavouriteStationService(favouriteRepo repository.FavouriteStationRepository) FavouriteStationService {
+	return &favouriteStationService{favouriteRepo: favouriteRepo}
+}
+
+func (s *favouriteStationService) AddFavourite(userID, stationID string) error {
+	err := s.favouriteRepo.Add(userID, stationID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrStationNotFound
+	}
+	return err
+}
+
+func (s *favouriteStationService) RemoveFavourite(userID, stationID string) (bool, error) {
+	return s.favouriteRepo.Remove(userID, stationID)
+}
+
+func (s *favouriteStationService) GetFavourites(userID string) ([]repository.FavouriteStationResult, error) {
+	return s.favouriteRepo.GetByUserID(userID)
+}
+
+func (s *favouriteStationService) GetFavouritePrices(userID, fuelTypeID string) ([]repository.FavouritePriceResult, error) {
+	return s.favouriteRepo.GetPrices(userID, fuelTypeID)
+}
@@ -0,0 +1,70 @@
+package service
+
+import (
+	"database/sql"
+	"testing"
+
+	"gaspeep/backend/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockFavouriteStationRepository mocks the FavouriteStationRepository interface
+type MockFavouriteStationRepository struct {
+	mock.Mock
+}
+
+func (m *MockFavouriteStationRepository) Add(userID, stationID string) error {
+	args := m.Called(userID, stationID)
+	return args.Error(0)
+}
+
+func (m *MockFavouriteStationRepository) Remove(userID, stationID string) (bool, error) {
+	args := m.Called(userID, stationID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockFavouriteStationRepository) GetByUserID(userID string) ([]repository.FavouriteStationResult, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]repository.FavouriteStationResult), args.Error(1)
+}
+
+func (m *MockFavouriteStationRepository) GetPrices(userID, fuelTypeID string) ([]repository.FavouritePriceResult, error) {
+	args := m.Called(userID, fuelTypeID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]repository.FavouritePriceResult), args.Error(1)
+}
+
+// ============ Favourite Station Service Tests ============
+
+func TestFavouriteStationService_AddFavourite_MissingStation(t *testing.T) {
+	mockRepo := new(MockFavouriteStationRepository)
+	service := NewFavouriteStationService(mockRepo)
+
+	mockRepo.On("Add", "user-1", "station-1").Return(sql.ErrNoRows)
+
+	err := service.AddFavourite("user-1", "station-1")
+
+	assert.ErrorIs(t, err, ErrStationNotFound)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestFavouriteStationService_GetFavourites_CallsRepository(t *testing.T) {
+	mockRepo := new(MockFavouriteStationRepository)
+	service := NewFavouriteStationService(mockRepo)
+
+	expected := []repository.FavouriteStationResult{{StationID: "station-1"}}
+	mockRepo.On("GetByUserID", "user-1").Return(expected, nil)
+
+	result, err := service.GetFavourites("user-1")
+
+	require.NoError(t, err)
+	assert.Equal(t, expected, result)
+	mockRepo.AssertExpectations(t)
+}
@@ -0,0 +1,258 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"gaspeep/backend/internal/repository"
+)
+
+// loginMaxDelay caps the wait between sign-in attempts before an account is
+// locked.
+const loginMaxDelay = time.Minute
+
+var ErrTooManyAttempts = errors.New("too many attempts")
+
+// ThrottledError is returned when a client must wait before trying again.
+// It wraps ErrTooManyAttempts.
+type ThrottledError struct {
+	RetryAfter time.Duration
+}
+
+func (e *ThrottledError) Error() string {
+	return fmt.Sprintf("too many attempts, retry after %s", e.RetryAfter.Round(time.Second))
+}
+
+func (e *ThrottledError) Unwrap() error { return ErrTooManyAttempts }
+
+// LoginThrottle slows down password guessing and email enumeration. Failed
+// sign-ins are tracked per account and per client IP. After a few failures
+// each further attempt on an account must wait twice as long as the last,
+// and too many failures lock the account or IP for a while. Accounts are
+// tracked by email address whether or not they exist, so responses do not
+// reveal which addresses are registered.
+type LoginThrottle interface {
+	// Check returns a *ThrottledError if a sign-in for email from ip must
+	// wait.
+	Check(email, ip string) error
+	// RecordFailure counts a failed sign-in, locking the account or IP when
+	// it has failed too often. The account owner is emailed when their
+	// account is locked.
+	RecordFailure(email, ip string) error
+	// RecordSuccess clears the account's failures.
+	RecordSuccess(email string) error
+	// CheckMFA returns a *ThrottledError if a second-factor code for the
+	// user must wait.
+	CheckMFA(userID string) error
+	// RecordMFAFailure counts a wrong second-factor code. Too many lock both
+	// second-factor verification and password sign-in for the account, and
+	// its owner is emailed.
+	RecordMFAFailure(userID, email string) error
+	// RecordMFASuccess clears the user's second-factor failures.
+	RecordMFASuccess(userID string) error
+	// AllowLookup counts a request for scope, such as a password reset, from
+	// each of keys and returns a *ThrottledError if any has made too many.
+	AllowLookup(scope string, keys ...string) error
+}
+
+type loginThrottle struct {
+	store        repository.LoginAttemptRepository
+	userRepo     repository.UserRepository
+	emailService EmailService
+
+	freeAttempts  int
+	maxAttempts   int
+	ipMaxAttempts int
+	lookupLimit   int
+	lockout       time.Duration
+	now           func() time.Time
+}
+
+// NewLoginThrottle delays sign-in after LOGIN_FREE_ATTEMPTS (default 3)
+// failures and locks an account after LOGIN_MAX_ATTEMPTS (default 10) or an
+// IP after LOGIN_IP_MAX_ATTEMPTS (default 50), counted over and locked for
+// LOGIN_LOCKOUT_MINUTES (default 15). Each IP may make LOGIN_LOOKUP_LIMIT
+// (default 20) lookups, such as email availability checks, in that time.
+func NewLoginThrottle(store repository.LoginAttemptRepository, userRepo repository.UserRepository, emailService EmailService) LoginThrottle {
+	positive := func(name string, fallback int) int {
+		if v := parseEnvInt(name, fallback); v > 0 {
+			return v
+		}
+		return fallback
+	}
+	return &loginThrottle{
+		store:         store,
+		userRepo:      userRepo,
+		emailService:  emailService,
+		freeAttempts:  positive("LOGIN_FREE_ATTEMPTS", 3),
+		maxAttempts:   positive("LOGIN_MAX_ATTEMPTS", 10),
+		ipMaxAttempts: positive("LOGIN_IP_MAX_ATTEMPTS", 50),
+		lookupLimit:   positive("LOGIN_LOOKUP_LIMIT", 20),
+		lockout:       time.Duration(positive("LOGIN_LOCKOUT_MINUTES", 15)) * time.Minute,
+		now:           time.Now,
+	}
+}
+
+func accountAttemptKey(email string) string {
+	return "account:" + strings.ToLower(strings.TrimSpace(email))
+}
+
+func ipAttemptKey(ip string) string {
+	return "ip:" + ip
+}
+
+// mfaAttemptKey tracks second-factor failures apart from password ones, so
+// signing in again with the password does not reset them.
+func mfaAttemptKey(userID string) string {
+	return "mfa:" + userID
+}
+
+func (t *loginThrottle) Check(email, ip string) error {
+	now := t.now()
+
+	account, err := t.store.Get(accountAttemptKey(email))
+	if err != nil {
+		return err
+	}
+	if now.Before(account.LockedUntil) {
+		return &ThrottledError{RetryAfter: account.LockedUntil.Sub(now)}
+	}
+	if delay := t.delay(account.Failures); delay > 0 {
+		if wait := account.LastFailureAt.Add(delay).Sub(now); wait > 0 {
+			return &ThrottledError{RetryAfter: wait}
+		}
+	}
+
+	client, err := t.store.Get(ipAttemptKey(ip))
+	if err != nil {
+		return err
+	}
+	if now.Before(client.LockedUntil) {
+		return &ThrottledError{RetryAfter: client.LockedUntil.Sub(now)}
+	}
+	return nil
+}
+
+// delay returns how long to wait after the last of failures: nothing for the
+// free attempts, then 1s, 2s, 4s and so on up to loginMaxDelay.
+func (t *loginThrottle) delay(failures int) time.Duration {
+	n := failures - t.freeAttempts
+	if n <= 0 {
+		return 0
+	}
+	if n > 7 {
+		return loginMaxDelay
+	}
+	return min(time.Second<<(n-1), loginMaxDelay)
+}
+
+func (t *loginThrottle) RecordFailure(email, ip string) error {
+	key := accountAttemptKey(email)
+	account, err := t.store.RecordFailure(key, t.lockout)
+	if err != nil {
+		return err
+	}
+	if account.Failures >= t.maxAttempts {
+		if err := t.store.Lock(key, t.now().Add(t.lockout)); err != nil {
+			return err
+		}
+		t.notifyLocked(email)
+	}
+
+	key = ipAttemptKey(ip)
+	client, err := t.store.RecordFailure(key, t.lockout)
+	if err != nil {
+		return err
+	}
+	if client.Failures >= t.ipMaxAttempts {
+		log.Printf("warning: locking sign-in from %s after %d failed attempts", ip, client.Failures)
+		return t.store.Lock(key, t.now().Add(t.lockout))
+	}
+	return nil
+}
+
+// notifyLocked emails the owner of a locked account, if there is one.
+func (t *loginThrottle) notifyLocked(email string) {
+	user, err := t.userRepo.GetUserByEmail(email)
+	if err != nil || user == nil {
+		return
+	}
+	log.Printf("warning: locking sign-in to user %s after repeated failed attempts", user.ID)
+	if err := t.emailService.SendAccountLocked(user.ID, user.Email, t.lockout); err != nil {
+		log.Printf("warning: failed to send account locked email to user %s: %v", user.ID, err)
+	}
+}
+
+func (t *loginThrottle) RecordSuccess(email string) error {
+	return t.store.Reset(accountAttemptKey(email))
+}
+
+func (t *loginThrottle) CheckMFA(userID string) error {
+	now := t.now()
+	a, err := t.store.Get(mfaAttemptKey(userID))
+	if err != nil {
+		return err
+	}
+	if now.Before(a.LockedUntil) {
+		return &ThrottledError{RetryAfter: a.LockedUntil.Sub(now)}
+	}
+	if delay := t.delay(a.Failures); delay > 0 {
+		if wait := a.LastFailureAt.Add(delay).Sub(now); wait > 0 {
+			return &ThrottledError{RetryAfter: wait}
+		}
+	}
+	return nil
+}
+
+func (t *loginThrottle) RecordMFAFailure(userID, email string) error {
+	key := mfaAttemptKey(userID)
+	a, err := t.store.RecordFailure(key, t.lockout)
+	if err != nil {
+		return err
+	}
+	if a.Failures < t.maxAttempts {
+		return nil
+	}
+	lockedUntil := t.now().Add(t.lockout)
+	if err := t.store.Lock(key, lockedUntil); err != nil {
+		return err
+	}
+	if err := t.store.Lock(accountAttemptKey(email), lockedUntil); err != nil {
+		return err
+	}
+	t.notifyLocked(email)
+	return nil
+}
+
+func (t *loginThrottle) RecordMFASuccess(userID string) error {
+	return t.store.Reset(mfaAttemptKey(userID))
+}
+
+func (t *loginThrottle) AllowLookup(scope string, keys ...string) error {
+	now := t.now()
+	for _, k := range keys {
+		key := "lookup:" + scope + ":" + strings.ToLower(k)
+		a, err := t.store.Get(key)
+		if err != nil {
+			return err
+		}
+		if now.Before(a.LockedUntil) {
+			return &ThrottledError{RetryAfter: a.LockedUntil.Sub(now)}
+		}
+
+		a, err = t.store.RecordFailure(key, t.lockout)
+		if err != nil {
+			return err
+		}
+		if a.Failures > t.lookupLimit {
+			if err := t.store.Lock(key, now.Add(t.lockout)); err != nil {
+				return err
+			}
+			return &ThrottledError{RetryAfter: t.lockout}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,178 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"gaspeep/backend/internal/models"
+	"gaspeep/backend/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockUserRepositoryForThrottle mocks the user lookups made when an account
+// is locked. Other UserRepository methods are not implemented.
+type MockUserRepositoryForThrottle struct {
+	mock.Mock
+	repository.UserRepository
+}
+
+func (m *MockUserRepositoryForThrottle) GetUserByEmail(email string) (*models.User, error) {
+	args := m.Called(email)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.User), args.Error(1)
+}
+
+// MockEmailServiceForThrottle mocks the lockout email. Other EmailService
+// methods are not implemented.
+type MockEmailServiceForThrottle struct {
+	mock.Mock
+	EmailService
+}
+
+func (m *MockEmailServiceForThrottle) SendAccountLocked(userID, toEmail string, lockedFor time.Duration) error {
+	args := m.Called(userID, toEmail, lockedFor)
+	return args.Error(0)
+}
+
+func setupLoginThrottleTest(t *testing.T) (*loginThrottle, *MockUserRepositoryForThrottle, *MockEmailServiceForThrottle, *time.Time) {
+	t.Setenv("LOGIN_FREE_ATTEMPTS", "3")
+	t.Setenv("LOGIN_MAX_ATTEMPTS", "6")
+	t.Setenv("LOGIN_IP_MAX_ATTEMPTS", "8")
+	t.Setenv("LOGIN_LOOKUP_LIMIT", "2")
+	t.Setenv("LOGIN_LOCKOUT_MINUTES", "15")
+	userRepo := new(MockUserRepositoryForThrottle)
+	emailService := new(MockEmailServiceForThrottle)
+	throttle := NewLoginThrottle(repository.NewMemoryLoginAttemptRepository(), userRepo, emailService).(*loginThrottle)
+
+	// Attempts are stamped with the real time, so the clock only moves forward from it
+	now := time.Now()
+	throttle.now = func() time.Time { return now }
+	return throttle, userRepo, emailService, &now
+}
+
+func TestLoginThrottle_ProgressiveDelayAndLockout(t *testing.T) {
+	throttle, userRepo, emailService, now := setupLoginThrottleTest(t)
+
+	for i := 0; i < 3; i++ {
+		require.NoError(t, throttle.Check("User@Example.com", "203.0.113.7"))
+		require.NoError(t, throttle.RecordFailure("user@example.com", "203.0.113.7"))
+	}
+
+	// The fourth failure means waiting a second, the fifth two
+	require.NoError(t, throttle.RecordFailure("user@example.com", "203.0.113.7"))
+	var throttled *ThrottledError
+	require.ErrorAs(t, throttle.Check("user@example.com", "203.0.113.7"), &throttled)
+	assert.ErrorIs(t, throttled, ErrTooManyAttempts)
+	assert.InDelta(t, time.Second, throttled.RetryAfter, float64(100*time.Millisecond))
+	require.NoError(t, throttle.RecordFailure("user@example.com", "203.0.113.7"))
+	require.ErrorAs(t, throttle.Check("user@example.com", "203.0.113.7"), &throttled)
+	assert.InDelta(t, 2*time.Second, throttled.RetryAfter, float64(100*time.Millisecond))
+
+	// Other accounts are not slowed down
+	require.NoError(t, throttle.Check("other@example.com", "203.0.113.7"))
+
+	// The sixth failure locks the account and tells its owner
+	user := &models.User{ID: "user-1", Email: "user@example.com"}
+	userRepo.On("GetUserByEmail", "user@example.com").Return(user, nil).Once()
+	emailService.On("SendAccountLocked", "user-1", "user@example.com", 15*time.Minute).Return(nil).Once()
+	require.NoError(t, throttle.RecordFailure("user@example.com", "203.0.113.7"))
+
+	*now = now.Add(time.Minute)
+	require.ErrorAs(t, throttle.Check("user@example.com", "198.51.100.1"), &throttled)
+	assert.Equal(t, 14*time.Minute, throttled.RetryAfter.Round(time.Minute))
+
+	*now = now.Add(15 * time.Minute)
+	require.NoError(t, throttle.Check("user@example.com", "198.51.100.1"))
+	userRepo.AssertExpectations(t)
+	emailService.AssertExpectations(t)
+}
+
+func TestLoginThrottle_UnknownAccountsLockTheSameWay(t *testing.T) {
+	throttle, userRepo, emailService, _ := setupLoginThrottleTest(t)
+
+	userRepo.On("GetUserByEmail", "nobody@example.com").Return(nil, errors.New("user not found")).Once()
+	for i := 0; i < 6; i++ {
+		require.NoError(t, throttle.RecordFailure("nobody@example.com", "203.0.113.7"))
+	}
+
+	var throttled *ThrottledError
+	require.ErrorAs(t, throttle.Check("nobody@example.com", "203.0.113.7"), &throttled)
+	assert.Equal(t, 15*time.Minute, throttled.RetryAfter.Round(time.Minute))
+	emailService.AssertNotCalled(t, "SendAccountLocked", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestLoginThrottle_LocksIPAcrossAccounts(t *testing.T) {
+	throttle, _, _, _ := setupLoginThrottleTest(t)
+
+	for i := 0; i < 8; i++ {
+		require.NoError(t, throttle.RecordFailure("user"+string(rune('a'+i))+"@example.com", "203.0.113.7"))
+	}
+
+	assert.ErrorIs(t, throttle.Check("fresh@example.com", "203.0.113.7"), ErrTooManyAttempts)
+	assert.NoError(t, throttle.Check("fresh@example.com", "198.51.100.1"))
+}
+
+func TestLoginThrottle_SuccessClearsAccountFailures(t *testing.T) {
+	throttle, _, _, _ := setupLoginThrottleTest(t)
+
+	for i := 0; i < 5; i++ {
+		require.NoError(t, throttle.RecordFailure("user@example.com", "203.0.113.7"))
+	}
+	require.Error(t, throttle.Check("user@example.com", "203.0.113.7"))
+
+	require.NoError(t, throttle.RecordSuccess("USER@example.com"))
+	assert.NoError(t, throttle.Check("user@example.com", "203.0.113.7"))
+}
+
+// TestLoginThrottle_MFAFailuresLockAccount tests that wrong second-factor
+// codes are slowed down like passwords, survive a new password sign-in, and
+// eventually lock the whole account
+func TestLoginThrottle_MFAFailuresLockAccount(t *testing.T) {
+	throttle, userRepo, emailService, now := setupLoginThrottleTest(t)
+
+	for i := 0; i < 4; i++ {
+		require.NoError(t, throttle.CheckMFA("user-1"))
+		require.NoError(t, throttle.RecordMFAFailure("user-1", "user@example.com"))
+	}
+	assert.ErrorIs(t, throttle.CheckMFA("user-1"), ErrTooManyAttempts)
+
+	require.NoError(t, throttle.RecordSuccess("user@example.com"))
+	*now = now.Add(time.Minute)
+	require.NoError(t, throttle.RecordMFAFailure("user-1", "user@example.com"))
+
+	user := &models.User{ID: "user-1", Email: "user@example.com"}
+	userRepo.On("GetUserByEmail", "user@example.com").Return(user, nil).Once()
+	emailService.On("SendAccountLocked", "user-1", "user@example.com", 15*time.Minute).Return(nil).Once()
+	require.NoError(t, throttle.RecordMFAFailure("user-1", "user@example.com"))
+
+	var throttled *ThrottledError
+	require.ErrorAs(t, throttle.CheckMFA("user-1"), &throttled)
+	assert.Equal(t, 15*time.Minute, throttled.RetryAfter.Round(time.Minute))
+	assert.ErrorIs(t, throttle.Check("user@example.com", "198.51.100.1"), ErrTooManyAttempts)
+	userRepo.AssertExpectations(t)
+	emailService.AssertExpectations(t)
+
+	*now = now.Add(16 * time.Minute)
+	require.NoError(t, throttle.RecordMFASuccess("user-1"))
+	assert.NoError(t, throttle.CheckMFA("user-1"))
+}
+
+func TestLoginThrottle_AllowLookup(t *testing.T) {
+	throttle, _, _, now := setupLoginThrottleTest(t)
+
+	require.NoError(t, throttle.AllowLookup("check-email", "203.0.113.7"))
+	require.NoError(t, throttle.AllowLookup("check-email", "203.0.113.7"))
+	assert.ErrorIs(t, throttle.AllowLookup("check-email", "203.0.113.7"), ErrTooManyAttempts)
+
+	// Scopes and clients are counted separately
+	assert.NoError(t, throttle.AllowLookup("password-reset", "203.0.113.7"))
+	assert.NoError(t, throttle.AllowLookup("check-email", "198.51.100.1"))
+
+	*now = now.Add(16 * time.Minute)
+	assert.NoError(t, throttle.AllowLookup("check-email", "203.0.113.7"))
+}
@@ -0,0 +1,136 @@
+package service
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"time"
+
+	"gaspeep/backend/internal/models"
+	"gaspeep/backend/internal/repository"
+)
+
+const (
+	// MagicLinkTTL is how long a sign-in link can be used for.
+	MagicLinkTTL             = 15 * time.Minute
+	magicLinkResendDelay     = time.Minute
+	magicLinkHourlyLimit     = 5
+	magicLinkAuditResultSent = "sent"
+)
+
+var ErrInvalidMagicLink = errors.New("invalid or expired magic link")
+
+// MagicLinkService signs users in with single-use links emailed to them. Each
+// link only works in the browser that asked for it, which holds a random
+// nonce the link was issued for.
+type MagicLinkService interface {
+	// Send emails a sign-in link for the browser holding nonce to the account
+	// registered with email. Requests for unregistered addresses, and those
+	// over the rate limit, are audited but otherwise ignored, so callers
+	// cannot tell which addresses are registered.
+	Send(email, nonce string, meta SessionMetadata) error
+	// Redeem uses up a link's token and returns the user it signs in. It
+	// returns ErrInvalidMagicLink if the token is unknown, used, expired or
+	// was not issued for nonce.
+	Redeem(token, nonce string, meta SessionMetadata) (*models.User, error)
+}
+
+type magicLinkService struct {
+	magicLinkRepo repository.MagicLinkRepository
+	userRepo      repository.UserRepository
+	auditRepo     repository.AuditLogRepository
+	emailService  EmailService
+	now           func() time.Time
+}
+
+func NewMagicLinkService(
+	magicLinkRepo repository.MagicLinkRepository,
+	userRepo repository.UserRepository,
+	auditRepo repository.AuditLogRepository,
+	emailService EmailService,
+) MagicLinkService {
+	return &magicLinkService{
+		magicLinkRepo: magicLinkRepo,
+		userRepo:      userRepo,
+		auditRepo:     auditRepo,
+		emailService:  emailService,
+		now:           time.Now,
+	}
+}
+
+func (s *magicLinkService) Send(email, nonce string, meta SessionMetadata) error {
+	if nonce == "" {
+		return fmt.Errorf("failed to send magic link: missing nonce")
+	}
+	user, err := s.userRepo.GetUserByEmail(email)
+	if err != nil || user == nil {
+		s.audit("", repository.AuditMagicLinkRequested, meta, map[string]string{"result": "unknown_email"})
+		return nil
+	}
+
+	now := s.now()
+	issued, latest, err := s.magicLinkRepo.CountIssuedSince(user.ID, now.Add(-time.Hour))
+	if err != nil {
+		return err
+	}
+	if issued >= magicLinkHourlyLimit || (latest != nil && now.Sub(*latest) < magicLinkResendDelay) {
+		s.audit(user.ID, repository.AuditMagicLinkRequested, meta, map[string]string{"result": "rate_limited"})
+		return nil
+	}
+
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Errorf("failed to generate magic link token: %w", err)
+	}
+	token := hex.EncodeToString(b)
+
+	if err := s.magicLinkRepo.Create(user.ID, user.Email, hashVerificationToken(token), hashVerificationToken(nonce), now.Add(MagicLinkTTL)); err != nil {
+		return err
+	}
+	if err := s.emailService.SendMagicLink(user.ID, user.Email, appURL("/auth/magic-link?token="+url.QueryEscape(token)), MagicLinkTTL); err != nil {
+		return err
+	}
+	s.audit(user.ID, repository.AuditMagicLinkRequested, meta, map[string]string{"result": magicLinkAuditResultSent})
+	return nil
+}
+
+func (s *magicLinkService) Redeem(token, nonce string, meta SessionMetadata) (*models.User, error) {
+	if token == "" || nonce == "" {
+		s.audit("", repository.AuditMagicLinkRejected, meta, nil)
+		return nil, ErrInvalidMagicLink
+	}
+	userID, err := s.magicLinkRepo.Consume(hashVerificationToken(token), hashVerificationToken(nonce))
+	if errors.Is(err, sql.ErrNoRows) {
+		s.audit("", repository.AuditMagicLinkRejected, meta, nil)
+		return nil, ErrInvalidMagicLink
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.GetUserByID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load magic link user: %w", err)
+	}
+	s.audit(userID, repository.AuditMagicLinkRedeemed, meta, nil)
+	return user, nil
+}
+
+// audit records an event, logging rather than failing the request when it
+// cannot.
+func (s *magicLinkService) audit(userID, action string, meta SessionMetadata, details map[string]string) {
+	err := s.auditRepo.Record(repository.AuditLogEntry{
+		UserID:    userID,
+		Action:    action,
+		IPAddress: meta.IPAddress,
+		UserAgent: meta.UserAgent,
+		Details:   details,
+	})
+	if err != nil {
+		log.Printf("warning: failed to record %s: %v", action, err)
+	}
+}
@@ -0,0 +1,164 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"gaspeep/backend/internal/models"
+	"gaspeep/backend/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockMagicLinkRepository struct {
+	mock.Mock
+}
+
+func (m *MockMagicLinkRepository) Create(userID, email, tokenHash, nonceHash string, expiresAt time.Time) error {
+	args := m.Called(userID, email, tokenHash, nonceHash, expiresAt)
+	return args.Error(0)
+}
+
+func (m *MockMagicLinkRepository) CountIssuedSince(userID string, since time.Time) (int, *time.Time, error) {
+	args := m.Called(userID, since)
+	latest, _ := args.Get(1).(*time.Time)
+	return args.Int(0), latest, args.Error(2)
+}
+
+func (m *MockMagicLinkRepository) Consume(tokenHash, nonceHash string) (string, error) {
+	args := m.Called(tokenHash, nonceHash)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockMagicLinkRepository) DeleteExpiredBefore(cutoff time.Time) (int64, error) {
+	args := m.Called(cutoff)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+type MockAuditLogRepository struct {
+	mock.Mock
+}
+
+func (m *MockAuditLogRepository) Record(entry repository.AuditLogEntry) error {
+	args := m.Called(entry)
+	return args.Error(0)
+}
+
+// MockUserRepositoryForMagicLink mocks the user lookups made when sending and
+// redeeming links. Other UserRepository methods are not implemented.
+type MockUserRepositoryForMagicLink struct {
+	mock.Mock
+	repository.UserRepository
+}
+
+func (m *MockUserRepositoryForMagicLink) GetUserByEmail(email string) (*models.User, error) {
+	args := m.Called(email)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.User), args.Error(1)
+}
+
+func (m *MockUserRepositoryForMagicLink) GetUserByID(id string) (*models.User, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.User), args.Error(1)
+}
+
+// MockEmailServiceForMagicLink mocks the sign-in link email. Other
+// EmailService methods are not implemented.
+type MockEmailServiceForMagicLink struct {
+	mock.Mock
+	EmailService
+}
+
+func (m *MockEmailServiceForMagicLink) SendMagicLink(userID, toEmail, signInURL string, expiresIn time.Duration) error {
+	args := m.Called(userID, toEmail, signInURL, expiresIn)
+	return args.Error(0)
+}
+
+func setupMagicLinkTest() (MagicLinkService, *MockMagicLinkRepository, *MockUserRepositoryForMagicLink, *MockAuditLogRepository, *MockEmailServiceForMagicLink) {
+	linkRepo := new(MockMagicLinkRepository)
+	userRepo := new(MockUserRepositoryForMagicLink)
+	auditRepo := new(MockAuditLogRepository)
+	emailService := new(MockEmailServiceForMagicLink)
+	return NewMagicLinkService(linkRepo, userRepo, auditRepo, emailService), linkRepo, userRepo, auditRepo, emailService
+}
+
+func auditAction(action, result string) interface{} {
+	return mock.MatchedBy(func(entry repository.AuditLogEntry) bool {
+		return entry.Action == action && entry.Details["result"] == result && entry.IPAddress == "192.0.2.1"
+	})
+}
+
+func TestMagicLink_SendEmailsLinkBoundToNonce(t *testing.T) {
+	t.Setenv("APP_BASE_URL", "https://app.example.com")
+	svc, linkRepo, userRepo, auditRepo, emailService := setupMagicLinkTest()
+	meta := SessionMetadata{IPAddress: "192.0.2.1"}
+
+	userRepo.On("GetUserByEmail", "user@example.com").Return(&models.User{ID: "user-1", Email: "user@example.com"}, nil)
+	linkRepo.On("CountIssuedSince", "user-1", mock.Anything).Return(0, nil, nil)
+	var tokenHash string
+	linkRepo.On("Create", "user-1", "user@example.com", mock.Anything, hashVerificationToken("nonce-1"), mock.Anything).
+		Run(func(args mock.Arguments) { tokenHash = args.String(2) }).Return(nil)
+	var link string
+	emailService.On("SendMagicLink", "user-1", "user@example.com", mock.Anything, MagicLinkTTL).
+		Run(func(args mock.Arguments) { link = args.String(2) }).Return(nil)
+	auditRepo.On("Record", auditAction(repository.AuditMagicLinkRequested, "sent")).Return(nil).Once()
+
+	require.NoError(t, svc.Send("user@example.com", "nonce-1", meta))
+
+	token, ok := strings.CutPrefix(link, "https://app.example.com/auth/magic-link?token=")
+	require.True(t, ok, link)
+	assert.Equal(t, hashVerificationToken(token), tokenHash)
+	auditRepo.AssertExpectations(t)
+}
+
+func TestMagicLink_SendIgnoresUnknownEmailAndRateLimited(t *testing.T) {
+	svc, linkRepo, userRepo, auditRepo, emailService := setupMagicLinkTest()
+	meta := SessionMetadata{IPAddress: "192.0.2.1"}
+
+	userRepo.On("GetUserByEmail", "nobody@example.com").Return(nil, errors.New("user not found"))
+	auditRepo.On("Record", auditAction(repository.AuditMagicLinkRequested, "unknown_email")).Return(nil).Once()
+	require.NoError(t, svc.Send("nobody@example.com", "nonce-1", meta))
+
+	latest := time.Now().Add(-30 * time.Second)
+	userRepo.On("GetUserByEmail", "user@example.com").Return(&models.User{ID: "user-1", Email: "user@example.com"}, nil)
+	linkRepo.On("CountIssuedSince", "user-1", mock.Anything).Return(1, &latest, nil)
+	auditRepo.On("Record", auditAction(repository.AuditMagicLinkRequested, "rate_limited")).Return(nil).Once()
+	require.NoError(t, svc.Send("user@example.com", "nonce-1", meta))
+
+	linkRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	emailService.AssertNotCalled(t, "SendMagicLink", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	auditRepo.AssertExpectations(t)
+}
+
+func TestMagicLink_Redeem(t *testing.T) {
+	svc, linkRepo, userRepo, auditRepo, _ := setupMagicLinkTest()
+	meta := SessionMetadata{IPAddress: "192.0.2.1"}
+	user := &models.User{ID: "user-1", Email: "user@example.com"}
+
+	linkRepo.On("Consume", hashVerificationToken("token-1"), hashVerificationToken("nonce-1")).Return("user-1", nil).Once()
+	userRepo.On("GetUserByID", "user-1").Return(user, nil)
+	auditRepo.On("Record", auditAction(repository.AuditMagicLinkRedeemed, "")).Return(nil).Once()
+
+	got, err := svc.Redeem("token-1", "nonce-1", meta)
+	require.NoError(t, err)
+	assert.Equal(t, user, got)
+
+	// Used, expired or another browser's links, and missing nonces, are rejected
+	linkRepo.On("Consume", hashVerificationToken("token-1"), hashVerificationToken("nonce-2")).Return("", sql.ErrNoRows).Once()
+	auditRepo.On("Record", auditAction(repository.AuditMagicLinkRejected, "")).Return(nil).Twice()
+
+	_, err = svc.Redeem("token-1", "nonce-2", meta)
+	assert.ErrorIs(t, err, ErrInvalidMagicLink)
+	_, err = svc.Redeem("token-1", "", meta)
+	assert.ErrorIs(t, err, ErrInvalidMagicLink)
+	auditRepo.AssertExpectations(t)
+}
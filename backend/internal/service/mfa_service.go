@@ -0,0 +1,283 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base32"
+	"encoding/hex"
+	"errors"
+	"os"
+	"strings"
+	"time"
+
+	"gaspeep/backend/internal/auth"
+	"gaspeep/backend/internal/models"
+	"gaspeep/backend/internal/repository"
+)
+
+const recoveryCodeCount = 10
+
+var (
+	ErrMFAAlreadyEnabled  = errors.New("two-factor authentication already enabled")
+	ErrMFANotEnabled      = errors.New("two-factor authentication not enabled")
+	ErrMFASetupNotStarted = errors.New("two-factor authentication setup not started")
+	ErrInvalidMFACode     = errors.New("invalid two-factor authentication code")
+	ErrMFARequired        = errors.New("two-factor authentication required")
+)
+
+// MFAStatus describes a user's two-factor authentication settings.
+type MFAStatus struct {
+	Enabled                bool `json:"enabled"`
+	Required               bool `json:"required"`
+	RecoveryCodesRemaining int  `json:"recoveryCodesRemaining"`
+}
+
+// TOTPEnrollment is shown to the user to add the account to an authenticator
+// app, usually by rendering ProvisioningURI as a QR code.
+type TOTPEnrollment struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioningUri"`
+}
+
+// MFAService manages optional TOTP two-factor authentication and its
+// single-use recovery codes.
+type MFAService interface {
+	Status(userID string) (*MFAStatus, error)
+	// BeginTOTP creates a new secret for the user to confirm with
+	// ConfirmTOTP. It returns ErrMFAAlreadyEnabled if one is confirmed.
+	BeginTOTP(user *models.User) (*TOTPEnrollment, error)
+	// ConfirmTOTP enables two-factor authentication once the user enters a
+	// code from the new secret, and returns their recovery codes.
+	ConfirmTOTP(userID, code string) ([]string, error)
+	// Enabled reports whether the user must enter a code to sign in.
+	Enabled(userID string) (bool, error)
+	// Verify accepts a TOTP code or an unused recovery code. Each is
+	// accepted once.
+	Verify(userID, code string) error
+	// RegenerateRecoveryCodes replaces the user's recovery codes after
+	// verifying code.
+	RegenerateRecoveryCodes(userID, code string) ([]string, error)
+	// Disable turns two-factor authentication off after verifying code. It
+	// returns ErrMFARequired if policy requires it for the user.
+	Disable(userID, code string) error
+	// MFARequired reports whether policy requires the user to use
+	// two-factor authentication.
+	MFARequired(userID string) (bool, error)
+}
+
+type mfaService struct {
+	mfaRepo       repository.MFARepository
+	issuer        string
+	requiredRoles map[string]bool
+	now           func() time.Time
+}
+
+// NewMFAService requires two-factor authentication for users holding any of
+// the comma-separated MFA_REQUIRED_ROLES (owner, moderator, admin; default
+// none). Authenticator apps list accounts under MFA_ISSUER (default
+// "Gas Peep").
+func NewMFAService(mfaRepo repository.MFARepository) MFAService {
+	issuer := strings.TrimSpace(os.Getenv("MFA_ISSUER"))
+	if issuer == "" {
+		issuer = "Gas Peep"
+	}
+	requiredRoles := map[string]bool{}
+	for _, role := range strings.Split(os.Getenv("MFA_REQUIRED_ROLES"), ",") {
+		if role = strings.ToLower(strings.TrimSpace(role)); role != "" {
+			requiredRoles[role] = true
+		}
+	}
+	return &mfaService{mfaRepo: mfaRepo, issuer: issuer, requiredRoles: requiredRoles, now: time.Now}
+}
+
+func (s *mfaService) Status(userID string) (*MFAStatus, error) {
+	enabled, err := s.Enabled(userID)
+	if err != nil {
+		return nil, err
+	}
+	required, err := s.MFARequired(userID)
+	if err != nil {
+		return nil, err
+	}
+	status := &MFAStatus{Enabled: enabled, Required: required}
+	if enabled {
+		if status.RecoveryCodesRemaining, err = s.mfaRepo.CountRecoveryCodes(userID); err != nil {
+			return nil, err
+		}
+	}
+	return status, nil
+}
+
+func (s *mfaService) BeginTOTP(user *models.User) (*TOTPEnrollment, error) {
+	enabled, err := s.Enabled(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if enabled {
+		return nil, ErrMFAAlreadyEnabled
+	}
+
+	secret, err := auth.GenerateTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.mfaRepo.SaveTOTPSecret(user.ID, secret); err != nil {
+		return nil, err
+	}
+	return &TOTPEnrollment{
+		Secret:          secret,
+		ProvisioningURI: auth.TOTPProvisioningURI(s.issuer, user.Email, secret),
+	}, nil
+}
+
+func (s *mfaService) ConfirmTOTP(userID, code string) ([]string, error) {
+	cred, err := s.mfaRepo.GetTOTP(userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrMFASetupNotStarted
+	}
+	if err != nil {
+		return nil, err
+	}
+	if cred.ConfirmedAt != nil {
+		return nil, ErrMFAAlreadyEnabled
+	}
+
+	step, ok := auth.ValidateTOTP(cred.Secret, normalizeMFACode(code), s.now())
+	if !ok {
+		return nil, ErrInvalidMFACode
+	}
+	confirmed, err := s.mfaRepo.ConfirmTOTP(userID, step)
+	if err != nil {
+		return nil, err
+	}
+	if !confirmed {
+		// Another request confirmed it first
+		return nil, ErrMFAAlreadyEnabled
+	}
+	return s.replaceRecoveryCodes(userID)
+}
+
+func (s *mfaService) Enabled(userID string) (bool, error) {
+	cred, err := s.mfaRepo.GetTOTP(userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return cred.ConfirmedAt != nil, nil
+}
+
+func (s *mfaService) Verify(userID, code string) error {
+	cred, err := s.mfaRepo.GetTOTP(userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrMFANotEnabled
+	}
+	if err != nil {
+		return err
+	}
+	if cred.ConfirmedAt == nil {
+		return ErrMFANotEnabled
+	}
+
+	code = normalizeMFACode(code)
+	if step, ok := auth.ValidateTOTP(cred.Secret, code, s.now()); ok {
+		// Recording the step refuses a code that was already used
+		used, err := s.mfaRepo.UseTOTPStep(userID, step)
+		if err != nil {
+			return err
+		}
+		if !used {
+			return ErrInvalidMFACode
+		}
+		return nil
+	}
+
+	used, err := s.mfaRepo.UseRecoveryCode(userID, hashRecoveryCode(code))
+	if err != nil {
+		return err
+	}
+	if !used {
+		return ErrInvalidMFACode
+	}
+	return nil
+}
+
+func (s *mfaService) RegenerateRecoveryCodes(userID, code string) ([]string, error) {
+	if err := s.Verify(userID, code); err != nil {
+		return nil, err
+	}
+	return s.replaceRecoveryCodes(userID)
+}
+
+func (s *mfaService) Disable(userID, code string) error {
+	required, err := s.MFARequired(userID)
+	if err != nil {
+		return err
+	}
+	if required {
+		return ErrMFARequired
+	}
+	if err := s.Verify(userID, code); err != nil {
+		return err
+	}
+	return s.mfaRepo.DeleteTOTP(userID)
+}
+
+func (s *mfaService) MFARequired(userID string) (bool, error) {
+	if len(s.requiredRoles) == 0 {
+		return false, nil
+	}
+	roles, err := s.mfaRepo.GetUserRoles(userID)
+	if err != nil {
+		return false, err
+	}
+	for _, role := range roles {
+		if s.requiredRoles[role] {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (s *mfaService) replaceRecoveryCodes(userID string) ([]string, error) {
+	codes := make([]string, recoveryCodeCount)
+	hashes := make([]string, recoveryCodeCount)
+	for i := range codes {
+		code, err := newRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+		codes[i] = code
+		hashes[i] = hashRecoveryCode(normalizeMFACode(code))
+	}
+	if err := s.mfaRepo.ReplaceRecoveryCodes(userID, hashes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+var recoveryCodeEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// newRecoveryCode returns a random code formatted for reading, such as
+// "k3vq7-m2xpa".
+func newRecoveryCode() (string, error) {
+	b := make([]byte, 7)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	code := strings.ToLower(recoveryCodeEncoding.EncodeToString(b))[:10]
+	return code[:5] + "-" + code[5:], nil
+}
+
+// normalizeMFACode drops the spaces and dashes people type or paste, and
+// lowercases recovery codes.
+func normalizeMFACode(code string) string {
+	return strings.ToLower(strings.NewReplacer(" ", "", "-", "").Replace(strings.TrimSpace(code)))
+}
+
+func hashRecoveryCode(code string) string {
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,191 @@
+package service
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"gaspeep/backend/internal/auth"
+	"gaspeep/backend/internal/models"
+	"gaspeep/backend/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockMFARepository is a mock implementation of MFARepository
+type MockMFARepository struct {
+	mock.Mock
+}
+
+func (m *MockMFARepository) GetTOTP(userID string) (*repository.TOTPCredential, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*repository.TOTPCredential), args.Error(1)
+}
+
+func (m *MockMFARepository) SaveTOTPSecret(userID, secret string) error {
+	args := m.Called(userID, secret)
+	return args.Error(0)
+}
+
+func (m *MockMFARepository) ConfirmTOTP(userID string, step int64) (bool, error) {
+	args := m.Called(userID, step)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockMFARepository) UseTOTPStep(userID string, step int64) (bool, error) {
+	args := m.Called(userID, step)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockMFARepository) DeleteTOTP(userID string) error {
+	args := m.Called(userID)
+	return args.Error(0)
+}
+
+func (m *MockMFARepository) ReplaceRecoveryCodes(userID string, codeHashes []string) error {
+	args := m.Called(userID, codeHashes)
+	return args.Error(0)
+}
+
+func (m *MockMFARepository) UseRecoveryCode(userID, codeHash string) (bool, error) {
+	args := m.Called(userID, codeHash)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockMFARepository) CountRecoveryCodes(userID string) (int, error) {
+	args := m.Called(userID)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockMFARepository) GetUserRoles(userID string) ([]string, error) {
+	args := m.Called(userID)
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func newTestMFAService(t *testing.T, repo *MockMFARepository, now time.Time) *mfaService {
+	t.Helper()
+	s := NewMFAService(repo).(*mfaService)
+	s.now = func() time.Time { return now }
+	return s
+}
+
+func TestMFAService_EnrolAndConfirm(t *testing.T) {
+	t.Setenv("MFA_ISSUER", "Gas Peep Test")
+	repo := new(MockMFARepository)
+	now := time.Unix(1_700_000_000, 0)
+	s := newTestMFAService(t, repo, now)
+
+	var secret string
+	repo.On("GetTOTP", "user-1").Return(nil, sql.ErrNoRows).Once()
+	repo.On("SaveTOTPSecret", "user-1", mock.Anything).Run(func(args mock.Arguments) {
+		secret = args.String(1)
+	}).Return(nil).Once()
+
+	enrolment, err := s.BeginTOTP(&models.User{ID: "user-1", Email: "a@example.com"})
+	require.NoError(t, err)
+	assert.Equal(t, secret, enrolment.Secret)
+	assert.Contains(t, enrolment.ProvisioningURI, "otpauth://totp/Gas%20Peep%20Test:a@example.com?")
+
+	repo.On("GetTOTP", "user-1").Return(&repository.TOTPCredential{UserID: "user-1", Secret: secret}, nil)
+	_, err = s.ConfirmTOTP("user-1", "000000")
+	assert.ErrorIs(t, err, ErrInvalidMFACode)
+
+	var hashes []string
+	repo.On("ConfirmTOTP", "user-1", now.Unix()/30).Return(true, nil).Once()
+	repo.On("ReplaceRecoveryCodes", "user-1", mock.Anything).Run(func(args mock.Arguments) {
+		hashes = args.Get(1).([]string)
+	}).Return(nil).Once()
+
+	code, err := auth.TOTPCode(secret, now)
+	require.NoError(t, err)
+	codes, err := s.ConfirmTOTP("user-1", code[:3]+" "+code[3:])
+	require.NoError(t, err)
+	require.Len(t, codes, recoveryCodeCount)
+	require.Len(t, hashes, recoveryCodeCount)
+
+	// Only hashes of the recovery codes are stored
+	assert.Regexp(t, `^[a-z2-7]{5}-[a-z2-7]{5}$`, codes[0])
+	assert.Equal(t, hashRecoveryCode(normalizeMFACode(codes[0])), hashes[0])
+	assert.NotContains(t, hashes, codes[0])
+	repo.AssertExpectations(t)
+}
+
+func TestMFAService_BeginTOTP_AlreadyEnabled(t *testing.T) {
+	repo := new(MockMFARepository)
+	s := newTestMFAService(t, repo, time.Now())
+
+	confirmed := time.Now()
+	repo.On("GetTOTP", "user-1").Return(&repository.TOTPCredential{Secret: "JBSWY3DPEHPK3PXP", ConfirmedAt: &confirmed}, nil)
+
+	_, err := s.BeginTOTP(&models.User{ID: "user-1"})
+	assert.ErrorIs(t, err, ErrMFAAlreadyEnabled)
+	repo.AssertNotCalled(t, "SaveTOTPSecret", mock.Anything, mock.Anything)
+}
+
+func TestMFAService_Verify(t *testing.T) {
+	repo := new(MockMFARepository)
+	now := time.Unix(1_700_000_000, 0)
+	s := newTestMFAService(t, repo, now)
+
+	const secret = "JBSWY3DPEHPK3PXP"
+	confirmed := now.Add(-time.Hour)
+	repo.On("GetTOTP", "user-1").Return(&repository.TOTPCredential{Secret: secret, ConfirmedAt: &confirmed}, nil)
+	code, err := auth.TOTPCode(secret, now)
+	require.NoError(t, err)
+
+	repo.On("UseTOTPStep", "user-1", now.Unix()/30).Return(true, nil).Once()
+	assert.NoError(t, s.Verify("user-1", code))
+
+	// A code cannot be used twice
+	repo.On("UseTOTPStep", "user-1", now.Unix()/30).Return(false, nil).Once()
+	assert.ErrorIs(t, s.Verify("user-1", code), ErrInvalidMFACode)
+
+	// Recovery codes are matched by hash, ignoring case and dashes
+	repo.On("UseRecoveryCode", "user-1", hashRecoveryCode("abcdefghij")).Return(true, nil).Once()
+	assert.NoError(t, s.Verify("user-1", "ABCDE-FGHIJ"))
+	repo.On("UseRecoveryCode", "user-1", hashRecoveryCode("abcdefghij")).Return(false, nil).Once()
+	assert.ErrorIs(t, s.Verify("user-1", "abcde-fghij"), ErrInvalidMFACode)
+
+	repo.On("GetTOTP", "user-2").Return(nil, sql.ErrNoRows)
+	assert.ErrorIs(t, s.Verify("user-2", code), ErrMFANotEnabled)
+	repo.AssertExpectations(t)
+}
+
+func TestMFAService_RequiredRoles(t *testing.T) {
+	t.Setenv("MFA_REQUIRED_ROLES", "Owner, moderator")
+	repo := new(MockMFARepository)
+	s := newTestMFAService(t, repo, time.Now())
+
+	repo.On("GetUserRoles", "owner").Return([]string{"owner"}, nil)
+	repo.On("GetUserRoles", "admin").Return([]string{"admin"}, nil)
+	repo.On("GetUserRoles", "user").Return([]string(nil), nil)
+
+	required, err := s.MFARequired("owner")
+	require.NoError(t, err)
+	assert.True(t, required)
+	required, err = s.MFARequired("admin")
+	require.NoError(t, err)
+	assert.False(t, required)
+	required, err = s.MFARequired("user")
+	require.NoError(t, err)
+	assert.False(t, required)
+
+	// Users who must use two-factor authentication cannot turn it off
+	assert.ErrorIs(t, s.Disable("owner", "123456"), ErrMFARequired)
+	repo.AssertNotCalled(t, "DeleteTOTP", mock.Anything)
+}
+
+func TestMFAService_NoRequiredRoles(t *testing.T) {
+	t.Setenv("MFA_REQUIRED_ROLES", "")
+	repo := new(MockMFARepository)
+	s := newTestMFAService(t, repo, time.Now())
+
+	required, err := s.MFARequired("owner")
+	require.NoError(t, err)
+	assert.False(t, required)
+	repo.AssertNotCalled(t, "GetUserRoles", mock.Anything)
+}
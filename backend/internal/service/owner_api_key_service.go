@@ -0,0 +1,378 @@
+package service
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+
+	"gaspeep/backend/internal/models"
+	"gaspeep/backend/internal/repository"
+)
+
+const (
+	// apiKeyPrefix starts every API key, so leaked keys are easy to spot.
+	apiKeyPrefix = "gpk_"
+	// apiKeyDisplayLength is how much of a key is kept to tell keys apart.
+	apiKeyDisplayLength = len(apiKeyPrefix) + 8
+	// MaxIntegrationPriceBatch is the most prices an integration can send in
+	// one request.
+	MaxIntegrationPriceBatch = 100
+	// MaxIdempotencyKeyLength is the longest Idempotency-Key accepted.
+	MaxIdempotencyKeyLength = 255
+	apiKeyUsageLimit        = 100
+	maxAPIKeyNameLength     = 100
+)
+
+// apiKeyScopes are the scopes a key can be given.
+var apiKeyScopes = []string{models.APIKeyScopePricesRead, models.APIKeyScopePricesWrite}
+
+// Outcomes of each price sent to the integration API, and why prices were
+// rejected.
+const (
+	IntegrationPricePublished = "published"
+	IntegrationPriceRejected  = "rejected"
+
+	IntegrationErrorInvalidPrice      = "invalid_price"
+	IntegrationErrorDuplicateFuelType = "duplicate_fuel_type"
+	IntegrationErrorUnknownFuelType   = "unknown_fuel_type"
+	IntegrationErrorClaimNotApproved  = "claim_not_approved"
+	IntegrationErrorPermissionDenied  = "permission_denied"
+	IntegrationErrorInternal          = "internal_error"
+)
+
+var (
+	ErrInvalidAPIKeyInput          = errors.New("invalid API key input")
+	ErrAPIKeyNotFound              = errors.New("API key not found")
+	ErrAPIKeyScopeMissing          = errors.New("API key lacks the required scope")
+	ErrAPIKeyStationNotAllowed     = errors.New("API key is not allowed for this station")
+	ErrInvalidIntegrationPrices    = errors.New("invalid integration prices")
+	ErrIdempotencyKeyReused        = errors.New("idempotency key reused for a different request")
+	ErrIdempotentRequestInProgress = errors.New("idempotent request still in progress")
+)
+
+// CreateAPIKeyInput describes a new API key.
+type CreateAPIKeyInput struct {
+	Name       string   `json:"name"`
+	Scopes     []string `json:"scopes"`
+	StationIDs []string `json:"stationIds"`
+}
+
+// IntegrationPriceEntry is one price sent by an integration.
+type IntegrationPriceEntry struct {
+	FuelTypeID string  `json:"fuelTypeId"`
+	Price      float64 `json:"price"`
+}
+
+// IntegrationPriceEntryResult is what happened to one price, in the order
+// sent. Error says why a price was rejected.
+type IntegrationPriceEntryResult struct {
+	FuelTypeID string  `json:"fuelTypeId"`
+	Price      float64 `json:"price"`
+	Status     string  `json:"status"`
+	Error      string  `json:"error,omitempty"`
+}
+
+// IntegrationPriceResult is the outcome of an integration's price update.
+// Replayed is set when it is the stored response to an earlier request with
+// the same idempotency key.
+type IntegrationPriceResult struct {
+	StationID string                        `json:"stationId"`
+	Published int                           `json:"published"`
+	Rejected  int                           `json:"rejected"`
+	Results   []IntegrationPriceEntryResult `json:"results"`
+	Replayed  bool                          `json:"replayed"`
+}
+
+// OwnerAPIKeyService issues station owners' API keys and serves the
+// integration API that point-of-sale and pricing systems call with them.
+type OwnerAPIKeyService interface {
+	// Create issues a key for some of the user's approved stations. The raw
+	// key is returned only here. It returns ErrInvalidAPIKeyInput, or the
+	// repository's ErrStationOwnerNotFound, ErrStationPermissionDenied or
+	// ErrStationClaimNotApproved.
+	Create(userID string, input CreateAPIKeyInput) (*models.OwnerAPIKey, string, error)
+	List(userID string) ([]models.OwnerAPIKey, error)
+	// Revoke stops a key working. It returns ErrAPIKeyNotFound.
+	Revoke(userID, keyID string) error
+	// Usage returns the latest requests made with a key. It returns
+	// ErrAPIKeyNotFound.
+	Usage(userID, keyID string) ([]models.OwnerAPIKeyUsage, error)
+
+	// Authenticate returns the key rawKey identifies, or nil if it is unknown
+	// or revoked.
+	Authenticate(rawKey string) (*models.OwnerAPIKey, error)
+	RecordUsage(usage models.OwnerAPIKeyUsage) error
+	// StationPrices returns a station's current prices. It returns
+	// ErrAPIKeyScopeMissing or ErrAPIKeyStationNotAllowed.
+	StationPrices(key *models.OwnerAPIKey, stationID string) ([]repository.StationPriceResult, error)
+	// PublishPrices publishes each price independently and reports what
+	// happened to each. When idempotencyKey is set, a retried request gets
+	// the first one's result without publishing again. It returns
+	// ErrAPIKeyScopeMissing, ErrAPIKeyStationNotAllowed,
+	// ErrInvalidIntegrationPrices, ErrIdempotencyKeyReused or
+	// ErrIdempotentRequestInProgress.
+	PublishPrices(key *models.OwnerAPIKey, stationID, idempotencyKey string, entries []IntegrationPriceEntry) (*IntegrationPriceResult, error)
+}
+
+type ownerAPIKeyService struct {
+	apiKeyRepo       repository.OwnerAPIKeyRepository
+	stationOwnerRepo repository.StationOwnerRepository
+	fuelPriceRepo    repository.FuelPriceRepository
+}
+
+func NewOwnerAPIKeyService(
+	apiKeyRepo repository.OwnerAPIKeyRepository,
+	stationOwnerRepo repository.StationOwnerRepository,
+	fuelPriceRepo repository.FuelPriceRepository,
+) OwnerAPIKeyService {
+	return &ownerAPIKeyService{
+		apiKeyRepo:       apiKeyRepo,
+		stationOwnerRepo: stationOwnerRepo,
+		fuelPriceRepo:    fuelPriceRepo,
+	}
+}
+
+// ownerID returns the ID of the user's business. Only its owners and managers
+// may manage API keys; staff get repository.ErrStationPermissionDenied.
+func (s *ownerAPIKeyService) ownerID(userID string) (string, error) {
+	owner, err := s.stationOwnerRepo.GetByUserID(userID)
+	if err != nil {
+		return "", err
+	}
+	if !managesTeam(owner.Role) {
+		return "", repository.ErrStationPermissionDenied
+	}
+	return owner.ID, nil
+}
+
+func (s *ownerAPIKeyService) Create(userID string, input CreateAPIKeyInput) (*models.OwnerAPIKey, string, error) {
+	name := strings.TrimSpace(input.Name)
+	if name == "" || len(name) > maxAPIKeyNameLength || len(input.Scopes) == 0 || len(input.StationIDs) == 0 {
+		return nil, "", ErrInvalidAPIKeyInput
+	}
+	for _, scope := range input.Scopes {
+		if !slices.Contains(apiKeyScopes, scope) {
+			return nil, "", ErrInvalidAPIKeyInput
+		}
+	}
+
+	ownerID, err := s.ownerID(userID)
+	if err != nil {
+		return nil, "", err
+	}
+	stations, err := s.stationOwnerRepo.GetStationsByOwnerUserID(userID)
+	if err != nil {
+		return nil, "", err
+	}
+	for _, stationID := range input.StationIDs {
+		if !slices.ContainsFunc(stations, func(station map[string]interface{}) bool {
+			return station["id"] == stationID && station["verificationStatus"] == "approved"
+		}) {
+			return nil, "", repository.ErrStationClaimNotApproved
+		}
+	}
+
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return nil, "", fmt.Errorf("failed to generate API key: %w", err)
+	}
+	rawKey := apiKeyPrefix + base64.RawURLEncoding.EncodeToString(b)
+
+	key := &models.OwnerAPIKey{
+		StationOwnerID: ownerID,
+		OwnerUserID:    userID,
+		Name:           name,
+		Prefix:         rawKey[:apiKeyDisplayLength],
+		Scopes:         slices.Compact(slices.Sorted(slices.Values(input.Scopes))),
+		StationIDs:     slices.Compact(slices.Sorted(slices.Values(input.StationIDs))),
+	}
+	if err := s.apiKeyRepo.Create(key, hashVerificationToken(rawKey), userID); err != nil {
+		return nil, "", err
+	}
+	return key, rawKey, nil
+}
+
+func (s *ownerAPIKeyService) List(userID string) ([]models.OwnerAPIKey, error) {
+	ownerID, err := s.ownerID(userID)
+	if errors.Is(err, repository.ErrStationOwnerNotFound) {
+		return []models.OwnerAPIKey{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s.apiKeyRepo.ListByOwner(ownerID)
+}
+
+func (s *ownerAPIKeyService) Revoke(userID, keyID string) error {
+	ownerID, err := s.ownerID(userID)
+	if errors.Is(err, repository.ErrStationOwnerNotFound) {
+		return ErrAPIKeyNotFound
+	}
+	if err != nil {
+		return err
+	}
+	err = s.apiKeyRepo.Revoke(ownerID, keyID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrAPIKeyNotFound
+	}
+	return err
+}
+
+func (s *ownerAPIKeyService) Usage(userID, keyID string) ([]models.OwnerAPIKeyUsage, error) {
+	ownerID, err := s.ownerID(userID)
+	if errors.Is(err, repository.ErrStationOwnerNotFound) {
+		return nil, ErrAPIKeyNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	key, err := s.apiKeyRepo.GetByID(ownerID, keyID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrAPIKeyNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s.apiKeyRepo.ListUsage(key.ID, apiKeyUsageLimit)
+}
+
+func (s *ownerAPIKeyService) Authenticate(rawKey string) (*models.OwnerAPIKey, error) {
+	if !strings.HasPrefix(rawKey, apiKeyPrefix) {
+		return nil, nil
+	}
+	key, err := s.apiKeyRepo.GetByHash(hashVerificationToken(rawKey))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return key, err
+}
+
+func (s *ownerAPIKeyService) RecordUsage(usage models.OwnerAPIKeyUsage) error {
+	return s.apiKeyRepo.RecordUsage(usage)
+}
+
+// authorize checks that key has scope and may be used for stationID.
+func authorize(key *models.OwnerAPIKey, scope, stationID string) error {
+	if !slices.Contains(key.Scopes, scope) {
+		return ErrAPIKeyScopeMissing
+	}
+	if !slices.Contains(key.StationIDs, stationID) {
+		return ErrAPIKeyStationNotAllowed
+	}
+	return nil
+}
+
+func (s *ownerAPIKeyService) StationPrices(key *models.OwnerAPIKey, stationID string) ([]repository.StationPriceResult, error) {
+	if err := authorize(key, models.APIKeyScopePricesRead, stationID); err != nil {
+		return nil, err
+	}
+	prices, err := s.fuelPriceRepo.GetStationPrices(stationID)
+	if err != nil {
+		return nil, err
+	}
+	if prices == nil {
+		prices = []repository.StationPriceResult{}
+	}
+	return prices, nil
+}
+
+func (s *ownerAPIKeyService) PublishPrices(key *models.OwnerAPIKey, stationID, idempotencyKey string, entries []IntegrationPriceEntry) (*IntegrationPriceResult, error) {
+	if err := authorize(key, models.APIKeyScopePricesWrite, stationID); err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 || len(entries) > MaxIntegrationPriceBatch || len(idempotencyKey) > MaxIdempotencyKeyLength {
+		return nil, ErrInvalidIntegrationPrices
+	}
+
+	if idempotencyKey == "" {
+		return s.publish(key, stationID, entries), nil
+	}
+
+	body, err := json.Marshal(entries)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash integration request: %w", err)
+	}
+	requestHash := hashVerificationToken(stationID + "\n" + string(body))
+	earlier, err := s.apiKeyRepo.BeginIdempotent(key.ID, idempotencyKey, requestHash)
+	if err != nil {
+		return nil, err
+	}
+	if earlier != nil {
+		if earlier.RequestHash != requestHash {
+			return nil, ErrIdempotencyKeyReused
+		}
+		if earlier.Response == nil {
+			return nil, ErrIdempotentRequestInProgress
+		}
+		var result IntegrationPriceResult
+		if err := json.Unmarshal(earlier.Response, &result); err != nil {
+			return nil, fmt.Errorf("failed to read stored integration response: %w", err)
+		}
+		result.Replayed = true
+		return &result, nil
+	}
+
+	// Entries published before a failure stay published, so the response is
+	// stored whatever happened and a retry never publishes them again
+	result := s.publish(key, stationID, entries)
+	response, err := json.Marshal(result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to store integration response: %w", err)
+	}
+	if err := s.apiKeyRepo.CompleteIdempotent(key.ID, idempotencyKey, response); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// publish publishes each entry on its own, so one bad price does not hold up
+// the rest. Entries that fail unexpectedly are rejected with
+// IntegrationErrorInternal.
+func (s *ownerAPIKeyService) publish(key *models.OwnerAPIKey, stationID string, entries []IntegrationPriceEntry) *IntegrationPriceResult {
+	result := &IntegrationPriceResult{
+		StationID: stationID,
+		Results:   make([]IntegrationPriceEntryResult, len(entries)),
+	}
+	seen := make(map[string]bool, len(entries))
+	for i, entry := range entries {
+		var reason string
+		switch {
+		case entry.FuelTypeID == "" || entry.Price <= 0 || entry.Price > maxFuelPrice:
+			reason = IntegrationErrorInvalidPrice
+		case seen[entry.FuelTypeID]:
+			reason = IntegrationErrorDuplicateFuelType
+		default:
+			seen[entry.FuelTypeID] = true
+			err := s.stationOwnerRepo.PublishPrices(key.OwnerUserID, []repository.OwnerPriceInput{
+				{StationID: stationID, FuelTypeID: entry.FuelTypeID, Price: entry.Price},
+			})
+			switch {
+			case errors.Is(err, repository.ErrUnknownFuelType):
+				reason = IntegrationErrorUnknownFuelType
+			case errors.Is(err, repository.ErrStationClaimNotApproved):
+				reason = IntegrationErrorClaimNotApproved
+			case errors.Is(err, repository.ErrStationPermissionDenied):
+				reason = IntegrationErrorPermissionDenied
+			case err != nil:
+				log.Printf("Failed to publish price from API key %s: %v", key.ID, err)
+				reason = IntegrationErrorInternal
+			}
+		}
+
+		result.Results[i] = IntegrationPriceEntryResult{FuelTypeID: entry.FuelTypeID, Price: entry.Price, Status: IntegrationPricePublished}
+		if reason != "" {
+			result.Results[i].Status = IntegrationPriceRejected
+			result.Results[i].Error = reason
+			result.Rejected++
+		} else {
+			result.Published++
+		}
+	}
+	return result
+}
@@ -0,0 +1,293 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"gaspeep/backend/internal/models"
+	"gaspeep/backend/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockOwnerAPIKeyRepository mocks OwnerAPIKeyRepository. Idempotency key
+// cleanup is not implemented.
+type MockOwnerAPIKeyRepository struct {
+	mock.Mock
+	repository.OwnerAPIKeyRepository
+}
+
+func (m *MockOwnerAPIKeyRepository) Create(key *models.OwnerAPIKey, keyHash, createdBy string) error {
+	args := m.Called(key, keyHash, createdBy)
+	return args.Error(0)
+}
+
+func (m *MockOwnerAPIKeyRepository) ListByOwner(stationOwnerID string) ([]models.OwnerAPIKey, error) {
+	args := m.Called(stationOwnerID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.OwnerAPIKey), args.Error(1)
+}
+
+func (m *MockOwnerAPIKeyRepository) GetByID(stationOwnerID, keyID string) (*models.OwnerAPIKey, error) {
+	args := m.Called(stationOwnerID, keyID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.OwnerAPIKey), args.Error(1)
+}
+
+func (m *MockOwnerAPIKeyRepository) GetByHash(keyHash string) (*models.OwnerAPIKey, error) {
+	args := m.Called(keyHash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.OwnerAPIKey), args.Error(1)
+}
+
+func (m *MockOwnerAPIKeyRepository) Revoke(stationOwnerID, keyID string) error {
+	args := m.Called(stationOwnerID, keyID)
+	return args.Error(0)
+}
+
+func (m *MockOwnerAPIKeyRepository) ListUsage(keyID string, limit int) ([]models.OwnerAPIKeyUsage, error) {
+	args := m.Called(keyID, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.OwnerAPIKeyUsage), args.Error(1)
+}
+
+func (m *MockOwnerAPIKeyRepository) BeginIdempotent(keyID, idempotencyKey, requestHash string) (*repository.IdempotentRequest, error) {
+	args := m.Called(keyID, idempotencyKey, requestHash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*repository.IdempotentRequest), args.Error(1)
+}
+
+func (m *MockOwnerAPIKeyRepository) CompleteIdempotent(keyID, idempotencyKey string, response []byte) error {
+	args := m.Called(keyID, idempotencyKey, response)
+	return args.Error(0)
+}
+
+func setupOwnerAPIKeyTest() (OwnerAPIKeyService, *MockOwnerAPIKeyRepository, *MockStationOwnerRepositoryForOwnerService, *MockFuelPriceRepositoryTest) {
+	keyRepo := new(MockOwnerAPIKeyRepository)
+	ownerRepo := new(MockStationOwnerRepositoryForOwnerService)
+	priceRepo := new(MockFuelPriceRepositoryTest)
+	return NewOwnerAPIKeyService(keyRepo, ownerRepo, priceRepo), keyRepo, ownerRepo, priceRepo
+}
+
+func TestOwnerAPIKey_CreateStoresHashOnly(t *testing.T) {
+	svc, keyRepo, ownerRepo, _ := setupOwnerAPIKeyTest()
+
+	ownerRepo.On("GetByUserID", "user-1").Return(&models.StationOwner{ID: "owner-1", UserID: "user-1", Role: models.OwnerRoleOwner}, nil)
+	ownerRepo.On("GetStationsByOwnerUserID", "user-1").Return([]map[string]interface{}{
+		{"id": "station-1", "verificationStatus": "approved"},
+		{"id": "station-2", "verificationStatus": "pending"},
+	}, nil)
+	var keyHash string
+	keyRepo.On("Create", mock.Anything, mock.Anything, "user-1").
+		Run(func(args mock.Arguments) { keyHash = args.String(1) }).Return(nil).Once()
+
+	key, rawKey, err := svc.Create("user-1", CreateAPIKeyInput{
+		Name:       " Till system ",
+		Scopes:     []string{models.APIKeyScopePricesWrite, models.APIKeyScopePricesRead, models.APIKeyScopePricesWrite},
+		StationIDs: []string{"station-1"},
+	})
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(rawKey, "gpk_"))
+	assert.Equal(t, hashVerificationToken(rawKey), keyHash)
+	assert.Equal(t, rawKey[:len(key.Prefix)], key.Prefix)
+	assert.Equal(t, "Till system", key.Name)
+	assert.Equal(t, "owner-1", key.StationOwnerID)
+	assert.Equal(t, []string{models.APIKeyScopePricesRead, models.APIKeyScopePricesWrite}, key.Scopes)
+
+	// Keys are only issued for approved stations and known scopes
+	_, _, err = svc.Create("user-1", CreateAPIKeyInput{Name: "Till", Scopes: []string{models.APIKeyScopePricesWrite}, StationIDs: []string{"station-2"}})
+	assert.ErrorIs(t, err, repository.ErrStationClaimNotApproved)
+	_, _, err = svc.Create("user-1", CreateAPIKeyInput{Name: "Till", Scopes: []string{"admin"}, StationIDs: []string{"station-1"}})
+	assert.ErrorIs(t, err, ErrInvalidAPIKeyInput)
+	keyRepo.AssertExpectations(t)
+}
+
+func TestOwnerAPIKey_Authenticate(t *testing.T) {
+	svc, keyRepo, _, _ := setupOwnerAPIKeyTest()
+
+	key := &models.OwnerAPIKey{ID: "key-1"}
+	keyRepo.On("GetByHash", hashVerificationToken("gpk_good")).Return(key, nil)
+	keyRepo.On("GetByHash", hashVerificationToken("gpk_revoked")).Return(nil, sql.ErrNoRows)
+
+	got, err := svc.Authenticate("gpk_good")
+	require.NoError(t, err)
+	assert.Equal(t, key, got)
+
+	got, err = svc.Authenticate("gpk_revoked")
+	require.NoError(t, err)
+	assert.Nil(t, got)
+
+	got, err = svc.Authenticate("not-a-key")
+	require.NoError(t, err)
+	assert.Nil(t, got)
+}
+
+func TestOwnerAPIKey_RevokeAndUsageOfAnotherOwnersKey(t *testing.T) {
+	svc, keyRepo, ownerRepo, _ := setupOwnerAPIKeyTest()
+
+	ownerRepo.On("GetByUserID", "user-1").Return(&models.StationOwner{ID: "owner-1", Role: models.OwnerRoleManager}, nil)
+	keyRepo.On("Revoke", "owner-1", "key-2").Return(sql.ErrNoRows)
+	keyRepo.On("GetByID", "owner-1", "key-2").Return(nil, sql.ErrNoRows)
+
+	assert.ErrorIs(t, svc.Revoke("user-1", "key-2"), ErrAPIKeyNotFound)
+	_, err := svc.Usage("user-1", "key-2")
+	assert.ErrorIs(t, err, ErrAPIKeyNotFound)
+}
+
+func integrationKey(scopes ...string) *models.OwnerAPIKey {
+	return &models.OwnerAPIKey{ID: "key-1", OwnerUserID: "user-1", Scopes: scopes, StationIDs: []string{"station-1"}}
+}
+
+func TestOwnerAPIKey_PublishPricesReportsEachEntry(t *testing.T) {
+	svc, _, ownerRepo, _ := setupOwnerAPIKeyTest()
+	key := integrationKey(models.APIKeyScopePricesWrite)
+
+	ownerRepo.On("PublishPrices", "user-1", []repository.OwnerPriceInput{{StationID: "station-1", FuelTypeID: "e10", Price: 1.799}}).Return(nil).Once()
+	ownerRepo.On("PublishPrices", "user-1", []repository.OwnerPriceInput{{StationID: "station-1", FuelTypeID: "lpg", Price: 0.999}}).Return(repository.ErrUnknownFuelType).Once()
+
+	result, err := svc.PublishPrices(key, "station-1", "", []IntegrationPriceEntry{
+		{FuelTypeID: "e10", Price: 1.799},
+		{FuelTypeID: "lpg", Price: 0.999},
+		{FuelTypeID: "e10", Price: 1.789},
+		{FuelTypeID: "u91", Price: -1},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Published)
+	assert.Equal(t, 3, result.Rejected)
+	assert.Equal(t, []IntegrationPriceEntryResult{
+		{FuelTypeID: "e10", Price: 1.799, Status: IntegrationPricePublished},
+		{FuelTypeID: "lpg", Price: 0.999, Status: IntegrationPriceRejected, Error: IntegrationErrorUnknownFuelType},
+		{FuelTypeID: "e10", Price: 1.789, Status: IntegrationPriceRejected, Error: IntegrationErrorDuplicateFuelType},
+		{FuelTypeID: "u91", Price: -1, Status: IntegrationPriceRejected, Error: IntegrationErrorInvalidPrice},
+	}, result.Results)
+	ownerRepo.AssertExpectations(t)
+}
+
+// TestOwnerAPIKey_PublishPricesRejectsWithoutPermission tests that a key
+// whose creator can no longer publish rejects each price instead of failing
+// the request
+func TestOwnerAPIKey_PublishPricesRejectsWithoutPermission(t *testing.T) {
+	svc, keyRepo, ownerRepo, _ := setupOwnerAPIKeyTest()
+	key := integrationKey(models.APIKeyScopePricesWrite)
+
+	keyRepo.On("BeginIdempotent", "key-1", "retry-1", mock.Anything).Return(nil, nil).Once()
+	ownerRepo.On("PublishPrices", "user-1", mock.Anything).Return(repository.ErrStationPermissionDenied).Once()
+	keyRepo.On("CompleteIdempotent", "key-1", "retry-1", mock.Anything).Return(nil).Once()
+
+	result, err := svc.PublishPrices(key, "station-1", "retry-1", []IntegrationPriceEntry{{FuelTypeID: "e10", Price: 1.799}})
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Rejected)
+	assert.Equal(t, IntegrationErrorPermissionDenied, result.Results[0].Error)
+	keyRepo.AssertExpectations(t)
+}
+
+func TestOwnerAPIKey_PublishPricesChecksScopeAndStation(t *testing.T) {
+	svc, _, ownerRepo, _ := setupOwnerAPIKeyTest()
+	entries := []IntegrationPriceEntry{{FuelTypeID: "e10", Price: 1.799}}
+
+	_, err := svc.PublishPrices(integrationKey(models.APIKeyScopePricesRead), "station-1", "", entries)
+	assert.ErrorIs(t, err, ErrAPIKeyScopeMissing)
+	_, err = svc.PublishPrices(integrationKey(models.APIKeyScopePricesWrite), "station-9", "", entries)
+	assert.ErrorIs(t, err, ErrAPIKeyStationNotAllowed)
+	_, err = svc.PublishPrices(integrationKey(models.APIKeyScopePricesWrite), "station-1", "", nil)
+	assert.ErrorIs(t, err, ErrInvalidIntegrationPrices)
+	ownerRepo.AssertNotCalled(t, "PublishPrices", mock.Anything, mock.Anything)
+}
+
+func TestOwnerAPIKey_PublishPricesIdempotency(t *testing.T) {
+	svc, keyRepo, ownerRepo, _ := setupOwnerAPIKeyTest()
+	key := integrationKey(models.APIKeyScopePricesWrite)
+	entries := []IntegrationPriceEntry{{FuelTypeID: "e10", Price: 1.799}}
+
+	// The first request publishes and stores its response
+	var requestHash string
+	var stored []byte
+	keyRepo.On("BeginIdempotent", "key-1", "req-1", mock.Anything).
+		Run(func(args mock.Arguments) { requestHash = args.String(2) }).Return(nil, nil).Once()
+	ownerRepo.On("PublishPrices", "user-1", mock.Anything).Return(nil).Once()
+	keyRepo.On("CompleteIdempotent", "key-1", "req-1", mock.Anything).
+		Run(func(args mock.Arguments) { stored = args.Get(2).([]byte) }).Return(nil).Once()
+
+	first, err := svc.PublishPrices(key, "station-1", "req-1", entries)
+	require.NoError(t, err)
+	assert.False(t, first.Replayed)
+
+	// A retry gets the stored response without publishing again
+	keyRepo.On("BeginIdempotent", "key-1", "req-1", requestHash).
+		Return(&repository.IdempotentRequest{RequestHash: requestHash, Response: stored}, nil).Once()
+	retry, err := svc.PublishPrices(key, "station-1", "req-1", entries)
+	require.NoError(t, err)
+	assert.True(t, retry.Replayed)
+	assert.Equal(t, first.Results, retry.Results)
+
+	// Reusing the key for another request, or while the first is running, fails
+	keyRepo.On("BeginIdempotent", "key-1", "req-1", mock.Anything).
+		Return(&repository.IdempotentRequest{RequestHash: requestHash, Response: stored}, nil).Once()
+	_, err = svc.PublishPrices(key, "station-1", "req-1", []IntegrationPriceEntry{{FuelTypeID: "e10", Price: 1.899}})
+	assert.ErrorIs(t, err, ErrIdempotencyKeyReused)
+
+	keyRepo.On("BeginIdempotent", "key-1", "req-2", mock.Anything).
+		Return(&repository.IdempotentRequest{RequestHash: requestHash}, nil).Once()
+	_, err = svc.PublishPrices(key, "station-1", "req-2", entries)
+	assert.ErrorIs(t, err, ErrIdempotentRequestInProgress)
+
+	ownerRepo.AssertNumberOfCalls(t, "PublishPrices", 1)
+	keyRepo.AssertExpectations(t)
+}
+
+// TestOwnerAPIKey_PublishPricesStoresPartialFailures tests that a price that
+// fails unexpectedly is rejected and the response still stored, so a retry
+// does not publish the prices before it again
+func TestOwnerAPIKey_PublishPricesStoresPartialFailures(t *testing.T) {
+	svc, keyRepo, ownerRepo, _ := setupOwnerAPIKeyTest()
+	key := integrationKey(models.APIKeyScopePricesWrite)
+
+	keyRepo.On("BeginIdempotent", "key-1", "req-1", mock.Anything).Return(nil, nil).Once()
+	ownerRepo.On("PublishPrices", "user-1", []repository.OwnerPriceInput{{StationID: "station-1", FuelTypeID: "e10", Price: 179.9}}).Return(nil).Once()
+	ownerRepo.On("PublishPrices", "user-1", []repository.OwnerPriceInput{{StationID: "station-1", FuelTypeID: "u91", Price: 189.9}}).Return(errors.New("db down")).Once()
+	keyRepo.On("CompleteIdempotent", "key-1", "req-1", mock.Anything).Return(nil).Once()
+
+	result, err := svc.PublishPrices(key, "station-1", "req-1", []IntegrationPriceEntry{
+		{FuelTypeID: "e10", Price: 179.9},
+		{FuelTypeID: "u91", Price: 189.9},
+		{FuelTypeID: "lpg", Price: 9990},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Published)
+	assert.Equal(t, []IntegrationPriceEntryResult{
+		{FuelTypeID: "e10", Price: 179.9, Status: IntegrationPricePublished},
+		{FuelTypeID: "u91", Price: 189.9, Status: IntegrationPriceRejected, Error: IntegrationErrorInternal},
+		{FuelTypeID: "lpg", Price: 9990, Status: IntegrationPriceRejected, Error: IntegrationErrorInvalidPrice},
+	}, result.Results)
+	keyRepo.AssertExpectations(t)
+	ownerRepo.AssertExpectations(t)
+}
+
+func TestOwnerAPIKey_StationPricesNeedsReadScope(t *testing.T) {
+	svc, _, _, priceRepo := setupOwnerAPIKeyTest()
+
+	updated := time.Now()
+	prices := []repository.StationPriceResult{{StationID: "station-1", FuelTypeID: "e10", Price: 1.799, LastUpdatedAt: &updated, Source: repository.PriceChangeSourceOwner}}
+	priceRepo.On("GetStationPrices", "station-1").Return(prices, nil)
+
+	got, err := svc.StationPrices(integrationKey(models.APIKeyScopePricesRead), "station-1")
+	require.NoError(t, err)
+	assert.Equal(t, prices, got)
+
+	_, err = svc.StationPrices(integrationKey(models.APIKeyScopePricesWrite), "station-1")
+	assert.ErrorIs(t, err, ErrAPIKeyScopeMissing)
+}
@@ -0,0 +1,363 @@
+package service
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"gaspeep/backend/internal/models"
+	"gaspeep/backend/internal/repository"
+)
+
+// ownerTeamInvitationTTL is how long an invitation's accept link works.
+const ownerTeamInvitationTTL = 7 * 24 * time.Hour
+
+var stationPermissions = []string{
+	models.StationPermissionPublishPrices,
+	models.StationPermissionSendBroadcasts,
+	models.StationPermissionViewAnalytics,
+}
+
+var (
+	// ErrInvalidTeamInput is returned for an unknown role, a malformed email
+	// address, or staff station permissions that are empty, unknown or for
+	// a station the business does not own.
+	ErrInvalidTeamInput = errors.New("invalid team input")
+	// ErrTeamMemberNotFound is returned for a member of another business.
+	ErrTeamMemberNotFound = errors.New("team member not found")
+	// ErrTeamInvitationNotFound is returned for an invitation that is not
+	// pending or belongs to another business.
+	ErrTeamInvitationNotFound = errors.New("team invitation not found")
+	// ErrInvalidTeamInvitation is returned when an accept link's token is
+	// unknown, expired, revoked or already used.
+	ErrInvalidTeamInvitation = errors.New("invalid or expired team invitation")
+	// ErrTeamInvitationEmailMismatch is returned when a user accepts an
+	// invitation sent to another email address.
+	ErrTeamInvitationEmailMismatch = errors.New("team invitation was sent to another email address")
+)
+
+// OwnerTeam is a business's members and pending invitations.
+type OwnerTeam struct {
+	Members     []models.OwnerTeamMember     `json:"members"`
+	Invitations []models.OwnerTeamInvitation `json:"invitations"`
+}
+
+// TeamInvitationInput invites someone to a business. Stations are only used
+// for staff.
+type TeamInvitationInput struct {
+	Email    string                      `json:"email" binding:"required"`
+	Role     string                      `json:"role" binding:"required"`
+	Stations []models.StationPermissions `json:"stations"`
+}
+
+// TeamMemberInput changes a member's role and, for staff, station
+// permissions.
+type TeamMemberInput struct {
+	Role     string                      `json:"role" binding:"required"`
+	Stations []models.StationPermissions `json:"stations"`
+}
+
+// OwnerTeamService manages the members of station owner businesses. Owners
+// and managers run the team, but managers may only invite and manage staff.
+// Other methods return the repository's ErrStationOwnerNotFound for users
+// outside any business and ErrStationPermissionDenied for those without the
+// role they need.
+type OwnerTeamService interface {
+	// Me returns the user's own membership.
+	Me(userID string) (*models.OwnerTeamMember, error)
+	Team(userID string) (*OwnerTeam, error)
+	// Invite emails an accept link to input.Email. It returns
+	// ErrInvalidTeamInput.
+	Invite(userID string, input TeamInvitationInput) (*models.OwnerTeamInvitation, error)
+	// RevokeInvitation returns ErrTeamInvitationNotFound.
+	RevokeInvitation(userID, invitationID string) error
+	// UpdateMember returns ErrInvalidTeamInput, ErrTeamMemberNotFound or the
+	// repository's ErrAccountHolderMember.
+	UpdateMember(userID, memberID string, input TeamMemberInput) (*models.OwnerTeamMember, error)
+	// RemoveMember returns ErrTeamMemberNotFound or the repository's
+	// ErrAccountHolderMember.
+	RemoveMember(userID, memberID string) error
+	// AcceptInvitation adds the user to the business that invited them. It
+	// returns ErrEmailNotVerified, ErrInvalidTeamInvitation,
+	// ErrTeamInvitationEmailMismatch or the repository's
+	// ErrAlreadyTeamMember.
+	AcceptInvitation(userID, token string) (*models.OwnerTeamMember, error)
+}
+
+type ownerTeamService struct {
+	teamRepo         repository.OwnerTeamRepository
+	stationOwnerRepo repository.StationOwnerRepository
+	userRepo         repository.UserRepository
+	emailService     EmailService
+	verification     EmailVerificationPolicy
+	now              func() time.Time
+}
+
+func NewOwnerTeamService(
+	teamRepo repository.OwnerTeamRepository,
+	stationOwnerRepo repository.StationOwnerRepository,
+	userRepo repository.UserRepository,
+	emailService EmailService,
+	verification EmailVerificationPolicy,
+) OwnerTeamService {
+	return &ownerTeamService{
+		teamRepo:         teamRepo,
+		stationOwnerRepo: stationOwnerRepo,
+		userRepo:         userRepo,
+		emailService:     emailService,
+		verification:     verification,
+		now:              time.Now,
+	}
+}
+
+// managesTeam reports whether role may run a business's team and use all of
+// its stations.
+func managesTeam(role string) bool {
+	return role == models.OwnerRoleOwner || role == models.OwnerRoleManager
+}
+
+// manager returns the user's business, or ErrStationPermissionDenied unless
+// they are one of its owners or managers.
+func (s *ownerTeamService) manager(userID string) (*models.StationOwner, error) {
+	owner, err := s.stationOwnerRepo.GetByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if !managesTeam(owner.Role) {
+		return nil, repository.ErrStationPermissionDenied
+	}
+	return owner, nil
+}
+
+// mayAssign reports whether a member with role may give others assigned.
+func mayAssign(role, assigned string) bool {
+	return role == models.OwnerRoleOwner || assigned == models.OwnerRoleStaff
+}
+
+// checkStations validates staff station permissions against the stations of
+// the user's business, filling in station names. Other roles have access to
+// every station, so their stations are dropped.
+func (s *ownerTeamService) checkStations(userID, role string, stations []models.StationPermissions) ([]models.StationPermissions, error) {
+	switch role {
+	case models.OwnerRoleOwner, models.OwnerRoleManager:
+		return []models.StationPermissions{}, nil
+	case models.OwnerRoleStaff:
+	default:
+		return nil, ErrInvalidTeamInput
+	}
+	if len(stations) == 0 {
+		return nil, ErrInvalidTeamInput
+	}
+
+	owned, err := s.stationOwnerRepo.GetStationsByOwnerUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[string]string, len(owned))
+	for _, station := range owned {
+		id, _ := station["id"].(string)
+		name, _ := station["name"].(string)
+		names[id] = name
+	}
+
+	checked := make([]models.StationPermissions, 0, len(stations))
+	seen := make(map[string]bool, len(stations))
+	for _, st := range stations {
+		name, ok := names[st.StationID]
+		if !ok || seen[st.StationID] || len(st.Permissions) == 0 {
+			return nil, ErrInvalidTeamInput
+		}
+		seen[st.StationID] = true
+		for _, p := range st.Permissions {
+			if !slices.Contains(stationPermissions, p) {
+				return nil, ErrInvalidTeamInput
+			}
+		}
+		permissions := slices.Clone(st.Permissions)
+		slices.Sort(permissions)
+		checked = append(checked, models.StationPermissions{
+			StationID:   st.StationID,
+			StationName: name,
+			Permissions: slices.Compact(permissions),
+		})
+	}
+	return checked, nil
+}
+
+// teamMember returns a member of the business, or ErrTeamMemberNotFound.
+func (s *ownerTeamService) teamMember(stationOwnerID, memberID string) (*models.OwnerTeamMember, error) {
+	members, err := s.teamRepo.ListMembers(stationOwnerID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range members {
+		if members[i].ID == memberID {
+			return &members[i], nil
+		}
+	}
+	return nil, ErrTeamMemberNotFound
+}
+
+func (s *ownerTeamService) Me(userID string) (*models.OwnerTeamMember, error) {
+	return s.teamRepo.GetMember(userID)
+}
+
+func (s *ownerTeamService) Team(userID string) (*OwnerTeam, error) {
+	owner, err := s.manager(userID)
+	if err != nil {
+		return nil, err
+	}
+	members, err := s.teamRepo.ListMembers(owner.ID)
+	if err != nil {
+		return nil, err
+	}
+	invitations, err := s.teamRepo.ListInvitations(owner.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &OwnerTeam{Members: members, Invitations: invitations}, nil
+}
+
+func (s *ownerTeamService) Invite(userID string, input TeamInvitationInput) (*models.OwnerTeamInvitation, error) {
+	owner, err := s.manager(userID)
+	if err != nil {
+		return nil, err
+	}
+	email := strings.ToLower(strings.TrimSpace(input.Email))
+	if addr, err := mail.ParseAddress(email); err != nil || addr.Address != email {
+		return nil, ErrInvalidTeamInput
+	}
+	stations, err := s.checkStations(userID, input.Role, input.Stations)
+	if err != nil {
+		return nil, err
+	}
+	if !mayAssign(owner.Role, input.Role) {
+		return nil, repository.ErrStationPermissionDenied
+	}
+	inviter, err := s.userRepo.GetUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return nil, fmt.Errorf("failed to generate invitation token: %w", err)
+	}
+	token := hex.EncodeToString(b)
+
+	inv := &models.OwnerTeamInvitation{
+		StationOwnerID: owner.ID,
+		BusinessName:   owner.BusinessName,
+		Email:          email,
+		Role:           input.Role,
+		Stations:       stations,
+		InvitedBy:      &userID,
+		ExpiresAt:      s.now().Add(ownerTeamInvitationTTL),
+	}
+	if err := s.teamRepo.CreateInvitation(inv, hashVerificationToken(token)); err != nil {
+		return nil, err
+	}
+
+	inviterName := inviter.DisplayName
+	if inviterName == "" {
+		inviterName = inviter.Email
+	}
+	acceptURL := appURL("/station-owner/invitations/accept?token=" + url.QueryEscape(token))
+	if err := s.emailService.SendOwnerTeamInvitation(email, owner.BusinessName, inviterName, input.Role, acceptURL); err != nil {
+		return nil, err
+	}
+	return inv, nil
+}
+
+func (s *ownerTeamService) RevokeInvitation(userID, invitationID string) error {
+	owner, err := s.manager(userID)
+	if err != nil {
+		return err
+	}
+	err = s.teamRepo.RevokeInvitation(owner.ID, invitationID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrTeamInvitationNotFound
+	}
+	return err
+}
+
+// UpdateMember lets managers change staff members' permissions but not
+// promote them.
+func (s *ownerTeamService) UpdateMember(userID, memberID string, input TeamMemberInput) (*models.OwnerTeamMember, error) {
+	owner, err := s.manager(userID)
+	if err != nil {
+		return nil, err
+	}
+	stations, err := s.checkStations(userID, input.Role, input.Stations)
+	if err != nil {
+		return nil, err
+	}
+	member, err := s.teamMember(owner.ID, memberID)
+	if err != nil {
+		return nil, err
+	}
+	if !mayAssign(owner.Role, member.Role) || !mayAssign(owner.Role, input.Role) {
+		return nil, repository.ErrStationPermissionDenied
+	}
+
+	updated, err := s.teamRepo.UpdateMember(owner.ID, memberID, input.Role, stations)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrTeamMemberNotFound
+	}
+	return updated, err
+}
+
+func (s *ownerTeamService) RemoveMember(userID, memberID string) error {
+	owner, err := s.manager(userID)
+	if err != nil {
+		return err
+	}
+	member, err := s.teamMember(owner.ID, memberID)
+	if err != nil {
+		return err
+	}
+	if !mayAssign(owner.Role, member.Role) {
+		return repository.ErrStationPermissionDenied
+	}
+
+	err = s.teamRepo.RemoveMember(owner.ID, memberID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrTeamMemberNotFound
+	}
+	return err
+}
+
+// AcceptInvitation requires a verified email address matching the one the
+// invitation was sent to, so a forwarded link cannot be used by someone else.
+func (s *ownerTeamService) AcceptInvitation(userID, token string) (*models.OwnerTeamMember, error) {
+	if err := s.verification.RequireVerifiedEmail(userID); err != nil {
+		return nil, err
+	}
+	inv, err := s.teamRepo.GetInvitationByToken(hashVerificationToken(token))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrInvalidTeamInvitation
+	}
+	if err != nil {
+		return nil, err
+	}
+	user, err := s.userRepo.GetUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.EqualFold(strings.TrimSpace(user.Email), inv.Email) {
+		return nil, ErrTeamInvitationEmailMismatch
+	}
+
+	member, err := s.teamRepo.AcceptInvitation(inv.ID, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrInvalidTeamInvitation
+	}
+	return member, err
+}
@@ -0,0 +1,280 @@
+package service
+
+import (
+	"database/sql"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"gaspeep/backend/internal/models"
+	"gaspeep/backend/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockOwnerTeamRepository mocks the OwnerTeamRepository interface
+type MockOwnerTeamRepository struct {
+	mock.Mock
+}
+
+func (m *MockOwnerTeamRepository) GetMember(userID string) (*models.OwnerTeamMember, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.OwnerTeamMember), args.Error(1)
+}
+
+func (m *MockOwnerTeamRepository) ListMembers(stationOwnerID string) ([]models.OwnerTeamMember, error) {
+	args := m.Called(stationOwnerID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.OwnerTeamMember), args.Error(1)
+}
+
+func (m *MockOwnerTeamRepository) UpdateMember(stationOwnerID, memberID, role string, stations []models.StationPermissions) (*models.OwnerTeamMember, error) {
+	args := m.Called(stationOwnerID, memberID, role, stations)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.OwnerTeamMember), args.Error(1)
+}
+
+func (m *MockOwnerTeamRepository) RemoveMember(stationOwnerID, memberID string) error {
+	args := m.Called(stationOwnerID, memberID)
+	return args.Error(0)
+}
+
+func (m *MockOwnerTeamRepository) CreateInvitation(inv *models.OwnerTeamInvitation, tokenHash string) error {
+	args := m.Called(inv, tokenHash)
+	return args.Error(0)
+}
+
+func (m *MockOwnerTeamRepository) ListInvitations(stationOwnerID string) ([]models.OwnerTeamInvitation, error) {
+	args := m.Called(stationOwnerID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.OwnerTeamInvitation), args.Error(1)
+}
+
+func (m *MockOwnerTeamRepository) RevokeInvitation(stationOwnerID, invitationID string) error {
+	args := m.Called(stationOwnerID, invitationID)
+	return args.Error(0)
+}
+
+func (m *MockOwnerTeamRepository) GetInvitationByToken(tokenHash string) (*models.OwnerTeamInvitation, error) {
+	args := m.Called(tokenHash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.OwnerTeamInvitation), args.Error(1)
+}
+
+func (m *MockOwnerTeamRepository) AcceptInvitation(invitationID, userID string) (*models.OwnerTeamMember, error) {
+	args := m.Called(invitationID, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.OwnerTeamMember), args.Error(1)
+}
+
+// MockUserRepositoryForOwnerTeam mocks the user lookups made for invitations.
+// Other UserRepository methods are not implemented.
+type MockUserRepositoryForOwnerTeam struct {
+	mock.Mock
+	repository.UserRepository
+}
+
+func (m *MockUserRepositoryForOwnerTeam) GetUserByID(id string) (*models.User, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.User), args.Error(1)
+}
+
+// MockEmailServiceForOwnerTeam mocks the invitation email. Other EmailService
+// methods are not implemented.
+type MockEmailServiceForOwnerTeam struct {
+	mock.Mock
+	EmailService
+}
+
+func (m *MockEmailServiceForOwnerTeam) SendOwnerTeamInvitation(toEmail, businessName, inviterName, role, acceptURL string) error {
+	args := m.Called(toEmail, businessName, inviterName, role, acceptURL)
+	return args.Error(0)
+}
+
+type ownerTeamTest struct {
+	service   *ownerTeamService
+	teamRepo  *MockOwnerTeamRepository
+	ownerRepo *MockStationOwnerRepository
+	userRepo  *MockUserRepositoryForOwnerTeam
+	emails    *MockEmailServiceForOwnerTeam
+}
+
+func setupOwnerTeamTest(verification EmailVerificationPolicy) ownerTeamTest {
+	tt := ownerTeamTest{
+		teamRepo:  new(MockOwnerTeamRepository),
+		ownerRepo: new(MockStationOwnerRepository),
+		userRepo:  new(MockUserRepositoryForOwnerTeam),
+		emails:    new(MockEmailServiceForOwnerTeam),
+	}
+	tt.service = NewOwnerTeamService(tt.teamRepo, tt.ownerRepo, tt.userRepo, tt.emails, verification).(*ownerTeamService)
+	tt.service.now = func() time.Time { return time.Date(2026, 5, 1, 9, 0, 0, 0, time.UTC) }
+	return tt
+}
+
+// asRole makes user-1 a member of owner-1 with role, owning station-1.
+func (tt ownerTeamTest) asRole(role string) {
+	tt.ownerRepo.On("GetByUserID", "user-1").Return(&models.StationOwner{ID: "owner-1", BusinessName: "Acme Fuel", Role: role}, nil)
+	tt.ownerRepo.On("GetStationsByOwnerUserID", "user-1").Return([]map[string]interface{}{
+		{"id": "station-1", "name": "Acme Parramatta"},
+	}, nil).Maybe()
+}
+
+func TestOwnerTeam_InviteEmailsAcceptLink(t *testing.T) {
+	t.Setenv("APP_BASE_URL", "https://app.example.com")
+	tt := setupOwnerTeamTest(allowAllEmailVerification{})
+	tt.asRole(models.OwnerRoleManager)
+	tt.userRepo.On("GetUserByID", "user-1").Return(&models.User{ID: "user-1", Email: "sam@acme.example", DisplayName: "Sam"}, nil)
+
+	var tokenHash string
+	tt.teamRepo.On("CreateInvitation", mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) { tokenHash = args.String(1) }).Return(nil).Once()
+	var link string
+	tt.emails.On("SendOwnerTeamInvitation", "kim@example.com", "Acme Fuel", "Sam", models.OwnerRoleStaff, mock.Anything).
+		Run(func(args mock.Arguments) { link = args.String(4) }).Return(nil).Once()
+
+	inv, err := tt.service.Invite("user-1", TeamInvitationInput{
+		Email: "  Kim@Example.com ",
+		Role:  models.OwnerRoleStaff,
+		Stations: []models.StationPermissions{{
+			StationID:   "station-1",
+			Permissions: []string{models.StationPermissionSendBroadcasts, models.StationPermissionPublishPrices, models.StationPermissionSendBroadcasts},
+		}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "owner-1", inv.StationOwnerID)
+	assert.Equal(t, "kim@example.com", inv.Email)
+	assert.Equal(t, []models.StationPermissions{{
+		StationID:   "station-1",
+		StationName: "Acme Parramatta",
+		Permissions: []string{models.StationPermissionPublishPrices, models.StationPermissionSendBroadcasts},
+	}}, inv.Stations)
+	assert.Equal(t, time.Date(2026, 5, 8, 9, 0, 0, 0, time.UTC), inv.ExpiresAt)
+
+	// Only the hash of the token in the link is stored
+	require.True(t, strings.HasPrefix(link, "https://app.example.com/station-owner/invitations/accept?token="))
+	parsed, err := url.Parse(link)
+	require.NoError(t, err)
+	token := parsed.Query().Get("token")
+	assert.Len(t, token, 64)
+	assert.Equal(t, hashVerificationToken(token), tokenHash)
+	tt.emails.AssertExpectations(t)
+}
+
+func TestOwnerTeam_InviteRejectsInvalidInput(t *testing.T) {
+	staff := func(stations ...models.StationPermissions) TeamInvitationInput {
+		return TeamInvitationInput{Email: "kim@example.com", Role: models.OwnerRoleStaff, Stations: stations}
+	}
+	tests := map[string]TeamInvitationInput{
+		"bad email":          {Email: "Kim <kim@example.com>", Role: models.OwnerRoleManager},
+		"unknown role":       {Email: "kim@example.com", Role: "admin"},
+		"staff without any":  staff(),
+		"no permissions":     staff(models.StationPermissions{StationID: "station-1"}),
+		"unknown permission": staff(models.StationPermissions{StationID: "station-1", Permissions: []string{"delete_station"}}),
+		"another station":    staff(models.StationPermissions{StationID: "station-2", Permissions: []string{models.StationPermissionViewAnalytics}}),
+	}
+	for name, input := range tests {
+		tt := setupOwnerTeamTest(allowAllEmailVerification{})
+		tt.asRole(models.OwnerRoleOwner)
+
+		_, err := tt.service.Invite("user-1", input)
+		assert.ErrorIs(t, err, ErrInvalidTeamInput, name)
+		tt.teamRepo.AssertNotCalled(t, "CreateInvitation", mock.Anything, mock.Anything)
+	}
+}
+
+func TestOwnerTeam_RolesLimitWhoCanBeInvited(t *testing.T) {
+	tt := setupOwnerTeamTest(allowAllEmailVerification{})
+	tt.asRole(models.OwnerRoleManager)
+	_, err := tt.service.Invite("user-1", TeamInvitationInput{Email: "kim@example.com", Role: models.OwnerRoleManager})
+	assert.ErrorIs(t, err, repository.ErrStationPermissionDenied)
+
+	tt = setupOwnerTeamTest(allowAllEmailVerification{})
+	tt.asRole(models.OwnerRoleStaff)
+	_, err = tt.service.Invite("user-1", TeamInvitationInput{Email: "kim@example.com", Role: models.OwnerRoleStaff})
+	assert.ErrorIs(t, err, repository.ErrStationPermissionDenied)
+	_, err = tt.service.Team("user-1")
+	assert.ErrorIs(t, err, repository.ErrStationPermissionDenied)
+	tt.teamRepo.AssertNotCalled(t, "CreateInvitation", mock.Anything, mock.Anything)
+	tt.teamRepo.AssertNotCalled(t, "ListMembers", mock.Anything)
+}
+
+func TestOwnerTeam_ManagersOnlyManageStaff(t *testing.T) {
+	tt := setupOwnerTeamTest(allowAllEmailVerification{})
+	tt.asRole(models.OwnerRoleManager)
+	tt.teamRepo.On("ListMembers", "owner-1").Return([]models.OwnerTeamMember{
+		{ID: "member-manager", Role: models.OwnerRoleManager},
+		{ID: "member-staff", Role: models.OwnerRoleStaff},
+	}, nil)
+
+	assert.ErrorIs(t, tt.service.RemoveMember("user-1", "member-manager"), repository.ErrStationPermissionDenied)
+	_, err := tt.service.UpdateMember("user-1", "member-staff", TeamMemberInput{Role: models.OwnerRoleManager})
+	assert.ErrorIs(t, err, repository.ErrStationPermissionDenied)
+	assert.ErrorIs(t, tt.service.RemoveMember("user-1", "member-unknown"), ErrTeamMemberNotFound)
+	tt.teamRepo.AssertNotCalled(t, "RemoveMember", mock.Anything, mock.Anything)
+	tt.teamRepo.AssertNotCalled(t, "UpdateMember", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+
+	// Changing a staff member's permissions is allowed
+	stations := []models.StationPermissions{{StationID: "station-1", StationName: "Acme Parramatta", Permissions: []string{models.StationPermissionViewAnalytics}}}
+	tt.teamRepo.On("UpdateMember", "owner-1", "member-staff", models.OwnerRoleStaff, stations).
+		Return(&models.OwnerTeamMember{ID: "member-staff", Role: models.OwnerRoleStaff, Stations: stations}, nil).Once()
+	member, err := tt.service.UpdateMember("user-1", "member-staff", TeamMemberInput{
+		Role:     models.OwnerRoleStaff,
+		Stations: []models.StationPermissions{{StationID: "station-1", Permissions: []string{models.StationPermissionViewAnalytics}}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, stations, member.Stations)
+}
+
+func TestOwnerTeam_RevokeUnknownInvitation(t *testing.T) {
+	tt := setupOwnerTeamTest(allowAllEmailVerification{})
+	tt.asRole(models.OwnerRoleOwner)
+	tt.teamRepo.On("RevokeInvitation", "owner-1", "inv-1").Return(sql.ErrNoRows)
+
+	assert.ErrorIs(t, tt.service.RevokeInvitation("user-1", "inv-1"), ErrTeamInvitationNotFound)
+}
+
+func TestOwnerTeam_AcceptInvitation(t *testing.T) {
+	inv := &models.OwnerTeamInvitation{ID: "inv-1", StationOwnerID: "owner-1", Email: "kim@example.com", Role: models.OwnerRoleStaff}
+
+	tt := setupOwnerTeamTest(allowAllEmailVerification{})
+	tt.teamRepo.On("GetInvitationByToken", hashVerificationToken("token-1")).Return(inv, nil)
+	tt.userRepo.On("GetUserByID", "user-2").Return(&models.User{ID: "user-2", Email: "Kim@example.com"}, nil)
+	tt.teamRepo.On("AcceptInvitation", "inv-1", "user-2").Return(&models.OwnerTeamMember{ID: "member-2", Role: models.OwnerRoleStaff}, nil).Once()
+	member, err := tt.service.AcceptInvitation("user-2", "token-1")
+	require.NoError(t, err)
+	assert.Equal(t, "member-2", member.ID)
+
+	// A forwarded link cannot be used from another account
+	tt.userRepo.On("GetUserByID", "user-3").Return(&models.User{ID: "user-3", Email: "someone@example.com"}, nil)
+	_, err = tt.service.AcceptInvitation("user-3", "token-1")
+	assert.ErrorIs(t, err, ErrTeamInvitationEmailMismatch)
+
+	tt.teamRepo.On("GetInvitationByToken", hashVerificationToken("stale")).Return(nil, sql.ErrNoRows)
+	_, err = tt.service.AcceptInvitation("user-2", "stale")
+	assert.ErrorIs(t, err, ErrInvalidTeamInvitation)
+	tt.teamRepo.AssertNumberOfCalls(t, "AcceptInvitation", 1)
+
+	tt = setupOwnerTeamTest(denyAllEmailVerification{})
+	_, err = tt.service.AcceptInvitation("user-2", "token-1")
+	assert.ErrorIs(t, err, ErrEmailNotVerified)
+	tt.teamRepo.AssertNotCalled(t, "GetInvitationByToken", mock.Anything)
+}
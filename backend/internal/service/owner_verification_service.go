@@ -0,0 +1,154 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"math"
+	"strings"
+	"time"
+
+	"gaspeep/backend/internal/models"
+	"gaspeep/backend/internal/repository"
+)
+
+// OwnerVerificationValidity is how long an approved ownership verification
+// lasts before the owner must re-verify.
+const OwnerVerificationValidity = 365 * 24 * time.Hour
+
+// ownerVerificationReminderDays are the days before expiry on which owners
+// are reminded to re-verify, furthest first.
+var ownerVerificationReminderDays = []int{30, 7, 1}
+
+// ErrClaimVerificationNotFound is returned for an unknown claim verification.
+var ErrClaimVerificationNotFound = errors.New("claim verification not found")
+
+// OwnerVerificationService runs the yearly lifecycle of station ownership
+// verifications: owners re-verify with the same evidence as a claim, admins
+// review claims, and claims that lapse suspend the owner's broadcasts,
+// official prices and station edits until a re-verification is approved.
+type OwnerVerificationService interface {
+	// ReVerify asks for the user's claim on a station to be verified again.
+	// It returns ErrEmailNotVerified, or the repository's ErrNoClaimToRenew
+	// or ErrClaimRenewalPending.
+	ReVerify(userID, stationID string, evidence repository.ClaimEvidence) (*models.ClaimVerification, error)
+	// Claims returns claims with status, pending ones if it is empty, oldest
+	// first.
+	Claims(status string) ([]models.ClaimVerification, error)
+	// Approve approves a pending claim or re-verification for
+	// OwnerVerificationValidity, recording the admin who reviewed it. It
+	// returns ErrClaimVerificationNotFound or the repository's
+	// ErrClaimNotPending.
+	Approve(id, reviewerID string) (*models.ClaimVerification, error)
+	// Reject rejects a pending claim or re-verification, recording the admin
+	// who reviewed it.
+	Reject(id, reviewerID, reason string) error
+	// ProcessExpiries expires lapsed claims and tells their owners, then
+	// reminds owners whose claims are due a reminder.
+	ProcessExpiries() error
+}
+
+type ownerVerificationService struct {
+	claimRepo    repository.ClaimVerificationRepository
+	emailService EmailService
+	verification EmailVerificationPolicy
+	now          func() time.Time
+}
+
+func NewOwnerVerificationService(
+	claimRepo repository.ClaimVerificationRepository,
+	emailService EmailService,
+	verification EmailVerificationPolicy,
+) OwnerVerificationService {
+	return &ownerVerificationService{
+		claimRepo:    claimRepo,
+		emailService: emailService,
+		verification: verification,
+		now:          time.Now,
+	}
+}
+
+// ReVerify requires a verified email address, as ClaimStation does.
+func (s *ownerVerificationService) ReVerify(userID, stationID string, evidence repository.ClaimEvidence) (*models.ClaimVerification, error) {
+	if err := s.verification.RequireVerifiedEmail(userID); err != nil {
+		return nil, err
+	}
+	return s.claimRepo.CreateRenewal(userID, stationID, evidence)
+}
+
+func (s *ownerVerificationService) Claims(status string) ([]models.ClaimVerification, error) {
+	if status == "" {
+		status = models.ClaimVerificationPending
+	}
+	return s.claimRepo.ListClaims(status)
+}
+
+func (s *ownerVerificationService) Approve(id, reviewerID string) (*models.ClaimVerification, error) {
+	claim, err := s.claimRepo.ApproveClaim(id, reviewerID, s.now().Add(OwnerVerificationValidity))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrClaimVerificationNotFound
+	}
+	return claim, err
+}
+
+func (s *ownerVerificationService) Reject(id, reviewerID, reason string) error {
+	err := s.claimRepo.RejectClaim(id, reviewerID, strings.TrimSpace(reason))
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrClaimVerificationNotFound
+	}
+	return err
+}
+
+// ProcessExpiries sends each owner at most one reminder per run, for the
+// nearest reminder day they have not had, so a missed run does not send a
+// burst of reminders. Email failures are logged rather than returned so one
+// bad address does not hold up the rest; a reminder that failed is retried
+// on the next run.
+func (s *ownerVerificationService) ProcessExpiries() error {
+	now := s.now()
+	lapsed, err := s.claimRepo.ExpireLapsed(now)
+	if err != nil {
+		return err
+	}
+	for _, c := range lapsed {
+		if err := s.emailService.SendOwnerVerificationLapsed(c.OwnerUserID, c.OwnerEmail, c.StationName); err != nil {
+			log.Printf("Failed to send verification lapsed email for claim %s: %v", c.ID, err)
+		}
+	}
+
+	furthest := time.Duration(ownerVerificationReminderDays[0]) * 24 * time.Hour
+	expiring, err := s.claimRepo.ListExpiring(now.Add(furthest))
+	if err != nil {
+		return err
+	}
+	for _, c := range expiring {
+		days, due := ownerVerificationReminderDue(c.ExpiresAt.Sub(now), c.ReminderDaysSent)
+		if !due {
+			continue
+		}
+		daysLeft := int(math.Ceil(c.ExpiresAt.Sub(now).Hours() / 24))
+		if err := s.emailService.SendOwnerVerificationReminder(c.OwnerUserID, c.OwnerEmail, c.StationName, c.ExpiresAt, daysLeft); err != nil {
+			log.Printf("Failed to send verification reminder for claim %s: %v", c.ID, err)
+			continue
+		}
+		if err := s.claimRepo.MarkReminderSent(c.ID, days); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ownerVerificationReminderDue returns the nearest reminder day that has been
+// reached with left until expiry, and whether it is still to be sent.
+func ownerVerificationReminderDue(left time.Duration, sent *int) (int, bool) {
+	due := 0
+	for _, days := range ownerVerificationReminderDays {
+		if left <= time.Duration(days)*24*time.Hour {
+			due = days
+		}
+	}
+	if due == 0 || (sent != nil && *sent <= due) {
+		return 0, false
+	}
+	return due, true
+}
@@ -0,0 +1,181 @@
+package service
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"gaspeep/backend/internal/models"
+	"gaspeep/backend/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockClaimVerificationRepository mocks ClaimVerificationRepository
+type MockClaimVerificationRepository struct {
+	mock.Mock
+}
+
+func (m *MockClaimVerificationRepository) ListClaims(status string) ([]models.ClaimVerification, error) {
+	args := m.Called(status)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.ClaimVerification), args.Error(1)
+}
+
+func (m *MockClaimVerificationRepository) CreateRenewal(userID, stationID string, evidence repository.ClaimEvidence) (*models.ClaimVerification, error) {
+	args := m.Called(userID, stationID, evidence)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ClaimVerification), args.Error(1)
+}
+
+func (m *MockClaimVerificationRepository) ApproveClaim(id, reviewerID string, expiresAt time.Time) (*models.ClaimVerification, error) {
+	args := m.Called(id, reviewerID, expiresAt)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ClaimVerification), args.Error(1)
+}
+
+func (m *MockClaimVerificationRepository) RejectClaim(id, reviewerID, reason string) error {
+	args := m.Called(id, reviewerID, reason)
+	return args.Error(0)
+}
+
+func (m *MockClaimVerificationRepository) ListExpiring(before time.Time) ([]repository.ExpiringClaim, error) {
+	args := m.Called(before)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]repository.ExpiringClaim), args.Error(1)
+}
+
+func (m *MockClaimVerificationRepository) MarkReminderSent(id string, days int) error {
+	args := m.Called(id, days)
+	return args.Error(0)
+}
+
+func (m *MockClaimVerificationRepository) ExpireLapsed(now time.Time) ([]repository.ExpiringClaim, error) {
+	args := m.Called(now)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]repository.ExpiringClaim), args.Error(1)
+}
+
+func (m *MockClaimVerificationRepository) HasActiveClaim(userID, stationID string) (bool, error) {
+	args := m.Called(userID, stationID)
+	return args.Bool(0), args.Error(1)
+}
+
+// MockEmailServiceForOwnerVerification mocks the verification emails. Other
+// EmailService methods panic if called.
+type MockEmailServiceForOwnerVerification struct {
+	EmailService
+	mock.Mock
+}
+
+func (m *MockEmailServiceForOwnerVerification) SendOwnerVerificationReminder(userID, toEmail, stationName string, expiresAt time.Time, daysLeft int) error {
+	args := m.Called(userID, toEmail, stationName, expiresAt, daysLeft)
+	return args.Error(0)
+}
+
+func (m *MockEmailServiceForOwnerVerification) SendOwnerVerificationLapsed(userID, toEmail, stationName string) error {
+	args := m.Called(userID, toEmail, stationName)
+	return args.Error(0)
+}
+
+func newTestOwnerVerificationService(claimRepo repository.ClaimVerificationRepository, emails EmailService, now time.Time) *ownerVerificationService {
+	s := NewOwnerVerificationService(claimRepo, emails, allowAllEmailVerification{}).(*ownerVerificationService)
+	s.now = func() time.Time { return now }
+	return s
+}
+
+func TestOwnerVerification_ReVerifyRequiresVerifiedEmail(t *testing.T) {
+	claimRepo := new(MockClaimVerificationRepository)
+	evidence := repository.ClaimEvidence{VerificationMethod: "document", DocumentURLs: []string{"https://example.com/lease.pdf"}}
+
+	denied := NewOwnerVerificationService(claimRepo, nil, denyAllEmailVerification{})
+	_, err := denied.ReVerify("user-1", "station-1", evidence)
+	assert.ErrorIs(t, err, ErrEmailNotVerified)
+	claimRepo.AssertNotCalled(t, "CreateRenewal", mock.Anything, mock.Anything, mock.Anything)
+
+	claimRepo.On("CreateRenewal", "user-1", "station-1", evidence).
+		Return(&models.ClaimVerification{ID: "claim-2", Status: models.ClaimVerificationPending}, nil).Once()
+	svc := NewOwnerVerificationService(claimRepo, nil, allowAllEmailVerification{})
+	claim, err := svc.ReVerify("user-1", "station-1", evidence)
+	require.NoError(t, err)
+	assert.Equal(t, "claim-2", claim.ID)
+	claimRepo.AssertExpectations(t)
+}
+
+func TestOwnerVerification_ApproveLastsAYear(t *testing.T) {
+	now := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	claimRepo := new(MockClaimVerificationRepository)
+	svc := newTestOwnerVerificationService(claimRepo, nil, now)
+
+	claimRepo.On("ApproveClaim", "claim-1", "admin-1", now.Add(OwnerVerificationValidity)).
+		Return(&models.ClaimVerification{ID: "claim-1", Status: models.ClaimVerificationApproved}, nil).Once()
+	claimRepo.On("ApproveClaim", "missing", "admin-1", mock.Anything).Return(nil, sql.ErrNoRows)
+	claimRepo.On("RejectClaim", "claim-3", "admin-1", "Blurry lease").Return(repository.ErrClaimNotPending)
+
+	claim, err := svc.Approve("claim-1", "admin-1")
+	require.NoError(t, err)
+	assert.Equal(t, models.ClaimVerificationApproved, claim.Status)
+
+	_, err = svc.Approve("missing", "admin-1")
+	assert.ErrorIs(t, err, ErrClaimVerificationNotFound)
+	assert.ErrorIs(t, svc.Reject("claim-3", "admin-1", " Blurry lease "), repository.ErrClaimNotPending)
+	claimRepo.AssertExpectations(t)
+}
+
+func TestOwnerVerification_ClaimsDefaultToPending(t *testing.T) {
+	claimRepo := new(MockClaimVerificationRepository)
+	svc := NewOwnerVerificationService(claimRepo, nil, allowAllEmailVerification{})
+
+	claimRepo.On("ListClaims", models.ClaimVerificationPending).Return([]models.ClaimVerification{{ID: "claim-1"}}, nil).Once()
+
+	claims, err := svc.Claims("")
+	require.NoError(t, err)
+	assert.Len(t, claims, 1)
+}
+
+func TestOwnerVerification_ProcessExpiries(t *testing.T) {
+	now := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+	claimRepo := new(MockClaimVerificationRepository)
+	emails := new(MockEmailServiceForOwnerVerification)
+	svc := newTestOwnerVerificationService(claimRepo, emails, now)
+
+	claimRepo.On("ExpireLapsed", now).Return([]repository.ExpiringClaim{
+		{ID: "lapsed", StationName: "Acme Fuel", OwnerUserID: "user-1", OwnerEmail: "owner@example.com"},
+	}, nil).Once()
+	emails.On("SendOwnerVerificationLapsed", "user-1", "owner@example.com", "Acme Fuel").Return(nil).Once()
+
+	expiring := []repository.ExpiringClaim{
+		// First reminder
+		{ID: "first", StationName: "A", OwnerUserID: "user-2", OwnerEmail: "a@example.com", ExpiresAt: now.Add(25 * day)},
+		// Already had the 30 day reminder and the 7 day one is not due
+		{ID: "waiting", StationName: "B", OwnerUserID: "user-3", OwnerEmail: "b@example.com", ExpiresAt: now.Add(20 * day), ReminderDaysSent: intPtr(30)},
+		// A missed run only sends the nearest reminder
+		{ID: "catch-up", StationName: "C", OwnerUserID: "user-4", OwnerEmail: "c@example.com", ExpiresAt: now.Add(12 * time.Hour)},
+		// Failed sends are not recorded, so they are retried
+		{ID: "failing", StationName: "D", OwnerUserID: "user-5", OwnerEmail: "d@example.com", ExpiresAt: now.Add(6 * day), ReminderDaysSent: intPtr(30)},
+	}
+	claimRepo.On("ListExpiring", now.Add(30*day)).Return(expiring, nil).Once()
+	emails.On("SendOwnerVerificationReminder", "user-2", "a@example.com", "A", now.Add(25*day), 25).Return(nil).Once()
+	claimRepo.On("MarkReminderSent", "first", 30).Return(nil).Once()
+	emails.On("SendOwnerVerificationReminder", "user-4", "c@example.com", "C", now.Add(12*time.Hour), 1).Return(nil).Once()
+	claimRepo.On("MarkReminderSent", "catch-up", 1).Return(nil).Once()
+	emails.On("SendOwnerVerificationReminder", "user-5", "d@example.com", "D", now.Add(6*day), 6).Return(assert.AnError).Once()
+
+	require.NoError(t, svc.ProcessExpiries())
+	claimRepo.AssertExpectations(t)
+	emails.AssertExpectations(t)
+	claimRepo.AssertNotCalled(t, "MarkReminderSent", "failing", mock.Anything)
+	emails.AssertNotCalled(t, "SendOwnerVerificationReminder", "user-3", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
@@ -0,0 +1,38 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+const ownerVerificationExpiryInterval = time.Hour
+
+// OwnerVerificationWorker periodically expires station ownership
+// verifications that have lapsed and reminds owners to re-verify before
+// theirs do.
+type OwnerVerificationWorker struct {
+	verificationService OwnerVerificationService
+}
+
+func NewOwnerVerificationWorker(verificationService OwnerVerificationService) *OwnerVerificationWorker {
+	return &OwnerVerificationWorker{verificationService: verificationService}
+}
+
+// Start processes expiries now and then every hour until ctx is cancelled.
+func (w *OwnerVerificationWorker) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(ownerVerificationExpiryInterval)
+		defer ticker.Stop()
+		for {
+			if err := w.verificationService.ProcessExpiries(); err != nil {
+				log.Printf("Owner verification expiry failed: %v", err)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
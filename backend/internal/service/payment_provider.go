@@ -0,0 +1,96 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"gaspeep/backend/internal/models"
+)
+
+// Payment events reported by providers' webhooks.
+const (
+	// PaymentEventSubscriptionStarted is sent when a checkout completes,
+	// starting a trial or the first paid period.
+	PaymentEventSubscriptionStarted = "subscription.started"
+	// PaymentEventSubscriptionRenewed is sent when a period has been paid for.
+	PaymentEventSubscriptionRenewed = "subscription.renewed"
+	// PaymentEventPaymentFailed is sent when a renewal could not be charged.
+	// The provider keeps retrying until it cancels the subscription.
+	PaymentEventPaymentFailed = "subscription.payment_failed"
+	// PaymentEventSubscriptionCanceled is sent when a subscription will not
+	// renew. It ends at the end of its period unless EndedImmediately is set.
+	PaymentEventSubscriptionCanceled = "subscription.canceled"
+)
+
+var ErrInvalidPaymentWebhook = errors.New("invalid payment webhook")
+
+// PaymentEvent is a provider-neutral webhook event. SubscriptionID is the
+// provider's ID for the subscription; UserID and PlanID are ours, passed to
+// the provider at checkout.
+type PaymentEvent struct {
+	ID               string     `json:"id"`
+	Type             string     `json:"type"`
+	SubscriptionID   string     `json:"subscriptionId"`
+	UserID           string     `json:"userId"`
+	PlanID           string     `json:"planId"`
+	PeriodStart      time.Time  `json:"periodStart"`
+	PeriodEnd        time.Time  `json:"periodEnd"`
+	TrialEnd         *time.Time `json:"trialEnd,omitempty"`
+	EndedImmediately bool       `json:"endedImmediately,omitempty"`
+}
+
+// CheckoutRequest asks a provider to take payment for a plan. TrialDays is
+// zero when the user has already had a trial.
+type CheckoutRequest struct {
+	UserID     string
+	Email      string
+	Plan       models.SubscriptionPlan
+	TrialDays  int
+	SuccessURL string
+	CancelURL  string
+}
+
+// Checkout is a provider-hosted payment page the user is sent to.
+type Checkout struct {
+	ID  string `json:"id"`
+	URL string `json:"url"`
+}
+
+// PaymentProvider takes payment for subscriptions and reports changes to them
+// through webhooks.
+type PaymentProvider interface {
+	// Name identifies the provider in stored subscriptions.
+	Name() string
+	CreateCheckout(req CheckoutRequest) (*Checkout, error)
+	// CancelSubscription stops a subscription renewing. It stays active until
+	// the end of the paid period.
+	CancelSubscription(subscriptionID string) error
+	// ParseWebhook verifies a webhook request and returns its event, or
+	// ErrInvalidPaymentWebhook.
+	ParseWebhook(payload []byte, header http.Header) (*PaymentEvent, error)
+}
+
+// NewPaymentProviderFromEnv picks the provider from PAYMENT_PROVIDER. Only
+// the fake provider, which takes no payment, is available so far; it is the
+// default outside production, where it is refused, and verifies webhooks
+// with PAYMENT_WEBHOOK_SECRET.
+func NewPaymentProviderFromEnv() (PaymentProvider, error) {
+	provider := strings.ToLower(strings.TrimSpace(os.Getenv("PAYMENT_PROVIDER")))
+	if provider == "" {
+		provider = "fake"
+	}
+
+	switch provider {
+	case "fake":
+		if os.Getenv("ENV") == "production" {
+			return nil, errors.New("PAYMENT_PROVIDER must be a real payment provider in production, as the fake one does not charge for subscriptions")
+		}
+		return NewFakePaymentProvider(os.Getenv("PAYMENT_WEBHOOK_SECRET")), nil
+	default:
+		return nil, fmt.Errorf("unknown PAYMENT_PROVIDER %q: expected fake", provider)
+	}
+}
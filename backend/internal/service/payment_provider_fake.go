@@ -0,0 +1,86 @@
+package service
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+)
+
+// FakePaymentSignatureHeader carries the fake provider's webhook signature:
+// the hex HMAC-SHA256 of the body keyed with the webhook secret.
+const FakePaymentSignatureHeader = "X-Fake-Payment-Signature"
+
+// FakePaymentProvider stands in for a real provider in development and tests.
+// Checkouts send the user straight back to the success URL, and webhooks are
+// PaymentEvents as JSON, signed with SignFakePaymentWebhook.
+type FakePaymentProvider struct {
+	webhookSecret string
+}
+
+// NewFakePaymentProvider returns a fake provider. Without a webhook secret
+// every webhook is rejected.
+func NewFakePaymentProvider(webhookSecret string) *FakePaymentProvider {
+	return &FakePaymentProvider{webhookSecret: webhookSecret}
+}
+
+func (p *FakePaymentProvider) Name() string { return "fake" }
+
+func (p *FakePaymentProvider) CreateCheckout(req CheckoutRequest) (*Checkout, error) {
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		return nil, fmt.Errorf("failed to generate checkout ID: %w", err)
+	}
+	id := "fake_cs_" + hex.EncodeToString(b)
+	log.Printf("Fake payment checkout %s: user %s, plan %s, %d trial days", id, req.UserID, req.Plan.ID, req.TrialDays)
+
+	u, err := url.Parse(req.SuccessURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid checkout success URL: %w", err)
+	}
+	q := u.Query()
+	q.Set("checkout", id)
+	u.RawQuery = q.Encode()
+	return &Checkout{ID: id, URL: u.String()}, nil
+}
+
+func (p *FakePaymentProvider) CancelSubscription(subscriptionID string) error {
+	log.Printf("Fake payment provider: canceled subscription %s", subscriptionID)
+	return nil
+}
+
+func (p *FakePaymentProvider) ParseWebhook(payload []byte, header http.Header) (*PaymentEvent, error) {
+	if p.webhookSecret == "" {
+		return nil, fmt.Errorf("%w: PAYMENT_WEBHOOK_SECRET is not set", ErrInvalidPaymentWebhook)
+	}
+	signature, err := hex.DecodeString(header.Get(FakePaymentSignatureHeader))
+	if err != nil || !hmac.Equal(signature, fakePaymentSignature(p.webhookSecret, payload)) {
+		return nil, fmt.Errorf("%w: bad signature", ErrInvalidPaymentWebhook)
+	}
+
+	var event PaymentEvent
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPaymentWebhook, err)
+	}
+	if event.ID == "" || event.Type == "" || event.SubscriptionID == "" {
+		return nil, fmt.Errorf("%w: missing id, type or subscriptionId", ErrInvalidPaymentWebhook)
+	}
+	return &event, nil
+}
+
+// SignFakePaymentWebhook returns the FakePaymentSignatureHeader value for a
+// webhook body.
+func SignFakePaymentWebhook(secret string, payload []byte) string {
+	return hex.EncodeToString(fakePaymentSignature(secret, payload))
+}
+
+func fakePaymentSignature(secret string, payload []byte) []byte {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
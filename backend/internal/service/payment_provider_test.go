@@ -0,0 +1,28 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewPaymentProviderFromEnv_DefaultsToFake(t *testing.T) {
+	t.Setenv("ENV", "development")
+	t.Setenv("PAYMENT_PROVIDER", "")
+
+	provider, err := NewPaymentProviderFromEnv()
+
+	require.NoError(t, err)
+	assert.Equal(t, "fake", provider.Name())
+}
+
+func TestNewPaymentProviderFromEnv_RefusesFakeInProduction(t *testing.T) {
+	t.Setenv("ENV", "production")
+
+	for _, name := range []string{"", "fake"} {
+		t.Setenv("PAYMENT_PROVIDER", name)
+		_, err := NewPaymentProviderFromEnv()
+		assert.Error(t, err, "PAYMENT_PROVIDER=%q", name)
+	}
+}
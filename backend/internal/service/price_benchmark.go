@@ -0,0 +1,179 @@
+package service
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"time"
+
+	"gaspeep/backend/internal/models"
+	"gaspeep/backend/internal/repository"
+)
+
+// Price benchmark limits. Competitors are stations within the radius, and
+// the history covers the given number of days up to today.
+const (
+	DefaultBenchmarkRadiusKm = 5
+	MaxBenchmarkRadiusKm     = 50
+	DefaultBenchmarkDays     = 30
+	MaxBenchmarkDays         = 90
+)
+
+// ErrInvalidBenchmarkRange is returned when a price benchmark radius is not
+// positive or above MaxBenchmarkRadiusKm, or its days are not between 1 and
+// MaxBenchmarkDays.
+var ErrInvalidBenchmarkRange = errors.New("invalid benchmark range")
+
+type priceKey struct {
+	stationID  string
+	fuelTypeID string
+}
+
+// priceSeries walks a station's price history forward in time.
+type priceSeries struct {
+	points []repository.PricePoint
+	next   int
+	price  *float64
+}
+
+// at returns the price before t, or nil if there was none. Calls must not go
+// back in time.
+func (p *priceSeries) at(t time.Time) *float64 {
+	for p.next < len(p.points) && p.points[p.next].RecordedAt.Before(t) {
+		p.price = &p.points[p.next].Price
+		p.next++
+	}
+	return p.price
+}
+
+func (s *stationOwnerService) PriceBenchmarks(userID string, radiusKm float64, days int) ([]models.PriceBenchmark, error) {
+	if radiusKm <= 0 || radiusKm > MaxBenchmarkRadiusKm || days < 1 || days > MaxBenchmarkDays {
+		return nil, ErrInvalidBenchmarkRange
+	}
+
+	prices, err := s.analyticsRepo.BenchmarkedPrices(userID, radiusKm)
+	if err != nil {
+		return nil, err
+	}
+	benchmarks := make([]models.PriceBenchmark, 0, len(prices))
+	if len(prices) == 0 {
+		return benchmarks, nil
+	}
+
+	now := s.now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	since := today.AddDate(0, 0, 1-days)
+
+	stationIDs, fuelTypeIDs := map[string]bool{}, map[string]bool{}
+	for _, p := range prices {
+		stationIDs[p.StationID] = true
+		fuelTypeIDs[p.FuelTypeID] = true
+		for _, id := range p.CompetitorIDs {
+			stationIDs[id] = true
+		}
+	}
+	points, err := s.analyticsRepo.PriceHistory(sortedKeys(stationIDs), sortedKeys(fuelTypeIDs), since)
+	if err != nil {
+		return nil, err
+	}
+	history := make(map[priceKey][]repository.PricePoint)
+	for _, p := range points {
+		k := priceKey{p.StationID, p.FuelTypeID}
+		history[k] = append(history[k], p)
+	}
+
+	for _, p := range prices {
+		b := models.PriceBenchmark{
+			StationID:       p.StationID,
+			StationName:     p.StationName,
+			FuelTypeID:      p.FuelTypeID,
+			FuelTypeName:    p.FuelTypeName,
+			PriceComparison: comparePrice(p.Price, p.CompetitorPrices),
+			History:         []models.PriceBenchmarkDay{},
+		}
+
+		own := &priceSeries{points: history[priceKey{p.StationID, p.FuelTypeID}]}
+		competitors := make([]*priceSeries, len(p.CompetitorIDs))
+		for i, id := range p.CompetitorIDs {
+			series := history[priceKey{id, p.FuelTypeID}]
+			competitors[i] = &priceSeries{points: series}
+
+			changed := false
+			for _, point := range series {
+				if !point.RecordedAt.Before(since) && point.PreviousPrice != nil {
+					b.CompetitorPriceChanges++
+					changed = true
+				}
+			}
+			if changed {
+				b.CompetitorsChangingPrice++
+			}
+		}
+
+		for day := since; !day.After(today); day = day.AddDate(0, 0, 1) {
+			end := day.AddDate(0, 0, 1)
+			price := own.at(end)
+			var competitorPrices []float64
+			for _, c := range competitors {
+				if cp := c.at(end); cp != nil {
+					competitorPrices = append(competitorPrices, *cp)
+				}
+			}
+			// Days before the station had a price are left out
+			if price == nil {
+				continue
+			}
+			b.History = append(b.History, models.PriceBenchmarkDay{
+				Date:            day.Format("2006-01-02"),
+				PriceComparison: comparePrice(*price, competitorPrices),
+			})
+		}
+
+		benchmarks = append(benchmarks, b)
+	}
+	return benchmarks, nil
+}
+
+// comparePrice compares price with competitors' prices.
+func comparePrice(price float64, competitors []float64) models.PriceComparison {
+	c := models.PriceComparison{Price: price, Rank: 1, CompetitorCount: len(competitors)}
+	if len(competitors) == 0 {
+		return c
+	}
+
+	sorted := append([]float64(nil), competitors...)
+	sort.Float64s(sorted)
+	for _, cp := range sorted {
+		if cp < price {
+			c.Rank++
+		}
+	}
+
+	cheapest := sorted[0]
+	median := sorted[len(sorted)/2]
+	if len(sorted)%2 == 0 {
+		median = (sorted[len(sorted)/2-1] + median) / 2
+	}
+	median = roundPrice(median)
+	gapToCheapest := roundPrice(price - cheapest)
+	gapToMedian := roundPrice(price - median)
+	c.CheapestPrice = &cheapest
+	c.MedianPrice = &median
+	c.GapToCheapest = &gapToCheapest
+	c.GapToMedian = &gapToMedian
+	return c
+}
+
+// roundPrice rounds to a tenth of a cent, as prices are stored.
+func roundPrice(price float64) float64 {
+	return math.Round(price*1000) / 1000
+}
+
+func sortedKeys(set map[string]bool) []string {
+	out := make([]string, 0, len(set))
+	for k := range set {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
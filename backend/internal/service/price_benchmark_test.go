@@ -0,0 +1,207 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"gaspeep/backend/internal/models"
+	"gaspeep/backend/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockOwnerAnalyticsRepository struct {
+	mock.Mock
+}
+
+func (m *MockOwnerAnalyticsRepository) BenchmarkedPrices(userID string, radiusKm float64) ([]repository.BenchmarkedPrice, error) {
+	args := m.Called(userID, radiusKm)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]repository.BenchmarkedPrice), args.Error(1)
+}
+
+func (m *MockOwnerAnalyticsRepository) PriceHistory(stationIDs, fuelTypeIDs []string, since time.Time) ([]repository.PricePoint, error) {
+	args := m.Called(stationIDs, fuelTypeIDs, since)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]repository.PricePoint), args.Error(1)
+}
+
+func (m *MockOwnerAnalyticsRepository) BroadcastStats(userID string, weekStart, monthStart time.Time) (*repository.OwnerBroadcastStats, error) {
+	args := m.Called(userID, weekStart, monthStart)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*repository.OwnerBroadcastStats), args.Error(1)
+}
+
+var benchmarkNow = time.Date(2026, 10, 18, 15, 0, 0, 0, time.UTC)
+
+func setupPriceBenchmarkTest() (*stationOwnerService, *MockOwnerAnalyticsRepository) {
+	analyticsRepo := new(MockOwnerAnalyticsRepository)
+	svc := NewStationOwnerService(new(MockStationOwnerRepositoryForOwnerService), analyticsRepo, allowAllEmailVerification{}).(*stationOwnerService)
+	svc.now = func() time.Time { return benchmarkNow }
+	return svc, analyticsRepo
+}
+
+func pricePoint(stationID string, price float64, previous *float64, at time.Time) repository.PricePoint {
+	return repository.PricePoint{StationID: stationID, FuelTypeID: "ft-e10", Price: price, PreviousPrice: previous, RecordedAt: at}
+}
+
+func price(p float64) *float64 {
+	return &p
+}
+
+func TestGetStats_SumsUpBroadcasts(t *testing.T) {
+	svc, analyticsRepo := setupPriceBenchmarkTest()
+	ownerRepo := svc.stationOwnerRepo.(*MockStationOwnerRepositoryForOwnerService)
+	ownerRepo.On("GetByUserID", "user-1").Return(&models.StationOwner{Plan: "basic"}, nil)
+	ownerRepo.On("GetStationsByOwnerUserID", "user-1").Return([]map[string]interface{}{}, nil)
+
+	weekStart := benchmarkNow.AddDate(0, 0, -7)
+	monthStart := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	analyticsRepo.On("BroadcastStats", "user-1", weekStart, monthStart).Return(&repository.OwnerBroadcastStats{
+		ActiveBroadcasts:   3,
+		BroadcastsThisWeek: 2,
+		DeliveredThisMonth: 200,
+		OpenedThisMonth:    37,
+	}, nil)
+
+	result, err := svc.GetStats("user-1")
+
+	require.NoError(t, err)
+	assert.Equal(t, 3, result["activeBroadcasts"])
+	assert.Equal(t, 2, result["broadcastsThisWeek"])
+	assert.Equal(t, 200, result["totalReachThisMonth"])
+	assert.Equal(t, 18.5, result["averageEngagementRate"])
+
+	profile, err := svc.GetProfile("user-1")
+	require.NoError(t, err)
+	assert.Equal(t, 2, profile["broadcastsThisWeek"])
+	analyticsRepo.AssertExpectations(t)
+}
+
+func TestPriceBenchmarks_RanksCurrentPrice(t *testing.T) {
+	svc, analyticsRepo := setupPriceBenchmarkTest()
+	analyticsRepo.On("BenchmarkedPrices", "user-1", 5.0).Return([]repository.BenchmarkedPrice{{
+		StationID:        "s1",
+		StationName:      "Harbour Fuel",
+		FuelTypeID:       "ft-e10",
+		FuelTypeName:     "E10",
+		Price:            1.899,
+		CompetitorIDs:    []string{"c1", "c2", "c3"},
+		CompetitorPrices: []float64{1.949, 1.859, 1.899},
+	}}, nil)
+	analyticsRepo.On("PriceHistory", []string{"c1", "c2", "c3", "s1"}, []string{"ft-e10"}, mock.Anything).Return([]repository.PricePoint{}, nil)
+
+	benchmarks, err := svc.PriceBenchmarks("user-1", 5, 30)
+
+	require.NoError(t, err)
+	require.Len(t, benchmarks, 1)
+	b := benchmarks[0]
+	assert.Equal(t, "Harbour Fuel", b.StationName)
+	assert.Equal(t, 2, b.Rank)
+	assert.Equal(t, 3, b.CompetitorCount)
+	assert.Equal(t, 1.859, *b.CheapestPrice)
+	assert.Equal(t, 1.899, *b.MedianPrice)
+	assert.InDelta(t, 0.04, *b.GapToCheapest, 1e-9)
+	assert.InDelta(t, 0, *b.GapToMedian, 1e-9)
+	assert.Empty(t, b.History)
+}
+
+func TestPriceBenchmarks_NoCompetitors(t *testing.T) {
+	svc, analyticsRepo := setupPriceBenchmarkTest()
+	analyticsRepo.On("BenchmarkedPrices", "user-1", 5.0).Return([]repository.BenchmarkedPrice{{
+		StationID: "s1", FuelTypeID: "ft-e10", Price: 1.899,
+	}}, nil)
+	analyticsRepo.On("PriceHistory", []string{"s1"}, []string{"ft-e10"}, mock.Anything).Return([]repository.PricePoint{}, nil)
+
+	benchmarks, err := svc.PriceBenchmarks("user-1", 5, 30)
+
+	require.NoError(t, err)
+	require.Len(t, benchmarks, 1)
+	assert.Equal(t, 1, benchmarks[0].Rank)
+	assert.Equal(t, 0, benchmarks[0].CompetitorCount)
+	assert.Nil(t, benchmarks[0].CheapestPrice)
+	assert.Nil(t, benchmarks[0].GapToMedian)
+}
+
+// TestPriceBenchmarks_History tests that each day compares the prices in
+// place at the end of it, and that only competitors' changes in the period
+// are counted
+func TestPriceBenchmarks_History(t *testing.T) {
+	svc, analyticsRepo := setupPriceBenchmarkTest()
+	analyticsRepo.On("BenchmarkedPrices", "user-1", 3.0).Return([]repository.BenchmarkedPrice{{
+		StationID:        "s1",
+		FuelTypeID:       "ft-e10",
+		Price:            1.85,
+		CompetitorIDs:    []string{"c1", "c2"},
+		CompetitorPrices: []float64{1.83, 1.95},
+	}}, nil)
+	since := time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)
+	analyticsRepo.On("PriceHistory", []string{"c1", "c2", "s1"}, []string{"ft-e10"}, since).Return([]repository.PricePoint{
+		pricePoint("c1", 1.88, price(1.92), time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)),
+		pricePoint("s1", 1.90, nil, time.Date(2026, 10, 10, 9, 0, 0, 0, time.UTC)),
+		pricePoint("c1", 1.80, price(1.88), time.Date(2026, 10, 16, 7, 0, 0, 0, time.UTC)),
+		pricePoint("s1", 1.85, price(1.90), time.Date(2026, 10, 17, 8, 0, 0, 0, time.UTC)),
+		pricePoint("c2", 1.95, nil, time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)),
+		pricePoint("c1", 1.83, price(1.80), time.Date(2026, 10, 18, 6, 0, 0, 0, time.UTC)),
+	}, nil)
+
+	benchmarks, err := svc.PriceBenchmarks("user-1", 3, 3)
+
+	require.NoError(t, err)
+	require.Len(t, benchmarks, 1)
+	b := benchmarks[0]
+	assert.Equal(t, 2, b.CompetitorPriceChanges)
+	assert.Equal(t, 1, b.CompetitorsChangingPrice)
+
+	require.Len(t, b.History, 3)
+	days := b.History
+	assert.Equal(t, "2026-10-16", days[0].Date)
+	assert.Equal(t, 1.90, days[0].Price)
+	assert.Equal(t, 2, days[0].Rank)
+	assert.Equal(t, 1, days[0].CompetitorCount)
+	assert.InDelta(t, 0.1, *days[0].GapToCheapest, 1e-9)
+
+	assert.Equal(t, "2026-10-17", days[1].Date)
+	assert.Equal(t, 1.85, days[1].Price)
+	assert.Equal(t, 2, days[1].CompetitorCount)
+	assert.Equal(t, 1.875, *days[1].MedianPrice)
+	assert.InDelta(t, 0.05, *days[1].GapToCheapest, 1e-9)
+	assert.InDelta(t, -0.025, *days[1].GapToMedian, 1e-9)
+
+	assert.Equal(t, "2026-10-18", days[2].Date)
+	assert.Equal(t, 1.83, *days[2].CheapestPrice)
+	assert.Equal(t, 1.89, *days[2].MedianPrice)
+	assert.InDelta(t, -0.04, *days[2].GapToMedian, 1e-9)
+}
+
+func TestPriceBenchmarks_InvalidRange(t *testing.T) {
+	svc, analyticsRepo := setupPriceBenchmarkTest()
+
+	for _, tt := range []struct {
+		radiusKm float64
+		days     int
+	}{{0, 30}, {MaxBenchmarkRadiusKm + 1, 30}, {5, 0}, {5, MaxBenchmarkDays + 1}} {
+		_, err := svc.PriceBenchmarks("user-1", tt.radiusKm, tt.days)
+		assert.ErrorIs(t, err, ErrInvalidBenchmarkRange)
+	}
+	analyticsRepo.AssertNotCalled(t, "BenchmarkedPrices", mock.Anything, mock.Anything)
+}
+
+func TestPriceBenchmarks_NoStations(t *testing.T) {
+	svc, analyticsRepo := setupPriceBenchmarkTest()
+	analyticsRepo.On("BenchmarkedPrices", "user-1", 5.0).Return([]repository.BenchmarkedPrice{}, nil)
+
+	benchmarks, err := svc.PriceBenchmarks("user-1", 5, 30)
+
+	require.NoError(t, err)
+	assert.Empty(t, benchmarks)
+	assert.NotNil(t, benchmarks)
+	analyticsRepo.AssertNotCalled(t, "PriceHistory", mock.Anything, mock.Anything, mock.Anything)
+}
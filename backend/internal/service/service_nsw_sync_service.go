@@ -14,6 +14,8 @@ import (
 	"sync"
 	"time"
 
+	"gaspeep/backend/internal/repository"
+
 	"github.com/google/uuid"
 )
 
@@ -496,8 +498,19 @@ func (s *ServiceNSWSyncService) upsertStation(ctx context.Context, st serviceNSW
 			service_nsw_station_id = EXCLUDED.service_nsw_station_id,
 			last_verified_at = NOW(),
 			updated_at = NOW()
+		WHERE NOT EXISTS (SELECT 1 FROM station_profile_versions v WHERE v.station_id = stations.id)
 		RETURNING id
 	`, uuid.NewString(), name, strings.TrimSpace(st.Brand), strings.TrimSpace(st.Address), lon, lat, code, strings.TrimSpace(string(st.StationID)), state).Scan(&stationID)
+	if errors.Is(err, sql.ErrNoRows) {
+		// Owners or admins have edited the station's profile, which the feed
+		// no longer overwrites
+		err = s.db.QueryRowContext(ctx, `
+			UPDATE stations
+			SET service_nsw_station_id = $3, last_verified_at = NOW(), updated_at = NOW()
+			WHERE service_nsw_state = $1 AND service_nsw_station_code = $2
+			RETURNING id
+		`, state, code, strings.TrimSpace(string(st.StationID))).Scan(&stationID)
+	}
 	if err != nil {
 		return "", err
 	}
@@ -537,20 +550,41 @@ func (s *ServiceNSWSyncService) getStationIDByExternalCode(ctx context.Context,
 	return id, nil
 }
 
+// upsertFuelPrice stores a synced price and, when it differs from the stored
+// one, records it in the price history and queues a price change event for
+// alert evaluation in the same statement. The stored previous price only
+// moves when the price changes.
 func (s *ServiceNSWSyncService) upsertFuelPrice(ctx context.Context, stationID, fuelTypeID string, price float64, lastUpdated time.Time) error {
 	_, err := s.db.ExecContext(ctx, `
-		INSERT INTO fuel_prices (id, station_id, fuel_type_id, price, currency, unit, last_updated_at, verification_status, confirmation_count, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, 'AUD', 'litre', $5, 'verified', 1, NOW(), NOW())
-		ON CONFLICT (station_id, fuel_type_id)
-		DO UPDATE SET
-			price = EXCLUDED.price,
-			currency = EXCLUDED.currency,
-			unit = EXCLUDED.unit,
-			last_updated_at = EXCLUDED.last_updated_at,
-			verification_status = 'verified',
-			confirmation_count = fuel_prices.confirmation_count + 1,
-			updated_at = NOW()
-	`, uuid.NewString(), stationID, fuelTypeID, price, lastUpdated.UTC())
+		WITH existing AS (
+			SELECT price FROM fuel_prices WHERE station_id = $2 AND fuel_type_id = $3 FOR UPDATE
+		), upserted AS (
+			INSERT INTO fuel_prices (id, station_id, fuel_type_id, price, currency, unit, last_updated_at, verification_status, confirmation_count, source, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, 'AUD', 'litre', $5, 'verified', 1, $7, NOW(), NOW())
+			ON CONFLICT (station_id, fuel_type_id)
+			DO UPDATE SET
+				previous_price = CASE WHEN fuel_prices.price IS DISTINCT FROM EXCLUDED.price
+					THEN fuel_prices.price ELSE fuel_prices.previous_price END,
+				price = EXCLUDED.price,
+				currency = EXCLUDED.currency,
+				unit = EXCLUDED.unit,
+				last_updated_at = EXCLUDED.last_updated_at,
+				verification_status = 'verified',
+				confirmation_count = fuel_prices.confirmation_count + 1,
+				source = EXCLUDED.source,
+				updated_at = NOW()
+			RETURNING station_id, fuel_type_id, price
+		), changed AS (
+			SELECT u.station_id, u.fuel_type_id, u.price, e.price AS previous_price
+			FROM upserted u LEFT JOIN existing e ON true
+			WHERE e.price IS DISTINCT FROM u.price
+		), recorded AS (
+			INSERT INTO fuel_price_history (id, station_id, fuel_type_id, price, previous_price, source, recorded_at)
+			SELECT gen_random_uuid(), station_id, fuel_type_id, price, previous_price, $7, $5 FROM changed
+		)
+		INSERT INTO price_change_events (id, station_id, fuel_type_id, price, previous_price, source)
+		SELECT $6, station_id, fuel_type_id, price, previous_price, $7 FROM changed
+	`, uuid.NewString(), stationID, fuelTypeID, price, lastUpdated.UTC(), uuid.NewString(), repository.PriceChangeSourceServiceNSW)
 	return err
 }
 
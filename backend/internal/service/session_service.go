@@ -0,0 +1,201 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"gaspeep/backend/internal/auth"
+	"gaspeep/backend/internal/models"
+	"gaspeep/backend/internal/repository"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidRefreshToken = errors.New("invalid or expired refresh token")
+	ErrRefreshTokenReused  = errors.New("refresh token reused")
+	ErrSessionNotFound     = errors.New("session not found")
+)
+
+// SessionMetadata describes the client a session is used from.
+type SessionMetadata struct {
+	UserAgent  string
+	DeviceName string
+	IPAddress  string
+	// MFAVerified is set when the user completed a second factor to sign in.
+	MFAVerified bool
+}
+
+// SessionTokens are issued when a session starts or is refreshed.
+type SessionTokens struct {
+	Session               *models.Session
+	AccessToken           string
+	AccessTokenExpiresAt  time.Time
+	RefreshToken          string
+	RefreshTokenExpiresAt time.Time
+}
+
+// SessionService manages signed-in sessions. A session is renewed by
+// exchanging its refresh token for a new access token and refresh token;
+// each refresh token can be exchanged once.
+type SessionService interface {
+	// Start signs the user in on a new device.
+	Start(user *models.User, meta SessionMetadata) (*SessionTokens, error)
+	// Refresh exchanges a refresh token for new tokens. It returns
+	// ErrRefreshTokenReused, and revokes the session, when the token has
+	// already been exchanged.
+	Refresh(refreshToken string, meta SessionMetadata) (*SessionTokens, error)
+	// End revokes the session a refresh token belongs to.
+	End(refreshToken string) error
+	// List returns the user's active sessions, flagging currentSessionID.
+	List(userID, currentSessionID string) ([]models.Session, error)
+	// Revoke ends one of the user's sessions.
+	Revoke(userID, sessionID string) error
+	// RevokeAll ends all of the user's sessions apart from exceptSessionID,
+	// which may be empty. reason is one of the repository.SessionRevoked*
+	// values.
+	RevokeAll(userID, exceptSessionID, reason string) error
+}
+
+type sessionService struct {
+	sessionRepo repository.SessionRepository
+	userRepo    repository.UserRepository
+	refreshTTL  time.Duration
+}
+
+// NewSessionService keeps sessions alive for REFRESH_TOKEN_TTL_DAYS (default
+// 30) after they were last refreshed.
+func NewSessionService(sessionRepo repository.SessionRepository, userRepo repository.UserRepository) SessionService {
+	days := parseEnvInt("REFRESH_TOKEN_TTL_DAYS", 30)
+	if days <= 0 {
+		days = 30
+	}
+	return &sessionService{
+		sessionRepo: sessionRepo,
+		userRepo:    userRepo,
+		refreshTTL:  time.Duration(days) * 24 * time.Hour,
+	}
+}
+
+func (s *sessionService) Start(user *models.User, meta SessionMetadata) (*SessionTokens, error) {
+	refreshToken, err := newRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := s.sessionRepo.Create(repository.CreateSessionInput{
+		UserID:           user.ID,
+		RefreshTokenHash: hashRefreshToken(refreshToken),
+		UserAgent:        meta.UserAgent,
+		DeviceName:       meta.DeviceName,
+		IPAddress:        meta.IPAddress,
+		MFAVerified:      meta.MFAVerified,
+		ExpiresAt:        time.Now().Add(s.refreshTTL),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.issue(session, user.Email, refreshToken)
+}
+
+func (s *sessionService) Refresh(refreshToken string, meta SessionMetadata) (*SessionTokens, error) {
+	if refreshToken == "" {
+		return nil, ErrInvalidRefreshToken
+	}
+	newToken, err := newRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := s.sessionRepo.Rotate(repository.RotateSessionInput{
+		TokenHash:    hashRefreshToken(refreshToken),
+		NewTokenHash: hashRefreshToken(newToken),
+		UserAgent:    meta.UserAgent,
+		IPAddress:    meta.IPAddress,
+		ExpiresAt:    time.Now().Add(s.refreshTTL),
+	})
+	switch {
+	case errors.Is(err, repository.ErrRefreshTokenReused):
+		log.Printf("warning: refresh token reused; session revoked")
+		return nil, ErrRefreshTokenReused
+	case errors.Is(err, sql.ErrNoRows):
+		return nil, ErrInvalidRefreshToken
+	case err != nil:
+		return nil, err
+	}
+
+	user, err := s.userRepo.GetUserByID(session.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load session user: %w", err)
+	}
+	return s.issue(session, user.Email, newToken)
+}
+
+func (s *sessionService) End(refreshToken string) error {
+	if refreshToken == "" {
+		return nil
+	}
+	return s.sessionRepo.RevokeByRefreshToken(hashRefreshToken(refreshToken), repository.SessionRevokedLogout)
+}
+
+func (s *sessionService) List(userID, currentSessionID string) ([]models.Session, error) {
+	sessions, err := s.sessionRepo.ListActive(userID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range sessions {
+		sessions[i].Current = sessions[i].ID == currentSessionID
+	}
+	return sessions, nil
+}
+
+func (s *sessionService) Revoke(userID, sessionID string) error {
+	if _, err := uuid.Parse(sessionID); err != nil {
+		return ErrSessionNotFound
+	}
+	ok, err := s.sessionRepo.Revoke(userID, sessionID, repository.SessionRevokedByUser)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrSessionNotFound
+	}
+	return nil
+}
+
+func (s *sessionService) RevokeAll(userID, exceptSessionID, reason string) error {
+	_, err := s.sessionRepo.RevokeAll(userID, exceptSessionID, reason)
+	return err
+}
+
+func (s *sessionService) issue(session *models.Session, email, refreshToken string) (*SessionTokens, error) {
+	accessToken, err := auth.GenerateToken(session.UserID, email, session.ID, session.MFAVerified)
+	if err != nil {
+		return nil, err
+	}
+	return &SessionTokens{
+		Session:               session,
+		AccessToken:           accessToken,
+		AccessTokenExpiresAt:  time.Now().Add(auth.AccessTokenTTL),
+		RefreshToken:          refreshToken,
+		RefreshTokenExpiresAt: session.ExpiresAt,
+	}, nil
+}
+
+func newRefreshToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate refresh token: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func hashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,163 @@
+package service
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"gaspeep/backend/internal/auth"
+	"gaspeep/backend/internal/models"
+	"gaspeep/backend/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockSessionRepository is a mock implementation of SessionRepository
+type MockSessionRepository struct {
+	mock.Mock
+}
+
+func (m *MockSessionRepository) Create(input repository.CreateSessionInput) (*models.Session, error) {
+	args := m.Called(input)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Session), args.Error(1)
+}
+
+func (m *MockSessionRepository) Rotate(input repository.RotateSessionInput) (*models.Session, error) {
+	args := m.Called(input)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Session), args.Error(1)
+}
+
+func (m *MockSessionRepository) ListActive(userID string) ([]models.Session, error) {
+	args := m.Called(userID)
+	return args.Get(0).([]models.Session), args.Error(1)
+}
+
+func (m *MockSessionRepository) Revoke(userID, sessionID, reason string) (bool, error) {
+	args := m.Called(userID, sessionID, reason)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockSessionRepository) RevokeByRefreshToken(tokenHash, reason string) error {
+	args := m.Called(tokenHash, reason)
+	return args.Error(0)
+}
+
+func (m *MockSessionRepository) RevokeAll(userID, exceptSessionID, reason string) (int64, error) {
+	args := m.Called(userID, exceptSessionID, reason)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func TestSessionService_StartAndRefresh(t *testing.T) {
+	sessionRepo := new(MockSessionRepository)
+	userRepo := new(MockUserRepositoryForVerification)
+	service := NewSessionService(sessionRepo, userRepo)
+
+	user := &models.User{ID: "user-1", Email: "a@example.com"}
+	session := &models.Session{ID: "session-1", UserID: "user-1", ExpiresAt: time.Now().Add(30 * 24 * time.Hour)}
+
+	var created repository.CreateSessionInput
+	sessionRepo.On("Create", mock.Anything).Run(func(args mock.Arguments) {
+		created = args.Get(0).(repository.CreateSessionInput)
+	}).Return(session, nil).Once()
+
+	tokens, err := service.Start(user, SessionMetadata{UserAgent: "Mozilla/5.0", DeviceName: "Pixel 8", IPAddress: "203.0.113.7"})
+	require.NoError(t, err)
+	assert.Equal(t, "Pixel 8", created.DeviceName)
+	assert.WithinDuration(t, time.Now().Add(30*24*time.Hour), created.ExpiresAt, time.Minute)
+
+	// Only the hash of the refresh token is stored
+	assert.Len(t, tokens.RefreshToken, 64)
+	assert.Equal(t, hashRefreshToken(tokens.RefreshToken), created.RefreshTokenHash)
+	assert.NotEqual(t, tokens.RefreshToken, created.RefreshTokenHash)
+
+	claims, err := auth.ValidateToken(tokens.AccessToken)
+	require.NoError(t, err)
+	assert.Equal(t, "user-1", claims.UserID)
+	assert.Equal(t, "session-1", claims.SessionID)
+
+	var rotated repository.RotateSessionInput
+	sessionRepo.On("Rotate", mock.Anything).Run(func(args mock.Arguments) {
+		rotated = args.Get(0).(repository.RotateSessionInput)
+	}).Return(session, nil).Once()
+	userRepo.On("GetUserByID", "user-1").Return(user, nil).Once()
+
+	refreshed, err := service.Refresh(tokens.RefreshToken, SessionMetadata{IPAddress: "203.0.113.8"})
+	require.NoError(t, err)
+	assert.Equal(t, created.RefreshTokenHash, rotated.TokenHash)
+	assert.Equal(t, hashRefreshToken(refreshed.RefreshToken), rotated.NewTokenHash)
+	assert.NotEqual(t, tokens.RefreshToken, refreshed.RefreshToken)
+	assert.Equal(t, "203.0.113.8", rotated.IPAddress)
+
+	sessionRepo.AssertExpectations(t)
+	userRepo.AssertExpectations(t)
+}
+
+func TestSessionService_RefreshErrors(t *testing.T) {
+	sessionRepo := new(MockSessionRepository)
+	service := NewSessionService(sessionRepo, new(MockUserRepositoryForVerification))
+
+	sessionRepo.On("Rotate", mock.MatchedBy(func(input repository.RotateSessionInput) bool {
+		return input.TokenHash == hashRefreshToken("replayed")
+	})).Return(nil, repository.ErrRefreshTokenReused)
+	sessionRepo.On("Rotate", mock.MatchedBy(func(input repository.RotateSessionInput) bool {
+		return input.TokenHash == hashRefreshToken("unknown")
+	})).Return(nil, sql.ErrNoRows)
+
+	_, err := service.Refresh("replayed", SessionMetadata{})
+	assert.ErrorIs(t, err, ErrRefreshTokenReused)
+	_, err = service.Refresh("unknown", SessionMetadata{})
+	assert.ErrorIs(t, err, ErrInvalidRefreshToken)
+	_, err = service.Refresh("", SessionMetadata{})
+	assert.ErrorIs(t, err, ErrInvalidRefreshToken)
+
+	sessionRepo.AssertNumberOfCalls(t, "Rotate", 2)
+}
+
+func TestSessionService_ListAndRevoke(t *testing.T) {
+	sessionRepo := new(MockSessionRepository)
+	service := NewSessionService(sessionRepo, new(MockUserRepositoryForVerification))
+
+	sessionRepo.On("ListActive", "user-1").Return([]models.Session{{ID: "a"}, {ID: "b"}}, nil)
+	sessions, err := service.List("user-1", "b")
+	require.NoError(t, err)
+	assert.False(t, sessions[0].Current)
+	assert.True(t, sessions[1].Current)
+
+	const sessionID = "3f1c2b9e-8a51-4d7e-9f0a-6c2d1e4b5a77"
+	sessionRepo.On("Revoke", "user-1", sessionID, repository.SessionRevokedByUser).Return(true, nil).Once()
+	sessionRepo.On("Revoke", "user-2", sessionID, repository.SessionRevokedByUser).Return(false, nil).Once()
+	assert.NoError(t, service.Revoke("user-1", sessionID))
+	assert.ErrorIs(t, service.Revoke("user-2", sessionID), ErrSessionNotFound)
+	assert.ErrorIs(t, service.Revoke("user-1", "not-a-uuid"), ErrSessionNotFound)
+
+	sessionRepo.On("RevokeByRefreshToken", hashRefreshToken("refresh"), repository.SessionRevokedLogout).Return(nil).Once()
+	assert.NoError(t, service.End("refresh"))
+	assert.NoError(t, service.End(""))
+
+	sessionRepo.AssertExpectations(t)
+}
+
+func TestSessionService_StartWithMFA(t *testing.T) {
+	sessionRepo := new(MockSessionRepository)
+	service := NewSessionService(sessionRepo, new(MockUserRepositoryForVerification))
+
+	sessionRepo.On("Create", mock.MatchedBy(func(input repository.CreateSessionInput) bool {
+		return input.MFAVerified
+	})).Return(&models.Session{ID: "session-1", UserID: "user-1", MFAVerified: true, ExpiresAt: time.Now().Add(time.Hour)}, nil).Once()
+
+	tokens, err := service.Start(&models.User{ID: "user-1"}, SessionMetadata{MFAVerified: true})
+	require.NoError(t, err)
+
+	// Access tokens from the session record that a second factor was used
+	claims, err := auth.ValidateToken(tokens.AccessToken)
+	require.NoError(t, err)
+	assert.True(t, claims.MFA)
+	sessionRepo.AssertExpectations(t)
+}
@@ -1,7 +1,10 @@
 package service
 
 import (
+	"errors"
 	"fmt"
+	"math"
+	"time"
 
 	"gaspeep/backend/internal/models"
 	"gaspeep/backend/internal/repository"
@@ -20,24 +23,59 @@ type StationOwnerService interface {
 	GetStationDetails(userID, stationID string) (map[string]interface{}, error)
 	SearchAvailableStations(query, lat, lon, radius string) ([]map[string]interface{}, error)
 	ClaimStation(userID, stationID, verificationMethod string, documentUrls []string, phoneNumber, email string) (map[string]interface{}, error)
-	UpdateStation(userID, stationID string, data interface{}) (map[string]interface{}, error)
 	SavePhotos(userID, stationID string, photoURLs []string) ([]string, error)
 	UnclaimStation(userID, stationID string) error
-	ReVerifyStation(userID, stationID string) (map[string]interface{}, error)
 
 	// Fuel Prices
 	GetFuelPrices(userID string) (map[string]interface{}, error)
+	// PublishPrices publishes official prices for the user's stations, all or
+	// none. It returns ErrInvalidOwnerPrices, or the repository's
+	// ErrStationPermissionDenied, ErrStationClaimNotApproved or
+	// ErrUnknownFuelType.
+	PublishPrices(userID string, prices []repository.OwnerPriceInput) error
+
+	// Analytics
+	// PriceBenchmarks compares the user's current prices with competitors'
+	// within radiusKm, day by day over the last days days. It returns
+	// ErrInvalidBenchmarkRange if either is out of range.
+	PriceBenchmarks(userID string, radiusKm float64, days int) ([]models.PriceBenchmark, error)
 }
 
+// MaxOwnerPriceBatch is the most prices an owner can publish at once.
+const MaxOwnerPriceBatch = 100
+
+// maxFuelPrice is the highest price owners and their integrations can
+// publish, in cents per litre like the Service NSW feed. Anything dearer is
+// taken to be a typo.
+const maxFuelPrice = 999.9
+
+// ErrInvalidOwnerPrices is returned when a batch of owner prices is empty or
+// too large, lists a station's fuel type twice, or has a price that is not
+// positive or is above maxFuelPrice.
+var ErrInvalidOwnerPrices = errors.New("invalid owner prices")
+
 type stationOwnerService struct {
 	stationOwnerRepo repository.StationOwnerRepository
+	analyticsRepo    repository.OwnerAnalyticsRepository
+	verification     EmailVerificationPolicy
+	now              func() time.Time
 }
 
-func NewStationOwnerService(stationOwnerRepo repository.StationOwnerRepository) StationOwnerService {
-	return &stationOwnerService{stationOwnerRepo: stationOwnerRepo}
+func NewStationOwnerService(stationOwnerRepo repository.StationOwnerRepository, analyticsRepo repository.OwnerAnalyticsRepository, verification EmailVerificationPolicy) StationOwnerService {
+	return &stationOwnerService{
+		stationOwnerRepo: stationOwnerRepo,
+		analyticsRepo:    analyticsRepo,
+		verification:     verification,
+		now:              time.Now,
+	}
 }
 
+// VerifyOwnership and ClaimStation require a verified email address, since
+// claim reviews and owner notices are sent by email.
 func (s *stationOwnerService) VerifyOwnership(userID string, input repository.CreateOwnerVerificationInput) (*models.StationOwner, error) {
+	if err := s.verification.RequireVerifiedEmail(userID); err != nil {
+		return nil, err
+	}
 	return s.stationOwnerRepo.CreateVerificationRequest(userID, input)
 }
 
@@ -78,8 +116,13 @@ func (s *stationOwnerService) GetProfile(userID string) (map[string]interface{},
 		contactPhone = *owner.ContactPhone
 	}
 
+	broadcasts, err := s.broadcastStats(userID)
+	if err != nil {
+		return nil, err
+	}
+
 	// TODO: Fetch additional fields from user table (email, contact info, etc.)
-	// TODO: Calculate broadcasts this week and plan limits
+	// TODO: Calculate plan limits
 	return map[string]interface{}{
 		"id":                 owner.ID,
 		"userId":             owner.UserID,
@@ -91,16 +134,25 @@ func (s *stationOwnerService) GetProfile(userID string) (map[string]interface{},
 		"verifiedAt":         owner.VerifiedAt,
 		"plan":               owner.Plan,
 		"accountCreatedAt":   owner.CreatedAt,
-		"broadcastsThisWeek": 0, // TODO: Count from broadcasts table
+		"broadcastsThisWeek": broadcasts.BroadcastsThisWeek,
 		"broadcastLimit":     20, // TODO: Get from plan table
 	}, nil
 }
 
+// UpdateProfile and UnclaimStation are limited to the business's owners and
+// managers; staff get repository.ErrStationPermissionDenied.
 func (s *stationOwnerService) UpdateProfile(userID string, input repository.UpdateOwnerProfileInput) (map[string]interface{}, error) {
+	if err := s.requireManager(userID); err != nil {
+		return nil, err
+	}
 	owner, err := s.stationOwnerRepo.UpdateProfile(userID, input)
 	if err != nil {
 		return nil, err
 	}
+	broadcasts, err := s.broadcastStats(userID)
+	if err != nil {
+		return nil, err
+	}
 
 	// Safe dereference of nullable pointer fields
 	contactName := ""
@@ -127,16 +179,36 @@ func (s *stationOwnerService) UpdateProfile(userID string, input repository.Upda
 		"verifiedAt":         owner.VerifiedAt,
 		"plan":               owner.Plan,
 		"accountCreatedAt":   owner.CreatedAt,
-		"broadcastsThisWeek": 0,
+		"broadcastsThisWeek": broadcasts.BroadcastsThisWeek,
 		"broadcastLimit":     20,
 	}, nil
 }
 
+// broadcastStats sums up the broadcasts for the user's stations: those active
+// now and sent in the last week, and their deliveries and opens this calendar
+// month.
+func (s *stationOwnerService) broadcastStats(userID string) (*repository.OwnerBroadcastStats, error) {
+	now := s.now()
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	return s.analyticsRepo.BroadcastStats(userID, now.AddDate(0, 0, -7), monthStart)
+}
+
+// GetStats counts the user's stations and sums up their broadcasts. The reach
+// is this month's deliveries, and the engagement rate the percentage of them
+// that were opened.
 func (s *stationOwnerService) GetStats(userID string) (map[string]interface{}, error) {
-	// Calculate stats based on stations and broadcasts
 	owner, _ := s.stationOwnerRepo.GetByUserID(userID)
 	stations, _ := s.stationOwnerRepo.GetStationsByOwnerUserID(userID)
 
+	broadcasts, err := s.broadcastStats(userID)
+	if err != nil {
+		return nil, err
+	}
+	engagementRate := 0.0
+	if broadcasts.DeliveredThisMonth > 0 {
+		engagementRate = math.Round(float64(broadcasts.OpenedThisMonth)/float64(broadcasts.DeliveredThisMonth)*1000) / 10
+	}
+
 	verifiedCount := 0
 	for _, station := range stations {
 		if status, ok := station["verificationStatus"].(string); ok && status == "verified" {
@@ -151,14 +223,14 @@ func (s *stationOwnerService) GetStats(userID string) (map[string]interface{}, e
 	}
 
 	return map[string]interface{}{
-		"totalStations":        len(stations),
-		"verifiedStations":     verifiedCount,
-		"activeBroadcasts":     0, // TODO: fetch from broadcasts
-		"totalReachThisMonth":  0, // TODO: calculate from broadcasts
-		"averageEngagementRate": 0, // TODO: calculate from analytics
-		"broadcastsThisWeek":   0, // TODO: count from broadcasts
-		"broadcastLimit":       broadcastLimit, // TODO: get from plan table
-		"plan":                 plan,
+		"totalStations":         len(stations),
+		"verifiedStations":      verifiedCount,
+		"activeBroadcasts":      broadcasts.ActiveBroadcasts,
+		"totalReachThisMonth":   broadcasts.DeliveredThisMonth,
+		"averageEngagementRate": engagementRate,
+		"broadcastsThisWeek":    broadcasts.BroadcastsThisWeek,
+		"broadcastLimit":        broadcastLimit, // TODO: get from plan table
+		"plan":                  plan,
 	}, nil
 }
 
@@ -175,16 +247,12 @@ func (s *stationOwnerService) SearchAvailableStations(query, lat, lon, radius st
 }
 
 func (s *stationOwnerService) ClaimStation(userID, stationID, verificationMethod string, documentUrls []string, phoneNumber, email string) (map[string]interface{}, error) {
+	if err := s.verification.RequireVerifiedEmail(userID); err != nil {
+		return nil, err
+	}
 	return s.stationOwnerRepo.ClaimStation(userID, stationID, verificationMethod, documentUrls, phoneNumber, email)
 }
 
-func (s *stationOwnerService) UpdateStation(userID, stationID string, data interface{}) (map[string]interface{}, error) {
-	// TODO: Implement in repository
-	// Verify the station is owned by this user, then update fields
-	// Can update: name, brand, address, operating_hours, amenities
-	return map[string]interface{}{}, nil
-}
-
 func (s *stationOwnerService) SavePhotos(userID, stationID string, photoURLs []string) ([]string, error) {
 	// TODO: Implement in repository
 	// Store photo URLs in a station_photos table
@@ -194,6 +262,10 @@ func (s *stationOwnerService) SavePhotos(userID, stationID string, photoURLs []s
 }
 
 func (s *stationOwnerService) UnclaimStation(userID, stationID string) error {
+	if err := s.requireManager(userID); err != nil {
+		return err
+	}
+
 	// Verify ownership first
 	station, err := s.stationOwnerRepo.GetStationByID(userID, stationID)
 	if err != nil {
@@ -213,14 +285,34 @@ func (s *stationOwnerService) UnclaimStation(userID, stationID string) error {
 	return nil
 }
 
-func (s *stationOwnerService) ReVerifyStation(userID, stationID string) (map[string]interface{}, error) {
-	// TODO: Implement in repository
-	// Create a new verification request for annual renewal
-	// Station should already be verified (verification_status = "verified")
-	// Create a re_verification record and set status to "pending"
-	return map[string]interface{}{}, nil
+// requireManager returns repository.ErrStationPermissionDenied unless the user
+// is an owner or manager of their business.
+func (s *stationOwnerService) requireManager(userID string) error {
+	owner, err := s.stationOwnerRepo.GetByUserID(userID)
+	if err != nil {
+		return err
+	}
+	if !managesTeam(owner.Role) {
+		return repository.ErrStationPermissionDenied
+	}
+	return nil
 }
 
 func (s *stationOwnerService) GetFuelPrices(userID string) (map[string]interface{}, error) {
 	return s.stationOwnerRepo.GetFuelPricesForOwner(userID)
 }
+
+func (s *stationOwnerService) PublishPrices(userID string, prices []repository.OwnerPriceInput) error {
+	if len(prices) == 0 || len(prices) > MaxOwnerPriceBatch {
+		return ErrInvalidOwnerPrices
+	}
+	seen := make(map[[2]string]bool, len(prices))
+	for _, p := range prices {
+		key := [2]string{p.StationID, p.FuelTypeID}
+		if p.StationID == "" || p.FuelTypeID == "" || p.Price <= 0 || p.Price > maxFuelPrice || seen[key] {
+			return ErrInvalidOwnerPrices
+		}
+		seen[key] = true
+	}
+	return s.stationOwnerRepo.PublishPrices(userID, prices)
+}
@@ -1,6 +1,7 @@
 package service
 
 import (
+	"fmt"
 	"testing"
 	"time"
 
@@ -77,6 +78,11 @@ func (m *MockStationOwnerRepositoryForOwnerService) GetFuelPricesForOwner(userID
 	return args.Get(0).(map[string]interface{}), args.Error(1)
 }
 
+func (m *MockStationOwnerRepositoryForOwnerService) PublishPrices(userID string, prices []repository.OwnerPriceInput) error {
+	args := m.Called(userID, prices)
+	return args.Error(0)
+}
+
 func (m *MockStationOwnerRepositoryForOwnerService) UpdateProfile(userID string, input repository.UpdateOwnerProfileInput) (*models.StationOwner, error) {
 	args := m.Called(userID, input)
 	if args.Get(0) == nil {
@@ -85,10 +91,17 @@ func (m *MockStationOwnerRepositoryForOwnerService) UpdateProfile(userID string,
 	return args.Get(0).(*models.StationOwner), args.Error(1)
 }
 
+func (m *MockStationOwnerRepositoryForOwnerService) HasStationPermission(userID, stationID, permission string) (bool, error) {
+	args := m.Called(userID, stationID, permission)
+	return args.Bool(0), args.Error(1)
+}
+
 // Helper function to set up tests
 func setupStationOwnerTest(t *testing.T) (*stationOwnerService, *MockStationOwnerRepositoryForOwnerService) {
 	mockOwnerRepo := new(MockStationOwnerRepositoryForOwnerService)
-	service := NewStationOwnerService(mockOwnerRepo).(*stationOwnerService)
+	mockAnalyticsRepo := new(MockOwnerAnalyticsRepository)
+	mockAnalyticsRepo.On("BroadcastStats", mock.Anything, mock.Anything, mock.Anything).Return(&repository.OwnerBroadcastStats{}, nil).Maybe()
+	service := NewStationOwnerService(mockOwnerRepo, mockAnalyticsRepo, allowAllEmailVerification{}).(*stationOwnerService)
 	return service, mockOwnerRepo
 }
 
@@ -207,6 +220,7 @@ func TestGetStats_MixedVerificationStatus(t *testing.T) {
 func TestUnclaimStation_ValidOwnership_Success(t *testing.T) {
 	service, mockOwnerRepo := setupStationOwnerTest(t)
 
+	mockOwnerRepo.On("GetByUserID", "user-1").Return(&models.StationOwner{ID: "owner-123", Role: models.OwnerRoleOwner}, nil)
 	station := map[string]interface{}{
 		"id":    "station-123",
 		"owner": "user-1",
@@ -223,6 +237,7 @@ func TestUnclaimStation_ValidOwnership_Success(t *testing.T) {
 func TestUnclaimStation_StationNotFound_ReturnsError(t *testing.T) {
 	service, mockOwnerRepo := setupStationOwnerTest(t)
 
+	mockOwnerRepo.On("GetByUserID", "user-1").Return(&models.StationOwner{ID: "owner-123", Role: models.OwnerRoleOwner}, nil)
 	mockOwnerRepo.On("GetStationByID", "user-1", "nonexistent").Return(nil, assert.AnError)
 
 	err := service.UnclaimStation("user-1", "nonexistent")
@@ -234,6 +249,7 @@ func TestUnclaimStation_StationNotFound_ReturnsError(t *testing.T) {
 func TestUnclaimStation_UserNotOwner_ReturnsError(t *testing.T) {
 	service, mockOwnerRepo := setupStationOwnerTest(t)
 
+	mockOwnerRepo.On("GetByUserID", "user-1").Return(&models.StationOwner{ID: "owner-123", Role: models.OwnerRoleOwner}, nil)
 	mockOwnerRepo.On("GetStationByID", "user-1", "station-123").Return(nil, nil)
 
 	err := service.UnclaimStation("user-1", "station-123")
@@ -242,6 +258,20 @@ func TestUnclaimStation_UserNotOwner_ReturnsError(t *testing.T) {
 	mockOwnerRepo.AssertNotCalled(t, "UnclaimStation")
 }
 
+func TestUnclaimStation_StaffMember_ReturnsPermissionDenied(t *testing.T) {
+	service, mockOwnerRepo := setupStationOwnerTest(t)
+
+	mockOwnerRepo.On("GetByUserID", "user-1").Return(&models.StationOwner{ID: "owner-123", Role: models.OwnerRoleStaff}, nil)
+
+	err := service.UnclaimStation("user-1", "station-123")
+
+	assert.ErrorIs(t, err, repository.ErrStationPermissionDenied)
+	mockOwnerRepo.AssertNotCalled(t, "UnclaimStation", mock.Anything, mock.Anything)
+	_, err = service.UpdateProfile("user-1", repository.UpdateOwnerProfileInput{BusinessName: "Renamed"})
+	assert.ErrorIs(t, err, repository.ErrStationPermissionDenied)
+	mockOwnerRepo.AssertNotCalled(t, "UpdateProfile", mock.Anything, mock.Anything)
+}
+
 // ============ GetStations Tests ============
 
 func TestGetStations_Success(t *testing.T) {
@@ -313,6 +343,51 @@ func TestGetFuelPrices_Success(t *testing.T) {
 	assert.Equal(t, fuelPrices, result)
 }
 
+// ============ PublishPrices Tests ============
+
+func TestPublishPrices_Success(t *testing.T) {
+	service, mockOwnerRepo := setupStationOwnerTest(t)
+
+	prices := []repository.OwnerPriceInput{
+		{StationID: "station-1", FuelTypeID: "e10", Price: 1.799},
+		{StationID: "station-1", FuelTypeID: "diesel", Price: 1.959},
+	}
+	mockOwnerRepo.On("PublishPrices", "user-1", prices).Return(nil)
+
+	require.NoError(t, service.PublishPrices("user-1", prices))
+	mockOwnerRepo.AssertExpectations(t)
+}
+
+func TestPublishPrices_RejectsInvalidBatches(t *testing.T) {
+	service, mockOwnerRepo := setupStationOwnerTest(t)
+
+	tooMany := make([]repository.OwnerPriceInput, MaxOwnerPriceBatch+1)
+	for i := range tooMany {
+		tooMany[i] = repository.OwnerPriceInput{StationID: "station-1", FuelTypeID: fmt.Sprint(i), Price: 1.5}
+	}
+	batches := map[string][]repository.OwnerPriceInput{
+		"empty":          nil,
+		"too many":       tooMany,
+		"zero price":     {{StationID: "station-1", FuelTypeID: "e10", Price: 0}},
+		"typo price":     {{StationID: "station-1", FuelTypeID: "e10", Price: 19990}},
+		"missing fuel":   {{StationID: "station-1", Price: 1.5}},
+		"duplicate fuel": {{StationID: "station-1", FuelTypeID: "e10", Price: 1.5}, {StationID: "station-1", FuelTypeID: "e10", Price: 1.6}},
+	}
+	for name, prices := range batches {
+		assert.ErrorIs(t, service.PublishPrices("user-1", prices), ErrInvalidOwnerPrices, name)
+	}
+	mockOwnerRepo.AssertNotCalled(t, "PublishPrices", mock.Anything, mock.Anything)
+}
+
+func TestPublishPrices_ClaimNotApproved(t *testing.T) {
+	service, mockOwnerRepo := setupStationOwnerTest(t)
+
+	prices := []repository.OwnerPriceInput{{StationID: "station-2", FuelTypeID: "e10", Price: 1.799}}
+	mockOwnerRepo.On("PublishPrices", "user-1", prices).Return(repository.ErrStationClaimNotApproved)
+
+	assert.ErrorIs(t, service.PublishPrices("user-1", prices), repository.ErrStationClaimNotApproved)
+}
+
 // ============ VerifyOwnership Tests ============
 
 func TestVerifyOwnership_Success(t *testing.T) {
@@ -338,6 +413,7 @@ func TestVerifyOwnership_Success(t *testing.T) {
 func TestUpdateProfile_Success(t *testing.T) {
 	service, mockOwnerRepo := setupStationOwnerTest(t)
 
+	mockOwnerRepo.On("GetByUserID", "user-1").Return(&models.StationOwner{ID: "owner-123", Role: models.OwnerRoleOwner}, nil)
 	input := repository.UpdateOwnerProfileInput{
 		BusinessName: "Updated Business",
 		ContactName:  "Alex",
@@ -390,17 +466,6 @@ func TestClaimStation_Success(t *testing.T) {
 	mockOwnerRepo.AssertExpectations(t)
 }
 
-// ============ UpdateStation Tests ============
-
-func TestUpdateStation_ReturnsEmptyMapUntilImplemented(t *testing.T) {
-	service, _ := setupStationOwnerTest(t)
-
-	result, err := service.UpdateStation("user-1", "station-1", map[string]interface{}{"name": "New Name"})
-
-	require.NoError(t, err)
-	assert.Equal(t, map[string]interface{}{}, result)
-}
-
 // ============ SavePhotos Tests ============
 
 func TestSavePhotos_ReturnsInputURLsUntilImplemented(t *testing.T) {
@@ -412,14 +477,3 @@ func TestSavePhotos_ReturnsInputURLsUntilImplemented(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, photos, result)
 }
-
-// ============ ReVerifyStation Tests ============
-
-func TestReVerifyStation_ReturnsEmptyMapUntilImplemented(t *testing.T) {
-	service, _ := setupStationOwnerTest(t)
-
-	result, err := service.ReVerifyStation("user-1", "station-1")
-
-	require.NoError(t, err)
-	assert.Equal(t, map[string]interface{}{}, result)
-}
@@ -0,0 +1,323 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"gaspeep/backend/internal/models"
+	"gaspeep/backend/internal/repository"
+)
+
+// Limits on station profile fields.
+const (
+	maxStationNameLength           = 255
+	maxStationBrandLength          = 255
+	maxStationAddressLength        = 500
+	maxStationPhoneLength          = 50
+	maxStationWebsiteLength        = 500
+	maxStationOperatingHoursLength = 4000
+	maxStationAmenities            = 50
+	maxStationAmenityLength        = 100
+)
+
+var (
+	// ErrInvalidStationProfile is returned when an owner's changes leave a
+	// required field empty, exceed a length limit, or give a latitude or
+	// longitude without the other or out of range.
+	ErrInvalidStationProfile = errors.New("invalid station profile")
+	// ErrStationChangeNotFound is returned for an unknown change request.
+	ErrStationChangeNotFound = errors.New("station change request not found")
+	// ErrStationVersionNotFound is returned for an unknown station version.
+	ErrStationVersionNotFound = errors.New("station version not found")
+)
+
+// StationUpdateResult is the outcome of an owner's station edit: the profile
+// after the changes that applied straight away, the version they made, and
+// the request holding any changes that wait for review.
+type StationUpdateResult struct {
+	Profile       models.StationProfile         `json:"profile"`
+	Version       *models.StationProfileVersion `json:"version,omitempty"`
+	PendingChange *models.StationChangeRequest  `json:"pendingChange,omitempty"`
+}
+
+// StationProfileDiff is a field that differs between two versions.
+type StationProfileDiff struct {
+	Field string      `json:"field"`
+	From  interface{} `json:"from"`
+	To    interface{} `json:"to"`
+}
+
+// StationProfileService lets owners edit their stations and admins review
+// brand and location changes, compare versions and roll back.
+type StationProfileService interface {
+	// UpdateStation applies an owner's changes to a station whose claim has
+	// been approved. Name, operating hours, amenities and contact details
+	// change straight away; brand, address and location changes are queued
+	// for review. It returns ErrInvalidStationProfile or the repository's
+	// ErrStationClaimNotApproved.
+	UpdateStation(userID, stationID string, changes models.StationProfileChanges) (*StationUpdateResult, error)
+	// StationChanges returns the change requests for one of the owner's
+	// stations.
+	StationChanges(userID, stationID string) ([]models.StationChangeRequest, error)
+	// ReviewQueue returns change requests with status, pending ones if it is
+	// empty, oldest first.
+	ReviewQueue(status string) ([]models.StationChangeRequest, error)
+	// ApproveChange applies a pending change request, recording the admin
+	// who reviewed it. It returns ErrStationChangeNotFound or the
+	// repository's ErrChangeRequestNotPending.
+	ApproveChange(id, reviewerID, notes string) (*models.StationProfileVersion, error)
+	// RejectChange rejects a pending change request, recording the admin who
+	// reviewed it.
+	RejectChange(id, reviewerID, notes string) error
+	// Versions returns a station's versions, newest first.
+	Versions(stationID string) ([]models.StationProfileVersion, error)
+	// Diff returns the fields that differ between two of a station's
+	// versions.
+	Diff(stationID string, from, to int) ([]StationProfileDiff, error)
+	// Rollback restores a station's profile as it was in version, recording
+	// it as a new version made by the admin.
+	Rollback(stationID, adminID string, version int) (*models.StationProfileVersion, error)
+}
+
+type stationProfileService struct {
+	profileRepo repository.StationProfileRepository
+}
+
+func NewStationProfileService(profileRepo repository.StationProfileRepository) StationProfileService {
+	return &stationProfileService{profileRepo: profileRepo}
+}
+
+func (s *stationProfileService) UpdateStation(userID, stationID string, changes models.StationProfileChanges) (*StationUpdateResult, error) {
+	changes = trimStationChanges(changes)
+	if err := validateStationChanges(changes); err != nil {
+		return nil, err
+	}
+
+	owner, err := s.profileRepo.IsApprovedOwner(userID, stationID)
+	if err != nil {
+		return nil, err
+	}
+	if !owner {
+		return nil, repository.ErrStationClaimNotApproved
+	}
+
+	current, err := s.profileRepo.GetProfile(stationID)
+	if err != nil {
+		return nil, err
+	}
+	immediate, review := splitStationChanges(*current, changes)
+	result := &StationUpdateResult{Profile: *current}
+
+	if hasStationChanges(immediate) {
+		version, err := s.profileRepo.ApplyChanges(stationID, immediate, repository.StationVersionMeta{
+			Source:    models.StationVersionSourceOwner,
+			ChangedBy: userID,
+		})
+		if err != nil {
+			return nil, err
+		}
+		result.Profile = version.Profile
+		result.Version = version
+	}
+
+	if hasStationChanges(review) {
+		result.PendingChange, err = s.profileRepo.CreateChangeRequest(stationID, userID, review)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+func trimStationChanges(c models.StationProfileChanges) models.StationProfileChanges {
+	for _, field := range []**string{&c.Name, &c.Brand, &c.Address, &c.OperatingHours, &c.Phone, &c.Website} {
+		if *field != nil {
+			trimmed := strings.TrimSpace(**field)
+			*field = &trimmed
+		}
+	}
+	if c.Amenities != nil {
+		amenities := make([]string, 0, len(c.Amenities))
+		for _, a := range c.Amenities {
+			if a = strings.TrimSpace(a); a != "" && !slices.Contains(amenities, a) {
+				amenities = append(amenities, a)
+			}
+		}
+		c.Amenities = amenities
+	}
+	return c
+}
+
+func validateStationChanges(c models.StationProfileChanges) error {
+	tooLong := func(s *string, max int) bool { return s != nil && len(*s) > max }
+	empty := func(s *string) bool { return s != nil && *s == "" }
+
+	if empty(c.Name) || empty(c.Address) ||
+		tooLong(c.Name, maxStationNameLength) || tooLong(c.Brand, maxStationBrandLength) ||
+		tooLong(c.Address, maxStationAddressLength) || tooLong(c.Phone, maxStationPhoneLength) ||
+		tooLong(c.Website, maxStationWebsiteLength) || tooLong(c.OperatingHours, maxStationOperatingHoursLength) {
+		return ErrInvalidStationProfile
+	}
+	if (c.Latitude == nil) != (c.Longitude == nil) {
+		return ErrInvalidStationProfile
+	}
+	if c.Latitude != nil && (*c.Latitude < -90 || *c.Latitude > 90 || *c.Longitude < -180 || *c.Longitude > 180) {
+		return ErrInvalidStationProfile
+	}
+	if len(c.Amenities) > maxStationAmenities {
+		return ErrInvalidStationProfile
+	}
+	for _, a := range c.Amenities {
+		if len(a) > maxStationAmenityLength {
+			return ErrInvalidStationProfile
+		}
+	}
+	return nil
+}
+
+// splitStationChanges drops changes that match the current profile and
+// separates the rest into those that apply straight away and those that
+// need review.
+func splitStationChanges(current models.StationProfile, c models.StationProfileChanges) (immediate, review models.StationProfileChanges) {
+	changed := func(s *string, value string) bool { return s != nil && *s != value }
+
+	if changed(c.Name, current.Name) {
+		immediate.Name = c.Name
+	}
+	if changed(c.OperatingHours, current.OperatingHours) {
+		immediate.OperatingHours = c.OperatingHours
+	}
+	if c.Amenities != nil && !slices.Equal(c.Amenities, current.Amenities) {
+		immediate.Amenities = c.Amenities
+	}
+	if changed(c.Phone, current.Phone) {
+		immediate.Phone = c.Phone
+	}
+	if changed(c.Website, current.Website) {
+		immediate.Website = c.Website
+	}
+
+	if changed(c.Brand, current.Brand) {
+		review.Brand = c.Brand
+	}
+	if changed(c.Address, current.Address) {
+		review.Address = c.Address
+	}
+	if c.Latitude != nil && (*c.Latitude != current.Latitude || *c.Longitude != current.Longitude) {
+		review.Latitude, review.Longitude = c.Latitude, c.Longitude
+	}
+	return immediate, review
+}
+
+func hasStationChanges(c models.StationProfileChanges) bool {
+	return c.Name != nil || c.Brand != nil || c.Address != nil || c.Latitude != nil || c.Longitude != nil ||
+		c.OperatingHours != nil || c.Amenities != nil || c.Phone != nil || c.Website != nil
+}
+
+func (s *stationProfileService) StationChanges(userID, stationID string) ([]models.StationChangeRequest, error) {
+	owner, err := s.profileRepo.IsApprovedOwner(userID, stationID)
+	if err != nil {
+		return nil, err
+	}
+	if !owner {
+		return nil, repository.ErrStationClaimNotApproved
+	}
+	return s.profileRepo.ListChangeRequests(repository.StationChangeRequestFilter{StationID: stationID})
+}
+
+func (s *stationProfileService) ReviewQueue(status string) ([]models.StationChangeRequest, error) {
+	if status == "" {
+		status = models.StationChangePending
+	}
+	return s.profileRepo.ListChangeRequests(repository.StationChangeRequestFilter{Status: status})
+}
+
+func (s *stationProfileService) ApproveChange(id, reviewerID, notes string) (*models.StationProfileVersion, error) {
+	version, err := s.profileRepo.ApproveChangeRequest(id, reviewerID, strings.TrimSpace(notes))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrStationChangeNotFound
+	}
+	return version, err
+}
+
+func (s *stationProfileService) RejectChange(id, reviewerID, notes string) error {
+	err := s.profileRepo.RejectChangeRequest(id, reviewerID, strings.TrimSpace(notes))
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrStationChangeNotFound
+	}
+	return err
+}
+
+func (s *stationProfileService) Versions(stationID string) ([]models.StationProfileVersion, error) {
+	return s.profileRepo.ListVersions(stationID)
+}
+
+func (s *stationProfileService) version(stationID string, version int) (*models.StationProfileVersion, error) {
+	v, err := s.profileRepo.GetVersion(stationID, version)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrStationVersionNotFound
+	}
+	return v, err
+}
+
+func (s *stationProfileService) Diff(stationID string, from, to int) ([]StationProfileDiff, error) {
+	a, err := s.version(stationID, from)
+	if err != nil {
+		return nil, err
+	}
+	b, err := s.version(stationID, to)
+	if err != nil {
+		return nil, err
+	}
+	return diffStationProfiles(a.Profile, b.Profile), nil
+}
+
+func diffStationProfiles(a, b models.StationProfile) []StationProfileDiff {
+	diffs := []StationProfileDiff{}
+	add := func(field string, from, to interface{}, differs bool) {
+		if differs {
+			diffs = append(diffs, StationProfileDiff{Field: field, From: from, To: to})
+		}
+	}
+	add("name", a.Name, b.Name, a.Name != b.Name)
+	add("brand", a.Brand, b.Brand, a.Brand != b.Brand)
+	add("address", a.Address, b.Address, a.Address != b.Address)
+	add("latitude", a.Latitude, b.Latitude, a.Latitude != b.Latitude)
+	add("longitude", a.Longitude, b.Longitude, a.Longitude != b.Longitude)
+	add("operatingHours", a.OperatingHours, b.OperatingHours, a.OperatingHours != b.OperatingHours)
+	add("amenities", a.Amenities, b.Amenities, !slices.Equal(a.Amenities, b.Amenities))
+	add("phone", a.Phone, b.Phone, a.Phone != b.Phone)
+	add("website", a.Website, b.Website, a.Website != b.Website)
+	return diffs
+}
+
+func (s *stationProfileService) Rollback(stationID, adminID string, version int) (*models.StationProfileVersion, error) {
+	v, err := s.version(stationID, version)
+	if err != nil {
+		return nil, err
+	}
+
+	p := v.Profile
+	amenities := p.Amenities
+	if amenities == nil {
+		amenities = []string{}
+	}
+	restored, err := s.profileRepo.ApplyChanges(stationID, models.StationProfileChanges{
+		Name:           &p.Name,
+		Brand:          &p.Brand,
+		Address:        &p.Address,
+		Latitude:       &p.Latitude,
+		Longitude:      &p.Longitude,
+		OperatingHours: &p.OperatingHours,
+		Amenities:      amenities,
+		Phone:          &p.Phone,
+		Website:        &p.Website,
+	}, repository.StationVersionMeta{Source: models.StationVersionSourceRollback, ChangedBy: adminID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to roll back station to version %d: %w", version, err)
+	}
+	return restored, nil
+}
@@ -0,0 +1,232 @@
+package service
+
+import (
+	"database/sql"
+	"testing"
+
+	"gaspeep/backend/internal/models"
+	"gaspeep/backend/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockStationProfileRepository mocks StationProfileRepository
+type MockStationProfileRepository struct {
+	mock.Mock
+}
+
+func (m *MockStationProfileRepository) IsApprovedOwner(userID, stationID string) (bool, error) {
+	args := m.Called(userID, stationID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockStationProfileRepository) GetProfile(stationID string) (*models.StationProfile, error) {
+	args := m.Called(stationID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.StationProfile), args.Error(1)
+}
+
+func (m *MockStationProfileRepository) ApplyChanges(stationID string, changes models.StationProfileChanges, meta repository.StationVersionMeta) (*models.StationProfileVersion, error) {
+	args := m.Called(stationID, changes, meta)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.StationProfileVersion), args.Error(1)
+}
+
+func (m *MockStationProfileRepository) ListVersions(stationID string) ([]models.StationProfileVersion, error) {
+	args := m.Called(stationID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.StationProfileVersion), args.Error(1)
+}
+
+func (m *MockStationProfileRepository) GetVersion(stationID string, version int) (*models.StationProfileVersion, error) {
+	args := m.Called(stationID, version)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.StationProfileVersion), args.Error(1)
+}
+
+func (m *MockStationProfileRepository) CreateChangeRequest(stationID, requestedBy string, changes models.StationProfileChanges) (*models.StationChangeRequest, error) {
+	args := m.Called(stationID, requestedBy, changes)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.StationChangeRequest), args.Error(1)
+}
+
+func (m *MockStationProfileRepository) ListChangeRequests(filter repository.StationChangeRequestFilter) ([]models.StationChangeRequest, error) {
+	args := m.Called(filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.StationChangeRequest), args.Error(1)
+}
+
+func (m *MockStationProfileRepository) ApproveChangeRequest(id, reviewerID, notes string) (*models.StationProfileVersion, error) {
+	args := m.Called(id, reviewerID, notes)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.StationProfileVersion), args.Error(1)
+}
+
+func (m *MockStationProfileRepository) RejectChangeRequest(id, reviewerID, notes string) error {
+	args := m.Called(id, reviewerID, notes)
+	return args.Error(0)
+}
+
+func strPtr(s string) *string { return &s }
+
+func testStationProfile() *models.StationProfile {
+	return &models.StationProfile{
+		Name:      "Acme Fuel",
+		Brand:     "Acme",
+		Address:   "1 George St, Sydney",
+		Latitude:  -33.8688,
+		Longitude: 151.2093,
+		Amenities: []string{"toilets"},
+	}
+}
+
+func TestStationProfile_UpdateSplitsImmediateAndReviewedChanges(t *testing.T) {
+	repo := new(MockStationProfileRepository)
+	svc := NewStationProfileService(repo)
+
+	repo.On("IsApprovedOwner", "user-1", "station-1").Return(true, nil)
+	repo.On("GetProfile", "station-1").Return(testStationProfile(), nil)
+	immediate := models.StationProfileChanges{Name: strPtr("Acme Fuel George St"), Phone: strPtr("02 9000 0000")}
+	updated := *testStationProfile()
+	updated.Name, updated.Phone = "Acme Fuel George St", "02 9000 0000"
+	repo.On("ApplyChanges", "station-1", immediate, repository.StationVersionMeta{Source: models.StationVersionSourceOwner, ChangedBy: "user-1"}).
+		Return(&models.StationProfileVersion{Version: 2, Profile: updated}, nil).Once()
+	review := models.StationProfileChanges{Brand: strPtr("Metro"), Latitude: floatPtr(-33.87), Longitude: floatPtr(151.2093)}
+	repo.On("CreateChangeRequest", "station-1", "user-1", review).
+		Return(&models.StationChangeRequest{ID: "change-1", Status: models.StationChangePending}, nil).Once()
+
+	// The address and amenities are unchanged, so they are left out
+	result, err := svc.UpdateStation("user-1", "station-1", models.StationProfileChanges{
+		Name:      strPtr(" Acme Fuel George St "),
+		Brand:     strPtr("Metro"),
+		Address:   strPtr("1 George St, Sydney"),
+		Latitude:  floatPtr(-33.87),
+		Longitude: floatPtr(151.2093),
+		Amenities: []string{"toilets"},
+		Phone:     strPtr("02 9000 0000"),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "Acme Fuel George St", result.Profile.Name)
+	assert.Equal(t, "Acme", result.Profile.Brand)
+	assert.Equal(t, 2, result.Version.Version)
+	assert.Equal(t, "change-1", result.PendingChange.ID)
+	repo.AssertExpectations(t)
+}
+
+func TestStationProfile_UpdateWithoutChanges(t *testing.T) {
+	repo := new(MockStationProfileRepository)
+	svc := NewStationProfileService(repo)
+
+	repo.On("IsApprovedOwner", "user-1", "station-1").Return(true, nil)
+	repo.On("GetProfile", "station-1").Return(testStationProfile(), nil)
+
+	result, err := svc.UpdateStation("user-1", "station-1", models.StationProfileChanges{Name: strPtr("Acme Fuel"), Brand: strPtr("Acme")})
+	require.NoError(t, err)
+	assert.Nil(t, result.Version)
+	assert.Nil(t, result.PendingChange)
+	repo.AssertNotCalled(t, "ApplyChanges", mock.Anything, mock.Anything, mock.Anything)
+	repo.AssertNotCalled(t, "CreateChangeRequest", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestStationProfile_UpdateRejectsInvalidChangesAndOtherOwners(t *testing.T) {
+	repo := new(MockStationProfileRepository)
+	svc := NewStationProfileService(repo)
+
+	invalid := []models.StationProfileChanges{
+		{Name: strPtr("  ")},
+		{Address: strPtr("")},
+		{Latitude: floatPtr(-33.87)},
+		{Latitude: floatPtr(-91), Longitude: floatPtr(151)},
+		{Phone: strPtr(string(make([]byte, maxStationPhoneLength+1)))},
+	}
+	for _, changes := range invalid {
+		_, err := svc.UpdateStation("user-1", "station-1", changes)
+		assert.ErrorIs(t, err, ErrInvalidStationProfile)
+	}
+
+	repo.On("IsApprovedOwner", "user-2", "station-1").Return(false, nil)
+	_, err := svc.UpdateStation("user-2", "station-1", models.StationProfileChanges{Name: strPtr("Mine now")})
+	assert.ErrorIs(t, err, repository.ErrStationClaimNotApproved)
+	repo.AssertNotCalled(t, "GetProfile", mock.Anything)
+}
+
+func TestStationProfile_ReviewNotFound(t *testing.T) {
+	repo := new(MockStationProfileRepository)
+	svc := NewStationProfileService(repo)
+
+	repo.On("ApproveChangeRequest", "missing", "admin-1", "").Return(nil, sql.ErrNoRows)
+	repo.On("RejectChangeRequest", "done", "admin-1", "duplicate").Return(repository.ErrChangeRequestNotPending)
+
+	_, err := svc.ApproveChange("missing", "admin-1", "")
+	assert.ErrorIs(t, err, ErrStationChangeNotFound)
+	assert.ErrorIs(t, svc.RejectChange("done", "admin-1", " duplicate "), repository.ErrChangeRequestNotPending)
+}
+
+func TestStationProfile_ReviewQueueDefaultsToPending(t *testing.T) {
+	repo := new(MockStationProfileRepository)
+	svc := NewStationProfileService(repo)
+
+	repo.On("ListChangeRequests", repository.StationChangeRequestFilter{Status: models.StationChangePending}).
+		Return([]models.StationChangeRequest{{ID: "change-1"}}, nil).Once()
+
+	queue, err := svc.ReviewQueue("")
+	require.NoError(t, err)
+	assert.Len(t, queue, 1)
+}
+
+func TestStationProfile_Diff(t *testing.T) {
+	repo := new(MockStationProfileRepository)
+	svc := NewStationProfileService(repo)
+
+	before := *testStationProfile()
+	after := before
+	after.Brand = "Metro"
+	after.Amenities = []string{"toilets", "car wash"}
+	repo.On("GetVersion", "station-1", 1).Return(&models.StationProfileVersion{Version: 1, Profile: before}, nil)
+	repo.On("GetVersion", "station-1", 3).Return(&models.StationProfileVersion{Version: 3, Profile: after}, nil)
+	repo.On("GetVersion", "station-1", 9).Return(nil, sql.ErrNoRows)
+
+	diff, err := svc.Diff("station-1", 1, 3)
+	require.NoError(t, err)
+	assert.Equal(t, []StationProfileDiff{
+		{Field: "brand", From: "Acme", To: "Metro"},
+		{Field: "amenities", From: []string{"toilets"}, To: []string{"toilets", "car wash"}},
+	}, diff)
+
+	_, err = svc.Diff("station-1", 1, 9)
+	assert.ErrorIs(t, err, ErrStationVersionNotFound)
+}
+
+func TestStationProfile_RollbackRestoresEveryField(t *testing.T) {
+	repo := new(MockStationProfileRepository)
+	svc := NewStationProfileService(repo)
+
+	old := *testStationProfile()
+	old.Amenities = nil
+	repo.On("GetVersion", "station-1", 1).Return(&models.StationProfileVersion{Version: 1, Profile: old}, nil)
+	repo.On("ApplyChanges", "station-1", mock.MatchedBy(func(c models.StationProfileChanges) bool {
+		return *c.Name == old.Name && *c.Brand == old.Brand && *c.Latitude == old.Latitude &&
+			*c.Phone == "" && c.Amenities != nil && len(c.Amenities) == 0
+	}), repository.StationVersionMeta{Source: models.StationVersionSourceRollback, ChangedBy: "admin-1"}).
+		Return(&models.StationProfileVersion{Version: 5, Profile: old}, nil).Once()
+
+	restored, err := svc.Rollback("station-1", "admin-1", 1)
+	require.NoError(t, err)
+	assert.Equal(t, 5, restored.Version)
+	repo.AssertExpectations(t)
+}
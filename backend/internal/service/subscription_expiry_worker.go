@@ -0,0 +1,39 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+const subscriptionExpiryInterval = time.Hour
+
+// SubscriptionExpiryWorker periodically expires subscriptions whose period or
+// grace period has run out without a webhook saying they renewed, so their
+// users drop back to the free tier.
+type SubscriptionExpiryWorker struct {
+	subscriptionService SubscriptionService
+}
+
+func NewSubscriptionExpiryWorker(subscriptionService SubscriptionService) *SubscriptionExpiryWorker {
+	return &SubscriptionExpiryWorker{subscriptionService: subscriptionService}
+}
+
+// Start expires lapsed subscriptions now and then every hour until ctx is
+// cancelled.
+func (w *SubscriptionExpiryWorker) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(subscriptionExpiryInterval)
+		defer ticker.Stop()
+		for {
+			if err := w.subscriptionService.ExpireLapsed(); err != nil {
+				log.Printf("Subscription expiry failed: %v", err)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
@@ -0,0 +1,303 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"time"
+
+	"gaspeep/backend/internal/models"
+	"gaspeep/backend/internal/repository"
+)
+
+// Entitlements are features a subscription pays for.
+const (
+	// EntitlementPriceAlerts allows creating and changing price alerts.
+	EntitlementPriceAlerts = "price_alerts"
+)
+
+// tierEntitlements lists what each tier includes. The free tier includes
+// none.
+var tierEntitlements = map[string][]string{
+	models.TierPremium: {EntitlementPriceAlerts},
+}
+
+var (
+	ErrPlanNotFound      = errors.New("subscription plan not found")
+	ErrAlreadySubscribed = errors.New("already subscribed")
+	ErrNoSubscription    = errors.New("no active subscription")
+)
+
+// SubscriptionStatus is a user's tier, what it entitles them to, and the
+// subscription it comes from, if any.
+type SubscriptionStatus struct {
+	Tier         string               `json:"tier"`
+	Entitlements []string             `json:"entitlements"`
+	Subscription *models.Subscription `json:"subscription"`
+}
+
+// SubscriptionService sells plans through a PaymentProvider and decides what
+// users are entitled to. A user's tier comes from their subscription and is
+// copied to users.tier whenever it changes.
+type SubscriptionService interface {
+	Plans() ([]models.SubscriptionPlan, error)
+	Status(userID string) (*SubscriptionStatus, error)
+	// Checkout starts paying for a plan, returning the provider's payment
+	// page. A user's first subscription starts with the plan's free trial. It
+	// returns ErrPlanNotFound or ErrAlreadySubscribed.
+	Checkout(userID, planID string) (*Checkout, error)
+	// Cancel stops the user's subscription renewing. They keep their tier
+	// until the end of the period. It returns ErrNoSubscription if nothing
+	// would renew.
+	Cancel(userID string) (*models.Subscription, error)
+	// HandleWebhook applies a provider's webhook. Events already applied are
+	// ignored. It returns ErrInvalidPaymentWebhook if the request cannot be
+	// verified.
+	HandleWebhook(payload []byte, header http.Header) error
+	HasEntitlement(userID, entitlement string) (bool, error)
+	// ExpireLapsed expires subscriptions that have run out and moves their
+	// users back to the free tier.
+	ExpireLapsed() error
+}
+
+type subscriptionService struct {
+	subscriptionRepo repository.SubscriptionRepository
+	userRepo         repository.UserRepository
+	provider         PaymentProvider
+	grace            time.Duration
+	now              func() time.Time
+}
+
+// NewSubscriptionService keeps subscriptions in step with provider. When a
+// renewal fails or its webhook is late, users keep their tier for a grace
+// period of SUBSCRIPTION_GRACE_DAYS (default 7).
+func NewSubscriptionService(
+	subscriptionRepo repository.SubscriptionRepository,
+	userRepo repository.UserRepository,
+	provider PaymentProvider,
+) SubscriptionService {
+	graceDays := parseEnvInt("SUBSCRIPTION_GRACE_DAYS", 7)
+	if graceDays < 0 {
+		graceDays = 7
+	}
+	return &subscriptionService{
+		subscriptionRepo: subscriptionRepo,
+		userRepo:         userRepo,
+		provider:         provider,
+		grace:            time.Duration(graceDays) * 24 * time.Hour,
+		now:              time.Now,
+	}
+}
+
+func (s *subscriptionService) Plans() ([]models.SubscriptionPlan, error) {
+	return s.subscriptionRepo.ListPlans()
+}
+
+// entitled reports whether sub still grants its tier. Trials and paid
+// periods run for the grace period past their end, so a late renewal does
+// not interrupt them; canceled subscriptions end with their period.
+func (s *subscriptionService) entitled(sub *models.Subscription, now time.Time) bool {
+	switch sub.Status {
+	case models.SubscriptionTrialing, models.SubscriptionActive:
+		return now.Before(sub.CurrentPeriodEnd.Add(s.grace))
+	case models.SubscriptionPastDue:
+		return sub.GraceUntil != nil && now.Before(*sub.GraceUntil)
+	case models.SubscriptionCanceled:
+		return now.Before(sub.CurrentPeriodEnd)
+	}
+	return false
+}
+
+// current returns the user's subscription, or nil if they have never had
+// one.
+func (s *subscriptionService) current(userID string) (*models.Subscription, error) {
+	sub, err := s.subscriptionRepo.GetCurrent(userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return sub, err
+}
+
+func (s *subscriptionService) Status(userID string) (*SubscriptionStatus, error) {
+	sub, err := s.current(userID)
+	if err != nil {
+		return nil, err
+	}
+	tier := models.TierFree
+	if sub != nil && s.entitled(sub, s.now()) {
+		tier = sub.Tier
+	}
+	return &SubscriptionStatus{
+		Tier:         tier,
+		Entitlements: append([]string{}, tierEntitlements[tier]...),
+		Subscription: sub,
+	}, nil
+}
+
+func (s *subscriptionService) HasEntitlement(userID, entitlement string) (bool, error) {
+	status, err := s.Status(userID)
+	if err != nil {
+		return false, err
+	}
+	return slices.Contains(status.Entitlements, entitlement), nil
+}
+
+func (s *subscriptionService) Checkout(userID, planID string) (*Checkout, error) {
+	plan, err := s.subscriptionRepo.GetPlan(planID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrPlanNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	sub, err := s.current(userID)
+	if err != nil {
+		return nil, err
+	}
+	// A canceled subscription can be replaced before it runs out
+	if sub != nil && sub.Status != models.SubscriptionCanceled && s.entitled(sub, s.now()) {
+		return nil, ErrAlreadySubscribed
+	}
+	trialDays := plan.TrialDays
+	if sub != nil {
+		trialDays = 0
+	}
+
+	user, err := s.userRepo.GetUserByID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load user for checkout: %w", err)
+	}
+	return s.provider.CreateCheckout(CheckoutRequest{
+		UserID:     userID,
+		Email:      user.Email,
+		Plan:       *plan,
+		TrialDays:  trialDays,
+		SuccessURL: appURL("/profile?subscription=success"),
+		CancelURL:  appURL("/auth/tier-comparison"),
+	})
+}
+
+func (s *subscriptionService) Cancel(userID string) (*models.Subscription, error) {
+	sub, err := s.current(userID)
+	if err != nil {
+		return nil, err
+	}
+	now := s.now()
+	if sub == nil || sub.Status == models.SubscriptionCanceled || !s.entitled(sub, now) {
+		return nil, ErrNoSubscription
+	}
+
+	if err := s.provider.CancelSubscription(sub.ProviderSubscriptionID); err != nil {
+		return nil, fmt.Errorf("failed to cancel subscription with provider: %w", err)
+	}
+	sub.Status = models.SubscriptionCanceled
+	sub.CanceledAt = &now
+	if err := s.subscriptionRepo.Save(sub); err != nil {
+		return nil, err
+	}
+	return sub, nil
+}
+
+func (s *subscriptionService) HandleWebhook(payload []byte, header http.Header) error {
+	event, err := s.provider.ParseWebhook(payload, header)
+	if err != nil {
+		return err
+	}
+
+	sub, err := s.subscriptionRepo.GetByProviderID(s.provider.Name(), event.SubscriptionID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+	if sub == nil && event.Type != PaymentEventSubscriptionStarted {
+		log.Printf("warning: ignoring %s webhook %s for unknown subscription %s", event.Type, event.ID, event.SubscriptionID)
+		return nil
+	}
+
+	now := s.now()
+	switch event.Type {
+	case PaymentEventSubscriptionStarted:
+		if sub == nil {
+			if event.UserID == "" {
+				return fmt.Errorf("%w: %s event without userId", ErrInvalidPaymentWebhook, event.Type)
+			}
+			sub = &models.Subscription{
+				UserID:                 event.UserID,
+				Provider:               s.provider.Name(),
+				ProviderSubscriptionID: event.SubscriptionID,
+			}
+		}
+		plan, err := s.subscriptionRepo.GetPlan(event.PlanID)
+		if err != nil {
+			return fmt.Errorf("failed to load plan %q for subscription: %w", event.PlanID, err)
+		}
+		sub.PlanID, sub.Tier = plan.ID, plan.Tier
+		sub.Status = models.SubscriptionActive
+		sub.TrialEnd = nil
+		if event.TrialEnd != nil && event.TrialEnd.After(now) {
+			sub.Status = models.SubscriptionTrialing
+			sub.TrialEnd = event.TrialEnd
+		}
+		sub.CurrentPeriodStart, sub.CurrentPeriodEnd = event.PeriodStart, event.PeriodEnd
+		sub.GraceUntil = nil
+	case PaymentEventSubscriptionRenewed:
+		// Ignore a renewal delivered after a later one
+		if event.PeriodEnd.Before(sub.CurrentPeriodEnd) {
+			return nil
+		}
+		sub.Status = models.SubscriptionActive
+		sub.CurrentPeriodStart, sub.CurrentPeriodEnd = event.PeriodStart, event.PeriodEnd
+		sub.GraceUntil = nil
+		sub.CanceledAt = nil
+	case PaymentEventPaymentFailed:
+		// Further failed retries do not extend the grace period
+		if sub.Status != models.SubscriptionPastDue || sub.GraceUntil == nil {
+			graceUntil := now.Add(s.grace)
+			sub.Status = models.SubscriptionPastDue
+			sub.GraceUntil = &graceUntil
+		}
+	case PaymentEventSubscriptionCanceled:
+		sub.Status = models.SubscriptionCanceled
+		sub.CanceledAt = &now
+		if event.EndedImmediately {
+			sub.Status = models.SubscriptionExpired
+		}
+	default:
+		log.Printf("Ignoring %s webhook %s", event.Type, event.ID)
+		return nil
+	}
+
+	applied, err := s.subscriptionRepo.SaveFromWebhook(sub, event.ID, event.Type)
+	if err != nil || !applied {
+		return err
+	}
+	return s.syncTier(sub.UserID)
+}
+
+func (s *subscriptionService) ExpireLapsed() error {
+	userIDs, err := s.subscriptionRepo.ExpireLapsed(s.now(), s.grace)
+	if err != nil {
+		return err
+	}
+	for _, userID := range userIDs {
+		if err := s.syncTier(userID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// syncTier copies the user's tier to users.tier, which the apps read.
+func (s *subscriptionService) syncTier(userID string) error {
+	status, err := s.Status(userID)
+	if err != nil {
+		return err
+	}
+	if err := s.userRepo.UpdateUserTier(userID, status.Tier); err != nil {
+		return fmt.Errorf("failed to update user tier: %w", err)
+	}
+	return nil
+}
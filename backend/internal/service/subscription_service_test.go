@@ -0,0 +1,283 @@
+package service
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"gaspeep/backend/internal/models"
+	"gaspeep/backend/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockSubscriptionRepository struct {
+	mock.Mock
+}
+
+func (m *MockSubscriptionRepository) ListPlans() ([]models.SubscriptionPlan, error) {
+	args := m.Called()
+	return args.Get(0).([]models.SubscriptionPlan), args.Error(1)
+}
+
+func (m *MockSubscriptionRepository) GetPlan(planID string) (*models.SubscriptionPlan, error) {
+	args := m.Called(planID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.SubscriptionPlan), args.Error(1)
+}
+
+func (m *MockSubscriptionRepository) GetCurrent(userID string) (*models.Subscription, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Subscription), args.Error(1)
+}
+
+func (m *MockSubscriptionRepository) GetByProviderID(provider, providerSubscriptionID string) (*models.Subscription, error) {
+	args := m.Called(provider, providerSubscriptionID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Subscription), args.Error(1)
+}
+
+func (m *MockSubscriptionRepository) Save(sub *models.Subscription) error {
+	args := m.Called(sub)
+	return args.Error(0)
+}
+
+func (m *MockSubscriptionRepository) SaveFromWebhook(sub *models.Subscription, eventID, eventType string) (bool, error) {
+	args := m.Called(sub, eventID, eventType)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockSubscriptionRepository) ExpireLapsed(now time.Time, grace time.Duration) ([]string, error) {
+	args := m.Called(now, grace)
+	userIDs, _ := args.Get(0).([]string)
+	return userIDs, args.Error(1)
+}
+
+// MockUserRepositoryForSubscriptions mocks the user lookups and tier updates
+// made by the subscription service. Other UserRepository methods are not
+// implemented.
+type MockUserRepositoryForSubscriptions struct {
+	mock.Mock
+	repository.UserRepository
+}
+
+func (m *MockUserRepositoryForSubscriptions) GetUserByID(id string) (*models.User, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.User), args.Error(1)
+}
+
+func (m *MockUserRepositoryForSubscriptions) UpdateUserTier(userID, tier string) error {
+	args := m.Called(userID, tier)
+	return args.Error(0)
+}
+
+const testPaymentWebhookSecret = "webhook-secret"
+
+var (
+	subscriptionTestNow = time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	premiumMonthly      = &models.SubscriptionPlan{ID: "premium-monthly", Tier: models.TierPremium, TrialDays: 14}
+)
+
+func setupSubscriptionTest() (*subscriptionService, *MockSubscriptionRepository, *MockUserRepositoryForSubscriptions) {
+	repo := new(MockSubscriptionRepository)
+	userRepo := new(MockUserRepositoryForSubscriptions)
+	svc := NewSubscriptionService(repo, userRepo, NewFakePaymentProvider(testPaymentWebhookSecret)).(*subscriptionService)
+	svc.now = func() time.Time { return subscriptionTestNow }
+	return svc, repo, userRepo
+}
+
+func signedWebhook(t *testing.T, event PaymentEvent) ([]byte, http.Header) {
+	t.Helper()
+	payload, err := json.Marshal(event)
+	require.NoError(t, err)
+	header := http.Header{}
+	header.Set(FakePaymentSignatureHeader, SignFakePaymentWebhook(testPaymentWebhookSecret, payload))
+	return payload, header
+}
+
+func TestSubscription_Entitlement(t *testing.T) {
+	svc, _, _ := setupSubscriptionTest()
+	now := subscriptionTestNow
+	graceUntil := now.Add(time.Hour)
+	lapsedGrace := now.Add(-time.Hour)
+
+	cases := []struct {
+		name string
+		sub  models.Subscription
+		want bool
+	}{
+		{"active", models.Subscription{Status: models.SubscriptionActive, CurrentPeriodEnd: now.Add(time.Hour)}, true},
+		{"active renewal late", models.Subscription{Status: models.SubscriptionActive, CurrentPeriodEnd: now.Add(-24 * time.Hour)}, true},
+		{"active renewal missed", models.Subscription{Status: models.SubscriptionActive, CurrentPeriodEnd: now.Add(-8 * 24 * time.Hour)}, false},
+		{"trialing", models.Subscription{Status: models.SubscriptionTrialing, CurrentPeriodEnd: now.Add(time.Hour)}, true},
+		{"past due in grace", models.Subscription{Status: models.SubscriptionPastDue, GraceUntil: &graceUntil}, true},
+		{"past due after grace", models.Subscription{Status: models.SubscriptionPastDue, GraceUntil: &lapsedGrace}, false},
+		{"canceled before period end", models.Subscription{Status: models.SubscriptionCanceled, CurrentPeriodEnd: now.Add(time.Hour)}, true},
+		{"canceled after period end", models.Subscription{Status: models.SubscriptionCanceled, CurrentPeriodEnd: now.Add(-time.Hour)}, false},
+		{"expired", models.Subscription{Status: models.SubscriptionExpired, CurrentPeriodEnd: now.Add(time.Hour)}, false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, svc.entitled(&tc.sub, now))
+		})
+	}
+}
+
+func TestSubscription_HasEntitlement(t *testing.T) {
+	svc, repo, _ := setupSubscriptionTest()
+
+	repo.On("GetCurrent", "free-1").Return(nil, sql.ErrNoRows)
+	repo.On("GetCurrent", "premium-1").Return(&models.Subscription{
+		Tier: models.TierPremium, Status: models.SubscriptionActive, CurrentPeriodEnd: subscriptionTestNow.Add(time.Hour),
+	}, nil)
+
+	ok, err := svc.HasEntitlement("free-1", EntitlementPriceAlerts)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	ok, err = svc.HasEntitlement("premium-1", EntitlementPriceAlerts)
+	require.NoError(t, err)
+	assert.True(t, ok)
+}
+
+func TestSubscription_CheckoutOffersTrialOnce(t *testing.T) {
+	t.Setenv("APP_BASE_URL", "https://app.example.com")
+	svc, repo, userRepo := setupSubscriptionTest()
+	repo.On("GetPlan", "premium-monthly").Return(premiumMonthly, nil)
+	repo.On("GetPlan", "gold").Return(nil, sql.ErrNoRows)
+	userRepo.On("GetUserByID", "user-1").Return(&models.User{ID: "user-1", Email: "user@example.com"}, nil)
+
+	repo.On("GetCurrent", "user-1").Return(nil, sql.ErrNoRows).Once()
+	checkout, err := svc.Checkout("user-1", "premium-monthly")
+	require.NoError(t, err)
+	assert.Contains(t, checkout.URL, "https://app.example.com/profile?")
+	assert.Contains(t, checkout.URL, "checkout="+checkout.ID)
+
+	_, err = svc.Checkout("user-1", "gold")
+	assert.ErrorIs(t, err, ErrPlanNotFound)
+
+	active := &models.Subscription{Status: models.SubscriptionActive, CurrentPeriodEnd: subscriptionTestNow.Add(time.Hour)}
+	repo.On("GetCurrent", "user-1").Return(active, nil).Once()
+	_, err = svc.Checkout("user-1", "premium-monthly")
+	assert.ErrorIs(t, err, ErrAlreadySubscribed)
+}
+
+func TestSubscription_WebhookStartsTrialAndSetsTier(t *testing.T) {
+	svc, repo, userRepo := setupSubscriptionTest()
+	trialEnd := subscriptionTestNow.Add(14 * 24 * time.Hour)
+	event := PaymentEvent{
+		ID:             "evt-1",
+		Type:           PaymentEventSubscriptionStarted,
+		SubscriptionID: "fake_sub_1",
+		UserID:         "user-1",
+		PlanID:         "premium-monthly",
+		PeriodStart:    subscriptionTestNow,
+		PeriodEnd:      trialEnd,
+		TrialEnd:       &trialEnd,
+	}
+
+	repo.On("GetByProviderID", "fake", "fake_sub_1").Return(nil, sql.ErrNoRows)
+	repo.On("GetPlan", "premium-monthly").Return(premiumMonthly, nil)
+	var saved *models.Subscription
+	repo.On("SaveFromWebhook", mock.Anything, "evt-1", PaymentEventSubscriptionStarted).
+		Run(func(args mock.Arguments) { saved = args.Get(0).(*models.Subscription) }).Return(true, nil).Once()
+	repo.On("GetCurrent", "user-1").Return(&models.Subscription{
+		Tier: models.TierPremium, Status: models.SubscriptionTrialing, CurrentPeriodEnd: trialEnd,
+	}, nil)
+	userRepo.On("UpdateUserTier", "user-1", models.TierPremium).Return(nil).Once()
+
+	payload, header := signedWebhook(t, event)
+	require.NoError(t, svc.HandleWebhook(payload, header))
+
+	require.NotNil(t, saved)
+	assert.Equal(t, models.SubscriptionTrialing, saved.Status)
+	assert.Equal(t, "user-1", saved.UserID)
+	assert.Equal(t, trialEnd, saved.CurrentPeriodEnd)
+
+	// A redelivered event changes nothing
+	repo.On("SaveFromWebhook", mock.Anything, "evt-1", PaymentEventSubscriptionStarted).Return(false, nil).Once()
+	require.NoError(t, svc.HandleWebhook(payload, header))
+	userRepo.AssertExpectations(t)
+}
+
+func TestSubscription_WebhookPaymentFailedStartsGracePeriod(t *testing.T) {
+	svc, repo, userRepo := setupSubscriptionTest()
+	sub := &models.Subscription{
+		UserID: "user-1", Tier: models.TierPremium, Status: models.SubscriptionActive,
+		Provider: "fake", ProviderSubscriptionID: "fake_sub_1", CurrentPeriodEnd: subscriptionTestNow,
+	}
+	repo.On("GetByProviderID", "fake", "fake_sub_1").Return(sub, nil)
+	repo.On("SaveFromWebhook", sub, "evt-2", PaymentEventPaymentFailed).Return(true, nil)
+	repo.On("SaveFromWebhook", sub, "evt-3", PaymentEventPaymentFailed).Return(true, nil)
+	repo.On("GetCurrent", "user-1").Return(sub, nil)
+	userRepo.On("UpdateUserTier", "user-1", models.TierPremium).Return(nil)
+
+	payload, header := signedWebhook(t, PaymentEvent{ID: "evt-2", Type: PaymentEventPaymentFailed, SubscriptionID: "fake_sub_1"})
+	require.NoError(t, svc.HandleWebhook(payload, header))
+
+	assert.Equal(t, models.SubscriptionPastDue, sub.Status)
+	require.NotNil(t, sub.GraceUntil)
+	wantGrace := subscriptionTestNow.Add(7 * 24 * time.Hour)
+	assert.Equal(t, wantGrace, *sub.GraceUntil)
+
+	// Retries failing later do not extend the grace period
+	svc.now = func() time.Time { return subscriptionTestNow.Add(48 * time.Hour) }
+	payload, header = signedWebhook(t, PaymentEvent{ID: "evt-3", Type: PaymentEventPaymentFailed, SubscriptionID: "fake_sub_1"})
+	require.NoError(t, svc.HandleWebhook(payload, header))
+	assert.Equal(t, wantGrace, *sub.GraceUntil)
+}
+
+func TestSubscription_WebhookRejectsBadSignature(t *testing.T) {
+	svc, repo, _ := setupSubscriptionTest()
+	payload, header := signedWebhook(t, PaymentEvent{ID: "evt-1", Type: PaymentEventSubscriptionRenewed, SubscriptionID: "fake_sub_1"})
+	header.Set(FakePaymentSignatureHeader, SignFakePaymentWebhook("wrong-secret", payload))
+
+	err := svc.HandleWebhook(payload, header)
+
+	assert.ErrorIs(t, err, ErrInvalidPaymentWebhook)
+	repo.AssertNotCalled(t, "GetByProviderID", mock.Anything, mock.Anything)
+}
+
+func TestSubscription_CancelKeepsTierUntilPeriodEnd(t *testing.T) {
+	svc, repo, _ := setupSubscriptionTest()
+	sub := &models.Subscription{
+		UserID: "user-1", Tier: models.TierPremium, Status: models.SubscriptionActive,
+		ProviderSubscriptionID: "fake_sub_1", CurrentPeriodEnd: subscriptionTestNow.Add(10 * 24 * time.Hour),
+	}
+	repo.On("GetCurrent", "user-1").Return(sub, nil)
+	repo.On("Save", sub).Return(nil).Once()
+
+	got, err := svc.Cancel("user-1")
+	require.NoError(t, err)
+	assert.Equal(t, models.SubscriptionCanceled, got.Status)
+
+	status, err := svc.Status("user-1")
+	require.NoError(t, err)
+	assert.Equal(t, models.TierPremium, status.Tier)
+
+	_, err = svc.Cancel("user-1")
+	assert.ErrorIs(t, err, ErrNoSubscription)
+}
+
+func TestSubscription_ExpireLapsedDowngradesUsers(t *testing.T) {
+	svc, repo, userRepo := setupSubscriptionTest()
+	repo.On("ExpireLapsed", subscriptionTestNow, 7*24*time.Hour).Return([]string{"user-1"}, nil)
+	repo.On("GetCurrent", "user-1").Return(&models.Subscription{Tier: models.TierPremium, Status: models.SubscriptionExpired}, nil)
+	userRepo.On("UpdateUserTier", "user-1", models.TierFree).Return(nil).Once()
+
+	require.NoError(t, svc.ExpireLapsed())
+	userRepo.AssertExpectations(t)
+}
@@ -0,0 +1,78 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"gaspeep/backend/internal/repository"
+)
+
+const (
+	tokenCleanupInterval = time.Hour
+	// tokenRetention keeps expired tokens for a day, so limits on how many
+	// were recently issued still count them.
+	tokenRetention = 24 * time.Hour
+)
+
+// TokenCleanupWorker periodically deletes expired single-use tokens: password
+// resets, sign-in links, email verifications and the integration API's
+// idempotency keys, which are honoured for tokenRetention.
+type TokenCleanupWorker struct {
+	passwordResetRepo     repository.PasswordResetRepository
+	magicLinkRepo         repository.MagicLinkRepository
+	emailVerificationRepo repository.EmailVerificationRepository
+	apiKeyRepo            repository.OwnerAPIKeyRepository
+	now                   func() time.Time
+}
+
+func NewTokenCleanupWorker(
+	passwordResetRepo repository.PasswordResetRepository,
+	magicLinkRepo repository.MagicLinkRepository,
+	emailVerificationRepo repository.EmailVerificationRepository,
+	apiKeyRepo repository.OwnerAPIKeyRepository,
+) *TokenCleanupWorker {
+	return &TokenCleanupWorker{
+		passwordResetRepo:     passwordResetRepo,
+		magicLinkRepo:         magicLinkRepo,
+		emailVerificationRepo: emailVerificationRepo,
+		apiKeyRepo:            apiKeyRepo,
+		now:                   time.Now,
+	}
+}
+
+// Start runs a cleanup now and then every hour until ctx is cancelled.
+func (w *TokenCleanupWorker) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(tokenCleanupInterval)
+		defer ticker.Stop()
+		for {
+			w.purge()
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+func (w *TokenCleanupWorker) purge() {
+	cutoff := w.now().Add(-tokenRetention)
+	for _, tokens := range []struct {
+		name  string
+		purge func(time.Time) (int64, error)
+	}{
+		{"password reset", w.passwordResetRepo.DeleteExpiredBefore},
+		{"magic link", w.magicLinkRepo.DeleteExpiredBefore},
+		{"email verification", w.emailVerificationRepo.DeleteExpiredBefore},
+		{"idempotency", w.apiKeyRepo.DeleteIdempotencyKeysBefore},
+	} {
+		deleted, err := tokens.purge(cutoff)
+		if err != nil {
+			log.Printf("Token cleanup failed for %s tokens: %v", tokens.name, err)
+		} else if deleted > 0 {
+			log.Printf("Token cleanup removed %d expired %s tokens", deleted, tokens.name)
+		}
+	}
+}
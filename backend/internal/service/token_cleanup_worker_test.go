@@ -0,0 +1,58 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"gaspeep/backend/internal/repository"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockPasswordResetRepository mocks the cleanup of expired reset tokens.
+// Other PasswordResetRepository methods are not implemented.
+type MockPasswordResetRepository struct {
+	mock.Mock
+	repository.PasswordResetRepository
+}
+
+func (m *MockPasswordResetRepository) DeleteExpiredBefore(cutoff time.Time) (int64, error) {
+	args := m.Called(cutoff)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+// MockOwnerAPIKeyRepositoryForCleanup mocks the cleanup of old idempotency
+// keys. Other OwnerAPIKeyRepository methods are not implemented.
+type MockOwnerAPIKeyRepositoryForCleanup struct {
+	mock.Mock
+	repository.OwnerAPIKeyRepository
+}
+
+func (m *MockOwnerAPIKeyRepositoryForCleanup) DeleteIdempotencyKeysBefore(cutoff time.Time) (int64, error) {
+	args := m.Called(cutoff)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func TestTokenCleanupWorker_PurgesExpiredTokens(t *testing.T) {
+	resets := new(MockPasswordResetRepository)
+	links := new(MockMagicLinkRepository)
+	verifications := new(MockEmailVerificationRepository)
+	apiKeys := new(MockOwnerAPIKeyRepositoryForCleanup)
+	worker := NewTokenCleanupWorker(resets, links, verifications, apiKeys)
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	worker.now = func() time.Time { return now }
+
+	cutoff := now.Add(-tokenRetention)
+	resets.On("DeleteExpiredBefore", cutoff).Return(int64(3), nil).Once()
+	// One failing table does not stop the others being cleaned up
+	links.On("DeleteExpiredBefore", cutoff).Return(int64(0), errors.New("db down")).Once()
+	verifications.On("DeleteExpiredBefore", cutoff).Return(int64(1), nil).Once()
+	apiKeys.On("DeleteIdempotencyKeysBefore", cutoff).Return(int64(2), nil).Once()
+
+	worker.purge()
+
+	resets.AssertExpectations(t)
+	links.AssertExpectations(t)
+	verifications.AssertExpectations(t)
+	apiKeys.AssertExpectations(t)
+}
@@ -18,10 +18,34 @@ export const apiClient = axios.create({
   withCredentials: true,
 })
 
-// Handle response errors (redirect to signin on 401)
+// Access tokens are short-lived. On a 401, renew the session with the
+// refresh_token cookie once and retry; concurrent requests share the refresh.
+let refreshing: Promise<unknown> | null = null
+
+const refreshSession = () => {
+  if (!refreshing) {
+    refreshing = apiClient.post('/auth/refresh').finally(() => {
+      refreshing = null
+    })
+  }
+  return refreshing
+}
+
+// Handle response errors (redirect to signin when the session has ended)
 apiClient.interceptors.response.use(
   (response) => response,
-  (error) => {
+  async (error) => {
+    const request = error.config
+    const isAuthRequest = /\/auth\/(refresh|signin|signup)$/.test(request?.url ?? '')
+    if (error.response?.status === 401 && request && !request._retried && !isAuthRequest) {
+      request._retried = true
+      try {
+        await refreshSession()
+        return apiClient(request)
+      } catch {
+        // fall through to the sign-in redirect
+      }
+    }
     if (error.response?.status === 401) {
       window.location.href = '/signin'
     }
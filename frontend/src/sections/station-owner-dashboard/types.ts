@@ -14,6 +14,10 @@ export type ClaimStatus = 'available' | 'claimed' | 'pending';
 
 export type VerificationMethod = 'document' | 'phone' | 'email';
 
+export type OwnerRole = 'owner' | 'manager' | 'staff';
+
+export type StationPermission = 'publish_prices' | 'send_broadcasts' | 'view_analytics';
+
 export type DayOfWeek = 'monday' | 'tuesday' | 'wednesday' | 'thursday' | 'friday' | 'saturday' | 'sunday';
 
 export interface StationOwner {
@@ -29,6 +33,7 @@ export interface StationOwner {
   broadcastsThisWeek: number;
   broadcastLimit: number;
   accountCreatedAt: string;
+  role?: OwnerRole; // The signed-in user's role in the business
 }
 
 export interface OperatingHours {
@@ -78,6 +83,8 @@ export interface Broadcast {
   delivered: number;
   opened: number;
   clickedThrough: number;
+  createdBy?: string; // User ID of the team member who created it
+  updatedBy?: string; // User ID of the team member who last changed it
 }
 
 export interface DashboardStats {
@@ -176,3 +183,67 @@ export interface StationOwnerDashboardData {
   availableStationsForClaim: AvailableStation[];
   currentFuelPrices: Record<string, FuelPrice[]>;
 }
+
+export interface StationPermissions {
+  stationId: string;
+  stationName?: string;
+  permissions: StationPermission[];
+}
+
+export interface OwnerTeamMember {
+  id: string;
+  stationOwnerId: string;
+  userId: string;
+  email: string;
+  displayName: string;
+  role: OwnerRole;
+  accountHolder: boolean;
+  stations: StationPermissions[]; // Only staff have per-station permissions
+  createdAt: string;
+}
+
+export interface OwnerTeamInvitation {
+  id: string;
+  businessName: string;
+  email: string;
+  role: OwnerRole;
+  stations: StationPermissions[];
+  invitedBy?: string;
+  expiresAt: string;
+  createdAt: string;
+}
+
+export interface OwnerTeam {
+  members: OwnerTeamMember[];
+  invitations: OwnerTeamInvitation[];
+}
+
+export interface PriceComparison {
+  price: number;
+  rank: number; // 1 is the cheapest; equal prices share a rank
+  competitorCount: number;
+  cheapestPrice: number | null; // Null when no competitor has a price
+  medianPrice: number | null;
+  gapToCheapest: number | null; // Positive when dearer
+  gapToMedian: number | null;
+}
+
+export interface PriceBenchmarkDay extends PriceComparison {
+  date: string; // YYYY-MM-DD, compared at the end of the day
+}
+
+export interface PriceBenchmark extends PriceComparison {
+  stationId: string;
+  stationName: string;
+  fuelTypeId: string;
+  fuelTypeName: string;
+  competitorPriceChanges: number;
+  competitorsChangingPrice: number;
+  history: PriceBenchmarkDay[];
+}
+
+export interface PriceBenchmarkReport {
+  radiusKm: number;
+  days: number;
+  benchmarks: PriceBenchmark[];
+}
@@ -11,6 +11,12 @@ import {
   StationUpdateFormData,
   FuelPrice,
   BroadcastEngagementMetric,
+  OwnerRole,
+  OwnerTeam,
+  OwnerTeamInvitation,
+  OwnerTeamMember,
+  StationPermissions,
+  PriceBenchmarkReport,
 } from '../sections/station-owner-dashboard/types'
 import { AccountSettingsFormData } from '../sections/station-owner-dashboard/AccountSettingsScreen'
 
@@ -120,10 +126,21 @@ export const submitVerification = async (
 }
 
 /**
- * Re-verify station ownership (annual requirement)
+ * Re-verify station ownership (annual requirement), with the same evidence as a claim
  */
-export const reVerifyStation = async (stationId: string): Promise<VerificationRequest> => {
-  const { data } = await apiClient.post(`/station-owners/stations/${stationId}/reverify`, {})
+export const reVerifyStation = async (
+  stationId: string,
+  verificationMethod: 'document' | 'phone' | 'email',
+  documentUrls?: string[],
+  phoneNumber?: string,
+  email?: string
+): Promise<VerificationRequest> => {
+  const { data } = await apiClient.post(`/station-owners/stations/${stationId}/reverify`, {
+    verificationMethod,
+    documentUrls,
+    phoneNumber,
+    email,
+  })
   return data
 }
 
@@ -390,6 +407,99 @@ export const getOwnerFuelPrices = async (): Promise<Record<string, FuelPrice[]>>
   return data || {}
 }
 
+// ============================================================================
+// TEAM
+// ============================================================================
+
+/**
+ * Get the business's members and pending invitations (owners and managers)
+ */
+export const getTeam = async (): Promise<OwnerTeam> => {
+  const { data } = await apiClient.get('/station-owners/team')
+  return data
+}
+
+/**
+ * Get the current user's role and station permissions in their business
+ */
+export const getMyMembership = async (): Promise<OwnerTeamMember> => {
+  const { data } = await apiClient.get('/station-owners/team/me')
+  return data
+}
+
+/**
+ * Invite someone to the business by email. Stations are only used for staff.
+ */
+export const inviteTeamMember = async (
+  email: string,
+  role: OwnerRole,
+  stations: StationPermissions[] = []
+): Promise<OwnerTeamInvitation> => {
+  const { data } = await apiClient.post('/station-owners/team/invitations', {
+    email,
+    role,
+    stations,
+  })
+  return data
+}
+
+/**
+ * Revoke a pending invitation
+ */
+export const revokeTeamInvitation = async (invitationId: string): Promise<{ message: string }> => {
+  const { data } = await apiClient.delete(`/station-owners/team/invitations/${invitationId}`)
+  return data
+}
+
+/**
+ * Join a business with the token from an invitation email
+ */
+export const acceptTeamInvitation = async (token: string): Promise<OwnerTeamMember> => {
+  const { data } = await apiClient.post('/station-owners/team/invitations/accept', { token })
+  return data
+}
+
+/**
+ * Change a member's role and, for staff, station permissions
+ */
+export const updateTeamMember = async (
+  memberId: string,
+  role: OwnerRole,
+  stations: StationPermissions[] = []
+): Promise<OwnerTeamMember> => {
+  const { data } = await apiClient.put(`/station-owners/team/members/${memberId}`, {
+    role,
+    stations,
+  })
+  return data
+}
+
+/**
+ * Remove a member from the business
+ */
+export const removeTeamMember = async (memberId: string): Promise<{ message: string }> => {
+  const { data } = await apiClient.delete(`/station-owners/team/members/${memberId}`)
+  return data
+}
+
+// ============================================================================
+// ANALYTICS
+// ============================================================================
+
+/**
+ * Compare the owner's prices with competitors within radiusKm, day by day
+ * over the last `days` days
+ */
+export const getPriceBenchmarks = async (
+  radiusKm?: number,
+  days?: number
+): Promise<PriceBenchmarkReport> => {
+  const { data } = await apiClient.get('/station-owners/analytics/price-benchmarks', {
+    params: { radiusKm, days },
+  })
+  return data
+}
+
 // ============================================================================
 // COMBINED SERVICE OBJECT
 // ============================================================================
@@ -432,4 +542,16 @@ export const stationOwnerService = {
   getFuelTypes,
   getStationFuelPrices,
   getOwnerFuelPrices,
+
+  // Team
+  getTeam,
+  getMyMembership,
+  inviteTeamMember,
+  revokeTeamInvitation,
+  acceptTeamInvitation,
+  updateTeamMember,
+  removeTeamMember,
+
+  // Analytics
+  getPriceBenchmarks,
 }